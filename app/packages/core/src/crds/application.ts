@@ -9,6 +9,7 @@ export interface IApplication {
   links?: ILink[];
   name: string;
   namespace: string;
+  resourceVersion?: string;
   tags?: string[];
   teams?: string[];
   topology?: ITopology;
@@ -16,6 +17,7 @@ export interface IApplication {
 }
 
 export interface IInsight {
+  health?: IInsightHealth;
   mappings?: Record<string, string>;
   plugin: IPlugin;
   title: string;
@@ -23,6 +25,12 @@ export interface IInsight {
   unit?: string;
 }
 
+export interface IInsightHealth {
+  degraded?: string;
+  operator?: string;
+  unhealthy?: string;
+}
+
 export interface ILink {
   link: string;
   title: string;
@@ -10,6 +10,7 @@ export interface ITeam {
   logo?: string;
   name: string;
   namespace: string;
+  resourceVersion?: string;
   updatedAt: number;
 }
 
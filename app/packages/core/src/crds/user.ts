@@ -8,6 +8,7 @@ export interface IUser {
   namespace: string;
   navigation?: INavigation[];
   permissions: IPermissions;
+  resourceVersion?: string;
   updatedAt: number;
 }
 
@@ -36,6 +37,7 @@ export interface IResourcesPermissions {
   namespaces: string[];
   resources: string[];
   verbs: string[];
+  deny?: boolean;
 }
 
 export interface INavigation {
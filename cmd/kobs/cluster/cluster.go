@@ -9,6 +9,7 @@ import (
 	"github.com/kobsio/kobs/pkg/cluster/api"
 	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
 	clusterPlugins "github.com/kobsio/kobs/pkg/cluster/plugins"
+	"github.com/kobsio/kobs/pkg/cluster/webhook"
 	"github.com/kobsio/kobs/pkg/instrument/debug"
 	"github.com/kobsio/kobs/pkg/instrument/log"
 	"github.com/kobsio/kobs/pkg/instrument/metrics"
@@ -30,6 +31,7 @@ type Cmd struct {
 		Metrics    metrics.Config    `json:"metrics" embed:"" prefix:"metrics." envprefix:"METRICS_"`
 		Kubernetes kubernetes.Config `json:"kubernetes" embed:"" prefix:"kubernetes." envprefix:"KUBERNETES_"`
 		API        api.Config        `json:"api" embed:"" prefix:"api." envprefix:"API_"`
+		Webhook    webhook.Config    `json:"webhook" embed:"" prefix:"webhook." envprefix:"WEBHOOK_"`
 		Plugins    []plugin.Instance `json:"plugins" kong:"-"`
 	} `json:"cluster" embed:"" prefix:"cluster." envprefix:"KOBS_CLUSTER_"`
 }
@@ -84,6 +86,21 @@ func (r *Cmd) Run(plugins []plugins.Plugin) error {
 	}
 	go apiServer.Start()
 
+	var webhookServer webhook.Server
+	if cfg.Cluster.Webhook.Enabled {
+		var pluginTypes []string
+		for _, plugin := range plugins {
+			pluginTypes = append(pluginTypes, plugin.Type())
+		}
+
+		webhookServer, err = webhook.New(cfg.Cluster.Webhook, kubernetesClient, pluginTypes)
+		if err != nil {
+			log.Error(context.Background(), "Could not create webhook server", zap.Error(err))
+			return err
+		}
+		go webhookServer.Start()
+	}
+
 	// All components should be terminated gracefully. For that we are listen for the SIGINT and SIGTERM signals and try
 	// to gracefully shutdown the started kobs components. This ensures that established connections or tasks are not
 	// interrupted.
@@ -95,6 +112,9 @@ func (r *Cmd) Run(plugins []plugins.Plugin) error {
 	log.Info(context.Background(), "Shutdown kobs client...")
 
 	apiServer.Stop()
+	if webhookServer != nil {
+		webhookServer.Stop()
+	}
 
 	log.Info(context.Background(), "Shutdown is done")
 
@@ -8,10 +8,13 @@ import (
 
 	"github.com/kobsio/kobs/pkg/hub/api"
 	"github.com/kobsio/kobs/pkg/hub/app"
+	"github.com/kobsio/kobs/pkg/hub/audit"
 	"github.com/kobsio/kobs/pkg/hub/auth"
 	"github.com/kobsio/kobs/pkg/hub/clusters"
 	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/hub/health"
 	hubPlugins "github.com/kobsio/kobs/pkg/hub/plugins"
+	"github.com/kobsio/kobs/pkg/hub/recordings"
 	"github.com/kobsio/kobs/pkg/instrument/debug"
 	"github.com/kobsio/kobs/pkg/instrument/log"
 	"github.com/kobsio/kobs/pkg/instrument/metrics"
@@ -27,16 +30,19 @@ type Cmd struct {
 	Config string `env:"KOBS_CONFIG" default:"config.yaml" help:"The path to the configuration file for the hub."`
 
 	Hub struct {
-		Debug    debug.Config      `json:"debug" embed:"" prefix:"debug." envprefix:"DEBUG_"`
-		Log      log.Config        `json:"log" embed:"" prefix:"log." envprefix:"LOG_"`
-		Tracer   tracer.Config     `json:"tracer" embed:"" prefix:"tracer." envprefix:"TRACER_"`
-		Metrics  metrics.Config    `json:"metrics" embed:"" prefix:"metrics." envprefix:"METRICS_"`
-		Database db.Config         `json:"database" embed:"" prefix:"database." envprefix:"DATABASE_"`
-		API      api.Config        `json:"api" embed:"" prefix:"api." envprefix:"API_"`
-		Auth     auth.Config       `json:"auth" embed:"" prefix:"auth." envprefix:"AUTH_"`
-		App      app.Config        `json:"app" embed:"" prefix:"app." envprefix:"APP_"`
-		Clusters clusters.Config   `json:"clusters" kong:"-"`
-		Plugins  []plugin.Instance `json:"plugins" kong:"-"`
+		Debug      debug.Config      `json:"debug" embed:"" prefix:"debug." envprefix:"DEBUG_"`
+		Log        log.Config        `json:"log" embed:"" prefix:"log." envprefix:"LOG_"`
+		Tracer     tracer.Config     `json:"tracer" embed:"" prefix:"tracer." envprefix:"TRACER_"`
+		Metrics    metrics.Config    `json:"metrics" embed:"" prefix:"metrics." envprefix:"METRICS_"`
+		Database   db.Config         `json:"database" embed:"" prefix:"database." envprefix:"DATABASE_"`
+		API        api.Config        `json:"api" embed:"" prefix:"api." envprefix:"API_"`
+		Auth       auth.Config       `json:"auth" embed:"" prefix:"auth." envprefix:"AUTH_"`
+		Audit      audit.Config      `json:"audit" embed:"" prefix:"audit." envprefix:"AUDIT_"`
+		Recordings recordings.Config `json:"recordings" embed:"" prefix:"recordings." envprefix:"RECORDINGS_"`
+		App        app.Config        `json:"app" embed:"" prefix:"app." envprefix:"APP_"`
+		Health     health.Config     `json:"health" embed:"" prefix:"health." envprefix:"HEALTH_"`
+		Clusters   clusters.Config   `json:"clusters" kong:"-"`
+		Plugins    []plugin.Instance `json:"plugins" kong:"-"`
 	} `json:"hub" embed:"" prefix:"hub." envprefix:"KOBS_HUB_"`
 }
 
@@ -71,12 +77,6 @@ func (r *Cmd) Run(plugins []plugins.Plugin) error {
 		defer debugServer.Stop()
 	}
 
-	clustersClient, err := clusters.NewClient(cfg.Hub.Clusters)
-	if err != nil {
-		log.Error(context.Background(), "Could not create clusters client", zap.Error(err))
-		return err
-	}
-
 	dbClient, err := db.NewClient(cfg.Hub.Database)
 	if err != nil {
 		log.Error(context.Background(), "Could not create database client", zap.Error(err))
@@ -89,19 +89,43 @@ func (r *Cmd) Run(plugins []plugins.Plugin) error {
 		return err
 	}
 
+	clustersClient, err := clusters.NewClient(cfg.Hub.Clusters, dbClient)
+	if err != nil {
+		log.Error(context.Background(), "Could not create clusters client", zap.Error(err))
+		return err
+	}
+
+	clustersCtx, clustersCancel := context.WithCancel(context.Background())
+	defer clustersCancel()
+	go clustersClient.Watch(clustersCtx)
+
 	pluginsClient, err := hubPlugins.NewClient(plugins, cfg.Hub.Plugins, clustersClient, dbClient)
 	if err != nil {
 		log.Error(context.Background(), "Could not create plugins client", zap.Error(err))
 		return err
 	}
 
+	if cfg.Hub.Health.Enabled {
+		healthClient := health.NewClient(cfg.Hub.Health, clustersClient, dbClient, pluginsClient)
+		go healthClient.Watch()
+		defer healthClient.Stop()
+	}
+
 	authClient, err := auth.NewClient(cfg.Hub.Auth, cfg.Hub.App.Settings, dbClient)
 	if err != nil {
 		log.Error(context.Background(), "Could not create auth client", zap.Error(err))
 		return err
 	}
 
-	apiServer, err := api.New(cfg.Hub.API, cfg.Hub.App.Settings, authClient, clustersClient, dbClient, pluginsClient)
+	auditClient, err := audit.NewClient(cfg.Hub.Audit, dbClient)
+	if err != nil {
+		log.Error(context.Background(), "Could not create audit client", zap.Error(err))
+		return err
+	}
+
+	recordingsClient := recordings.NewClient(cfg.Hub.Recordings, dbClient)
+
+	apiServer, err := api.New(cfg.Hub.API, cfg.Hub.App.Settings, authClient, auditClient, clustersClient, dbClient, pluginsClient, recordingsClient)
 	if err != nil {
 		log.Error(context.Background(), "Could not create client server", zap.Error(err))
 		return err
@@ -0,0 +1,76 @@
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/lint"
+	"github.com/kobsio/kobs/pkg/instrument/log"
+	"github.com/kobsio/kobs/pkg/plugins"
+
+	"go.uber.org/zap"
+)
+
+type Cmd struct {
+	Paths     []string `arg:"" name:"path" type:"path" help:"The files or directories with the Application, Dashboard, Team and User CRs which should be linted."`
+	Output    string   `env:"KOBS_LINT_OUTPUT" enum:"text,json" default:"text" help:"The output format for the found problems. Must be \"text\" or \"json\"."`
+	Namespace string   `env:"KOBS_LINT_NAMESPACE" default:"default" help:"The namespace which is used for CRs without a namespace."`
+	Teams     []string `env:"KOBS_LINT_TEAMS" help:"The ids of teams which are not defined in the linted files, but can be referenced by the CRs."`
+	Strict    bool     `env:"KOBS_LINT_STRICT" default:"false" help:"Exit with a non-zero exit code when warnings are found, e.g. for referenced teams which are not defined."`
+}
+
+func (r *Cmd) Run(plugins []plugins.Plugin) error {
+	manifests, problems, err := lint.Load(r.Paths, r.Namespace)
+	if err != nil {
+		log.Error(context.Background(), "Could not load manifests", zap.Error(err))
+		return err
+	}
+
+	var pluginTypes []string
+	for _, plugin := range plugins {
+		pluginTypes = append(pluginTypes, plugin.Type())
+	}
+
+	problems = append(problems, lint.Lint(context.Background(), manifests, pluginTypes, r.Teams)...)
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].File != problems[j].File {
+			return problems[i].File < problems[j].File
+		}
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+
+	if r.Output == "json" {
+		if problems == nil {
+			problems = []lint.Problem{}
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(problems); err != nil {
+			return err
+		}
+	} else {
+		for _, problem := range problems {
+			fmt.Fprintln(os.Stdout, problem.String())
+		}
+	}
+
+	var failures int
+	for _, problem := range problems {
+		if !problem.Warning || r.Strict {
+			failures++
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("found %d problems in %d manifests", failures, len(manifests))
+	}
+
+	return nil
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"github.com/kobsio/kobs/cmd/kobs/cluster"
 	"github.com/kobsio/kobs/cmd/kobs/hub"
+	"github.com/kobsio/kobs/cmd/kobs/lint"
+	"github.com/kobsio/kobs/cmd/kobs/portforward"
 	"github.com/kobsio/kobs/cmd/kobs/version"
 	"github.com/kobsio/kobs/cmd/kobs/watcher"
 	"github.com/kobsio/kobs/pkg/plugins"
@@ -34,10 +36,12 @@ import (
 )
 
 var cli struct {
-	Hub     hub.Cmd     `cmd:"hub" help:"Start the hub."`
-	Watcher watcher.Cmd `cmd:"watcher" help:"Start the watcher."`
-	Cluster cluster.Cmd `cmd:"cluster" help:"Start the cluster."`
-	Version version.Cmd `cmd:"version" help:"Show version information."`
+	Hub         hub.Cmd         `cmd:"hub" help:"Start the hub."`
+	Watcher     watcher.Cmd     `cmd:"watcher" help:"Start the watcher."`
+	Cluster     cluster.Cmd     `cmd:"cluster" help:"Start the cluster."`
+	Lint        lint.Cmd        `cmd:"lint" help:"Lint Application, Dashboard, Team and User CRs."`
+	PortForward portforward.Cmd `cmd:"port-forward" help:"Forward one or more local ports to a Pod through the hub."`
+	Version     version.Cmd     `cmd:"version" help:"Show version information."`
 }
 
 func main() {
@@ -0,0 +1,164 @@
+package portforward
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/portforward"
+	"github.com/kobsio/kobs/pkg/instrument/log"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+type Cmd struct {
+	Hub       string   `env:"KOBS_HUB" required:"" help:"The address of the hub, e.g. \"https://kobs.example.com\"."`
+	Token     string   `env:"KOBS_TOKEN" required:"" help:"The personal API token, which is used to authenticate against the hub."`
+	Cluster   string   `env:"KOBS_CLUSTER" required:"" help:"The name of the cluster of the Pod."`
+	Namespace string   `short:"n" env:"KOBS_NAMESPACE" default:"default" help:"The namespace of the Pod."`
+	Address   string   `env:"KOBS_PORT_FORWARD_ADDRESS" default:"127.0.0.1" help:"The local address, where the ports should be bound."`
+	Pod       string   `arg:"" name:"pod" help:"The name of the Pod."`
+	Ports     []string `arg:"" name:"ports" help:"The ports which should be forwarded in the format \"<local-port>:<remote-port>\". If the local port is omitted, the remote port is used as local port. If the local port is empty (\":<remote-port>\"), a random local port is used."`
+}
+
+// portMapping is a mapping of a local port to a remote port of the Pod. When the local port is 0 a random local port
+// is used.
+type portMapping struct {
+	local  int
+	remote int
+}
+
+func (r *Cmd) Run() error {
+	hubURL, err := url.Parse(r.Hub)
+	if err != nil {
+		log.Error(context.Background(), "Invalid hub address", zap.Error(err))
+		return err
+	}
+
+	switch hubURL.Scheme {
+	case "http":
+		hubURL.Scheme = "ws"
+	case "https":
+		hubURL.Scheme = "wss"
+	default:
+		return fmt.Errorf("invalid hub address %s, must start with http:// or https://", r.Hub)
+	}
+
+	var mappings []portMapping
+	for _, port := range r.Ports {
+		mapping, err := parsePort(port)
+		if err != nil {
+			log.Error(context.Background(), "Invalid port", zap.Error(err))
+			return err
+		}
+		mappings = append(mappings, mapping)
+	}
+
+	var listeners []net.Listener
+	defer func() {
+		for _, listener := range listeners {
+			listener.Close()
+		}
+	}()
+
+	for _, mapping := range mappings {
+		listener, err := net.Listen("tcp", net.JoinHostPort(r.Address, strconv.Itoa(mapping.local)))
+		if err != nil {
+			log.Error(context.Background(), "Could not bind local port", zap.Error(err), zap.Int("port", mapping.local))
+			return err
+		}
+		listeners = append(listeners, listener)
+
+		fmt.Fprintf(os.Stdout, "Forwarding from %s -> %d\n", listener.Addr().String(), mapping.remote)
+		go r.accept(listener, hubURL, mapping.remote)
+	}
+
+	done := make(chan os.Signal, 1)
+	signal.Notify(done, os.Interrupt, syscall.SIGTERM)
+	<-done
+
+	return nil
+}
+
+// accept accepts all connections for the provided listener and forwards them to the remote port of the Pod, until the
+// listener is closed.
+func (r *Cmd) accept(listener net.Listener, hubURL *url.URL, remotePort int) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Error(context.Background(), "Could not accept connection", zap.Error(err))
+			}
+			return
+		}
+
+		go r.forward(conn, hubURL, remotePort)
+	}
+}
+
+// forward opens a new WebSocket connection to the port forward endpoint of the hub for the provided connection and
+// copies the data between both connections.
+func (r *Cmd) forward(conn net.Conn, hubURL *url.URL, remotePort int) {
+	defer conn.Close()
+
+	forwardURL := *hubURL
+	forwardURL.Path = strings.TrimSuffix(forwardURL.Path, "/") + "/api/resources/portforward"
+	forwardURL.RawQuery = url.Values{
+		"namespace": []string{r.Namespace},
+		"name":      []string{r.Pod},
+		"port":      []string{strconv.Itoa(remotePort)},
+	}.Encode()
+
+	headers := http.Header{}
+	headers.Set("Authorization", "Bearer "+r.Token)
+	headers.Set("x-kobs-cluster", r.Cluster)
+
+	ws, resp, err := websocket.DefaultDialer.Dial(forwardURL.String(), headers)
+	if err != nil {
+		if resp != nil {
+			err = fmt.Errorf("%w: %s", err, resp.Status)
+		}
+		log.Error(context.Background(), "Could not connect to hub", zap.Error(err), zap.Int("port", remotePort))
+		return
+	}
+	defer ws.Close()
+
+	fmt.Fprintf(os.Stdout, "Handling connection for %d\n", remotePort)
+
+	if err := portforward.Proxy(ws, conn); err != nil && !errors.Is(err, net.ErrClosed) {
+		log.Error(context.Background(), "Port forward was closed with an error", zap.Error(err), zap.Int("port", remotePort))
+	}
+}
+
+// parsePort parses a port in the format "<local-port>:<remote-port>", "<port>" or ":<remote-port>".
+func parsePort(port string) (portMapping, error) {
+	local, remote, ok := strings.Cut(port, ":")
+	if !ok {
+		remote = local
+	}
+
+	remotePort, err := strconv.Atoi(remote)
+	if err != nil || remotePort < 1 || remotePort > 65535 {
+		return portMapping{}, fmt.Errorf("invalid remote port in %s", port)
+	}
+
+	if local == "" {
+		return portMapping{local: 0, remote: remotePort}, nil
+	}
+
+	localPort, err := strconv.Atoi(local)
+	if err != nil || localPort < 0 || localPort > 65535 {
+		return portMapping{}, fmt.Errorf("invalid local port in %s", port)
+	}
+
+	return portMapping{local: localPort, remote: remotePort}, nil
+}
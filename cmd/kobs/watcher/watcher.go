@@ -64,17 +64,21 @@ func (r *Cmd) Run(plugins []plugins.Plugin) error {
 		defer debugServer.Stop()
 	}
 
-	clustersClient, err := clusters.NewClient(cfg.Watcher.Clusters)
+	dbClient, err := db.NewClient(cfg.Watcher.Database)
 	if err != nil {
-		log.Error(context.Background(), "Could not create clusters client", zap.Error(err))
-		return err
+		log.Error(context.Background(), "Could not create database client", zap.Error(err))
 	}
 
-	dbClient, err := db.NewClient(cfg.Watcher.Database)
+	clustersClient, err := clusters.NewClient(cfg.Watcher.Clusters, dbClient)
 	if err != nil {
-		log.Error(context.Background(), "Could not create database client", zap.Error(err))
+		log.Error(context.Background(), "Could not create clusters client", zap.Error(err))
+		return err
 	}
 
+	clustersCtx, clustersCancel := context.WithCancel(context.Background())
+	defer clustersCancel()
+	go clustersClient.Watch(clustersCtx)
+
 	watcherClient, err := watcher.NewClient(cfg.Watcher.Watcher, clustersClient, dbClient)
 	if err != nil {
 		log.Error(context.Background(), "Could not create watcher", zap.Error(err))
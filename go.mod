@@ -56,6 +56,7 @@ require (
 	golang.org/x/crypto v0.20.0
 	golang.org/x/oauth2 v0.17.0
 	google.golang.org/api v0.167.0
+	gopkg.in/yaml.v3 v3.0.1
 	k8s.io/api v0.29.2
 	k8s.io/apiextensions-apiserver v0.29.2
 	k8s.io/apimachinery v0.29.2
@@ -198,7 +199,6 @@ require (
 	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/square/go-jose.v2 v2.6.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 	istio.io/api v0.0.0-20230524015941-fa6c5f7916bf // indirect
 	istio.io/client-go v1.18.0 // indirect
 	k8s.io/klog/v2 v2.110.1 // indirect
@@ -7,6 +7,7 @@ import (
 
 	"github.com/kobsio/kobs/pkg/cluster/api/applications"
 	"github.com/kobsio/kobs/pkg/cluster/api/dashboards"
+	"github.com/kobsio/kobs/pkg/cluster/api/events"
 	"github.com/kobsio/kobs/pkg/cluster/api/resources"
 	"github.com/kobsio/kobs/pkg/cluster/api/teams"
 	"github.com/kobsio/kobs/pkg/cluster/api/users"
@@ -16,6 +17,7 @@ import (
 	"github.com/kobsio/kobs/pkg/instrument/log"
 	"github.com/kobsio/kobs/pkg/utils/middleware/recoverer"
 	"github.com/kobsio/kobs/pkg/utils/middleware/tokenauth"
+	"github.com/kobsio/kobs/pkg/utils/reload"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -25,8 +27,11 @@ import (
 )
 
 type Config struct {
-	Address string `json:"address" env:"ADDRESS" default:":15221" help:"The address where the cluster API should listen on."`
-	Token   string `json:"token" env:"TOKEN" default:"" help:"The token which is used to protect the cluster API."`
+	Address   string       `json:"address" env:"ADDRESS" default:":15221" help:"The address where the cluster API should listen on."`
+	Token     string       `json:"token" env:"TOKEN" default:"" help:"The token which is used to protect the cluster API."`
+	TokenFile string       `json:"tokenFile" env:"TOKEN_FILE" default:"" help:"The path to a file with the token, which is used to protect the cluster API. The file is reloaded when it is changed."`
+	TLS       TLSConfig    `json:"tls" embed:"" prefix:"tls." envprefix:"TLS_"`
+	Tunnel    TunnelConfig `json:"tunnel" embed:"" prefix:"tunnel." envprefix:"TUNNEL_"`
 }
 
 // Server is the interface of a client service, which provides the options to start and stop the underlying http
@@ -36,16 +41,29 @@ type Server interface {
 	Stop()
 }
 
-// server implements the Server interface.
+// server implements the Server interface. When a tunnel address is configured, the server also contains an agent,
+// which serves the API through a tunnel to the hub.
 type server struct {
 	server *http.Server
+	agent  *agent
 }
 
 // Start starts serving the client server.
 func (s *server) Start() {
-	log.Info(context.Background(), "Client server started", zap.String("address", s.server.Addr))
+	if s.agent != nil {
+		go s.agent.Start()
+	}
+
+	log.Info(context.Background(), "Client server started", zap.String("address", s.server.Addr), zap.Bool("tls", s.server.TLSConfig != nil))
 
-	if err := s.server.ListenAndServe(); err != nil {
+	var err error
+	if s.server.TLSConfig != nil {
+		err = s.server.ListenAndServeTLS("", "")
+	} else {
+		err = s.server.ListenAndServe()
+	}
+
+	if err != nil {
 		if err != http.ErrServerClosed {
 			log.Error(context.Background(), "Client server died unexpected", zap.Error(err))
 		}
@@ -56,6 +74,10 @@ func (s *server) Start() {
 func (s *server) Stop() {
 	log.Debug(context.Background(), "Start shutdown of the client server")
 
+	if s.agent != nil {
+		s.agent.Stop()
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -65,12 +87,23 @@ func (s *server) Stop() {
 	}
 }
 
-// New return a new client server. It creates the underlying http server, with the given name, address and token.
+// New return a new client server. It creates the underlying http server, with the given name, address and token. When
+// a certificate is configured, the server is served via TLS and can require a client certificate from the hub.
 //
 // We exclude the health check from all middlewares, because the health check just returns 200. Therefore we do not need
 // our defined middlewares like request id, metrics, auth or loggin. This also makes it easier to analyze the logs in a
 // Kubernetes cluster where the health check is called every x seconds, because we generate less logs.
 func New(config Config, kubernetesClient kubernetes.Client, pluginsClient plugins.Client) (Server, error) {
+	token, err := reload.NewToken(config.Token, config.TokenFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := newTLSConfig(config.TLS)
+	if err != nil {
+		return nil, err
+	}
+
 	router := chi.NewRouter()
 	router.Use(recoverer.Handler)
 	router.Use(middleware.Compress(5))
@@ -87,21 +120,36 @@ func New(config Config, kubernetesClient kubernetes.Client, pluginsClient plugin
 
 	router.Route("/api", func(r chi.Router) {
 		r.Use(instrument.Handler())
-		r.Use(tokenauth.Handler(config.Token))
+		r.Use(tokenauth.HandlerFunc(token.Valid, token.Required()))
 
 		r.Mount("/applications", applications.Mount(kubernetesClient))
 		r.Mount("/dashboards", dashboards.Mount(kubernetesClient))
+		r.Mount("/events", events.Mount(kubernetesClient))
 		r.Mount("/resources", resources.Mount(kubernetesClient))
 		r.Mount("/teams", teams.Mount(kubernetesClient))
 		r.Mount("/users", users.Mount(kubernetesClient))
 		r.Mount("/plugins", pluginsClient.Mount())
 	})
 
+	// The requests which are sent through the tunnel are not using TLS, so that we only require a client certificate
+	// for the requests which are sent directly to the cluster API.
+	var tunnelAgent *agent
+	if config.Tunnel.Address != "" {
+		tunnelAgent = newAgent(config.Tunnel, token.Current, router)
+	}
+
+	var handler http.Handler = router
+	if tlsConfig != nil && config.TLS.ClientCAFile != "" {
+		handler = requireClientCertificate(router)
+	}
+
 	return &server{
 		server: &http.Server{
 			Addr:              config.Address,
-			Handler:           router,
+			Handler:           handler,
+			TLSConfig:         tlsConfig,
 			ReadHeaderTimeout: 3 * time.Second,
 		},
+		agent: tunnelAgent,
 	}, nil
 }
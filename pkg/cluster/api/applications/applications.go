@@ -1,10 +1,12 @@
 package applications
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 
 	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
 	"github.com/kobsio/kobs/pkg/instrument/log"
 	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
 
@@ -15,6 +17,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 // Router implements the chi.Router interface, but also contains a tracer and a Kubernetes client which can be used in
@@ -62,6 +65,56 @@ func (router *Router) getApplications(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, applications)
 }
 
+// saveApplication creates or updates the Application CR for the application from the request body. The cluster,
+// namespace and name of the application are required and select the CR, the ID of the application is not saved in the
+// CR. An existing CR is only updated when the application contains the resource version of the CR, which was loaded by
+// the user. When the CR was modified or deleted in the meantime or when a new CR already exists, the API endpoint
+// returns a conflict error, so that the user can reload the application and apply the changes again.
+func (router *Router) saveApplication(w http.ResponseWriter, r *http.Request) {
+	ctx, span := router.tracer.Start(r.Context(), "saveApplication")
+	defer span.End()
+
+	var application applicationv1.ApplicationSpec
+	if err := json.NewDecoder(r.Body).Decode(&application); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to decode request body", zap.Error(err))
+		errresponse.Render(w, r, http.StatusBadRequest, "Failed to decode request body")
+		return
+	}
+
+	span.SetAttributes(attribute.Key("cluster").String(application.Cluster))
+	span.SetAttributes(attribute.Key("namespace").String(application.Namespace))
+	span.SetAttributes(attribute.Key("name").String(application.Name))
+	log.Debug(ctx, "Save application", zap.String("cluster", application.Cluster), zap.String("namespace", application.Namespace), zap.String("name", application.Name), zap.String("resourceVersion", application.ResourceVersion))
+
+	if application.Cluster == "" || application.Namespace == "" || application.Name == "" {
+		err := fmt.Errorf("invalid application data")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to save application", zap.Error(err))
+		errresponse.Render(w, r, http.StatusBadRequest, "Invalid application data")
+		return
+	}
+
+	savedApplication, err := router.kubernetesClient.SaveApplication(ctx, application)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to save application", zap.Error(err))
+
+		if apierrors.IsConflict(err) {
+			errresponse.Render(w, r, http.StatusConflict, "The application was modified, reload the application and try again")
+			return
+		}
+
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to save application")
+		return
+	}
+
+	render.JSON(w, r, savedApplication)
+}
+
 // Mount returns a chi.Router which handles all application related API endpoints.
 func Mount(kubernetesClient kubernetes.Client) chi.Router {
 	router := Router{
@@ -71,6 +124,7 @@ func Mount(kubernetesClient kubernetes.Client) chi.Router {
 	}
 
 	router.Get("/", router.getApplications)
+	router.Put("/", router.saveApplication)
 
 	return router
 }
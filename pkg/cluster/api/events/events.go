@@ -0,0 +1,113 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
+	"github.com/kobsio/kobs/pkg/instrument/log"
+	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Router implements the chi.Router interface, but also contains a tracer and a Kubernetes client which can be used in
+// all API routers.
+type Router struct {
+	*chi.Mux
+	kubernetesClient kubernetes.Client
+	tracer           trace.Tracer
+}
+
+// getEvents streams all changes of Applications, Dashboards, Teams and Users to the caller. The API endpoint requires a
+// `cluster` parameter, to set the cluster for all returned resources, like it is done for the other API endpoints.
+//
+// Each event is written as a single JSON object followed by a newline and the response is flushed after each event, so
+// that the hub receives a change as soon as it happens. The stream is open until the caller closes the connection or
+// until the [kubernetesClient.WatchEvents] method returns an error.
+func (router *Router) getEvents(w http.ResponseWriter, r *http.Request) {
+	cluster := r.URL.Query().Get("cluster")
+
+	ctx, span := router.tracer.Start(r.Context(), "getEvents")
+	defer span.End()
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	log.Debug(ctx, "Get events", zap.String("cluster", cluster))
+
+	if cluster == "" {
+		err := fmt.Errorf("cluster parameter is missing")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to get events", zap.Error(err))
+		errresponse.Render(w, r, http.StatusBadRequest, "The 'cluster' parameter can not be empty")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		err := fmt.Errorf("response writer does not support flushing")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to get events", zap.Error(err))
+		errresponse.Render(w, r, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events := make(chan kubernetes.Event)
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- router.kubernetesClient.WatchEvents(ctx, cluster, events)
+	}()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	encoder := json.NewEncoder(w)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debug(ctx, "Events stream was closed")
+			return
+		case err := <-errs:
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				log.Error(ctx, "Failed to watch events", zap.Error(err))
+			}
+			return
+		case event := <-events:
+			if err := encoder.Encode(event); err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				log.Error(ctx, "Failed to write event", zap.Error(err))
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// Mount returns a chi.Router which handles all event related API endpoints.
+func Mount(kubernetesClient kubernetes.Client) chi.Router {
+	router := Router{
+		chi.NewRouter(),
+		kubernetesClient,
+		otel.Tracer("events"),
+	}
+
+	router.Get("/", router.getEvents)
+
+	return router
+}
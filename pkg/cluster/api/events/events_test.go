@@ -0,0 +1,62 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
+	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
+	"github.com/kobsio/kobs/pkg/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestGetEvents(t *testing.T) {
+	defaultTracer := otel.Tracer("fakeTracer")
+
+	t.Run("should return an error when no cluster paramter is provided", func(t *testing.T) {
+		router := Router{chi.NewRouter(), nil, defaultTracer}
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "/?cluster=", nil)
+		w := httptest.NewRecorder()
+
+		router.getEvents(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		utils.AssertJSONEq(t, w, `{"errors":["The 'cluster' parameter can not be empty"]}`)
+	})
+
+	t.Run("should stream events until the watch returns", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		kubernetesClient := kubernetes.NewMockClient(ctrl)
+		kubernetesClient.EXPECT().WatchEvents(gomock.Any(), "cluster1", gomock.Any()).DoAndReturn(func(ctx context.Context, cluster string, events chan<- kubernetes.Event) error {
+			events <- kubernetes.Event{Type: kubernetes.EventTypeAdd, Resource: "teams", Team: &teamv1.TeamSpec{ID: "/cluster/cluster1/namespace/default/name/team1"}}
+			events <- kubernetes.Event{Type: kubernetes.EventTypeDelete, Resource: "teams", Team: &teamv1.TeamSpec{ID: "/cluster/cluster1/namespace/default/name/team1"}}
+			return fmt.Errorf("unexpected error")
+		})
+
+		router := Router{chi.NewRouter(), kubernetesClient, defaultTracer}
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "/?cluster=cluster1", nil)
+		w := httptest.NewRecorder()
+
+		router.getEvents(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+		require.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		require.Equal(t, `{"type":"add","resource":"teams","team":{"id":"/cluster/cluster1/namespace/default/name/team1","permissions":{}}}
+{"type":"delete","resource":"teams","team":{"id":"/cluster/cluster1/namespace/default/name/team1","permissions":{}}}
+`, w.Body.String())
+	})
+}
+
+func TestMount(t *testing.T) {
+	router := Mount(nil)
+	require.NotNil(t, router)
+}
@@ -2,12 +2,18 @@ package resources
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/copy"
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/portforward"
 	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/terminal"
 	"github.com/kobsio/kobs/pkg/instrument/log"
 	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
@@ -314,6 +320,120 @@ func (router *Router) getLogs(w http.ResponseWriter, r *http.Request) {
 	}{logs})
 }
 
+// getPodsLogs streams the logs of all Pods which are matching the provided label selector or owner via a WebSocket
+// connection. In contrast to the getLogs function the logs of all containers of the Pods are streamed, when no
+// container is provided and Pods which are created during the stream are also followed. The namespace is required, so
+// that the logs can not be streamed for all namespaces at once.
+func (router *Router) getPodsLogs(w http.ResponseWriter, r *http.Request) {
+	namespace := r.URL.Query().Get("namespace")
+	labelSelector := r.URL.Query().Get("labelSelector")
+	owner := r.URL.Query().Get("owner")
+	container := r.URL.Query().Get("container")
+	regex := r.URL.Query().Get("regex")
+	since := r.URL.Query().Get("since")
+	tail := r.URL.Query().Get("tail")
+
+	ctx, span := router.tracer.Start(r.Context(), "getPodsLogs")
+	defer span.End()
+	span.SetAttributes(attribute.Key("namespace").String(namespace))
+	span.SetAttributes(attribute.Key("labelSelector").String(labelSelector))
+	span.SetAttributes(attribute.Key("owner").String(owner))
+	span.SetAttributes(attribute.Key("container").String(container))
+	span.SetAttributes(attribute.Key("regex").String(regex))
+	span.SetAttributes(attribute.Key("since").String(since))
+	span.SetAttributes(attribute.Key("tail").String(tail))
+	log.Debug(ctx, "Get pods logs", zap.String("namespace", namespace), zap.String("labelSelector", labelSelector), zap.String("owner", owner), zap.String("container", container), zap.String("regex", regex), zap.String("since", since), zap.String("tail", tail))
+
+	if namespace == "" {
+		span.RecordError(fmt.Errorf("the 'namespace' parameter is required"))
+		span.SetStatus(codes.Error, "the 'namespace' parameter is required")
+		log.Error(ctx, "The 'namespace' parameter is required")
+		errresponse.Render(w, r, http.StatusBadRequest, "The 'namespace' parameter is required")
+		return
+	}
+
+	if labelSelector == "" && owner == "" {
+		span.RecordError(fmt.Errorf("the 'labelSelector' or 'owner' parameter is required"))
+		span.SetStatus(codes.Error, "the 'labelSelector' or 'owner' parameter is required")
+		log.Error(ctx, "The 'labelSelector' or 'owner' parameter is required")
+		errresponse.Render(w, r, http.StatusBadRequest, "The 'labelSelector' or 'owner' parameter is required")
+		return
+	}
+
+	parsedSince, err := strconv.ParseInt(since, 10, 64)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to parse 'since' parameter", zap.Error(err))
+		errresponse.Render(w, r, http.StatusBadRequest, "Failed to parse 'since' parameter")
+		return
+	}
+
+	parsedTail, err := strconv.ParseInt(tail, 10, 64)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to parse 'tail' parameter", zap.Error(err))
+		errresponse.Render(w, r, http.StatusBadRequest, "Failed to parse 'tail' parameter")
+		return
+	}
+
+	if _, err := regexp.Compile(regex); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to parse 'regex' parameter", zap.Error(err))
+		errresponse.Render(w, r, http.StatusBadRequest, "Failed to parse 'regex' parameter")
+		return
+	}
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+
+	c, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to upgrade connection", zap.Error(err))
+		return
+	}
+	defer c.Close()
+
+	c.SetPongHandler(func(string) error { return nil })
+
+	// The logs are written from the StreamPodsLogs function, so that we have to use WriteControl for the ping messages,
+	// which can be called concurrently with the other write methods.
+	go func() {
+		ticker := time.NewTicker(pingPeriod)
+		defer ticker.Stop()
+
+		for {
+			<-ticker.C
+			if err := c.WriteControl(websocket.PingMessage, nil, time.Now().Add(pingPeriod)); err != nil {
+				return
+			}
+		}
+	}()
+
+	err = router.kubernetesClient.StreamPodsLogs(ctx, c, namespace, kubernetes.PodsLogsOptions{
+		LabelSelector: labelSelector,
+		Owner:         owner,
+		Container:     container,
+		Regex:         regex,
+		Since:         parsedSince,
+		Tail:          parsedTail,
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to stream logs", zap.Error(err))
+		c.WriteMessage(websocket.TextMessage, []byte("Failed to stream logs: "+err.Error()))
+		return
+	}
+
+	log.Debug(ctx, "Logs stream was closed")
+}
+
 // getTerminal starts a new terminal session for a container in a pod. The user must provide the cluster, namespace, pod
 // and container via the corresponding query parameter. It is also possible to specify the shell which should be used
 // for the terminal.
@@ -375,6 +495,141 @@ func (router *Router) getTerminal(w http.ResponseWriter, r *http.Request) {
 	log.Debug(ctx, "Terminal connection was closed")
 }
 
+// getDebugTerminal creates a new ephemeral debug container in a pod and starts a terminal session for it. The user must
+// provide the namespace and pod via the corresponding query parameter. It is also possible to specify the image, the
+// target container which process namespace should be shared with the debug container and the shell which should be
+// used for the terminal.
+func (router *Router) getDebugTerminal(w http.ResponseWriter, r *http.Request) {
+	namespace := r.URL.Query().Get("namespace")
+	name := r.URL.Query().Get("name")
+	image := r.URL.Query().Get("image")
+	targetContainerName := r.URL.Query().Get("targetContainerName")
+	shell := r.URL.Query().Get("shell")
+
+	ctx, span := router.tracer.Start(r.Context(), "getDebugTerminal")
+	defer span.End()
+	span.SetAttributes(attribute.Key("namespace").String(namespace))
+	span.SetAttributes(attribute.Key("name").String(name))
+	span.SetAttributes(attribute.Key("image").String(image))
+	span.SetAttributes(attribute.Key("targetContainerName").String(targetContainerName))
+	span.SetAttributes(attribute.Key("shell").String(shell))
+	log.Debug(ctx, "Get debug terminal", zap.String("namespace", namespace), zap.String("name", name), zap.String("image", image), zap.String("targetContainerName", targetContainerName), zap.String("shell", shell))
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+
+	c, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to upgrade connection", zap.Error(err))
+		return
+	}
+	defer c.Close()
+
+	c.SetPongHandler(func(string) error { return nil })
+
+	go func() {
+		ticker := time.NewTicker(pingPeriod)
+		defer ticker.Stop()
+
+		for {
+			<-ticker.C
+
+			if err := c.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}()
+
+	err = router.kubernetesClient.GetDebugTerminal(ctx, c, namespace, name, kubernetes.DebugOptions{Image: image, TargetContainer: targetContainerName, Shell: shell})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to create debug terminal", zap.Error(err))
+		msg, _ := json.Marshal(terminal.Message{
+			Op:   "stdout",
+			Data: fmt.Sprintf("Failed to create debug terminal: %s", err.Error()),
+		})
+		c.WriteMessage(websocket.TextMessage, msg)
+		return
+	}
+
+	log.Debug(ctx, "Debug terminal connection was closed")
+}
+
+// getPortForward forwards a port of a pod via a WebSocket connection. The user must provide the namespace, pod and port
+// via the corresponding query parameter. Each WebSocket connection is used for one TCP connection to the pod.
+func (router *Router) getPortForward(w http.ResponseWriter, r *http.Request) {
+	namespace := r.URL.Query().Get("namespace")
+	name := r.URL.Query().Get("name")
+	port := r.URL.Query().Get("port")
+
+	ctx, span := router.tracer.Start(r.Context(), "getPortForward")
+	defer span.End()
+	span.SetAttributes(attribute.Key("namespace").String(namespace))
+	span.SetAttributes(attribute.Key("name").String(name))
+	span.SetAttributes(attribute.Key("port").String(port))
+	log.Debug(ctx, "Get port forward", zap.String("namespace", namespace), zap.String("name", name), zap.String("port", port))
+
+	parsedPort, err := strconv.Atoi(port)
+	if err != nil || parsedPort < 1 || parsedPort > 65535 {
+		span.RecordError(fmt.Errorf("invalid port %s", port))
+		span.SetStatus(codes.Error, fmt.Sprintf("invalid port %s", port))
+		log.Error(ctx, "Invalid port", zap.String("port", port))
+		errresponse.Render(w, r, http.StatusBadRequest, "Invalid port")
+		return
+	}
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+
+	c, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to upgrade connection", zap.Error(err))
+		return
+	}
+	defer c.Close()
+
+	c.SetPongHandler(func(string) error { return nil })
+
+	done := make(chan struct{})
+	defer close(done)
+
+	// The data is written from another goroutine, so that we have to use the WriteControl method for the ping
+	// messages, which can be called concurrently with the other methods of the connection.
+	go func() {
+		ticker := time.NewTicker(pingPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := c.WriteControl(websocket.PingMessage, nil, time.Now().Add(pingPeriod)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	err = router.kubernetesClient.PortForward(ctx, c, namespace, name, parsedPort)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to forward port", zap.Error(err))
+		portforward.Close(c, websocket.CloseInternalServerErr, "Failed to forward port")
+		return
+	}
+
+	log.Debug(ctx, "Port forward connection was closed")
+}
+
 // getFile allows a user to download a file from a given container. For that the file/folder which should be downloaded
 // must be specified as source path (srcPath).
 func (router *Router) getFile(w http.ResponseWriter, r *http.Request) {
@@ -441,6 +696,127 @@ func (router *Router) postFile(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, nil)
 }
 
+// getArchive allows a user to download a directory or file from a given container as tar.gz archive. For that the
+// directory which should be downloaded must be specified as source path (srcPath).
+func (router *Router) getArchive(w http.ResponseWriter, r *http.Request) {
+	namespace := r.URL.Query().Get("namespace")
+	name := r.URL.Query().Get("name")
+	container := r.URL.Query().Get("container")
+	srcPath := r.URL.Query().Get("srcPath")
+
+	ctx, span := router.tracer.Start(r.Context(), "getArchive")
+	defer span.End()
+	span.SetAttributes(attribute.Key("namespace").String(namespace))
+	span.SetAttributes(attribute.Key("name").String(name))
+	span.SetAttributes(attribute.Key("container").String(container))
+	span.SetAttributes(attribute.Key("srcPath").String(srcPath))
+	log.Debug(ctx, "Get archive", zap.String("namespace", namespace), zap.String("name", name), zap.String("container", container), zap.String("srcPath", srcPath))
+
+	if srcPath == "" {
+		log.Error(ctx, "The parameter 'srcPath' is required")
+		errresponse.Render(w, r, http.StatusBadRequest, "The parameter 'srcPath' is required")
+		return
+	}
+
+	err := router.kubernetesClient.CopyDirectoryFromPod(ctx, w, namespace, name, container, srcPath)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to copy archive", zap.Error(err))
+		if errors.Is(err, copy.ErrArchiveTooLarge) {
+			errresponse.Render(w, r, http.StatusRequestEntityTooLarge, "Archive exceeds the maximum size")
+			return
+		}
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get archive")
+		return
+	}
+}
+
+// postArchive allows a user to upload a tar or tar.gz archive to a given container, where it is extracted in the
+// destination directory (destPath). For that the archive must be sent as form data with the name "file". The response
+// contains the number of files and the size of the extracted archive.
+func (router *Router) postArchive(w http.ResponseWriter, r *http.Request) {
+	namespace := r.URL.Query().Get("namespace")
+	name := r.URL.Query().Get("name")
+	container := r.URL.Query().Get("container")
+	destPath := r.URL.Query().Get("destPath")
+
+	ctx, span := router.tracer.Start(r.Context(), "postArchive")
+	defer span.End()
+	span.SetAttributes(attribute.Key("namespace").String(namespace))
+	span.SetAttributes(attribute.Key("name").String(name))
+	span.SetAttributes(attribute.Key("container").String(container))
+	span.SetAttributes(attribute.Key("destPath").String(destPath))
+	log.Debug(ctx, "Post archive", zap.String("namespace", namespace), zap.String("name", name), zap.String("container", container), zap.String("destPath", destPath))
+
+	if destPath == "" {
+		log.Error(ctx, "The parameter 'destPath' is required")
+		errresponse.Render(w, r, http.StatusBadRequest, "The parameter 'destPath' is required")
+		return
+	}
+
+	if strings.HasPrefix(destPath, "-") {
+		log.Error(ctx, "The parameter 'destPath' must not start with '-'")
+		errresponse.Render(w, r, http.StatusBadRequest, "The parameter 'destPath' must not start with '-'")
+		return
+	}
+
+	// We read the archive directly from the multipart reader instead of using the "FormFile" method, so that the
+	// archive isn't buffered in memory or on disk before it is validated.
+	archive, err := getFormFile(r, "file")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to read archive", zap.Error(err))
+		errresponse.Render(w, r, http.StatusBadRequest, "Failed to read archive")
+		return
+	}
+	defer archive.Close()
+
+	stats, err := router.kubernetesClient.CopyArchiveToPod(ctx, namespace, name, container, archive, destPath)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to copy archive", zap.Error(err))
+		if errors.Is(err, copy.ErrArchiveTooLarge) {
+			errresponse.Render(w, r, http.StatusRequestEntityTooLarge, "Archive exceeds the maximum size")
+			return
+		}
+		if errors.Is(err, copy.ErrInvalidArchive) || errors.Is(err, copy.ErrInvalidPath) {
+			errresponse.Render(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to copy archive")
+		return
+	}
+
+	render.JSON(w, r, stats)
+}
+
+// getFormFile returns the part of the multipart form from the request with the provided name.
+func getFormFile(r *http.Request, name string) (io.ReadCloser, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("form file %s not found", name)
+			}
+			return nil, err
+		}
+
+		if part.FormName() == name {
+			return part, nil
+		}
+
+		part.Close()
+	}
+}
+
 func (router *Router) getNamespaces(w http.ResponseWriter, r *http.Request) {
 	ctx, span := router.tracer.Start(r.Context(), "getNamespaces")
 	defer span.End()
@@ -475,6 +851,10 @@ func (router *Router) getCRDs(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, crds)
 }
 
+// Mount returns the router for the resources API. The requests to get, edit, create and delete resources, to get logs,
+// to exec into and to copy files from and to Pods are using the user forwarded by the hub, when the impersonation is
+// enabled. The namespaces and CRDs are always returned with the service account of the cluster component, because they
+// are requested by the watcher without a user.
 func Mount(kubernetesClient kubernetes.Client) chi.Router {
 	router := Router{
 		chi.NewRouter(),
@@ -482,16 +862,26 @@ func Mount(kubernetesClient kubernetes.Client) chi.Router {
 		otel.Tracer("resources"),
 	}
 
-	router.Get("/", router.getResources)
-	router.Delete("/", router.deleteResource)
-	router.Put("/", router.patchResource)
-	router.Post("/", router.createResource)
-	router.Get("/logs", router.getLogs)
-	router.HandleFunc("/terminal", router.getTerminal)
-	router.Get("/file", router.getFile)
-	router.Post("/file", router.postFile)
 	router.Get("/namespaces", router.getNamespaces)
 	router.Get("/crds", router.getCRDs)
 
+	router.Group(func(r chi.Router) {
+		r.Use(kubernetes.ImpersonationHandler(kubernetesClient.ImpersonationEnabled()))
+
+		r.Get("/", router.getResources)
+		r.Delete("/", router.deleteResource)
+		r.Put("/", router.patchResource)
+		r.Post("/", router.createResource)
+		r.Get("/logs", router.getLogs)
+		r.Get("/logs/stream", router.getPodsLogs)
+		r.HandleFunc("/terminal", router.getTerminal)
+		r.HandleFunc("/terminal/debug", router.getDebugTerminal)
+		r.HandleFunc("/portforward", router.getPortForward)
+		r.Get("/file", router.getFile)
+		r.Post("/file", router.postFile)
+		r.Get("/file/archive", router.getArchive)
+		r.Post("/file/archive", router.postArchive)
+	})
+
 	return router
 }
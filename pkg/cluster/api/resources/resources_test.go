@@ -1,16 +1,20 @@
 package resources
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/copy"
 	"github.com/kobsio/kobs/pkg/utils"
 
 	"github.com/go-chi/chi/v5"
@@ -387,6 +391,194 @@ func TestGetFile(t *testing.T) {
 	})
 }
 
+func TestGetArchive(t *testing.T) {
+	defaultTracer := otel.Tracer("fakeTracer")
+
+	var newKubernetesClient = func(t *testing.T) *kubernetes.MockClient {
+		ctrl := gomock.NewController(t)
+		kubernetesClient := kubernetes.NewMockClient(ctrl)
+		return kubernetesClient
+	}
+
+	for _, tt := range []struct {
+		name               string
+		url                string
+		prepare            func(kubernetesClient *kubernetes.MockClient)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "should fail for missing source path",
+			url:                "/file/archive?namespace=garden&name=apple&container=busybox",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors":["The parameter 'srcPath' is required"]}`,
+		},
+		{
+			name: "should fail when archive is too large",
+			url:  "/file/archive?namespace=garden&name=apple&container=busybox&srcPath=/tmp",
+			prepare: func(kubernetesClient *kubernetes.MockClient) {
+				kubernetesClient.EXPECT().CopyDirectoryFromPod(gomock.Any(), gomock.Any(), "garden", "apple", "busybox", "/tmp").Return(copy.ErrArchiveTooLarge)
+			},
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+			expectedBody:       `{"errors":["Archive exceeds the maximum size"]}`,
+		},
+		{
+			name: "should handle Kubernetes client error",
+			url:  "/file/archive?namespace=garden&name=apple&container=busybox&srcPath=/tmp",
+			prepare: func(kubernetesClient *kubernetes.MockClient) {
+				kubernetesClient.EXPECT().CopyDirectoryFromPod(gomock.Any(), gomock.Any(), "garden", "apple", "busybox", "/tmp").Return(fmt.Errorf("unexpected error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `{"errors":["Failed to get archive"]}`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			kubernetesClient := newKubernetesClient(t)
+			if tt.prepare != nil {
+				tt.prepare(kubernetesClient)
+			}
+
+			router := Router{chi.NewRouter(), kubernetesClient, defaultTracer}
+
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, tt.url, nil)
+			w := httptest.NewRecorder()
+			router.getArchive(w, req)
+
+			utils.AssertStatusEq(t, w, tt.expectedStatusCode)
+			utils.AssertJSONEq(t, w, tt.expectedBody)
+		})
+	}
+
+	t.Run("should get archive", func(t *testing.T) {
+		kubernetesClient := newKubernetesClient(t)
+		kubernetesClient.EXPECT().CopyDirectoryFromPod(gomock.Any(), gomock.Any(), "garden", "apple", "busybox", "/tmp").Return(nil)
+
+		router := Router{chi.NewRouter(), kubernetesClient, defaultTracer}
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "/file/archive?namespace=garden&name=apple&container=busybox&srcPath=/tmp", nil)
+		w := httptest.NewRecorder()
+		router.getArchive(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+	})
+}
+
+func TestPostArchive(t *testing.T) {
+	defaultTracer := otel.Tracer("fakeTracer")
+
+	var newKubernetesClient = func(t *testing.T) *kubernetes.MockClient {
+		ctrl := gomock.NewController(t)
+		kubernetesClient := kubernetes.NewMockClient(ctrl)
+		return kubernetesClient
+	}
+
+	var newRequest = func(t *testing.T, url, field string) *http.Request {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		require.NoError(t, writer.WriteField("description", "ignored"))
+		part, err := writer.CreateFormFile(field, "archive.tar")
+		require.NoError(t, err)
+		_, err = part.Write([]byte("archive"))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, url, body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req
+	}
+
+	for _, tt := range []struct {
+		name               string
+		url                string
+		field              string
+		prepare            func(kubernetesClient *kubernetes.MockClient)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "should fail for missing destination path",
+			url:                "/file/archive?namespace=garden&name=apple&container=busybox",
+			field:              "file",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors":["The parameter 'destPath' is required"]}`,
+		},
+		{
+			name:               "should fail for destination path which is a tar option",
+			url:                "/file/archive?namespace=garden&name=apple&container=busybox&destPath=--to-command=sh",
+			field:              "file",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors":["The parameter 'destPath' must not start with '-'"]}`,
+		},
+		{
+			name:               "should fail when form file is missing",
+			url:                "/file/archive?namespace=garden&name=apple&container=busybox&destPath=/tmp",
+			field:              "archive",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors":["Failed to read archive"]}`,
+		},
+		{
+			name:  "should fail for invalid archive",
+			url:   "/file/archive?namespace=garden&name=apple&container=busybox&destPath=/tmp",
+			field: "file",
+			prepare: func(kubernetesClient *kubernetes.MockClient) {
+				kubernetesClient.EXPECT().CopyArchiveToPod(gomock.Any(), "garden", "apple", "busybox", gomock.Any(), "/tmp").Return(nil, fmt.Errorf("%w: path ../etc/passwd is outside of the destination", copy.ErrInvalidArchive))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors":["invalid archive: path ../etc/passwd is outside of the destination"]}`,
+		},
+		{
+			name:  "should fail when archive is too large",
+			url:   "/file/archive?namespace=garden&name=apple&container=busybox&destPath=/tmp",
+			field: "file",
+			prepare: func(kubernetesClient *kubernetes.MockClient) {
+				kubernetesClient.EXPECT().CopyArchiveToPod(gomock.Any(), "garden", "apple", "busybox", gomock.Any(), "/tmp").Return(nil, copy.ErrArchiveTooLarge)
+			},
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+			expectedBody:       `{"errors":["Archive exceeds the maximum size"]}`,
+		},
+		{
+			name:  "should handle Kubernetes client error",
+			url:   "/file/archive?namespace=garden&name=apple&container=busybox&destPath=/tmp",
+			field: "file",
+			prepare: func(kubernetesClient *kubernetes.MockClient) {
+				kubernetesClient.EXPECT().CopyArchiveToPod(gomock.Any(), "garden", "apple", "busybox", gomock.Any(), "/tmp").Return(nil, fmt.Errorf("unexpected error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `{"errors":["Failed to copy archive"]}`,
+		},
+		{
+			name:  "should upload archive",
+			url:   "/file/archive?namespace=garden&name=apple&container=busybox&destPath=/tmp",
+			field: "file",
+			prepare: func(kubernetesClient *kubernetes.MockClient) {
+				kubernetesClient.EXPECT().CopyArchiveToPod(gomock.Any(), "garden", "apple", "busybox", gomock.Any(), "/tmp").DoAndReturn(func(ctx context.Context, namespace, name, container string, archive io.Reader, destPath string) (*copy.ArchiveStats, error) {
+					data, err := io.ReadAll(archive)
+					require.NoError(t, err)
+					require.Equal(t, "archive", string(data))
+					return &copy.ArchiveStats{Files: 2, Size: 1024}, nil
+				})
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"files":2,"size":1024,"skipped":0}`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			kubernetesClient := newKubernetesClient(t)
+			if tt.prepare != nil {
+				tt.prepare(kubernetesClient)
+			}
+
+			router := Router{chi.NewRouter(), kubernetesClient, defaultTracer}
+
+			w := httptest.NewRecorder()
+			router.postArchive(w, newRequest(t, tt.url, tt.field))
+
+			utils.AssertStatusEq(t, w, tt.expectedStatusCode)
+			utils.AssertJSONEq(t, w, tt.expectedBody)
+		})
+	}
+}
+
 func TestGetTerminal(t *testing.T) {
 	defaultTracer := otel.Tracer("fakeTracer")
 
@@ -418,6 +610,105 @@ func TestGetTerminal(t *testing.T) {
 	})
 }
 
+func TestGetDebugTerminal(t *testing.T) {
+	defaultTracer := otel.Tracer("fakeTracer")
+
+	var newKubernetesClient = func(t *testing.T) *kubernetes.MockClient {
+		ctrl := gomock.NewController(t)
+		kubernetesClient := kubernetes.NewMockClient(ctrl)
+		return kubernetesClient
+	}
+
+	t.Run("should get debug terminal session", func(t *testing.T) {
+		kubernetesClient := newKubernetesClient(t)
+		kubernetesClient.EXPECT().GetDebugTerminal(gomock.Any(), gomock.Any(), "garden", "apple", kubernetes.DebugOptions{Image: "busybox", TargetContainer: "app", Shell: "sh"}).Return(nil)
+
+		router := Router{chi.NewRouter(), kubernetesClient, defaultTracer}
+		s := httptest.NewServer(http.HandlerFunc(router.getDebugTerminal))
+		defer s.Close()
+
+		host := strings.TrimPrefix(s.URL, "http://")
+		ws, resp, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s?namespace=garden&name=apple&image=busybox&targetContainerName=app&shell=sh", host), nil)
+		require.NoError(t, err)
+		defer ws.Close()
+		defer resp.Body.Close()
+	})
+
+	t.Run("should return error message when debug terminal could not be created", func(t *testing.T) {
+		kubernetesClient := newKubernetesClient(t)
+		kubernetesClient.EXPECT().GetDebugTerminal(gomock.Any(), gomock.Any(), "garden", "apple", kubernetes.DebugOptions{}).Return(fmt.Errorf("ephemeral containers are disabled"))
+
+		router := Router{chi.NewRouter(), kubernetesClient, defaultTracer}
+		s := httptest.NewServer(http.HandlerFunc(router.getDebugTerminal))
+		defer s.Close()
+
+		host := strings.TrimPrefix(s.URL, "http://")
+		ws, resp, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s?namespace=garden&name=apple", host), nil)
+		require.NoError(t, err)
+		defer ws.Close()
+		defer resp.Body.Close()
+
+		_, msg, err := ws.ReadMessage()
+		require.NoError(t, err)
+		require.JSONEq(t, `{"Op": "stdout", "Data": "Failed to create debug terminal: ephemeral containers are disabled", "Rows": 0, "Cols": 0}`, string(msg))
+	})
+}
+
+func TestGetPortForward(t *testing.T) {
+	defaultTracer := otel.Tracer("fakeTracer")
+
+	var newKubernetesClient = func(t *testing.T) *kubernetes.MockClient {
+		ctrl := gomock.NewController(t)
+		kubernetesClient := kubernetes.NewMockClient(ctrl)
+		return kubernetesClient
+	}
+
+	t.Run("should fail for invalid port", func(t *testing.T) {
+		router := Router{chi.NewRouter(), newKubernetesClient(t), defaultTracer}
+
+		req, _ := http.NewRequest(http.MethodGet, "/portforward?namespace=garden&name=apple&port=abc", nil)
+		w := httptest.NewRecorder()
+		router.getPortForward(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		utils.AssertJSONEq(t, w, `{"errors":["Invalid port"]}`)
+	})
+
+	t.Run("should forward port", func(t *testing.T) {
+		kubernetesClient := newKubernetesClient(t)
+		kubernetesClient.EXPECT().PortForward(gomock.Any(), gomock.Any(), "garden", "apple", 8080).Return(nil)
+
+		router := Router{chi.NewRouter(), kubernetesClient, defaultTracer}
+		s := httptest.NewServer(http.HandlerFunc(router.getPortForward))
+		defer s.Close()
+
+		host := strings.TrimPrefix(s.URL, "http://")
+		ws, resp, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s?namespace=garden&name=apple&port=8080", host), nil)
+		require.NoError(t, err)
+		defer ws.Close()
+		defer resp.Body.Close()
+	})
+
+	t.Run("should close connection when port could not be forwarded", func(t *testing.T) {
+		kubernetesClient := newKubernetesClient(t)
+		kubernetesClient.EXPECT().PortForward(gomock.Any(), gomock.Any(), "garden", "apple", 8080).Return(fmt.Errorf("unexpected error"))
+
+		router := Router{chi.NewRouter(), kubernetesClient, defaultTracer}
+		s := httptest.NewServer(http.HandlerFunc(router.getPortForward))
+		defer s.Close()
+
+		host := strings.TrimPrefix(s.URL, "http://")
+		ws, resp, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s?namespace=garden&name=apple&port=8080", host), nil)
+		require.NoError(t, err)
+		defer ws.Close()
+		defer resp.Body.Close()
+
+		_, _, err = ws.ReadMessage()
+		require.True(t, websocket.IsCloseError(err, websocket.CloseInternalServerErr))
+		require.Contains(t, err.Error(), "Failed to forward port")
+	})
+}
+
 func TestGetLogs(t *testing.T) {
 	defaultTracer := otel.Tracer("fakeTracer")
 
@@ -514,7 +805,92 @@ func TestGetLogs(t *testing.T) {
 	})
 }
 
+func TestGetPodsLogs(t *testing.T) {
+	defaultTracer := otel.Tracer("fakeTracer")
+
+	var newKubernetesClient = func(t *testing.T) *kubernetes.MockClient {
+		ctrl := gomock.NewController(t)
+		kubernetesClient := kubernetes.NewMockClient(ctrl)
+		return kubernetesClient
+	}
+
+	t.Run("should stream logs", func(t *testing.T) {
+		kubernetesClient := newKubernetesClient(t)
+		kubernetesClient.EXPECT().StreamPodsLogs(gomock.Any(), gomock.Any(), "garden", kubernetes.PodsLogsOptions{LabelSelector: "app=apple", Owner: "Deployment/apple", Container: "busybox", Regex: "error", Since: 1234, Tail: 20}).Return(nil)
+
+		router := Router{chi.NewRouter(), kubernetesClient, defaultTracer}
+		s := httptest.NewServer(http.HandlerFunc(router.getPodsLogs))
+		defer s.Close()
+
+		host := strings.TrimPrefix(s.URL, "http://")
+		uri := fmt.Sprintf("ws://%s?namespace=garden&labelSelector=app%%3Dapple&owner=Deployment/apple&container=busybox&regex=error&since=1234&tail=20", host)
+		ws, resp, err := websocket.DefaultDialer.Dial(uri, nil)
+		require.NoError(t, err)
+		defer ws.Close()
+		defer resp.Body.Close()
+
+		_, _, err = ws.ReadMessage()
+		require.Error(t, err)
+	})
+
+	t.Run("should handle bad request query parameters", func(t *testing.T) {
+		for _, tt := range []struct {
+			name          string
+			namespace     string
+			labelSelector string
+			since         string
+			tail          string
+			regex         string
+			err           string
+		}{
+			{name: "missing namespace", labelSelector: "app", since: "1234", tail: "20", err: "The 'namespace' parameter is required"},
+			{name: "missing label selector and owner", namespace: "namespace", since: "1234", tail: "20", err: "The 'labelSelector' or 'owner' parameter is required"},
+			{name: "invalid since", namespace: "namespace", labelSelector: "app", since: "abc", tail: "20", err: "Failed to parse 'since' parameter"},
+			{name: "invalid tail", namespace: "namespace", labelSelector: "app", since: "1234", tail: "0.5", err: "Failed to parse 'tail' parameter"},
+			{name: "invalid regex", namespace: "namespace", labelSelector: "app", since: "1234", tail: "20", regex: "%5B", err: "Failed to parse 'regex' parameter"},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				kubernetesClient := newKubernetesClient(t)
+				router := Router{chi.NewRouter(), kubernetesClient, defaultTracer}
+
+				path := fmt.Sprintf("/logs/stream?namespace=%s&labelSelector=%s&since=%s&tail=%s&regex=%s", tt.namespace, tt.labelSelector, tt.since, tt.tail, tt.regex)
+				req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, path, nil)
+				w := httptest.NewRecorder()
+
+				router.getPodsLogs(w, req)
+
+				utils.AssertStatusEq(t, w, http.StatusBadRequest)
+				utils.AssertJSONEq(t, w, `{"errors":["`+tt.err+`"]}`)
+			})
+		}
+	})
+}
+
 func TestMount(t *testing.T) {
-	router := Mount(nil)
+	ctrl := gomock.NewController(t)
+	kubernetesClient := kubernetes.NewMockClient(ctrl)
+	kubernetesClient.EXPECT().ImpersonationEnabled().Return(true)
+
+	router := Mount(kubernetesClient)
 	require.NotNil(t, router)
+
+	t.Run("should reject requests without user when impersonation is enabled", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/?namespace=default&resource=pods&path=/api/v1", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusForbidden)
+		utils.AssertJSONEq(t, w, `{"errors":["The request doesn't contain a user to impersonate"]}`)
+	})
+
+	t.Run("should get namespaces without user", func(t *testing.T) {
+		kubernetesClient.EXPECT().GetNamespaces(gomock.Any()).Return([]string{"default"}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/namespaces", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+		utils.AssertJSONEq(t, w, `["default"]`)
+	})
 }
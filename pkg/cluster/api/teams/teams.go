@@ -1,10 +1,12 @@
 package teams
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 
 	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
+	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
 	"github.com/kobsio/kobs/pkg/instrument/log"
 	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
 
@@ -15,6 +17,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 // Router implements the chi.Router interface, but also contains a tracer and a Kubernetes client which can be used in
@@ -62,6 +65,55 @@ func (router *Router) getTeams(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, teams)
 }
 
+// saveTeam creates or updates the Team CR for the team from the request body. The cluster, namespace and name of the
+// team are required, while the ID of the team is saved in the CR, because it is used to assign the team to users and
+// applications. Same as for applications, a conflict error is returned when the resource version of the team doesn't
+// match the CR anymore or when a new CR already exists.
+func (router *Router) saveTeam(w http.ResponseWriter, r *http.Request) {
+	ctx, span := router.tracer.Start(r.Context(), "saveTeam")
+	defer span.End()
+
+	var team teamv1.TeamSpec
+	if err := json.NewDecoder(r.Body).Decode(&team); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to decode request body", zap.Error(err))
+		errresponse.Render(w, r, http.StatusBadRequest, "Failed to decode request body")
+		return
+	}
+
+	span.SetAttributes(attribute.Key("cluster").String(team.Cluster))
+	span.SetAttributes(attribute.Key("namespace").String(team.Namespace))
+	span.SetAttributes(attribute.Key("name").String(team.Name))
+	log.Debug(ctx, "Save team", zap.String("cluster", team.Cluster), zap.String("namespace", team.Namespace), zap.String("name", team.Name), zap.String("resourceVersion", team.ResourceVersion))
+
+	if team.Cluster == "" || team.Namespace == "" || team.Name == "" {
+		err := fmt.Errorf("invalid team data")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to save team", zap.Error(err))
+		errresponse.Render(w, r, http.StatusBadRequest, "Invalid team data")
+		return
+	}
+
+	savedTeam, err := router.kubernetesClient.SaveTeam(ctx, team)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to save team", zap.Error(err))
+
+		if apierrors.IsConflict(err) {
+			errresponse.Render(w, r, http.StatusConflict, "The team was modified, reload the team and try again")
+			return
+		}
+
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to save team")
+		return
+	}
+
+	render.JSON(w, r, savedTeam)
+}
+
 // Mount returns a chi.Router which handles all team related API endpoints.
 func Mount(kubernetesClient kubernetes.Client) chi.Router {
 	router := Router{
@@ -71,6 +123,7 @@ func Mount(kubernetesClient kubernetes.Client) chi.Router {
 	}
 
 	router.Get("/", router.getTeams)
+	router.Put("/", router.saveTeam)
 
 	return router
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
@@ -15,6 +16,8 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestGetTeams(t *testing.T) {
@@ -94,6 +97,76 @@ func TestGetTeams(t *testing.T) {
 	})
 }
 
+func TestSaveTeam(t *testing.T) {
+	defaultTracer := otel.Tracer("fakeTracer")
+
+	for _, tt := range []struct {
+		name               string
+		body               string
+		prepare            func(kubernetesClient *kubernetes.MockClient)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "should return error for invalid request body",
+			body:               "[]",
+			prepare:            func(kubernetesClient *kubernetes.MockClient) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors":["Failed to decode request body"]}`,
+		},
+		{
+			name:               "should return error for invalid team data",
+			body:               `{"cluster": "cluster1", "namespace": "namespace1"}`,
+			prepare:            func(kubernetesClient *kubernetes.MockClient) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors":["Invalid team data"]}`,
+		},
+		{
+			name: "should return conflict error",
+			body: `{"cluster": "cluster1", "namespace": "namespace1", "name": "team1", "resourceVersion": "1"}`,
+			prepare: func(kubernetesClient *kubernetes.MockClient) {
+				kubernetesClient.EXPECT().SaveTeam(gomock.Any(), teamv1.TeamSpec{Cluster: "cluster1", Namespace: "namespace1", Name: "team1", ResourceVersion: "1"}).Return(nil, apierrors.NewConflict(schema.GroupResource{Group: "kobs.io", Resource: "teams"}, "team1", fmt.Errorf("the object has been modified")))
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedBody:       `{"errors":["The team was modified, reload the team and try again"]}`,
+		},
+		{
+			name: "should return error when team could not be saved",
+			body: `{"cluster": "cluster1", "namespace": "namespace1", "name": "team1"}`,
+			prepare: func(kubernetesClient *kubernetes.MockClient) {
+				kubernetesClient.EXPECT().SaveTeam(gomock.Any(), teamv1.TeamSpec{Cluster: "cluster1", Namespace: "namespace1", Name: "team1"}).Return(nil, fmt.Errorf("unexpected error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `{"errors":["Failed to save team"]}`,
+		},
+		{
+			name: "should save team",
+			body: `{"cluster": "cluster1", "namespace": "namespace1", "name": "team1", "resourceVersion": "1"}`,
+			prepare: func(kubernetesClient *kubernetes.MockClient) {
+				kubernetesClient.EXPECT().SaveTeam(gomock.Any(), teamv1.TeamSpec{Cluster: "cluster1", Namespace: "namespace1", Name: "team1", ResourceVersion: "1"}).Return(&teamv1.TeamSpec{Cluster: "cluster1", Namespace: "namespace1", Name: "team1", ResourceVersion: "2"}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"cluster": "cluster1", "namespace": "namespace1", "name": "team1", "resourceVersion": "2", "permissions": {}}`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			kubernetesClient := kubernetes.NewMockClient(ctrl)
+			tt.prepare(kubernetesClient)
+
+			router := Router{chi.NewRouter(), kubernetesClient, defaultTracer}
+
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodPut, "/", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			router.saveTeam(w, req)
+
+			utils.AssertStatusEq(t, w, tt.expectedStatusCode)
+			utils.AssertJSONEq(t, w, tt.expectedBody)
+		})
+	}
+}
+
 func TestMount(t *testing.T) {
 	router := Mount(nil)
 	require.NotNil(t, router)
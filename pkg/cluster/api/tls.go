@@ -0,0 +1,76 @@
+package api
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+
+	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
+	"github.com/kobsio/kobs/pkg/utils/reload"
+)
+
+type TLSConfig struct {
+	CertFile     string `json:"certFile" env:"CERT_FILE" default:"" help:"The path to the certificate file, which is used to serve the cluster API via TLS. The file is reloaded when it is changed."`
+	KeyFile      string `json:"keyFile" env:"KEY_FILE" default:"" help:"The path to the key file, which is used to serve the cluster API via TLS. The file is reloaded when it is changed."`
+	ClientCAFile string `json:"clientCAFile" env:"CLIENT_CA_FILE" default:"" help:"The path to the CA file, which is used to verify the client certificate of the hub. When set, all requests must provide a valid client certificate. The file is reloaded when it is changed."`
+}
+
+// newTLSConfig returns the TLS configuration for the cluster API. If no certificate is configured, nil is returned
+// and the API is served without TLS. The certificate and the CA for the client certificates are reloaded when the files
+// are changed, so that they can be rotated without a restart.
+//
+// We only verify a client certificate if it is given, so that the health check can still be used without a client
+// certificate. Requests to all other endpoints are rejected via the requireClientCertificate middleware, when no valid
+// client certificate was provided.
+func newTLSConfig(config TLSConfig) (*tls.Config, error) {
+	if config.CertFile == "" && config.KeyFile == "" {
+		if config.ClientCAFile != "" {
+			return nil, fmt.Errorf("a certificate and key file are required to verify client certificates")
+		}
+		return nil, nil
+	}
+
+	certificate, err := reload.NewCertificate(config.CertFile, config.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certificate.GetCertificate,
+	}
+
+	if config.ClientCAFile != "" {
+		clientCAs, err := reload.NewCertPool(config.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		tlsConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return &tls.Config{
+				MinVersion:     tls.VersionTLS12,
+				GetCertificate: certificate.GetCertificate,
+				ClientAuth:     tls.VerifyClientCertIfGiven,
+				ClientCAs:      clientCAs.Get(),
+			}, nil
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// requireClientCertificate is a middleware, which rejects all requests without a verified client certificate, except
+// the requests for the health check.
+func requireClientCertificate(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/health" && (r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+			errresponse.Render(w, r, http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
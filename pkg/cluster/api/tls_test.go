@@ -0,0 +1,121 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// generateCertificates generates a CA and a server and client certificate signed by the CA in the provided directory.
+func generateCertificates(t *testing.T, dir string) {
+	var writePEM = func(name, pemType string, data []byte) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: data}), 0600))
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kobs-ca"},
+		NotBefore:             time.Now().Add(-1 * time.Hour),
+		NotAfter:              time.Now().Add(1 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	writePEM("ca.pem", "CERTIFICATE", caDER)
+
+	for i, name := range []string{"server", "client"} {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(int64(i + 2)),
+			Subject:      pkix.Name{CommonName: name},
+			DNSNames:     []string{"localhost"},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+			NotBefore:    time.Now().Add(-1 * time.Hour),
+			NotAfter:     time.Now().Add(1 * time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+		writePEM(name+".pem", "CERTIFICATE", der)
+		writePEM(name+"-key.pem", "EC PRIVATE KEY", keyDER)
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	generateCertificates(t, dir)
+
+	t.Run("should return nil without certificate", func(t *testing.T) {
+		tlsConfig, err := newTLSConfig(TLSConfig{})
+		require.NoError(t, err)
+		require.Nil(t, tlsConfig)
+	})
+
+	t.Run("should fail for client ca without certificate", func(t *testing.T) {
+		_, err := newTLSConfig(TLSConfig{ClientCAFile: filepath.Join(dir, "ca.pem")})
+		require.Error(t, err)
+	})
+
+	t.Run("should fail for invalid certificate", func(t *testing.T) {
+		_, err := newTLSConfig(TLSConfig{CertFile: filepath.Join(dir, "server.pem"), KeyFile: filepath.Join(dir, "not-existing.pem")})
+		require.Error(t, err)
+	})
+
+	t.Run("should require client certificate", func(t *testing.T) {
+		tlsConfig, err := newTLSConfig(TLSConfig{CertFile: filepath.Join(dir, "server.pem"), KeyFile: filepath.Join(dir, "server-key.pem"), ClientCAFile: filepath.Join(dir, "ca.pem")})
+		require.NoError(t, err)
+
+		router := chi.NewRouter()
+		router.Get("/api/health", func(w http.ResponseWriter, r *http.Request) {})
+		router.Get("/api/resources", func(w http.ResponseWriter, r *http.Request) {})
+
+		listener, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+		require.NoError(t, err)
+
+		server := &http.Server{Handler: requireClientCertificate(router), ReadHeaderTimeout: 3 * time.Second}
+		go server.Serve(listener)
+		defer server.Close()
+
+		caPEM, err := os.ReadFile(filepath.Join(dir, "ca.pem"))
+		require.NoError(t, err)
+		rootCAs := x509.NewCertPool()
+		rootCAs.AppendCertsFromPEM(caPEM)
+
+		clientCertificate, err := tls.LoadX509KeyPair(filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem"))
+		require.NoError(t, err)
+
+		var doRequest = func(path string, certificates []tls.Certificate) int {
+			httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs, Certificates: certificates}}}
+			resp, err := httpClient.Get("https://" + listener.Addr().String() + path)
+			require.NoError(t, err)
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+
+		require.Equal(t, http.StatusOK, doRequest("/api/health", nil))
+		require.Equal(t, http.StatusUnauthorized, doRequest("/api/resources", nil))
+		require.Equal(t, http.StatusOK, doRequest("/api/resources", []tls.Certificate{clientCertificate}))
+	})
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/kobsio/kobs/pkg/instrument/log"
+	"github.com/kobsio/kobs/pkg/utils/tunnel"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	tunnelMinBackoff = 1 * time.Second
+	tunnelMaxBackoff = 30 * time.Second
+)
+
+type TunnelConfig struct {
+	Address string `json:"address" env:"ADDRESS" default:"" help:"The address of the tunnel API of the hub, e.g. wss://kobs.example.com/api/tunnel. When set the cluster connects to the hub via a tunnel, instead of waiting for requests from the hub."`
+	Name    string `json:"name" env:"NAME" default:"" help:"The name of the cluster, which is used to identify the tunnel in the hub."`
+}
+
+// agent opens a tunnel to the hub and serves all requests, which are sent by the hub through the tunnel, via the
+// provided handler. When the tunnel is closed the agent reconnects to the hub, until the agent is stopped.
+type agent struct {
+	config  TunnelConfig
+	token   func() string
+	handler http.Handler
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// Start connects to the hub and serves the tunnel. If the connection fails or the tunnel is closed, we wait before we
+// reconnect to the hub. The time to wait is doubled after each failed connection attempt up to a maximum of 30
+// seconds.
+func (a *agent) Start() {
+	log.Info(a.ctx, "Tunnel agent started", zap.String("address", a.config.Address), zap.String("cluster", a.config.Name))
+
+	backoff := tunnelMinBackoff
+
+	for {
+		connected, err := a.serve()
+		if err != nil {
+			log.Warn(a.ctx, "Tunnel to hub failed", zap.Error(err), zap.String("address", a.config.Address))
+		}
+
+		if connected {
+			backoff = tunnelMinBackoff
+		}
+
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, tunnelMaxBackoff)
+	}
+}
+
+// serve opens a single tunnel to the hub and serves all requests until the tunnel is closed. It returns true if the
+// connection to the hub was established.
+func (a *agent) serve() (bool, error) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+a.token())
+	header.Set(tunnel.ClusterHeader, a.config.Name)
+
+	conn, _, err := websocket.DefaultDialer.DialContext(a.ctx, a.config.Address, header)
+	if err != nil {
+		return false, err
+	}
+
+	session := tunnel.NewSession(conn, false)
+	log.Info(a.ctx, "Tunnel to hub connected", zap.String("address", a.config.Address))
+
+	go func() {
+		select {
+		case <-a.ctx.Done():
+			session.Close()
+		case <-session.Done():
+		}
+	}()
+
+	server := &http.Server{
+		Handler:           a.handler,
+		ReadHeaderTimeout: 3 * time.Second,
+	}
+
+	err = server.Serve(session)
+	if err == tunnel.ErrSessionClosed {
+		log.Info(a.ctx, "Tunnel to hub disconnected", zap.String("address", a.config.Address))
+		return true, nil
+	}
+
+	return true, err
+}
+
+// Stop closes the tunnel to the hub.
+func (a *agent) Stop() {
+	a.cancel()
+}
+
+func newAgent(config TunnelConfig, token func() string, handler http.Handler) *agent {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &agent{
+		config:  config,
+		token:   token,
+		handler: handler,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
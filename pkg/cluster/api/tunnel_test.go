@@ -0,0 +1,70 @@
+package api
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kobsio/kobs/pkg/utils/tunnel"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent(t *testing.T) {
+	sessions := make(chan *tunnel.Session, 1)
+
+	hubServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get(tunnel.ClusterHeader) != "cluster1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		sessions <- tunnel.NewSession(conn, true)
+	}))
+	defer hubServer.Close()
+
+	a := newAgent(TunnelConfig{Address: "ws" + strings.TrimPrefix(hubServer.URL, "http"), Name: "cluster1"}, func() string { return "token" }, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello from " + r.URL.Path))
+	}))
+	go a.Start()
+	defer a.Stop()
+
+	var session *tunnel.Session
+	select {
+	case session = <-sessions:
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent did not connect")
+	}
+
+	httpClient := &http.Client{Transport: &http.Transport{Dial: func(network, addr string) (net.Conn, error) {
+		return session.Open()
+	}}}
+
+	resp, err := httpClient.Get("http://tunnel/api/health")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "hello from /api/health", string(body))
+
+	session.Close()
+
+	select {
+	case session = <-sessions:
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent did not reconnect")
+	}
+	session.Close()
+}
@@ -1,10 +1,12 @@
 package users
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 
 	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
 	"github.com/kobsio/kobs/pkg/instrument/log"
 	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
 
@@ -15,6 +17,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 // Router implements the chi.Router interface, but also contains a tracer and a Kubernetes client which can be used in
@@ -62,6 +65,55 @@ func (router *Router) getUsers(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, users)
 }
 
+// saveUser creates or updates the User CR for the user from the request body. The cluster, namespace and name of the
+// user are required, while the ID of the user is saved in the CR, because it is used to match the user on sign in. Same
+// as for applications, a conflict error is returned when the resource version of the user doesn't match the CR anymore
+// or when a new CR already exists.
+func (router *Router) saveUser(w http.ResponseWriter, r *http.Request) {
+	ctx, span := router.tracer.Start(r.Context(), "saveUser")
+	defer span.End()
+
+	var user userv1.UserSpec
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to decode request body", zap.Error(err))
+		errresponse.Render(w, r, http.StatusBadRequest, "Failed to decode request body")
+		return
+	}
+
+	span.SetAttributes(attribute.Key("cluster").String(user.Cluster))
+	span.SetAttributes(attribute.Key("namespace").String(user.Namespace))
+	span.SetAttributes(attribute.Key("name").String(user.Name))
+	log.Debug(ctx, "Save user", zap.String("cluster", user.Cluster), zap.String("namespace", user.Namespace), zap.String("name", user.Name), zap.String("resourceVersion", user.ResourceVersion))
+
+	if user.Cluster == "" || user.Namespace == "" || user.Name == "" {
+		err := fmt.Errorf("invalid user data")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to save user", zap.Error(err))
+		errresponse.Render(w, r, http.StatusBadRequest, "Invalid user data")
+		return
+	}
+
+	savedUser, err := router.kubernetesClient.SaveUser(ctx, user)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to save user", zap.Error(err))
+
+		if apierrors.IsConflict(err) {
+			errresponse.Render(w, r, http.StatusConflict, "The user was modified, reload the user and try again")
+			return
+		}
+
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to save user")
+		return
+	}
+
+	render.JSON(w, r, savedUser)
+}
+
 // Mount returns a chi.Router which handles all user related API endpoints.
 func Mount(kubernetesClient kubernetes.Client) chi.Router {
 	router := Router{
@@ -71,6 +123,7 @@ func Mount(kubernetesClient kubernetes.Client) chi.Router {
 	}
 
 	router.Get("/", router.getUsers)
+	router.Put("/", router.saveUser)
 
 	return router
 }
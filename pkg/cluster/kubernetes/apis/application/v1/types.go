@@ -28,18 +28,19 @@ type ApplicationList struct {
 }
 
 type ApplicationSpec struct {
-	ID          string                  `json:"id,omitempty" bson:"_id"`
-	UpdatedAt   int64                   `json:"updatedAt,omitempty" bson:"updatedAt"`
-	Cluster     string                  `json:"cluster,omitempty" bson:"cluster"`
-	Namespace   string                  `json:"namespace,omitempty" bson:"namespace"`
-	Name        string                  `json:"name,omitempty" bson:"name"`
-	Description string                  `json:"description,omitempty" bson:"description"`
-	Tags        []string                `json:"tags,omitempty" bson:"tags"`
-	Links       []Link                  `json:"links,omitempty" bson:"links"`
-	Teams       []string                `json:"teams,omitempty" bson:"teams"`
-	Topology    Topology                `json:"topology,omitempty" bson:"topology"`
-	Insights    []Insight               `json:"insights,omitempty" bson:"insights"`
-	Dashboards  []dashboardv1.Reference `json:"dashboards,omitempty" bson:"dashboards"`
+	ID              string                  `json:"id,omitempty" bson:"_id"`
+	UpdatedAt       int64                   `json:"updatedAt,omitempty" bson:"updatedAt"`
+	ResourceVersion string                  `json:"resourceVersion,omitempty" bson:"resourceVersion"`
+	Cluster         string                  `json:"cluster,omitempty" bson:"cluster"`
+	Namespace       string                  `json:"namespace,omitempty" bson:"namespace"`
+	Name            string                  `json:"name,omitempty" bson:"name"`
+	Description     string                  `json:"description,omitempty" bson:"description"`
+	Tags            []string                `json:"tags,omitempty" bson:"tags"`
+	Links           []Link                  `json:"links,omitempty" bson:"links"`
+	Teams           []string                `json:"teams,omitempty" bson:"teams"`
+	Topology        Topology                `json:"topology,omitempty" bson:"topology"`
+	Insights        []Insight               `json:"insights,omitempty" bson:"insights"`
+	Dashboards      []dashboardv1.Reference `json:"dashboards,omitempty" bson:"dashboards"`
 }
 
 type Link struct {
@@ -65,4 +66,15 @@ type Insight struct {
 	Unit     string             `json:"unit,omitempty" bson:"unit"`
 	Mappings map[string]string  `json:"mappings,omitempty" bson:"mappings"`
 	Plugin   dashboardv1.Plugin `json:"plugin" bson:"plugin"`
+	Health   *InsightHealth     `json:"health,omitempty" bson:"health"`
+}
+
+// InsightHealth defines the thresholds for the last value of an insight, which are used by the hub to compute the
+// health of an application. With the default operator (">"), the insight is degraded / unhealthy when the last value
+// is greater than the degraded / unhealthy threshold. The thresholds are strings, so that they can contain floating
+// point numbers.
+type InsightHealth struct {
+	Operator  string `json:"operator,omitempty" bson:"operator"`
+	Degraded  string `json:"degraded,omitempty" bson:"degraded"`
+	Unhealthy string `json:"unhealthy,omitempty" bson:"unhealthy"`
 }
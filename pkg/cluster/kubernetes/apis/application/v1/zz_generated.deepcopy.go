@@ -143,6 +143,11 @@ func (in *Insight) DeepCopyInto(out *Insight) {
 		}
 	}
 	in.Plugin.DeepCopyInto(&out.Plugin)
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = new(InsightHealth)
+		**out = **in
+	}
 	return
 }
 
@@ -156,6 +161,22 @@ func (in *Insight) DeepCopy() *Insight {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InsightHealth) DeepCopyInto(out *InsightHealth) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InsightHealth.
+func (in *InsightHealth) DeepCopy() *InsightHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(InsightHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Link) DeepCopyInto(out *Link) {
 	*out = *in
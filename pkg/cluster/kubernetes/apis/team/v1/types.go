@@ -29,16 +29,17 @@ type TeamList struct {
 }
 
 type TeamSpec struct {
-	ID          string                  `json:"id,omitempty" bson:"_id"`
-	UpdatedAt   int64                   `json:"updatedAt,omitempty" bson:"updatedAt"`
-	Cluster     string                  `json:"cluster,omitempty" bson:"cluster"`
-	Namespace   string                  `json:"namespace,omitempty" bson:"namespace"`
-	Name        string                  `json:"name,omitempty" bson:"name"`
-	Description string                  `json:"description,omitempty" bson:"description"`
-	Links       []Link                  `json:"links,omitempty" bson:"links"`
-	Logo        string                  `json:"logo,omitempty" bson:"logo"`
-	Permissions userv1.Permissions      `json:"permissions,omitempty" bson:"permissions"`
-	Dashboards  []dashboardv1.Reference `json:"dashboards,omitempty" bson:"dashboards"`
+	ID              string                  `json:"id,omitempty" bson:"_id"`
+	UpdatedAt       int64                   `json:"updatedAt,omitempty" bson:"updatedAt"`
+	ResourceVersion string                  `json:"resourceVersion,omitempty" bson:"resourceVersion"`
+	Cluster         string                  `json:"cluster,omitempty" bson:"cluster"`
+	Namespace       string                  `json:"namespace,omitempty" bson:"namespace"`
+	Name            string                  `json:"name,omitempty" bson:"name"`
+	Description     string                  `json:"description,omitempty" bson:"description"`
+	Links           []Link                  `json:"links,omitempty" bson:"links"`
+	Logo            string                  `json:"logo,omitempty" bson:"logo"`
+	Permissions     userv1.Permissions      `json:"permissions,omitempty" bson:"permissions"`
+	Dashboards      []dashboardv1.Reference `json:"dashboards,omitempty" bson:"dashboards"`
 }
 
 type Link struct {
@@ -29,17 +29,18 @@ type UserList struct {
 }
 
 type UserSpec struct {
-	ID          string                  `json:"id,omitempty" bson:"_id"`
-	UpdatedAt   int64                   `json:"updatedAt,omitempty" bson:"updatedAt"`
-	Cluster     string                  `json:"cluster,omitempty" bson:"cluster"`
-	Namespace   string                  `json:"namespace,omitempty" bson:"namespace"`
-	Name        string                  `json:"name,omitempty" bson:"name"`
-	DisplayName string                  `json:"displayName,omitempty" bson:"displayName"`
-	Password    string                  `json:"password,omitempty" bson:"password"`
-	Teams       []string                `json:"teams,omitempty" bson:"teams"`
-	Permissions Permissions             `json:"permissions,omitempty" bson:"permissions"`
-	Dashboards  []dashboardv1.Reference `json:"dashboards,omitempty" bson:"dashboards"`
-	Navigation  []Navigation            `json:"navigation,omitempty" bson:"navigation"`
+	ID              string                  `json:"id,omitempty" bson:"_id"`
+	UpdatedAt       int64                   `json:"updatedAt,omitempty" bson:"updatedAt"`
+	ResourceVersion string                  `json:"resourceVersion,omitempty" bson:"resourceVersion"`
+	Cluster         string                  `json:"cluster,omitempty" bson:"cluster"`
+	Namespace       string                  `json:"namespace,omitempty" bson:"namespace"`
+	Name            string                  `json:"name,omitempty" bson:"name"`
+	DisplayName     string                  `json:"displayName,omitempty" bson:"displayName"`
+	Password        string                  `json:"password,omitempty" bson:"password"`
+	Teams           []string                `json:"teams,omitempty" bson:"teams"`
+	Permissions     Permissions             `json:"permissions,omitempty" bson:"permissions"`
+	Dashboards      []dashboardv1.Reference `json:"dashboards,omitempty" bson:"dashboards"`
+	Navigation      []Navigation            `json:"navigation,omitempty" bson:"navigation"`
 }
 
 type Permissions struct {
@@ -67,6 +68,7 @@ type Resources struct {
 	Namespaces []string `json:"namespaces" bson:"namespaces"`
 	Resources  []string `json:"resources" bson:"resources"`
 	Verbs      []string `json:"verbs" bson:"verbs"`
+	Deny       bool     `json:"deny,omitempty" bson:"deny"`
 }
 
 type Navigation struct {
@@ -0,0 +1,365 @@
+package copy
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+var (
+	// ErrArchiveTooLarge is returned when the size of all files in an archive exceeds the maximum size.
+	ErrArchiveTooLarge = errors.New("archive exceeds the maximum size")
+	// ErrInvalidArchive is returned when an uploaded archive can not be read or contains an entry which is not allowed,
+	// e.g. a path which is outside of the destination directory.
+	ErrInvalidArchive = errors.New("invalid archive")
+	// ErrInvalidPath is returned when the destination path for an archive is not allowed, e.g. because it starts with
+	// "-" and would be parsed as an option by tar.
+	ErrInvalidPath = errors.New("invalid path")
+)
+
+// ArchiveStats contains the number of files and directories and the size of all files in an archive. Entries which
+// were skipped, because they are not safe to extract, are counted separately.
+type ArchiveStats struct {
+	Files   int   `json:"files"`
+	Size    int64 `json:"size"`
+	Skipped int   `json:"skipped"`
+}
+
+// Progress is called after each entry of an archive was processed, with the stats of all processed entries.
+type Progress func(stats ArchiveStats)
+
+// DirectoryFromPod let a user download a directory or file from a container as tar.gz archive. The command from the
+// request URL must write a tar archive to stdout. The archive is compressed into a temporary file first, so that the
+// maximum size can be checked before the response is written and so that we can set the "Content-Length" header.
+//
+// Symlinks are not followed. Entries with a path or a link target outside of the archive are skipped, so that the
+// archive can be extracted safely.
+func DirectoryFromPod(ctx context.Context, w http.ResponseWriter, config *rest.Config, reqURL *url.URL, filename string, maxSize int64, progress Progress) (ArchiveStats, error) {
+	exec, err := remotecommand.NewSPDYExecutor(config, http.MethodPost, reqURL)
+	if err != nil {
+		return ArchiveStats{}, err
+	}
+
+	tmpFile, err := os.CreateTemp("", "kobs-copy-*.tar.gz")
+	if err != nil {
+		return ArchiveStats{}, err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	reader, writer := io.Pipe()
+
+	go func() {
+		var stderr bytes.Buffer
+		err := exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+			Stdout: writer,
+			Stderr: &stderr,
+			Tty:    false,
+		})
+		if err != nil && stderr.Len() > 0 {
+			err = fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		writer.CloseWithError(err)
+	}()
+
+	stats, err := compressArchive(reader, tmpFile, maxSize, progress)
+	// Closing the reader stops the command in the container, when we return before the complete archive was read.
+	reader.CloseWithError(err)
+	if err != nil {
+		return stats, err
+	}
+
+	size, err := tmpFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return stats, err
+	}
+
+	if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
+		return stats, err
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.Header().Set("X-Kobs-Archive-Files", strconv.Itoa(stats.Files))
+	w.Header().Set("X-Kobs-Archive-Size", strconv.FormatInt(stats.Size, 10))
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, tmpFile); err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// ArchiveToPod let a user upload a tar or tar.gz archive to a container. The command from the request URL must read a
+// tar archive from stdin and extract it. The complete archive is validated before it is sent to the container, so that
+// nothing is extracted when the archive contains an entry which is not allowed or when it exceeds the maximum size.
+func ArchiveToPod(ctx context.Context, config *rest.Config, reqURL *url.URL, archive io.Reader, maxSize int64, progress Progress) (ArchiveStats, error) {
+	tmpFile, err := os.CreateTemp("", "kobs-copy-*.tar")
+	if err != nil {
+		return ArchiveStats{}, err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	stats, err := sanitizeArchive(archive, tmpFile, maxSize, progress)
+	if err != nil {
+		return stats, err
+	}
+
+	if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
+		return stats, err
+	}
+
+	exec, err := remotecommand.NewSPDYExecutor(config, http.MethodPost, reqURL)
+	if err != nil {
+		return stats, err
+	}
+
+	var stderr bytes.Buffer
+	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  tmpFile,
+		Stdout: io.Discard,
+		Stderr: &stderr,
+		Tty:    false,
+	})
+	if err != nil {
+		if stderr.Len() > 0 {
+			return stats, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// compressArchive reads the tar archive from the container and writes it as tar.gz archive to dst. Entries which are
+// not safe to extract are skipped. When the size of all files exceeds the maximum size ErrArchiveTooLarge is returned.
+func compressArchive(src io.Reader, dst io.Writer, maxSize int64, progress Progress) (ArchiveStats, error) {
+	var stats ArchiveStats
+
+	gzipWriter := gzip.NewWriter(dst)
+	tarWriter := tar.NewWriter(gzipWriter)
+	tarReader := tar.NewReader(src)
+	checker := newEntryChecker()
+
+	for {
+		header, err := tarReader.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return stats, err
+		}
+
+		if err := checker.check(header); err != nil {
+			stats.Skipped++
+			continue
+		}
+
+		stats.Files++
+		stats.Size += header.Size
+		if maxSize > 0 && stats.Size > maxSize {
+			return stats, ErrArchiveTooLarge
+		}
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return stats, err
+		}
+		if _, err := io.Copy(tarWriter, tarReader); err != nil {
+			return stats, err
+		}
+
+		if progress != nil {
+			progress(stats)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return stats, err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// sanitizeArchive reads the uploaded tar or tar.gz archive and writes it as tar archive to dst. The mode of all entries
+// is limited to the permission bits, so that no setuid or setgid files can be created. If an entry is not safe to
+// extract an error wrapping ErrInvalidArchive is returned. When the size of all files exceeds the maximum size
+// ErrArchiveTooLarge is returned.
+func sanitizeArchive(src io.Reader, dst io.Writer, maxSize int64, progress Progress) (ArchiveStats, error) {
+	var stats ArchiveStats
+
+	bufferedSrc := bufio.NewReader(src)
+	var archive io.Reader = bufferedSrc
+
+	// A gzip compressed archive always starts with the magic number 0x1f 0x8b.
+	if magic, err := bufferedSrc.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(bufferedSrc)
+		if err != nil {
+			return stats, fmt.Errorf("%w: %s", ErrInvalidArchive, err.Error())
+		}
+		defer gzipReader.Close()
+		archive = gzipReader
+	}
+
+	tarWriter := tar.NewWriter(dst)
+	tarReader := tar.NewReader(archive)
+	checker := newEntryChecker()
+
+	for {
+		header, err := tarReader.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return stats, fmt.Errorf("%w: %s", ErrInvalidArchive, err.Error())
+		}
+
+		if err := checker.check(header); err != nil {
+			return stats, fmt.Errorf("%w: %s", ErrInvalidArchive, err.Error())
+		}
+
+		stats.Files++
+		stats.Size += header.Size
+		if maxSize > 0 && stats.Size > maxSize {
+			return stats, ErrArchiveTooLarge
+		}
+
+		header.Mode &= 0o777
+		header.Uid = 0
+		header.Gid = 0
+		header.Uname = ""
+		header.Gname = ""
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return stats, err
+		}
+		if _, err := io.Copy(tarWriter, tarReader); err != nil {
+			return stats, fmt.Errorf("%w: %s", ErrInvalidArchive, err.Error())
+		}
+
+		if progress != nil {
+			progress(stats)
+		}
+	}
+
+	if stats.Files == 0 {
+		return stats, fmt.Errorf("%w: archive is empty", ErrInvalidArchive)
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// entryChecker checks if the entries of an archive can be safely extracted. It remembers the symlinks of all checked
+// entries, because a symlink created by an earlier entry can be used to write a later entry outside of the directory
+// where the archive is extracted, e.g. "s -> .", "s/t -> .." and "s/t/file". Not all tar implementations (e.g. the one
+// from busybox) are protecting against this, so that we reject all entries which are using a symlink from the archive
+// in their path or link target.
+type entryChecker struct {
+	symlinks map[string]bool
+}
+
+// check checks if the entry of an archive can be safely extracted. This is the case for regular files, directories and
+// links, when the path and the link target are within the directory where the archive is extracted and when they do
+// not go through a symlink from the archive.
+func (c *entryChecker) check(header *tar.Header) error {
+	if !isLocalPath(header.Name) {
+		return fmt.Errorf("path %s is outside of the destination", header.Name)
+	}
+
+	name := path.Clean(header.Name)
+	dir := rawDir(header.Name)
+	if c.hasSymlink(dir) {
+		return fmt.Errorf("path %s contains a symlink", header.Name)
+	}
+
+	switch header.Typeflag {
+	case tar.TypeReg, tar.TypeDir:
+		delete(c.symlinks, name)
+		return nil
+	case tar.TypeSymlink:
+		target := path.Join(path.Dir(name), header.Linkname)
+		if path.IsAbs(header.Linkname) || !isLocalPath(target) || c.hasSymlink(dir+header.Linkname) {
+			return fmt.Errorf("symlink %s points to %s, which is outside of the destination", header.Name, header.Linkname)
+		}
+		c.symlinks[name] = true
+		return nil
+	case tar.TypeLink:
+		if !isLocalPath(header.Linkname) || c.hasSymlink(header.Linkname) {
+			return fmt.Errorf("hard link %s points to %s, which is outside of the destination", header.Name, header.Linkname)
+		}
+		delete(c.symlinks, name)
+		return nil
+	default:
+		return fmt.Errorf("type of %s is not supported", header.Name)
+	}
+}
+
+// hasSymlink returns true when the provided path goes through one of the symlinks from the archive. The path is walked
+// component by component without cleaning it first, so that a path like "s/.." is detected, when "s" is a symlink.
+func (c *entryChecker) hasSymlink(p string) bool {
+	if len(c.symlinks) == 0 {
+		return false
+	}
+
+	var components []string
+	for _, component := range strings.Split(p, "/") {
+		switch component {
+		case "", ".":
+			continue
+		case "..":
+			if len(components) > 0 {
+				components = components[:len(components)-1]
+			}
+		default:
+			components = append(components, component)
+			if c.symlinks[strings.Join(components, "/")] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// rawDir returns the directory of the provided path including the trailing slash. In contrast to "path.Dir" the path is
+// not cleaned, so that it can be passed to "hasSymlink".
+func rawDir(p string) string {
+	return p[:strings.LastIndex(strings.TrimRight(p, "/"), "/")+1]
+}
+
+func newEntryChecker() *entryChecker {
+	return &entryChecker{symlinks: make(map[string]bool)}
+}
+
+// isLocalPath returns true when the provided path is relative and doesn't leave the current directory.
+func isLocalPath(p string) bool {
+	if p == "" || path.IsAbs(p) {
+		return false
+	}
+
+	p = path.Clean(p)
+	return p != ".." && !strings.HasPrefix(p, "../")
+}
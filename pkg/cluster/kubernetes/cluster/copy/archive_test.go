@@ -0,0 +1,231 @@
+package copy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type entry struct {
+	header  tar.Header
+	content string
+}
+
+func newArchive(t *testing.T, compress bool, entries ...entry) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+
+	var w io.Writer = buf
+	var gzipWriter *gzip.Writer
+	if compress {
+		gzipWriter = gzip.NewWriter(buf)
+		w = gzipWriter
+	}
+
+	tarWriter := tar.NewWriter(w)
+	for _, e := range entries {
+		header := e.header
+		header.Size = int64(len(e.content))
+		if header.Mode == 0 {
+			header.Mode = 0o644
+		}
+		require.NoError(t, tarWriter.WriteHeader(&header))
+		_, err := tarWriter.Write([]byte(e.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+
+	if gzipWriter != nil {
+		require.NoError(t, gzipWriter.Close())
+	}
+
+	return buf
+}
+
+func readArchive(t *testing.T, r io.Reader) map[string]tar.Header {
+	headers := make(map[string]tar.Header)
+
+	tarReader := tar.NewReader(r)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		headers[header.Name] = *header
+	}
+
+	return headers
+}
+
+func TestCompressArchive(t *testing.T) {
+	t.Run("should compress archive and skip unsafe entries", func(t *testing.T) {
+		src := newArchive(t, false,
+			entry{header: tar.Header{Name: "config/", Typeflag: tar.TypeDir, Mode: 0o755}},
+			entry{header: tar.Header{Name: "config/..data/app.yaml", Typeflag: tar.TypeReg}, content: "key: value"},
+			entry{header: tar.Header{Name: "config/app.yaml", Typeflag: tar.TypeSymlink, Linkname: "..data/app.yaml"}},
+			entry{header: tar.Header{Name: "config/passwd", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}},
+			entry{header: tar.Header{Name: "config/parent", Typeflag: tar.TypeSymlink, Linkname: "../../etc"}},
+			entry{header: tar.Header{Name: "../escape", Typeflag: tar.TypeReg}, content: "escape"},
+			entry{header: tar.Header{Name: "config/self", Typeflag: tar.TypeSymlink, Linkname: "."}},
+			entry{header: tar.Header{Name: "config/self/escape", Typeflag: tar.TypeSymlink, Linkname: ".."}},
+		)
+
+		var progress []ArchiveStats
+		dst := &bytes.Buffer{}
+		stats, err := compressArchive(src, dst, 0, func(stats ArchiveStats) { progress = append(progress, stats) })
+		require.NoError(t, err)
+		require.Equal(t, ArchiveStats{Files: 4, Size: 10, Skipped: 4}, stats)
+		require.Len(t, progress, 4)
+
+		gzipReader, err := gzip.NewReader(dst)
+		require.NoError(t, err)
+
+		headers := readArchive(t, gzipReader)
+		require.Len(t, headers, 4)
+		require.Contains(t, headers, "config/")
+		require.Contains(t, headers, "config/..data/app.yaml")
+		require.Equal(t, "..data/app.yaml", headers["config/app.yaml"].Linkname)
+	})
+
+	t.Run("should fail when archive is too large", func(t *testing.T) {
+		src := newArchive(t, false, entry{header: tar.Header{Name: "heap.hprof", Typeflag: tar.TypeReg}, content: "0123456789"})
+
+		_, err := compressArchive(src, io.Discard, 5, nil)
+		require.ErrorIs(t, err, ErrArchiveTooLarge)
+	})
+
+	t.Run("should fail for invalid archive", func(t *testing.T) {
+		_, err := compressArchive(bytes.NewBufferString("tar: /tmp: No such file or directory"), io.Discard, 0, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestSanitizeArchive(t *testing.T) {
+	t.Run("should sanitize tar archive", func(t *testing.T) {
+		src := newArchive(t, false,
+			entry{header: tar.Header{Name: "./bin/", Typeflag: tar.TypeDir, Mode: 0o755}},
+			entry{header: tar.Header{Name: "./bin/tool", Typeflag: tar.TypeReg, Mode: 0o4755, Uid: 1000, Uname: "user"}, content: "tool"},
+			entry{header: tar.Header{Name: "./bin/link", Typeflag: tar.TypeLink, Linkname: "bin/tool"}},
+		)
+
+		dst := &bytes.Buffer{}
+		stats, err := sanitizeArchive(src, dst, 0, nil)
+		require.NoError(t, err)
+		require.Equal(t, ArchiveStats{Files: 3, Size: 4}, stats)
+
+		headers := readArchive(t, dst)
+		require.Equal(t, int64(0o755), headers["./bin/tool"].Mode)
+		require.Equal(t, 0, headers["./bin/tool"].Uid)
+		require.Equal(t, "", headers["./bin/tool"].Uname)
+	})
+
+	t.Run("should sanitize tar.gz archive", func(t *testing.T) {
+		src := newArchive(t, true, entry{header: tar.Header{Name: "app.yaml", Typeflag: tar.TypeReg}, content: "key: value"})
+
+		dst := &bytes.Buffer{}
+		stats, err := sanitizeArchive(src, dst, 0, nil)
+		require.NoError(t, err)
+		require.Equal(t, ArchiveStats{Files: 1, Size: 10}, stats)
+		require.Contains(t, readArchive(t, dst), "app.yaml")
+	})
+
+	for _, tt := range []struct {
+		name  string
+		entry entry
+	}{
+		{name: "should reject path traversal", entry: entry{header: tar.Header{Name: "../../etc/passwd", Typeflag: tar.TypeReg}}},
+		{name: "should reject absolute path", entry: entry{header: tar.Header{Name: "/etc/passwd", Typeflag: tar.TypeReg}}},
+		{name: "should reject absolute symlink", entry: entry{header: tar.Header{Name: "passwd", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}}},
+		{name: "should reject symlink outside of destination", entry: entry{header: tar.Header{Name: "dir/etc", Typeflag: tar.TypeSymlink, Linkname: "../../etc"}}},
+		{name: "should reject hard link outside of destination", entry: entry{header: tar.Header{Name: "passwd", Typeflag: tar.TypeLink, Linkname: "../etc/passwd"}}},
+		{name: "should reject device", entry: entry{header: tar.Header{Name: "null", Typeflag: tar.TypeChar}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sanitizeArchive(newArchive(t, false, tt.entry), io.Discard, 0, nil)
+			require.ErrorIs(t, err, ErrInvalidArchive)
+		})
+	}
+
+	for _, tt := range []struct {
+		name    string
+		entries []entry
+	}{
+		{
+			name: "should reject path through symlink chain",
+			entries: []entry{
+				{header: tar.Header{Name: "s", Typeflag: tar.TypeSymlink, Linkname: "."}},
+				{header: tar.Header{Name: "s/t", Typeflag: tar.TypeSymlink, Linkname: ".."}},
+				{header: tar.Header{Name: "s/t/evil", Typeflag: tar.TypeReg}, content: "evil"},
+			},
+		},
+		{
+			name: "should reject file in symlinked directory",
+			entries: []entry{
+				{header: tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0o755}},
+				{header: tar.Header{Name: "dir/link", Typeflag: tar.TypeSymlink, Linkname: "."}},
+				{header: tar.Header{Name: "./dir/link/file", Typeflag: tar.TypeReg}, content: "file"},
+			},
+		},
+		{
+			name: "should reject parent directory of symlink",
+			entries: []entry{
+				{header: tar.Header{Name: "a/b/s", Typeflag: tar.TypeSymlink, Linkname: "../.."}},
+				{header: tar.Header{Name: "a/b/s/../x", Typeflag: tar.TypeReg}, content: "evil"},
+			},
+		},
+		{
+			name: "should reject symlink target through symlink",
+			entries: []entry{
+				{header: tar.Header{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "."}},
+				{header: tar.Header{Name: "b", Typeflag: tar.TypeSymlink, Linkname: "a/.."}},
+			},
+		},
+		{
+			name: "should reject hard link through symlink",
+			entries: []entry{
+				{header: tar.Header{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "."}},
+				{header: tar.Header{Name: "b", Typeflag: tar.TypeLink, Linkname: "a/file"}},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sanitizeArchive(newArchive(t, false, tt.entries...), io.Discard, 0, nil)
+			require.ErrorIs(t, err, ErrInvalidArchive)
+		})
+	}
+
+	t.Run("should allow symlink after directory with the same name", func(t *testing.T) {
+		src := newArchive(t, false,
+			entry{header: tar.Header{Name: "config/", Typeflag: tar.TypeDir, Mode: 0o755}},
+			entry{header: tar.Header{Name: "config/..data/", Typeflag: tar.TypeDir, Mode: 0o755}},
+			entry{header: tar.Header{Name: "config/..data/app.yaml", Typeflag: tar.TypeReg}, content: "key: value"},
+			entry{header: tar.Header{Name: "config/app.yaml", Typeflag: tar.TypeSymlink, Linkname: "..data/app.yaml"}},
+		)
+
+		stats, err := sanitizeArchive(src, io.Discard, 0, nil)
+		require.NoError(t, err)
+		require.Equal(t, ArchiveStats{Files: 4, Size: 10}, stats)
+	})
+
+	t.Run("should fail when archive is too large", func(t *testing.T) {
+		src := newArchive(t, true, entry{header: tar.Header{Name: "heap.hprof", Typeflag: tar.TypeReg}, content: "0123456789"})
+
+		_, err := sanitizeArchive(src, io.Discard, 5, nil)
+		require.ErrorIs(t, err, ErrArchiveTooLarge)
+	})
+
+	t.Run("should fail for empty archive", func(t *testing.T) {
+		_, err := sanitizeArchive(newArchive(t, false), io.Discard, 0, nil)
+		require.ErrorIs(t, err, ErrInvalidArchive)
+	})
+
+	t.Run("should fail for invalid gzip archive", func(t *testing.T) {
+		_, err := sanitizeArchive(bytes.NewBuffer([]byte{0x1f, 0x8b, 0x00}), io.Discard, 0, nil)
+		require.ErrorIs(t, err, ErrInvalidArchive)
+	})
+}
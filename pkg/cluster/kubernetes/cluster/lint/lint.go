@@ -0,0 +1,147 @@
+package lint
+
+import (
+	"context"
+	"fmt"
+
+	dashboardv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/dashboard/v1"
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/validation"
+)
+
+// Problem is a single problem found in a manifest. It contains the file, line and column of the problem and the kind,
+// namespace and name of the CR, so that it can be reported in a human readable and a machine readable format. Problems
+// which are marked as warning are reported, but the manifests are still valid.
+type Problem struct {
+	File      string `json:"file"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Field     string `json:"field,omitempty"`
+	Message   string `json:"message"`
+	Warning   bool   `json:"warning,omitempty"`
+}
+
+func (p Problem) String() string {
+	position := p.File
+	if p.Line > 0 {
+		position = fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+	}
+	if p.Warning {
+		position = position + ": warning"
+	}
+
+	if p.Kind == "" {
+		return fmt.Sprintf("%s: %s", position, p.Message)
+	}
+
+	if p.Field == "" {
+		return fmt.Sprintf("%s: %s %s/%s: %s", position, p.Kind, p.Namespace, p.Name, p.Message)
+	}
+
+	return fmt.Sprintf("%s: %s %s/%s: %s: %s", position, p.Kind, p.Namespace, p.Name, p.Field, p.Message)
+}
+
+// Lint validates the given manifests with the same checks as the validating admission webhook of the cluster. Because
+// the manifests are checked without a cluster, dashboard references are resolved against the dashboards in the given
+// manifests. We also check that the kind, namespace and name of each CR is unique.
+//
+// Referenced teams which are not defined in the manifests or in the list of known teams are reported as warnings,
+// because the teams are often defined in another repository or cluster.
+func Lint(ctx context.Context, manifests []Manifest, pluginTypes []string, knownTeams []string) []Problem {
+	var problems []Problem
+
+	dashboards := make(map[string]*dashboardv1.DashboardSpec)
+	teams := make(map[string]bool)
+	seen := make(map[string]bool)
+
+	for _, team := range knownTeams {
+		teams[team] = true
+	}
+
+	for _, manifest := range manifests {
+		key := fmt.Sprintf("%s/%s/%s", manifest.Kind, manifest.Namespace, manifest.Name)
+		if seen[key] {
+			problems = append(problems, newProblem(manifest, "metadata.name", fmt.Sprintf("duplicate %s %s/%s", manifest.Kind, manifest.Namespace, manifest.Name)))
+			continue
+		}
+		seen[key] = true
+
+		if manifest.Dashboard != nil {
+			dashboards[fmt.Sprintf("%s/%s", manifest.Namespace, manifest.Name)] = manifest.Dashboard
+		}
+		if manifest.Team != nil && manifest.Team.ID != "" {
+			teams[manifest.Team.ID] = true
+		}
+	}
+
+	validator := validation.New(pluginTypes, func(ctx context.Context, namespace, name string) (*dashboardv1.DashboardSpec, error) {
+		return dashboards[fmt.Sprintf("%s/%s", namespace, name)], nil
+	})
+
+	for _, manifest := range manifests {
+		var errs validation.Errors
+		var warnings validation.Errors
+
+		if manifest.Name == "" {
+			errs = append(errs, validation.Error{Field: "metadata.name", Message: "name is required"})
+		}
+
+		switch {
+		case manifest.Application != nil:
+			errs = append(errs, validator.ValidateApplication(ctx, manifest.Namespace, manifest.Name, *manifest.Application)...)
+			warnings = append(warnings, validateTeams("spec.teams", manifest.Application.Teams, teams)...)
+		case manifest.Dashboard != nil:
+			errs = append(errs, validator.ValidateDashboard(ctx, *manifest.Dashboard)...)
+		case manifest.Team != nil:
+			errs = append(errs, validator.ValidateTeam(ctx, manifest.Namespace, *manifest.Team)...)
+			warnings = append(warnings, validateTeams("spec.permissions.teams", manifest.Team.Permissions.Teams, teams)...)
+		case manifest.User != nil:
+			errs = append(errs, validator.ValidateUser(ctx, manifest.Namespace, *manifest.User)...)
+			warnings = append(warnings, validateTeams("spec.teams", manifest.User.Teams, teams)...)
+			warnings = append(warnings, validateTeams("spec.permissions.teams", manifest.User.Permissions.Teams, teams)...)
+		}
+
+		for _, err := range errs {
+			problems = append(problems, newProblem(manifest, err.Field, err.Message))
+		}
+
+		for _, warning := range warnings {
+			problem := newProblem(manifest, warning.Field, warning.Message)
+			problem.Warning = true
+			problems = append(problems, problem)
+		}
+	}
+
+	return problems
+}
+
+// validateTeams checks that all the given teams are defined via a Team CR or are known teams. The special character "*"
+// which can be used in the permissions to allow the access to all teams is always valid.
+func validateTeams(field string, names []string, teams map[string]bool) validation.Errors {
+	var errs validation.Errors
+
+	for i, name := range names {
+		if name != "" && name != "*" && !teams[name] {
+			errs = append(errs, validation.Error{Field: fmt.Sprintf("%s[%d]", field, i), Message: fmt.Sprintf("team %q does not exist", name)})
+		}
+	}
+
+	return errs
+}
+
+func newProblem(manifest Manifest, field, message string) Problem {
+	line, column := manifest.Position(field)
+
+	return Problem{
+		File:      manifest.File,
+		Line:      line,
+		Column:    column,
+		Kind:      manifest.Kind,
+		Namespace: manifest.Namespace,
+		Name:      manifest.Name,
+		Field:     field,
+		Message:   message,
+	}
+}
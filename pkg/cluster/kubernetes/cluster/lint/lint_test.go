@@ -0,0 +1,89 @@
+package lint
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	t.Run("should return no problems for valid manifests", func(t *testing.T) {
+		manifests, _ := parse("manifests.yaml", strings.NewReader(testManifests+`---
+apiVersion: kobs.io/v1
+kind: Team
+metadata:
+  name: team1
+spec:
+  id: team1@kobs.io
+`), "kobs")
+
+		problems := Lint(context.Background(), manifests, []string{"prometheus"}, nil)
+		require.Empty(t, problems)
+	})
+
+	t.Run("should return problems for invalid references", func(t *testing.T) {
+		manifests, _ := parse("manifests.yaml", strings.NewReader(`---
+apiVersion: kobs.io/v1
+kind: User
+metadata:
+  name: user1
+spec:
+  id: user1@kobs.io
+  teams:
+    - team1@kobs.io
+  permissions:
+    teams:
+      - "*"
+  dashboards:
+    - title: Resources
+      name: resources
+---
+apiVersion: kobs.io/v1
+kind: Dashboard
+metadata:
+  name: resources
+spec:
+  rows:
+    - panels:
+        - title: Panel
+          plugin:
+            type: foo
+            name: foo
+---
+apiVersion: kobs.io/v1
+kind: Dashboard
+metadata:
+  name: resources
+`), "default")
+
+		problems := Lint(context.Background(), manifests, []string{"prometheus"}, nil)
+		require.Equal(t, []Problem{
+			{File: "manifests.yaml", Line: 32, Column: 9, Kind: "Dashboard", Namespace: "default", Name: "resources", Field: "metadata.name", Message: "duplicate Dashboard default/resources"},
+			{File: "manifests.yaml", Line: 9, Column: 7, Kind: "User", Namespace: "default", Name: "user1", Field: "spec.teams[0]", Message: "team \"team1@kobs.io\" does not exist", Warning: true},
+			{File: "manifests.yaml", Line: 26, Column: 19, Kind: "Dashboard", Namespace: "default", Name: "resources", Field: "spec.rows[0].panels[0].plugin.type", Message: "unknown plugin type \"foo\""},
+		}, problems)
+	})
+
+	t.Run("should not return warning for known teams", func(t *testing.T) {
+		manifests, _ := parse("manifests.yaml", strings.NewReader("apiVersion: kobs.io/v1\nkind: User\nmetadata:\n  name: user1\nspec:\n  id: user1@kobs.io\n  teams:\n    - team1@kobs.io\n"), "default")
+
+		problems := Lint(context.Background(), manifests, nil, []string{"team1@kobs.io"})
+		require.Empty(t, problems)
+	})
+
+	t.Run("should return problem for missing name", func(t *testing.T) {
+		manifests, _ := parse("manifests.yaml", strings.NewReader("apiVersion: kobs.io/v1\nkind: Team\nmetadata: {}\nspec:\n  id: team1\n"), "default")
+
+		problems := Lint(context.Background(), manifests, nil, nil)
+		require.Equal(t, []Problem{{File: "manifests.yaml", Line: 3, Column: 11, Kind: "Team", Namespace: "default", Field: "metadata.name", Message: "name is required"}}, problems)
+	})
+}
+
+func TestProblemString(t *testing.T) {
+	require.Equal(t, "manifests.yaml: yaml: line 2: did not find expected node content", Problem{File: "manifests.yaml", Message: "yaml: line 2: did not find expected node content"}.String())
+	require.Equal(t, "manifests.yaml:6:3: User default/user1: invalid spec", Problem{File: "manifests.yaml", Line: 6, Column: 3, Kind: "User", Namespace: "default", Name: "user1", Message: "invalid spec"}.String())
+	require.Equal(t, "manifests.yaml:8:7: User default/user1: spec.teams[0]: team \"team1\" does not exist", Problem{File: "manifests.yaml", Line: 8, Column: 7, Kind: "User", Namespace: "default", Name: "user1", Field: "spec.teams[0]", Message: "team \"team1\" does not exist"}.String())
+	require.Equal(t, "manifests.yaml:8:7: warning: User default/user1: spec.teams[0]: team \"team1\" does not exist", Problem{File: "manifests.yaml", Line: 8, Column: 7, Kind: "User", Namespace: "default", Name: "user1", Field: "spec.teams[0]", Message: "team \"team1\" does not exist", Warning: true}.String())
+}
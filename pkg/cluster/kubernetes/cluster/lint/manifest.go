@@ -0,0 +1,258 @@
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
+	dashboardv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/dashboard/v1"
+	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is a single Application, Dashboard, Team or User CR from a YAML file. Besides the parsed spec of the CR it
+// also contains the YAML node of the document, so that the position of a field within the file can be determined.
+type Manifest struct {
+	File        string
+	Kind        string
+	Namespace   string
+	Name        string
+	Application *applicationv1.ApplicationSpec
+	Dashboard   *dashboardv1.DashboardSpec
+	Team        *teamv1.TeamSpec
+	User        *userv1.UserSpec
+
+	node *yaml.Node
+}
+
+// Position returns the line and column of the given field in the manifest. The field must be a JSON path, like it is
+// used in the validation errors, e.g. "spec.dashboards[0].name". If the field does not exist, the position of the
+// closest existing parent is returned, so that a missing field is reported at the position of the object which should
+// contain the field.
+func (m Manifest) Position(field string) (int, int) {
+	node := m.node
+	if node == nil {
+		return 0, 0
+	}
+
+	for _, segment := range splitField(field) {
+		child := lookup(node, segment)
+		if child == nil {
+			break
+		}
+		node = child
+	}
+
+	return node.Line, node.Column
+}
+
+// Load loads all kobs CRs from the given files. If a path is a directory, all files with the ".yaml" or ".yml" extension
+// in the directory and all sub directories are loaded. Documents which are not a kobs CR, like Deployments or Services,
+// are ignored. CRs without a namespace are placed in the given namespace.
+//
+// Documents which can not be parsed are returned as problems, so that all invalid files can be reported at once. An
+// error is only returned when a file can not be read.
+func Load(paths []string, namespace string) ([]Manifest, []Problem, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				return nil
+			}
+
+			if file == path || strings.HasSuffix(file, ".yaml") || strings.HasSuffix(file, ".yml") {
+				files = append(files, file)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var manifests []Manifest
+	var problems []Problem
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		fileManifests, fileProblems := parse(file, f, namespace)
+		f.Close()
+
+		manifests = append(manifests, fileManifests...)
+		problems = append(problems, fileProblems...)
+	}
+
+	return manifests, problems, nil
+}
+
+// parse parses all YAML documents from the given reader and returns the kobs CRs from the documents.
+func parse(file string, r io.Reader, namespace string) ([]Manifest, []Problem) {
+	var manifests []Manifest
+	var problems []Problem
+
+	decoder := yaml.NewDecoder(r)
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			// The decoder can not continue after a syntax error, so that we report the error and skip the rest of the
+			// file. The error of the yaml package already contains the line of the error.
+			problems = append(problems, Problem{File: file, Message: err.Error()})
+			break
+		}
+
+		if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		node := document.Content[0]
+
+		apiVersion := scalar(node, "apiVersion")
+		kind := scalar(node, "kind")
+		if !strings.HasPrefix(apiVersion, "kobs.io/") {
+			continue
+		}
+
+		manifest := Manifest{File: file, Kind: kind, Namespace: namespace, node: node}
+		if metadata := lookup(node, "metadata"); metadata != nil {
+			manifest.Name = scalar(metadata, "name")
+			if ns := scalar(metadata, "namespace"); ns != "" {
+				manifest.Namespace = ns
+			}
+		}
+
+		if err := manifest.decode(); err != nil {
+			line, column := manifest.Position("spec")
+			problems = append(problems, Problem{File: file, Line: line, Column: column, Kind: manifest.Kind, Namespace: manifest.Namespace, Name: manifest.Name, Message: err.Error()})
+			continue
+		}
+
+		manifests = append(manifests, manifest)
+	}
+
+	return manifests, problems
+}
+
+// decode decodes the YAML node of the manifest into the CR for the kind of the manifest. We are converting the YAML to
+// JSON first, so that the JSON tags of the CRs are used, like it is done by the Kubernetes API server.
+func (m *Manifest) decode() error {
+	var data interface{}
+	if err := m.node.Decode(&data); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	switch m.Kind {
+	case "Application":
+		var application applicationv1.Application
+		if err := json.Unmarshal(raw, &application); err != nil {
+			return err
+		}
+		m.Application = &application.Spec
+	case "Dashboard":
+		var dashboard dashboardv1.Dashboard
+		if err := json.Unmarshal(raw, &dashboard); err != nil {
+			return err
+		}
+		m.Dashboard = &dashboard.Spec
+	case "Team":
+		var team teamv1.Team
+		if err := json.Unmarshal(raw, &team); err != nil {
+			return err
+		}
+		m.Team = &team.Spec
+	case "User":
+		var user userv1.User
+		if err := json.Unmarshal(raw, &user); err != nil {
+			return err
+		}
+		m.User = &user.Spec
+	default:
+		return fmt.Errorf("unsupported kind %q", m.Kind)
+	}
+
+	return nil
+}
+
+// splitField splits a JSON path like "spec.dashboards[0].name" into its segments "spec", "dashboards", "0" and "name".
+func splitField(field string) []string {
+	var segments []string
+	for _, part := range strings.Split(field, ".") {
+		for part != "" {
+			start := strings.Index(part, "[")
+			if start == -1 {
+				segments = append(segments, part)
+				break
+			}
+
+			if start > 0 {
+				segments = append(segments, part[:start])
+			}
+
+			end := strings.Index(part, "]")
+			if end < start {
+				segments = append(segments, part)
+				break
+			}
+
+			segments = append(segments, part[start+1:end])
+			part = part[end+1:]
+		}
+	}
+
+	return segments
+}
+
+// lookup returns the child of the given node for the segment of a path. For mappings the segment is the key, for
+// sequences the index of the item.
+func lookup(node *yaml.Node, segment string) *yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == segment {
+				return node.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		index, err := strconv.Atoi(segment)
+		if err == nil && index >= 0 && index < len(node.Content) {
+			return node.Content[index]
+		}
+	}
+
+	return nil
+}
+
+// scalar returns the value of the scalar child with the given key of a mapping node.
+func scalar(node *yaml.Node, key string) string {
+	child := lookup(node, key)
+	if child == nil || child.Kind != yaml.ScalarNode {
+		return ""
+	}
+
+	return child.Value
+}
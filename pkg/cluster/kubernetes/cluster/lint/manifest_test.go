@@ -0,0 +1,121 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testManifests = `---
+apiVersion: kobs.io/v1
+kind: Application
+metadata:
+  name: application1
+  namespace: kobs
+spec:
+  teams:
+    - team1@kobs.io
+  dashboards:
+    - title: Resources
+      name: resources
+      placeholders:
+        service: application1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: configmap1
+---
+apiVersion: kobs.io/v1
+kind: Dashboard
+metadata:
+  name: resources
+spec:
+  placeholders:
+    - name: service
+`
+
+func TestLoad(t *testing.T) {
+	t.Run("should load manifests from directory", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "manifests.yaml"), []byte(testManifests), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "team.yml"), []byte("apiVersion: kobs.io/v1\nkind: Team\nmetadata:\n  name: team1\nspec:\n  id: team1@kobs.io\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Manifests"), 0644))
+
+		manifests, problems, err := Load([]string{dir}, "default")
+		require.NoError(t, err)
+		require.Empty(t, problems)
+		require.Equal(t, 3, len(manifests))
+
+		require.Equal(t, "Application", manifests[0].Kind)
+		require.Equal(t, "kobs", manifests[0].Namespace)
+		require.Equal(t, "application1", manifests[0].Name)
+		require.Equal(t, []string{"team1@kobs.io"}, manifests[0].Application.Teams)
+
+		require.Equal(t, "Dashboard", manifests[1].Kind)
+		require.Equal(t, "default", manifests[1].Namespace)
+		require.Equal(t, "service", manifests[1].Dashboard.Placeholders[0].Name)
+
+		require.Equal(t, "Team", manifests[2].Kind)
+		require.Equal(t, "team1@kobs.io", manifests[2].Team.ID)
+	})
+
+	t.Run("should return error for missing file", func(t *testing.T) {
+		_, _, err := Load([]string{filepath.Join(t.TempDir(), "missing.yaml")}, "default")
+		require.Error(t, err)
+	})
+}
+
+func TestParse(t *testing.T) {
+	t.Run("should return problem for invalid yaml", func(t *testing.T) {
+		manifests, problems := parse("invalid.yaml", strings.NewReader("apiVersion: kobs.io/v1\nkind: [User\n"), "default")
+		require.Empty(t, manifests)
+		require.Equal(t, 1, len(problems))
+		require.Equal(t, "invalid.yaml", problems[0].File)
+		require.Contains(t, problems[0].Message, "line")
+	})
+
+	t.Run("should return problem for invalid spec", func(t *testing.T) {
+		manifests, problems := parse("user.yaml", strings.NewReader("apiVersion: kobs.io/v1\nkind: User\nmetadata:\n  name: user1\nspec:\n  teams: team1\n"), "default")
+		require.Empty(t, manifests)
+		require.Equal(t, []Problem{{File: "user.yaml", Line: 6, Column: 3, Kind: "User", Namespace: "default", Name: "user1", Message: "json: cannot unmarshal string into Go struct field User.spec.teams of type []string"}}, problems)
+	})
+
+	t.Run("should return problem for unsupported kind", func(t *testing.T) {
+		_, problems := parse("plugin.yaml", strings.NewReader("apiVersion: kobs.io/v1\nkind: Plugin\nmetadata:\n  name: plugin1\n"), "default")
+		require.Equal(t, 1, len(problems))
+		require.Equal(t, "unsupported kind \"Plugin\"", problems[0].Message)
+	})
+}
+
+func TestPosition(t *testing.T) {
+	manifests, _ := parse("manifests.yaml", strings.NewReader(testManifests), "default")
+	require.Equal(t, 2, len(manifests))
+
+	for _, tt := range []struct {
+		field          string
+		expectedLine   int
+		expectedColumn int
+	}{
+		{field: "spec.teams[0]", expectedLine: 9, expectedColumn: 7},
+		{field: "spec.dashboards[0].name", expectedLine: 12, expectedColumn: 13},
+		{field: "spec.dashboards[0].placeholders.service", expectedLine: 14, expectedColumn: 18},
+		{field: "spec.dashboards[0].inline.rows", expectedLine: 11, expectedColumn: 7},
+		{field: "spec.dashboards[1]", expectedLine: 11, expectedColumn: 5},
+	} {
+		t.Run(tt.field, func(t *testing.T) {
+			line, column := manifests[0].Position(tt.field)
+			require.Equal(t, tt.expectedLine, line)
+			require.Equal(t, tt.expectedColumn, column)
+		})
+	}
+}
+
+func TestSplitField(t *testing.T) {
+	require.Equal(t, []string{"spec", "dashboards", "0", "inline", "rows", "1", "panels", "2", "title"}, splitField("spec.dashboards[0].inline.rows[1].panels[2].title"))
+	require.Equal(t, []string{"spec", "placeholders", "service"}, splitField("spec.placeholders.service"))
+}
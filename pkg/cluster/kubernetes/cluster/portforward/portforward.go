@@ -0,0 +1,169 @@
+// Package portforward implements the forwarding of a port of a Pod via a WebSocket connection. Each WebSocket
+// connection is used for exactly one TCP connection to the Pod, the data is sent as binary messages in both directions.
+package portforward
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// maxCloseReasonLength is the maximum length of the reason in a close message, because the payload of a control
+// message can not be larger then 125 bytes and the first two bytes are used for the close code.
+const maxCloseReasonLength = 123
+
+// StartForwarding forwards the provided port of the Pod from the request URL via the WebSocket connection. For that we
+// create a new SPDY connection to the Kubernetes API server and open the error and data stream for the port, like it is
+// done by "kubectl port-forward". The function blocks until the WebSocket connection or the data stream is closed.
+//
+// When the Kubernetes API server reports an error for the port, e.g. because nothing is listening on the port in the
+// Pod, the WebSocket connection is closed with the error as reason and the error is returned.
+func StartForwarding(ctx context.Context, config *rest.Config, reqURL *url.URL, conn *websocket.Conn, port int) error {
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return err
+	}
+
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, reqURL)
+	streamConn, _, err := dialer.Dial(portforward.PortForwardProtocolV1Name)
+	if err != nil {
+		return err
+	}
+	defer streamConn.Close()
+
+	headers := http.Header{}
+	headers.Set(corev1.StreamType, corev1.StreamTypeError)
+	headers.Set(corev1.PortHeader, strconv.Itoa(port))
+	headers.Set(corev1.PortForwardRequestIDHeader, "0")
+
+	errorStream, err := streamConn.CreateStream(headers)
+	if err != nil {
+		return err
+	}
+	// We only read from the error stream, so that we can close it for writing right away.
+	errorStream.Close()
+
+	headers.Set(corev1.StreamType, corev1.StreamTypeData)
+	dataStream, err := streamConn.CreateStream(headers)
+	if err != nil {
+		return err
+	}
+
+	forwardErr := make(chan error, 1)
+	go func() {
+		message, err := io.ReadAll(errorStream)
+		if err != nil || len(message) == 0 {
+			forwardErr <- nil
+			return
+		}
+
+		err = fmt.Errorf("failed to forward port %d: %s", port, string(message))
+		forwardErr <- err
+		Close(conn, websocket.CloseInternalServerErr, err.Error())
+		dataStream.Reset()
+	}()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			streamConn.Close()
+		case <-done:
+		}
+	}()
+
+	proxyErr := Proxy(conn, dataStream)
+
+	// Closing the connection also closes the error stream, so that we can wait for the result of the error stream. An
+	// error from the Kubernetes API server is more helpful for the user than the error from the proxy.
+	streamConn.Close()
+	if err := <-forwardErr; err != nil {
+		return err
+	}
+
+	return proxyErr
+}
+
+// Proxy copies the data between the WebSocket connection and the provided stream, until one of them is closed. The
+// data from the stream is sent as binary message via the WebSocket connection and all binary messages from the
+// WebSocket connection are written to the stream. When the stream is closed, a normal close message is sent, so that
+// the other side knows that the connection was not interrupted.
+func Proxy(conn *websocket.Conn, stream io.ReadWriteCloser) error {
+	errCh := make(chan error, 2)
+
+	go func() {
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+					errCh <- nil
+				} else {
+					errCh <- err
+				}
+				return
+			}
+
+			if messageType != websocket.BinaryMessage {
+				continue
+			}
+
+			if _, err := stream.Write(data); err != nil {
+				errCh <- err
+				return
+			}
+		}
+	}()
+
+	go func() {
+		buf := make([]byte, 32*1024)
+
+		for {
+			n, err := stream.Read(buf)
+			if n > 0 {
+				if err := conn.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+					errCh <- err
+					return
+				}
+			}
+
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					Close(conn, websocket.CloseNormalClosure, "")
+					errCh <- nil
+				} else {
+					errCh <- err
+				}
+				return
+			}
+		}
+	}()
+
+	err := <-errCh
+	stream.Close()
+	conn.Close()
+
+	return err
+}
+
+// Close sends a close message with the provided code and reason via the WebSocket connection. The reason is truncated
+// when it is too long for a close message.
+func Close(conn *websocket.Conn, code int, reason string) {
+	if len(reason) > maxCloseReasonLength {
+		reason = reason[:maxCloseReasonLength]
+	}
+
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+}
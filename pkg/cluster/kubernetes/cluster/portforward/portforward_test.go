@@ -0,0 +1,98 @@
+package portforward
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxy(t *testing.T) {
+	t.Run("should copy data between websocket connection and stream", func(t *testing.T) {
+		stream, pod := net.Pipe()
+		proxyErr := make(chan error, 1)
+
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upgrader := websocket.Upgrader{}
+			conn, err := upgrader.Upgrade(w, r, nil)
+			require.NoError(t, err)
+			proxyErr <- Proxy(conn, stream)
+		}))
+		defer s.Close()
+
+		ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(s.URL, "http"), nil)
+		require.NoError(t, err)
+		defer ws.Close()
+
+		require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte("ignored")))
+		require.NoError(t, ws.WriteMessage(websocket.BinaryMessage, []byte("request")))
+
+		buf := make([]byte, 7)
+		_, err = io.ReadFull(pod, buf)
+		require.NoError(t, err)
+		require.Equal(t, "request", string(buf))
+
+		_, err = pod.Write([]byte("response"))
+		require.NoError(t, err)
+
+		messageType, data, err := ws.ReadMessage()
+		require.NoError(t, err)
+		require.Equal(t, websocket.BinaryMessage, messageType)
+		require.Equal(t, "response", string(data))
+
+		pod.Close()
+
+		_, _, err = ws.ReadMessage()
+		require.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+		require.NoError(t, <-proxyErr)
+	})
+
+	t.Run("should close stream when websocket connection is closed", func(t *testing.T) {
+		stream, pod := net.Pipe()
+		proxyErr := make(chan error, 1)
+
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upgrader := websocket.Upgrader{}
+			conn, err := upgrader.Upgrade(w, r, nil)
+			require.NoError(t, err)
+			proxyErr <- Proxy(conn, stream)
+		}))
+		defer s.Close()
+
+		ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(s.URL, "http"), nil)
+		require.NoError(t, err)
+		defer ws.Close()
+
+		require.NoError(t, ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")))
+		require.NoError(t, <-proxyErr)
+
+		_, err = pod.Read(make([]byte, 1))
+		require.ErrorIs(t, err, io.EOF)
+	})
+}
+
+func TestClose(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		Close(conn, websocket.CloseInternalServerErr, strings.Repeat("a", 200))
+	}))
+	defer s.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(s.URL, "http"), nil)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	_, _, err = ws.ReadMessage()
+	closeErr, ok := err.(*websocket.CloseError)
+	require.True(t, ok)
+	require.Equal(t, websocket.CloseInternalServerErr, closeErr.Code)
+	require.Equal(t, strings.Repeat("a", maxCloseReasonLength), closeErr.Text)
+}
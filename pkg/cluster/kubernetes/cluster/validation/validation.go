@@ -0,0 +1,472 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
+	dashboardv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/dashboard/v1"
+	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+)
+
+// CorePluginType is the type of the plugins which are implemented in the core of kobs, e.g. the "placeholder" and
+// "static" variables or the "markdown" panel. The core plugin type is always valid.
+const CorePluginType = "core"
+
+// Error is a single validation error. The field is the JSON path of the invalid field within the CR, e.g.
+// "spec.dashboards[0].placeholders.service", so that the error can be mapped to the position of the field in a
+// manifest.
+type Error struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Errors is a list of validation errors.
+type Errors []Error
+
+func (e Errors) Error() string {
+	var messages []string
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// GetDashboardFunc is used to get the dashboard with the given namespace and name, to validate dashboard references. If
+// the dashboard does not exist the function must return nil and no error.
+type GetDashboardFunc func(ctx context.Context, namespace, name string) (*dashboardv1.DashboardSpec, error)
+
+// Validator validates Application, Dashboard, Team and User CRs. Besides the structural checks, like required fields,
+// it also checks the plugin types against the list of registered plugins and if referenced dashboards exist.
+type Validator struct {
+	pluginTypes  map[string]bool
+	getDashboard GetDashboardFunc
+}
+
+// ValidateApplication validates the spec of the Application CR with the given namespace and name.
+func (v *Validator) ValidateApplication(ctx context.Context, namespace, name string, application applicationv1.ApplicationSpec) Errors {
+	var errs Errors
+
+	for i, link := range application.Links {
+		errs = append(errs, validateLink(fmt.Sprintf("spec.links[%d]", i), link.Title, link.Link)...)
+	}
+
+	errs = append(errs, validateNames("spec.teams", application.Teams)...)
+
+	dependencies := make(map[string]bool)
+	for i, dependency := range application.Topology.Dependencies {
+		field := fmt.Sprintf("spec.topology.dependencies[%d]", i)
+
+		if dependency.Name == "" {
+			errs = append(errs, Error{Field: field + ".name", Message: "name is required"})
+			continue
+		}
+
+		dependencyNamespace := dependency.Namespace
+		if dependencyNamespace == "" {
+			dependencyNamespace = namespace
+		}
+
+		if dependency.Cluster == "" && dependencyNamespace == namespace && dependency.Name == name {
+			errs = append(errs, Error{Field: field, Message: "application can not depend on itself"})
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%s/%s", dependency.Cluster, dependencyNamespace, dependency.Name)
+		if dependencies[key] {
+			errs = append(errs, Error{Field: field, Message: fmt.Sprintf("duplicate dependency %s/%s", dependencyNamespace, dependency.Name)})
+			continue
+		}
+		dependencies[key] = true
+	}
+
+	for i, insight := range application.Insights {
+		field := fmt.Sprintf("spec.insights[%d]", i)
+
+		if insight.Title == "" {
+			errs = append(errs, Error{Field: field + ".title", Message: "title is required"})
+		}
+		if insight.Type != "sparkline" {
+			errs = append(errs, Error{Field: field + ".type", Message: fmt.Sprintf("invalid insight type %q, must be sparkline", insight.Type)})
+		}
+		errs = append(errs, v.validatePlugin(field+".plugin", insight.Plugin)...)
+
+		if insight.Health != nil {
+			switch insight.Health.Operator {
+			case "", ">", ">=", "<", "<=":
+			default:
+				errs = append(errs, Error{Field: field + ".health.operator", Message: fmt.Sprintf("invalid operator %q, must be >, >=, < or <=", insight.Health.Operator)})
+			}
+
+			if _, err := strconv.ParseFloat(insight.Health.Degraded, 64); insight.Health.Degraded != "" && err != nil {
+				errs = append(errs, Error{Field: field + ".health.degraded", Message: fmt.Sprintf("invalid threshold %q, must be a number", insight.Health.Degraded)})
+			}
+			if _, err := strconv.ParseFloat(insight.Health.Unhealthy, 64); insight.Health.Unhealthy != "" && err != nil {
+				errs = append(errs, Error{Field: field + ".health.unhealthy", Message: fmt.Sprintf("invalid threshold %q, must be a number", insight.Health.Unhealthy)})
+			}
+		}
+	}
+
+	errs = append(errs, v.validateReferences(ctx, "spec.dashboards", namespace, application.Dashboards)...)
+
+	return errs
+}
+
+// ValidateDashboard validates the spec of a Dashboard CR.
+func (v *Validator) ValidateDashboard(ctx context.Context, dashboard dashboardv1.DashboardSpec) Errors {
+	var errs Errors
+
+	placeholders := make(map[string]bool)
+	for i, placeholder := range dashboard.Placeholders {
+		field := fmt.Sprintf("spec.placeholders[%d]", i)
+
+		if placeholder.Name == "" {
+			errs = append(errs, Error{Field: field + ".name", Message: "name is required"})
+			continue
+		}
+		if placeholders[placeholder.Name] {
+			errs = append(errs, Error{Field: field + ".name", Message: fmt.Sprintf("duplicate placeholder name %q", placeholder.Name)})
+			continue
+		}
+		placeholders[placeholder.Name] = true
+	}
+
+	errs = append(errs, v.validateVariables("spec.variables", dashboard.Variables, placeholders)...)
+	errs = append(errs, v.validateRows("spec.rows", dashboard.Rows)...)
+
+	return errs
+}
+
+// ValidateTeam validates the spec of the Team CR in the given namespace. The id is required, because teams are saved
+// by their id in the database of the hub, so that all teams without an id would overwrite each other.
+func (v *Validator) ValidateTeam(ctx context.Context, namespace string, team teamv1.TeamSpec) Errors {
+	var errs Errors
+
+	if team.ID == "" {
+		errs = append(errs, Error{Field: "spec.id", Message: "id is required"})
+	}
+
+	for i, link := range team.Links {
+		errs = append(errs, validateLink(fmt.Sprintf("spec.links[%d]", i), link.Title, link.Link)...)
+	}
+
+	errs = append(errs, v.validatePermissions("spec.permissions", team.Permissions)...)
+	errs = append(errs, v.validateReferences(ctx, "spec.dashboards", namespace, team.Dashboards)...)
+
+	return errs
+}
+
+// ValidateUser validates the spec of the User CR in the given namespace. Like for teams, the id is required, because
+// users are saved by their id in the database of the hub.
+func (v *Validator) ValidateUser(ctx context.Context, namespace string, user userv1.UserSpec) Errors {
+	var errs Errors
+
+	if user.ID == "" {
+		errs = append(errs, Error{Field: "spec.id", Message: "id is required"})
+	}
+
+	errs = append(errs, validateNames("spec.teams", user.Teams)...)
+	errs = append(errs, v.validatePermissions("spec.permissions", user.Permissions)...)
+	errs = append(errs, v.validateReferences(ctx, "spec.dashboards", namespace, user.Dashboards)...)
+
+	for i, navigation := range user.Navigation {
+		field := fmt.Sprintf("spec.navigation[%d]", i)
+
+		if navigation.Name == "" {
+			errs = append(errs, Error{Field: field + ".name", Message: "name is required"})
+		}
+
+		for j, item := range navigation.Items {
+			itemField := fmt.Sprintf("%s.items[%d]", field, j)
+
+			if item.Name == "" {
+				errs = append(errs, Error{Field: itemField + ".name", Message: "name is required"})
+			}
+			if item.Page != nil {
+				errs = append(errs, v.validateReferences(ctx, itemField+".page.dashboards", namespace, item.Page.Dashboards)...)
+			}
+
+			for k, subItem := range item.Items {
+				subItemField := fmt.Sprintf("%s.items[%d]", itemField, k)
+
+				if subItem.Name == "" {
+					errs = append(errs, Error{Field: subItemField + ".name", Message: "name is required"})
+				}
+				if subItem.Page != nil {
+					errs = append(errs, v.validateReferences(ctx, subItemField+".page.dashboards", namespace, subItem.Page.Dashboards)...)
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// validatePlugin checks that the type and name of a plugin are set and that the type is the core plugin type or the
+// type of a registered plugin.
+func (v *Validator) validatePlugin(field string, plugin dashboardv1.Plugin) Errors {
+	var errs Errors
+
+	if plugin.Name == "" {
+		errs = append(errs, Error{Field: field + ".name", Message: "name is required"})
+	}
+
+	if plugin.Type == "" {
+		errs = append(errs, Error{Field: field + ".type", Message: "type is required"})
+	} else if !v.isPluginType(plugin.Type) {
+		errs = append(errs, Error{Field: field + ".type", Message: fmt.Sprintf("unknown plugin type %q", plugin.Type)})
+	}
+
+	return errs
+}
+
+// validateVariables checks the variables of a dashboard. The names of the variables must be unique and can not be the
+// same as the name of a placeholder, because placeholders and variables are both used via their name in a dashboard.
+func (v *Validator) validateVariables(field string, variables []dashboardv1.Variable, placeholders map[string]bool) Errors {
+	var errs Errors
+
+	names := make(map[string]bool)
+	for i, variable := range variables {
+		variableField := fmt.Sprintf("%s[%d]", field, i)
+
+		if variable.Name == "" {
+			errs = append(errs, Error{Field: variableField + ".name", Message: "name is required"})
+		} else if names[variable.Name] {
+			errs = append(errs, Error{Field: variableField + ".name", Message: fmt.Sprintf("duplicate variable name %q", variable.Name)})
+		} else if placeholders[variable.Name] {
+			errs = append(errs, Error{Field: variableField + ".name", Message: fmt.Sprintf("variable name %q is already used by a placeholder", variable.Name)})
+		}
+		names[variable.Name] = true
+
+		errs = append(errs, v.validatePlugin(variableField+".plugin", variable.Plugin)...)
+	}
+
+	return errs
+}
+
+// validateRows checks the rows of a dashboard. Each panel must have a title and a valid plugin and must fit into the
+// grid of the dashboard, which has 12 columns.
+func (v *Validator) validateRows(field string, rows []dashboardv1.Row) Errors {
+	var errs Errors
+
+	for i, row := range rows {
+		for j, panel := range row.Panels {
+			panelField := fmt.Sprintf("%s[%d].panels[%d]", field, i, j)
+
+			if panel.Title == "" {
+				errs = append(errs, Error{Field: panelField + ".title", Message: "title is required"})
+			}
+			if panel.X < 0 || panel.Y < 0 || panel.W < 0 || panel.H < 0 {
+				errs = append(errs, Error{Field: panelField, Message: "x, y, w and h can not be negative"})
+			} else if panel.W > 12 || panel.X+panel.W > 12 {
+				errs = append(errs, Error{Field: panelField, Message: "panel does not fit into the 12 columns of the dashboard"})
+			}
+
+			errs = append(errs, v.validatePlugin(panelField+".plugin", panel.Plugin)...)
+		}
+	}
+
+	return errs
+}
+
+// validateReferences checks a list of dashboard references. A reference must contain the name of a dashboard or an
+// inline dashboard. When a dashboard from the same cluster is referenced, we also check that the dashboard exists and
+// that the provided placeholders match the placeholders of the dashboard. References to dashboards in other clusters
+// can not be checked, because they are not known to a single cluster.
+func (v *Validator) validateReferences(ctx context.Context, field, namespace string, references []dashboardv1.Reference) Errors {
+	var errs Errors
+
+	for i, reference := range references {
+		referenceField := fmt.Sprintf("%s[%d]", field, i)
+
+		if reference.Title == "" {
+			errs = append(errs, Error{Field: referenceField + ".title", Message: "title is required"})
+		}
+
+		if reference.Name == "" && reference.Inline == nil {
+			errs = append(errs, Error{Field: referenceField, Message: "name or inline dashboard is required"})
+			continue
+		}
+		if reference.Name != "" && reference.Inline != nil {
+			errs = append(errs, Error{Field: referenceField, Message: "name and inline dashboard can not be used together"})
+			continue
+		}
+
+		if reference.Inline != nil {
+			errs = append(errs, v.validateVariables(referenceField+".inline.variables", reference.Inline.Variables, nil)...)
+			errs = append(errs, v.validateRows(referenceField+".inline.rows", reference.Inline.Rows)...)
+			continue
+		}
+
+		if reference.Cluster != "" || v.getDashboard == nil {
+			continue
+		}
+
+		dashboardNamespace := reference.Namespace
+		if dashboardNamespace == "" {
+			dashboardNamespace = namespace
+		}
+
+		dashboard, err := v.getDashboard(ctx, dashboardNamespace, reference.Name)
+		if err != nil {
+			errs = append(errs, Error{Field: referenceField + ".name", Message: fmt.Sprintf("could not get dashboard %s/%s: %s", dashboardNamespace, reference.Name, err.Error())})
+			continue
+		}
+		if dashboard == nil {
+			errs = append(errs, Error{Field: referenceField + ".name", Message: fmt.Sprintf("dashboard %s/%s does not exist", dashboardNamespace, reference.Name)})
+			continue
+		}
+
+		placeholders := make(map[string]bool)
+		for _, placeholder := range dashboard.Placeholders {
+			placeholders[placeholder.Name] = true
+
+			if _, ok := reference.Placeholders[placeholder.Name]; !ok && placeholder.Default == "" {
+				errs = append(errs, Error{Field: referenceField + ".placeholders", Message: fmt.Sprintf("placeholder %q is required by dashboard %s/%s", placeholder.Name, dashboardNamespace, reference.Name)})
+			}
+		}
+
+		for _, name := range sortedKeys(reference.Placeholders) {
+			if !placeholders[name] {
+				errs = append(errs, Error{Field: referenceField + ".placeholders." + name, Message: fmt.Sprintf("placeholder %q is not defined in dashboard %s/%s", name, dashboardNamespace, reference.Name)})
+			}
+		}
+	}
+
+	return errs
+}
+
+// validatePermissions checks the permissions of a team or user.
+func (v *Validator) validatePermissions(field string, permissions userv1.Permissions) Errors {
+	var errs Errors
+
+	for i, application := range permissions.Applications {
+		applicationField := fmt.Sprintf("%s.applications[%d]", field, i)
+
+		switch application.Type {
+		case "all", "own":
+		case "custom":
+			if len(application.Clusters) == 0 || len(application.Namespaces) == 0 {
+				errs = append(errs, Error{Field: applicationField, Message: "clusters and namespaces are required for type custom"})
+			}
+		default:
+			errs = append(errs, Error{Field: applicationField + ".type", Message: fmt.Sprintf("invalid type %q, must be all, own or custom", application.Type)})
+		}
+	}
+
+	errs = append(errs, validateNames(field+".teams", permissions.Teams)...)
+
+	for i, plugin := range permissions.Plugins {
+		pluginField := fmt.Sprintf("%s.plugins[%d]", field, i)
+
+		if plugin.Cluster == "" {
+			errs = append(errs, Error{Field: pluginField + ".cluster", Message: "cluster is required"})
+		}
+		if plugin.Name == "" {
+			errs = append(errs, Error{Field: pluginField + ".name", Message: "name is required"})
+		}
+		if plugin.Type == "" {
+			errs = append(errs, Error{Field: pluginField + ".type", Message: "type is required"})
+		} else if plugin.Type != "*" && !v.isPluginType(plugin.Type) {
+			errs = append(errs, Error{Field: pluginField + ".type", Message: fmt.Sprintf("unknown plugin type %q", plugin.Type)})
+		}
+	}
+
+	for i, resource := range permissions.Resources {
+		resourceField := fmt.Sprintf("%s.resources[%d]", field, i)
+
+		if len(resource.Clusters) == 0 {
+			errs = append(errs, Error{Field: resourceField + ".clusters", Message: "at least one cluster is required"})
+		}
+		if len(resource.Namespaces) == 0 {
+			errs = append(errs, Error{Field: resourceField + ".namespaces", Message: "at least one namespace is required"})
+		}
+		if len(resource.Resources) == 0 {
+			errs = append(errs, Error{Field: resourceField + ".resources", Message: "at least one resource is required"})
+		}
+		if len(resource.Verbs) == 0 {
+			errs = append(errs, Error{Field: resourceField + ".verbs", Message: "at least one verb is required"})
+		}
+	}
+
+	return errs
+}
+
+// isPluginType returns true when the given type is the core plugin type or the type of a registered plugin. When no
+// plugins are registered, all types are valid.
+func (v *Validator) isPluginType(pluginType string) bool {
+	if pluginType == CorePluginType || len(v.pluginTypes) == 0 {
+		return true
+	}
+
+	return v.pluginTypes[pluginType]
+}
+
+// New returns a new validator. The plugin types are the types of all registered plugins, which can be used in a CR. The
+// getDashboard function is used to check dashboard references, if it is nil the references are not checked.
+func New(pluginTypes []string, getDashboard GetDashboardFunc) *Validator {
+	types := make(map[string]bool)
+	for _, pluginType := range pluginTypes {
+		types[pluginType] = true
+	}
+
+	return &Validator{
+		pluginTypes:  types,
+		getDashboard: getDashboard,
+	}
+}
+
+// validateLink checks that the title and the link of a link are set.
+func validateLink(field, title, link string) Errors {
+	var errs Errors
+
+	if title == "" {
+		errs = append(errs, Error{Field: field + ".title", Message: "title is required"})
+	}
+	if link == "" {
+		errs = append(errs, Error{Field: field + ".link", Message: "link is required"})
+	}
+
+	return errs
+}
+
+// validateNames checks that a list of names, e.g. the teams of an application, does not contain empty or duplicate
+// names.
+func validateNames(field string, names []string) Errors {
+	var errs Errors
+
+	seen := make(map[string]bool)
+	for i, name := range names {
+		if name == "" {
+			errs = append(errs, Error{Field: fmt.Sprintf("%s[%d]", field, i), Message: "name can not be empty"})
+			continue
+		}
+		if seen[name] {
+			errs = append(errs, Error{Field: fmt.Sprintf("%s[%d]", field, i), Message: fmt.Sprintf("duplicate name %q", name)})
+			continue
+		}
+		seen[name] = true
+	}
+
+	return errs
+}
+
+// sortedKeys returns the keys of the given map in a sorted order, so that the returned errors are stable.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,215 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
+	dashboardv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/dashboard/v1"
+	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newValidator() *Validator {
+	return New([]string{"prometheus", "klogs"}, func(ctx context.Context, namespace, name string) (*dashboardv1.DashboardSpec, error) {
+		switch fmt.Sprintf("%s/%s", namespace, name) {
+		case "default/dashboard1":
+			return &dashboardv1.DashboardSpec{Placeholders: []dashboardv1.Placeholder{{Name: "service"}, {Name: "namespace", Default: "default"}}}, nil
+		case "default/error":
+			return nil, fmt.Errorf("unexpected error")
+		default:
+			return nil, nil
+		}
+	})
+}
+
+func TestValidateApplication(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		application    applicationv1.ApplicationSpec
+		expectedErrors Errors
+	}{
+		{
+			name: "should return no errors for valid application",
+			application: applicationv1.ApplicationSpec{
+				Links:    []applicationv1.Link{{Title: "Docs", Link: "https://kobs.io"}},
+				Teams:    []string{"team1"},
+				Topology: applicationv1.Topology{Dependencies: []applicationv1.Dependency{{Name: "application2"}, {Cluster: "cluster2", Name: "application1"}}},
+				Insights: []applicationv1.Insight{{Title: "Requests", Type: "sparkline", Plugin: dashboardv1.Plugin{Type: "prometheus", Name: "prometheus"}, Health: &applicationv1.InsightHealth{Operator: "<", Degraded: "0.99", Unhealthy: "0.95"}}},
+				Dashboards: []dashboardv1.Reference{
+					{Title: "Dashboard", Name: "dashboard1", Placeholders: map[string]string{"service": "application1"}},
+					{Title: "Other Cluster", Cluster: "cluster2", Name: "dashboard2"},
+					{Title: "Inline", Inline: &dashboardv1.ReferenceInline{Rows: []dashboardv1.Row{{Panels: []dashboardv1.Panel{{Title: "Logs", W: 12, Plugin: dashboardv1.Plugin{Type: "klogs", Name: "klogs"}}}}}}},
+				},
+			},
+		},
+		{
+			name: "should return errors for invalid links, teams and insights",
+			application: applicationv1.ApplicationSpec{
+				Links:    []applicationv1.Link{{Title: "Docs"}},
+				Teams:    []string{"team1", "team1", ""},
+				Insights: []applicationv1.Insight{{Type: "gauge", Plugin: dashboardv1.Plugin{Type: "foo", Name: "foo"}, Health: &applicationv1.InsightHealth{Operator: "!=", Unhealthy: "ten"}}},
+			},
+			expectedErrors: Errors{
+				{Field: "spec.links[0].link", Message: "link is required"},
+				{Field: "spec.teams[1]", Message: "duplicate name \"team1\""},
+				{Field: "spec.teams[2]", Message: "name can not be empty"},
+				{Field: "spec.insights[0].title", Message: "title is required"},
+				{Field: "spec.insights[0].type", Message: "invalid insight type \"gauge\", must be sparkline"},
+				{Field: "spec.insights[0].plugin.type", Message: "unknown plugin type \"foo\""},
+				{Field: "spec.insights[0].health.operator", Message: "invalid operator \"!=\", must be >, >=, < or <="},
+				{Field: "spec.insights[0].health.unhealthy", Message: "invalid threshold \"ten\", must be a number"},
+			},
+		},
+		{
+			name: "should return errors for invalid topology dependencies",
+			application: applicationv1.ApplicationSpec{
+				Topology: applicationv1.Topology{Dependencies: []applicationv1.Dependency{{Namespace: "default"}, {Name: "application1"}, {Name: "application2"}, {Namespace: "default", Name: "application2"}}},
+			},
+			expectedErrors: Errors{
+				{Field: "spec.topology.dependencies[0].name", Message: "name is required"},
+				{Field: "spec.topology.dependencies[1]", Message: "application can not depend on itself"},
+				{Field: "spec.topology.dependencies[3]", Message: "duplicate dependency default/application2"},
+			},
+		},
+		{
+			name: "should return errors for invalid dashboard references",
+			application: applicationv1.ApplicationSpec{
+				Dashboards: []dashboardv1.Reference{
+					{Name: "dashboard1", Placeholders: map[string]string{"foo": "bar"}},
+					{Title: "Missing", Name: "dashboard2"},
+					{Title: "Error", Name: "error"},
+					{Title: "Empty"},
+					{Title: "Both", Name: "dashboard1", Inline: &dashboardv1.ReferenceInline{}},
+				},
+			},
+			expectedErrors: Errors{
+				{Field: "spec.dashboards[0].title", Message: "title is required"},
+				{Field: "spec.dashboards[0].placeholders", Message: "placeholder \"service\" is required by dashboard default/dashboard1"},
+				{Field: "spec.dashboards[0].placeholders.foo", Message: "placeholder \"foo\" is not defined in dashboard default/dashboard1"},
+				{Field: "spec.dashboards[1].name", Message: "dashboard default/dashboard2 does not exist"},
+				{Field: "spec.dashboards[2].name", Message: "could not get dashboard default/error: unexpected error"},
+				{Field: "spec.dashboards[3]", Message: "name or inline dashboard is required"},
+				{Field: "spec.dashboards[4]", Message: "name and inline dashboard can not be used together"},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := newValidator().ValidateApplication(context.Background(), "default", "application1", tt.application)
+			require.Equal(t, tt.expectedErrors, errs)
+		})
+	}
+}
+
+func TestValidateDashboard(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		dashboard      dashboardv1.DashboardSpec
+		expectedErrors Errors
+	}{
+		{
+			name: "should return no errors for valid dashboard",
+			dashboard: dashboardv1.DashboardSpec{
+				Placeholders: []dashboardv1.Placeholder{{Name: "service"}},
+				Variables:    []dashboardv1.Variable{{Name: "pod", Plugin: dashboardv1.Plugin{Type: "prometheus", Name: "prometheus"}}, {Name: "var", Plugin: dashboardv1.Plugin{Type: "core", Name: "static"}}},
+				Rows:         []dashboardv1.Row{{Panels: []dashboardv1.Panel{{Title: "Panel 1", W: 6, Plugin: dashboardv1.Plugin{Type: "prometheus", Name: "prometheus"}}, {Title: "Panel 2", X: 6, W: 6, Plugin: dashboardv1.Plugin{Type: "core", Name: "markdown"}}}}},
+			},
+		},
+		{
+			name: "should return errors for duplicate placeholders and variables",
+			dashboard: dashboardv1.DashboardSpec{
+				Placeholders: []dashboardv1.Placeholder{{Name: "service"}, {Name: "service"}, {}},
+				Variables:    []dashboardv1.Variable{{Name: "service", Plugin: dashboardv1.Plugin{Type: "core", Name: "static"}}, {Name: "pod", Plugin: dashboardv1.Plugin{Type: "core", Name: "static"}}, {Name: "pod", Plugin: dashboardv1.Plugin{Type: "core"}}},
+			},
+			expectedErrors: Errors{
+				{Field: "spec.placeholders[1].name", Message: "duplicate placeholder name \"service\""},
+				{Field: "spec.placeholders[2].name", Message: "name is required"},
+				{Field: "spec.variables[0].name", Message: "variable name \"service\" is already used by a placeholder"},
+				{Field: "spec.variables[2].name", Message: "duplicate variable name \"pod\""},
+				{Field: "spec.variables[2].plugin.name", Message: "name is required"},
+			},
+		},
+		{
+			name: "should return errors for invalid panels",
+			dashboard: dashboardv1.DashboardSpec{
+				Rows: []dashboardv1.Row{{Panels: []dashboardv1.Panel{{W: 6, X: 8, Plugin: dashboardv1.Plugin{Type: "prometheus", Name: "prometheus"}}, {Title: "Panel 2", H: -1, Plugin: dashboardv1.Plugin{Name: "foo"}}}}},
+			},
+			expectedErrors: Errors{
+				{Field: "spec.rows[0].panels[0].title", Message: "title is required"},
+				{Field: "spec.rows[0].panels[0]", Message: "panel does not fit into the 12 columns of the dashboard"},
+				{Field: "spec.rows[0].panels[1]", Message: "x, y, w and h can not be negative"},
+				{Field: "spec.rows[0].panels[1].plugin.type", Message: "type is required"},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := newValidator().ValidateDashboard(context.Background(), tt.dashboard)
+			require.Equal(t, tt.expectedErrors, errs)
+		})
+	}
+}
+
+func TestValidateTeam(t *testing.T) {
+	t.Run("should return no errors for valid team", func(t *testing.T) {
+		errs := newValidator().ValidateTeam(context.Background(), "default", teamv1.TeamSpec{
+			ID: "team1@kobs.io",
+			Permissions: userv1.Permissions{
+				Applications: []userv1.ApplicationPermissions{{Type: "custom", Clusters: []string{"*"}, Namespaces: []string{"*"}}},
+				Plugins:      []userv1.Plugin{{Cluster: "*", Type: "*", Name: "*"}},
+				Resources:    []userv1.Resources{{Clusters: []string{"*"}, Namespaces: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}},
+			},
+		})
+		require.Nil(t, errs)
+	})
+
+	t.Run("should return errors for invalid permissions", func(t *testing.T) {
+		errs := newValidator().ValidateTeam(context.Background(), "default", teamv1.TeamSpec{
+			Permissions: userv1.Permissions{
+				Applications: []userv1.ApplicationPermissions{{Type: "custom"}, {Type: "foo"}},
+				Plugins:      []userv1.Plugin{{Cluster: "*", Type: "foo", Name: "*"}},
+				Resources:    []userv1.Resources{{Clusters: []string{"*"}, Namespaces: []string{"*"}, Resources: []string{"*"}}},
+			},
+		})
+		require.Equal(t, Errors{
+			{Field: "spec.id", Message: "id is required"},
+			{Field: "spec.permissions.applications[0]", Message: "clusters and namespaces are required for type custom"},
+			{Field: "spec.permissions.applications[1].type", Message: "invalid type \"foo\", must be all, own or custom"},
+			{Field: "spec.permissions.plugins[0].type", Message: "unknown plugin type \"foo\""},
+			{Field: "spec.permissions.resources[0].verbs", Message: "at least one verb is required"},
+		}, errs)
+	})
+}
+
+func TestValidateUser(t *testing.T) {
+	t.Run("should return errors for invalid navigation", func(t *testing.T) {
+		errs := newValidator().ValidateUser(context.Background(), "default", userv1.UserSpec{
+			ID:    "user1@kobs.io",
+			Teams: []string{"team1"},
+			Navigation: []userv1.Navigation{{
+				Name: "Home",
+				Items: []userv1.NavigationItem{
+					{Name: "Dashboard", Page: &userv1.NavigationPage{Dashboards: []dashboardv1.Reference{{Title: "Dashboard", Name: "dashboard1", Placeholders: map[string]string{"service": "foo"}}}}},
+					{Items: []userv1.NavigationSubItems{{Name: "Sub Item", Page: &userv1.NavigationPage{Dashboards: []dashboardv1.Reference{{Title: "Dashboard", Name: "dashboard2"}}}}}},
+				},
+			}},
+		})
+		require.Equal(t, Errors{
+			{Field: "spec.navigation[0].items[1].name", Message: "name is required"},
+			{Field: "spec.navigation[0].items[1].items[0].page.dashboards[0].name", Message: "dashboard default/dashboard2 does not exist"},
+		}, errs)
+	})
+}
+
+func TestIsPluginType(t *testing.T) {
+	require.True(t, New(nil, nil).isPluginType("foo"))
+	require.True(t, New([]string{"prometheus"}, nil).isPluginType("core"))
+	require.True(t, New([]string{"prometheus"}, nil).isPluginType("prometheus"))
+	require.False(t, New([]string{"prometheus"}, nil).isPluginType("foo"))
+}
+
+func TestErrors(t *testing.T) {
+	require.Equal(t, "spec.teams[0]: name can not be empty; spec.links[0].link: link is required", Errors{{Field: "spec.teams[0]", Message: "name can not be empty"}, {Field: "spec.links[0].link", Message: "link is required"}}.Error())
+}
@@ -0,0 +1,191 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/terminal"
+
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+var (
+	// debugContainerTimeout is the maximum time we wait for an ephemeral debug container to be running, before we
+	// return an error to the user. The time includes pulling the debug image.
+	debugContainerTimeout = 2 * time.Minute
+	// debugContainerPollInterval is the interval in which we check the status of an ephemeral debug container.
+	debugContainerPollInterval = time.Second
+)
+
+// DebugOptions are the options to start an ephemeral debug container in a Pod. When the `Image` is empty, the
+// configured debug image is used. The `TargetContainer` is the name of the container in the Pod, which process namespace
+// should be shared with the debug container. When the `Shell` is empty, the default command of the image is used.
+type DebugOptions struct {
+	Image           string
+	TargetContainer string
+	Shell           string
+}
+
+// GetDebugTerminal creates a new ephemeral debug container in the provided Pod and starts a terminal session for the
+// debug container via the given WebSocket connection. This allows users to get a terminal for containers which are
+// using an image without a shell (e.g. distroless images).
+//
+// Ephemeral containers can not be removed from a Pod, so that the debug container stays in the Pod until the Pod is
+// deleted. The process of the debug container is terminated when the terminal session is closed.
+func (c *client) GetDebugTerminal(ctx context.Context, conn *websocket.Conn, namespace, name string, options DebugOptions) error {
+	ctx, span := c.tracer.Start(ctx, "cluster.GetDebugTerminal")
+	span.SetAttributes(attribute.Key("namespace").String(namespace))
+	span.SetAttributes(attribute.Key("name").String(name))
+	span.SetAttributes(attribute.Key("image").String(options.Image))
+	span.SetAttributes(attribute.Key("targetContainer").String(options.TargetContainer))
+	span.SetAttributes(attribute.Key("shell").String(options.Shell))
+	defer span.End()
+
+	if options.Shell != "" && !terminal.IsValidShell(options.Shell) {
+		err := fmt.Errorf("invalid shell %s", options.Shell)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	if options.Image == "" {
+		options.Image = c.debugImage
+	}
+
+	restConfig, clientset, err := c.getImpersonatedClient(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	container, err := createDebugContainer(ctx, clientset, namespace, name, options)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	span.SetAttributes(attribute.Key("container").String(container))
+
+	err = waitForDebugContainer(ctx, clientset, namespace, name, container)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	reqURL, err := url.Parse(fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/attach?container=%s&stdin=true&stdout=true&stderr=true&tty=true", restConfig.Host, namespace, name, container))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	session := &terminal.Session{
+		WebSocket: conn,
+		SizeChan:  make(chan remotecommand.TerminalSize),
+	}
+
+	// When we attach to the debug container, the prompt of the shell was already printed, so that we tell the user to
+	// press enter, like it is done by "kubectl debug".
+	session.Write([]byte(fmt.Sprintf("Debug container %s is running. If you don't see a command prompt, try pressing enter.\r\n", container)))
+
+	return terminal.StartProcess(ctx, restConfig, reqURL, session)
+}
+
+// createDebugContainer adds a new ephemeral container with the image and shell from the provided options to the Pod via
+// the "ephemeralcontainers" subresource. It returns the name of the created container.
+func createDebugContainer(ctx context.Context, clientset kubernetes.Interface, namespace, name string, options DebugOptions) (string, error) {
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	if options.TargetContainer != "" {
+		found := false
+		for _, container := range pod.Spec.Containers {
+			if container.Name == options.TargetContainer {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return "", fmt.Errorf("target container %s not found in pod %s", options.TargetContainer, name)
+		}
+	}
+
+	container := corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:                     fmt.Sprintf("debugger-%s", utilrand.String(5)),
+			Image:                    options.Image,
+			ImagePullPolicy:          corev1.PullIfNotPresent,
+			Stdin:                    true,
+			TTY:                      true,
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		},
+		TargetContainerName: options.TargetContainer,
+	}
+
+	if options.Shell != "" {
+		container.Command = []string{options.Shell}
+	}
+
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, container)
+
+	_, err = clientset.CoreV1().Pods(namespace).UpdateEphemeralContainers(ctx, name, pod, metav1.UpdateOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	return container.Name, nil
+}
+
+// waitForDebugContainer waits until the ephemeral container with the provided name is running. If the container is
+// terminated or isn't running after the debugContainerTimeout an error is returned.
+func waitForDebugContainer(ctx context.Context, clientset kubernetes.Interface, namespace, name, container string) error {
+	var lastState corev1.ContainerState
+
+	err := wait.PollUntilContextTimeout(ctx, debugContainerPollInterval, debugContainerTimeout, true, func(ctx context.Context) (bool, error) {
+		pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		for _, status := range pod.Status.EphemeralContainerStatuses {
+			if status.Name != container {
+				continue
+			}
+
+			lastState = status.State
+
+			if status.State.Running != nil {
+				return true, nil
+			}
+
+			if status.State.Terminated != nil {
+				return false, fmt.Errorf("debug container %s was terminated: %s", container, status.State.Terminated.Reason)
+			}
+		}
+
+		return false, nil
+	})
+	if err != nil {
+		if wait.Interrupted(err) && lastState.Waiting != nil {
+			return fmt.Errorf("debug container %s is not running: %s", container, lastState.Waiting.Reason)
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newDebugPod(statuses ...corev1.ContainerStatus) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "distroless"}}},
+		Status:     corev1.PodStatus{EphemeralContainerStatuses: statuses},
+	}
+}
+
+func TestGetDebugTerminal(t *testing.T) {
+	t.Run("should fail for invalid shell", func(t *testing.T) {
+		c := &client{clientset: fake.NewSimpleClientset(newDebugPod()), tracer: otel.Tracer("cluster")}
+		err := c.GetDebugTerminal(context.Background(), nil, "default", "pod1", DebugOptions{Shell: "zsh"})
+		require.EqualError(t, err, "invalid shell zsh")
+	})
+
+	t.Run("should fail when debug container could not be created", func(t *testing.T) {
+		c := &client{clientset: fake.NewSimpleClientset(), tracer: otel.Tracer("cluster")}
+		err := c.GetDebugTerminal(context.Background(), nil, "default", "pod1", DebugOptions{})
+		require.Error(t, err)
+	})
+}
+
+func TestCreateDebugContainer(t *testing.T) {
+	t.Run("should create debug container", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newDebugPod())
+
+		name, err := createDebugContainer(context.Background(), clientset, "default", "pod1", DebugOptions{Image: "busybox", TargetContainer: "app", Shell: "sh"})
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(name, "debugger-"))
+
+		pod, err := clientset.CoreV1().Pods("default").Get(context.Background(), "pod1", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Len(t, pod.Spec.EphemeralContainers, 1)
+		require.Equal(t, name, pod.Spec.EphemeralContainers[0].Name)
+		require.Equal(t, "busybox", pod.Spec.EphemeralContainers[0].Image)
+		require.Equal(t, "app", pod.Spec.EphemeralContainers[0].TargetContainerName)
+		require.Equal(t, []string{"sh"}, pod.Spec.EphemeralContainers[0].Command)
+		require.True(t, pod.Spec.EphemeralContainers[0].Stdin)
+		require.True(t, pod.Spec.EphemeralContainers[0].TTY)
+	})
+
+	t.Run("should use default command of image when shell is empty", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newDebugPod())
+
+		_, err := createDebugContainer(context.Background(), clientset, "default", "pod1", DebugOptions{Image: "busybox"})
+		require.NoError(t, err)
+
+		pod, err := clientset.CoreV1().Pods("default").Get(context.Background(), "pod1", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Nil(t, pod.Spec.EphemeralContainers[0].Command)
+		require.Empty(t, pod.Spec.EphemeralContainers[0].TargetContainerName)
+	})
+
+	t.Run("should fail for invalid target container", func(t *testing.T) {
+		_, err := createDebugContainer(context.Background(), fake.NewSimpleClientset(newDebugPod()), "default", "pod1", DebugOptions{Image: "busybox", TargetContainer: "sidecar"})
+		require.Error(t, err)
+	})
+
+	t.Run("should fail when pod does not exist", func(t *testing.T) {
+		_, err := createDebugContainer(context.Background(), fake.NewSimpleClientset(), "default", "pod1", DebugOptions{Image: "busybox"})
+		require.Error(t, err)
+	})
+}
+
+func TestWaitForDebugContainer(t *testing.T) {
+	defaultTimeout := debugContainerTimeout
+	defaultInterval := debugContainerPollInterval
+	defer func() {
+		debugContainerTimeout = defaultTimeout
+		debugContainerPollInterval = defaultInterval
+	}()
+
+	debugContainerTimeout = 100 * time.Millisecond
+	debugContainerPollInterval = 10 * time.Millisecond
+
+	for _, tt := range []struct {
+		name          string
+		pod           *corev1.Pod
+		expectedError error
+	}{
+		{
+			name: "should return when container is running",
+			pod:  newDebugPod(corev1.ContainerStatus{Name: "debugger-abcde", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}),
+		},
+		{
+			name:          "should fail when container is terminated",
+			pod:           newDebugPod(corev1.ContainerStatus{Name: "debugger-abcde", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error"}}}),
+			expectedError: fmt.Errorf("debug container debugger-abcde was terminated: Error"),
+		},
+		{
+			name:          "should fail when container is not running before timeout",
+			pod:           newDebugPod(corev1.ContainerStatus{Name: "debugger-abcde", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}}),
+			expectedError: fmt.Errorf("debug container debugger-abcde is not running: ImagePullBackOff"),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := waitForDebugContainer(context.Background(), fake.NewSimpleClientset(tt.pod), "default", "pod1", "debugger-abcde")
+			if tt.expectedError != nil {
+				require.EqualError(t, err, tt.expectedError.Error())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package kubernetes
+
+import (
+	"context"
+
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
+	dashboardv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/dashboard/v1"
+	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	applicationInformers "github.com/kobsio/kobs/pkg/cluster/kubernetes/clients/application/informers/externalversions"
+	dashboardInformers "github.com/kobsio/kobs/pkg/cluster/kubernetes/clients/dashboard/informers/externalversions"
+	teamInformers "github.com/kobsio/kobs/pkg/cluster/kubernetes/clients/team/informers/externalversions"
+	userInformers "github.com/kobsio/kobs/pkg/cluster/kubernetes/clients/user/informers/externalversions"
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/defaults"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// EventType is the type of a change of an Application, Dashboard, Team or User CR.
+type EventType string
+
+const (
+	// EventTypeAdd is used when a CR was created. It is also used for all existing CRs when the watch is started.
+	EventTypeAdd EventType = "add"
+	// EventTypeUpdate is used when an existing CR was modified.
+	EventTypeUpdate EventType = "update"
+	// EventTypeDelete is used when a CR was deleted.
+	EventTypeDelete EventType = "delete"
+)
+
+// Event is a single change of an Application, Dashboard, Team or User CR. The `resource` field contains the name of the
+// changed resource (`applications`, `dashboards`, `teams` or `users`) and defines which of the spec fields is set. The
+// spec always has the defaults applied, so that the id of the changed CR can also be used for delete events.
+type Event struct {
+	Type        EventType                      `json:"type"`
+	Resource    string                         `json:"resource"`
+	Application *applicationv1.ApplicationSpec `json:"application,omitempty"`
+	Dashboard   *dashboardv1.DashboardSpec     `json:"dashboard,omitempty"`
+	Team        *teamv1.TeamSpec               `json:"team,omitempty"`
+	User        *userv1.UserSpec               `json:"user,omitempty"`
+}
+
+// WatchEvents starts an informer for Applications, Dashboards, Teams and Users and sends an event for each change to
+// the provided `events` channel. Since the informers are listing all CRs on start, the caller receives an add event for
+// each existing CR first. The function blocks until the provided context is canceled.
+func (c *client) WatchEvents(ctx context.Context, cluster string, events chan<- Event) error {
+	ctx, span := c.tracer.Start(ctx, "cluster.WatchEvents")
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	defer span.End()
+
+	send := func(event Event) {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+		}
+	}
+
+	applicationInformerFactory := applicationInformers.NewSharedInformerFactory(c.applicationClientset, 0)
+	dashboardInformerFactory := dashboardInformers.NewSharedInformerFactory(c.dashboardClientset, 0)
+	teamInformerFactory := teamInformers.NewSharedInformerFactory(c.teamClientset, 0)
+	userInformerFactory := userInformers.NewSharedInformerFactory(c.userClientset, 0)
+
+	handlers := []struct {
+		informer cache.SharedIndexInformer
+		handler  cache.ResourceEventHandler
+	}{{
+		informer: applicationInformerFactory.Kobs().V1().Applications().Informer(),
+		handler: eventHandler(send, func(eventType EventType, obj any) (Event, bool) {
+			application, ok := obj.(*applicationv1.Application)
+			if !ok {
+				return Event{}, false
+			}
+
+			spec := defaults.SetApplicationDefaults(application.Spec, cluster, application.Namespace, application.Name)
+			spec.ResourceVersion = application.ResourceVersion
+			return Event{Type: eventType, Resource: "applications", Application: &spec}, true
+		}),
+	}, {
+		informer: dashboardInformerFactory.Kobs().V1().Dashboards().Informer(),
+		handler: eventHandler(send, func(eventType EventType, obj any) (Event, bool) {
+			dashboard, ok := obj.(*dashboardv1.Dashboard)
+			if !ok {
+				return Event{}, false
+			}
+
+			spec := defaults.SetDashboardDefaults(dashboard.Spec, cluster, dashboard.Namespace, dashboard.Name)
+			return Event{Type: eventType, Resource: "dashboards", Dashboard: &spec}, true
+		}),
+	}, {
+		informer: teamInformerFactory.Kobs().V1().Teams().Informer(),
+		handler: eventHandler(send, func(eventType EventType, obj any) (Event, bool) {
+			team, ok := obj.(*teamv1.Team)
+			if !ok {
+				return Event{}, false
+			}
+
+			spec := defaults.SetTeamDefaults(team.Spec, cluster, team.Namespace, team.Name)
+			spec.ResourceVersion = team.ResourceVersion
+			return Event{Type: eventType, Resource: "teams", Team: &spec}, true
+		}),
+	}, {
+		informer: userInformerFactory.Kobs().V1().Users().Informer(),
+		handler: eventHandler(send, func(eventType EventType, obj any) (Event, bool) {
+			user, ok := obj.(*userv1.User)
+			if !ok {
+				return Event{}, false
+			}
+
+			spec := defaults.SetUserDefaults(user.Spec, cluster, user.Namespace, user.Name)
+			spec.ResourceVersion = user.ResourceVersion
+			return Event{Type: eventType, Resource: "users", User: &spec}, true
+		}),
+	}}
+
+	for _, h := range handlers {
+		if _, err := h.informer.AddEventHandler(h.handler); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+	}
+
+	applicationInformerFactory.Start(ctx.Done())
+	dashboardInformerFactory.Start(ctx.Done())
+	teamInformerFactory.Start(ctx.Done())
+	userInformerFactory.Start(ctx.Done())
+
+	<-ctx.Done()
+	return nil
+}
+
+// eventHandler returns a handler for an informer, which converts all added, updated and deleted objects via the
+// provided `toEvent` function and passes the created event to the `send` function. Deleted objects which are wrapped
+// in a tombstone, because the informer missed the delete event, are unwrapped before they are converted.
+func eventHandler(send func(event Event), toEvent func(eventType EventType, obj any) (Event, bool)) cache.ResourceEventHandler {
+	handle := func(eventType EventType, obj any) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+
+		if event, ok := toEvent(eventType, obj); ok {
+			send(event)
+		}
+	}
+
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { handle(EventTypeAdd, obj) },
+		UpdateFunc: func(oldObj, newObj any) { handle(EventTypeUpdate, newObj) },
+		DeleteFunc: func(obj any) { handle(EventTypeDelete, obj) },
+	}
+}
@@ -0,0 +1,77 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
+	applicationfakeclient "github.com/kobsio/kobs/pkg/cluster/kubernetes/clients/application/clientset/versioned/fake"
+	dashboardfakeclient "github.com/kobsio/kobs/pkg/cluster/kubernetes/clients/dashboard/clientset/versioned/fake"
+	teamfakeclient "github.com/kobsio/kobs/pkg/cluster/kubernetes/clients/team/clientset/versioned/fake"
+	userfakeclient "github.com/kobsio/kobs/pkg/cluster/kubernetes/clients/user/clientset/versioned/fake"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWatchEvents(t *testing.T) {
+	client := client{
+		applicationClientset: applicationfakeclient.NewSimpleClientset(),
+		dashboardClientset:   dashboardfakeclient.NewSimpleClientset(),
+		teamClientset: teamfakeclient.NewSimpleClientset(&teamv1.Team{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "team1",
+				Namespace: "default",
+			},
+			Spec: teamv1.TeamSpec{
+				ID: "team1@kobs.io",
+			},
+		}),
+		userClientset: userfakeclient.NewSimpleClientset(),
+		tracer:        otel.Tracer("cluster"),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan Event)
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- client.WatchEvents(ctx, "cluster", events)
+	}()
+
+	var receive = func() Event {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(10 * time.Second):
+			require.Fail(t, "timeout while waiting for event")
+			return Event{}
+		}
+	}
+
+	t.Run("should send add event for existing team", func(t *testing.T) {
+		event := receive()
+		require.Equal(t, EventTypeAdd, event.Type)
+		require.Equal(t, "teams", event.Resource)
+		require.Equal(t, teamv1.TeamSpec{ID: "team1@kobs.io", Cluster: "cluster", Namespace: "default", Name: "team1"}, *event.Team)
+	})
+
+	t.Run("should send delete event for deleted team", func(t *testing.T) {
+		err := client.teamClientset.KobsV1().Teams("default").Delete(ctx, "team1", metav1.DeleteOptions{})
+		require.NoError(t, err)
+
+		event := receive()
+		require.Equal(t, EventTypeDelete, event.Type)
+		require.Equal(t, "teams", event.Resource)
+		require.Equal(t, teamv1.TeamSpec{ID: "team1@kobs.io", Cluster: "cluster", Namespace: "default", Name: "team1"}, *event.Team)
+	})
+
+	t.Run("should return when context is canceled", func(t *testing.T) {
+		cancel()
+		require.NoError(t, <-errs)
+	})
+}
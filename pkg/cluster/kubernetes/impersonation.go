@@ -0,0 +1,96 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/kobsio/kobs/pkg/instrument/log"
+	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// ImpersonateUserHeader is the header which is used by the hub to forward the id of the authenticated user to the
+	// cluster.
+	ImpersonateUserHeader = "X-Kobs-User"
+	// ImpersonateGroupHeader is the header which is used by the hub to forward the teams of the authenticated user to
+	// the cluster. The header is set once for each team.
+	ImpersonateGroupHeader = "X-Kobs-Group"
+)
+
+// ErrMissingImpersonationUser is returned when the impersonation is enabled, but a request doesn't contain the user
+// which should be impersonated. In this case we must not fall back to the service account of the cluster component,
+// because this would grant the permissions of the service account to every request without a user.
+var ErrMissingImpersonationUser = errors.New("user to impersonate is missing")
+
+// Key to use when setting the impersonation config.
+type ctxKeyImpersonation int
+
+// impersonationKey is the key that holds the impersonation config in a request context.
+const impersonationKey ctxKeyImpersonation = 0
+
+// WithImpersonation returns a copy of the provided context, which contains the user and groups which should be
+// impersonated for requests against the Kubernetes API.
+func WithImpersonation(ctx context.Context, user string, groups []string) context.Context {
+	return context.WithValue(ctx, impersonationKey, rest.ImpersonationConfig{UserName: user, Groups: groups})
+}
+
+// ImpersonationHandler returns a middleware which reads the user and groups forwarded by the hub from the request
+// headers and adds them to the request context, so that they can be impersonated by the Kubernetes client. When the
+// impersonation is enabled, requests without a user are rejected.
+func ImpersonationHandler(enabled bool) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			user := r.Header.Get(ImpersonateUserHeader)
+			if user == "" {
+				if enabled {
+					log.Warn(r.Context(), "The request doesn't contain a user to impersonate")
+					errresponse.Render(w, r, http.StatusForbidden, "The request doesn't contain a user to impersonate")
+					return
+				}
+
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithImpersonation(r.Context(), user, r.Header.Values(ImpersonateGroupHeader))))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// ImpersonationEnabled returns true when the user and groups forwarded by the hub are impersonated for requests against
+// the Kubernetes API.
+func (c *client) ImpersonationEnabled() bool {
+	return c.impersonation
+}
+
+// getImpersonatedClient returns the rest config and clientset which should be used for a request. When the
+// impersonation is enabled, the returned rest config and clientset impersonate the user and its groups from the
+// context, so that the RBAC rules of the Kubernetes cluster are applied for the user. If the context doesn't contain a
+// user, the ErrMissingImpersonationUser error is returned. When the impersonation is disabled the rest config and
+// clientset of the cluster component are returned.
+func (c *client) getImpersonatedClient(ctx context.Context) (*rest.Config, kubernetes.Interface, error) {
+	if !c.impersonation {
+		return c.restConfig, c.clientset, nil
+	}
+
+	impersonationConfig, ok := ctx.Value(impersonationKey).(rest.ImpersonationConfig)
+	if !ok || impersonationConfig.UserName == "" {
+		return nil, nil, ErrMissingImpersonationUser
+	}
+
+	restConfig := rest.CopyConfig(c.restConfig)
+	restConfig.Impersonate = impersonationConfig
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return restConfig, clientset, nil
+}
@@ -0,0 +1,89 @@
+package kubernetes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestImpersonationHandler(t *testing.T) {
+	t.Run("should add user and groups to context", func(t *testing.T) {
+		var impersonationConfig rest.ImpersonationConfig
+		handler := ImpersonationHandler(true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			impersonationConfig, _ = r.Context().Value(impersonationKey).(rest.ImpersonationConfig)
+		}))
+
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(ImpersonateUserHeader, "user1@kobs.io")
+		req.Header.Add(ImpersonateGroupHeader, "team1@kobs.io")
+		req.Header.Add(ImpersonateGroupHeader, "team2@kobs.io")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		require.Equal(t, rest.ImpersonationConfig{UserName: "user1@kobs.io", Groups: []string{"team1@kobs.io", "team2@kobs.io"}}, impersonationConfig)
+	})
+
+	t.Run("should not add user to context when header is missing and impersonation is disabled", func(t *testing.T) {
+		var ok bool
+		handler := ImpersonationHandler(false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, ok = r.Context().Value(impersonationKey).(rest.ImpersonationConfig)
+		}))
+
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.False(t, ok)
+	})
+
+	t.Run("should reject request when header is missing and impersonation is enabled", func(t *testing.T) {
+		var called bool
+		handler := ImpersonationHandler(true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.False(t, called)
+	})
+}
+
+func TestGetImpersonatedClient(t *testing.T) {
+	restConfig := &rest.Config{Host: "https://localhost:6443"}
+	clientset := fake.NewSimpleClientset()
+	ctx := WithImpersonation(context.Background(), "user1@kobs.io", []string{"team1@kobs.io"})
+
+	t.Run("should return default client when impersonation is disabled", func(t *testing.T) {
+		c := &client{restConfig: restConfig, clientset: clientset}
+		actualRestConfig, actualClientset, err := c.getImpersonatedClient(ctx)
+		require.NoError(t, err)
+		require.Equal(t, restConfig, actualRestConfig)
+		require.Equal(t, clientset, actualClientset)
+	})
+
+	t.Run("should return error when context does not contain a user", func(t *testing.T) {
+		c := &client{restConfig: restConfig, clientset: clientset, impersonation: true}
+		actualRestConfig, actualClientset, err := c.getImpersonatedClient(context.Background())
+		require.ErrorIs(t, err, ErrMissingImpersonationUser)
+		require.Nil(t, actualRestConfig)
+		require.Nil(t, actualClientset)
+	})
+
+	t.Run("should return impersonated client", func(t *testing.T) {
+		c := &client{restConfig: restConfig, clientset: clientset, impersonation: true}
+		actualRestConfig, actualClientset, err := c.getImpersonatedClient(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "https://localhost:6443", actualRestConfig.Host)
+		require.Equal(t, rest.ImpersonationConfig{UserName: "user1@kobs.io", Groups: []string{"team1@kobs.io"}}, actualRestConfig.Impersonate)
+		require.NotEqual(t, clientset, actualClientset)
+		require.Empty(t, restConfig.Impersonate.UserName)
+	})
+}
@@ -12,9 +12,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"path"
 	"regexp"
 	"sort"
 	"strings"
@@ -29,6 +31,7 @@ import (
 	userClientsetVersioned "github.com/kobsio/kobs/pkg/cluster/kubernetes/clients/user/clientset/versioned"
 	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/copy"
 	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/defaults"
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/portforward"
 	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/provider"
 	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/terminal"
 	"github.com/kobsio/kobs/pkg/instrument/log"
@@ -41,8 +44,10 @@ import (
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/kubernetes"
@@ -52,7 +57,10 @@ import (
 )
 
 type Config struct {
-	Provider provider.Config `json:"provider" embed:"" prefix:"provider." envprefix:"PROVIDER_"`
+	Provider       provider.Config `json:"provider" embed:"" prefix:"provider." envprefix:"PROVIDER_"`
+	Impersonation  bool            `json:"impersonation" env:"IMPERSONATION" default:"false" help:"Impersonate the user and teams forwarded by the hub for requests to get, edit, create and delete resources, to get logs, to exec into and to copy files from and to Pods, so that the RBAC rules of the Kubernetes cluster are applied per user."`
+	DebugImage     string          `json:"debugImage" env:"DEBUG_IMAGE" default:"busybox:stable" help:"The default image for ephemeral debug containers, which is used when the user doesn't provide an image."`
+	MaxArchiveSize int64           `json:"maxArchiveSize" env:"MAX_ARCHIVE_SIZE" default:"1073741824" help:"The maximum size of all files in bytes, which can be downloaded from or uploaded to a Pod as archive. Set to 0 to disable the limit."`
 }
 
 // Client is the interface to interact with an Kubernetes cluster.
@@ -65,9 +73,14 @@ type Client interface {
 	CreateResource(ctx context.Context, namespace, name, path, resource string, body []byte) error
 	GetLogs(ctx context.Context, namespace, name, container, regex string, since, tail int64, previous bool) (string, error)
 	StreamLogs(ctx context.Context, conn *websocket.Conn, namespace, name, container string, since, tail int64, follow bool) error
+	StreamPodsLogs(ctx context.Context, conn *websocket.Conn, namespace string, options PodsLogsOptions) error
 	GetTerminal(ctx context.Context, conn *websocket.Conn, namespace, name, container, shell string) error
+	GetDebugTerminal(ctx context.Context, conn *websocket.Conn, namespace, name string, options DebugOptions) error
 	CopyFileFromPod(ctx context.Context, w http.ResponseWriter, namespace, name, container, srcPath string) error
 	CopyFileToPod(ctx context.Context, namespace, name, container string, srcFile multipart.File, destPath string) error
+	CopyDirectoryFromPod(ctx context.Context, w http.ResponseWriter, namespace, name, container, srcPath string) error
+	CopyArchiveToPod(ctx context.Context, namespace, name, container string, archive io.Reader, destPath string) (*copy.ArchiveStats, error)
+	PortForward(ctx context.Context, conn *websocket.Conn, namespace, name string, port int) error
 	GetApplications(ctx context.Context, cluster, namespace string) ([]applicationv1.ApplicationSpec, error)
 	GetApplication(ctx context.Context, cluster, namespace, name string) (*applicationv1.ApplicationSpec, error)
 	GetTeams(ctx context.Context, cluster, namespace string) ([]teamv1.TeamSpec, error)
@@ -76,7 +89,12 @@ type Client interface {
 	GetDashboard(ctx context.Context, cluster, namespace, name string) (*dashboardv1.DashboardSpec, error)
 	GetUsers(ctx context.Context, cluster, namespace string) ([]userv1.UserSpec, error)
 	GetUser(ctx context.Context, cluster, namespace, name string) (*userv1.UserSpec, error)
+	SaveApplication(ctx context.Context, application applicationv1.ApplicationSpec) (*applicationv1.ApplicationSpec, error)
+	SaveTeam(ctx context.Context, team teamv1.TeamSpec) (*teamv1.TeamSpec, error)
+	SaveUser(ctx context.Context, user userv1.UserSpec) (*userv1.UserSpec, error)
 	GetCRDs(ctx context.Context) ([]CRD, error)
+	WatchEvents(ctx context.Context, cluster string, events chan<- Event) error
+	ImpersonationEnabled() bool
 }
 
 // client implements the Client interface. It contains all required fields and methods to interact with an Kubernetes
@@ -93,6 +111,9 @@ type client struct {
 	teamClientset        teamClientsetVersioned.Interface
 	dashboardClientset   dashboardClientsetVersioned.Interface
 	userClientset        userClientsetVersioned.Interface
+	impersonation        bool
+	debugImage           string
+	maxArchiveSize       int64
 	tracer               trace.Tracer
 }
 
@@ -170,9 +191,16 @@ func (c *client) GetResources(ctx context.Context, namespace, name, path, resour
 	span.SetAttributes(attribute.Key("param").String(param))
 	defer span.End()
 
+	_, clientset, err := c.getImpersonatedClient(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
 	if name != "" {
 		if namespace != "" {
-			res, err := c.clientset.CoreV1().RESTClient().Get().AbsPath(path).Namespace(namespace).Resource(resource).Name(name).DoRaw(ctx)
+			res, err := clientset.CoreV1().RESTClient().Get().AbsPath(path).Namespace(namespace).Resource(resource).Name(name).DoRaw(ctx)
 			if err != nil {
 				log.Error(ctx, "Could not get resources", zap.Error(err), zap.String("namespace", namespace), zap.String("name", name), zap.String("path", path), zap.String("resource", resource))
 				span.RecordError(err)
@@ -183,7 +211,7 @@ func (c *client) GetResources(ctx context.Context, namespace, name, path, resour
 			return res, nil
 		}
 
-		res, err := c.clientset.CoreV1().RESTClient().Get().AbsPath(path).Resource(resource).Name(name).DoRaw(ctx)
+		res, err := clientset.CoreV1().RESTClient().Get().AbsPath(path).Resource(resource).Name(name).DoRaw(ctx)
 		if err != nil {
 			log.Error(ctx, "Could not get resources", zap.Error(err), zap.String("name", name), zap.String("path", path), zap.String("resource", resource))
 			span.RecordError(err)
@@ -194,7 +222,7 @@ func (c *client) GetResources(ctx context.Context, namespace, name, path, resour
 		return res, nil
 	}
 
-	res, err := c.clientset.CoreV1().RESTClient().Get().AbsPath(path).Namespace(namespace).Resource(resource).Param(paramName, param).DoRaw(ctx)
+	res, err := clientset.CoreV1().RESTClient().Get().AbsPath(path).Namespace(namespace).Resource(resource).Param(paramName, param).DoRaw(ctx)
 	if err != nil {
 		log.Error(ctx, "Could not get resources", zap.Error(err), zap.String("namespace", namespace), zap.String("path", path), zap.String("resource", resource))
 		span.RecordError(err)
@@ -215,7 +243,14 @@ func (c *client) DeleteResource(ctx context.Context, namespace, name, path, reso
 	span.SetAttributes(attribute.Key("resource").String(resource))
 	defer span.End()
 
-	_, err := c.clientset.CoreV1().RESTClient().Delete().AbsPath(path).Namespace(namespace).Resource(resource).Name(name).Body(body).DoRaw(ctx)
+	_, clientset, err := c.getImpersonatedClient(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	_, err = clientset.CoreV1().RESTClient().Delete().AbsPath(path).Namespace(namespace).Resource(resource).Name(name).Body(body).DoRaw(ctx)
 	if err != nil {
 		log.Error(ctx, "Could not delete resources", zap.Error(err), zap.String("namespace", namespace), zap.String("path", path), zap.String("resource", resource))
 		span.RecordError(err)
@@ -237,8 +272,15 @@ func (c *client) PatchResource(ctx context.Context, namespace, name, path, resou
 	span.SetAttributes(attribute.Key("subResource").String(subResource))
 	defer span.End()
 
+	_, clientset, err := c.getImpersonatedClient(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
 	if subResource != "" {
-		_, err := c.clientset.CoreV1().RESTClient().Patch(types.JSONPatchType).AbsPath(path).Namespace(namespace).Resource(resource).Name(name).SubResource(subResource).Body(body).DoRaw(ctx)
+		_, err := clientset.CoreV1().RESTClient().Patch(types.JSONPatchType).AbsPath(path).Namespace(namespace).Resource(resource).Name(name).SubResource(subResource).Body(body).DoRaw(ctx)
 		if err != nil {
 			log.Error(ctx, "Could not patch resources", zap.Error(err), zap.String("namespace", namespace), zap.String("name", name), zap.String("path", path), zap.String("resource", resource), zap.String("subResource", subResource))
 			span.RecordError(err)
@@ -248,7 +290,7 @@ func (c *client) PatchResource(ctx context.Context, namespace, name, path, resou
 		return nil
 	}
 
-	_, err := c.clientset.CoreV1().RESTClient().Patch(types.JSONPatchType).AbsPath(path).Namespace(namespace).Resource(resource).Name(name).Body(body).DoRaw(ctx)
+	_, err = clientset.CoreV1().RESTClient().Patch(types.JSONPatchType).AbsPath(path).Namespace(namespace).Resource(resource).Name(name).Body(body).DoRaw(ctx)
 	if err != nil {
 		log.Error(ctx, "Could not patch resources", zap.Error(err), zap.String("namespace", namespace), zap.String("name", name), zap.String("path", path), zap.String("resource", resource))
 		span.RecordError(err)
@@ -269,7 +311,14 @@ func (c *client) CreateResource(ctx context.Context, namespace, name, path, reso
 	span.SetAttributes(attribute.Key("resource").String(resource))
 	defer span.End()
 
-	_, err := c.clientset.CoreV1().RESTClient().Post().AbsPath(path).Namespace(namespace).Resource(resource).Body(body).DoRaw(ctx)
+	_, clientset, err := c.getImpersonatedClient(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	_, err = clientset.CoreV1().RESTClient().Post().AbsPath(path).Namespace(namespace).Resource(resource).Body(body).DoRaw(ctx)
 	if err != nil {
 		log.Error(ctx, "Could not create resources", zap.Error(err), zap.String("namespace", namespace), zap.String("path", path), zap.String("resource", resource))
 		span.RecordError(err)
@@ -294,6 +343,13 @@ func (c *client) GetLogs(ctx context.Context, namespace, name, container, regex
 	span.SetAttributes(attribute.Key("previous").Bool(previous))
 	defer span.End()
 
+	_, clientset, err := c.getImpersonatedClient(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return "", err
+	}
+
 	options := &corev1.PodLogOptions{
 		Container:    container,
 		SinceSeconds: &since,
@@ -304,7 +360,7 @@ func (c *client) GetLogs(ctx context.Context, namespace, name, container, regex
 		options.TailLines = &tail
 	}
 
-	res, err := c.clientset.CoreV1().Pods(namespace).GetLogs(name, options).DoRaw(ctx)
+	res, err := clientset.CoreV1().Pods(namespace).GetLogs(name, options).DoRaw(ctx)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -345,6 +401,13 @@ func (c *client) StreamLogs(ctx context.Context, conn *websocket.Conn, namespace
 	span.SetAttributes(attribute.Key("follow").Bool(follow))
 	defer span.End()
 
+	_, clientset, err := c.getImpersonatedClient(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
 	options := &corev1.PodLogOptions{
 		Container:    container,
 		SinceSeconds: &since,
@@ -355,7 +418,7 @@ func (c *client) StreamLogs(ctx context.Context, conn *websocket.Conn, namespace
 		options.TailLines = &tail
 	}
 
-	stream, err := c.clientset.CoreV1().Pods(namespace).GetLogs(name, options).Stream(ctx)
+	stream, err := clientset.CoreV1().Pods(namespace).GetLogs(name, options).Stream(ctx)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -406,7 +469,14 @@ func (c *client) GetTerminal(ctx context.Context, conn *websocket.Conn, namespac
 	span.SetAttributes(attribute.Key("shell").String(shell))
 	defer span.End()
 
-	reqURL, err := url.Parse(fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/exec?container=%s&command=%s&stdin=true&stdout=true&stderr=true&tty=true", c.restConfig.Host, namespace, name, container, shell))
+	restConfig, _, err := c.getImpersonatedClient(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	reqURL, err := url.Parse(fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/exec?container=%s&command=%s&stdin=true&stdout=true&stderr=true&tty=true", restConfig.Host, namespace, name, container, shell))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -424,7 +494,7 @@ func (c *client) GetTerminal(ctx context.Context, conn *websocket.Conn, namespac
 		SizeChan:  make(chan remotecommand.TerminalSize),
 	}
 
-	return terminal.StartProcess(ctx, c.restConfig, reqURL, session)
+	return terminal.StartProcess(ctx, restConfig, reqURL, session)
 }
 
 // CopyFileFromPod creates the request URL for downloading a file from the specified container.
@@ -436,15 +506,22 @@ func (c *client) CopyFileFromPod(ctx context.Context, w http.ResponseWriter, nam
 	span.SetAttributes(attribute.Key("srcPath").String(srcPath))
 	defer span.End()
 
+	restConfig, _, err := c.getImpersonatedClient(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
 	command := fmt.Sprintf("&command=tar&command=cf&command=-&command=%s", srcPath)
-	reqURL, err := url.Parse(fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/exec?container=%s&stdin=true&stdout=true&stderr=true&tty=false%s", c.restConfig.Host, namespace, name, container, command))
+	reqURL, err := url.Parse(fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/exec?container=%s&stdin=true&stdout=true&stderr=true&tty=false%s", restConfig.Host, namespace, name, container, command))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
 
-	return copy.FileFromPod(ctx, w, c.restConfig, reqURL)
+	return copy.FileFromPod(ctx, w, restConfig, reqURL)
 }
 
 // CopyFileToPod creates the request URL for uploading a file to the specified container.
@@ -456,15 +533,183 @@ func (c *client) CopyFileToPod(ctx context.Context, namespace, name, container s
 	span.SetAttributes(attribute.Key("destPath").String(destPath))
 	defer span.End()
 
+	restConfig, _, err := c.getImpersonatedClient(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
 	command := fmt.Sprintf("&command=cp&command=/dev/stdin&command=%s", destPath)
-	reqURL, err := url.Parse(fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/exec?container=%s&stdin=true&stdout=true&stderr=true&tty=false%s", c.restConfig.Host, namespace, name, container, command))
+	reqURL, err := url.Parse(fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/exec?container=%s&stdin=true&stdout=true&stderr=true&tty=false%s", restConfig.Host, namespace, name, container, command))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return copy.FileToPod(ctx, restConfig, reqURL, srcFile, destPath)
+}
+
+// CopyDirectoryFromPod creates the request URL for downloading a directory or file from the specified container as
+// tar.gz archive.
+func (c *client) CopyDirectoryFromPod(ctx context.Context, w http.ResponseWriter, namespace, name, container, srcPath string) error {
+	ctx, span := c.tracer.Start(ctx, "cluster.CopyDirectoryFromPod")
+	span.SetAttributes(attribute.Key("namespace").String(namespace))
+	span.SetAttributes(attribute.Key("name").String(name))
+	span.SetAttributes(attribute.Key("container").String(container))
+	span.SetAttributes(attribute.Key("srcPath").String(srcPath))
+	defer span.End()
+
+	restConfig, _, err := c.getImpersonatedClient(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	// We change into the parent directory of the source path, so that the archive only contains the source path
+	// relative to its parent directory. When the root directory is downloaded, the archive contains all files in it.
+	//
+	// The source path is always cleaned to an absolute path, so that the directory can not be parsed as an option by
+	// tar. The base name is passed after "--" for the same reason, e.g. for "/--checkpoint-action=exec=sh".
+	srcPath = path.Clean("/" + srcPath)
+	dir, base := path.Dir(srcPath), path.Base(srcPath)
+	if base == "/" {
+		base = "."
+	}
+
+	query := url.Values{}
+	query.Set("container", container)
+	query.Set("stdout", "true")
+	query.Set("stderr", "true")
+	query["command"] = []string{"tar", "cf", "-", "-C", dir, "--", base}
+
+	reqURL, err := url.Parse(fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/exec?%s", restConfig.Host, namespace, name, query.Encode()))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	filename := base
+	if filename == "." {
+		filename = name
+	}
+
+	stats, err := copy.DirectoryFromPod(ctx, w, restConfig, reqURL, filename+".tar.gz", c.maxArchiveSize, copyProgress(ctx, "Download archive"))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
 
-	return copy.FileToPod(ctx, c.restConfig, reqURL, srcFile, destPath)
+	span.SetAttributes(attribute.Key("files").Int(stats.Files))
+	span.SetAttributes(attribute.Key("size").Int64(stats.Size))
+	span.SetAttributes(attribute.Key("skipped").Int(stats.Skipped))
+	return nil
+}
+
+// CopyArchiveToPod creates the request URL for uploading a tar or tar.gz archive to the specified container, where it
+// is extracted in the destination path.
+func (c *client) CopyArchiveToPod(ctx context.Context, namespace, name, container string, archive io.Reader, destPath string) (*copy.ArchiveStats, error) {
+	ctx, span := c.tracer.Start(ctx, "cluster.CopyArchiveToPod")
+	span.SetAttributes(attribute.Key("namespace").String(namespace))
+	span.SetAttributes(attribute.Key("name").String(name))
+	span.SetAttributes(attribute.Key("container").String(container))
+	span.SetAttributes(attribute.Key("destPath").String(destPath))
+	defer span.End()
+
+	// The destination path is passed as argument to tar, so that it must not start with "-", otherwise it would be
+	// parsed as an option.
+	if destPath == "" || strings.HasPrefix(destPath, "-") {
+		err := fmt.Errorf("%w: %s", copy.ErrInvalidPath, destPath)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	restConfig, _, err := c.getImpersonatedClient(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("container", container)
+	query.Set("stdin", "true")
+	query.Set("stdout", "true")
+	query.Set("stderr", "true")
+	query["command"] = []string{"tar", "xmf", "-", "-C", destPath}
+
+	reqURL, err := url.Parse(fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/exec?%s", restConfig.Host, namespace, name, query.Encode()))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	stats, err := copy.ArchiveToPod(ctx, restConfig, reqURL, archive, c.maxArchiveSize, copyProgress(ctx, "Upload archive"))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Key("files").Int(stats.Files))
+	span.SetAttributes(attribute.Key("size").Int64(stats.Size))
+	return &stats, nil
+}
+
+// copyProgress returns a progress function for the transfer of an archive, which logs the number of files and the size
+// of the transferred archive each time another 100 MiB were transferred.
+func copyProgress(ctx context.Context, msg string) copy.Progress {
+	var next int64 = 100 * 1024 * 1024
+
+	return func(stats copy.ArchiveStats) {
+		if stats.Size < next {
+			return
+		}
+
+		log.Info(ctx, msg, zap.Int("files", stats.Files), zap.Int64("size", stats.Size))
+		for next <= stats.Size {
+			next += 100 * 1024 * 1024
+		}
+	}
+}
+
+// PortForward forwards the given port of a Pod via the given WebSocket connection. Each WebSocket connection is used
+// for one TCP connection to the Pod.
+func (c *client) PortForward(ctx context.Context, conn *websocket.Conn, namespace, name string, port int) error {
+	ctx, span := c.tracer.Start(ctx, "cluster.PortForward")
+	span.SetAttributes(attribute.Key("namespace").String(namespace))
+	span.SetAttributes(attribute.Key("name").String(name))
+	span.SetAttributes(attribute.Key("port").Int(port))
+	defer span.End()
+
+	restConfig, _, err := c.getImpersonatedClient(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	reqURL, err := url.Parse(fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/portforward", restConfig.Host, namespace, name))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	err = portforward.StartForwarding(ctx, restConfig, reqURL, conn, port)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
 }
 
 // GetApplications returns a list of applications gor the given namespace. It also adds the cluster, namespace and
@@ -485,6 +730,7 @@ func (c *client) GetApplications(ctx context.Context, cluster, namespace string)
 
 	for _, applicationItem := range applicationsList.Items {
 		application := defaults.SetApplicationDefaults(applicationItem.Spec, cluster, applicationItem.Namespace, applicationItem.Name)
+		application.ResourceVersion = applicationItem.ResourceVersion
 		applications = append(applications, application)
 	}
 
@@ -508,6 +754,7 @@ func (c *client) GetApplication(ctx context.Context, cluster, namespace, name st
 	}
 
 	application := defaults.SetApplicationDefaults(applicationItem.Spec, cluster, namespace, name)
+	application.ResourceVersion = applicationItem.ResourceVersion
 	return &application, nil
 }
 
@@ -529,6 +776,7 @@ func (c *client) GetTeams(ctx context.Context, cluster, namespace string) ([]tea
 
 	for _, teamItem := range teamsList.Items {
 		team := defaults.SetTeamDefaults(teamItem.Spec, cluster, teamItem.Namespace, teamItem.Name)
+		team.ResourceVersion = teamItem.ResourceVersion
 		teams = append(teams, team)
 	}
 
@@ -552,6 +800,7 @@ func (c *client) GetTeam(ctx context.Context, cluster, namespace, name string) (
 	}
 
 	team := defaults.SetTeamDefaults(teamItem.Spec, cluster, namespace, name)
+	team.ResourceVersion = teamItem.ResourceVersion
 	return &team, nil
 }
 
@@ -617,6 +866,7 @@ func (c *client) GetUsers(ctx context.Context, cluster, namespace string) ([]use
 
 	for _, userItem := range usersList.Items {
 		user := defaults.SetUserDefaults(userItem.Spec, cluster, userItem.Namespace, userItem.Name)
+		user.ResourceVersion = userItem.ResourceVersion
 		users = append(users, user)
 	}
 
@@ -640,9 +890,134 @@ func (c *client) GetUser(ctx context.Context, cluster, namespace, name string) (
 	}
 
 	user := defaults.SetUserDefaults(userItem.Spec, cluster, namespace, name)
+	user.ResourceVersion = userItem.ResourceVersion
 	return &user, nil
 }
 
+// SaveApplication creates or updates the Application CR for the provided application. The cluster, namespace and name
+// of the CR are taken from the application. If the application contains a resource version, the existing CR is updated
+// and the Kubernetes API rejects the update when the CR was modified in the meantime. If the application doesn't
+// contain a resource version, a new CR is created. In both cases a conflict error is returned, when the CR was changed,
+// deleted or already exists, so that the caller can check the error via [apierrors.IsConflict].
+//
+// The returned application is the saved Application CR with the defaults and the new resource version.
+func (c *client) SaveApplication(ctx context.Context, application applicationv1.ApplicationSpec) (*applicationv1.ApplicationSpec, error) {
+	ctx, span := c.tracer.Start(ctx, "cluster.SaveApplication")
+	span.SetAttributes(attribute.Key("namespace").String(application.Namespace))
+	span.SetAttributes(attribute.Key("name").String(application.Name))
+	defer span.End()
+
+	cluster, namespace, name, resourceVersion := application.Cluster, application.Namespace, application.Name, application.ResourceVersion
+	spec := application
+	spec.ID, spec.UpdatedAt, spec.ResourceVersion, spec.Cluster, spec.Namespace, spec.Name = "", 0, "", "", "", ""
+
+	var applicationItem *applicationv1.Application
+	var err error
+
+	if resourceVersion == "" {
+		applicationItem, err = c.applicationClientset.KobsV1().Applications(namespace).Create(ctx, &applicationv1.Application{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}, Spec: spec}, metav1.CreateOptions{})
+	} else {
+		applicationItem, err = c.applicationClientset.KobsV1().Applications(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			applicationItem.ResourceVersion = resourceVersion
+			applicationItem.Spec = spec
+			applicationItem, err = c.applicationClientset.KobsV1().Applications(namespace).Update(ctx, applicationItem, metav1.UpdateOptions{})
+		}
+	}
+	if err != nil {
+		err = conflictError(err, "applications", name)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	savedApplication := defaults.SetApplicationDefaults(applicationItem.Spec, cluster, namespace, name)
+	savedApplication.ResourceVersion = applicationItem.ResourceVersion
+	return &savedApplication, nil
+}
+
+// SaveTeam creates or updates the Team CR for the provided team. It works the same as the [SaveApplication] method.
+func (c *client) SaveTeam(ctx context.Context, team teamv1.TeamSpec) (*teamv1.TeamSpec, error) {
+	ctx, span := c.tracer.Start(ctx, "cluster.SaveTeam")
+	span.SetAttributes(attribute.Key("namespace").String(team.Namespace))
+	span.SetAttributes(attribute.Key("name").String(team.Name))
+	defer span.End()
+
+	cluster, namespace, name, resourceVersion := team.Cluster, team.Namespace, team.Name, team.ResourceVersion
+	spec := team
+	spec.UpdatedAt, spec.ResourceVersion, spec.Cluster, spec.Namespace, spec.Name = 0, "", "", "", ""
+
+	var teamItem *teamv1.Team
+	var err error
+
+	if resourceVersion == "" {
+		teamItem, err = c.teamClientset.KobsV1().Teams(namespace).Create(ctx, &teamv1.Team{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}, Spec: spec}, metav1.CreateOptions{})
+	} else {
+		teamItem, err = c.teamClientset.KobsV1().Teams(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			teamItem.ResourceVersion = resourceVersion
+			teamItem.Spec = spec
+			teamItem, err = c.teamClientset.KobsV1().Teams(namespace).Update(ctx, teamItem, metav1.UpdateOptions{})
+		}
+	}
+	if err != nil {
+		err = conflictError(err, "teams", name)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	savedTeam := defaults.SetTeamDefaults(teamItem.Spec, cluster, namespace, name)
+	savedTeam.ResourceVersion = teamItem.ResourceVersion
+	return &savedTeam, nil
+}
+
+// SaveUser creates or updates the User CR for the provided user. It works the same as the [SaveApplication] method.
+func (c *client) SaveUser(ctx context.Context, user userv1.UserSpec) (*userv1.UserSpec, error) {
+	ctx, span := c.tracer.Start(ctx, "cluster.SaveUser")
+	span.SetAttributes(attribute.Key("namespace").String(user.Namespace))
+	span.SetAttributes(attribute.Key("name").String(user.Name))
+	defer span.End()
+
+	cluster, namespace, name, resourceVersion := user.Cluster, user.Namespace, user.Name, user.ResourceVersion
+	spec := user
+	spec.UpdatedAt, spec.ResourceVersion, spec.Cluster, spec.Namespace, spec.Name = 0, "", "", "", ""
+
+	var userItem *userv1.User
+	var err error
+
+	if resourceVersion == "" {
+		userItem, err = c.userClientset.KobsV1().Users(namespace).Create(ctx, &userv1.User{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}, Spec: spec}, metav1.CreateOptions{})
+	} else {
+		userItem, err = c.userClientset.KobsV1().Users(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			userItem.ResourceVersion = resourceVersion
+			userItem.Spec = spec
+			userItem, err = c.userClientset.KobsV1().Users(namespace).Update(ctx, userItem, metav1.UpdateOptions{})
+		}
+	}
+	if err != nil {
+		err = conflictError(err, "users", name)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	savedUser := defaults.SetUserDefaults(userItem.Spec, cluster, namespace, name)
+	savedUser.ResourceVersion = userItem.ResourceVersion
+	return &savedUser, nil
+}
+
+// conflictError converts the errors returned by the Kubernetes API when a CR already exists or was deleted into a
+// conflict error. For a save operation all these errors mean, that the CR was modified since it was loaded by the user.
+func conflictError(err error, resource, name string) error {
+	if apierrors.IsAlreadyExists(err) || apierrors.IsNotFound(err) {
+		return apierrors.NewConflict(schema.GroupResource{Group: "kobs.io", Resource: resource}, name, err)
+	}
+
+	return err
+}
+
 func (c *client) GetCRDs(ctx context.Context) ([]CRD, error) {
 	ctx, span := c.tracer.Start(ctx, "cluster.GetCRDs")
 	defer span.End()
@@ -754,6 +1129,9 @@ func NewClient(config Config) (Client, error) {
 		teamClientset:        teamClientset,
 		dashboardClientset:   dashboardClientset,
 		userClientset:        userClientset,
+		impersonation:        config.Impersonation,
+		debugImage:           config.DebugImage,
+		maxArchiveSize:       config.MaxArchiveSize,
 		tracer:               otel.Tracer("cluster"),
 	}, nil
 }
@@ -6,6 +6,7 @@ package kubernetes
 
 import (
 	context "context"
+	io "io"
 	multipart "mime/multipart"
 	http "net/http"
 	reflect "reflect"
@@ -16,6 +17,7 @@ import (
 	v10 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/dashboard/v1"
 	v11 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
 	v12 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	copy "github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/copy"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	controllerRuntimeClient "sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -43,6 +45,35 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// CopyArchiveToPod mocks base method.
+func (m *MockClient) CopyArchiveToPod(ctx context.Context, namespace, name, container string, archive io.Reader, destPath string) (*copy.ArchiveStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyArchiveToPod", ctx, namespace, name, container, archive, destPath)
+	ret0, _ := ret[0].(*copy.ArchiveStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CopyArchiveToPod indicates an expected call of CopyArchiveToPod.
+func (mr *MockClientMockRecorder) CopyArchiveToPod(ctx, namespace, name, container, archive, destPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyArchiveToPod", reflect.TypeOf((*MockClient)(nil).CopyArchiveToPod), ctx, namespace, name, container, archive, destPath)
+}
+
+// CopyDirectoryFromPod mocks base method.
+func (m *MockClient) CopyDirectoryFromPod(ctx context.Context, w http.ResponseWriter, namespace, name, container, srcPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyDirectoryFromPod", ctx, w, namespace, name, container, srcPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CopyDirectoryFromPod indicates an expected call of CopyDirectoryFromPod.
+func (mr *MockClientMockRecorder) CopyDirectoryFromPod(ctx, w, namespace, name, container, srcPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyDirectoryFromPod", reflect.TypeOf((*MockClient)(nil).CopyDirectoryFromPod), ctx, w, namespace, name, container, srcPath)
+}
+
 // CopyFileFromPod mocks base method.
 func (m *MockClient) CopyFileFromPod(ctx context.Context, w http.ResponseWriter, namespace, name, container, srcPath string) error {
 	m.ctrl.T.Helper()
@@ -189,6 +220,20 @@ func (mr *MockClientMockRecorder) GetDashboards(ctx, cluster, namespace interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDashboards", reflect.TypeOf((*MockClient)(nil).GetDashboards), ctx, cluster, namespace)
 }
 
+// GetDebugTerminal mocks base method.
+func (m *MockClient) GetDebugTerminal(ctx context.Context, conn *websocket.Conn, namespace, name string, options DebugOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDebugTerminal", ctx, conn, namespace, name, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetDebugTerminal indicates an expected call of GetDebugTerminal.
+func (mr *MockClientMockRecorder) GetDebugTerminal(ctx, conn, namespace, name, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDebugTerminal", reflect.TypeOf((*MockClient)(nil).GetDebugTerminal), ctx, conn, namespace, name, options)
+}
+
 // GetLogs mocks base method.
 func (m *MockClient) GetLogs(ctx context.Context, namespace, name, container, regex string, since, tail int64, previous bool) (string, error) {
 	m.ctrl.T.Helper()
@@ -308,6 +353,20 @@ func (mr *MockClientMockRecorder) GetUsers(ctx, cluster, namespace interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockClient)(nil).GetUsers), ctx, cluster, namespace)
 }
 
+// ImpersonationEnabled mocks base method.
+func (m *MockClient) ImpersonationEnabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImpersonationEnabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ImpersonationEnabled indicates an expected call of ImpersonationEnabled.
+func (mr *MockClientMockRecorder) ImpersonationEnabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImpersonationEnabled", reflect.TypeOf((*MockClient)(nil).ImpersonationEnabled))
+}
+
 // PatchResource mocks base method.
 func (m *MockClient) PatchResource(ctx context.Context, namespace, name, path, resource, subResource string, body []byte) error {
 	m.ctrl.T.Helper()
@@ -322,6 +381,65 @@ func (mr *MockClientMockRecorder) PatchResource(ctx, namespace, name, path, reso
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchResource", reflect.TypeOf((*MockClient)(nil).PatchResource), ctx, namespace, name, path, resource, subResource, body)
 }
 
+// PortForward mocks base method.
+func (m *MockClient) PortForward(ctx context.Context, conn *websocket.Conn, namespace, name string, port int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PortForward", ctx, conn, namespace, name, port)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PortForward indicates an expected call of PortForward.
+func (mr *MockClientMockRecorder) PortForward(ctx, conn, namespace, name, port interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PortForward", reflect.TypeOf((*MockClient)(nil).PortForward), ctx, conn, namespace, name, port)
+}
+
+// SaveApplication mocks base method.
+func (m *MockClient) SaveApplication(ctx context.Context, application v1.ApplicationSpec) (*v1.ApplicationSpec, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveApplication", ctx, application)
+	ret0, _ := ret[0].(*v1.ApplicationSpec)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveApplication indicates an expected call of SaveApplication.
+func (mr *MockClientMockRecorder) SaveApplication(ctx, application interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveApplication", reflect.TypeOf((*MockClient)(nil).SaveApplication), ctx, application)
+}
+
+// SaveTeam mocks base method.
+func (m *MockClient) SaveTeam(ctx context.Context, team v11.TeamSpec) (*v11.TeamSpec, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTeam", ctx, team)
+	ret0, _ := ret[0].(*v11.TeamSpec)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveTeam indicates an expected call of SaveTeam.
+func (mr *MockClientMockRecorder) SaveTeam(ctx, team interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTeam", reflect.TypeOf((*MockClient)(nil).SaveTeam), ctx, team)
+}
+
+// SaveUser mocks base method.
+func (m *MockClient) SaveUser(ctx context.Context, user v12.UserSpec) (*v12.UserSpec, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveUser", ctx, user)
+	ret0, _ := ret[0].(*v12.UserSpec)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveUser indicates an expected call of SaveUser.
+func (mr *MockClientMockRecorder) SaveUser(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUser", reflect.TypeOf((*MockClient)(nil).SaveUser), ctx, user)
+}
+
 // StreamLogs mocks base method.
 func (m *MockClient) StreamLogs(ctx context.Context, conn *websocket.Conn, namespace, name, container string, since, tail int64, follow bool) error {
 	m.ctrl.T.Helper()
@@ -335,3 +453,31 @@ func (mr *MockClientMockRecorder) StreamLogs(ctx, conn, namespace, name, contain
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamLogs", reflect.TypeOf((*MockClient)(nil).StreamLogs), ctx, conn, namespace, name, container, since, tail, follow)
 }
+
+// StreamPodsLogs mocks base method.
+func (m *MockClient) StreamPodsLogs(ctx context.Context, conn *websocket.Conn, namespace string, options PodsLogsOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamPodsLogs", ctx, conn, namespace, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamPodsLogs indicates an expected call of StreamPodsLogs.
+func (mr *MockClientMockRecorder) StreamPodsLogs(ctx, conn, namespace, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamPodsLogs", reflect.TypeOf((*MockClient)(nil).StreamPodsLogs), ctx, conn, namespace, options)
+}
+
+// WatchEvents mocks base method.
+func (m *MockClient) WatchEvents(ctx context.Context, cluster string, events chan<- Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchEvents", ctx, cluster, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WatchEvents indicates an expected call of WatchEvents.
+func (mr *MockClientMockRecorder) WatchEvents(ctx, cluster, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchEvents", reflect.TypeOf((*MockClient)(nil).WatchEvents), ctx, cluster, events)
+}
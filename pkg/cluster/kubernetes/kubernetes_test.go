@@ -3,12 +3,14 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
 	dashboardv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/dashboard/v1"
 	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
 	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/copy"
 	applicationfakeclient "github.com/kobsio/kobs/pkg/cluster/kubernetes/clients/application/clientset/versioned/fake"
 	applicationfake "github.com/kobsio/kobs/pkg/cluster/kubernetes/clients/application/clientset/versioned/typed/application/v1/fake"
 	dashboardfakeclient "github.com/kobsio/kobs/pkg/cluster/kubernetes/clients/dashboard/clientset/versioned/fake"
@@ -21,8 +23,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/fake"
 	fakecorev1 "k8s.io/client-go/kubernetes/typed/core/v1/fake"
 	kubernetesTesting "k8s.io/client-go/testing"
@@ -366,3 +370,124 @@ func TestGetUser(t *testing.T) {
 		require.Equal(t, &userv1.UserSpec{Cluster: "cluster", Namespace: "default", Name: "user1"}, users)
 	})
 }
+
+func TestSaveApplication(t *testing.T) {
+	var getClient = func() client {
+		return client{
+			applicationClientset: applicationfakeclient.NewSimpleClientset(&applicationv1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "application1",
+					Namespace:       "default",
+					ResourceVersion: "1",
+					Labels:          map[string]string{"app": "application1"},
+				},
+			}),
+			tracer: otel.Tracer("cluster"),
+		}
+	}
+
+	t.Run("should create application", func(t *testing.T) {
+		client := getClient()
+		application, err := client.SaveApplication(context.Background(), applicationv1.ApplicationSpec{ID: "/cluster/cluster/namespace/default/name/application2", Cluster: "cluster", Namespace: "default", Name: "application2", Description: "Application 2"})
+		require.NoError(t, err)
+		require.Equal(t, &applicationv1.ApplicationSpec{ID: "/cluster/cluster/namespace/default/name/application2", Cluster: "cluster", Namespace: "default", Name: "application2", Description: "Application 2"}, application)
+
+		applicationItem, err := client.applicationClientset.KobsV1().Applications("default").Get(context.Background(), "application2", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, applicationv1.ApplicationSpec{Description: "Application 2"}, applicationItem.Spec)
+	})
+
+	t.Run("should return conflict when application already exists", func(t *testing.T) {
+		client := getClient()
+		_, err := client.SaveApplication(context.Background(), applicationv1.ApplicationSpec{Cluster: "cluster", Namespace: "default", Name: "application1"})
+		require.Error(t, err)
+		require.True(t, apierrors.IsConflict(err))
+	})
+
+	t.Run("should update application", func(t *testing.T) {
+		client := getClient()
+		application, err := client.SaveApplication(context.Background(), applicationv1.ApplicationSpec{Cluster: "cluster", Namespace: "default", Name: "application1", ResourceVersion: "1", Description: "Application 1"})
+		require.NoError(t, err)
+		require.Equal(t, "Application 1", application.Description)
+
+		applicationItem, err := client.applicationClientset.KobsV1().Applications("default").Get(context.Background(), "application1", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, applicationv1.ApplicationSpec{Description: "Application 1"}, applicationItem.Spec)
+		require.Equal(t, map[string]string{"app": "application1"}, applicationItem.Labels)
+	})
+
+	t.Run("should return conflict when application was deleted", func(t *testing.T) {
+		client := getClient()
+		_, err := client.SaveApplication(context.Background(), applicationv1.ApplicationSpec{Cluster: "cluster", Namespace: "default", Name: "application2", ResourceVersion: "1"})
+		require.Error(t, err)
+		require.True(t, apierrors.IsConflict(err))
+	})
+
+	t.Run("should return conflict when application was modified", func(t *testing.T) {
+		client := getClient()
+		client.applicationClientset.KobsV1().(*applicationfake.FakeKobsV1).PrependReactor("update", "applications", func(action kubernetesTesting.Action) (handled bool, ret runtime.Object, err error) {
+			return true, nil, apierrors.NewConflict(schema.GroupResource{Group: "kobs.io", Resource: "applications"}, "application1", fmt.Errorf("the object has been modified"))
+		})
+		_, err := client.SaveApplication(context.Background(), applicationv1.ApplicationSpec{Cluster: "cluster", Namespace: "default", Name: "application1", ResourceVersion: "1"})
+		require.Error(t, err)
+		require.True(t, apierrors.IsConflict(err))
+	})
+
+	t.Run("should return error", func(t *testing.T) {
+		client := getClient()
+		client.applicationClientset.KobsV1().(*applicationfake.FakeKobsV1).PrependReactor("get", "applications", func(action kubernetesTesting.Action) (handled bool, ret runtime.Object, err error) {
+			return true, nil, fmt.Errorf("error getting application")
+		})
+		_, err := client.SaveApplication(context.Background(), applicationv1.ApplicationSpec{Cluster: "cluster", Namespace: "default", Name: "application1", ResourceVersion: "1"})
+		require.Error(t, err)
+		require.False(t, apierrors.IsConflict(err))
+	})
+}
+
+func TestSaveTeam(t *testing.T) {
+	client := client{
+		teamClientset: teamfakeclient.NewSimpleClientset(&teamv1.Team{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "team1",
+				Namespace:       "default",
+				ResourceVersion: "1",
+			},
+		}),
+		tracer: otel.Tracer("cluster"),
+	}
+
+	team, err := client.SaveTeam(context.Background(), teamv1.TeamSpec{ID: "team1@kobs.io", Cluster: "cluster", Namespace: "default", Name: "team1", ResourceVersion: "1", Description: "Team 1"})
+	require.NoError(t, err)
+	require.Equal(t, &teamv1.TeamSpec{ID: "team1@kobs.io", Cluster: "cluster", Namespace: "default", Name: "team1", ResourceVersion: "1", Description: "Team 1"}, team)
+
+	_, err = client.SaveTeam(context.Background(), teamv1.TeamSpec{ID: "team1@kobs.io", Cluster: "cluster", Namespace: "default", Name: "team1"})
+	require.True(t, apierrors.IsConflict(err))
+}
+
+func TestSaveUser(t *testing.T) {
+	client := client{
+		userClientset: userfakeclient.NewSimpleClientset(&userv1.User{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "user1",
+				Namespace:       "default",
+				ResourceVersion: "1",
+			},
+		}),
+		tracer: otel.Tracer("cluster"),
+	}
+
+	user, err := client.SaveUser(context.Background(), userv1.UserSpec{ID: "user1@kobs.io", Cluster: "cluster", Namespace: "default", Name: "user1", ResourceVersion: "1", DisplayName: "User 1"})
+	require.NoError(t, err)
+	require.Equal(t, &userv1.UserSpec{ID: "user1@kobs.io", Cluster: "cluster", Namespace: "default", Name: "user1", ResourceVersion: "1", DisplayName: "User 1"}, user)
+
+	_, err = client.SaveUser(context.Background(), userv1.UserSpec{ID: "user1@kobs.io", Cluster: "cluster", Namespace: "default", Name: "user2", ResourceVersion: "1"})
+	require.True(t, apierrors.IsConflict(err))
+}
+
+func TestCopyArchiveToPod(t *testing.T) {
+	t.Run("should fail for destination path which is a tar option", func(t *testing.T) {
+		c := &client{tracer: otel.Tracer("cluster")}
+		_, err := c.CopyArchiveToPod(context.Background(), "default", "pod1", "app", strings.NewReader(""), "--to-command=sh")
+		require.ErrorIs(t, err, copy.ErrInvalidPath)
+	})
+}
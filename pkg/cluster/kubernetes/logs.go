@@ -0,0 +1,327 @@
+package kubernetes
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// maxPodsLogsStreams is the maximum number of containers for which the logs are streamed at the same time via one
+// WebSocket connection, so that a selector which matches a lot of Pods can not overload the Kubernetes API server.
+const maxPodsLogsStreams = 100
+
+// PodsLogsOptions are the options to stream the logs of multiple Pods. The Pods are selected via the `LabelSelector`
+// or via the `Owner` of the Pods in the format `<kind>/<name>` (e.g. `Deployment/my-app`). If both are set, a Pod must
+// match both. When the `Container` is empty, the logs of all containers are streamed. The `Regex` is applied to each
+// log line, before it is sent to the user. `Since` and `Tail` are only used for the Pods which are already running when
+// the stream is started, for Pods which are created during the stream, all logs are returned.
+type PodsLogsOptions struct {
+	LabelSelector string
+	Owner         string
+	Container     string
+	Regex         string
+	Since         int64
+	Tail          int64
+}
+
+// StreamPodsLogs streams the logs of all Pods and containers which are matching the provided options via the passed
+// in WebSocket connection. Pods which are created during the stream are also followed. Each line is prefixed with the
+// name of the Pod and container and the timestamp of the line. The function blocks until the provided context is
+// canceled or the WebSocket connection is closed by the user.
+func (c *client) StreamPodsLogs(ctx context.Context, conn *websocket.Conn, namespace string, options PodsLogsOptions) error {
+	ctx, span := c.tracer.Start(ctx, "cluster.StreamPodsLogs")
+	span.SetAttributes(attribute.Key("namespace").String(namespace))
+	span.SetAttributes(attribute.Key("labelSelector").String(options.LabelSelector))
+	span.SetAttributes(attribute.Key("owner").String(options.Owner))
+	span.SetAttributes(attribute.Key("container").String(options.Container))
+	span.SetAttributes(attribute.Key("regex").String(options.Regex))
+	span.SetAttributes(attribute.Key("since").Int64(options.Since))
+	span.SetAttributes(attribute.Key("tail").Int64(options.Tail))
+	defer span.End()
+
+	_, clientset, err := c.getImpersonatedClient(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// We have to read from the WebSocket connection, so that the pong messages are handled and so that we notice when
+	// the user closes the connection, because we would otherwise not stop the stream when no new logs are written.
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	err = streamPodsLogs(ctx, clientset, namespace, options, func(line string) error {
+		return conn.WriteMessage(websocket.TextMessage, []byte(line))
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// streamPodsLogs watches all Pods which are matching the provided options and starts a log stream for each running
+// container. All lines are passed to the provided `write` function, which is never called concurrently. If the
+// `write` function returns an error, all streams are stopped and the error is returned.
+func streamPodsLogs(ctx context.Context, clientset kubernetes.Interface, namespace string, options PodsLogsOptions, write func(line string) error) error {
+	selector, err := getPodsSelector(ctx, clientset, namespace, options.LabelSelector, options.Owner)
+	if err != nil {
+		return err
+	}
+
+	var reg *regexp.Regexp
+	if options.Regex != "" {
+		reg, err = regexp.Compile(options.Regex)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var writeErr error
+	streams := make(map[string]bool)
+	activeStreams := 0
+
+	send := func(line string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if writeErr != nil {
+			return
+		}
+
+		if err := write(line); err != nil {
+			writeErr = err
+			cancel()
+		}
+	}
+
+	// startStreams starts a log stream for each running container of the provided Pod, which isn't already streamed.
+	// The id of the container is part of the key, so that we start a new stream when a container is restarted.
+	startStreams := func(pod *corev1.Pod, isInInitialList bool) {
+		for _, status := range pod.Status.ContainerStatuses {
+			if options.Container != "" && status.Name != options.Container {
+				continue
+			}
+
+			if status.State.Running == nil || status.ContainerID == "" {
+				continue
+			}
+
+			key := fmt.Sprintf("%s/%s/%s", pod.Name, status.Name, status.ContainerID)
+
+			mu.Lock()
+			if ctx.Err() != nil || streams[key] {
+				mu.Unlock()
+				continue
+			}
+			if activeStreams >= maxPodsLogsStreams {
+				mu.Unlock()
+				send(fmt.Sprintf("[%s/%s] Logs are not streamed, because the maximum number of %d containers is reached", pod.Name, status.Name, maxPodsLogsStreams))
+				continue
+			}
+			streams[key] = true
+			activeStreams++
+			wg.Add(1)
+			mu.Unlock()
+
+			logOptions := &corev1.PodLogOptions{
+				Container:  status.Name,
+				Follow:     true,
+				Timestamps: true,
+			}
+
+			if isInInitialList {
+				if options.Since > 0 {
+					logOptions.SinceSeconds = &options.Since
+				}
+				if options.Tail > 0 {
+					logOptions.TailLines = &options.Tail
+				}
+			}
+
+			go func(name string) {
+				defer func() {
+					mu.Lock()
+					activeStreams--
+					mu.Unlock()
+					wg.Done()
+				}()
+
+				if err := streamContainerLogs(ctx, clientset, namespace, name, logOptions, reg, send); err != nil && ctx.Err() == nil {
+					send(fmt.Sprintf("[%s/%s] Failed to stream logs: %s", name, logOptions.Container, err.Error()))
+				}
+			}(pod.Name)
+		}
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0, informers.WithNamespace(namespace), informers.WithTweakListOptions(func(listOptions *metav1.ListOptions) {
+		listOptions.LabelSelector = selector
+	}))
+
+	_, err = factory.Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj any, isInInitialList bool) {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				startStreams(pod, isInInitialList)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj any) {
+			if pod, ok := newObj.(*corev1.Pod); ok {
+				startStreams(pod, false)
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	factory.Start(ctx.Done())
+	<-ctx.Done()
+	factory.Shutdown()
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	return writeErr
+}
+
+// streamContainerLogs streams the logs of a single container and passes each line, which is matching the provided
+// regular expression, with the name of the Pod and container as prefix to the `send` function.
+func streamContainerLogs(ctx context.Context, clientset kubernetes.Interface, namespace, name string, options *corev1.PodLogOptions, reg *regexp.Regexp, send func(line string)) error {
+	stream, err := clientset.CoreV1().Pods(namespace).GetLogs(name, options).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		timestamp, message := splitLogLine(scanner.Text())
+		if reg != nil && !reg.MatchString(message) {
+			continue
+		}
+
+		if timestamp == "" {
+			send(fmt.Sprintf("[%s/%s] %s", name, options.Container, message))
+		} else {
+			send(fmt.Sprintf("[%s/%s] %s %s", name, options.Container, timestamp, message))
+		}
+	}
+
+	return scanner.Err()
+}
+
+// splitLogLine splits a log line, which was returned with the `timestamps` option, into the timestamp and the message.
+// If the line doesn't start with a valid timestamp, the timestamp is empty and the message is the whole line.
+func splitLogLine(line string) (string, string) {
+	timestamp, message, ok := strings.Cut(line, " ")
+	if !ok {
+		return "", line
+	}
+
+	if _, err := time.Parse(time.RFC3339Nano, timestamp); err != nil {
+		return "", line
+	}
+
+	return timestamp, message
+}
+
+// getPodsSelector returns the label selector for the Pods, which should be streamed. When an owner is provided, the
+// selector of the owner is added to the provided label selector.
+func getPodsSelector(ctx context.Context, clientset kubernetes.Interface, namespace, labelSelector, owner string) (string, error) {
+	if labelSelector == "" && owner == "" {
+		return "", fmt.Errorf("label selector or owner is required")
+	}
+
+	if owner == "" {
+		return labelSelector, nil
+	}
+
+	kind, name, ok := strings.Cut(owner, "/")
+	if !ok || name == "" {
+		return "", fmt.Errorf("invalid owner %s, must be in the format <kind>/<name>", owner)
+	}
+
+	var ownerSelector *metav1.LabelSelector
+
+	switch strings.ToLower(kind) {
+	case "deployment":
+		deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		ownerSelector = deployment.Spec.Selector
+	case "statefulset":
+		statefulSet, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		ownerSelector = statefulSet.Spec.Selector
+	case "daemonset":
+		daemonSet, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		ownerSelector = daemonSet.Spec.Selector
+	case "replicaset":
+		replicaSet, err := clientset.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		ownerSelector = replicaSet.Spec.Selector
+	case "job":
+		job, err := clientset.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		ownerSelector = job.Spec.Selector
+	default:
+		return "", fmt.Errorf("invalid owner kind %s, must be Deployment, StatefulSet, DaemonSet, ReplicaSet or Job", kind)
+	}
+
+	if ownerSelector == nil || (len(ownerSelector.MatchLabels) == 0 && len(ownerSelector.MatchExpressions) == 0) {
+		return "", fmt.Errorf("owner %s doesn't have a selector", owner)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(ownerSelector)
+	if err != nil {
+		return "", err
+	}
+
+	if labelSelector == "" {
+		return selector.String(), nil
+	}
+
+	return labelSelector + "," + selector.String(), nil
+}
@@ -0,0 +1,125 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newRunningPod(name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "myapp"}},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", ContainerID: "containerd://" + name, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				{Name: "sidecar", ContainerID: "containerd://" + name + "-sidecar", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}},
+			},
+		},
+	}
+}
+
+func TestStreamPodsLogs(t *testing.T) {
+	t.Run("should stream logs of existing and new pods", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newRunningPod("pod1"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		lines := make(chan string, 10)
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- streamPodsLogs(ctx, clientset, "default", PodsLogsOptions{LabelSelector: "app=myapp"}, func(line string) error {
+				lines <- line
+				return nil
+			})
+		}()
+
+		require.Equal(t, "[pod1/app] fake logs", <-lines)
+
+		_, err := clientset.CoreV1().Pods("default").Create(ctx, newRunningPod("pod2"), metav1.CreateOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "[pod2/app] fake logs", <-lines)
+
+		cancel()
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("should filter lines by regex", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newRunningPod("pod1"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+
+		var lines []string
+		err := streamPodsLogs(ctx, clientset, "default", PodsLogsOptions{LabelSelector: "app=myapp", Regex: "error"}, func(line string) error {
+			lines = append(lines, line)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Empty(t, lines)
+	})
+
+	t.Run("should return error from write function", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newRunningPod("pod1"))
+
+		err := streamPodsLogs(context.Background(), clientset, "default", PodsLogsOptions{LabelSelector: "app=myapp"}, func(line string) error {
+			return fmt.Errorf("connection closed")
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("should return error for invalid regex", func(t *testing.T) {
+		err := streamPodsLogs(context.Background(), fake.NewSimpleClientset(), "default", PodsLogsOptions{LabelSelector: "app=myapp", Regex: "["}, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestGetPodsSelector(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "myapp"}}},
+	})
+
+	for _, tt := range []struct {
+		name             string
+		labelSelector    string
+		owner            string
+		expectedSelector string
+		expectedErr      bool
+	}{
+		{name: "should fail for missing label selector and owner", expectedErr: true},
+		{name: "should return label selector", labelSelector: "app=myapp", expectedSelector: "app=myapp"},
+		{name: "should return selector of owner", owner: "Deployment/myapp", expectedSelector: "app=myapp"},
+		{name: "should combine label selector and selector of owner", labelSelector: "version=v1", owner: "Deployment/myapp", expectedSelector: "version=v1,app=myapp"},
+		{name: "should fail for invalid owner", owner: "myapp", expectedErr: true},
+		{name: "should fail for unsupported owner kind", owner: "Service/myapp", expectedErr: true},
+		{name: "should fail for missing owner", owner: "StatefulSet/myapp", expectedErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := getPodsSelector(context.Background(), clientset, "default", tt.labelSelector, tt.owner)
+			if tt.expectedErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedSelector, selector)
+			}
+		})
+	}
+}
+
+func TestSplitLogLine(t *testing.T) {
+	timestamp, message := splitLogLine("2024-01-01T00:00:00.123456789Z hello world")
+	require.Equal(t, "2024-01-01T00:00:00.123456789Z", timestamp)
+	require.Equal(t, "hello world", message)
+
+	timestamp, message = splitLogLine("hello world")
+	require.Equal(t, "", timestamp)
+	require.Equal(t, "hello world", message)
+}
@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
+	dashboardv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/dashboard/v1"
+	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/validation"
+	"github.com/kobsio/kobs/pkg/instrument/log"
+	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
+
+	"github.com/go-chi/render"
+	"go.uber.org/zap"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// validateHandler returns the handler for the validating admission webhook. The handler decodes the AdmissionReview
+// sent by the Kubernetes API server, validates the object in the request and returns the AdmissionReview with the
+// result of the validation. Only create and update requests are validated, all other requests are allowed.
+func validateHandler(validator *validation.Validator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var review admissionv1.AdmissionReview
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil || review.Request == nil {
+			log.Warn(r.Context(), "Failed to decode admission review", zap.Error(err))
+			errresponse.Render(w, r, http.StatusBadRequest, "Failed to decode admission review")
+			return
+		}
+
+		review.Response = validate(r.Context(), validator, review.Request)
+		review.Response.UID = review.Request.UID
+		review.Request = nil
+
+		render.JSON(w, r, review)
+	}
+}
+
+// validate validates the object of the given admission request. If the object is invalid the request is denied and all
+// validation errors are returned in the message of the response, so that they are shown by kubectl.
+func validate(ctx context.Context, validator *validation.Validator, request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if request.Operation != admissionv1.Create && request.Operation != admissionv1.Update {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	errs, err := validateObject(ctx, validator, request.Kind.Kind, request.Namespace, request.Object.Raw)
+	if err != nil {
+		log.Warn(ctx, "Failed to validate object", zap.Error(err), zap.String("kind", request.Kind.Kind), zap.String("namespace", request.Namespace), zap.String("name", request.Name))
+		return &admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: err.Error(),
+				Reason:  metav1.StatusReasonBadRequest,
+				Code:    http.StatusBadRequest,
+			},
+		}
+	}
+
+	if len(errs) > 0 {
+		log.Debug(ctx, "Object is invalid", zap.String("kind", request.Kind.Kind), zap.String("namespace", request.Namespace), zap.String("name", request.Name), zap.Int("errors", len(errs)))
+		return &admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: fmt.Sprintf("%s is invalid: %s", request.Kind.Kind, errs.Error()),
+				Reason:  metav1.StatusReasonInvalid,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+// validateObject decodes the given raw object into the CR for the given kind and validates the spec of the CR. An error
+// is only returned when the object can not be decoded or the kind is not supported.
+func validateObject(ctx context.Context, validator *validation.Validator, kind, namespace string, raw []byte) (validation.Errors, error) {
+	switch kind {
+	case "Application":
+		var application applicationv1.Application
+		if err := json.Unmarshal(raw, &application); err != nil {
+			return nil, err
+		}
+		return validator.ValidateApplication(ctx, namespace, application.Name, application.Spec), nil
+	case "Dashboard":
+		var dashboard dashboardv1.Dashboard
+		if err := json.Unmarshal(raw, &dashboard); err != nil {
+			return nil, err
+		}
+		return validator.ValidateDashboard(ctx, dashboard.Spec), nil
+	case "Team":
+		var team teamv1.Team
+		if err := json.Unmarshal(raw, &team); err != nil {
+			return nil, err
+		}
+		return validator.ValidateTeam(ctx, namespace, team.Spec), nil
+	case "User":
+		var user userv1.User
+		if err := json.Unmarshal(raw, &user); err != nil {
+			return nil, err
+		}
+		return validator.ValidateUser(ctx, namespace, user.Spec), nil
+	default:
+		return nil, fmt.Errorf("unsupported kind %s", kind)
+	}
+}
@@ -0,0 +1,128 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/validation"
+
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestValidateHandler(t *testing.T) {
+	var newReview = func(operation admissionv1.Operation, kind, object string) string {
+		review := admissionv1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+			Request: &admissionv1.AdmissionRequest{
+				UID:       "uid1",
+				Kind:      metav1.GroupVersionKind{Group: "kobs.io", Version: "v1", Kind: kind},
+				Namespace: "default",
+				Name:      "name1",
+				Operation: operation,
+				Object:    runtime.RawExtension{Raw: []byte(object)},
+			},
+		}
+
+		data, _ := json.Marshal(review)
+		return string(data)
+	}
+
+	for _, tt := range []struct {
+		name               string
+		body               string
+		expectedStatusCode int
+		expectedAllowed    bool
+		expectedMessage    string
+	}{
+		{
+			name:               "should return error for invalid request body",
+			body:               "[]",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "should allow valid application",
+			body:               newReview(admissionv1.Create, "Application", `{"metadata": {"name": "name1"}, "spec": {"teams": ["team1"]}}`),
+			expectedStatusCode: http.StatusOK,
+			expectedAllowed:    true,
+		},
+		{
+			name:               "should deny invalid application",
+			body:               newReview(admissionv1.Create, "Application", `{"metadata": {"name": "name1"}, "spec": {"topology": {"dependencies": [{"name": "name1"}]}}}`),
+			expectedStatusCode: http.StatusOK,
+			expectedMessage:    "Application is invalid: spec.topology.dependencies[0]: application can not depend on itself",
+		},
+		{
+			name:               "should deny invalid dashboard",
+			body:               newReview(admissionv1.Update, "Dashboard", `{"spec": {"rows": [{"panels": [{"title": "Panel", "plugin": {"type": "foo", "name": "foo"}}]}]}}`),
+			expectedStatusCode: http.StatusOK,
+			expectedMessage:    "Dashboard is invalid: spec.rows[0].panels[0].plugin.type: unknown plugin type \"foo\"",
+		},
+		{
+			name:               "should deny invalid team",
+			body:               newReview(admissionv1.Create, "Team", `{"spec": {"id": "team1", "links": [{"link": "https://kobs.io"}]}}`),
+			expectedStatusCode: http.StatusOK,
+			expectedMessage:    "Team is invalid: spec.links[0].title: title is required",
+		},
+		{
+			name:               "should deny team without id",
+			body:               newReview(admissionv1.Create, "Team", `{"spec": {}}`),
+			expectedStatusCode: http.StatusOK,
+			expectedMessage:    "Team is invalid: spec.id: id is required",
+		},
+		{
+			name:               "should deny invalid user",
+			body:               newReview(admissionv1.Create, "User", `{"spec": {"id": "user1", "teams": [""]}}`),
+			expectedStatusCode: http.StatusOK,
+			expectedMessage:    "User is invalid: spec.teams[0]: name can not be empty",
+		},
+		{
+			name:               "should deny object which can not be decoded",
+			body:               newReview(admissionv1.Create, "User", `{"spec": {"teams": "team1"}}`),
+			expectedStatusCode: http.StatusOK,
+			expectedMessage:    "json: cannot unmarshal string into Go struct field User.spec.teams of type []string",
+		},
+		{
+			name:               "should deny unsupported kind",
+			body:               newReview(admissionv1.Create, "Pod", `{}`),
+			expectedStatusCode: http.StatusOK,
+			expectedMessage:    "unsupported kind Pod",
+		},
+		{
+			name:               "should allow delete",
+			body:               newReview(admissionv1.Delete, "User", `{}`),
+			expectedStatusCode: http.StatusOK,
+			expectedAllowed:    true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "/validate", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			validateHandler(validation.New([]string{"prometheus"}, nil)).ServeHTTP(w, req)
+			require.Equal(t, tt.expectedStatusCode, w.Code)
+
+			if tt.expectedStatusCode == http.StatusOK {
+				var review admissionv1.AdmissionReview
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&review))
+				require.Nil(t, review.Request)
+				require.Equal(t, "uid1", string(review.Response.UID))
+				require.Equal(t, tt.expectedAllowed, review.Response.Allowed)
+
+				if !tt.expectedAllowed {
+					require.Equal(t, tt.expectedMessage, review.Response.Result.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{}, nil, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
+	dashboardv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/dashboard/v1"
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/validation"
+	"github.com/kobsio/kobs/pkg/instrument/log"
+	"github.com/kobsio/kobs/pkg/utils/middleware/recoverer"
+	"github.com/kobsio/kobs/pkg/utils/reload"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+type Config struct {
+	Enabled  bool   `json:"enabled" env:"ENABLED" default:"false" help:"Serve a validating admission webhook for the Application, Dashboard, Team and User CRs."`
+	Address  string `json:"address" env:"ADDRESS" default:":15226" help:"The address where the validating admission webhook should listen on."`
+	CertFile string `json:"certFile" env:"CERT_FILE" default:"" help:"The path to the certificate file, which is used to serve the webhook via TLS. The file is reloaded when it is changed."`
+	KeyFile  string `json:"keyFile" env:"KEY_FILE" default:"" help:"The path to the key file, which is used to serve the webhook via TLS. The file is reloaded when it is changed."`
+}
+
+// Server is the interface of a webhook service, which provides the options to start and stop the underlying http
+// server.
+type Server interface {
+	Start()
+	Stop()
+}
+
+// server implements the Server interface.
+type server struct {
+	*http.Server
+}
+
+// Start starts serving the webhook server. The Kubernetes API server only calls webhooks via HTTPS, so that the server
+// is always served via TLS.
+func (s *server) Start() {
+	log.Info(context.Background(), "Webhook server started", zap.String("address", s.Addr))
+
+	if err := s.ListenAndServeTLS("", ""); err != nil {
+		if err != http.ErrServerClosed {
+			log.Error(context.Background(), "Webhook server died unexpected", zap.Error(err))
+		}
+	}
+}
+
+// Stop terminates the webhook server gracefully.
+func (s *server) Stop() {
+	log.Debug(context.Background(), "Start shutdown of the webhook server")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := s.Shutdown(ctx)
+	if err != nil {
+		log.Error(context.Background(), "Graceful shutdown of the webhook server failed", zap.Error(err))
+	}
+}
+
+// New returns a new webhook server. The server serves the validating admission webhook for the kobs CRs at
+// "/validate". The given plugin types are used to validate the plugins used in the CRs and the Kubernetes client is used
+// to check if referenced dashboards exist.
+func New(config Config, kubernetesClient kubernetes.Client, pluginTypes []string) (Server, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, fmt.Errorf("a certificate and key file are required for the webhook")
+	}
+
+	certificate, err := reload.NewCertificate(config.CertFile, config.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	validator := validation.New(pluginTypes, func(ctx context.Context, namespace, name string) (*dashboardv1.DashboardSpec, error) {
+		dashboard, err := kubernetesClient.GetDashboard(ctx, "", namespace, name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		return dashboard, nil
+	})
+
+	router := chi.NewRouter()
+	router.Use(recoverer.Handler)
+
+	router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, nil)
+	})
+	router.Post("/validate", validateHandler(validator))
+
+	return &server{
+		&http.Server{
+			Addr:    config.Address,
+			Handler: router,
+			TLSConfig: &tls.Config{
+				MinVersion:     tls.VersionTLS12,
+				GetCertificate: certificate.GetCertificate,
+			},
+			ReadHeaderTimeout: 3 * time.Second,
+		},
+	}, nil
+}
@@ -10,12 +10,15 @@ import (
 	dashboardsAPI "github.com/kobsio/kobs/pkg/hub/api/dashboards"
 	resourcesAPI "github.com/kobsio/kobs/pkg/hub/api/resources"
 	teamsAPI "github.com/kobsio/kobs/pkg/hub/api/teams"
+	tunnelAPI "github.com/kobsio/kobs/pkg/hub/api/tunnel"
 	usersAPI "github.com/kobsio/kobs/pkg/hub/api/users"
 	"github.com/kobsio/kobs/pkg/hub/app/settings"
+	"github.com/kobsio/kobs/pkg/hub/audit"
 	"github.com/kobsio/kobs/pkg/hub/auth"
 	"github.com/kobsio/kobs/pkg/hub/clusters"
 	"github.com/kobsio/kobs/pkg/hub/db"
 	"github.com/kobsio/kobs/pkg/hub/plugins"
+	"github.com/kobsio/kobs/pkg/hub/recordings"
 	"github.com/kobsio/kobs/pkg/instrument"
 	"github.com/kobsio/kobs/pkg/instrument/log"
 	"github.com/kobsio/kobs/pkg/utils/middleware/recoverer"
@@ -71,7 +74,7 @@ func (s *server) Stop() {
 // We exclude the health check from all middlewares, because the health check just returns 200. Therefore we do not need
 // our defined middlewares like request id, metrics, auth or loggin. This also makes it easier to analyze the logs in a
 // Kubernetes cluster where the health check is called every x seconds, because we generate less logs.
-func New(config Config, appSettings settings.Settings, authClient auth.Client, clustersClient clusters.Client, dbClient db.Client, pluginsClient plugins.Client) (Server, error) {
+func New(config Config, appSettings settings.Settings, authClient auth.Client, auditClient audit.Client, clustersClient clusters.Client, dbClient db.Client, pluginsClient plugins.Client, recordingsClient recordings.Client) (Server, error) {
 	router := chi.NewRouter()
 	router.Use(recoverer.Handler)
 	router.Use(middleware.Compress(5))
@@ -89,16 +92,20 @@ func New(config Config, appSettings settings.Settings, authClient auth.Client, c
 	router.Route("/api", func(r chi.Router) {
 		r.Use(instrument.Handler())
 		r.Mount("/auth", authClient.Mount())
+		r.Mount("/tunnel", tunnelAPI.Mount(clustersClient))
 
 		r.Group(func(r chi.Router) {
 			r.Use(authClient.MiddlewareHandler)
+			r.Use(auditClient.MiddlewareHandler)
 			r.Mount("/clusters", clustersAPI.Mount(dbClient, clustersClient))
-			r.Mount("/applications", applicationsAPI.Mount(appSettings, dbClient))
-			r.Mount("/teams", teamsAPI.Mount(appSettings, dbClient))
-			r.Mount("/users", usersAPI.Mount(appSettings, dbClient))
+			r.Mount("/applications", applicationsAPI.Mount(appSettings, clustersClient, dbClient))
+			r.Mount("/teams", teamsAPI.Mount(appSettings, clustersClient, dbClient))
+			r.Mount("/users", usersAPI.Mount(appSettings, clustersClient, dbClient))
 			r.Mount("/dashboards", dashboardsAPI.Mount(dbClient))
-			r.Mount("/resources", resourcesAPI.Mount(appSettings, clustersClient, dbClient))
+			r.Mount("/resources", resourcesAPI.Mount(appSettings, clustersClient, dbClient, recordingsClient))
 			r.Mount("/plugins", pluginsClient.Mount())
+			r.Mount("/audit", auditClient.Mount())
+			r.Mount("/recordings", recordingsClient.Mount())
 		})
 	})
 
@@ -2,6 +2,7 @@ package applications
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -9,8 +10,11 @@ import (
 	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
 	"github.com/kobsio/kobs/pkg/hub/app/settings"
 	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/clusters"
+	"github.com/kobsio/kobs/pkg/hub/clusters/cluster"
 	"github.com/kobsio/kobs/pkg/hub/db"
 	"github.com/kobsio/kobs/pkg/instrument/log"
+	"github.com/kobsio/kobs/pkg/utils"
 	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
 
 	"github.com/go-chi/chi/v5"
@@ -22,11 +26,16 @@ import (
 	"go.uber.org/zap"
 )
 
+// applicationResource is the name of the Application CR in the resource permissions of a user. Only users which are
+// allowed to edit the Application CR via the resources API can change the teams of an application.
+const applicationResource = "applications.kobs.io/v1"
+
 type Router struct {
 	*chi.Mux
-	appSettings settings.Settings
-	dbClient    db.Client
-	tracer      trace.Tracer
+	appSettings    settings.Settings
+	clustersClient clusters.Client
+	dbClient       db.Client
+	tracer         trace.Tracer
 }
 
 func (router *Router) getApplications(w http.ResponseWriter, r *http.Request) {
@@ -39,6 +48,7 @@ func (router *Router) getApplications(w http.ResponseWriter, r *http.Request) {
 	clusters := r.URL.Query()["cluster"]
 	namespaces := r.URL.Query()["namespace"]
 	tags := r.URL.Query()["tag"]
+	health := r.URL.Query()["health"]
 	searchTerm := r.URL.Query().Get("searchTerm")
 	limit := r.URL.Query().Get("limit")
 	offset := r.URL.Query().Get("offset")
@@ -48,6 +58,7 @@ func (router *Router) getApplications(w http.ResponseWriter, r *http.Request) {
 	span.SetAttributes(attribute.Key("clusters").StringSlice(clusters))
 	span.SetAttributes(attribute.Key("namespaces").StringSlice(namespaces))
 	span.SetAttributes(attribute.Key("tags").StringSlice(tags))
+	span.SetAttributes(attribute.Key("health").StringSlice(health))
 	span.SetAttributes(attribute.Key("searchTerm").String(searchTerm))
 	span.SetAttributes(attribute.Key("limit").String(limit))
 	span.SetAttributes(attribute.Key("offset").String(offset))
@@ -86,7 +97,7 @@ func (router *Router) getApplications(w http.ResponseWriter, r *http.Request) {
 		teams = nil
 	}
 
-	applications, err := router.dbClient.GetApplicationsByFilter(ctx, teams, clusters, namespaces, tags, searchTerm, parsedLimit, parsedOffset)
+	applications, err := router.dbClient.GetApplicationsByFilter(ctx, teams, clusters, namespaces, tags, health, searchTerm, parsedLimit, parsedOffset)
 	if err != nil {
 		log.Error(ctx, "Failed to get applications", zap.Error(err))
 		span.RecordError(err)
@@ -95,7 +106,7 @@ func (router *Router) getApplications(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	count, err := router.dbClient.GetApplicationsByFilterCount(ctx, teams, clusters, namespaces, tags, searchTerm)
+	count, err := router.dbClient.GetApplicationsByFilterCount(ctx, teams, clusters, namespaces, tags, health, searchTerm)
 	if err != nil {
 		log.Error(ctx, "Failed to get applications count", zap.Error(err))
 		span.RecordError(err)
@@ -217,7 +228,7 @@ func (router *Router) getApplicationsByTeam(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
-	applications, err := router.dbClient.GetApplicationsByFilter(ctx, teams, nil, nil, nil, "", parsedLimit, parsedOffset)
+	applications, err := router.dbClient.GetApplicationsByFilter(ctx, teams, nil, nil, nil, nil, "", parsedLimit, parsedOffset)
 	if err != nil {
 		log.Error(ctx, "Failed to get applications", zap.Error(err))
 		span.RecordError(err)
@@ -226,7 +237,7 @@ func (router *Router) getApplicationsByTeam(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	count, err := router.dbClient.GetApplicationsByFilterCount(ctx, teams, nil, nil, nil, "")
+	count, err := router.dbClient.GetApplicationsByFilterCount(ctx, teams, nil, nil, nil, nil, "")
 	if err != nil {
 		log.Error(ctx, "Failed to get applications count", zap.Error(err))
 		span.RecordError(err)
@@ -246,6 +257,11 @@ func (router *Router) getApplicationsByTeam(w http.ResponseWriter, r *http.Reque
 	render.JSON(w, r, data)
 }
 
+// saveApplication saves the application from the request body. When the application already exists (the
+// `resourceVersion` is set), the user must be allowed to view the stored application and the teams are always taken
+// from the stored application, when the user is not allowed to edit the Application CR via the resources API. When a
+// new application is created by such a user, only the teams of the user are kept. This is required, because the teams
+// of an application are used to decide who can edit the application and which plugins can be used by its insights.
 func (router *Router) saveApplication(w http.ResponseWriter, r *http.Request) {
 	ctx, span := router.tracer.Start(r.Context(), "saveApplication")
 	defer span.End()
@@ -276,7 +292,54 @@ func (router *Router) saveApplication(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !user.HasApplicationAccess(&application) {
+	// The user must be allowed to view the stored application, when an existing application is saved and the new
+	// application, when a new application is created.
+	accessApplication := &application
+
+	storedApplication, err := router.dbClient.GetApplicationByID(ctx, application.ID)
+	if application.ResourceVersion != "" {
+		if err != nil {
+			log.Error(ctx, "Failed to get application", zap.Error(err), zap.String("id", application.ID))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get application")
+			return
+		}
+
+		if storedApplication == nil {
+			log.Warn(ctx, "Application was not found", zap.String("id", application.ID))
+			span.RecordError(fmt.Errorf("application was not found"))
+			span.SetStatus(codes.Error, "application was not found")
+			errresponse.Render(w, r, http.StatusNotFound, "Application was not found")
+			return
+		}
+
+		if !user.HasResourceAccess(application.Cluster, application.Namespace, applicationResource, "patch") {
+			application.Teams = storedApplication.Teams
+		}
+
+		accessApplication = storedApplication
+	} else {
+		if err == nil && storedApplication != nil {
+			log.Warn(ctx, "Application already exists", zap.String("id", application.ID))
+			span.RecordError(fmt.Errorf("application already exists"))
+			span.SetStatus(codes.Error, "application already exists")
+			errresponse.Render(w, r, http.StatusConflict, "The application already exists, reload the application and try again")
+			return
+		}
+
+		if !user.HasResourceAccess(application.Cluster, application.Namespace, applicationResource, "post") {
+			var teams []string
+			for _, team := range application.Teams {
+				if utils.Contains(user.Teams, team) {
+					teams = append(teams, team)
+				}
+			}
+			application.Teams = teams
+		}
+	}
+
+	if !user.HasApplicationAccess(accessApplication) {
 		log.Warn(ctx, "The user is not authorized to edit the application")
 		span.RecordError(fmt.Errorf("user is not authorized to edit the application"))
 		span.SetStatus(codes.Error, "user is not authorized to edit the application")
@@ -284,6 +347,34 @@ func (router *Router) saveApplication(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if router.appSettings.Save.WriteBack {
+		clusterClient := router.clustersClient.GetCluster(application.Cluster)
+		if clusterClient == nil {
+			log.Error(ctx, "Invalid cluster name", zap.String("cluster", application.Cluster))
+			span.RecordError(fmt.Errorf("invalid cluster name"))
+			span.SetStatus(codes.Error, "invalid cluster name")
+			errresponse.Render(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid cluster name: %s", application.Cluster))
+			return
+		}
+
+		savedApplication, err := clusterClient.SaveApplication(ctx, application)
+		if err != nil {
+			log.Error(ctx, "Failed to write application back to cluster", zap.Error(err))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Failed to write application back to cluster")
+
+			if errors.Is(err, cluster.ErrConflict) {
+				errresponse.Render(w, r, http.StatusConflict, "The application was modified, reload the application and try again")
+				return
+			}
+
+			errresponse.Render(w, r, http.StatusInternalServerError, "Failed to write application back to cluster")
+			return
+		}
+
+		application = *savedApplication
+	}
+
 	err = router.dbClient.SaveApplication(ctx, &application)
 	if err != nil {
 		log.Error(ctx, "Failed to save application", zap.Error(err))
@@ -330,10 +421,11 @@ func (router *Router) getApplicationGroups(w http.ResponseWriter, r *http.Reques
 	render.JSON(w, r, applicationsGroups)
 }
 
-func Mount(appSettings settings.Settings, dbClient db.Client) chi.Router {
+func Mount(appSettings settings.Settings, clustersClient clusters.Client, dbClient db.Client) chi.Router {
 	router := Router{
 		chi.NewRouter(),
 		appSettings,
+		clustersClient,
 		dbClient,
 		otel.Tracer("applications"),
 	}
@@ -345,7 +437,11 @@ func Mount(appSettings settings.Settings, dbClient db.Client) chi.Router {
 	router.Get("/team", router.getApplicationsByTeam)
 	router.Get("/topology", router.getApplicationsTopology)
 	router.Get("/topology/application", router.getApplicationTopology)
+	router.Get("/topology/application/dependencies", router.getApplicationDependencies)
+	router.Get("/topology/application/impact", router.getApplicationImpact)
 	router.Get("/groups", router.getApplicationGroups)
+	router.Get("/health", router.getApplicationsHealth)
+	router.Get("/health/history", router.getApplicationHealthHistory)
 
 	return router
 }
@@ -12,6 +12,8 @@ import (
 	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
 	"github.com/kobsio/kobs/pkg/hub/app/settings"
 	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/clusters"
+	"github.com/kobsio/kobs/pkg/hub/clusters/cluster"
 	"github.com/kobsio/kobs/pkg/hub/db"
 	"github.com/kobsio/kobs/pkg/utils"
 
@@ -25,7 +27,7 @@ func TestGetApplications(t *testing.T) {
 	var newRouter = func(t *testing.T) (*db.MockClient, Router) {
 		ctrl := gomock.NewController(t)
 		dbClient := db.NewMockClient(ctrl)
-		router := Router{chi.NewRouter(), settings.Settings{}, dbClient, otel.Tracer("applications")}
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient, otel.Tracer("applications")}
 
 		return dbClient, router
 	}
@@ -77,7 +79,7 @@ func TestGetApplications(t *testing.T) {
 
 	t.Run("should handle error from db client for applications", func(t *testing.T) {
 		dbClient, router := newRouter(t)
-		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("could not get applications"))
+		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("could not get applications"))
 
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
@@ -93,13 +95,13 @@ func TestGetApplications(t *testing.T) {
 
 	t.Run("should handle error from db client for count", func(t *testing.T) {
 		dbClient, router := newRouter(t)
-		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]applicationv1.ApplicationSpec{
+		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]applicationv1.ApplicationSpec{
 			{
 				Name:      "foo",
 				Namespace: "bar",
 			},
 		}, nil)
-		dbClient.EXPECT().GetApplicationsByFilterCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(0, fmt.Errorf("could not get applications count"))
+		dbClient.EXPECT().GetApplicationsByFilterCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(0, fmt.Errorf("could not get applications count"))
 
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
@@ -115,13 +117,13 @@ func TestGetApplications(t *testing.T) {
 
 	t.Run("should return all applications", func(t *testing.T) {
 		dbClient, router := newRouter(t)
-		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]applicationv1.ApplicationSpec{
+		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]applicationv1.ApplicationSpec{
 			{
 				Name:      "foo",
 				Namespace: "bar",
 			},
 		}, nil)
-		dbClient.EXPECT().GetApplicationsByFilterCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(1, nil)
+		dbClient.EXPECT().GetApplicationsByFilterCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(1, nil)
 
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
@@ -140,7 +142,7 @@ func TestGetTags(t *testing.T) {
 	var newRouter = func(t *testing.T) (*db.MockClient, Router) {
 		ctrl := gomock.NewController(t)
 		dbClient := db.NewMockClient(ctrl)
-		router := Router{chi.NewRouter(), settings.Settings{}, dbClient, otel.Tracer("applications")}
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient, otel.Tracer("applications")}
 
 		return dbClient, router
 	}
@@ -184,7 +186,7 @@ func TestGetApplication(t *testing.T) {
 	var newRouter = func(t *testing.T) (*db.MockClient, Router) {
 		ctrl := gomock.NewController(t)
 		dbClient := db.NewMockClient(ctrl)
-		router := Router{chi.NewRouter(), settings.Settings{}, dbClient, otel.Tracer("applications")}
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient, otel.Tracer("applications")}
 
 		return dbClient, router
 	}
@@ -258,7 +260,7 @@ func TestGetApplicationsByTeam(t *testing.T) {
 	var newRouter = func(t *testing.T) (*db.MockClient, Router) {
 		ctrl := gomock.NewController(t)
 		dbClient := db.NewMockClient(ctrl)
-		router := Router{chi.NewRouter(), settings.Settings{}, dbClient, otel.Tracer("applications")}
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient, otel.Tracer("applications")}
 
 		return dbClient, router
 	}
@@ -301,7 +303,7 @@ func TestGetApplicationsByTeam(t *testing.T) {
 
 	t.Run("should handle error from db client", func(t *testing.T) {
 		dbClient, router := newRouter(t)
-		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("could not get applications"))
+		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("could not get applications"))
 
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}})
@@ -315,8 +317,8 @@ func TestGetApplicationsByTeam(t *testing.T) {
 
 	t.Run("should handle error from db client for applications count", func(t *testing.T) {
 		dbClient, router := newRouter(t)
-		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
-		dbClient.EXPECT().GetApplicationsByFilterCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(0, fmt.Errorf("could not get applications count"))
+		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+		dbClient.EXPECT().GetApplicationsByFilterCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(0, fmt.Errorf("could not get applications count"))
 
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}})
@@ -335,8 +337,8 @@ func TestGetApplicationsByTeam(t *testing.T) {
 			Namespace: "namespace1",
 			Teams:     []string{"team1"},
 		}
-		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]applicationv1.ApplicationSpec{application}, nil)
-		dbClient.EXPECT().GetApplicationsByFilterCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(20, nil)
+		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]applicationv1.ApplicationSpec{application}, nil)
+		dbClient.EXPECT().GetApplicationsByFilterCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(20, nil)
 
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}})
@@ -362,9 +364,9 @@ func TestSaveApplication(t *testing.T) {
 	var newRouter = func(t *testing.T, saveEnabled bool) (*db.MockClient, Router) {
 		ctrl := gomock.NewController(t)
 		dbClient := db.NewMockClient(ctrl)
-		router := Router{chi.NewRouter(), settings.Settings{Save: struct {
-			Enabled bool `json:"enabled"`
-		}{Enabled: saveEnabled}}, dbClient, otel.Tracer("applications")}
+		appSettings := settings.Settings{}
+		appSettings.Save.Enabled = saveEnabled
+		router := Router{chi.NewRouter(), appSettings, nil, dbClient, otel.Tracer("applications")}
 
 		return dbClient, router
 	}
@@ -409,7 +411,8 @@ func TestSaveApplication(t *testing.T) {
 	})
 
 	t.Run("should return error when user is not authorized to edit the application", func(t *testing.T) {
-		_, router := newRouter(t, true)
+		dbClient, router := newRouter(t, true)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "/cluster/test/namespace/test/name/test").Return(nil, nil)
 
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{})
@@ -423,6 +426,7 @@ func TestSaveApplication(t *testing.T) {
 
 	t.Run("should return error on db error", func(t *testing.T) {
 		dbClient, router := newRouter(t, true)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "/cluster/test/namespace/test/name/test").Return(nil, nil)
 		dbClient.EXPECT().SaveApplication(gomock.Any(), gomock.Any()).Return(fmt.Errorf("unexpected error"))
 
 		ctx := context.Background()
@@ -437,6 +441,7 @@ func TestSaveApplication(t *testing.T) {
 
 	t.Run("should save application", func(t *testing.T) {
 		dbClient, router := newRouter(t, true)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "/cluster/test/namespace/test/name/test").Return(nil, nil)
 		dbClient.EXPECT().SaveApplication(gomock.Any(), gomock.Any()).Return(nil)
 
 		ctx := context.Background()
@@ -450,11 +455,180 @@ func TestSaveApplication(t *testing.T) {
 	})
 }
 
+func TestSaveApplicationWriteBack(t *testing.T) {
+	var newRouter = func(t *testing.T) (*db.MockClient, *clusters.MockClient, *cluster.MockClient, Router) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		clustersClient := clusters.NewMockClient(ctrl)
+		clusterClient := cluster.NewMockClient(ctrl)
+
+		appSettings := settings.Settings{}
+		appSettings.Save.Enabled = true
+		appSettings.Save.WriteBack = true
+		router := Router{chi.NewRouter(), appSettings, clustersClient, dbClient, otel.Tracer("applications")}
+
+		return dbClient, clustersClient, clusterClient, router
+	}
+
+	t.Run("should return error for invalid cluster", func(t *testing.T) {
+		dbClient, clustersClient, _, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "/cluster/test/namespace/test/name/test").Return(&applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", Cluster: "test", Namespace: "test", Name: "test"}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/application", strings.NewReader(`{"id": "/cluster/test/namespace/test/name/test", "cluster": "test", "namespace": "test", "name": "test", "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveApplication(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		utils.AssertJSONEq(t, w, `{"errors": ["Invalid cluster name: test"]}`)
+	})
+
+	t.Run("should return error on conflict", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "/cluster/test/namespace/test/name/test").Return(&applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", Cluster: "test", Namespace: "test", Name: "test"}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveApplication(gomock.Any(), applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "1"}).Return(nil, fmt.Errorf("%w: [The application was modified]", cluster.ErrConflict))
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/application", strings.NewReader(`{"id": "/cluster/test/namespace/test/name/test", "cluster": "test", "namespace": "test", "name": "test", "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveApplication(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusConflict)
+		utils.AssertJSONEq(t, w, `{"errors": ["The application was modified, reload the application and try again"]}`)
+	})
+
+	t.Run("should return error when write back fails", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "/cluster/test/namespace/test/name/test").Return(&applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", Cluster: "test", Namespace: "test", Name: "test"}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveApplication(gomock.Any(), applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "1"}).Return(nil, fmt.Errorf("unexpected error"))
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/application", strings.NewReader(`{"id": "/cluster/test/namespace/test/name/test", "cluster": "test", "namespace": "test", "name": "test", "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveApplication(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusInternalServerError)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to write application back to cluster"]}`)
+	})
+
+	t.Run("should write back and save application", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "/cluster/test/namespace/test/name/test").Return(&applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", Cluster: "test", Namespace: "test", Name: "test"}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveApplication(gomock.Any(), applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "1"}).Return(&applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "2"}, nil)
+		dbClient.EXPECT().SaveApplication(gomock.Any(), &applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "2"}).Return(nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/application", strings.NewReader(`{"id": "/cluster/test/namespace/test/name/test", "cluster": "test", "namespace": "test", "name": "test", "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveApplication(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusNoContent)
+		utils.AssertJSONEq(t, w, `null`)
+	})
+
+	teamUser := authContext.User{Teams: []string{"team1"}, Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "own"}}}}
+
+	t.Run("should return error when application was not found", func(t *testing.T) {
+		dbClient, _, _, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "/cluster/test/namespace/test/name/test").Return(nil, nil)
+
+		ctx := context.WithValue(context.Background(), authContext.UserKey, teamUser)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/application", strings.NewReader(`{"id": "/cluster/test/namespace/test/name/test", "cluster": "test", "namespace": "test", "name": "test", "teams": ["team1"], "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveApplication(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusNotFound)
+		utils.AssertJSONEq(t, w, `{"errors": ["Application was not found"]}`)
+	})
+
+	t.Run("should return error when user is not allowed to edit the stored application", func(t *testing.T) {
+		dbClient, _, _, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "/cluster/test/namespace/test/name/test").Return(&applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", Cluster: "test", Namespace: "test", Name: "test", Teams: []string{"team2"}}, nil)
+
+		ctx := context.WithValue(context.Background(), authContext.UserKey, teamUser)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/application", strings.NewReader(`{"id": "/cluster/test/namespace/test/name/test", "cluster": "test", "namespace": "test", "name": "test", "teams": ["team1"], "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveApplication(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusForbidden)
+		utils.AssertJSONEq(t, w, `{"errors": ["You are not allowed to edit the application"]}`)
+	})
+
+	t.Run("should keep teams of the stored application", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "/cluster/test/namespace/test/name/test").Return(&applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", Cluster: "test", Namespace: "test", Name: "test", Teams: []string{"team1"}}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveApplication(gomock.Any(), applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", Cluster: "test", Namespace: "test", Name: "test", Teams: []string{"team1"}, Description: "test", ResourceVersion: "1"}).Return(&applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", ResourceVersion: "2"}, nil)
+		dbClient.EXPECT().SaveApplication(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx := context.WithValue(context.Background(), authContext.UserKey, teamUser)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/application", strings.NewReader(`{"id": "/cluster/test/namespace/test/name/test", "cluster": "test", "namespace": "test", "name": "test", "description": "test", "teams": ["team1", "team2"], "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveApplication(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusNoContent)
+	})
+
+	t.Run("should change teams when user is allowed to edit the application CR", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "/cluster/test/namespace/test/name/test").Return(&applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", Cluster: "test", Namespace: "test", Name: "test", Teams: []string{"team1"}}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveApplication(gomock.Any(), applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", Cluster: "test", Namespace: "test", Name: "test", Teams: []string{"team2"}, ResourceVersion: "1"}).Return(&applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", ResourceVersion: "2"}, nil)
+		dbClient.EXPECT().SaveApplication(gomock.Any(), gomock.Any()).Return(nil)
+
+		user := teamUser
+		user.Permissions.Resources = []userv1.Resources{{Clusters: []string{"test"}, Namespaces: []string{"test"}, Resources: []string{"applications.kobs.io/v1"}, Verbs: []string{"patch"}}}
+
+		ctx := context.WithValue(context.Background(), authContext.UserKey, user)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/application", strings.NewReader(`{"id": "/cluster/test/namespace/test/name/test", "cluster": "test", "namespace": "test", "name": "test", "teams": ["team2"], "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveApplication(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusNoContent)
+	})
+
+	t.Run("should return error when new application already exists", func(t *testing.T) {
+		dbClient, _, _, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "/cluster/test/namespace/test/name/test").Return(&applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", Cluster: "test", Namespace: "test", Name: "test", Teams: []string{"team2"}}, nil)
+
+		ctx := context.WithValue(context.Background(), authContext.UserKey, teamUser)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/application", strings.NewReader(`{"id": "/cluster/test/namespace/test/name/test", "cluster": "test", "namespace": "test", "name": "test", "teams": ["team1"]}`))
+		w := httptest.NewRecorder()
+		router.saveApplication(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusConflict)
+		utils.AssertJSONEq(t, w, `{"errors": ["The application already exists, reload the application and try again"]}`)
+	})
+
+	t.Run("should only keep teams of the user for new application", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "/cluster/test/namespace/test/name/test").Return(nil, fmt.Errorf("not found"))
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveApplication(gomock.Any(), applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", Cluster: "test", Namespace: "test", Name: "test", Teams: []string{"team1"}}).Return(&applicationv1.ApplicationSpec{ID: "/cluster/test/namespace/test/name/test", ResourceVersion: "1"}, nil)
+		dbClient.EXPECT().SaveApplication(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx := context.WithValue(context.Background(), authContext.UserKey, teamUser)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/application", strings.NewReader(`{"id": "/cluster/test/namespace/test/name/test", "cluster": "test", "namespace": "test", "name": "test", "teams": ["team1", "team2"]}`))
+		w := httptest.NewRecorder()
+		router.saveApplication(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusNoContent)
+	})
+}
+
 func TestGetApplicationGroups(t *testing.T) {
 	var newRouter = func(t *testing.T) (*db.MockClient, Router) {
 		ctrl := gomock.NewController(t)
 		dbClient := db.NewMockClient(ctrl)
-		router := Router{chi.NewRouter(), settings.Settings{}, dbClient, otel.Tracer("applications")}
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient, otel.Tracer("applications")}
 
 		return dbClient, router
 	}
@@ -517,6 +691,6 @@ func TestGetApplicationGroups(t *testing.T) {
 }
 
 func TestMount(t *testing.T) {
-	router := Mount(settings.Settings{}, nil)
+	router := Mount(settings.Settings{}, nil, nil)
 	require.NotNil(t, router)
 }
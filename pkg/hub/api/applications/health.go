@@ -0,0 +1,132 @@
+package applications
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/instrument/log"
+	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
+
+	"github.com/go-chi/render"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.uber.org/zap"
+)
+
+// maxHealthHistoryLimit is the maximum number of entries which can be returned by the `getApplicationHealthHistory`
+// handler.
+const maxHealthHistoryLimit = 1000
+
+// getApplicationsHealth returns the current health status of the applications with the provided ids. The user must be
+// allowed to view all of the applications.
+func (router *Router) getApplicationsHealth(w http.ResponseWriter, r *http.Request) {
+	ctx, span := router.tracer.Start(r.Context(), "getApplicationsHealth")
+	defer span.End()
+
+	user := authContext.MustGetUser(ctx)
+	ids := r.URL.Query()["id"]
+
+	span.SetAttributes(attribute.Key("ids").StringSlice(ids))
+
+	if len(ids) == 0 {
+		log.Warn(ctx, "The 'id' parameter is missing")
+		span.RecordError(fmt.Errorf("the 'id' parameter is missing"))
+		span.SetStatus(codes.Error, "the 'id' parameter is missing")
+		errresponse.Render(w, r, http.StatusBadRequest, "The 'id' parameter is missing")
+		return
+	}
+
+	for _, id := range ids {
+		if !router.hasApplicationAccess(w, r, user, id) {
+			return
+		}
+	}
+
+	health, err := router.dbClient.GetApplicationsHealth(ctx, ids)
+	if err != nil {
+		log.Error(ctx, "Failed to get health", zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get health")
+		return
+	}
+
+	render.JSON(w, r, health)
+}
+
+// getApplicationHealthHistory returns the health history of the application with the provided id. The history only
+// contains an entry for each change of the health status or the reasons of the application. The number of returned
+// entries is set via the `limit` parameter, which is capped at `maxHealthHistoryLimit`.
+func (router *Router) getApplicationHealthHistory(w http.ResponseWriter, r *http.Request) {
+	ctx, span := router.tracer.Start(r.Context(), "getApplicationHealthHistory")
+	defer span.End()
+
+	user := authContext.MustGetUser(ctx)
+	id := r.URL.Query().Get("id")
+	limit := r.URL.Query().Get("limit")
+
+	span.SetAttributes(attribute.Key("id").String(id))
+	span.SetAttributes(attribute.Key("limit").String(limit))
+
+	parsedLimit, err := strconv.Atoi(limit)
+	if err != nil {
+		log.Error(ctx, "Failed to parse 'limit' parameter", zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		errresponse.Render(w, r, http.StatusBadRequest, "Failed to parse 'limit' parameter")
+		return
+	}
+	if parsedLimit < 1 {
+		log.Error(ctx, "Invalid 'limit' parameter", zap.Int("limit", parsedLimit))
+		span.SetStatus(codes.Error, "invalid limit")
+		errresponse.Render(w, r, http.StatusBadRequest, "The 'limit' parameter must be greater than 0")
+		return
+	}
+	if parsedLimit > maxHealthHistoryLimit {
+		parsedLimit = maxHealthHistoryLimit
+	}
+
+	if !router.hasApplicationAccess(w, r, user, id) {
+		return
+	}
+
+	history, err := router.dbClient.GetApplicationHealthHistory(ctx, id, parsedLimit)
+	if err != nil {
+		log.Error(ctx, "Failed to get health history", zap.Error(err), zap.String("id", id))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get health history")
+		return
+	}
+
+	render.JSON(w, r, history)
+}
+
+// hasApplicationAccess checks if the user is allowed to view the application with the provided id. If the application
+// can not be found or the user is not allowed to view it, an error is rendered and false is returned.
+func (router *Router) hasApplicationAccess(w http.ResponseWriter, r *http.Request, user *authContext.User, id string) bool {
+	ctx := r.Context()
+
+	application, err := router.dbClient.GetApplicationByID(ctx, id)
+	if err != nil {
+		log.Error(ctx, "Failed to get application", zap.Error(err), zap.String("id", id))
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get application")
+		return false
+	}
+
+	if application == nil {
+		log.Error(ctx, "Application was not found", zap.String("id", id))
+		errresponse.Render(w, r, http.StatusNotFound, "Application was not found")
+		return false
+	}
+
+	if !user.HasApplicationAccess(application) {
+		log.Warn(ctx, "The user is not authorized to view the application", zap.String("id", id))
+		errresponse.Render(w, r, http.StatusForbidden, "You are not allowed to view the application")
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,214 @@
+package applications
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	"github.com/kobsio/kobs/pkg/hub/app/settings"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"go.opentelemetry.io/otel"
+)
+
+func TestGetApplicationsHealth(t *testing.T) {
+	var newRouter = func(t *testing.T) (*db.MockClient, Router) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient, otel.Tracer("applications")}
+
+		return dbClient, router
+	}
+
+	timestamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should fail for missing id", func(t *testing.T) {
+		_, router := newRouter(t)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/health", nil)
+		w := httptest.NewRecorder()
+		router.getApplicationsHealth(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		utils.AssertJSONEq(t, w, `{"errors": ["The 'id' parameter is missing"]}`)
+	})
+
+	t.Run("should return error when application was not found", func(t *testing.T) {
+		dbClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "id1").Return(nil, nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/health?id=id1", nil)
+		w := httptest.NewRecorder()
+		router.getApplicationsHealth(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusNotFound)
+		utils.AssertJSONEq(t, w, `{"errors": ["Application was not found"]}`)
+	})
+
+	t.Run("should return error when user does not have the permissions to view an application", func(t *testing.T) {
+		dbClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "id1").Return(&applicationv1.ApplicationSpec{Cluster: "cluster1", Namespace: "namespace1", Teams: []string{"myteam"}}, nil)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "id2").Return(&applicationv1.ApplicationSpec{Cluster: "cluster1", Namespace: "namespace1"}, nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Teams: []string{"myteam"}, Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "own"}}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/health?id=id1&id=id2", nil)
+		w := httptest.NewRecorder()
+		router.getApplicationsHealth(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusForbidden)
+		utils.AssertJSONEq(t, w, `{"errors": ["You are not allowed to view the application"]}`)
+	})
+
+	t.Run("should handle error from db client", func(t *testing.T) {
+		dbClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "id1").Return(&applicationv1.ApplicationSpec{ID: "id1"}, nil)
+		dbClient.EXPECT().GetApplicationsHealth(gomock.Any(), []string{"id1"}).Return(nil, fmt.Errorf("could not get health"))
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/health?id=id1", nil)
+		w := httptest.NewRecorder()
+		router.getApplicationsHealth(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusInternalServerError)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to get health"]}`)
+	})
+
+	t.Run("should return health", func(t *testing.T) {
+		dbClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "id1").Return(&applicationv1.ApplicationSpec{ID: "id1"}, nil)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "id2").Return(&applicationv1.ApplicationSpec{ID: "id2"}, nil)
+		dbClient.EXPECT().GetApplicationsHealth(gomock.Any(), []string{"id1", "id2"}).Return([]db.ApplicationHealth{
+			{ID: "id1", Application: "id1", Status: db.HealthStatusHealthy, Timestamp: timestamp},
+			{ID: "id2", Application: "id2", Status: db.HealthStatusDegraded, Reasons: []db.ApplicationHealthReason{{Type: "workload", Name: "Deployment/id2", Status: db.HealthStatusDegraded, Message: "1 of 2 replicas are ready"}}, Timestamp: timestamp},
+		}, nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/health?id=id1&id=id2", nil)
+		w := httptest.NewRecorder()
+		router.getApplicationsHealth(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+		utils.AssertJSONEq(t, w, `[
+			{"id": "id1", "application": "id1", "status": "healthy", "timestamp": "2024-01-01T00:00:00Z"},
+			{"id": "id2", "application": "id2", "status": "degraded", "reasons": [{"type": "workload", "name": "Deployment/id2", "status": "degraded", "message": "1 of 2 replicas are ready"}], "timestamp": "2024-01-01T00:00:00Z"}
+		]`)
+	})
+}
+
+func TestGetApplicationHealthHistory(t *testing.T) {
+	var newRouter = func(t *testing.T) (*db.MockClient, Router) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient, otel.Tracer("applications")}
+
+		return dbClient, router
+	}
+
+	timestamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should fail for invalid limit", func(t *testing.T) {
+		_, router := newRouter(t)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/health/history?id=id1&limit=abc", nil)
+		w := httptest.NewRecorder()
+		router.getApplicationHealthHistory(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to parse 'limit' parameter"]}`)
+	})
+
+	t.Run("should fail for limit lower than 1", func(t *testing.T) {
+		_, router := newRouter(t)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/health/history?id=id1&limit=-1", nil)
+		w := httptest.NewRecorder()
+		router.getApplicationHealthHistory(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		utils.AssertJSONEq(t, w, `{"errors": ["The 'limit' parameter must be greater than 0"]}`)
+	})
+
+	t.Run("should cap limit", func(t *testing.T) {
+		dbClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "id1").Return(&applicationv1.ApplicationSpec{ID: "id1"}, nil)
+		dbClient.EXPECT().GetApplicationHealthHistory(gomock.Any(), "id1", maxHealthHistoryLimit).Return(nil, nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/health/history?id=id1&limit=100000", nil)
+		w := httptest.NewRecorder()
+		router.getApplicationHealthHistory(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+	})
+
+	t.Run("should return error when application could not be returned", func(t *testing.T) {
+		dbClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "id1").Return(nil, fmt.Errorf("could not get application"))
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/health/history?id=id1&limit=10", nil)
+		w := httptest.NewRecorder()
+		router.getApplicationHealthHistory(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusInternalServerError)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to get application"]}`)
+	})
+
+	t.Run("should handle error from db client", func(t *testing.T) {
+		dbClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "id1").Return(&applicationv1.ApplicationSpec{ID: "id1"}, nil)
+		dbClient.EXPECT().GetApplicationHealthHistory(gomock.Any(), "id1", 10).Return(nil, fmt.Errorf("could not get health history"))
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/health/history?id=id1&limit=10", nil)
+		w := httptest.NewRecorder()
+		router.getApplicationHealthHistory(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusInternalServerError)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to get health history"]}`)
+	})
+
+	t.Run("should return health history", func(t *testing.T) {
+		dbClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "id1").Return(&applicationv1.ApplicationSpec{ID: "id1"}, nil)
+		dbClient.EXPECT().GetApplicationHealthHistory(gomock.Any(), "id1", 10).Return([]db.ApplicationHealth{
+			{ID: "id1/2", Application: "id1", Status: db.HealthStatusHealthy, Timestamp: timestamp.Add(time.Minute)},
+			{ID: "id1/1", Application: "id1", Status: db.HealthStatusUnhealthy, Timestamp: timestamp},
+		}, nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/health/history?id=id1&limit=10", nil)
+		w := httptest.NewRecorder()
+		router.getApplicationHealthHistory(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+		utils.AssertJSONEq(t, w, `[
+			{"id": "id1/2", "application": "id1", "status": "healthy", "timestamp": "2024-01-01T00:01:00Z"},
+			{"id": "id1/1", "application": "id1", "status": "unhealthy", "timestamp": "2024-01-01T00:00:00Z"}
+		]`)
+	})
+}
@@ -1,6 +1,7 @@
 package applications
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -30,12 +31,14 @@ type Node struct {
 
 // NodeData is the data for a node.
 type NodeData struct {
-	ID        string `json:"id"`
-	Label     string `json:"label"`
-	Cluster   string `json:"cluster"`
-	Namespace string `json:"namespace"`
-	Name      string `json:"name"`
-	External  string `json:"external"`
+	ID        string                `json:"id"`
+	Label     string                `json:"label"`
+	Cluster   string                `json:"cluster"`
+	Namespace string                `json:"namespace"`
+	Name      string                `json:"name"`
+	External  string                `json:"external"`
+	Level     int                   `json:"level,omitempty"`
+	Health    *db.ApplicationHealth `json:"health,omitempty"`
 }
 
 // Edge is the structure for a edge in the topology graph.
@@ -55,6 +58,7 @@ type EdgeData struct {
 	TargetNamespace string `json:"-"`
 	TargetName      string `json:"-"`
 	Description     string `json:"description"`
+	Cycle           bool   `json:"cycle,omitempty"`
 }
 
 func createTopologyGraph(topology []db.Topology) Topology {
@@ -145,6 +149,7 @@ func (router *Router) getApplicationsTopology(w http.ResponseWriter, r *http.Req
 	clusters := r.URL.Query()["cluster"]
 	namespaces := r.URL.Query()["namespace"]
 	tags := r.URL.Query()["tag"]
+	health := r.URL.Query()["health"]
 	searchTerm := r.URL.Query().Get("searchTerm")
 
 	span.SetAttributes(attribute.Key("teams").StringSlice(teams))
@@ -152,6 +157,7 @@ func (router *Router) getApplicationsTopology(w http.ResponseWriter, r *http.Req
 	span.SetAttributes(attribute.Key("clusters").StringSlice(clusters))
 	span.SetAttributes(attribute.Key("namespaces").StringSlice(namespaces))
 	span.SetAttributes(attribute.Key("tags").StringSlice(tags))
+	span.SetAttributes(attribute.Key("health").StringSlice(health))
 	span.SetAttributes(attribute.Key("searchTerm").String(searchTerm))
 
 	// Check if the user requested to see all applications, if this is the case we have to check if he is alowed to do
@@ -170,7 +176,7 @@ func (router *Router) getApplicationsTopology(w http.ResponseWriter, r *http.Req
 		teams = nil
 	}
 
-	applications, err := router.dbClient.GetApplicationsByFilter(ctx, teams, clusters, namespaces, tags, searchTerm, 0, 0)
+	applications, err := router.dbClient.GetApplicationsByFilter(ctx, teams, clusters, namespaces, tags, health, searchTerm, 0, 0)
 	if err != nil {
 		log.Error(ctx, "Failed to get applications", zap.Error(err))
 		span.RecordError(err)
@@ -277,3 +283,254 @@ func (router *Router) getApplicationTopology(w http.ResponseWriter, r *http.Requ
 	topologyGraph := createTopologyGraph(topology)
 	render.JSON(w, r, topologyGraph)
 }
+
+const (
+	// defaultTopologyDepth is the number of levels which are walked in the topology graph, when the user doesn't
+	// provide a depth.
+	defaultTopologyDepth = 5
+	// maxTopologyDepth is the maximum number of levels which can be walked in the topology graph.
+	maxTopologyDepth = 20
+)
+
+// TopologyAnalysis is the result of a transitive walk over the topology graph, starting at a single application. Next
+// to the graph it contains all cycles which were found during the walk and the `truncated` field, which is true when
+// the graph contains more applications than the ones which were reached with the requested depth.
+type TopologyAnalysis struct {
+	Topology
+	Depth     int        `json:"depth"`
+	Truncated bool       `json:"truncated"`
+	Cycles    [][]string `json:"cycles,omitempty"`
+}
+
+// topologyDirection defines how the topology graph is walked. The `field` is the field which is used to get the edges
+// for the current level and the `next` function returns the id of the application for the next level from an edge.
+type topologyDirection struct {
+	field string
+	next  func(t db.Topology) string
+}
+
+var (
+	// downstream walks the topology from an application to its dependencies, to answer the question "what does the
+	// application ultimately depend on".
+	downstream = topologyDirection{field: "sourceID", next: func(t db.Topology) string { return t.TargetID }}
+	// upstream walks the topology from an application to the applications which depend on it, to answer the question
+	// "what breaks if the application is down".
+	upstream = topologyDirection{field: "targetID", next: func(t db.Topology) string { return t.SourceID }}
+)
+
+// walkTopology walks the topology graph in the provided direction, starting at the application with the provided id,
+// until the provided depth is reached. It returns all edges which were found, the level at which each application was
+// reached first and if there are more applications behind the last level.
+func (router *Router) walkTopology(ctx context.Context, id string, direction topologyDirection, depth int) ([]db.Topology, map[string]int, bool, error) {
+	var topology []db.Topology
+	levels := map[string]int{id: 0}
+	frontier := []string{id}
+
+	for level := 1; level <= depth && len(frontier) > 0; level++ {
+		edges, err := router.dbClient.GetTopologyByIDs(ctx, direction.field, frontier)
+		if err != nil {
+			return nil, nil, false, err
+		}
+
+		frontier = nil
+		for _, edge := range edges {
+			topology = appendTopologyIfMissing(topology, edge)
+
+			next := direction.next(edge)
+			if _, ok := levels[next]; !ok {
+				levels[next] = level
+				frontier = append(frontier, next)
+			}
+		}
+	}
+
+	if len(frontier) == 0 {
+		return topology, levels, false, nil
+	}
+
+	edges, err := router.dbClient.GetTopologyByIDs(ctx, direction.field, frontier)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	for _, edge := range edges {
+		if _, ok := levels[direction.next(edge)]; !ok {
+			return topology, levels, true, nil
+		}
+	}
+
+	return topology, levels, false, nil
+}
+
+// findTopologyCycles returns all cycles in the provided edges, which can be reached from the application with the
+// provided id. A cycle is returned as list of application ids, where the first and the last id are the same. The
+// indexes of all edges which are closing a cycle are also returned, so that they can be marked in the graph.
+func findTopologyCycles(id string, topology []db.Topology, direction topologyDirection) ([][]string, map[int]bool) {
+	adjacency := make(map[string][]int)
+	for i, t := range topology {
+		current := t.SourceID
+		if direction.field == upstream.field {
+			current = t.TargetID
+		}
+		adjacency[current] = append(adjacency[current], i)
+	}
+
+	var cycles [][]string
+	cycleEdges := make(map[int]bool)
+	visiting := make(map[string]bool)
+	visited := make(map[string]bool)
+	var path []string
+
+	var visit func(node string)
+	visit = func(node string) {
+		visiting[node] = true
+		path = append(path, node)
+
+		for _, i := range adjacency[node] {
+			next := direction.next(topology[i])
+
+			if visiting[next] {
+				for j := range path {
+					if path[j] == next {
+						cycle := append(append([]string{}, path[j:]...), next)
+						cycles = append(cycles, cycle)
+						break
+					}
+				}
+				cycleEdges[i] = true
+				continue
+			}
+
+			if !visited[next] {
+				visit(next)
+			}
+		}
+
+		path = path[:len(path)-1]
+		visiting[node] = false
+		visited[node] = true
+	}
+
+	visit(id)
+
+	return cycles, cycleEdges
+}
+
+// analyzeApplicationTopology walks the topology graph of an application transitively in the provided direction. The
+// returned graph contains the level of each application, the edges which are closing a cycle and the current health
+// of each application, when the health of the applications is computed by the hub.
+func (router *Router) analyzeApplicationTopology(w http.ResponseWriter, r *http.Request, direction topologyDirection) {
+	ctx, span := router.tracer.Start(r.Context(), "analyzeApplicationTopology")
+	defer span.End()
+
+	user := authContext.MustGetUser(ctx)
+	id := r.URL.Query().Get("id")
+	depth := r.URL.Query().Get("depth")
+
+	span.SetAttributes(attribute.Key("id").String(id))
+	span.SetAttributes(attribute.Key("direction").String(direction.field))
+	span.SetAttributes(attribute.Key("depth").String(depth))
+
+	parsedDepth := defaultTopologyDepth
+	if depth != "" {
+		var err error
+		parsedDepth, err = strconv.Atoi(depth)
+		if err != nil || parsedDepth < 1 || parsedDepth > maxTopologyDepth {
+			log.Warn(ctx, "Invalid 'depth' parameter", zap.String("depth", depth))
+			span.RecordError(fmt.Errorf("invalid 'depth' parameter"))
+			span.SetStatus(codes.Error, "invalid 'depth' parameter")
+			errresponse.Render(w, r, http.StatusBadRequest, fmt.Sprintf("The 'depth' parameter must be a number between 1 and %d", maxTopologyDepth))
+			return
+		}
+	}
+
+	if !router.hasApplicationAccess(w, r, user, id) {
+		return
+	}
+
+	topology, levels, truncated, err := router.walkTopology(ctx, id, direction, parsedDepth)
+	if err != nil {
+		log.Error(ctx, "Failed to get topology", zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get topology")
+		return
+	}
+
+	cycles, cycleEdges := findTopologyCycles(id, topology, direction)
+
+	graph := createTopologyGraph(topology)
+	for i := range graph.Edges {
+		graph.Edges[i].Data.Cycle = cycleEdges[i]
+	}
+
+	var ids []string
+	for _, node := range graph.Nodes {
+		ids = append(ids, node.Data.ID)
+	}
+
+	// The health of the applications is optional, so that we only log an error when the health can not be returned,
+	// instead of failing the whole request. The health is only returned for the applications the user can view.
+	ids = router.filterApplicationIDs(ctx, user, ids)
+	health := make(map[string]db.ApplicationHealth)
+	if len(ids) > 0 {
+		applicationsHealth, err := router.dbClient.GetApplicationsHealth(ctx, ids)
+		if err != nil {
+			log.Warn(ctx, "Failed to get health", zap.Error(err))
+		}
+		for _, h := range applicationsHealth {
+			health[h.Application] = h
+		}
+	}
+
+	for i := range graph.Nodes {
+		graph.Nodes[i].Data.Level = levels[graph.Nodes[i].Data.ID]
+		if h, ok := health[graph.Nodes[i].Data.ID]; ok {
+			graph.Nodes[i].Data.Health = &h
+		}
+	}
+
+	render.JSON(w, r, TopologyAnalysis{
+		Topology:  graph,
+		Depth:     parsedDepth,
+		Truncated: truncated,
+		Cycles:    cycles,
+	})
+}
+
+// filterApplicationIDs returns the ids of all applications from the provided list, which can be viewed by the user. It
+// uses the same check as the getApplication handler. When the user can view all applications we can skip the check,
+// so that we do not have to get each application from the database. Applications which can not be returned are
+// removed from the list.
+func (router *Router) filterApplicationIDs(ctx context.Context, user *authContext.User, ids []string) []string {
+	if user.HasApplicationAccess(&applicationv1.ApplicationSpec{}) {
+		return ids
+	}
+
+	var filteredIDs []string
+	for _, id := range ids {
+		application, err := router.dbClient.GetApplicationByID(ctx, id)
+		if err != nil {
+			log.Warn(ctx, "Failed to get application", zap.Error(err), zap.String("id", id))
+			continue
+		}
+
+		if application != nil && user.HasApplicationAccess(application) {
+			filteredIDs = append(filteredIDs, id)
+		}
+	}
+
+	return filteredIDs
+}
+
+// getApplicationDependencies returns all applications the application with the provided id depends on, directly or
+// transitively via other applications.
+func (router *Router) getApplicationDependencies(w http.ResponseWriter, r *http.Request) {
+	router.analyzeApplicationTopology(w, r, downstream)
+}
+
+// getApplicationImpact returns all applications which depend on the application with the provided id, directly or
+// transitively via other applications, so that they might be impacted when the application is down.
+func (router *Router) getApplicationImpact(w http.ResponseWriter, r *http.Request) {
+	router.analyzeApplicationTopology(w, r, upstream)
+}
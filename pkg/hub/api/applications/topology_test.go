@@ -24,7 +24,7 @@ func TestGetApplicationsTopology(t *testing.T) {
 	var newRouter = func(t *testing.T) (*db.MockClient, Router) {
 		ctrl := gomock.NewController(t)
 		dbClient := db.NewMockClient(ctrl)
-		router := Router{chi.NewRouter(), settings.Settings{}, dbClient, otel.Tracer("applications")}
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient, otel.Tracer("applications")}
 
 		return dbClient, router
 	}
@@ -45,7 +45,7 @@ func TestGetApplicationsTopology(t *testing.T) {
 
 	t.Run("should handle error from db client", func(t *testing.T) {
 		dbClient, router := newRouter(t)
-		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("get applications by filter failed"))
+		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("get applications by filter failed"))
 
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Teams: []string{"team@test.test"}})
@@ -60,7 +60,7 @@ func TestGetApplicationsTopology(t *testing.T) {
 
 	t.Run("should handle error from db client for sourceID", func(t *testing.T) {
 		dbClient, router := newRouter(t)
-		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
 		dbClient.EXPECT().GetTopologyByIDs(gomock.Any(), "sourceID", gomock.Any()).Return(nil, fmt.Errorf("get topology by ids failed for sourceID"))
 
 		ctx := context.Background()
@@ -76,7 +76,7 @@ func TestGetApplicationsTopology(t *testing.T) {
 
 	t.Run("should handle error from db client for targetID", func(t *testing.T) {
 		dbClient, router := newRouter(t)
-		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
 		dbClient.EXPECT().GetTopologyByIDs(gomock.Any(), "sourceID", gomock.Any()).Return(nil, nil)
 		dbClient.EXPECT().GetTopologyByIDs(gomock.Any(), "targetID", gomock.Any()).Return(nil, fmt.Errorf("get topology by ids failed for targetID"))
 
@@ -96,12 +96,13 @@ func TestGetApplicationsTopology(t *testing.T) {
 		clusters := []string{"cluster1"}
 		namespaces := []string{"namespace1"}
 		tags := []string{"mytag"}
+		health := []string{"degraded"}
 		searchTerm := "searchterm"
 		teams := []string{"hello@test.test"}
 		application := applicationv1.ApplicationSpec{ID: "applicationID"}
 
 		dbClient, router := newRouter(t)
-		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), teams, clusters, namespaces, tags, searchTerm, 0, 0).Return([]applicationv1.ApplicationSpec{application}, nil)
+		dbClient.EXPECT().GetApplicationsByFilter(gomock.Any(), teams, clusters, namespaces, tags, health, searchTerm, 0, 0).Return([]applicationv1.ApplicationSpec{application}, nil)
 
 		sourceTopology := []db.Topology{
 			{
@@ -132,11 +133,12 @@ func TestGetApplicationsTopology(t *testing.T) {
 		ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
 		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Teams: teams})
 		path := fmt.Sprintf(
-			"/topology?all=%t&cluster=%s&namespace=%s&tag=%s&searchTerm=%s",
+			"/topology?all=%t&cluster=%s&namespace=%s&tag=%s&health=%s&searchTerm=%s",
 			all,
 			strings.Join(clusters, ","),
 			strings.Join(namespaces, ","),
 			strings.Join(tags, ","),
+			strings.Join(health, ","),
 			searchTerm,
 		)
 		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
@@ -186,7 +188,7 @@ func TestGetApplicationTopology(t *testing.T) {
 	var newRouter = func(t *testing.T) (*db.MockClient, Router) {
 		ctrl := gomock.NewController(t)
 		dbClient := db.NewMockClient(ctrl)
-		router := Router{chi.NewRouter(), settings.Settings{}, dbClient, otel.Tracer("applications")}
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient, otel.Tracer("applications")}
 
 		return dbClient, router
 	}
@@ -386,3 +388,165 @@ func TestGetApplicationTopology(t *testing.T) {
 		utils.AssertJSONEq(t, w, `{"errors": ["Failed to get target topology"]}`)
 	})
 }
+
+func TestGetApplicationDependencies(t *testing.T) {
+	var newRouter = func(t *testing.T) (*db.MockClient, Router) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient, otel.Tracer("applications")}
+
+		return dbClient, router
+	}
+
+	user := authContext.User{Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}}
+
+	t.Run("should fail for invalid depth", func(t *testing.T) {
+		_, router := newRouter(t)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, user)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/topology/application/dependencies?id=a&depth=21", nil)
+		w := httptest.NewRecorder()
+
+		router.getApplicationDependencies(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		utils.AssertJSONEq(t, w, `{"errors": ["The 'depth' parameter must be a number between 1 and 20"]}`)
+	})
+
+	t.Run("should return error when user is not authorized to view the application", func(t *testing.T) {
+		dbClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "a").Return(&applicationv1.ApplicationSpec{ID: "a", Cluster: "cluster1", Namespace: "namespace1"}, nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/topology/application/dependencies?id=a", nil)
+		w := httptest.NewRecorder()
+
+		router.getApplicationDependencies(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusForbidden)
+		utils.AssertJSONEq(t, w, `{"errors": ["You are not allowed to view the application"]}`)
+	})
+
+	t.Run("should handle error from db client", func(t *testing.T) {
+		dbClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "a").Return(&applicationv1.ApplicationSpec{ID: "a"}, nil)
+		dbClient.EXPECT().GetTopologyByIDs(gomock.Any(), "sourceID", []string{"a"}).Return(nil, fmt.Errorf("could not get topology"))
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, user)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/topology/application/dependencies?id=a", nil)
+		w := httptest.NewRecorder()
+
+		router.getApplicationDependencies(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusInternalServerError)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to get topology"]}`)
+	})
+
+	t.Run("should return transitive dependencies with cycles and health", func(t *testing.T) {
+		dbClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "a").Return(&applicationv1.ApplicationSpec{ID: "a"}, nil)
+		dbClient.EXPECT().GetTopologyByIDs(gomock.Any(), "sourceID", []string{"a"}).Return([]db.Topology{{ID: "a-b", SourceID: "a", SourceName: "a", TargetID: "b", TargetName: "b"}}, nil)
+		dbClient.EXPECT().GetTopologyByIDs(gomock.Any(), "sourceID", []string{"b"}).Return([]db.Topology{{ID: "b-c", SourceID: "b", SourceName: "b", TargetID: "c", TargetName: "c"}}, nil)
+		dbClient.EXPECT().GetTopologyByIDs(gomock.Any(), "sourceID", []string{"c"}).Return([]db.Topology{{ID: "c-a", SourceID: "c", SourceName: "c", TargetID: "a", TargetName: "a"}}, nil)
+		dbClient.EXPECT().GetApplicationsHealth(gomock.Any(), []string{"a", "b", "c"}).Return([]db.ApplicationHealth{{ID: "b", Application: "b", Status: db.HealthStatusUnhealthy}}, nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, user)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/topology/application/dependencies?id=a", nil)
+		w := httptest.NewRecorder()
+
+		router.getApplicationDependencies(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+		utils.AssertJSONEq(t, w, `{
+			"edges": [
+				{"data": {"id": "a-b", "source": "a", "target": "b", "description": ""}},
+				{"data": {"id": "b-c", "source": "b", "target": "c", "description": ""}},
+				{"data": {"id": "c-a", "source": "c", "target": "a", "description": "", "cycle": true}}
+			],
+			"nodes": [
+				{"data": {"id": "a", "label": "a (a / )", "cluster": "", "namespace": "", "name": "a", "external": ""}},
+				{"data": {"id": "b", "label": "b (b / )", "cluster": "", "namespace": "", "name": "b", "external": "", "level": 1, "health": {"id": "b", "application": "b", "status": "unhealthy", "timestamp": "0001-01-01T00:00:00Z"}}},
+				{"data": {"id": "c", "label": "c (c / )", "cluster": "", "namespace": "", "name": "c", "external": "", "level": 2}}
+			],
+			"depth": 5,
+			"truncated": false,
+			"cycles": [["a", "b", "c", "a"]]
+		}`)
+	})
+
+	t.Run("should return health only for applications the user can view", func(t *testing.T) {
+		dbClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "a").Return(&applicationv1.ApplicationSpec{ID: "a", Teams: []string{"team1"}}, nil).Times(2)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "b").Return(&applicationv1.ApplicationSpec{ID: "b", Teams: []string{"team2"}}, nil)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "c").Return(nil, fmt.Errorf("could not get application"))
+		dbClient.EXPECT().GetTopologyByIDs(gomock.Any(), "sourceID", []string{"a"}).Return([]db.Topology{{ID: "a-b", SourceID: "a", SourceName: "a", TargetID: "b", TargetName: "b"}, {ID: "a-c", SourceID: "a", SourceName: "a", TargetID: "c", TargetName: "c"}}, nil)
+		dbClient.EXPECT().GetTopologyByIDs(gomock.Any(), "sourceID", []string{"b", "c"}).Return(nil, nil)
+		dbClient.EXPECT().GetApplicationsHealth(gomock.Any(), []string{"a"}).Return([]db.ApplicationHealth{{ID: "a", Application: "a", Status: db.HealthStatusHealthy}}, nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Teams: []string{"team1"}, Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "own"}}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/topology/application/dependencies?id=a", nil)
+		w := httptest.NewRecorder()
+
+		router.getApplicationDependencies(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+		utils.AssertJSONEq(t, w, `{
+			"edges": [
+				{"data": {"id": "a-b", "source": "a", "target": "b", "description": ""}},
+				{"data": {"id": "a-c", "source": "a", "target": "c", "description": ""}}
+			],
+			"nodes": [
+				{"data": {"id": "a", "label": "a (a / )", "cluster": "", "namespace": "", "name": "a", "external": "", "health": {"id": "a", "application": "a", "status": "healthy", "timestamp": "0001-01-01T00:00:00Z"}}},
+				{"data": {"id": "b", "label": "b (b / )", "cluster": "", "namespace": "", "name": "b", "external": "", "level": 1}},
+				{"data": {"id": "c", "label": "c (c / )", "cluster": "", "namespace": "", "name": "c", "external": "", "level": 1}}
+			],
+			"depth": 5,
+			"truncated": false
+		}`)
+	})
+}
+
+func TestGetApplicationImpact(t *testing.T) {
+	var newRouter = func(t *testing.T) (*db.MockClient, Router) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient, otel.Tracer("applications")}
+
+		return dbClient, router
+	}
+
+	user := authContext.User{Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}}
+
+	t.Run("should return truncated impact without health", func(t *testing.T) {
+		dbClient, router := newRouter(t)
+		dbClient.EXPECT().GetApplicationByID(gomock.Any(), "c").Return(&applicationv1.ApplicationSpec{ID: "c"}, nil)
+		dbClient.EXPECT().GetTopologyByIDs(gomock.Any(), "targetID", []string{"c"}).Return([]db.Topology{{ID: "b-c", SourceID: "b", SourceName: "b", TargetID: "c", TargetName: "c"}}, nil)
+		dbClient.EXPECT().GetTopologyByIDs(gomock.Any(), "targetID", []string{"b"}).Return([]db.Topology{{ID: "a-b", SourceID: "a", SourceName: "a", TargetID: "b", TargetName: "b"}}, nil)
+		dbClient.EXPECT().GetApplicationsHealth(gomock.Any(), []string{"b", "c"}).Return(nil, fmt.Errorf("could not get health"))
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, user)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/topology/application/impact?id=c&depth=1", nil)
+		w := httptest.NewRecorder()
+
+		router.getApplicationImpact(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+		utils.AssertJSONEq(t, w, `{
+			"edges": [
+				{"data": {"id": "b-c", "source": "b", "target": "c", "description": ""}}
+			],
+			"nodes": [
+				{"data": {"id": "b", "label": "b (b / )", "cluster": "", "namespace": "", "name": "b", "external": "", "level": 1}},
+				{"data": {"id": "c", "label": "c (c / )", "cluster": "", "namespace": "", "name": "c", "external": ""}}
+			],
+			"depth": 1,
+			"truncated": true
+		}`)
+	})
+}
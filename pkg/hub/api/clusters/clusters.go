@@ -1,12 +1,19 @@
 package clusters
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"sort"
+	"strconv"
+	"time"
 
 	"github.com/kobsio/kobs/pkg/hub/api/resources"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
 	"github.com/kobsio/kobs/pkg/hub/clusters"
+	"github.com/kobsio/kobs/pkg/hub/clusters/cluster"
 	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/hub/watcher"
 	"github.com/kobsio/kobs/pkg/instrument/log"
 	"github.com/kobsio/kobs/pkg/utils"
 	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
@@ -16,6 +23,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// maxStatusHistoryLimit is the maximum number of sync runs which can be returned by the `getStatusHistory` handler.
+const maxStatusHistoryLimit = 1000
+
 type Router struct {
 	*chi.Mux
 	dbClient      db.Client
@@ -68,6 +78,203 @@ func (router *Router) getResources(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, resources.GetResources(crds))
 }
 
+// clusterStatus is the status of a single cluster. A cluster is healthy when the last sync runs for all resources were
+// healthy. The `resources` field contains the last sync run for each resource of the cluster.
+type clusterStatus struct {
+	Cluster   string       `json:"cluster"`
+	Healthy   bool         `json:"healthy"`
+	Resources []db.SyncRun `json:"resources"`
+}
+
+// getStatus returns the status of all clusters or of the clusters provided via the `cluster` parameter. The status is
+// generated from the last sync run of the watcher for each resource, so that we can see when a cluster stopped
+// delivering resources and flag the data for this cluster as stale.
+func (router *Router) getStatus(w http.ResponseWriter, r *http.Request) {
+	clusters := r.URL.Query()["cluster"]
+
+	runs, err := router.dbClient.GetLastSyncRuns(r.Context())
+	if err != nil {
+		log.Error(r.Context(), "Failed to get sync runs", zap.Error(err))
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get sync runs")
+		return
+	}
+
+	statuses := []clusterStatus{}
+
+	for _, client := range router.clusterClient.GetClusters() {
+		if len(clusters) > 0 && !utils.Contains(clusters, client.GetName()) {
+			continue
+		}
+
+		status := clusterStatus{Cluster: client.GetName(), Healthy: true, Resources: []db.SyncRun{}}
+		for _, run := range runs {
+			if run.Cluster == client.GetName() {
+				status.Resources = append(status.Resources, run)
+				status.Healthy = status.Healthy && run.Healthy
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	render.JSON(w, r, statuses)
+}
+
+// getStatusHistory returns the sync runs for the provided `cluster` and `resource`. The number of returned sync runs
+// can be set via the `limit` parameter, which defaults to 100 and is capped at `maxStatusHistoryLimit`.
+func (router *Router) getStatusHistory(w http.ResponseWriter, r *http.Request) {
+	cluster := r.URL.Query().Get("cluster")
+	resource := r.URL.Query().Get("resource")
+	limit := r.URL.Query().Get("limit")
+
+	parsedLimit := 100
+	if limit != "" {
+		var err error
+		parsedLimit, err = strconv.Atoi(limit)
+		if err != nil {
+			log.Error(r.Context(), "Failed to parse 'limit' parameter", zap.Error(err))
+			errresponse.Render(w, r, http.StatusBadRequest, "Failed to parse 'limit' parameter")
+			return
+		}
+	}
+	if parsedLimit < 1 {
+		log.Error(r.Context(), "Invalid 'limit' parameter", zap.Int("limit", parsedLimit))
+		errresponse.Render(w, r, http.StatusBadRequest, "The 'limit' parameter must be greater than 0")
+		return
+	}
+	if parsedLimit > maxStatusHistoryLimit {
+		parsedLimit = maxStatusHistoryLimit
+	}
+
+	runs, err := router.dbClient.GetSyncRuns(r.Context(), cluster, resource, parsedLimit)
+	if err != nil {
+		log.Error(r.Context(), "Failed to get sync runs", zap.Error(err))
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get sync runs")
+		return
+	}
+
+	render.JSON(w, r, runs)
+}
+
+// getLeader returns the lease of the watcher, which can be used to check which replica of the watcher is the current
+// leader. If the lease is expired, no replica is the leader and the `active` field is false.
+func (router *Router) getLeader(w http.ResponseWriter, r *http.Request) {
+	lease, err := router.dbClient.GetLease(r.Context(), watcher.LeaseName)
+	if err != nil {
+		if errors.Is(err, db.ErrLeaseNotFound) {
+			log.Warn(r.Context(), "Lease not found", zap.Error(err))
+			errresponse.Render(w, r, http.StatusNotFound, "Leader election is not enabled")
+			return
+		}
+
+		log.Error(r.Context(), "Failed to get lease", zap.Error(err))
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get lease")
+		return
+	}
+
+	render.JSON(w, r, struct {
+		db.Lease
+		Active bool `json:"active"`
+	}{*lease, lease.ExpiresAt.After(time.Now())})
+}
+
+// registerCluster registers a new cluster or updates an existing registered cluster. The cluster is saved in the
+// database, so that it is picked up by all hub and watcher instances without a restart. Clusters from the
+// configuration file can not be changed.
+//
+// To register a cluster the user must have access to the `clusters` resource with the `post` verb in all clusters and
+// namespaces.
+func (router *Router) registerCluster(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := authContext.MustGetUser(ctx)
+
+	if !user.HasResourceAccess("*", "*", "clusters", "post") {
+		log.Warn(ctx, "The user is not authorized to register clusters")
+		errresponse.Render(w, r, http.StatusForbidden, "You are not allowed to register clusters")
+		return
+	}
+
+	var data db.Cluster
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		log.Warn(ctx, "Failed to decode request body", zap.Error(err))
+		errresponse.Render(w, r, http.StatusBadRequest, "Failed to decode request body")
+		return
+	}
+
+	if data.Name == "" || data.Address == "" {
+		log.Warn(ctx, "Name and address are required")
+		errresponse.Render(w, r, http.StatusBadRequest, "Name and address are required")
+		return
+	}
+
+	if router.clusterClient.IsStatic(data.Name) {
+		log.Warn(ctx, "Cluster is defined in the configuration", zap.String("cluster", data.Name))
+		errresponse.Render(w, r, http.StatusBadRequest, "Cluster is defined in the configuration and can not be changed")
+		return
+	}
+
+	if _, err := cluster.NewClient(clusters.NewClusterConfig(data)); err != nil {
+		log.Warn(ctx, "Invalid cluster configuration", zap.Error(err), zap.String("cluster", data.Name))
+		errresponse.Render(w, r, http.StatusBadRequest, "Invalid cluster configuration")
+		return
+	}
+
+	if err := router.dbClient.SaveCluster(ctx, &data); err != nil {
+		log.Error(ctx, "Failed to register cluster", zap.Error(err), zap.String("cluster", data.Name))
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to register cluster")
+		return
+	}
+
+	if err := router.clusterClient.Sync(ctx); err != nil {
+		log.Warn(ctx, "Failed to sync clusters", zap.Error(err))
+	}
+
+	render.Status(r, http.StatusNoContent)
+	render.JSON(w, r, nil)
+}
+
+// unregisterCluster removes the registered cluster with the provided `name` and all data of the cluster from the
+// database. Clusters from the configuration file can not be removed.
+//
+// To remove a cluster the user must have access to the `clusters` resource with the `delete` verb in all clusters and
+// namespaces.
+func (router *Router) unregisterCluster(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := authContext.MustGetUser(ctx)
+	name := r.URL.Query().Get("name")
+
+	if !user.HasResourceAccess("*", "*", "clusters", "delete") {
+		log.Warn(ctx, "The user is not authorized to remove clusters")
+		errresponse.Render(w, r, http.StatusForbidden, "You are not allowed to remove clusters")
+		return
+	}
+
+	if name == "" {
+		log.Warn(ctx, "Name is required")
+		errresponse.Render(w, r, http.StatusBadRequest, "Name is required")
+		return
+	}
+
+	if router.clusterClient.IsStatic(name) {
+		log.Warn(ctx, "Cluster is defined in the configuration", zap.String("cluster", name))
+		errresponse.Render(w, r, http.StatusBadRequest, "Cluster is defined in the configuration and can not be removed")
+		return
+	}
+
+	if err := router.dbClient.DeleteCluster(ctx, name); err != nil {
+		log.Error(ctx, "Failed to remove cluster", zap.Error(err), zap.String("cluster", name))
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to remove cluster")
+		return
+	}
+
+	if err := router.clusterClient.Sync(ctx); err != nil {
+		log.Warn(ctx, "Failed to sync clusters", zap.Error(err))
+	}
+
+	render.Status(r, http.StatusNoContent)
+	render.JSON(w, r, nil)
+}
+
 func Mount(dbClient db.Client, clusterClient clusters.Client) chi.Router {
 	router := Router{
 		chi.NewRouter(),
@@ -76,8 +283,13 @@ func Mount(dbClient db.Client, clusterClient clusters.Client) chi.Router {
 	}
 
 	router.Get("/", router.getClusters)
+	router.Post("/", router.registerCluster)
+	router.Delete("/", router.unregisterCluster)
 	router.Get("/namespaces", router.getNamespaces)
 	router.Get("/resources", router.getResources)
+	router.Get("/status", router.getStatus)
+	router.Get("/status/history", router.getStatusHistory)
+	router.Get("/status/leader", router.getLeader)
 
 	return router
 }
@@ -5,8 +5,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
 	"github.com/kobsio/kobs/pkg/hub/clusters"
 	"github.com/kobsio/kobs/pkg/hub/clusters/cluster"
 	"github.com/kobsio/kobs/pkg/hub/db"
@@ -122,6 +126,373 @@ func TestGetResources(t *testing.T) {
 	})
 }
 
+func TestGetStatus(t *testing.T) {
+	timestamp := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var newRouter = func(t *testing.T) (Router, *db.MockClient, *clusters.MockClient) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		clusterClient := clusters.NewMockClient(ctrl)
+		cluster1 := cluster.NewMockClient(ctrl)
+		cluster1.EXPECT().GetName().Return("cluster-1").AnyTimes()
+		cluster2 := cluster.NewMockClient(ctrl)
+		cluster2.EXPECT().GetName().Return("cluster-2").AnyTimes()
+		clusterClient.EXPECT().GetClusters().Return([]cluster.Client{cluster1, cluster2}).AnyTimes()
+		return Router{chi.NewRouter(), dbClient, clusterClient}, dbClient, clusterClient
+	}
+
+	t.Run("should handle error from db client", func(t *testing.T) {
+		router, dbClient, _ := newRouter(t)
+		dbClient.EXPECT().GetLastSyncRuns(gomock.Any()).Return(nil, fmt.Errorf("could not get sync runs"))
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/status", nil)
+		w := httptest.NewRecorder()
+		router.getStatus(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusInternalServerError)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to get sync runs"]}`)
+	})
+
+	t.Run("should return status", func(t *testing.T) {
+		router, dbClient, _ := newRouter(t)
+		dbClient.EXPECT().GetLastSyncRuns(gomock.Any()).Return([]db.SyncRun{
+			{ID: "run1", Cluster: "cluster-1", Resource: "applications", Timestamp: timestamp, Duration: 100, Count: 10, Healthy: true},
+			{ID: "run2", Cluster: "cluster-2", Resource: "applications", Timestamp: timestamp, Duration: 200, Error: "unexpected error", Failures: 3, Healthy: false},
+		}, nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/status", nil)
+		w := httptest.NewRecorder()
+		router.getStatus(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+		utils.AssertJSONEq(t, w, `[
+			{"cluster": "cluster-1", "healthy": true, "resources": [{"id": "run1", "cluster": "cluster-1", "resource": "applications", "timestamp": "2023-01-01T00:00:00Z", "duration": 100, "count": 10, "failures": 0, "healthy": true}]},
+			{"cluster": "cluster-2", "healthy": false, "resources": [{"id": "run2", "cluster": "cluster-2", "resource": "applications", "timestamp": "2023-01-01T00:00:00Z", "duration": 200, "count": 0, "error": "unexpected error", "failures": 3, "healthy": false}]}
+		]`)
+	})
+
+	t.Run("should return status for cluster", func(t *testing.T) {
+		router, dbClient, _ := newRouter(t)
+		dbClient.EXPECT().GetLastSyncRuns(gomock.Any()).Return(nil, nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/status?cluster=cluster-2", nil)
+		w := httptest.NewRecorder()
+		router.getStatus(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+		utils.AssertJSONEq(t, w, `[{"cluster": "cluster-2", "healthy": true, "resources": []}]`)
+	})
+}
+
+func TestGetStatusHistory(t *testing.T) {
+	for _, tt := range []struct {
+		name               string
+		url                string
+		prepare            func(dbClient *db.MockClient)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "should fail for invalid limit",
+			url:                "/status/history?limit=abc",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors": ["Failed to parse 'limit' parameter"]}`,
+		},
+		{
+			name:               "should fail for limit lower than 1",
+			url:                "/status/history?limit=0",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors": ["The 'limit' parameter must be greater than 0"]}`,
+		},
+		{
+			name: "should cap limit",
+			url:  "/status/history?cluster=cluster-1&limit=100000",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetSyncRuns(gomock.Any(), "cluster-1", "", maxStatusHistoryLimit).Return(nil, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `null`,
+		},
+		{
+			name: "should handle error from db client",
+			url:  "/status/history?cluster=cluster-1",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetSyncRuns(gomock.Any(), "cluster-1", "", 100).Return(nil, fmt.Errorf("could not get sync runs"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `{"errors": ["Failed to get sync runs"]}`,
+		},
+		{
+			name: "should return sync runs",
+			url:  "/status/history?cluster=cluster-1&resource=teams&limit=1",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetSyncRuns(gomock.Any(), "cluster-1", "teams", 1).Return([]db.SyncRun{{ID: "run1", Cluster: "cluster-1", Resource: "teams", Timestamp: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Healthy: true}}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `[{"id": "run1", "cluster": "cluster-1", "resource": "teams", "timestamp": "2023-01-01T00:00:00Z", "duration": 0, "count": 0, "failures": 0, "healthy": true}]`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dbClient := db.NewMockClient(ctrl)
+			if tt.prepare != nil {
+				tt.prepare(dbClient)
+			}
+			router := Router{chi.NewRouter(), dbClient, nil}
+
+			ctx := context.Background()
+			ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, tt.url, nil)
+			w := httptest.NewRecorder()
+			router.getStatusHistory(w, req)
+
+			utils.AssertStatusEq(t, w, tt.expectedStatusCode)
+			utils.AssertJSONEq(t, w, tt.expectedBody)
+		})
+	}
+}
+
+func TestGetLeader(t *testing.T) {
+	for _, tt := range []struct {
+		name               string
+		prepare            func(dbClient *db.MockClient)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name: "should return not found when leader election is not enabled",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetLease(gomock.Any(), "watcher").Return(nil, db.ErrLeaseNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `{"errors": ["Leader election is not enabled"]}`,
+		},
+		{
+			name: "should handle error from db client",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetLease(gomock.Any(), "watcher").Return(nil, fmt.Errorf("could not get lease"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `{"errors": ["Failed to get lease"]}`,
+		},
+		{
+			name: "should return lease",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetLease(gomock.Any(), "watcher").Return(&db.Lease{Name: "watcher", Holder: "watcher-1", AcquiredAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), RenewedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), ExpiresAt: time.Date(2023, 1, 1, 0, 0, 15, 0, time.UTC)}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"name": "watcher", "holder": "watcher-1", "acquiredAt": "2023-01-01T00:00:00Z", "renewedAt": "2023-01-01T00:00:00Z", "expiresAt": "2023-01-01T00:00:15Z", "active": false}`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dbClient := db.NewMockClient(ctrl)
+			tt.prepare(dbClient)
+			router := Router{chi.NewRouter(), dbClient, nil}
+
+			ctx := context.Background()
+			ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/status/leader", nil)
+			w := httptest.NewRecorder()
+			router.getLeader(w, req)
+
+			utils.AssertStatusEq(t, w, tt.expectedStatusCode)
+			utils.AssertJSONEq(t, w, tt.expectedBody)
+		})
+	}
+}
+
+func TestRegisterCluster(t *testing.T) {
+	adminUser := authContext.User{ID: "admin@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"*"}, Namespaces: []string{"*"}, Resources: []string{"clusters"}, Verbs: []string{"*"}}}}}
+
+	for _, tt := range []struct {
+		name               string
+		user               authContext.User
+		body               string
+		prepare            func(dbClient *db.MockClient, clusterClient *clusters.MockClient)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "should fail when user is not allowed to register clusters",
+			user:               authContext.User{ID: "user@kobs.io"},
+			body:               `{"name": "cluster-1", "address": "http://cluster-1:15221"}`,
+			expectedStatusCode: http.StatusForbidden,
+			expectedBody:       `{"errors": ["You are not allowed to register clusters"]}`,
+		},
+		{
+			name:               "should fail for invalid request body",
+			user:               adminUser,
+			body:               `[]`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors": ["Failed to decode request body"]}`,
+		},
+		{
+			name:               "should fail for missing address",
+			user:               adminUser,
+			body:               `{"name": "cluster-1"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors": ["Name and address are required"]}`,
+		},
+		{
+			name: "should fail for static cluster",
+			user: adminUser,
+			body: `{"name": "cluster-1", "address": "http://cluster-1:15221"}`,
+			prepare: func(dbClient *db.MockClient, clusterClient *clusters.MockClient) {
+				clusterClient.EXPECT().IsStatic("cluster-1").Return(true)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors": ["Cluster is defined in the configuration and can not be changed"]}`,
+		},
+		{
+			name: "should fail for invalid address",
+			user: adminUser,
+			body: `{"name": "cluster-1", "address": " http://cluster-1:15221"}`,
+			prepare: func(dbClient *db.MockClient, clusterClient *clusters.MockClient) {
+				clusterClient.EXPECT().IsStatic("cluster-1").Return(false)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors": ["Invalid cluster configuration"]}`,
+		},
+		{
+			name: "should fail for invalid tls configuration",
+			user: adminUser,
+			body: `{"name": "cluster-1", "address": "https://cluster-1:15221", "tls": {"caFile": "/does/not/exist/ca.pem"}}`,
+			prepare: func(dbClient *db.MockClient, clusterClient *clusters.MockClient) {
+				clusterClient.EXPECT().IsStatic("cluster-1").Return(false)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors": ["Invalid cluster configuration"]}`,
+		},
+		{
+			name: "should fail when cluster can not be saved",
+			user: adminUser,
+			body: `{"name": "cluster-1", "address": "http://cluster-1:15221"}`,
+			prepare: func(dbClient *db.MockClient, clusterClient *clusters.MockClient) {
+				clusterClient.EXPECT().IsStatic("cluster-1").Return(false)
+				dbClient.EXPECT().SaveCluster(gomock.Any(), &db.Cluster{Name: "cluster-1", Address: "http://cluster-1:15221"}).Return(fmt.Errorf("unexpected error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `{"errors": ["Failed to register cluster"]}`,
+		},
+		{
+			name: "should register cluster",
+			user: adminUser,
+			body: `{"name": "cluster-1", "address": "https://cluster-1:15221", "token": "token", "tls": {"serverName": "cluster-1.kobs.io"}}`,
+			prepare: func(dbClient *db.MockClient, clusterClient *clusters.MockClient) {
+				clusterClient.EXPECT().IsStatic("cluster-1").Return(false)
+				dbClient.EXPECT().SaveCluster(gomock.Any(), &db.Cluster{Name: "cluster-1", Address: "https://cluster-1:15221", Token: "token", TLS: db.ClusterTLS{ServerName: "cluster-1.kobs.io"}}).Return(nil)
+				clusterClient.EXPECT().Sync(gomock.Any()).Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+			expectedBody:       `null`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dbClient := db.NewMockClient(ctrl)
+			clusterClient := clusters.NewMockClient(ctrl)
+			if tt.prepare != nil {
+				tt.prepare(dbClient, clusterClient)
+			}
+			router := Router{chi.NewRouter(), dbClient, clusterClient}
+
+			ctx := context.WithValue(context.Background(), authContext.UserKey, tt.user)
+			req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.registerCluster(w, req)
+
+			utils.AssertStatusEq(t, w, tt.expectedStatusCode)
+			utils.AssertJSONEq(t, w, tt.expectedBody)
+		})
+	}
+}
+
+func TestUnregisterCluster(t *testing.T) {
+	adminUser := authContext.User{ID: "admin@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"*"}, Namespaces: []string{"*"}, Resources: []string{"clusters"}, Verbs: []string{"delete"}}}}}
+
+	for _, tt := range []struct {
+		name               string
+		user               authContext.User
+		url                string
+		prepare            func(dbClient *db.MockClient, clusterClient *clusters.MockClient)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "should fail when user is not allowed to remove clusters",
+			user:               authContext.User{ID: "user@kobs.io"},
+			url:                "/?name=cluster-1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedBody:       `{"errors": ["You are not allowed to remove clusters"]}`,
+		},
+		{
+			name:               "should fail for missing name",
+			user:               adminUser,
+			url:                "/",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors": ["Name is required"]}`,
+		},
+		{
+			name: "should fail for static cluster",
+			user: adminUser,
+			url:  "/?name=cluster-1",
+			prepare: func(dbClient *db.MockClient, clusterClient *clusters.MockClient) {
+				clusterClient.EXPECT().IsStatic("cluster-1").Return(true)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors": ["Cluster is defined in the configuration and can not be removed"]}`,
+		},
+		{
+			name: "should fail when cluster can not be removed",
+			user: adminUser,
+			url:  "/?name=cluster-1",
+			prepare: func(dbClient *db.MockClient, clusterClient *clusters.MockClient) {
+				clusterClient.EXPECT().IsStatic("cluster-1").Return(false)
+				dbClient.EXPECT().DeleteCluster(gomock.Any(), "cluster-1").Return(fmt.Errorf("unexpected error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `{"errors": ["Failed to remove cluster"]}`,
+		},
+		{
+			name: "should remove cluster",
+			user: adminUser,
+			url:  "/?name=cluster-1",
+			prepare: func(dbClient *db.MockClient, clusterClient *clusters.MockClient) {
+				clusterClient.EXPECT().IsStatic("cluster-1").Return(false)
+				dbClient.EXPECT().DeleteCluster(gomock.Any(), "cluster-1").Return(nil)
+				clusterClient.EXPECT().Sync(gomock.Any()).Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+			expectedBody:       `null`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dbClient := db.NewMockClient(ctrl)
+			clusterClient := clusters.NewMockClient(ctrl)
+			if tt.prepare != nil {
+				tt.prepare(dbClient, clusterClient)
+			}
+			router := Router{chi.NewRouter(), dbClient, clusterClient}
+
+			ctx := context.WithValue(context.Background(), authContext.UserKey, tt.user)
+			req, _ := http.NewRequestWithContext(ctx, http.MethodDelete, tt.url, nil)
+			w := httptest.NewRecorder()
+			router.unregisterCluster(w, req)
+
+			utils.AssertStatusEq(t, w, tt.expectedStatusCode)
+			utils.AssertJSONEq(t, w, tt.expectedBody)
+		})
+	}
+}
+
 func TestMount(t *testing.T) {
 	router := Mount(nil, nil)
 	require.NotNil(t, router)
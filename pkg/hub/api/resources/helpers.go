@@ -1,6 +1,7 @@
 package resources
 
 import (
+	"net/http"
 	"strings"
 
 	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
@@ -291,3 +292,13 @@ func GetResource(id string) Resource {
 
 	return Resource{}
 }
+
+// getVerb returns the verb for the permission check of the provided request method. The cluster API uses the "PUT"
+// method to patch a resource, so that we have to map it to the "patch" verb.
+func getVerb(method string) string {
+	if method == http.MethodPut {
+		return "patch"
+	}
+
+	return strings.ToLower(method)
+}
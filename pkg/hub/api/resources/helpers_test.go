@@ -1,6 +1,7 @@
 package resources
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
@@ -42,3 +43,10 @@ func TestGetResource(t *testing.T) {
 		require.Equal(t, Resource{}, GetResource("this doesnt exist"))
 	})
 }
+
+func TestGetVerb(t *testing.T) {
+	require.Equal(t, "get", getVerb(http.MethodGet))
+	require.Equal(t, "post", getVerb(http.MethodPost))
+	require.Equal(t, "patch", getVerb(http.MethodPut))
+	require.Equal(t, "delete", getVerb(http.MethodDelete))
+}
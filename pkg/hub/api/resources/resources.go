@@ -14,6 +14,7 @@ import (
 	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
 	"github.com/kobsio/kobs/pkg/hub/clusters"
 	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/hub/recordings"
 	"github.com/kobsio/kobs/pkg/instrument/log"
 	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
 
@@ -45,9 +46,10 @@ type ResourceResponseNamespace struct {
 // configured integration to serve our needs.
 type Router struct {
 	*chi.Mux
-	appSettings    settings.Settings
-	clustersClient clusters.Client
-	dbClient       db.Client
+	appSettings      settings.Settings
+	clustersClient   clusters.Client
+	dbClient         db.Client
+	recordingsClient recordings.Client
 }
 
 func (router *Router) resourcesHandler(w http.ResponseWriter, r *http.Request) {
@@ -71,26 +73,52 @@ func (router *Router) resourcesHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if strings.HasSuffix(r.URL.Path, "/logs") {
-			if !user.HasResourceAccess(clusterHeader, r.URL.Query().Get("namespace"), "pods/logs", "*") {
-				log.Warn(ctx, "User is not authorized to access resource", zap.String("cluster", clusterHeader), zap.String("namespace", r.URL.Query().Get("namespace")), zap.String("resource", "pods/logs"), zap.String("method", "*"))
+		if strings.HasSuffix(r.URL.Path, "/logs/stream") && r.URL.Query().Get("namespace") == "" {
+			log.Warn(ctx, "The 'namespace' parameter is required", zap.String("cluster", clusterHeader))
+			errresponse.Render(w, r, http.StatusBadRequest, "The 'namespace' parameter is required")
+			return
+		}
+
+		if strings.HasSuffix(r.URL.Path, "/logs") || strings.HasSuffix(r.URL.Path, "/logs/stream") {
+			if !user.HasResourceAccess(clusterHeader, r.URL.Query().Get("namespace"), "pods/logs", "get") {
+				log.Warn(ctx, "User is not authorized to access resource", zap.String("cluster", clusterHeader), zap.String("namespace", r.URL.Query().Get("namespace")), zap.String("resource", "pods/logs"), zap.String("method", "get"))
+				errresponse.Render(w, r, http.StatusUnauthorized)
+				return
+			}
+		} else if strings.HasSuffix(r.URL.Path, "/portforward") {
+			if !user.HasResourceAccess(clusterHeader, r.URL.Query().Get("namespace"), "pods/portforward", "exec") {
+				log.Warn(ctx, "User is not authorized to access resource", zap.String("cluster", clusterHeader), zap.String("namespace", r.URL.Query().Get("namespace")), zap.String("resource", "pods/portforward"), zap.String("method", "exec"))
 				errresponse.Render(w, r, http.StatusUnauthorized)
 				return
 			}
-		} else if strings.HasSuffix(r.URL.Path, "/terminal") || strings.HasSuffix(r.URL.Path, "/file") {
-			if !user.HasResourceAccess(clusterHeader, r.URL.Query().Get("namespace"), "pods/exec", "*") {
-				log.Warn(ctx, "User is not authorized to access resource", zap.String("cluster", clusterHeader), zap.String("namespace", r.URL.Query().Get("namespace")), zap.String("resource", "pods/logs"), zap.String("method", "*"))
+		} else if strings.HasSuffix(r.URL.Path, "/terminal/debug") {
+			if !user.HasResourceAccess(clusterHeader, r.URL.Query().Get("namespace"), "pods/debug", "exec") {
+				log.Warn(ctx, "User is not authorized to access resource", zap.String("cluster", clusterHeader), zap.String("namespace", r.URL.Query().Get("namespace")), zap.String("resource", "pods/debug"), zap.String("method", "exec"))
+				errresponse.Render(w, r, http.StatusUnauthorized)
+				return
+			}
+		} else if strings.HasSuffix(r.URL.Path, "/terminal") || strings.HasSuffix(r.URL.Path, "/file") || strings.HasSuffix(r.URL.Path, "/file/archive") {
+			if !user.HasResourceAccess(clusterHeader, r.URL.Query().Get("namespace"), "pods/exec", "exec") {
+				log.Warn(ctx, "User is not authorized to access resource", zap.String("cluster", clusterHeader), zap.String("namespace", r.URL.Query().Get("namespace")), zap.String("resource", "pods/exec"), zap.String("method", "exec"))
 				errresponse.Render(w, r, http.StatusUnauthorized)
 				return
 			}
 		} else {
-			if !user.HasResourceAccess(clusterHeader, r.URL.Query().Get("namespace"), r.URL.Query().Get("resource"), r.Method) {
-				log.Warn(ctx, "User is not authorized to access resource", zap.String("cluster", clusterHeader), zap.String("namespace", r.URL.Query().Get("namespace")), zap.String("resource", r.URL.Query().Get("resource")), zap.String("method", r.Method))
+			if !user.HasResourceAccess(clusterHeader, r.URL.Query().Get("namespace"), r.URL.Query().Get("resource"), getVerb(r.Method)) {
+				log.Warn(ctx, "User is not authorized to access resource", zap.String("cluster", clusterHeader), zap.String("namespace", r.URL.Query().Get("namespace")), zap.String("resource", r.URL.Query().Get("resource")), zap.String("method", getVerb(r.Method)))
 				errresponse.Render(w, r, http.StatusUnauthorized)
 				return
 			}
 		}
 
+		// Terminal sessions are not proxied to the cluster, when they should be recorded. In this case the recordings
+		// client relays the messages between the user and the cluster, so that the output of the terminal can be
+		// recorded.
+		if (strings.HasSuffix(r.URL.Path, "/terminal") || strings.HasSuffix(r.URL.Path, "/terminal/debug")) && router.recordingsClient != nil && router.recordingsClient.IsEnabled(clusterHeader) {
+			router.recordingsClient.Terminal(w, r, clusterClient)
+			return
+		}
+
 		clusterClient.Proxy(w, r)
 		return
 	}
@@ -177,7 +205,7 @@ func (router *Router) resourcesHandler(w http.ResponseWriter, r *http.Request) {
 								return
 							}
 
-							ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+							ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), authContext.UserKey, *user), 10*time.Second)
 							defer cancel()
 
 							manifest, err := clusterClient.Request(ctx, http.MethodGet, fmt.Sprintf("/api/resources?namespace=%s&resource=%s&path=%s&paramName=%s&param=%s", "", r.Resource, r.Path, paramName, url.QueryEscape(param)), nil)
@@ -213,7 +241,7 @@ func (router *Router) resourcesHandler(w http.ResponseWriter, r *http.Request) {
 									return
 								}
 
-								ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+								ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), authContext.UserKey, *user), 10*time.Second)
 								defer cancel()
 
 								if r.Scope == "Cluster" {
@@ -274,12 +302,13 @@ func (router *Router) resourcesHandler(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, resourceResponses)
 }
 
-func Mount(appSettings settings.Settings, clustersClient clusters.Client, dbClient db.Client) chi.Router {
+func Mount(appSettings settings.Settings, clustersClient clusters.Client, dbClient db.Client, recordingsClient recordings.Client) chi.Router {
 	router := Router{
 		chi.NewRouter(),
 		appSettings,
 		clustersClient,
 		dbClient,
+		recordingsClient,
 	}
 
 	router.HandleFunc("/*", router.resourcesHandler)
@@ -2,12 +2,17 @@ package teams
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
 	"github.com/kobsio/kobs/pkg/hub/app/settings"
 	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/clusters"
+	"github.com/kobsio/kobs/pkg/hub/clusters/cluster"
 	"github.com/kobsio/kobs/pkg/hub/db"
 	"github.com/kobsio/kobs/pkg/instrument/log"
 	"github.com/kobsio/kobs/pkg/utils"
@@ -18,10 +23,15 @@ import (
 	"go.uber.org/zap"
 )
 
+// teamResource is the name of the Team CR in the resource permissions of a user. Only users which are allowed to edit
+// the Team CR via the resources API can change the permissions of a team.
+const teamResource = "teams.kobs.io/v1"
+
 type Router struct {
 	*chi.Mux
-	appSettings settings.Settings
-	dbClient    db.Client
+	appSettings    settings.Settings
+	clustersClient clusters.Client
+	dbClient       db.Client
 }
 
 func (router *Router) getTeams(w http.ResponseWriter, r *http.Request) {
@@ -91,6 +101,10 @@ func (router *Router) getTeam(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, team)
 }
 
+// saveTeam saves the team from the request body. When the team already exists (the `resourceVersion` is set), the
+// request is checked against the stored team, so that the cluster, namespace and name of the team can not be changed.
+// The permissions of the team are always taken from the stored team, when the user is not allowed to edit the Team CR
+// via the resources API, because otherwise every member of a team could grant additional permissions to the team.
 func (router *Router) saveTeam(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	user := authContext.MustGetUser(ctx)
@@ -121,6 +135,65 @@ func (router *Router) saveTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if team.ResourceVersion != "" {
+		storedTeam, err := router.dbClient.GetTeamByID(ctx, team.ID)
+		if err != nil {
+			log.Error(ctx, "Failed to get team", zap.Error(err), zap.String("id", team.ID))
+			errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get team")
+			return
+		}
+
+		if storedTeam == nil {
+			log.Warn(ctx, "Team was not found", zap.String("id", team.ID))
+			errresponse.Render(w, r, http.StatusNotFound, "Team was not found")
+			return
+		}
+
+		if storedTeam.Cluster != team.Cluster || storedTeam.Namespace != team.Namespace || storedTeam.Name != team.Name {
+			log.Warn(ctx, "The cluster, namespace and name of the team can not be changed", zap.String("id", team.ID))
+			errresponse.Render(w, r, http.StatusBadRequest, "The cluster, namespace and name of the team can not be changed")
+			return
+		}
+
+		if !user.HasResourceAccess(team.Cluster, team.Namespace, teamResource, "patch") {
+			team.Permissions = storedTeam.Permissions
+		}
+	} else {
+		if storedTeam, err := router.dbClient.GetTeamByID(ctx, team.ID); err == nil && storedTeam != nil {
+			log.Warn(ctx, "Team already exists", zap.String("id", team.ID))
+			errresponse.Render(w, r, http.StatusConflict, "The team already exists, reload the team and try again")
+			return
+		}
+
+		if !user.HasResourceAccess(team.Cluster, team.Namespace, teamResource, "post") {
+			team.Permissions = userv1.Permissions{}
+		}
+	}
+
+	if router.appSettings.Save.WriteBack {
+		clusterClient := router.clustersClient.GetCluster(team.Cluster)
+		if clusterClient == nil {
+			log.Error(ctx, "Invalid cluster name", zap.String("cluster", team.Cluster))
+			errresponse.Render(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid cluster name: %s", team.Cluster))
+			return
+		}
+
+		savedTeam, err := clusterClient.SaveTeam(ctx, team)
+		if err != nil {
+			log.Error(ctx, "Failed to write team back to cluster", zap.Error(err))
+
+			if errors.Is(err, cluster.ErrConflict) {
+				errresponse.Render(w, r, http.StatusConflict, "The team was modified, reload the team and try again")
+				return
+			}
+
+			errresponse.Render(w, r, http.StatusInternalServerError, "Failed to write team back to cluster")
+			return
+		}
+
+		team = *savedTeam
+	}
+
 	err = router.dbClient.SaveTeam(ctx, &team)
 	if err != nil {
 		log.Error(ctx, "Failed to save team", zap.Error(err))
@@ -132,10 +205,11 @@ func (router *Router) saveTeam(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, nil)
 }
 
-func Mount(appSettings settings.Settings, dbClient db.Client) chi.Router {
+func Mount(appSettings settings.Settings, clustersClient clusters.Client, dbClient db.Client) chi.Router {
 	router := Router{
 		chi.NewRouter(),
 		appSettings,
+		clustersClient,
 		dbClient,
 	}
 
@@ -12,6 +12,8 @@ import (
 	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
 	"github.com/kobsio/kobs/pkg/hub/app/settings"
 	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/clusters"
+	"github.com/kobsio/kobs/pkg/hub/clusters/cluster"
 	"github.com/kobsio/kobs/pkg/hub/db"
 	"github.com/kobsio/kobs/pkg/utils"
 
@@ -26,7 +28,7 @@ func TestGetTeams(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		dbClient := db.NewMockClient(ctrl)
 
-		router := Router{chi.NewRouter(), settings.Settings{}, dbClient}
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient}
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
 		ctx = context.WithValue(ctx, authContext.UserKey, user)
@@ -45,7 +47,7 @@ func TestGetTeams(t *testing.T) {
 		dbClient := db.NewMockClient(ctrl)
 		dbClient.EXPECT().GetTeams(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("could not get teams"))
 
-		router := Router{chi.NewRouter(), settings.Settings{}, dbClient}
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient}
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
 		ctx = context.WithValue(ctx, authContext.UserKey, user)
@@ -64,7 +66,7 @@ func TestGetTeams(t *testing.T) {
 		dbClient := db.NewMockClient(ctrl)
 		dbClient.EXPECT().GetTeams(gomock.Any(), gomock.Any()).Return([]teamv1.TeamSpec{{ID: "team1"}, {ID: "team2"}, {ID: "team3"}, {ID: "team1"}, {ID: "team2"}}, nil)
 
-		router := Router{chi.NewRouter(), settings.Settings{}, dbClient}
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient}
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
 		ctx = context.WithValue(ctx, authContext.UserKey, user)
@@ -84,7 +86,7 @@ func TestGetTeams(t *testing.T) {
 		dbClient := db.NewMockClient(ctrl)
 		dbClient.EXPECT().GetTeamsByIDs(gomock.Any(), teamIDs, gomock.Any()).Return(nil, fmt.Errorf("could not get teams"))
 
-		router := Router{chi.NewRouter(), settings.Settings{}, dbClient}
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient}
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
 		ctx = context.WithValue(ctx, authContext.UserKey, user)
@@ -104,7 +106,7 @@ func TestGetTeams(t *testing.T) {
 		dbClient := db.NewMockClient(ctrl)
 		dbClient.EXPECT().GetTeamsByIDs(gomock.Any(), teamIDs, gomock.Any()).Return([]teamv1.TeamSpec{{ID: "team1"}, {ID: "team1"}}, nil)
 
-		router := Router{chi.NewRouter(), settings.Settings{}, dbClient}
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient}
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
 		ctx = context.WithValue(ctx, authContext.UserKey, user)
@@ -124,7 +126,7 @@ func TestGetTeam(t *testing.T) {
 
 		ctrl := gomock.NewController(t)
 		dbClient := db.NewMockClient(ctrl)
-		router := Router{chi.NewRouter(), settings.Settings{}, dbClient}
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient}
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
 		ctx = context.WithValue(ctx, authContext.UserKey, user)
@@ -145,7 +147,7 @@ func TestGetTeam(t *testing.T) {
 		dbClient := db.NewMockClient(ctrl)
 		dbClient.EXPECT().GetTeamByID(gomock.Any(), teamID).Return(nil, fmt.Errorf("could not get team"))
 
-		router := Router{chi.NewRouter(), settings.Settings{}, dbClient}
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient}
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
 		ctx = context.WithValue(ctx, authContext.UserKey, user)
@@ -166,7 +168,7 @@ func TestGetTeam(t *testing.T) {
 		dbClient := db.NewMockClient(ctrl)
 		dbClient.EXPECT().GetTeamByID(gomock.Any(), teamID).Return(&teamv1.TeamSpec{ID: teamID}, nil)
 
-		router := Router{chi.NewRouter(), settings.Settings{}, dbClient}
+		router := Router{chi.NewRouter(), settings.Settings{}, nil, dbClient}
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
 		ctx = context.WithValue(ctx, authContext.UserKey, user)
@@ -184,9 +186,9 @@ func TestSaveTeam(t *testing.T) {
 	var newRouter = func(t *testing.T, saveEnabled bool) (*db.MockClient, Router) {
 		ctrl := gomock.NewController(t)
 		dbClient := db.NewMockClient(ctrl)
-		router := Router{chi.NewRouter(), settings.Settings{Save: struct {
-			Enabled bool `json:"enabled"`
-		}{Enabled: saveEnabled}}, dbClient}
+		appSettings := settings.Settings{}
+		appSettings.Save.Enabled = saveEnabled
+		router := Router{chi.NewRouter(), appSettings, nil, dbClient}
 
 		return dbClient, router
 	}
@@ -245,6 +247,7 @@ func TestSaveTeam(t *testing.T) {
 
 	t.Run("should return error on db error", func(t *testing.T) {
 		dbClient, router := newRouter(t, true)
+		dbClient.EXPECT().GetTeamByID(gomock.Any(), "team@kobs.io").Return(nil, fmt.Errorf("team not found"))
 		dbClient.EXPECT().SaveTeam(gomock.Any(), gomock.Any()).Return(fmt.Errorf("unexpected error"))
 
 		ctx := context.Background()
@@ -259,6 +262,7 @@ func TestSaveTeam(t *testing.T) {
 
 	t.Run("should save team", func(t *testing.T) {
 		dbClient, router := newRouter(t, true)
+		dbClient.EXPECT().GetTeamByID(gomock.Any(), "team@kobs.io").Return(nil, fmt.Errorf("team not found"))
 		dbClient.EXPECT().SaveTeam(gomock.Any(), gomock.Any()).Return(nil)
 
 		ctx := context.Background()
@@ -272,7 +276,177 @@ func TestSaveTeam(t *testing.T) {
 	})
 }
 
+func TestSaveTeamWriteBack(t *testing.T) {
+	var newRouter = func(t *testing.T) (*db.MockClient, *clusters.MockClient, *cluster.MockClient, Router) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		clustersClient := clusters.NewMockClient(ctrl)
+		clusterClient := cluster.NewMockClient(ctrl)
+
+		appSettings := settings.Settings{}
+		appSettings.Save.Enabled = true
+		appSettings.Save.WriteBack = true
+		router := Router{chi.NewRouter(), appSettings, clustersClient, dbClient}
+
+		return dbClient, clustersClient, clusterClient, router
+	}
+
+	t.Run("should return error for invalid cluster", func(t *testing.T) {
+		dbClient, clustersClient, _, router := newRouter(t)
+		dbClient.EXPECT().GetTeamByID(gomock.Any(), "team@kobs.io").Return(&teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test"}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Teams: []string{"team@kobs.io"}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/team", strings.NewReader(`{"id": "team@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveTeam(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		utils.AssertJSONEq(t, w, `{"errors": ["Invalid cluster name: test"]}`)
+	})
+
+	t.Run("should return error on conflict", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetTeamByID(gomock.Any(), "team@kobs.io").Return(&teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test"}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveTeam(gomock.Any(), teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "1"}).Return(nil, fmt.Errorf("%w: [The team was modified]", cluster.ErrConflict))
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Teams: []string{"team@kobs.io"}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/team", strings.NewReader(`{"id": "team@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveTeam(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusConflict)
+		utils.AssertJSONEq(t, w, `{"errors": ["The team was modified, reload the team and try again"]}`)
+	})
+
+	t.Run("should return error when write back fails", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetTeamByID(gomock.Any(), "team@kobs.io").Return(&teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test"}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveTeam(gomock.Any(), teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "1"}).Return(nil, fmt.Errorf("unexpected error"))
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Teams: []string{"team@kobs.io"}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/team", strings.NewReader(`{"id": "team@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveTeam(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusInternalServerError)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to write team back to cluster"]}`)
+	})
+
+	t.Run("should write back and save team", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetTeamByID(gomock.Any(), "team@kobs.io").Return(&teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test"}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveTeam(gomock.Any(), teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "1"}).Return(&teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "2"}, nil)
+		dbClient.EXPECT().SaveTeam(gomock.Any(), &teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "2"}).Return(nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Teams: []string{"team@kobs.io"}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/team", strings.NewReader(`{"id": "team@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveTeam(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusNoContent)
+		utils.AssertJSONEq(t, w, `null`)
+	})
+
+	t.Run("should return error when team was not found", func(t *testing.T) {
+		dbClient, _, _, router := newRouter(t)
+		dbClient.EXPECT().GetTeamByID(gomock.Any(), "team@kobs.io").Return(nil, nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Teams: []string{"team@kobs.io"}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/team", strings.NewReader(`{"id": "team@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "permissions": {"teams": ["*"]}, "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveTeam(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusNotFound)
+		utils.AssertJSONEq(t, w, `{"errors": ["Team was not found"]}`)
+	})
+
+	t.Run("should return error when cluster, namespace or name is changed", func(t *testing.T) {
+		dbClient, _, _, router := newRouter(t)
+		dbClient.EXPECT().GetTeamByID(gomock.Any(), "team@kobs.io").Return(&teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "kobs", Name: "test"}, nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Teams: []string{"team@kobs.io"}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/team", strings.NewReader(`{"id": "team@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "permissions": {"teams": ["*"]}, "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveTeam(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		utils.AssertJSONEq(t, w, `{"errors": ["The cluster, namespace and name of the team can not be changed"]}`)
+	})
+
+	t.Run("should keep permissions of the stored team", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetTeamByID(gomock.Any(), "team@kobs.io").Return(&teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test", Permissions: userv1.Permissions{Teams: []string{"team@kobs.io"}}}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveTeam(gomock.Any(), teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test", Permissions: userv1.Permissions{Teams: []string{"team@kobs.io"}}, ResourceVersion: "1"}).Return(&teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "2"}, nil)
+		dbClient.EXPECT().SaveTeam(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Teams: []string{"team@kobs.io"}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/team", strings.NewReader(`{"id": "team@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "permissions": {"teams": ["*"]}, "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveTeam(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusNoContent)
+	})
+
+	t.Run("should change permissions when user is allowed to edit the team CR", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetTeamByID(gomock.Any(), "team@kobs.io").Return(&teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test"}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveTeam(gomock.Any(), teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test", Permissions: userv1.Permissions{Teams: []string{"*"}}, ResourceVersion: "1"}).Return(&teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "2"}, nil)
+		dbClient.EXPECT().SaveTeam(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Teams: []string{"team@kobs.io"}, Resources: []userv1.Resources{{Clusters: []string{"test"}, Namespaces: []string{"test"}, Resources: []string{"teams.kobs.io/v1"}, Verbs: []string{"patch"}}}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/team", strings.NewReader(`{"id": "team@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "permissions": {"teams": ["*"]}, "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveTeam(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusNoContent)
+	})
+
+	t.Run("should return error when new team already exists", func(t *testing.T) {
+		dbClient, _, _, router := newRouter(t)
+		dbClient.EXPECT().GetTeamByID(gomock.Any(), "team@kobs.io").Return(&teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test"}, nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Teams: []string{"team@kobs.io"}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/team", strings.NewReader(`{"id": "team@kobs.io", "cluster": "test", "namespace": "test", "name": "test"}`))
+		w := httptest.NewRecorder()
+		router.saveTeam(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusConflict)
+		utils.AssertJSONEq(t, w, `{"errors": ["The team already exists, reload the team and try again"]}`)
+	})
+
+	t.Run("should remove permissions of new team", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetTeamByID(gomock.Any(), "team@kobs.io").Return(nil, fmt.Errorf("team not found"))
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveTeam(gomock.Any(), teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test"}).Return(&teamv1.TeamSpec{ID: "team@kobs.io", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "1"}, nil)
+		dbClient.EXPECT().SaveTeam(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{Permissions: userv1.Permissions{Teams: []string{"team@kobs.io"}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/team", strings.NewReader(`{"id": "team@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "permissions": {"teams": ["*"]}}`))
+		w := httptest.NewRecorder()
+		router.saveTeam(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusNoContent)
+	})
+}
+
 func TestMount(t *testing.T) {
-	router := Mount(settings.Settings{}, nil)
+	router := Mount(settings.Settings{}, nil, nil)
 	require.NotNil(t, router)
 }
@@ -0,0 +1,106 @@
+package tunnel
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/kobsio/kobs/pkg/hub/clusters"
+	"github.com/kobsio/kobs/pkg/hub/clusters/cluster"
+	"github.com/kobsio/kobs/pkg/instrument/log"
+	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
+	"github.com/kobsio/kobs/pkg/utils/tunnel"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// Router implements the tunnel API. The API is used by clusters which can not be reached by the hub, to connect to the
+// hub via a tunnel. Since these clusters are also not reachable by the watcher, the API can also be used by the watcher
+// to send its requests through the tunnel.
+//
+// The API is not protected by the auth middleware of the hub. Instead the cluster and the watcher must provide the
+// token of the cluster, which is configured in the hub.
+type Router struct {
+	*chi.Mux
+	clustersClient clusters.Client
+}
+
+// authenticate returns the cluster with the provided name, when the cluster is connected via a tunnel and the request
+// contains the token of the cluster. If this isn't the case nil is returned.
+func (router *Router) authenticate(r *http.Request, name string) cluster.Client {
+	authHeader := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return nil
+	}
+
+	clusterClient := router.clustersClient.GetCluster(name)
+	if clusterClient == nil || !clusterClient.AuthenticateTunnel(strings.TrimPrefix(authHeader, "Bearer ")) {
+		return nil
+	}
+
+	return clusterClient
+}
+
+// connect is called by a cluster to open a new tunnel. The connection is upgraded to a WebSocket connection, which is
+// then used to multiplex all requests from the hub to the cluster. The tunnel is registered until the connection is
+// closed.
+func (router *Router) connect(w http.ResponseWriter, r *http.Request) {
+	name := r.Header.Get(tunnel.ClusterHeader)
+
+	if router.authenticate(r, name) == nil {
+		log.Warn(r.Context(), "Cluster is not allowed to open a tunnel", zap.String("cluster", name))
+		errresponse.Render(w, r, http.StatusUnauthorized)
+		return
+	}
+
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Error(r.Context(), "Failed to upgrade connection", zap.Error(err), zap.String("cluster", name))
+		return
+	}
+
+	session := tunnel.NewSession(conn, true)
+	cluster.RegisterTunnel(name, session)
+	log.Info(r.Context(), "Tunnel connected", zap.String("cluster", name))
+
+	<-session.Done()
+
+	cluster.UnregisterTunnel(name, session)
+	log.Info(r.Context(), "Tunnel disconnected", zap.String("cluster", name))
+}
+
+// proxy sends the request through the tunnel of the cluster from the `cluster` url parameter. This is used by the
+// watcher, which can not open the tunnel by itself, because the tunnel is connected to the hub.
+func (router *Router) proxy(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "cluster")
+
+	clusterClient := router.authenticate(r, name)
+	if clusterClient == nil {
+		log.Warn(r.Context(), "Request is not allowed to use the tunnel", zap.String("cluster", name))
+		errresponse.Render(w, r, http.StatusUnauthorized)
+		return
+	}
+
+	r.URL.Path = "/" + chi.URLParam(r, "*")
+	r.URL.RawPath = ""
+
+	clusterClient.Proxy(w, r)
+}
+
+func Mount(clustersClient clusters.Client) chi.Router {
+	router := Router{
+		chi.NewRouter(),
+		clustersClient,
+	}
+
+	router.Get("/", router.connect)
+	router.HandleFunc("/clusters/{cluster}/*", router.proxy)
+
+	return router
+}
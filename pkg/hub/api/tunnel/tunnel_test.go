@@ -0,0 +1,99 @@
+package tunnel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kobsio/kobs/pkg/hub/clusters"
+	"github.com/kobsio/kobs/pkg/hub/clusters/cluster"
+	"github.com/kobsio/kobs/pkg/utils/tunnel"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTunnel(t *testing.T) {
+	clustersClient, err := clusters.NewClient(clusters.Config{{Name: "cluster1", Address: cluster.TunnelAddress, Token: "token1"}, {Name: "cluster2", Address: "http://localhost:15221", Token: "token2"}}, nil)
+	require.NoError(t, err)
+
+	hubServer := httptest.NewServer(Mount(clustersClient))
+	defer hubServer.Close()
+
+	var connect = func(name, token string) (*tunnel.Session, error) {
+		header := http.Header{}
+		header.Set("Authorization", "Bearer "+token)
+		header.Set(tunnel.ClusterHeader, name)
+
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(hubServer.URL, "http")+"/", header)
+		if err != nil {
+			return nil, err
+		}
+
+		return tunnel.NewSession(conn, false), nil
+	}
+
+	t.Run("should fail for invalid token", func(t *testing.T) {
+		_, err := connect("cluster1", "token2")
+		require.Error(t, err)
+	})
+
+	t.Run("should fail for cluster without tunnel", func(t *testing.T) {
+		_, err := connect("cluster2", "token2")
+		require.Error(t, err)
+	})
+
+	t.Run("should send requests through tunnel", func(t *testing.T) {
+		session, err := connect("cluster1", "token1")
+		require.NoError(t, err)
+		defer session.Close()
+
+		server := &http.Server{
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer token1" {
+					w.WriteHeader(http.StatusUnauthorized)
+					w.Write([]byte(`{"errors": ["Unauthorized"]}`))
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`["default", "kube-system"]`))
+			}),
+			ReadHeaderTimeout: 3 * time.Second,
+		}
+		go server.Serve(session)
+		defer server.Close()
+
+		clusterClient := clustersClient.GetCluster("cluster1")
+
+		require.Eventually(t, func() bool {
+			namespaces, err := clusterClient.GetNamespaces(context.Background())
+			return err == nil && len(namespaces) == 2
+		}, 5*time.Second, 10*time.Millisecond)
+
+		req, _ := http.NewRequest(http.MethodGet, hubServer.URL+"/clusters/cluster1/api/resources/namespaces", nil)
+		req.Header.Set("Authorization", "Bearer token1")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		req, _ = http.NewRequest(http.MethodGet, hubServer.URL+"/clusters/cluster1/api/resources/namespaces", nil)
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("should fail when cluster is not connected", func(t *testing.T) {
+		clusterClient := clustersClient.GetCluster("cluster1")
+
+		require.Eventually(t, func() bool {
+			_, err := clusterClient.GetNamespaces(context.Background())
+			return err != nil
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
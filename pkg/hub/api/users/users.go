@@ -2,11 +2,15 @@ package users
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 
 	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
 	"github.com/kobsio/kobs/pkg/hub/app/settings"
 	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/clusters"
+	"github.com/kobsio/kobs/pkg/hub/clusters/cluster"
 	"github.com/kobsio/kobs/pkg/hub/db"
 	"github.com/kobsio/kobs/pkg/instrument/log"
 	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
@@ -16,12 +20,22 @@ import (
 	"go.uber.org/zap"
 )
 
+// userResource is the name of the User CR in the resource permissions of a user. Only users which are allowed to edit
+// the User CR via the resources API can change the teams and permissions of a user.
+const userResource = "users.kobs.io/v1"
+
 type Router struct {
 	*chi.Mux
-	appSettings settings.Settings
-	dbClient    db.Client
+	appSettings    settings.Settings
+	clustersClient clusters.Client
+	dbClient       db.Client
 }
 
+// saveUser saves the user from the request body. A user can only save their own user. When the user already exists (the
+// `resourceVersion` is set), the request is checked against the stored user, so that the cluster, namespace and name
+// of the user can not be changed. The teams and permissions are always taken from the stored user, when the user is not
+// allowed to edit the User CR via the resources API, because otherwise every user could grant themselves additional
+// permissions.
 func (router *Router) saveUser(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	authUser := authContext.MustGetUser(ctx)
@@ -52,6 +66,67 @@ func (router *Router) saveUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	storedUser, err := router.dbClient.GetUserByID(ctx, user.ID)
+	if err != nil {
+		log.Error(ctx, "Failed to get user", zap.Error(err), zap.String("id", user.ID))
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get user")
+		return
+	}
+
+	if user.ResourceVersion != "" {
+		if storedUser == nil {
+			log.Warn(ctx, "User was not found", zap.String("id", user.ID))
+			errresponse.Render(w, r, http.StatusNotFound, "User was not found")
+			return
+		}
+
+		if storedUser.Cluster != user.Cluster || storedUser.Namespace != user.Namespace || storedUser.Name != user.Name {
+			log.Warn(ctx, "The cluster, namespace and name of the user can not be changed", zap.String("id", user.ID))
+			errresponse.Render(w, r, http.StatusBadRequest, "The cluster, namespace and name of the user can not be changed")
+			return
+		}
+
+		if !authUser.HasResourceAccess(user.Cluster, user.Namespace, userResource, "patch") {
+			user.Teams = storedUser.Teams
+			user.Permissions = storedUser.Permissions
+		}
+	} else {
+		if storedUser != nil {
+			log.Warn(ctx, "User already exists", zap.String("id", user.ID))
+			errresponse.Render(w, r, http.StatusConflict, "The user already exists, reload the user and try again")
+			return
+		}
+
+		if !authUser.HasResourceAccess(user.Cluster, user.Namespace, userResource, "post") {
+			user.Teams = nil
+			user.Permissions = userv1.Permissions{}
+		}
+	}
+
+	if router.appSettings.Save.WriteBack {
+		clusterClient := router.clustersClient.GetCluster(user.Cluster)
+		if clusterClient == nil {
+			log.Error(ctx, "Invalid cluster name", zap.String("cluster", user.Cluster))
+			errresponse.Render(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid cluster name: %s", user.Cluster))
+			return
+		}
+
+		savedUser, err := clusterClient.SaveUser(ctx, user)
+		if err != nil {
+			log.Error(ctx, "Failed to write user back to cluster", zap.Error(err))
+
+			if errors.Is(err, cluster.ErrConflict) {
+				errresponse.Render(w, r, http.StatusConflict, "The user was modified, reload the user and try again")
+				return
+			}
+
+			errresponse.Render(w, r, http.StatusInternalServerError, "Failed to write user back to cluster")
+			return
+		}
+
+		user = *savedUser
+	}
+
 	err = router.dbClient.SaveUser(ctx, &user)
 	if err != nil {
 		log.Error(ctx, "Failed to save user", zap.Error(err))
@@ -63,10 +138,11 @@ func (router *Router) saveUser(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, nil)
 }
 
-func Mount(appSettings settings.Settings, dbClient db.Client) chi.Router {
+func Mount(appSettings settings.Settings, clustersClient clusters.Client, dbClient db.Client) chi.Router {
 	router := Router{
 		chi.NewRouter(),
 		appSettings,
+		clustersClient,
 		dbClient,
 	}
 
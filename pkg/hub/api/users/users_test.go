@@ -8,8 +8,11 @@ import (
 	"strings"
 	"testing"
 
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
 	"github.com/kobsio/kobs/pkg/hub/app/settings"
 	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/clusters"
+	"github.com/kobsio/kobs/pkg/hub/clusters/cluster"
 	"github.com/kobsio/kobs/pkg/hub/db"
 	"github.com/kobsio/kobs/pkg/utils"
 
@@ -22,9 +25,9 @@ func TestSaveTeam(t *testing.T) {
 	var newRouter = func(t *testing.T, saveEnabled bool) (*db.MockClient, Router) {
 		ctrl := gomock.NewController(t)
 		dbClient := db.NewMockClient(ctrl)
-		router := Router{chi.NewRouter(), settings.Settings{Save: struct {
-			Enabled bool `json:"enabled"`
-		}{Enabled: saveEnabled}}, dbClient}
+		appSettings := settings.Settings{}
+		appSettings.Save.Enabled = saveEnabled
+		router := Router{chi.NewRouter(), appSettings, nil, dbClient}
 
 		return dbClient, router
 	}
@@ -81,8 +84,23 @@ func TestSaveTeam(t *testing.T) {
 		utils.AssertJSONEq(t, w, `{"errors": ["You are not allowed to edit the user"]}`)
 	})
 
+	t.Run("should return error when user can not be loaded", func(t *testing.T) {
+		dbClient, router := newRouter(t, true)
+		dbClient.EXPECT().GetUserByID(gomock.Any(), "user@kobs.io").Return(nil, fmt.Errorf("unexpected error"))
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{ID: "user@kobs.io"})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/user", strings.NewReader(`{"id": "user@kobs.io", "cluster": "test", "namespace": "test", "name": "test"}`))
+		w := httptest.NewRecorder()
+		router.saveUser(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusInternalServerError)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to get user"]}`)
+	})
+
 	t.Run("should return error on db error", func(t *testing.T) {
 		dbClient, router := newRouter(t, true)
+		dbClient.EXPECT().GetUserByID(gomock.Any(), "user@kobs.io").Return(nil, nil)
 		dbClient.EXPECT().SaveUser(gomock.Any(), gomock.Any()).Return(fmt.Errorf("unexpected error"))
 
 		ctx := context.Background()
@@ -97,6 +115,7 @@ func TestSaveTeam(t *testing.T) {
 
 	t.Run("should save user", func(t *testing.T) {
 		dbClient, router := newRouter(t, true)
+		dbClient.EXPECT().GetUserByID(gomock.Any(), "user@kobs.io").Return(nil, nil)
 		dbClient.EXPECT().SaveUser(gomock.Any(), gomock.Any()).Return(nil)
 
 		ctx := context.Background()
@@ -110,7 +129,177 @@ func TestSaveTeam(t *testing.T) {
 	})
 }
 
+func TestSaveUserWriteBack(t *testing.T) {
+	var newRouter = func(t *testing.T) (*db.MockClient, *clusters.MockClient, *cluster.MockClient, Router) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		clustersClient := clusters.NewMockClient(ctrl)
+		clusterClient := cluster.NewMockClient(ctrl)
+
+		appSettings := settings.Settings{}
+		appSettings.Save.Enabled = true
+		appSettings.Save.WriteBack = true
+		router := Router{chi.NewRouter(), appSettings, clustersClient, dbClient}
+
+		return dbClient, clustersClient, clusterClient, router
+	}
+
+	t.Run("should return error for invalid cluster", func(t *testing.T) {
+		dbClient, clustersClient, _, router := newRouter(t)
+		dbClient.EXPECT().GetUserByID(gomock.Any(), "user@kobs.io").Return(&userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test"}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{ID: "user@kobs.io"})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/user", strings.NewReader(`{"id": "user@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveUser(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		utils.AssertJSONEq(t, w, `{"errors": ["Invalid cluster name: test"]}`)
+	})
+
+	t.Run("should return error on conflict", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetUserByID(gomock.Any(), "user@kobs.io").Return(&userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test"}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveUser(gomock.Any(), userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "1"}).Return(nil, fmt.Errorf("%w: [The user was modified]", cluster.ErrConflict))
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{ID: "user@kobs.io"})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/user", strings.NewReader(`{"id": "user@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveUser(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusConflict)
+		utils.AssertJSONEq(t, w, `{"errors": ["The user was modified, reload the user and try again"]}`)
+	})
+
+	t.Run("should return error when write back fails", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetUserByID(gomock.Any(), "user@kobs.io").Return(&userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test"}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveUser(gomock.Any(), userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "1"}).Return(nil, fmt.Errorf("unexpected error"))
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{ID: "user@kobs.io"})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/user", strings.NewReader(`{"id": "user@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveUser(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusInternalServerError)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to write user back to cluster"]}`)
+	})
+
+	t.Run("should write back and save user", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetUserByID(gomock.Any(), "user@kobs.io").Return(&userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test"}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveUser(gomock.Any(), userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "1"}).Return(&userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "2"}, nil)
+		dbClient.EXPECT().SaveUser(gomock.Any(), &userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "2"}).Return(nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{ID: "user@kobs.io"})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/user", strings.NewReader(`{"id": "user@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveUser(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusNoContent)
+		utils.AssertJSONEq(t, w, `null`)
+	})
+
+	t.Run("should return error when user was not found", func(t *testing.T) {
+		dbClient, _, _, router := newRouter(t)
+		dbClient.EXPECT().GetUserByID(gomock.Any(), "user@kobs.io").Return(nil, nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{ID: "user@kobs.io"})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/user", strings.NewReader(`{"id": "user@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "teams": ["team@kobs.io"], "permissions": {"teams": ["*"]}, "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveUser(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusNotFound)
+		utils.AssertJSONEq(t, w, `{"errors": ["User was not found"]}`)
+	})
+
+	t.Run("should return error when cluster, namespace or name is changed", func(t *testing.T) {
+		dbClient, _, _, router := newRouter(t)
+		dbClient.EXPECT().GetUserByID(gomock.Any(), "user@kobs.io").Return(&userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "kobs", Name: "test"}, nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{ID: "user@kobs.io"})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/user", strings.NewReader(`{"id": "user@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "teams": ["team@kobs.io"], "permissions": {"teams": ["*"]}, "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveUser(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		utils.AssertJSONEq(t, w, `{"errors": ["The cluster, namespace and name of the user can not be changed"]}`)
+	})
+
+	t.Run("should keep teams and permissions of the stored user", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetUserByID(gomock.Any(), "user@kobs.io").Return(&userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test", Teams: []string{"team1@kobs.io"}}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveUser(gomock.Any(), userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test", Teams: []string{"team1@kobs.io"}, ResourceVersion: "1"}).Return(&userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "2"}, nil)
+		dbClient.EXPECT().SaveUser(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{ID: "user@kobs.io"})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/user", strings.NewReader(`{"id": "user@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "teams": ["team@kobs.io"], "permissions": {"teams": ["*"]}, "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveUser(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusNoContent)
+	})
+
+	t.Run("should change teams and permissions when user is allowed to edit the user CR", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetUserByID(gomock.Any(), "user@kobs.io").Return(&userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test"}, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveUser(gomock.Any(), userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test", Teams: []string{"team@kobs.io"}, Permissions: userv1.Permissions{Teams: []string{"*"}}, ResourceVersion: "1"}).Return(&userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "2"}, nil)
+		dbClient.EXPECT().SaveUser(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{ID: "user@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"test"}, Namespaces: []string{"test"}, Resources: []string{"users.kobs.io/v1"}, Verbs: []string{"patch"}}}}})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/user", strings.NewReader(`{"id": "user@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "teams": ["team@kobs.io"], "permissions": {"teams": ["*"]}, "resourceVersion": "1"}`))
+		w := httptest.NewRecorder()
+		router.saveUser(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusNoContent)
+	})
+
+	t.Run("should return error when new user already exists", func(t *testing.T) {
+		dbClient, _, _, router := newRouter(t)
+		dbClient.EXPECT().GetUserByID(gomock.Any(), "user@kobs.io").Return(&userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test"}, nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{ID: "user@kobs.io"})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/user", strings.NewReader(`{"id": "user@kobs.io", "cluster": "test", "namespace": "test", "name": "test"}`))
+		w := httptest.NewRecorder()
+		router.saveUser(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusConflict)
+		utils.AssertJSONEq(t, w, `{"errors": ["The user already exists, reload the user and try again"]}`)
+	})
+
+	t.Run("should remove teams and permissions of new user", func(t *testing.T) {
+		dbClient, clustersClient, clusterClient, router := newRouter(t)
+		dbClient.EXPECT().GetUserByID(gomock.Any(), "user@kobs.io").Return(nil, nil)
+		clustersClient.EXPECT().GetCluster("test").Return(clusterClient)
+		clusterClient.EXPECT().SaveUser(gomock.Any(), userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test"}).Return(&userv1.UserSpec{ID: "user@kobs.io", Cluster: "test", Namespace: "test", Name: "test", ResourceVersion: "1"}, nil)
+		dbClient.EXPECT().SaveUser(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, authContext.UserKey, authContext.User{ID: "user@kobs.io"})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/user", strings.NewReader(`{"id": "user@kobs.io", "cluster": "test", "namespace": "test", "name": "test", "teams": ["team@kobs.io"], "permissions": {"teams": ["*"]}}`))
+		w := httptest.NewRecorder()
+		router.saveUser(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusNoContent)
+	})
+}
+
 func TestMount(t *testing.T) {
-	router := Mount(settings.Settings{}, nil)
+	router := Mount(settings.Settings{}, nil, nil)
 	require.NotNil(t, router)
 }
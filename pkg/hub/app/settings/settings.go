@@ -11,7 +11,8 @@ import (
 
 type Settings struct {
 	Save struct {
-		Enabled bool `json:"enabled"`
+		Enabled   bool `json:"enabled"`
+		WriteBack bool `json:"writeBack"`
 	} `json:"save"`
 	DefaultNavigation []userv1.Navigation     `json:"defaultNavigation"`
 	DefaultDashboards []dashboardv1.Reference `json:"defaultDashboards"`
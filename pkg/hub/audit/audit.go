@@ -0,0 +1,318 @@
+package audit
+
+//go:generate mockgen -source=audit.go -destination=./audit_mock.go -package=audit Client
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/instrument/log"
+	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	Enabled bool   `json:"enabled" env:"ENABLED" default:"true" help:"Record all mutating requests through the hub in the audit log."`
+	Webhook string `json:"webhook" env:"WEBHOOK" help:"An optional url, where each audit event is sent to via a POST request."`
+	File    string `json:"file" env:"FILE" help:"An optional path to a file, where each audit event is appended as JSON object."`
+}
+
+// Client is the interface of the audit client. The client provides a middleware to record all mutating requests and a
+// router to get the recorded audit events.
+type Client interface {
+	MiddlewareHandler(next http.Handler) http.Handler
+	Mount() chi.Router
+}
+
+type client struct {
+	config   Config
+	router   *chi.Mux
+	dbClient db.Client
+	sinks    []sink
+	tracer   trace.Tracer
+}
+
+// MiddlewareHandler implements a middleware for the chi router, which records all mutating requests. The middleware
+// must be used after the auth middleware, because the user from the request context is added to the audit event.
+//
+// Requests which are not mutating are passed to the next handler without any changes. For mutating requests we
+// calculate the SHA-256 digest of the request body, while it is read by the next handler and we capture the returned
+// status code. The event is saved after the next handler returned, so that we can also record the result of the
+// request.
+func (c *client) MiddlewareHandler(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if !c.config.Enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		verb, ok := getVerb(r.Method, r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		user := authContext.MustGetUser(ctx)
+		timestamp := time.Now().UnixMilli()
+
+		digest := sha256.New()
+		if r.Body != nil {
+			r.Body = &digestReader{ReadCloser: r.Body, reader: io.TeeReader(r.Body, digest)}
+		}
+
+		statusCode := http.StatusOK
+		wroteHeader := false
+
+		next.ServeHTTP(httpsnoop.Wrap(w, httpsnoop.Hooks{
+			WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+				return func(code int) {
+					if !wroteHeader {
+						statusCode = code
+						wroteHeader = true
+					}
+					next(code)
+				}
+			},
+			Hijack: func(next httpsnoop.HijackFunc) httpsnoop.HijackFunc {
+				return func() (net.Conn, *bufio.ReadWriter, error) {
+					if !wroteHeader {
+						statusCode = http.StatusSwitchingProtocols
+						wroteHeader = true
+					}
+					return next()
+				}
+			},
+		}), r)
+
+		event := newEvent(r, user, verb, timestamp, hex.EncodeToString(digest.Sum(nil)), statusCode)
+		c.record(context.WithoutCancel(ctx), event)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// record saves the provided audit event in the database and writes it to all configured sinks. Errors are only logged,
+// because the request was already handled and we can not return an error to the user anymore.
+func (c *client) record(ctx context.Context, event db.AuditEvent) {
+	ctx, span := c.tracer.Start(ctx, "audit.record")
+	span.SetAttributes(attribute.Key("id").String(event.ID))
+	span.SetAttributes(attribute.Key("user").String(event.User))
+	span.SetAttributes(attribute.Key("verb").String(event.Verb))
+	defer span.End()
+
+	log.Info(ctx, "Audit event", zap.String("id", event.ID), zap.String("user", event.User), zap.String("verb", event.Verb), zap.String("cluster", event.Cluster), zap.String("namespace", event.Namespace), zap.String("resource", event.Resource), zap.String("name", event.Name), zap.String("plugin", event.Plugin), zap.Int("statusCode", event.StatusCode))
+
+	if err := c.dbClient.SaveAuditEvent(ctx, &event); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error(ctx, "Failed to save audit event", zap.Error(err), zap.String("id", event.ID))
+	}
+
+	for _, s := range c.sinks {
+		if err := s.write(ctx, event); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error(ctx, "Failed to write audit event", zap.Error(err), zap.String("id", event.ID), zap.String("sink", s.name()))
+		}
+	}
+}
+
+// getEvents returns the recorded audit events. Users which are allowed to access the `audit` resource in all clusters
+// and namespaces can see the events of all users. All other users can only see their own events.
+func (c *client) getEvents(w http.ResponseWriter, r *http.Request) {
+	ctx, span := c.tracer.Start(r.Context(), "getEvents")
+	defer span.End()
+
+	user := authContext.MustGetUser(ctx)
+	userFilter := r.URL.Query().Get("user")
+	cluster := r.URL.Query().Get("cluster")
+	namespace := r.URL.Query().Get("namespace")
+	verb := r.URL.Query().Get("verb")
+	timeStart := r.URL.Query().Get("timeStart")
+	timeEnd := r.URL.Query().Get("timeEnd")
+	limit := r.URL.Query().Get("limit")
+	offset := r.URL.Query().Get("offset")
+
+	span.SetAttributes(attribute.Key("user").String(userFilter))
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	span.SetAttributes(attribute.Key("namespace").String(namespace))
+	span.SetAttributes(attribute.Key("verb").String(verb))
+	span.SetAttributes(attribute.Key("timeStart").String(timeStart))
+	span.SetAttributes(attribute.Key("timeEnd").String(timeEnd))
+	span.SetAttributes(attribute.Key("limit").String(limit))
+	span.SetAttributes(attribute.Key("offset").String(offset))
+
+	if !user.HasResourceAccess("*", "*", "audit", "get") {
+		if userFilter != "" && userFilter != user.ID {
+			log.Warn(ctx, "The user is not authorized to view the audit events of other users")
+			span.RecordError(fmt.Errorf("user is not authorized to view the audit events of other users"))
+			span.SetStatus(codes.Error, "user is not authorized to view the audit events of other users")
+			errresponse.Render(w, r, http.StatusForbidden, "You are not allowed to view the audit events of other users")
+			return
+		}
+
+		userFilter = user.ID
+	}
+
+	parsedTimeStart, err := parseInt(timeStart, 0)
+	if err != nil {
+		log.Error(ctx, "Failed to parse 'timeStart' parameter", zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		errresponse.Render(w, r, http.StatusBadRequest, "Failed to parse 'timeStart' parameter")
+		return
+	}
+
+	parsedTimeEnd, err := parseInt(timeEnd, 0)
+	if err != nil {
+		log.Error(ctx, "Failed to parse 'timeEnd' parameter", zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		errresponse.Render(w, r, http.StatusBadRequest, "Failed to parse 'timeEnd' parameter")
+		return
+	}
+
+	parsedLimit, err := parseInt(limit, 100)
+	if err != nil {
+		log.Error(ctx, "Failed to parse 'limit' parameter", zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		errresponse.Render(w, r, http.StatusBadRequest, "Failed to parse 'limit' parameter")
+		return
+	}
+
+	parsedOffset, err := parseInt(offset, 0)
+	if err != nil {
+		log.Error(ctx, "Failed to parse 'offset' parameter", zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		errresponse.Render(w, r, http.StatusBadRequest, "Failed to parse 'offset' parameter")
+		return
+	}
+
+	// The start and end time are provided in seconds, like for all other API endpoints, but the timestamp of an audit
+	// event is saved in milliseconds.
+	events, err := c.dbClient.GetAuditEvents(ctx, userFilter, cluster, namespace, verb, parsedTimeStart*1000, parsedTimeEnd*1000, int(parsedLimit), int(parsedOffset))
+	if err != nil {
+		log.Error(ctx, "Failed to get audit events", zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get audit events")
+		return
+	}
+
+	render.JSON(w, r, events)
+}
+
+// Mount returns the router of the audit client, so it can be mounted into an existing chi router.
+func (c *client) Mount() chi.Router {
+	return c.router
+}
+
+// NewClient returns a new audit client. Besides the database, where all audit events are saved, the user can configure
+// a webhook and a file as additional sinks for the audit events.
+func NewClient(config Config, dbClient db.Client) (Client, error) {
+	var sinks []sink
+
+	if config.Webhook != "" {
+		sinks = append(sinks, newWebhookSink(config.Webhook))
+	}
+
+	if config.File != "" {
+		fileSink, err := newFileSink(config.File)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, fileSink)
+	}
+
+	c := &client{
+		config:   config,
+		router:   chi.NewRouter(),
+		dbClient: dbClient,
+		sinks:    sinks,
+		tracer:   otel.Tracer("audit"),
+	}
+
+	c.router.Get("/", c.getEvents)
+
+	return c, nil
+}
+
+// newEvent creates a new audit event for the provided request. The cluster, namespace, resource and name are taken from
+// the headers and query parameters, which are used by the resources API and the plugins. If the request was made to a
+// plugin, the plugin type is also added to the event.
+func newEvent(r *http.Request, user *authContext.User, verb string, timestamp int64, bodyDigest string, statusCode int) db.AuditEvent {
+	cluster := r.Header.Get("x-kobs-cluster")
+	if cluster == "" {
+		cluster = r.URL.Query().Get("x-kobs-cluster")
+	}
+
+	result := "success"
+	if statusCode >= http.StatusBadRequest {
+		result = "failure"
+	}
+
+	return db.AuditEvent{
+		ID:         newID(),
+		Timestamp:  timestamp,
+		User:       user.ID,
+		Cluster:    cluster,
+		Namespace:  r.URL.Query().Get("namespace"),
+		Resource:   r.URL.Query().Get("resource"),
+		Name:       r.URL.Query().Get("name"),
+		Plugin:     getPlugin(r.URL.Path),
+		Verb:       verb,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		BodyDigest: bodyDigest,
+		StatusCode: statusCode,
+		Result:     result,
+	}
+}
+
+// newID returns a new random id for an audit event.
+func newID() string {
+	id := make([]byte, 12)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// parseInt parses the provided value as integer. If the value is empty the provided default value is returned.
+func parseInt(value string, defaultValue int64) (int64, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// digestReader wraps the body of a request, so that all data which is read from the body is also written to the digest.
+type digestReader struct {
+	io.ReadCloser
+	reader io.Reader
+}
+
+func (r *digestReader) Read(p []byte) (int, error) {
+	return r.reader.Read(p)
+}
@@ -0,0 +1,252 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestMiddlewareHandler(t *testing.T) {
+	var newClient = func(t *testing.T, enabled bool) (*db.MockClient, *client) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+
+		return dbClient, &client{config: Config{Enabled: enabled}, router: chi.NewRouter(), dbClient: dbClient, tracer: otel.Tracer("audit")}
+	}
+
+	var newRequest = func(method, target, body string) *http.Request {
+		ctx := context.WithValue(context.Background(), authContext.UserKey, authContext.User{ID: "user@kobs.io"})
+		req, _ := http.NewRequestWithContext(ctx, method, target, strings.NewReader(body))
+		req.Header.Set("x-kobs-cluster", "cluster1")
+		return req
+	}
+
+	var handler = func(status int) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.ReadAll(r.Body)
+			w.WriteHeader(status)
+		})
+	}
+
+	t.Run("should not record event when audit log is disabled", func(t *testing.T) {
+		_, c := newClient(t, false)
+
+		w := httptest.NewRecorder()
+		c.MiddlewareHandler(handler(http.StatusOK)).ServeHTTP(w, newRequest(http.MethodDelete, "/api/resources?namespace=default&resource=pods&name=pod1", ""))
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+	})
+
+	t.Run("should not record event for request which is not mutating", func(t *testing.T) {
+		_, c := newClient(t, true)
+
+		w := httptest.NewRecorder()
+		c.MiddlewareHandler(handler(http.StatusOK)).ServeHTTP(w, newRequest(http.MethodGet, "/api/resources?namespace=default&resource=pods&name=pod1", ""))
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+	})
+
+	t.Run("should record event for successful request", func(t *testing.T) {
+		dbClient, c := newClient(t, true)
+		dbClient.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event *db.AuditEvent) error {
+			require.NotEmpty(t, event.ID)
+			require.NotEmpty(t, event.Timestamp)
+			require.Equal(t, "user@kobs.io", event.User)
+			require.Equal(t, "cluster1", event.Cluster)
+			require.Equal(t, "default", event.Namespace)
+			require.Equal(t, "deployments", event.Resource)
+			require.Equal(t, "deployment1", event.Name)
+			require.Equal(t, "", event.Plugin)
+			require.Equal(t, "patch", event.Verb)
+			require.Equal(t, http.MethodPut, event.Method)
+			require.Equal(t, "/api/resources", event.Path)
+			require.Equal(t, "a7cd6c222ea5fc1463c0ca3f70b93035196c8c4f34d89181ff5086bd7b58bfff", event.BodyDigest)
+			require.Equal(t, http.StatusOK, event.StatusCode)
+			require.Equal(t, "success", event.Result)
+			return nil
+		})
+
+		w := httptest.NewRecorder()
+		c.MiddlewareHandler(handler(http.StatusOK)).ServeHTTP(w, newRequest(http.MethodPut, "/api/resources?namespace=default&resource=deployments&name=deployment1", `{"test": "test"}`))
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+	})
+
+	t.Run("should record event for failed plugin request", func(t *testing.T) {
+		dbClient, c := newClient(t, true)
+		dbClient.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event *db.AuditEvent) error {
+			require.Equal(t, "flux", event.Plugin)
+			require.Equal(t, "sync", event.Verb)
+			require.Equal(t, http.StatusBadRequest, event.StatusCode)
+			require.Equal(t, "failure", event.Result)
+			return nil
+		})
+
+		w := httptest.NewRecorder()
+		c.MiddlewareHandler(handler(http.StatusBadRequest)).ServeHTTP(w, newRequest(http.MethodGet, "/api/plugins/flux/sync?namespace=default&resource=kustomizations&name=test", ""))
+
+		utils.AssertStatusEq(t, w, http.StatusBadRequest)
+	})
+
+	t.Run("should not fail request when event could not be saved", func(t *testing.T) {
+		dbClient, c := newClient(t, true)
+		dbClient.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).Return(fmt.Errorf("unexpected error"))
+
+		w := httptest.NewRecorder()
+		c.MiddlewareHandler(handler(http.StatusOK)).ServeHTTP(w, newRequest(http.MethodDelete, "/api/resources?namespace=default&resource=pods&name=pod1", ""))
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+	})
+
+	t.Run("should write event to sinks", func(t *testing.T) {
+		file := path.Join(t.TempDir(), "audit.log")
+		fileSink, err := newFileSink(file)
+		require.NoError(t, err)
+
+		dbClient, c := newClient(t, true)
+		c.sinks = []sink{fileSink}
+		dbClient.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).Return(nil)
+
+		w := httptest.NewRecorder()
+		c.MiddlewareHandler(handler(http.StatusOK)).ServeHTTP(w, newRequest(http.MethodDelete, "/api/resources?namespace=default&resource=pods&name=pod1", ""))
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.Contains(t, string(content), `"user":"user@kobs.io"`)
+		require.Contains(t, string(content), `"verb":"delete"`)
+	})
+}
+
+func TestGetEvents(t *testing.T) {
+	var newClient = func(t *testing.T) (*db.MockClient, *client) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+
+		return dbClient, &client{config: Config{Enabled: true}, router: chi.NewRouter(), dbClient: dbClient, tracer: otel.Tracer("audit")}
+	}
+
+	adminUser := authContext.User{ID: "admin@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"*"}, Namespaces: []string{"*"}, Resources: []string{"audit"}, Verbs: []string{"get"}}}}}
+
+	for _, tt := range []struct {
+		name               string
+		user               authContext.User
+		url                string
+		prepare            func(t *testing.T, dbClient *db.MockClient)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "should fail when user is not allowed to view events of other users",
+			user:               authContext.User{ID: "user@kobs.io"},
+			url:                "/?user=admin@kobs.io",
+			expectedStatusCode: http.StatusForbidden,
+			expectedBody:       `{"errors": ["You are not allowed to view the audit events of other users"]}`,
+		},
+		{
+			name:               "should fail for invalid time start",
+			user:               adminUser,
+			url:                "/?timeStart=abc",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors": ["Failed to parse 'timeStart' parameter"]}`,
+		},
+		{
+			name:               "should fail for invalid time end",
+			user:               adminUser,
+			url:                "/?timeEnd=abc",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors": ["Failed to parse 'timeEnd' parameter"]}`,
+		},
+		{
+			name:               "should fail for invalid limit",
+			user:               adminUser,
+			url:                "/?limit=abc",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors": ["Failed to parse 'limit' parameter"]}`,
+		},
+		{
+			name:               "should fail for invalid offset",
+			user:               adminUser,
+			url:                "/?offset=abc",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors": ["Failed to parse 'offset' parameter"]}`,
+		},
+		{
+			name: "should fail when events could not be returned",
+			user: adminUser,
+			url:  "/",
+			prepare: func(t *testing.T, dbClient *db.MockClient) {
+				dbClient.EXPECT().GetAuditEvents(gomock.Any(), "", "", "", "", int64(0), int64(0), 100, 0).Return(nil, fmt.Errorf("unexpected error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `{"errors": ["Failed to get audit events"]}`,
+		},
+		{
+			name: "should return own events",
+			user: authContext.User{ID: "user@kobs.io"},
+			url:  "/?verb=delete",
+			prepare: func(t *testing.T, dbClient *db.MockClient) {
+				dbClient.EXPECT().GetAuditEvents(gomock.Any(), "user@kobs.io", "", "", "delete", int64(0), int64(0), 100, 0).Return([]db.AuditEvent{{ID: "event1"}}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `[{"id": "event1", "timestamp": 0, "user": "", "cluster": "", "namespace": "", "resource": "", "name": "", "plugin": "", "verb": "", "method": "", "path": "", "bodyDigest": "", "statusCode": 0, "result": ""}]`,
+		},
+		{
+			name: "should return events of all users",
+			user: adminUser,
+			url:  "/?user=user@kobs.io&cluster=cluster1&namespace=default&timeStart=1&timeEnd=2&limit=10&offset=20",
+			prepare: func(t *testing.T, dbClient *db.MockClient) {
+				dbClient.EXPECT().GetAuditEvents(gomock.Any(), "user@kobs.io", "cluster1", "default", "", int64(1000), int64(2000), 10, 20).Return(nil, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `null`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dbClient, c := newClient(t)
+			if tt.prepare != nil {
+				tt.prepare(t, dbClient)
+			}
+
+			ctx := context.WithValue(context.Background(), authContext.UserKey, tt.user)
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, tt.url, nil)
+			w := httptest.NewRecorder()
+			c.getEvents(w, req)
+
+			utils.AssertStatusEq(t, w, tt.expectedStatusCode)
+			utils.AssertJSONEq(t, w, tt.expectedBody)
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	t.Run("should return client", func(t *testing.T) {
+		c, err := NewClient(Config{Enabled: true, Webhook: "http://localhost", File: path.Join(t.TempDir(), "audit.log")}, nil)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		require.NotNil(t, c.Mount())
+	})
+
+	t.Run("should fail for invalid file", func(t *testing.T) {
+		c, err := NewClient(Config{Enabled: true, File: path.Join(t.TempDir(), "invalid", "audit.log")}, nil)
+		require.Error(t, err)
+		require.Nil(t, c)
+	})
+}
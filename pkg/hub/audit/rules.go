@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"net/http"
+	"strings"
+)
+
+// rule defines a mutating request, which should be recorded in the audit log. A request matches a rule when the method
+// and the path are equal to the method and path of the rule. The verb of the rule is saved in the audit event.
+type rule struct {
+	method string
+	path   string
+	verb   string
+}
+
+// rules is the list of all mutating requests through the hub. We can not record all requests which are not using the
+// GET method, because some plugins are using POST requests to run queries and some mutating requests, like syncing a
+// Flux resource or closing an Opsgenie alert are using the GET method.
+var rules = []rule{
+	{method: http.MethodPost, path: "/api/resources", verb: "create"},
+	{method: http.MethodPut, path: "/api/resources", verb: "patch"},
+	{method: http.MethodDelete, path: "/api/resources", verb: "delete"},
+	{method: http.MethodGet, path: "/api/resources/terminal", verb: "exec"},
+	{method: http.MethodGet, path: "/api/resources/terminal/debug", verb: "debug"},
+	{method: http.MethodGet, path: "/api/resources/portforward", verb: "portforward"},
+	{method: http.MethodPost, path: "/api/resources/file", verb: "copy"},
+	{method: http.MethodPost, path: "/api/resources/file/archive", verb: "copy"},
+	{method: http.MethodPost, path: "/api/clusters", verb: "register"},
+	{method: http.MethodDelete, path: "/api/clusters", verb: "unregister"},
+	{method: http.MethodPost, path: "/api/applications/application", verb: "save"},
+	{method: http.MethodPost, path: "/api/teams/team", verb: "save"},
+	{method: http.MethodPost, path: "/api/users/user", verb: "save"},
+	{method: http.MethodGet, path: "/api/plugins/flux/sync", verb: "sync"},
+	{method: http.MethodPost, path: "/api/plugins/velero/backup", verb: "backup"},
+	{method: http.MethodGet, path: "/api/plugins/opsgenie/alert/acknowledge", verb: "acknowledge"},
+	{method: http.MethodGet, path: "/api/plugins/opsgenie/alert/snooze", verb: "snooze"},
+	{method: http.MethodGet, path: "/api/plugins/opsgenie/alert/close", verb: "close"},
+	{method: http.MethodGet, path: "/api/plugins/opsgenie/incident/resolve", verb: "resolve"},
+	{method: http.MethodGet, path: "/api/plugins/opsgenie/incident/close", verb: "close"},
+	{method: http.MethodPost, path: "/api/plugins/mongodb/collections/findoneandupdate", verb: "update"},
+	{method: http.MethodPost, path: "/api/plugins/mongodb/collections/findoneanddelete", verb: "delete"},
+	{method: http.MethodPost, path: "/api/plugins/mongodb/collections/updatemany", verb: "update"},
+	{method: http.MethodPost, path: "/api/plugins/mongodb/collections/deletemany", verb: "delete"},
+}
+
+// getVerb returns the verb for the provided request method and path. If the request isn't mutating, the second return
+// value is false.
+func getVerb(method, path string) (string, bool) {
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+
+	for _, r := range rules {
+		if r.method == method && r.path == path {
+			return r.verb, true
+		}
+	}
+
+	return "", false
+}
+
+// getPlugin returns the type of the plugin for the provided path. If the path doesn't belong to a plugin an empty
+// string is returned.
+func getPlugin(path string) string {
+	if !strings.HasPrefix(path, "/api/plugins/") {
+		return ""
+	}
+
+	return strings.Split(strings.TrimPrefix(path, "/api/plugins/"), "/")[0]
+}
@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetVerb(t *testing.T) {
+	for _, tt := range []struct {
+		method       string
+		path         string
+		expectedVerb string
+		expectedOk   bool
+	}{
+		{method: http.MethodGet, path: "/api/resources", expectedVerb: "", expectedOk: false},
+		{method: http.MethodDelete, path: "/api/resources", expectedVerb: "delete", expectedOk: true},
+		{method: http.MethodDelete, path: "/api/resources/", expectedVerb: "delete", expectedOk: true},
+		{method: http.MethodGet, path: "/api/resources/terminal", expectedVerb: "exec", expectedOk: true},
+		{method: http.MethodGet, path: "/api/resources/terminal/debug", expectedVerb: "debug", expectedOk: true},
+		{method: http.MethodGet, path: "/api/resources/portforward", expectedVerb: "portforward", expectedOk: true},
+		{method: http.MethodGet, path: "/api/resources/file", expectedVerb: "", expectedOk: false},
+		{method: http.MethodPost, path: "/api/resources/file", expectedVerb: "copy", expectedOk: true},
+		{method: http.MethodPost, path: "/api/resources/file/archive", expectedVerb: "copy", expectedOk: true},
+		{method: http.MethodGet, path: "/api/clusters", expectedVerb: "", expectedOk: false},
+		{method: http.MethodPost, path: "/api/clusters", expectedVerb: "register", expectedOk: true},
+		{method: http.MethodDelete, path: "/api/clusters/", expectedVerb: "unregister", expectedOk: true},
+		{method: http.MethodPost, path: "/api/plugins/prometheus/range", expectedVerb: "", expectedOk: false},
+		{method: http.MethodGet, path: "/api/plugins/opsgenie/alert/acknowledge", expectedVerb: "acknowledge", expectedOk: true},
+		{method: http.MethodGet, path: "/api/plugins/opsgenie/alert/snooze", expectedVerb: "snooze", expectedOk: true},
+		{method: http.MethodGet, path: "/api/plugins/opsgenie/alert/close", expectedVerb: "close", expectedOk: true},
+		{method: http.MethodGet, path: "/api/plugins/opsgenie/alert/notes", expectedVerb: "", expectedOk: false},
+		{method: http.MethodGet, path: "/api/plugins/opsgenie/incident/resolve", expectedVerb: "resolve", expectedOk: true},
+		{method: http.MethodGet, path: "/api/plugins/opsgenie/incident/close", expectedVerb: "close", expectedOk: true},
+		{method: http.MethodPost, path: "/api/plugins/mongodb/collections/deletemany", expectedVerb: "delete", expectedOk: true},
+	} {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			verb, ok := getVerb(tt.method, tt.path)
+			require.Equal(t, tt.expectedVerb, verb)
+			require.Equal(t, tt.expectedOk, ok)
+		})
+	}
+}
+
+func TestGetPlugin(t *testing.T) {
+	require.Equal(t, "", getPlugin("/api/resources"))
+	require.Equal(t, "flux", getPlugin("/api/plugins/flux/sync"))
+	require.Equal(t, "velero", getPlugin("/api/plugins/velero"))
+}
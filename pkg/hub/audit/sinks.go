@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/instrument/log"
+
+	"go.uber.org/zap"
+)
+
+// sink is the interface which must be implemented by all additional destinations for the audit events.
+type sink interface {
+	name() string
+	write(ctx context.Context, event db.AuditEvent) error
+}
+
+// webhookSink sends each audit event as JSON object to the configured url.
+type webhookSink struct {
+	url        string
+	httpClient *http.Client
+}
+
+func (s *webhookSink) name() string {
+	return "webhook"
+}
+
+// write sends the audit event to the webhook in a new goroutine, so that a slow webhook doesn't block the request of
+// the user. Errors are logged in the goroutine, so that write always returns nil.
+func (s *webhookSink) write(ctx context.Context, event db.AuditEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.send(ctx, body); err != nil {
+			log.Error(ctx, "Failed to send audit event to webhook", zap.Error(err), zap.String("id", event.ID))
+		}
+	}()
+
+	return nil
+}
+
+func (s *webhookSink) send(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+func newWebhookSink(url string) sink {
+	return &webhookSink{
+		url:        url,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// fileSink appends each audit event as a single line JSON object to the configured file.
+type fileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (s *fileSink) name() string {
+	return "file"
+}
+
+func (s *fileSink) write(ctx context.Context, event db.AuditEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+func newFileSink(path string) (sink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileSink{file: file}, nil
+}
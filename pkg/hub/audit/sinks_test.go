@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/kobsio/kobs/pkg/hub/db"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSink(t *testing.T) {
+	t.Run("should send event", func(t *testing.T) {
+		events := make(chan db.AuditEvent, 1)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var event db.AuditEvent
+			json.NewDecoder(r.Body).Decode(&event)
+			events <- event
+		}))
+		defer ts.Close()
+
+		s := newWebhookSink(ts.URL)
+		require.Equal(t, "webhook", s.name())
+
+		err := s.write(context.Background(), db.AuditEvent{ID: "event1"})
+		require.NoError(t, err)
+		require.Equal(t, "event1", (<-events).ID)
+	})
+
+	t.Run("should fail for unexpected status code", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer ts.Close()
+
+		err := newWebhookSink(ts.URL).(*webhookSink).send(context.Background(), []byte(`{}`))
+		require.Error(t, err)
+	})
+
+	t.Run("should fail for invalid url", func(t *testing.T) {
+		err := newWebhookSink(" http://localhost").(*webhookSink).send(context.Background(), []byte(`{}`))
+		require.Error(t, err)
+	})
+}
+
+func TestFileSink(t *testing.T) {
+	file := path.Join(t.TempDir(), "audit.log")
+
+	s, err := newFileSink(file)
+	require.NoError(t, err)
+	require.Equal(t, "file", s.name())
+
+	require.NoError(t, s.write(context.Background(), db.AuditEvent{ID: "event1"}))
+	require.NoError(t, s.write(context.Background(), db.AuditEvent{ID: "event2"}))
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.Equal(t, `{"id":"event1","timestamp":0,"user":"","cluster":"","namespace":"","resource":"","name":"","plugin":"","verb":"","method":"","path":"","bodyDigest":"","statusCode":0,"result":""}
+{"id":"event2","timestamp":0,"user":"","cluster":"","namespace":"","resource":"","name":"","plugin":"","verb":"","method":"","path":"","bodyDigest":"","statusCode":0,"result":""}
+`, string(content))
+}
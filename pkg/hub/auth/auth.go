@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
@@ -32,21 +33,41 @@ type Client interface {
 }
 
 type client struct {
-	config       Config
-	appSettings  settings.Settings
-	router       *chi.Mux
-	dbClient     db.Client
-	oidcConfig   *oauth2.Config
-	oidcProvider *oidc.Provider
+	config         Config
+	appSettings    settings.Settings
+	router         *chi.Mux
+	dbClient       db.Client
+	oidcConfig     *oauth2.Config
+	oidcProvider   *oidc.Provider
+	oidcMappings   []oidcMapping
+	limiter        *signinLimiter
+	trustedProxies []*net.IPNet
 }
 
 // MiddlewareHandler implements a middleware for the chi router, to check if the user is authorized to access kobs. If
 // we coud not get a user from the request the middleware returns an unauthorized error and the user have to redo the
 // authentication process.
+//
+// Besides the "kobs.token" cookie, which is set when a user signs in via the React app, the middleware also accepts a
+// personal API token via the "Authorization" header, so that the API can also be used by scripts and CI jobs. All other
+// "Authorization" headers are ignored, because they might be set by a proxy in front of kobs.
 func (c *client) MiddlewareHandler(next http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
+		if bearer, ok := getAPIToken(r); ok {
+			apiToken, err := c.validateAPIToken(ctx, bearer)
+			if err != nil {
+				log.Warn(ctx, "Failed to validate api token", zap.Error(err))
+				errresponse.Render(w, r, http.StatusUnauthorized, "Failed to validate api token")
+				return
+			}
+
+			ctx = context.WithValue(ctx, authContext.UserKey, apiToken.User)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
 		token, err := r.Cookie("kobs.token")
 		if err != nil {
 			log.Warn(ctx, "Failed to get token from cookie", zap.Error(err))
@@ -129,13 +150,25 @@ func (c *client) authHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // signinHandler is the request handler for handling the sign in of a user. To sign in a user we have to check if that
-// the user has a User CR. If this is the case we check if the provided password matches the password from the CR.
-// Finally we create a new user for the auth context and a new session / token, which is saved in a cookie and can be
-// used in the following requests to validate the user.
+// the user is a local user from the hub configuration or has a User CR. If this is the case we check if the provided
+// password matches the password of the user. Finally we create a new user for the auth context and a new session /
+// token, which is saved in a cookie and can be used in the following requests to validate the user.
+//
+// To protect the local users against brute force attacks, the number of sign in requests per ip address is limited
+// and a user is locked for an ip address after too many failed sign in attempts from this ip address. The lockout is
+// done per ip address, so that an attacker can not lock out other users (e.g. a break-glass account).
 func (c *client) signinHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	var signinRequestData signinRequest
 
+	ip := getClientIP(r, c.trustedProxies)
+
+	if !c.limiter.allow(ip) {
+		log.Warn(ctx, "Too many sign in requests", zap.String("ip", ip))
+		errresponse.Render(w, r, http.StatusTooManyRequests, "Too many sign in requests, try again later")
+		return
+	}
+
 	err := json.NewDecoder(r.Body).Decode(&signinRequestData)
 	if err != nil {
 		log.Warn(ctx, "Failed to decode request body", zap.Error(err))
@@ -143,19 +176,35 @@ func (c *client) signinHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := c.dbClient.GetUserByID(ctx, signinRequestData.Username)
+	if c.limiter.isLocked(ip, signinRequestData.Username) {
+		log.Warn(ctx, "User is locked because of too many failed sign in attempts", zap.String("user", signinRequestData.Username), zap.String("ip", ip))
+		errresponse.Render(w, r, http.StatusTooManyRequests, "Too many failed sign in attempts, try again later")
+		return
+	}
+
+	user, err := c.getStaticUser(signinRequestData.Username)
 	if err != nil {
-		log.Warn(ctx, "Failed to get user from database", zap.Error(err))
-		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get user from database")
+		log.Warn(ctx, "Failed to get local user", zap.Error(err))
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get local user")
 		return
 	}
 
+	if user == nil {
+		user, err = c.dbClient.GetUserByID(ctx, signinRequestData.Username)
+		if err != nil {
+			log.Warn(ctx, "Failed to get user from database", zap.Error(err))
+			errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get user from database")
+			return
+		}
+	}
+
 	if user == nil {
 		// When no user is found for the provided email address, we use a fixed password hash to prevent user
 		// enumeration by timing requests. Here we are comparing the bcrypt-hashed version of "fakepassword" against
 		// the user provided password.
 		bcrypt.CompareHashAndPassword([]byte("$2y$10$UPPBv.HThEllgJZINbFwYOsru62d.LT0EqG3XLug2pG81IvemopH2"), []byte(signinRequestData.Password))
 
+		c.limiter.fail(ip, signinRequestData.Username)
 		log.Warn(ctx, "Invalid username or password")
 		errresponse.Render(w, r, http.StatusBadRequest, "Invalid username or password")
 		return
@@ -163,11 +212,14 @@ func (c *client) signinHandler(w http.ResponseWriter, r *http.Request) {
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(signinRequestData.Password))
 	if err != nil {
+		c.limiter.fail(ip, signinRequestData.Username)
 		log.Warn(ctx, "Invalid username or password", zap.Error(err))
 		errresponse.Render(w, r, http.StatusBadRequest, "Invalid username or password")
 		return
 	}
 
+	c.limiter.succeed(ip, signinRequestData.Username)
+
 	authContextUser := authContext.User{
 		ID:          user.ID,
 		Name:        user.DisplayName,
@@ -191,7 +243,7 @@ func (c *client) signinHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	session, err := c.dbClient.CreateSession(ctx, authContextUser)
+	session, err := c.dbClient.CreateSession(ctx, authContextUser, newSessionMetadata(r, c.config.Session.Duration.Duration, c.trustedProxies))
 	if err != nil {
 		log.Warn(ctx, "Failed to create session", zap.Error(err))
 		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to create session")
@@ -342,12 +394,24 @@ func (c *client) oidcCallbackHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var rawClaims map[string]any
+	if err := idToken.Claims(&rawClaims); err != nil {
+		log.Warn(ctx, "Failed to get claims", zap.Error(err))
+		errresponse.Render(w, r, http.StatusBadRequest, "Failed to get claims")
+		return
+	}
+
 	authContextUser := &authContext.User{
 		ID:    claims.Email,
 		Name:  claims.Name,
 		Teams: claims.Groups,
 	}
 
+	// Apply the configured OIDC mappings, so that the teams and permissions of all matching mappings are added to the
+	// user. This must be done before we get the teams from the database, so that a user also gets the permissions of
+	// the teams which were added via a mapping.
+	applyOIDCMappings(authContextUser, c.oidcMappings, rawClaims)
+
 	user, err := c.dbClient.GetUserByID(ctx, authContextUser.ID)
 	if err != nil {
 		log.Warn(ctx, "Failed to get user from database", zap.Error(err))
@@ -378,7 +442,7 @@ func (c *client) oidcCallbackHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	session, err := c.dbClient.CreateSession(ctx, *authContextUser)
+	session, err := c.dbClient.CreateSession(ctx, *authContextUser, newSessionMetadata(r, c.config.Session.Duration.Duration, c.trustedProxies))
 	if err != nil {
 		log.Warn(ctx, "Failed to create session", zap.Error(err))
 		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to create session")
@@ -423,6 +487,16 @@ func NewClient(config Config, appSettings settings.Settings, dbClient db.Client)
 	var oidcConfig *oauth2.Config
 	var oidcProvider *oidc.Provider
 
+	oidcMappings, err := newOIDCMappings(config.OIDC.Mappings)
+	if err != nil {
+		return nil, err
+	}
+
+	trustedProxies, err := parseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
 	if config.OIDC.Enabled {
 		provider, err := oidc.NewProvider(context.Background(), config.OIDC.Issuer)
 		if err != nil {
@@ -440,12 +514,15 @@ func NewClient(config Config, appSettings settings.Settings, dbClient db.Client)
 	}
 
 	c := &client{
-		config:       config,
-		appSettings:  appSettings,
-		router:       chi.NewRouter(),
-		oidcConfig:   oidcConfig,
-		oidcProvider: oidcProvider,
-		dbClient:     dbClient,
+		config:         config,
+		appSettings:    appSettings,
+		router:         chi.NewRouter(),
+		oidcConfig:     oidcConfig,
+		oidcProvider:   oidcProvider,
+		oidcMappings:   oidcMappings,
+		limiter:        newSigninLimiter(config.Signin),
+		trustedProxies: trustedProxies,
+		dbClient:       dbClient,
 	}
 
 	c.router.Get("/", c.authHandler)
@@ -454,5 +531,23 @@ func NewClient(config Config, appSettings settings.Settings, dbClient db.Client)
 	c.router.Get("/oidc", c.oidcHandler)
 	c.router.Get("/oidc/callback", c.oidcCallbackHandler)
 
+	c.router.Route("/tokens", func(r chi.Router) {
+		r.Use(c.MiddlewareHandler)
+		r.Get("/", c.getTokensHandler)
+		r.Post("/", c.createTokenHandler)
+		r.Delete("/", c.deleteTokenHandler)
+	})
+
+	c.router.Route("/sessions", func(r chi.Router) {
+		r.Use(c.MiddlewareHandler)
+		r.Get("/", c.getSessionsHandler)
+		r.Delete("/", c.deleteSessionsHandler)
+	})
+
+	c.router.Route("/can-i", func(r chi.Router) {
+		r.Use(c.MiddlewareHandler)
+		r.Get("/", c.canIHandler)
+	})
+
 	return c, nil
 }
@@ -27,7 +27,7 @@ import (
 
 func TestAuthMiddleware(t *testing.T) {
 	t.Run("should succeed when cookie contains valid session", func(t *testing.T) {
-		sessionID := primitive.NewObjectID()
+		sessionID := primitive.NewObjectID().Hex()
 		session := &db.Session{
 			ID: sessionID,
 			User: authContext.User{
@@ -101,7 +101,7 @@ func TestAuthMiddleware(t *testing.T) {
 		ctx := context.Background()
 		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
 
-		token, err := jwt.CreateToken(&Token{SessionID: primitive.NewObjectID()}, client.config.Session.Token+"to make signature invalid", time.Hour)
+		token, err := jwt.CreateToken(&Token{SessionID: primitive.NewObjectID().Hex()}, client.config.Session.Token+"to make signature invalid", time.Hour)
 		require.NoError(t, err)
 
 		req.AddCookie(
@@ -121,7 +121,7 @@ func TestAuthMiddleware(t *testing.T) {
 	})
 
 	t.Run("should fail when session can not be found in database", func(t *testing.T) {
-		sessionID := primitive.NewObjectID()
+		sessionID := primitive.NewObjectID().Hex()
 
 		ctrl := gomock.NewController(t)
 		dbClient := db.NewMockClient(ctrl)
@@ -182,7 +182,7 @@ func TestAuthHandler(t *testing.T) {
 		ctx := context.Background()
 		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
 
-		token, err := jwt.CreateToken(&Token{SessionID: primitive.NewObjectID()}, client.config.Session.Token+"to make signature invalid", time.Hour)
+		token, err := jwt.CreateToken(&Token{SessionID: primitive.NewObjectID().Hex()}, client.config.Session.Token+"to make signature invalid", time.Hour)
 		require.NoError(t, err)
 
 		req.AddCookie(
@@ -202,7 +202,7 @@ func TestAuthHandler(t *testing.T) {
 	})
 
 	t.Run("should fail when session is not found", func(t *testing.T) {
-		sessionID := primitive.NewObjectID()
+		sessionID := primitive.NewObjectID().Hex()
 
 		ctrl := gomock.NewController(t)
 		dbClient := db.NewMockClient(ctrl)
@@ -233,7 +233,7 @@ func TestAuthHandler(t *testing.T) {
 	})
 
 	t.Run("should fail when database returns an error", func(t *testing.T) {
-		sessionID := primitive.NewObjectID()
+		sessionID := primitive.NewObjectID().Hex()
 
 		ctrl := gomock.NewController(t)
 		dbClient := db.NewMockClient(ctrl)
@@ -264,7 +264,7 @@ func TestAuthHandler(t *testing.T) {
 	})
 
 	t.Run("should fail when user could not be get from database", func(t *testing.T) {
-		sessionID := primitive.NewObjectID()
+		sessionID := primitive.NewObjectID().Hex()
 		session := &db.Session{
 			ID: sessionID,
 			User: authContext.User{
@@ -302,7 +302,7 @@ func TestAuthHandler(t *testing.T) {
 	})
 
 	t.Run("should return user", func(t *testing.T) {
-		sessionID := primitive.NewObjectID()
+		sessionID := primitive.NewObjectID().Hex()
 		session := &db.Session{
 			ID: sessionID,
 			User: authContext.User{
@@ -419,7 +419,7 @@ func TestSigninHandler(t *testing.T) {
 		dbClient := db.NewMockClient(ctrl)
 		dbClient.EXPECT().GetUserByID(gomock.Any(), "admin").Return(&userv1.UserSpec{ID: "admin", Password: "$2y$10$o2AokncpHCowCvDJ2rOp.e18ThDg0mlaLj5QMsjtwEEBtrEn7IYRS", Teams: []string{"team"}}, nil)
 		dbClient.EXPECT().GetTeamsByIDs(gomock.Any(), []string{"team"}, gomock.Any()).Return([]teamv1.TeamSpec{{ID: "team"}}, nil)
-		dbClient.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("unexpected error"))
+		dbClient.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("unexpected error"))
 
 		client := client{config: Config{Session: SessionConfig{Token: "1234"}}, dbClient: dbClient}
 
@@ -436,7 +436,7 @@ func TestSigninHandler(t *testing.T) {
 		dbClient := db.NewMockClient(ctrl)
 		dbClient.EXPECT().GetUserByID(gomock.Any(), "admin").Return(&userv1.UserSpec{ID: "admin", Password: "$2y$10$o2AokncpHCowCvDJ2rOp.e18ThDg0mlaLj5QMsjtwEEBtrEn7IYRS", Teams: []string{"team"}}, nil)
 		dbClient.EXPECT().GetTeamsByIDs(gomock.Any(), []string{"team"}, gomock.Any()).Return([]teamv1.TeamSpec{{ID: "team"}}, nil)
-		dbClient.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Return(&db.Session{ID: primitive.NewObjectID()}, nil)
+		dbClient.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(&db.Session{ID: primitive.NewObjectID().Hex()}, nil)
 
 		client := client{config: Config{Session: SessionConfig{Token: "1234"}}, dbClient: dbClient}
 
@@ -449,6 +449,117 @@ func TestSigninHandler(t *testing.T) {
 	})
 }
 
+func TestSigninHandlerWithStaticUsers(t *testing.T) {
+	var newRequest = func(body string) *http.Request {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, "/signin", strings.NewReader(body))
+		req.RemoteAddr = "127.0.0.1:12345"
+		return req
+	}
+
+	config := Config{
+		Session: SessionConfig{Token: "1234"},
+		Static:  StaticConfig{Users: []userv1.UserSpec{{ID: "admin", Password: "$2y$10$o2AokncpHCowCvDJ2rOp.e18ThDg0mlaLj5QMsjtwEEBtrEn7IYRS", Permissions: userv1.Permissions{Teams: []string{"*"}}}}},
+		Signin:  SigninConfig{RateLimit: 3, MaxFailedAttempts: 2, LockoutDuration: Duration{time.Hour}},
+	}
+
+	t.Run("should sign in static user without user from database", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		dbClient.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user authContext.User, metadata db.SessionMetadata) (*db.Session, error) {
+			require.Equal(t, "admin", user.ID)
+			require.Equal(t, userv1.Permissions{Teams: []string{"*"}}, user.Permissions)
+			require.Equal(t, "127.0.0.1", metadata.IP)
+			return &db.Session{ID: "session1"}, nil
+		})
+
+		client := client{config: config, dbClient: dbClient, limiter: newSigninLimiter(config.Signin)}
+
+		w := httptest.NewRecorder()
+		client.signinHandler(w, newRequest(`{"username":"admin","password":"admin"}`))
+		utils.AssertStatusEq(t, w, http.StatusOK)
+	})
+
+	t.Run("should fail when static user could not be read", func(t *testing.T) {
+		client := client{config: Config{Static: StaticConfig{HtpasswdFile: "/invalid/htpasswd"}}}
+
+		w := httptest.NewRecorder()
+		client.signinHandler(w, newRequest(`{"username":"admin","password":"admin"}`))
+		utils.AssertStatusEq(t, w, http.StatusInternalServerError)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to get local user"]}`)
+	})
+
+	t.Run("should lock user after failed sign in attempts", func(t *testing.T) {
+		client := client{config: config, limiter: newSigninLimiter(config.Signin)}
+
+		for i := 0; i < 2; i++ {
+			w := httptest.NewRecorder()
+			client.signinHandler(w, newRequest(`{"username":"admin","password":"wrongpassword"}`))
+			utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		}
+
+		w := httptest.NewRecorder()
+		client.signinHandler(w, newRequest(`{"username":"admin","password":"admin"}`))
+		utils.AssertStatusEq(t, w, http.StatusTooManyRequests)
+		utils.AssertJSONEq(t, w, `{"errors": ["Too many failed sign in attempts, try again later"]}`)
+	})
+
+	t.Run("should limit sign in requests per ip", func(t *testing.T) {
+		client := client{config: config, limiter: newSigninLimiter(config.Signin)}
+
+		for i := 0; i < 3; i++ {
+			w := httptest.NewRecorder()
+			client.signinHandler(w, newRequest(`{"username":1234}`))
+			utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		}
+
+		w := httptest.NewRecorder()
+		client.signinHandler(w, newRequest(`{"username":"admin","password":"admin"}`))
+		utils.AssertStatusEq(t, w, http.StatusTooManyRequests)
+		utils.AssertJSONEq(t, w, `{"errors": ["Too many sign in requests, try again later"]}`)
+	})
+
+	t.Run("should ignore forwarded for header from untrusted client", func(t *testing.T) {
+		client := client{config: config, limiter: newSigninLimiter(config.Signin)}
+
+		for i := 0; i < 3; i++ {
+			req := newRequest(`{"username":1234}`)
+			req.Header.Set("X-Forwarded-For", fmt.Sprintf("192.168.0.%d", i))
+
+			w := httptest.NewRecorder()
+			client.signinHandler(w, req)
+			utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		}
+
+		req := newRequest(`{"username":"admin","password":"admin"}`)
+		req.Header.Set("X-Forwarded-For", "192.168.0.10")
+
+		w := httptest.NewRecorder()
+		client.signinHandler(w, req)
+		utils.AssertStatusEq(t, w, http.StatusTooManyRequests)
+	})
+
+	t.Run("should not lock user for other ip addresses", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		dbClient.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(&db.Session{ID: "session1"}, nil)
+
+		client := client{config: config, dbClient: dbClient, limiter: newSigninLimiter(config.Signin)}
+
+		for i := 0; i < 2; i++ {
+			w := httptest.NewRecorder()
+			client.signinHandler(w, newRequest(`{"username":"admin","password":"wrongpassword"}`))
+			utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		}
+
+		req := newRequest(`{"username":"admin","password":"admin"}`)
+		req.RemoteAddr = "127.0.0.2:12345"
+
+		w := httptest.NewRecorder()
+		client.signinHandler(w, req)
+		utils.AssertStatusEq(t, w, http.StatusOK)
+	})
+}
+
 func TestSignoutHandler(t *testing.T) {
 	t.Run("should fail when no token is set", func(t *testing.T) {
 		client := client{}
@@ -467,7 +578,7 @@ func TestSignoutHandler(t *testing.T) {
 		ctx := context.Background()
 		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/signout", nil)
 
-		token, err := jwt.CreateToken(&Token{SessionID: primitive.NewObjectID()}, client.config.Session.Token+"to make signature invalid", time.Hour)
+		token, err := jwt.CreateToken(&Token{SessionID: primitive.NewObjectID().Hex()}, client.config.Session.Token+"to make signature invalid", time.Hour)
 		require.NoError(t, err)
 
 		req.AddCookie(
@@ -487,7 +598,7 @@ func TestSignoutHandler(t *testing.T) {
 	})
 
 	t.Run("should fail when session is not deleted", func(t *testing.T) {
-		sessionID := primitive.NewObjectID()
+		sessionID := primitive.NewObjectID().Hex()
 
 		ctrl := gomock.NewController(t)
 		dbClient := db.NewMockClient(ctrl)
@@ -518,7 +629,7 @@ func TestSignoutHandler(t *testing.T) {
 	})
 
 	t.Run("should sign out", func(t *testing.T) {
-		sessionID := primitive.NewObjectID()
+		sessionID := primitive.NewObjectID().Hex()
 
 		ctrl := gomock.NewController(t)
 		dbClient := db.NewMockClient(ctrl)
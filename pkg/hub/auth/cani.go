@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"net/http"
+
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/instrument/log"
+	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
+
+	"github.com/go-chi/render"
+)
+
+// canIHandler checks if the authenticated user is allowed to access the resource with the provided verb in the provided
+// cluster and namespace. Besides the decision the response also contains the rule which allowed or denied the access,
+// so that a user can see why a request was allowed or denied.
+func (c *client) canIHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := authContext.MustGetUser(ctx)
+	cluster := r.URL.Query().Get("cluster")
+	namespace := r.URL.Query().Get("namespace")
+	resource := r.URL.Query().Get("resource")
+	verb := r.URL.Query().Get("verb")
+
+	if cluster == "" || resource == "" || verb == "" {
+		log.Warn(ctx, "The 'cluster', 'resource' and 'verb' parameters are required")
+		errresponse.Render(w, r, http.StatusBadRequest, "The 'cluster', 'resource' and 'verb' parameters are required")
+		return
+	}
+
+	render.JSON(w, r, user.CanAccessResource(cluster, namespace, resource, verb))
+}
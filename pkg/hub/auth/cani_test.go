@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/utils"
+)
+
+func TestCanIHandler(t *testing.T) {
+	user := authContext.User{
+		ID: "user@kobs.io",
+		Permissions: userv1.Permissions{
+			Resources: []userv1.Resources{
+				{Clusters: []string{"*"}, Namespaces: []string{"team-*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+				{Clusters: []string{"*"}, Namespaces: []string{"*"}, Resources: []string{"secrets"}, Verbs: []string{"*"}, Deny: true},
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		name               string
+		url                string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "should fail for missing parameters",
+			url:                "/can-i?cluster=dev",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors": ["The 'cluster', 'resource' and 'verb' parameters are required"]}`,
+		},
+		{
+			name:               "should return allow rule",
+			url:                "/can-i?cluster=dev&namespace=team-a&resource=pods&verb=get",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"allowed": true, "reason": "allowed by rule", "rule": {"clusters": ["*"], "namespaces": ["team-*"], "resources": ["*"], "verbs": ["*"]}}`,
+		},
+		{
+			name:               "should return deny rule",
+			url:                "/can-i?cluster=dev&namespace=team-a&resource=secrets&verb=get",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"allowed": false, "reason": "denied by rule", "rule": {"clusters": ["*"], "namespaces": ["*"], "resources": ["secrets"], "verbs": ["*"], "deny": true}}`,
+		},
+		{
+			name:               "should return no matching rule",
+			url:                "/can-i?cluster=dev&namespace=default&resource=pods&verb=get",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"allowed": false, "reason": "no rule allows the request"}`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			client := client{}
+
+			ctx := context.WithValue(context.Background(), authContext.UserKey, user)
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, tt.url, nil)
+			w := httptest.NewRecorder()
+			client.canIHandler(w, req)
+
+			utils.AssertStatusEq(t, w, tt.expectedStatusCode)
+			utils.AssertJSONEq(t, w, tt.expectedBody)
+		})
+	}
+}
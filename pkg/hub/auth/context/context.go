@@ -3,6 +3,10 @@ package context
 import (
 	"context"
 	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
 
 	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
 	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
@@ -17,23 +21,87 @@ const UserKey ctxKeyUser = 0
 // User is the structure of our user object which is added to the current request context and saved within the users
 // current session. It contains the users id, teams and permissions. In addition to the permissions defined in a User CR
 // it also contains the permissions from all teams a user is part of.
+//
+// When the user was authenticated via a personal API token, the token can be scoped to a subset of the users
+// permissions. In this case the `Scope` contains the permissions of the token and a user only has access to an
+// application, team, plugin or resource when it is allowed by the users permissions and by the scope.
 type User struct {
-	ID          string             `json:"id" bson:"id"`
-	Name        string             `json:"name" bson:"name"`
-	Teams       []string           `json:"teams" bson:"teams"`
-	Permissions userv1.Permissions `json:"permissions" bson:"permissions"`
+	ID          string              `json:"id" bson:"id"`
+	Name        string              `json:"name" bson:"name"`
+	Teams       []string            `json:"teams" bson:"teams"`
+	Permissions userv1.Permissions  `json:"permissions" bson:"permissions"`
+	Scope       *userv1.Permissions `json:"scope,omitempty" bson:"scope,omitempty"`
 }
 
 // HasApplicationAccess checks if the user is allowed to view an application.
 func (u *User) HasApplicationAccess(application *applicationv1.ApplicationSpec) bool {
-	for _, a := range u.Permissions.Applications {
+	if u.Scope != nil && !hasApplicationAccess(*u.Scope, u.Teams, application) {
+		return false
+	}
+
+	return hasApplicationAccess(u.Permissions, u.Teams, application)
+}
+
+// HasTeamAccess checks if the user is allowed to view a team. Teams are identified by the group property.
+func (u *User) HasTeamAccess(team string) bool {
+	if u.Scope != nil && !hasTeamAccess(*u.Scope, team) {
+		return false
+	}
+
+	return hasTeamAccess(u.Permissions, team)
+}
+
+// HasPluginAccess checks if the user has access to the given plugin.
+func (u *User) HasPluginAccess(cluster, pluginType, pluginName string) bool {
+	if u.Scope != nil && !hasPluginAccess(*u.Scope, cluster, pluginType, pluginName) {
+		return false
+	}
+
+	return hasPluginAccess(u.Permissions, cluster, pluginType, pluginName)
+}
+
+// HasResourceAccess checks if the user has access to the given resource in the given cluster and namespace.
+func (u *User) HasResourceAccess(cluster, namespace, name, verb string) bool {
+	return u.CanAccessResource(cluster, namespace, name, verb).Allowed
+}
+
+// ResourceAccessDecision is the result of a resource access check. Besides the information if the access is allowed,
+// it also contains the reason for the decision and the rule which allowed or denied the access, so that a user can see
+// why a request was allowed or denied.
+type ResourceAccessDecision struct {
+	Allowed bool              `json:"allowed"`
+	Reason  string            `json:"reason"`
+	Rule    *userv1.Resources `json:"rule,omitempty"`
+	Scope   bool              `json:"scope,omitempty"`
+}
+
+// CanAccessResource checks if the user has access to the given resource in the given cluster and namespace and returns
+// the decision with the rule which allowed or denied the access. When the user was authenticated via a scoped personal
+// API token, the access must also be allowed by the scope of the token.
+func (u *User) CanAccessResource(cluster, namespace, name, verb string) ResourceAccessDecision {
+	decision := canAccessResource(u.Permissions, cluster, namespace, name, verb)
+	if !decision.Allowed || u.Scope == nil {
+		return decision
+	}
+
+	scopeDecision := canAccessResource(*u.Scope, cluster, namespace, name, verb)
+	if !scopeDecision.Allowed {
+		scopeDecision.Scope = true
+		return scopeDecision
+	}
+
+	return decision
+}
+
+func hasApplicationAccess(permissions userv1.Permissions, teams []string, application *applicationv1.ApplicationSpec) bool {
+	for _, a := range permissions.Applications {
 		if a.Type == "all" {
 			return true
 		}
 
 		if a.Type == "own" {
 			for _, applicationTeam := range application.Teams {
-				for _, userTeam := range u.Teams {
+				for _, userTeam := range teams {
 					if userTeam == applicationTeam {
 						return true
 					}
@@ -57,9 +125,8 @@ func (u *User) HasApplicationAccess(application *applicationv1.ApplicationSpec)
 	return false
 }
 
-// HasTeamAccess checks if the user is allowed to view a team. Teams are identified by the group property.
-func (u *User) HasTeamAccess(team string) bool {
-	for _, t := range u.Permissions.Teams {
+func hasTeamAccess(permissions userv1.Permissions, team string) bool {
+	for _, t := range permissions.Teams {
 		if t == team || t == "*" {
 			return true
 		}
@@ -68,9 +135,8 @@ func (u *User) HasTeamAccess(team string) bool {
 	return false
 }
 
-// HasPluginAccess checks if the user has access to the given plugin.
-func (u *User) HasPluginAccess(cluster, pluginType, pluginName string) bool {
-	for _, p := range u.Permissions.Plugins {
+func hasPluginAccess(permissions userv1.Permissions, cluster, pluginType, pluginName string) bool {
+	for _, p := range permissions.Plugins {
 		if p.Cluster == cluster || p.Cluster == "*" {
 			if p.Type == pluginType || p.Type == "*" {
 				if p.Name == pluginName || p.Name == "*" {
@@ -83,26 +149,82 @@ func (u *User) HasPluginAccess(cluster, pluginType, pluginName string) bool {
 	return false
 }
 
-// HasResourceAccess checks if the user has access to the given resource in the given cluster and namespace.
-func (u *User) HasResourceAccess(cluster, namespace, name, verb string) bool {
-	for _, resource := range u.Permissions.Resources {
-		for _, c := range resource.Clusters {
-			if c == cluster || c == "*" {
-				for _, n := range resource.Namespaces {
-					if n == namespace || n == "*" {
-						for _, r := range resource.Resources {
-							if r == name || r == "*" {
-								for _, v := range resource.Verbs {
-									if v == verb || v == "*" {
-										return true
-									}
-								}
-							}
-						}
-					}
-				}
-			}
+// canAccessResource checks the resource rules of the provided permissions. Deny rules take precedence over allow rules,
+// so that the access is denied as soon as one deny rule matches, even when another rule allows the access.
+//
+// A request for all clusters or namespaces (`*`) is denied by every deny rule for the resource and verb, because it
+// would also include the denied clusters or namespaces. An allow rule must contain `*` to allow such a request.
+func canAccessResource(permissions userv1.Permissions, cluster, namespace, name, verb string) ResourceAccessDecision {
+	for i, resource := range permissions.Resources {
+		if resource.Deny && matchResourceRule(resource, cluster, namespace, name, verb, true) {
+			return ResourceAccessDecision{Allowed: false, Reason: "denied by rule", Rule: &permissions.Resources[i]}
+		}
+	}
+
+	for i, resource := range permissions.Resources {
+		if !resource.Deny && matchResourceRule(resource, cluster, namespace, name, verb, false) {
+			return ResourceAccessDecision{Allowed: true, Reason: "allowed by rule", Rule: &permissions.Resources[i]}
+		}
+	}
+
+	return ResourceAccessDecision{Allowed: false, Reason: "no rule allows the request"}
+}
+
+// matchResourceRule returns true when the provided rule matches the cluster, namespace, resource and verb. Clusters and
+// namespaces can be matched via glob patterns (e.g. `team-*`) and regular expressions, which must be wrapped in slashes
+// (e.g. `/team-(a|b)/`). Verbs are matched case-insensitive.
+//
+// If `wildcardMatchesAll` is true, a `*` for the cluster, namespace, resource or verb in the request matches every
+// value of the rule. This is used for deny rules.
+func matchResourceRule(rule userv1.Resources, cluster, namespace, name, verb string, wildcardMatchesAll bool) bool {
+	return matchAny(rule.Clusters, cluster, wildcardMatchesAll, matchPattern) &&
+		matchAny(rule.Namespaces, namespace, wildcardMatchesAll, matchPattern) &&
+		matchAny(rule.Resources, name, wildcardMatchesAll, func(pattern, value string) bool {
+			return pattern == "*" || pattern == value
+		}) &&
+		matchAny(rule.Verbs, verb, wildcardMatchesAll, func(pattern, value string) bool {
+			return pattern == "*" || strings.EqualFold(pattern, value)
+		})
+}
+
+func matchAny(patterns []string, value string, wildcardMatchesAll bool, match func(pattern, value string) bool) bool {
+	for _, pattern := range patterns {
+		if (wildcardMatchesAll && value == "*") || match(pattern, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// regexpCache caches the compiled regular expressions from the permissions, so that we do not have to compile the same
+// expression for each request.
+var regexpCache sync.Map
+
+// matchPattern returns true when the provided value matches the pattern. The pattern can be `*`, a regular expression
+// wrapped in slashes, a glob pattern or a plain string.
+func matchPattern(pattern, value string) bool {
+	if pattern == "*" || pattern == value {
+		return true
+	}
+
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		if cached, ok := regexpCache.Load(pattern); ok {
+			re, _ := cached.(*regexp.Regexp)
+			return re != nil && re.MatchString(value)
 		}
+
+		re, err := regexp.Compile("^(?:" + pattern[1:len(pattern)-1] + ")$")
+		if err != nil {
+			re = nil
+		}
+		regexpCache.Store(pattern, re)
+		return re != nil && re.MatchString(value)
+	}
+
+	if strings.ContainsAny(pattern, "*?[") {
+		matched, err := path.Match(pattern, value)
+		return err == nil && matched
 	}
 
 	return false
@@ -25,6 +25,9 @@ func TestHasApplicationAccess(t *testing.T) {
 		{user: User{Teams: []string{"team1"}, ID: "user8@kobs.io", Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "custom", Clusters: []string{"stage-de1"}}}}}, expectedHasAccess: false},
 		{user: User{Teams: []string{"team1"}, ID: "user9@kobs.io", Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "custom", Clusters: []string{"dev-de1"}, Namespaces: []string{"kube-system"}}}}}, expectedHasAccess: false},
 		{user: User{Teams: []string{"team1"}, ID: "user10@kobs.io", Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "custom", Clusters: []string{"dev-de1"}, Namespaces: []string{"default"}}}}}, expectedHasAccess: true},
+		{user: User{Teams: []string{"team1"}, ID: "user11@kobs.io", Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}, Scope: &userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "own"}}}}, expectedHasAccess: true},
+		{user: User{Teams: []string{"team1"}, ID: "user12@kobs.io", Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}, Scope: &userv1.Permissions{}}, expectedHasAccess: false},
+		{user: User{Teams: []string{"team2"}, ID: "user13@kobs.io", Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "own"}}}, Scope: &userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}}, expectedHasAccess: false},
 	} {
 		t.Run(tt.user.ID, func(t *testing.T) {
 			actualHasAccess := tt.user.HasApplicationAccess(&applicationv1.ApplicationSpec{Cluster: "dev-de1", Namespace: "default", Teams: []string{"team1"}})
@@ -43,6 +46,9 @@ func TestHasTeamAccess(t *testing.T) {
 		{user: User{ID: "user1@kobs.io", Permissions: userv1.Permissions{Teams: []string{"team2", "team1"}}}, expectedHasAccess: true},
 		{user: User{ID: "user1@kobs.io", Permissions: userv1.Permissions{Teams: []string{"team2", "*"}}}, expectedHasAccess: true},
 		{user: User{ID: "user1@kobs.io", Permissions: userv1.Permissions{Teams: []string{"team2"}}}, expectedHasAccess: false},
+		{user: User{ID: "user1@kobs.io", Permissions: userv1.Permissions{Teams: []string{"*"}}, Scope: &userv1.Permissions{Teams: []string{"team1"}}}, expectedHasAccess: true},
+		{user: User{ID: "user1@kobs.io", Permissions: userv1.Permissions{Teams: []string{"*"}}, Scope: &userv1.Permissions{Teams: []string{"team2"}}}, expectedHasAccess: false},
+		{user: User{ID: "user1@kobs.io", Permissions: userv1.Permissions{Teams: []string{"team2"}}, Scope: &userv1.Permissions{Teams: []string{"*"}}}, expectedHasAccess: false},
 	} {
 		t.Run(tt.user.ID, func(t *testing.T) {
 			actualHasAccess := tt.user.HasTeamAccess("team1")
@@ -65,6 +71,8 @@ func TestHasPluginAccess(t *testing.T) {
 		{user: User{ID: "user7@kobs.io", Permissions: userv1.Permissions{Plugins: []userv1.Plugin{{Cluster: "test-cluster2", Type: "prometheus", Name: "*"}}}}, expectedHasAccess: false},
 		{user: User{ID: "user1@kobs.io", Permissions: userv1.Permissions{Plugins: []userv1.Plugin{{Cluster: "*", Type: "klogs", Name: "*"}}}}, expectedHasAccess: false},
 		{user: User{ID: "user1@kobs.io", Permissions: userv1.Permissions{Plugins: []userv1.Plugin{{Cluster: "*", Type: "*", Name: "*"}}}}, expectedHasAccess: true},
+		{user: User{ID: "user8@kobs.io", Permissions: userv1.Permissions{Plugins: []userv1.Plugin{{Cluster: "*", Type: "*", Name: "*"}}}, Scope: &userv1.Permissions{Plugins: []userv1.Plugin{{Cluster: "*", Type: "prometheus", Name: "plugin1"}}}}, expectedHasAccess: true},
+		{user: User{ID: "user9@kobs.io", Permissions: userv1.Permissions{Plugins: []userv1.Plugin{{Cluster: "*", Type: "*", Name: "*"}}}, Scope: &userv1.Permissions{Plugins: []userv1.Plugin{{Cluster: "*", Type: "klogs", Name: "*"}}}}, expectedHasAccess: false},
 	} {
 		t.Run(tt.user.ID, func(t *testing.T) {
 			actualHasAccess := tt.user.HasPluginAccess("test-cluster1", "prometheus", "plugin1")
@@ -99,6 +107,17 @@ func TestHasResourceAccess(t *testing.T) {
 		{user: User{ID: "user13@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"cluster2"}, Namespaces: []string{"*"}, Resources: []string{"resource1"}}, {Clusters: []string{"cluster1"}, Namespaces: []string{"*"}, Resources: []string{"resource1"}, Verbs: []string{"*"}}}}}, expectedHasAccess: true},
 		{user: User{ID: "user14@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"cluster2"}, Namespaces: []string{"*"}, Resources: []string{"resource1"}}, {Clusters: []string{"cluster1"}, Namespaces: []string{"*"}, Resources: []string{"resource1"}, Verbs: []string{"get"}}}}}, expectedHasAccess: true},
 		{user: User{ID: "user15@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"cluster2"}, Namespaces: []string{"*"}, Resources: []string{"resource1"}}, {Clusters: []string{"cluster1"}, Namespaces: []string{"*"}, Resources: []string{"resource1"}, Verbs: []string{"patch"}}}}}, expectedHasAccess: false},
+
+		{user: User{ID: "user16@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"*"}, Namespaces: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}}}, Scope: &userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"cluster1"}, Namespaces: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"get"}}}}}, expectedHasAccess: true},
+		{user: User{ID: "user17@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"*"}, Namespaces: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}}}, Scope: &userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"cluster1"}, Namespaces: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"patch"}}}}}, expectedHasAccess: false},
+		{user: User{ID: "user18@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"cluster1"}, Namespaces: []string{"*"}, Resources: []string{"resource1"}, Verbs: []string{"patch"}}}}, Scope: &userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"*"}, Namespaces: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}}}}, expectedHasAccess: false},
+
+		{user: User{ID: "user19@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"*"}, Namespaces: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}, {Clusters: []string{"*"}, Namespaces: []string{"namespace1"}, Resources: []string{"resource1"}, Verbs: []string{"*"}, Deny: true}}}}, expectedHasAccess: false},
+		{user: User{ID: "user20@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"*"}, Namespaces: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}, {Clusters: []string{"*"}, Namespaces: []string{"*"}, Resources: []string{"resource1"}, Verbs: []string{"patch"}, Deny: true}}}}, expectedHasAccess: true},
+		{user: User{ID: "user21@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"cluster*"}, Namespaces: []string{"namespace?"}, Resources: []string{"resource1"}, Verbs: []string{"GET"}}}}}, expectedHasAccess: true},
+		{user: User{ID: "user22@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"*"}, Namespaces: []string{"/namespace(1|2)/"}, Resources: []string{"resource1"}, Verbs: []string{"get"}}}}}, expectedHasAccess: true},
+		{user: User{ID: "user23@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"*"}, Namespaces: []string{"/namespace/"}, Resources: []string{"resource1"}, Verbs: []string{"get"}}}}}, expectedHasAccess: false},
+		{user: User{ID: "user24@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"*"}, Namespaces: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}}}, Scope: &userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"*"}, Namespaces: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}, {Clusters: []string{"cluster1"}, Namespaces: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}, Deny: true}}}}, expectedHasAccess: false},
 	} {
 		t.Run(tt.user.ID, func(t *testing.T) {
 			actualHasAccess := tt.user.HasResourceAccess("cluster1", "namespace1", "resource1", "get")
@@ -107,6 +126,74 @@ func TestHasResourceAccess(t *testing.T) {
 	}
 }
 
+func TestCanAccessResource(t *testing.T) {
+	user := User{
+		ID: "user1@kobs.io",
+		Permissions: userv1.Permissions{
+			Resources: []userv1.Resources{
+				{Clusters: []string{"*"}, Namespaces: []string{"team-*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+				{Clusters: []string{"prod"}, Namespaces: []string{"*"}, Resources: []string{"pods/exec"}, Verbs: []string{"*"}, Deny: true},
+			},
+		},
+	}
+
+	t.Run("should be allowed by rule", func(t *testing.T) {
+		decision := user.CanAccessResource("dev", "team-a", "pods/exec", "exec")
+		require.True(t, decision.Allowed)
+		require.Equal(t, &user.Permissions.Resources[0], decision.Rule)
+	})
+
+	t.Run("should be denied by rule", func(t *testing.T) {
+		decision := user.CanAccessResource("prod", "team-a", "pods/exec", "exec")
+		require.False(t, decision.Allowed)
+		require.Equal(t, &user.Permissions.Resources[1], decision.Rule)
+	})
+
+	t.Run("should be denied for all namespaces when a deny rule exists", func(t *testing.T) {
+		decision := user.CanAccessResource("*", "*", "pods/exec", "exec")
+		require.False(t, decision.Allowed)
+		require.Equal(t, &user.Permissions.Resources[1], decision.Rule)
+	})
+
+	t.Run("should be denied when no rule matches", func(t *testing.T) {
+		decision := user.CanAccessResource("dev", "default", "pods", "get")
+		require.False(t, decision.Allowed)
+		require.Nil(t, decision.Rule)
+	})
+
+	t.Run("should be denied by scope", func(t *testing.T) {
+		scopedUser := user
+		scopedUser.Scope = &userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"dev"}, Namespaces: []string{"*"}, Resources: []string{"pods"}, Verbs: []string{"get"}}}}
+
+		decision := scopedUser.CanAccessResource("dev", "team-a", "pods/exec", "exec")
+		require.False(t, decision.Allowed)
+		require.True(t, decision.Scope)
+	})
+}
+
+func TestMatchPattern(t *testing.T) {
+	for _, tt := range []struct {
+		pattern       string
+		value         string
+		expectedMatch bool
+	}{
+		{pattern: "*", value: "default", expectedMatch: true},
+		{pattern: "default", value: "default", expectedMatch: true},
+		{pattern: "default", value: "kube-system", expectedMatch: false},
+		{pattern: "kube-*", value: "kube-system", expectedMatch: true},
+		{pattern: "kube-*", value: "default", expectedMatch: false},
+		{pattern: "team-[ab]", value: "team-a", expectedMatch: true},
+		{pattern: "/team-(a|b)/", value: "team-b", expectedMatch: true},
+		{pattern: "/team-(a|b)/", value: "team-c", expectedMatch: false},
+		{pattern: "/team/", value: "my-team-a", expectedMatch: false},
+		{pattern: "/team-(/", value: "team-(", expectedMatch: false},
+	} {
+		t.Run(tt.pattern+" "+tt.value, func(t *testing.T) {
+			require.Equal(t, tt.expectedMatch, matchPattern(tt.pattern, tt.value))
+		})
+	}
+}
+
 func TestGetPluginPermissions(t *testing.T) {
 	user := User{ID: "user1@kobs.io", Permissions: userv1.Permissions{Plugins: []userv1.Plugin{{Cluster: "*", Name: "plugin1"}}}}
 	res1 := user.GetPluginPermissions("plugin1")
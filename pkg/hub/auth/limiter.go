@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// signinLimiter limits the number of sign in requests per ip address and locks a user for an ip address after too many
+// failed sign in attempts from this ip address. The lockout is tracked per ip address and user, so that an attacker can
+// not lock out a user by sending wrong passwords for it. The state is only kept in memory, so that it is reset when the
+// hub is restarted and it is not shared between multiple hub instances.
+type signinLimiter struct {
+	mu                sync.Mutex
+	rateLimit         int
+	maxFailedAttempts int
+	lockoutDuration   time.Duration
+	requests          map[string]*signinWindow
+	failures          map[string]*signinWindow
+	lastCleanup       time.Time
+	now               func() time.Time
+}
+
+// signinWindow counts the number of requests or failed attempts since `start`.
+type signinWindow struct {
+	start time.Time
+	count int
+}
+
+// allow returns true when the provided ip address is allowed to make another sign in request. Each ip address can make
+// `rateLimit` requests per minute.
+func (l *signinLimiter) allow(ip string) bool {
+	if l == nil || l.rateLimit <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.cleanup(now)
+
+	window, ok := l.requests[ip]
+	if !ok || now.Sub(window.start) >= time.Minute {
+		l.requests[ip] = &signinWindow{start: now, count: 1}
+		return true
+	}
+
+	if window.count >= l.rateLimit {
+		return false
+	}
+
+	window.count++
+	return true
+}
+
+// failureKey returns the key for the failed sign in attempts of the user with the provided id from the provided ip
+// address.
+func failureKey(ip, id string) string {
+	return ip + "/" + id
+}
+
+// isLocked returns true when the user with the provided id has `maxFailedAttempts` failed sign in attempts from the
+// provided ip address within the lockout duration.
+func (l *signinLimiter) isLocked(ip, id string) bool {
+	if l == nil || l.maxFailedAttempts <= 0 {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	window, ok := l.failures[failureKey(ip, id)]
+	if !ok || l.now().Sub(window.start) >= l.lockoutDuration {
+		return false
+	}
+
+	return window.count >= l.maxFailedAttempts
+}
+
+// fail records a failed sign in attempt for the user with the provided id from the provided ip address.
+func (l *signinLimiter) fail(ip, id string) {
+	if l == nil || l.maxFailedAttempts <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	key := failureKey(ip, id)
+
+	window, ok := l.failures[key]
+	if !ok || now.Sub(window.start) >= l.lockoutDuration {
+		l.failures[key] = &signinWindow{start: now, count: 1}
+		return
+	}
+
+	window.count++
+}
+
+// succeed resets the failed sign in attempts for the user with the provided id from the provided ip address.
+func (l *signinLimiter) succeed(ip, id string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.failures, failureKey(ip, id))
+}
+
+// cleanup removes all expired windows, so that the maps do not grow forever. The cleanup is done at most once per
+// minute and must be called while the mutex is locked.
+func (l *signinLimiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < time.Minute {
+		return
+	}
+	l.lastCleanup = now
+
+	for ip, window := range l.requests {
+		if now.Sub(window.start) >= time.Minute {
+			delete(l.requests, ip)
+		}
+	}
+
+	for id, window := range l.failures {
+		if now.Sub(window.start) >= l.lockoutDuration {
+			delete(l.failures, id)
+		}
+	}
+}
+
+func newSigninLimiter(config SigninConfig) *signinLimiter {
+	return &signinLimiter{
+		rateLimit:         config.RateLimit,
+		maxFailedAttempts: config.MaxFailedAttempts,
+		lockoutDuration:   config.LockoutDuration.Duration,
+		requests:          make(map[string]*signinWindow),
+		failures:          make(map[string]*signinWindow),
+		now:               time.Now,
+	}
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigninLimiter(t *testing.T) {
+	t.Run("should allow all requests when limiter is nil", func(t *testing.T) {
+		var l *signinLimiter
+		require.True(t, l.allow("127.0.0.1"))
+		require.False(t, l.isLocked("127.0.0.1", "admin"))
+		l.fail("127.0.0.1", "admin")
+		l.succeed("127.0.0.1", "admin")
+	})
+
+	t.Run("should limit requests per ip", func(t *testing.T) {
+		now := time.Now()
+		l := newSigninLimiter(SigninConfig{RateLimit: 2})
+		l.now = func() time.Time { return now }
+
+		require.True(t, l.allow("127.0.0.1"))
+		require.True(t, l.allow("127.0.0.1"))
+		require.False(t, l.allow("127.0.0.1"))
+		require.True(t, l.allow("127.0.0.2"))
+
+		now = now.Add(time.Minute)
+		require.True(t, l.allow("127.0.0.1"))
+	})
+
+	t.Run("should not limit requests when rate limit is disabled", func(t *testing.T) {
+		l := newSigninLimiter(SigninConfig{})
+		for i := 0; i < 100; i++ {
+			require.True(t, l.allow("127.0.0.1"))
+		}
+	})
+
+	t.Run("should lock user after failed attempts", func(t *testing.T) {
+		now := time.Now()
+		l := newSigninLimiter(SigninConfig{MaxFailedAttempts: 2, LockoutDuration: Duration{15 * time.Minute}})
+		l.now = func() time.Time { return now }
+
+		l.fail("127.0.0.1", "admin")
+		require.False(t, l.isLocked("127.0.0.1", "admin"))
+		l.fail("127.0.0.1", "admin")
+		require.True(t, l.isLocked("127.0.0.1", "admin"))
+		require.False(t, l.isLocked("127.0.0.1", "user"))
+
+		now = now.Add(15 * time.Minute)
+		require.False(t, l.isLocked("127.0.0.1", "admin"))
+	})
+
+	t.Run("should not lock user for other ip addresses", func(t *testing.T) {
+		l := newSigninLimiter(SigninConfig{MaxFailedAttempts: 2, LockoutDuration: Duration{15 * time.Minute}})
+
+		l.fail("127.0.0.1", "admin")
+		l.fail("127.0.0.1", "admin")
+		require.True(t, l.isLocked("127.0.0.1", "admin"))
+		require.False(t, l.isLocked("127.0.0.2", "admin"))
+	})
+
+	t.Run("should reset failed attempts after successful sign in", func(t *testing.T) {
+		l := newSigninLimiter(SigninConfig{MaxFailedAttempts: 2, LockoutDuration: Duration{15 * time.Minute}})
+
+		l.fail("127.0.0.1", "admin")
+		l.succeed("127.0.0.1", "admin")
+		l.fail("127.0.0.1", "admin")
+		require.False(t, l.isLocked("127.0.0.1", "admin"))
+	})
+
+	t.Run("should remove expired windows", func(t *testing.T) {
+		now := time.Now()
+		l := newSigninLimiter(SigninConfig{RateLimit: 2, MaxFailedAttempts: 2, LockoutDuration: Duration{time.Minute}})
+		l.now = func() time.Time { return now }
+
+		l.allow("127.0.0.1")
+		l.fail("127.0.0.1", "admin")
+		require.Len(t, l.requests, 1)
+		require.Len(t, l.failures, 1)
+
+		now = now.Add(2 * time.Minute)
+		l.allow("127.0.0.2")
+		require.Len(t, l.requests, 1)
+		require.Len(t, l.failures, 0)
+	})
+}
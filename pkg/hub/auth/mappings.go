@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"fmt"
+	"regexp"
+
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/utils"
+)
+
+// oidcMapping is an OIDC mapping from the configuration with the compiled regular expression, so that we do not have to
+// compile the regular expression for each sign in.
+type oidcMapping struct {
+	OIDCMapping
+	match *regexp.Regexp
+}
+
+// newOIDCMappings compiles the regular expressions of all provided mappings. The regular expression must match the
+// complete claim value, so that a mapping for "admins" doesn't also match a group named "not-admins".
+func newOIDCMappings(mappings []OIDCMapping) ([]oidcMapping, error) {
+	var compiledMappings []oidcMapping
+
+	for _, mapping := range mappings {
+		if mapping.Claim == "" {
+			mapping.Claim = "groups"
+		}
+
+		match, err := regexp.Compile("^(?:" + mapping.Match + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid match for claim %s: %w", mapping.Claim, err)
+		}
+
+		compiledMappings = append(compiledMappings, oidcMapping{OIDCMapping: mapping, match: match})
+	}
+
+	return compiledMappings, nil
+}
+
+// getClaimValues returns the values of the claim with the provided name. A claim can be a single string or a list of
+// strings. All other claim types are ignored.
+func getClaimValues(claims map[string]any, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []any:
+		var values []string
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// applyOIDCMappings adds the teams and permissions of all mappings, which are matching the provided claims, to the
+// provided user. Each mapping is only applied once, even when multiple values of the claim are matching.
+func applyOIDCMappings(user *authContext.User, mappings []oidcMapping, claims map[string]any) {
+	for _, mapping := range mappings {
+		for _, value := range getClaimValues(claims, mapping.Claim) {
+			if !mapping.match.MatchString(value) {
+				continue
+			}
+
+			for _, team := range mapping.Teams {
+				if !utils.Contains(user.Teams, team) {
+					user.Teams = append(user.Teams, team)
+				}
+			}
+
+			user.Permissions.Applications = append(user.Permissions.Applications, mapping.Permissions.Applications...)
+			user.Permissions.Teams = append(user.Permissions.Teams, mapping.Permissions.Teams...)
+			user.Permissions.Plugins = append(user.Permissions.Plugins, mapping.Permissions.Plugins...)
+			user.Permissions.Resources = append(user.Permissions.Resources, mapping.Permissions.Resources...)
+			break
+		}
+	}
+}
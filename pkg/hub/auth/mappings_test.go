@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	"github.com/kobsio/kobs/pkg/hub/app/settings"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/utils"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeOIDCIssuer starts a local OIDC issuer, which returns an id token with the provided claims for each token
+// request. The id token is signed with a random RSA key, which is returned via the JWKS endpoint of the issuer.
+func newFakeOIDCIssuer(t *testing.T, clientID string, claims map[string]any) *httptest.Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	encode := func(data []byte) string {
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                ts.URL,
+			"authorization_endpoint":                ts.URL + "/auth",
+			"token_endpoint":                        ts.URL + "/token",
+			"jwks_uri":                              ts.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]any{{
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"kid": "test",
+				"n":   encode(key.N.Bytes()),
+				"e":   encode(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		payload := map[string]any{
+			"iss": ts.URL,
+			"aud": clientID,
+			"sub": "test",
+			"iat": time.Now().Unix(),
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+		for k, v := range claims {
+			payload[k] = v
+		}
+
+		header, _ := json.Marshal(map[string]any{"alg": "RS256", "typ": "JWT", "kid": "test"})
+		body, _ := json.Marshal(payload)
+		unsigned := encode(header) + "." + encode(body)
+
+		hash := sha256.Sum256([]byte(unsigned))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     unsigned + "." + encode(signature),
+		})
+	})
+
+	return ts
+}
+
+func TestNewOIDCMappings(t *testing.T) {
+	t.Run("should use groups claim by default", func(t *testing.T) {
+		mappings, err := newOIDCMappings([]OIDCMapping{{Match: "admins"}, {Claim: "email", Match: ".*@kobs.io"}})
+		require.NoError(t, err)
+		require.Equal(t, "groups", mappings[0].Claim)
+		require.Equal(t, "email", mappings[1].Claim)
+	})
+
+	t.Run("should fail for invalid regular expression", func(t *testing.T) {
+		_, err := newOIDCMappings([]OIDCMapping{{Match: "("}})
+		require.Error(t, err)
+	})
+}
+
+func TestApplyOIDCMappings(t *testing.T) {
+	mappings, err := newOIDCMappings([]OIDCMapping{
+		{Match: "admins", Teams: []string{"team-admins"}, Permissions: userv1.Permissions{Teams: []string{"*"}}},
+		{Match: "dev-.*", Teams: []string{"team-dev", "team-all"}},
+		{Claim: "email", Match: ".*@kobs\\.io", Teams: []string{"team-all"}, Permissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}}},
+	})
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name                string
+		claims              map[string]any
+		expectedTeams       []string
+		expectedPermissions userv1.Permissions
+	}{
+		{
+			name:          "should not apply mappings when no claim matches",
+			claims:        map[string]any{"groups": []any{"not-admins"}, "email": "user@example.com"},
+			expectedTeams: []string{"group1"},
+		},
+		{
+			name:                "should apply mappings for matching groups",
+			claims:              map[string]any{"groups": []any{"admins", "dev-frontend", "dev-backend"}},
+			expectedTeams:       []string{"group1", "team-admins", "team-dev", "team-all"},
+			expectedPermissions: userv1.Permissions{Teams: []string{"*"}},
+		},
+		{
+			name:                "should apply mappings for string claim",
+			claims:              map[string]any{"groups": "dev-frontend", "email": "user@kobs.io"},
+			expectedTeams:       []string{"group1", "team-dev", "team-all"},
+			expectedPermissions: userv1.Permissions{Applications: []userv1.ApplicationPermissions{{Type: "all"}}},
+		},
+		{
+			name:          "should ignore claims with other types",
+			claims:        map[string]any{"groups": true, "email": []any{1, 2}},
+			expectedTeams: []string{"group1"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &authContext.User{ID: "user@kobs.io", Teams: []string{"group1"}}
+			applyOIDCMappings(user, mappings, tt.claims)
+			require.Equal(t, tt.expectedTeams, user.Teams)
+			require.Equal(t, tt.expectedPermissions, user.Permissions)
+		})
+	}
+}
+
+func TestOidcCallbackHandlerWithMappings(t *testing.T) {
+	ts := newFakeOIDCIssuer(t, "kobs", map[string]any{
+		"email":  "user@kobs.io",
+		"name":   "User",
+		"groups": []string{"idp-admins"},
+	})
+
+	ctrl := gomock.NewController(t)
+	dbClient := db.NewMockClient(ctrl)
+	dbClient.EXPECT().GetUserByID(gomock.Any(), "user@kobs.io").Return(nil, nil)
+	dbClient.EXPECT().GetTeamsByIDs(gomock.Any(), []string{"idp-admins", "admins"}, "").Return([]teamv1.TeamSpec{{ID: "admins", Permissions: userv1.Permissions{Plugins: []userv1.Plugin{{Cluster: "*", Type: "*", Name: "*"}}}}}, nil)
+	dbClient.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user authContext.User, metadata db.SessionMetadata) (*db.Session, error) {
+		require.Equal(t, "user@kobs.io", user.ID)
+		require.Equal(t, []string{"idp-admins", "admins"}, user.Teams)
+		require.Equal(t, userv1.Permissions{
+			Teams:   []string{"*"},
+			Plugins: []userv1.Plugin{{Cluster: "*", Type: "*", Name: "*"}},
+		}, user.Permissions)
+		return &db.Session{ID: "session1", User: user}, nil
+	})
+
+	c, err := NewClient(Config{
+		OIDC: OIDCConfig{
+			Enabled:     true,
+			Issuer:      ts.URL,
+			ClientID:    "kobs",
+			RedirectURL: "http://localhost:3000/auth/callback",
+			State:       "state",
+			Scopes:      []string{"openid", "profile", "email", "groups"},
+			Mappings:    []OIDCMapping{{Match: "idp-admins", Teams: []string{"admins"}, Permissions: userv1.Permissions{Teams: []string{"*"}}}},
+		},
+		Session: SessionConfig{Token: "1234", Duration: Duration{time.Hour}},
+	}, settings.Settings{}, dbClient)
+	require.NoError(t, err)
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "/oidc/callback?state=state%2F&code=code", nil)
+	w := httptest.NewRecorder()
+	c.Mount().ServeHTTP(w, req)
+
+	utils.AssertStatusEq(t, w, http.StatusOK)
+
+	var response struct {
+		User userResponse `json:"user"`
+		URL  string       `json:"url"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Equal(t, "/", response.URL)
+	require.Equal(t, []string{"idp-admins", "admins"}, response.User.Teams)
+	require.Len(t, w.Result().Cookies(), 1)
+}
+
+func TestNewClientWithInvalidMappings(t *testing.T) {
+	c, err := NewClient(Config{OIDC: OIDCConfig{Mappings: []OIDCMapping{{Match: "("}}}}, settings.Settings{}, nil)
+	require.Error(t, err)
+	require.Nil(t, c)
+}
@@ -0,0 +1,235 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/auth/jwt"
+	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/instrument/log"
+	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
+
+	"github.com/go-chi/render"
+	"go.uber.org/zap"
+)
+
+type sessionResponse struct {
+	ID        string    `json:"id"`
+	UserAgent string    `json:"userAgent"`
+	IP        string    `json:"ip"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Current   bool      `json:"current"`
+}
+
+// getClientIP returns the ip address of the client, which made the provided request. By default the remote address of
+// the request is used. The "X-Forwarded-For" header is only used when the request was sent by one of the trusted
+// proxies, because otherwise a client could set an arbitrary ip address. In this case the header is read from right to
+// left and the first address which isn't a trusted proxy is returned.
+func getClientIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	remoteIP := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remoteIP = host
+	}
+
+	if !isTrustedProxy(remoteIP, trustedProxies) {
+		return remoteIP
+	}
+
+	forwardedFor := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwardedFor) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwardedFor[i])
+		if ip == "" {
+			continue
+		}
+
+		if !isTrustedProxy(ip, trustedProxies) {
+			return ip
+		}
+		remoteIP = ip
+	}
+
+	return remoteIP
+}
+
+// isTrustedProxy returns true when the provided ip address is part of one of the trusted proxy networks.
+func isTrustedProxy(ip string, trustedProxies []*net.IPNet) bool {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return false
+	}
+
+	for _, trustedProxy := range trustedProxies {
+		if trustedProxy.Contains(parsedIP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseTrustedProxies parses the provided list of trusted proxies. Each entry must be a CIDR or a single ip address.
+func parseTrustedProxies(trustedProxies []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+
+	for _, trustedProxy := range trustedProxies {
+		if !strings.Contains(trustedProxy, "/") {
+			ip := net.ParseIP(trustedProxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %s", trustedProxy)
+			}
+
+			if ip.To4() != nil {
+				trustedProxy = trustedProxy + "/32"
+			} else {
+				trustedProxy = trustedProxy + "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(trustedProxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %s: %w", trustedProxy, err)
+		}
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
+// newSessionMetadata returns the metadata for a new session created by the provided request.
+func newSessionMetadata(r *http.Request, duration time.Duration, trustedProxies []*net.IPNet) db.SessionMetadata {
+	return db.SessionMetadata{
+		UserAgent: r.UserAgent(),
+		IP:        getClientIP(r, trustedProxies),
+		ExpiresAt: time.Now().Add(duration),
+	}
+}
+
+// getSessionID returns the id of the session from the "kobs.token" cookie of the provided request. If the request
+// doesn't contain a valid token an empty string is returned.
+func (c *client) getSessionID(r *http.Request) string {
+	token, err := r.Cookie("kobs.token")
+	if err != nil {
+		return ""
+	}
+
+	tokenClaims, err := jwt.ValidateToken[Token](token.Value, c.config.Session.Token)
+	if err != nil {
+		return ""
+	}
+
+	return tokenClaims.SessionID
+}
+
+// getSessionsHandler returns all active sessions of the authenticated user. Users which are allowed to get the
+// `sessions` resource in all clusters and namespaces can also get the sessions of other users via the `user` parameter.
+func (c *client) getSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := authContext.MustGetUser(ctx)
+	userID := r.URL.Query().Get("user")
+
+	if userID == "" {
+		userID = user.ID
+	}
+
+	if userID != user.ID && !user.HasResourceAccess("*", "*", "sessions", "get") {
+		log.Warn(ctx, "The user is not authorized to view the sessions of other users", zap.String("user", userID))
+		errresponse.Render(w, r, http.StatusForbidden, "You are not allowed to view the sessions of other users")
+		return
+	}
+
+	sessions, err := c.dbClient.GetSessions(ctx, userID)
+	if err != nil {
+		log.Warn(ctx, "Failed to get sessions", zap.Error(err))
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get sessions")
+		return
+	}
+
+	currentSessionID := c.getSessionID(r)
+
+	response := make([]sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, sessionResponse{
+			ID:        session.ID,
+			UserAgent: session.UserAgent,
+			IP:        session.IP,
+			CreatedAt: session.CreatedAt,
+			UpdatedAt: session.UpdatedAt,
+			ExpiresAt: session.ExpiresAt,
+			Current:   session.ID == currentSessionID,
+		})
+	}
+
+	render.JSON(w, r, response)
+}
+
+// deleteSessionsHandler revokes sessions. When the `id` parameter is provided only the session with this id is revoked,
+// otherwise all sessions of the user from the `user` parameter are revoked. If the `user` parameter is also not
+// provided, all sessions of the authenticated user are revoked.
+//
+// Users which are allowed to delete the `sessions` resource in all clusters and namespaces can also revoke the sessions
+// of other users, e.g. to force the logout of a user.
+func (c *client) deleteSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := authContext.MustGetUser(ctx)
+	id := r.URL.Query().Get("id")
+	userID := r.URL.Query().Get("user")
+
+	isAdmin := user.HasResourceAccess("*", "*", "sessions", "delete")
+
+	if id != "" {
+		session, err := c.dbClient.GetSession(ctx, id)
+		if err != nil {
+			if errors.Is(err, db.ErrSessionNotFound) {
+				log.Warn(ctx, "Session not found", zap.Error(err))
+				errresponse.Render(w, r, http.StatusNotFound, "Session not found")
+				return
+			}
+
+			log.Warn(ctx, "Failed to get session", zap.Error(err))
+			errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get session")
+			return
+		}
+
+		if session.User.ID != user.ID && !isAdmin {
+			log.Warn(ctx, "The user is not authorized to revoke the sessions of other users", zap.String("user", session.User.ID))
+			errresponse.Render(w, r, http.StatusForbidden, "You are not allowed to revoke the sessions of other users")
+			return
+		}
+
+		if err := c.dbClient.DeleteSession(ctx, id); err != nil {
+			log.Warn(ctx, "Failed to delete session", zap.Error(err))
+			errresponse.Render(w, r, http.StatusInternalServerError, "Failed to delete session")
+			return
+		}
+
+		render.Status(r, http.StatusNoContent)
+		render.JSON(w, r, nil)
+		return
+	}
+
+	if userID == "" {
+		userID = user.ID
+	}
+
+	if userID != user.ID && !isAdmin {
+		log.Warn(ctx, "The user is not authorized to revoke the sessions of other users", zap.String("user", userID))
+		errresponse.Render(w, r, http.StatusForbidden, "You are not allowed to revoke the sessions of other users")
+		return
+	}
+
+	if err := c.dbClient.DeleteSessions(ctx, userID); err != nil {
+		log.Warn(ctx, "Failed to delete sessions", zap.Error(err), zap.String("user", userID))
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to delete sessions")
+		return
+	}
+
+	render.Status(r, http.StatusNoContent)
+	render.JSON(w, r, nil)
+}
@@ -0,0 +1,279 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/auth/jwt"
+	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/utils"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSessionMetadata(t *testing.T) {
+	trustedProxies, err := parseTrustedProxies([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	t.Run("should use remote address", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/signin", nil)
+		req.RemoteAddr = "10.0.0.1:12345"
+		req.Header.Set("User-Agent", "curl/8.0.0")
+
+		metadata := newSessionMetadata(req, time.Hour, nil)
+		require.Equal(t, "10.0.0.1", metadata.IP)
+		require.Equal(t, "curl/8.0.0", metadata.UserAgent)
+		require.WithinDuration(t, time.Now().Add(time.Hour), metadata.ExpiresAt, time.Minute)
+	})
+
+	t.Run("should ignore forwarded for header from untrusted proxy", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/signin", nil)
+		req.RemoteAddr = "192.168.0.2:12345"
+		req.Header.Set("X-Forwarded-For", "192.168.0.1")
+
+		metadata := newSessionMetadata(req, time.Hour, trustedProxies)
+		require.Equal(t, "192.168.0.2", metadata.IP)
+	})
+
+	t.Run("should use forwarded for header from trusted proxy", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/signin", nil)
+		req.RemoteAddr = "10.0.0.1:12345"
+		req.Header.Set("X-Forwarded-For", "1.1.1.1, 192.168.0.1, 10.0.0.2")
+
+		metadata := newSessionMetadata(req, time.Hour, trustedProxies)
+		require.Equal(t, "192.168.0.1", metadata.IP)
+	})
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	t.Run("should parse cidrs and ip addresses", func(t *testing.T) {
+		trustedProxies, err := parseTrustedProxies([]string{"10.0.0.0/8", "192.168.0.1", "::1"})
+		require.NoError(t, err)
+		require.Len(t, trustedProxies, 3)
+		require.True(t, isTrustedProxy("10.1.2.3", trustedProxies))
+		require.True(t, isTrustedProxy("192.168.0.1", trustedProxies))
+		require.True(t, isTrustedProxy("::1", trustedProxies))
+		require.False(t, isTrustedProxy("192.168.0.2", trustedProxies))
+	})
+
+	t.Run("should fail for invalid proxy", func(t *testing.T) {
+		_, err := parseTrustedProxies([]string{"invalid"})
+		require.Error(t, err)
+	})
+}
+
+func TestGetSessionsHandler(t *testing.T) {
+	createdAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	adminUser := authContext.User{ID: "admin@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"*"}, Namespaces: []string{"*"}, Resources: []string{"sessions"}, Verbs: []string{"*"}}}}}
+
+	for _, tt := range []struct {
+		name               string
+		user               authContext.User
+		url                string
+		prepare            func(dbClient *db.MockClient)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "should fail when user is not allowed to view sessions of other users",
+			user:               authContext.User{ID: "user@kobs.io"},
+			url:                "/sessions?user=admin@kobs.io",
+			expectedStatusCode: http.StatusForbidden,
+			expectedBody:       `{"errors": ["You are not allowed to view the sessions of other users"]}`,
+		},
+		{
+			name: "should fail when sessions could not be returned",
+			user: authContext.User{ID: "user@kobs.io"},
+			url:  "/sessions",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetSessions(gomock.Any(), "user@kobs.io").Return(nil, fmt.Errorf("unexpected error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `{"errors": ["Failed to get sessions"]}`,
+		},
+		{
+			name: "should return own sessions",
+			user: authContext.User{ID: "user@kobs.io"},
+			url:  "/sessions",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetSessions(gomock.Any(), "user@kobs.io").Return([]db.Session{
+					{ID: "session1", User: authContext.User{ID: "user@kobs.io"}, SessionMetadata: db.SessionMetadata{UserAgent: "curl/8.0.0", IP: "127.0.0.1", ExpiresAt: createdAt.Add(time.Hour)}, CreatedAt: createdAt, UpdatedAt: createdAt},
+					{ID: "session2", User: authContext.User{ID: "user@kobs.io"}, SessionMetadata: db.SessionMetadata{ExpiresAt: createdAt.Add(time.Hour)}, CreatedAt: createdAt, UpdatedAt: createdAt},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `[{"id": "session1", "userAgent": "curl/8.0.0", "ip": "127.0.0.1", "createdAt": "2023-01-01T00:00:00Z", "updatedAt": "2023-01-01T00:00:00Z", "expiresAt": "2023-01-01T01:00:00Z", "current": true}, {"id": "session2", "userAgent": "", "ip": "", "createdAt": "2023-01-01T00:00:00Z", "updatedAt": "2023-01-01T00:00:00Z", "expiresAt": "2023-01-01T01:00:00Z", "current": false}]`,
+		},
+		{
+			name: "should return sessions of other user",
+			user: adminUser,
+			url:  "/sessions?user=user@kobs.io",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetSessions(gomock.Any(), "user@kobs.io").Return(nil, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `[]`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dbClient := db.NewMockClient(ctrl)
+			if tt.prepare != nil {
+				tt.prepare(dbClient)
+			}
+
+			client := client{config: Config{Session: SessionConfig{Token: "1234"}}, dbClient: dbClient}
+
+			token, err := jwt.CreateToken(&Token{SessionID: "session1"}, client.config.Session.Token, time.Hour)
+			require.NoError(t, err)
+
+			ctx := context.WithValue(context.Background(), authContext.UserKey, tt.user)
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, tt.url, nil)
+			req.AddCookie(&http.Cookie{Name: "kobs.token", Value: token})
+			w := httptest.NewRecorder()
+			client.getSessionsHandler(w, req)
+
+			utils.AssertStatusEq(t, w, tt.expectedStatusCode)
+			utils.AssertJSONEq(t, w, tt.expectedBody)
+		})
+	}
+}
+
+func TestDeleteSessionsHandler(t *testing.T) {
+	adminUser := authContext.User{ID: "admin@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"*"}, Namespaces: []string{"*"}, Resources: []string{"sessions"}, Verbs: []string{"delete"}}}}}
+
+	for _, tt := range []struct {
+		name               string
+		user               authContext.User
+		url                string
+		prepare            func(dbClient *db.MockClient)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name: "should fail when session is not found",
+			user: authContext.User{ID: "user@kobs.io"},
+			url:  "/sessions?id=session1",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetSession(gomock.Any(), "session1").Return(nil, db.ErrSessionNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `{"errors": ["Session not found"]}`,
+		},
+		{
+			name: "should fail when session could not be returned",
+			user: authContext.User{ID: "user@kobs.io"},
+			url:  "/sessions?id=session1",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetSession(gomock.Any(), "session1").Return(nil, fmt.Errorf("unexpected error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `{"errors": ["Failed to get session"]}`,
+		},
+		{
+			name: "should fail when session belongs to other user",
+			user: authContext.User{ID: "user@kobs.io"},
+			url:  "/sessions?id=session1",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetSession(gomock.Any(), "session1").Return(&db.Session{ID: "session1", User: authContext.User{ID: "admin@kobs.io"}}, nil)
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedBody:       `{"errors": ["You are not allowed to revoke the sessions of other users"]}`,
+		},
+		{
+			name: "should fail when session could not be deleted",
+			user: authContext.User{ID: "user@kobs.io"},
+			url:  "/sessions?id=session1",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetSession(gomock.Any(), "session1").Return(&db.Session{ID: "session1", User: authContext.User{ID: "user@kobs.io"}}, nil)
+				dbClient.EXPECT().DeleteSession(gomock.Any(), "session1").Return(fmt.Errorf("unexpected error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `{"errors": ["Failed to delete session"]}`,
+		},
+		{
+			name: "should delete own session",
+			user: authContext.User{ID: "user@kobs.io"},
+			url:  "/sessions?id=session1",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetSession(gomock.Any(), "session1").Return(&db.Session{ID: "session1", User: authContext.User{ID: "user@kobs.io"}}, nil)
+				dbClient.EXPECT().DeleteSession(gomock.Any(), "session1").Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+			expectedBody:       `null`,
+		},
+		{
+			name: "should delete session of other user",
+			user: adminUser,
+			url:  "/sessions?id=session1",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetSession(gomock.Any(), "session1").Return(&db.Session{ID: "session1", User: authContext.User{ID: "user@kobs.io"}}, nil)
+				dbClient.EXPECT().DeleteSession(gomock.Any(), "session1").Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+			expectedBody:       `null`,
+		},
+		{
+			name:               "should fail when user is not allowed to revoke sessions of other users",
+			user:               authContext.User{ID: "user@kobs.io"},
+			url:                "/sessions?user=admin@kobs.io",
+			expectedStatusCode: http.StatusForbidden,
+			expectedBody:       `{"errors": ["You are not allowed to revoke the sessions of other users"]}`,
+		},
+		{
+			name: "should fail when sessions could not be deleted",
+			user: authContext.User{ID: "user@kobs.io"},
+			url:  "/sessions",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().DeleteSessions(gomock.Any(), "user@kobs.io").Return(fmt.Errorf("unexpected error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `{"errors": ["Failed to delete sessions"]}`,
+		},
+		{
+			name: "should delete all own sessions",
+			user: authContext.User{ID: "user@kobs.io"},
+			url:  "/sessions",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().DeleteSessions(gomock.Any(), "user@kobs.io").Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+			expectedBody:       `null`,
+		},
+		{
+			name: "should delete all sessions of other user",
+			user: adminUser,
+			url:  "/sessions?user=user@kobs.io",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().DeleteSessions(gomock.Any(), "user@kobs.io").Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+			expectedBody:       `null`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dbClient := db.NewMockClient(ctrl)
+			if tt.prepare != nil {
+				tt.prepare(dbClient)
+			}
+
+			client := client{dbClient: dbClient}
+
+			ctx := context.WithValue(context.Background(), authContext.UserKey, tt.user)
+			req, _ := http.NewRequestWithContext(ctx, http.MethodDelete, tt.url, nil)
+			w := httptest.NewRecorder()
+			client.deleteSessionsHandler(w, req)
+
+			utils.AssertStatusEq(t, w, tt.expectedStatusCode)
+			utils.AssertJSONEq(t, w, tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+)
+
+// getStaticUser returns the local user with the provided id. The user is looked up in the list of users from the hub
+// configuration and in the configured htpasswd file. If the user is found in both sources, the password from the
+// htpasswd file is used, while the teams and permissions are taken from the user in the configuration. If no local
+// user is found nil is returned.
+//
+// The htpasswd file is read on each call, so that passwords can be changed without restarting the hub.
+func (c *client) getStaticUser(id string) (*userv1.UserSpec, error) {
+	var user *userv1.UserSpec
+
+	for _, u := range c.config.Static.Users {
+		if u.ID == id {
+			staticUser := u
+			user = &staticUser
+			break
+		}
+	}
+
+	if c.config.Static.HtpasswdFile != "" {
+		password, err := readHtpasswdFile(c.config.Static.HtpasswdFile, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if password != "" {
+			if user == nil {
+				user = &userv1.UserSpec{ID: id}
+			}
+			user.Password = password
+		}
+	}
+
+	if user == nil || user.Password == "" {
+		return nil, nil
+	}
+
+	return user, nil
+}
+
+// readHtpasswdFile returns the password hash for the user with the provided id from the htpasswd file. Only bcrypt
+// hashes are supported, so that an error is returned when the hash for the user uses another algorithm. If the user
+// is not found an empty string is returned.
+func readHtpasswdFile(path, id string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		user, hash, ok := strings.Cut(line, ":")
+		if !ok || user != id {
+			continue
+		}
+
+		if !strings.HasPrefix(hash, "$2a$") && !strings.HasPrefix(hash, "$2b$") && !strings.HasPrefix(hash, "$2y$") {
+			return "", fmt.Errorf("unsupported password hash for user %s, only bcrypt is supported", id)
+		}
+
+		return hash, nil
+	}
+
+	return "", scanner.Err()
+}
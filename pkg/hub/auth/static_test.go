@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStaticUser(t *testing.T) {
+	htpasswdFile := path.Join(t.TempDir(), "htpasswd")
+	err := os.WriteFile(htpasswdFile, []byte(`# local users
+admin:$2y$10$o2AokncpHCowCvDJ2rOp.e18ThDg0mlaLj5QMsjtwEEBtrEn7IYRS
+
+user:$2y$10$UPPBv.HThEllgJZINbFwYOsru62d.LT0EqG3XLug2pG81IvemopH2
+md5:$apr1$r31.....$HqJZimcKQFAMYayBlzkrA/
+`), 0600)
+	require.NoError(t, err)
+
+	c := client{config: Config{Static: StaticConfig{
+		HtpasswdFile: htpasswdFile,
+		Users: []userv1.UserSpec{
+			{ID: "admin", Teams: []string{"admins"}, Permissions: userv1.Permissions{Teams: []string{"*"}}},
+			{ID: "static", Password: "$2y$10$o2AokncpHCowCvDJ2rOp.e18ThDg0mlaLj5QMsjtwEEBtrEn7IYRS"},
+			{ID: "nopassword"},
+		},
+	}}}
+
+	t.Run("should merge user from htpasswd file and configuration", func(t *testing.T) {
+		user, err := c.getStaticUser("admin")
+		require.NoError(t, err)
+		require.Equal(t, &userv1.UserSpec{ID: "admin", Password: "$2y$10$o2AokncpHCowCvDJ2rOp.e18ThDg0mlaLj5QMsjtwEEBtrEn7IYRS", Teams: []string{"admins"}, Permissions: userv1.Permissions{Teams: []string{"*"}}}, user)
+		require.Empty(t, c.config.Static.Users[0].Password)
+	})
+
+	t.Run("should return user from htpasswd file", func(t *testing.T) {
+		user, err := c.getStaticUser("user")
+		require.NoError(t, err)
+		require.Equal(t, &userv1.UserSpec{ID: "user", Password: "$2y$10$UPPBv.HThEllgJZINbFwYOsru62d.LT0EqG3XLug2pG81IvemopH2"}, user)
+	})
+
+	t.Run("should return user from configuration", func(t *testing.T) {
+		user, err := c.getStaticUser("static")
+		require.NoError(t, err)
+		require.Equal(t, "static", user.ID)
+	})
+
+	t.Run("should not return user without password", func(t *testing.T) {
+		user, err := c.getStaticUser("nopassword")
+		require.NoError(t, err)
+		require.Nil(t, user)
+	})
+
+	t.Run("should not return unknown user", func(t *testing.T) {
+		user, err := c.getStaticUser("unknown")
+		require.NoError(t, err)
+		require.Nil(t, user)
+	})
+
+	t.Run("should fail for unsupported hash", func(t *testing.T) {
+		_, err := c.getStaticUser("md5")
+		require.Error(t, err)
+	})
+
+	t.Run("should fail when htpasswd file does not exist", func(t *testing.T) {
+		c := client{config: Config{Static: StaticConfig{HtpasswdFile: path.Join(t.TempDir(), "htpasswd")}}}
+		_, err := c.getStaticUser("admin")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/instrument/log"
+	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
+
+	"github.com/go-chi/render"
+	"go.uber.org/zap"
+)
+
+// apiTokenPrefix is the prefix of all personal API tokens. A token has the format "kobs_<id>_<secret>", where the id
+// is used to look up the token in the database and the secret is compared against the saved hash.
+const apiTokenPrefix = "kobs_"
+
+// defaultAPITokenExpiresIn is the duration for how long a personal API token is valid, when the user doesn't provide
+// a duration while creating the token.
+const defaultAPITokenExpiresIn = 30 * 24 * time.Hour
+
+var (
+	// ErrInvalidAPIToken is returned when the provided personal API token has an invalid format or when the secret of
+	// the token doesn't match the saved hash.
+	ErrInvalidAPIToken = fmt.Errorf("invalid api token")
+)
+
+type createTokenRequest struct {
+	Name        string              `json:"name"`
+	ExpiresIn   string              `json:"expiresIn"`
+	Permissions *userv1.Permissions `json:"permissions"`
+}
+
+type tokenResponse struct {
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	Token       string              `json:"token,omitempty"`
+	Permissions *userv1.Permissions `json:"permissions,omitempty"`
+	CreatedAt   time.Time           `json:"createdAt"`
+	ExpiresAt   time.Time           `json:"expiresAt"`
+}
+
+// newTokenResponse returns the API representation of a saved personal API token. The hash of the token is never
+// returned to the user.
+func newTokenResponse(apiToken db.APIToken) tokenResponse {
+	return tokenResponse{
+		ID:          apiToken.ID,
+		Name:        apiToken.Name,
+		Permissions: apiToken.User.Scope,
+		CreatedAt:   apiToken.CreatedAt,
+		ExpiresAt:   apiToken.ExpiresAt,
+	}
+}
+
+// hashAPITokenSecret returns the hex encoded SHA-256 hash of the provided secret, which is saved in the database.
+func hashAPITokenSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// randomHex returns n random bytes as hex encoded string.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// getAPIToken returns the personal API token from the "Authorization" header of the provided request. The second
+// return value is false, when the header doesn't contain a bearer token with the prefix of our personal API tokens.
+func getAPIToken(r *http.Request) (string, bool) {
+	bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || !strings.HasPrefix(bearer, apiTokenPrefix) {
+		return "", false
+	}
+
+	return bearer, true
+}
+
+// validateAPIToken parses the provided personal API token and returns the corresponding token from the database, when
+// the secret of the token matches the saved hash.
+func (c *client) validateAPIToken(ctx context.Context, token string) (*db.APIToken, error) {
+	id, secret, ok := strings.Cut(strings.TrimPrefix(token, apiTokenPrefix), "_")
+	if !strings.HasPrefix(token, apiTokenPrefix) || !ok || id == "" || secret == "" {
+		return nil, ErrInvalidAPIToken
+	}
+
+	apiToken, err := c.dbClient.GetAPIToken(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAPITokenSecret(secret)), []byte(apiToken.Hash)) != 1 {
+		return nil, ErrInvalidAPIToken
+	}
+
+	return apiToken, nil
+}
+
+// getTokensHandler returns all personal API tokens of the authenticated user.
+func (c *client) getTokensHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := authContext.MustGetUser(ctx)
+
+	apiTokens, err := c.dbClient.GetAPITokens(ctx, user.ID)
+	if err != nil {
+		log.Warn(ctx, "Failed to get api tokens", zap.Error(err))
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get api tokens")
+		return
+	}
+
+	tokens := make([]tokenResponse, 0, len(apiTokens))
+	for _, apiToken := range apiTokens {
+		tokens = append(tokens, newTokenResponse(apiToken))
+	}
+
+	render.JSON(w, r, tokens)
+}
+
+// createTokenHandler creates a new personal API token for the authenticated user. The token can be scoped to a subset
+// of the users permissions via the optional "permissions" field. The token is only returned once in the response,
+// because we only save the hash of the token in the database.
+//
+// It is not possible to create a new token with a personal API token, so that a leaked token can not be used to create
+// additional tokens. The token contains a copy of the users permissions at the time the token was created, so that a
+// token must be revoked when the permissions of the user are changed.
+func (c *client) createTokenHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := authContext.MustGetUser(ctx)
+
+	if _, ok := getAPIToken(r); ok {
+		log.Warn(ctx, "Api tokens can not be created with an api token")
+		errresponse.Render(w, r, http.StatusForbidden, "Api tokens can not be created with an api token")
+		return
+	}
+
+	var data createTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		log.Warn(ctx, "Failed to decode request body", zap.Error(err))
+		errresponse.Render(w, r, http.StatusBadRequest, "Failed to decode request body")
+		return
+	}
+
+	if data.Name == "" {
+		log.Warn(ctx, "Name is required")
+		errresponse.Render(w, r, http.StatusBadRequest, "Name is required")
+		return
+	}
+
+	maxExpiresIn := c.config.Tokens.MaxExpiresIn.Duration
+
+	expiresIn := defaultAPITokenExpiresIn
+	if maxExpiresIn > 0 && expiresIn > maxExpiresIn {
+		expiresIn = maxExpiresIn
+	}
+	if data.ExpiresIn != "" {
+		parsedExpiresIn, err := time.ParseDuration(data.ExpiresIn)
+		if err != nil || parsedExpiresIn <= 0 {
+			log.Warn(ctx, "Invalid 'expiresIn' value", zap.Error(err), zap.String("expiresIn", data.ExpiresIn))
+			errresponse.Render(w, r, http.StatusBadRequest, "Invalid 'expiresIn' value")
+			return
+		}
+		if maxExpiresIn > 0 && parsedExpiresIn > maxExpiresIn {
+			log.Warn(ctx, "The 'expiresIn' value exceeds the maximum lifetime", zap.String("expiresIn", data.ExpiresIn), zap.Duration("maxExpiresIn", maxExpiresIn))
+			errresponse.Render(w, r, http.StatusBadRequest, fmt.Sprintf("The 'expiresIn' value must not be greater than %s", maxExpiresIn))
+			return
+		}
+		expiresIn = parsedExpiresIn
+	}
+
+	id, err := randomHex(12)
+	if err != nil {
+		log.Warn(ctx, "Failed to create api token", zap.Error(err))
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to create api token")
+		return
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		log.Warn(ctx, "Failed to create api token", zap.Error(err))
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to create api token")
+		return
+	}
+
+	tokenUser := *user
+	tokenUser.Scope = data.Permissions
+
+	now := time.Now().UTC()
+	apiToken := db.APIToken{
+		ID:        id,
+		Name:      data.Name,
+		Hash:      hashAPITokenSecret(secret),
+		User:      tokenUser,
+		CreatedAt: now,
+		ExpiresAt: now.Add(expiresIn),
+	}
+
+	if err := c.dbClient.CreateAPIToken(ctx, &apiToken); err != nil {
+		log.Warn(ctx, "Failed to create api token", zap.Error(err))
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to create api token")
+		return
+	}
+
+	response := newTokenResponse(apiToken)
+	response.Token = apiTokenPrefix + id + "_" + secret
+
+	render.JSON(w, r, response)
+}
+
+// deleteTokenHandler revokes the personal API token with the provided id. A user can only revoke their own tokens.
+func (c *client) deleteTokenHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := authContext.MustGetUser(ctx)
+	id := r.URL.Query().Get("id")
+
+	if err := c.dbClient.DeleteAPIToken(ctx, user.ID, id); err != nil {
+		if errors.Is(err, db.ErrAPITokenNotFound) {
+			log.Warn(ctx, "Api token not found", zap.Error(err))
+			errresponse.Render(w, r, http.StatusNotFound, "Api token not found")
+			return
+		}
+
+		log.Warn(ctx, "Failed to delete api token", zap.Error(err))
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to delete api token")
+		return
+	}
+
+	render.Status(r, http.StatusNoContent)
+	render.JSON(w, r, nil)
+}
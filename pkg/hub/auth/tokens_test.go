@@ -0,0 +1,373 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/auth/jwt"
+	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/utils"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAuthMiddlewareAPIToken(t *testing.T) {
+	user := authContext.User{
+		ID:          "test@kobs.io",
+		Permissions: userv1.Permissions{Teams: []string{"*"}},
+		Scope:       &userv1.Permissions{Teams: []string{"team1"}},
+	}
+
+	var newRequest = func(authorization string) *http.Request {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", authorization)
+		return req
+	}
+
+	t.Run("should succeed for valid api token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		dbClient.EXPECT().GetAPIToken(gomock.Any(), "token1").Return(&db.APIToken{ID: "token1", Hash: hashAPITokenSecret("secret"), User: user}, nil)
+
+		client := client{dbClient: dbClient}
+		nxt := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userFromCtx, userIsSet := r.Context().Value(authContext.UserKey).(authContext.User)
+			require.True(t, userIsSet)
+			require.Equal(t, user, userFromCtx)
+			require.True(t, userFromCtx.HasTeamAccess("team1"))
+			require.False(t, userFromCtx.HasTeamAccess("team2"))
+			w.WriteHeader(http.StatusAccepted)
+		})
+
+		w := httptest.NewRecorder()
+		client.MiddlewareHandler(nxt).ServeHTTP(w, newRequest("Bearer kobs_token1_secret"))
+		utils.AssertStatusEq(t, w, http.StatusAccepted)
+	})
+
+	t.Run("should use cookie for other authorization headers", func(t *testing.T) {
+		sessionID := primitive.NewObjectID().Hex()
+
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		dbClient.EXPECT().GetSession(gomock.Any(), sessionID).Return(&db.Session{ID: sessionID, User: user}, nil).Times(2)
+
+		client := client{config: Config{Session: SessionConfig{Token: "1234"}}, dbClient: dbClient}
+		nxt := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		})
+
+		token, err := jwt.CreateToken(&Token{SessionID: sessionID}, client.config.Session.Token, time.Hour)
+		require.NoError(t, err)
+
+		for _, authorization := range []string{"Basic dGVzdDp0ZXN0", "Bearer token1"} {
+			req := newRequest(authorization)
+			req.AddCookie(&http.Cookie{Name: "kobs.token", Value: token})
+
+			w := httptest.NewRecorder()
+			client.MiddlewareHandler(nxt).ServeHTTP(w, req)
+			utils.AssertStatusEq(t, w, http.StatusAccepted)
+		}
+	})
+
+	t.Run("should fail for other authorization headers without cookie", func(t *testing.T) {
+		client := client{}
+
+		w := httptest.NewRecorder()
+		client.MiddlewareHandler(nil).ServeHTTP(w, newRequest("Basic dGVzdDp0ZXN0"))
+		utils.AssertStatusEq(t, w, http.StatusUnauthorized)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to get token from cookie"]}`)
+	})
+
+	t.Run("should fail for invalid api token format", func(t *testing.T) {
+		client := client{}
+
+		w := httptest.NewRecorder()
+		client.MiddlewareHandler(nil).ServeHTTP(w, newRequest("Bearer kobs_token1"))
+		utils.AssertStatusEq(t, w, http.StatusUnauthorized)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to validate api token"]}`)
+	})
+
+	t.Run("should fail when api token is not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		dbClient.EXPECT().GetAPIToken(gomock.Any(), "token1").Return(nil, db.ErrAPITokenNotFound)
+
+		client := client{dbClient: dbClient}
+
+		w := httptest.NewRecorder()
+		client.MiddlewareHandler(nil).ServeHTTP(w, newRequest("Bearer kobs_token1_secret"))
+		utils.AssertStatusEq(t, w, http.StatusUnauthorized)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to validate api token"]}`)
+	})
+
+	t.Run("should fail for wrong secret", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		dbClient.EXPECT().GetAPIToken(gomock.Any(), "token1").Return(&db.APIToken{ID: "token1", Hash: hashAPITokenSecret("secret"), User: user}, nil)
+
+		client := client{dbClient: dbClient}
+
+		w := httptest.NewRecorder()
+		client.MiddlewareHandler(nil).ServeHTTP(w, newRequest("Bearer kobs_token1_wrongsecret"))
+		utils.AssertStatusEq(t, w, http.StatusUnauthorized)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to validate api token"]}`)
+	})
+}
+
+func TestGetTokensHandler(t *testing.T) {
+	user := authContext.User{ID: "test@kobs.io"}
+	createdAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name               string
+		prepare            func(dbClient *db.MockClient)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name: "should fail when tokens could not be returned",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetAPITokens(gomock.Any(), "test@kobs.io").Return(nil, fmt.Errorf("unexpected error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `{"errors": ["Failed to get api tokens"]}`,
+		},
+		{
+			name: "should return tokens without hash",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetAPITokens(gomock.Any(), "test@kobs.io").Return([]db.APIToken{{ID: "token1", Name: "ci", Hash: "hash1", User: user, CreatedAt: createdAt, ExpiresAt: createdAt.Add(time.Hour)}}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `[{"id": "token1", "name": "ci", "createdAt": "2023-01-01T00:00:00Z", "expiresAt": "2023-01-01T01:00:00Z"}]`,
+		},
+		{
+			name: "should return empty list",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().GetAPITokens(gomock.Any(), "test@kobs.io").Return(nil, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `[]`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dbClient := db.NewMockClient(ctrl)
+			tt.prepare(dbClient)
+
+			client := client{dbClient: dbClient}
+
+			ctx := context.WithValue(context.Background(), authContext.UserKey, user)
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/tokens", nil)
+			w := httptest.NewRecorder()
+			client.getTokensHandler(w, req)
+
+			utils.AssertStatusEq(t, w, tt.expectedStatusCode)
+			utils.AssertJSONEq(t, w, tt.expectedBody)
+		})
+	}
+}
+
+func TestCreateTokenHandler(t *testing.T) {
+	user := authContext.User{ID: "test@kobs.io", Permissions: userv1.Permissions{Teams: []string{"*"}}}
+
+	var newRequest = func(body string) *http.Request {
+		ctx := context.WithValue(context.Background(), authContext.UserKey, user)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/tokens", strings.NewReader(body))
+		return req
+	}
+
+	t.Run("should fail when request was authenticated via api token", func(t *testing.T) {
+		client := client{}
+
+		req := newRequest(`{"name": "ci"}`)
+		req.Header.Set("Authorization", "Bearer kobs_token1_secret")
+		w := httptest.NewRecorder()
+		client.createTokenHandler(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusForbidden)
+		utils.AssertJSONEq(t, w, `{"errors": ["Api tokens can not be created with an api token"]}`)
+	})
+
+	t.Run("should fail for invalid request body", func(t *testing.T) {
+		client := client{}
+
+		w := httptest.NewRecorder()
+		client.createTokenHandler(w, newRequest(`[]`))
+
+		utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to decode request body"]}`)
+	})
+
+	t.Run("should fail when name is missing", func(t *testing.T) {
+		client := client{}
+
+		w := httptest.NewRecorder()
+		client.createTokenHandler(w, newRequest(`{}`))
+
+		utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		utils.AssertJSONEq(t, w, `{"errors": ["Name is required"]}`)
+	})
+
+	t.Run("should fail for invalid expires in value", func(t *testing.T) {
+		client := client{}
+
+		w := httptest.NewRecorder()
+		client.createTokenHandler(w, newRequest(`{"name": "ci", "expiresIn": "-1h"}`))
+
+		utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		utils.AssertJSONEq(t, w, `{"errors": ["Invalid 'expiresIn' value"]}`)
+	})
+
+	t.Run("should fail when expires in value exceeds the maximum", func(t *testing.T) {
+		client := client{config: Config{Tokens: TokensConfig{MaxExpiresIn: Duration{24 * time.Hour}}}}
+
+		w := httptest.NewRecorder()
+		client.createTokenHandler(w, newRequest(`{"name": "ci", "expiresIn": "876000h"}`))
+
+		utils.AssertStatusEq(t, w, http.StatusBadRequest)
+		utils.AssertJSONEq(t, w, `{"errors": ["The 'expiresIn' value must not be greater than 24h0m0s"]}`)
+	})
+
+	t.Run("should use maximum when it is lower than the default", func(t *testing.T) {
+		var savedToken *db.APIToken
+
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		dbClient.EXPECT().CreateAPIToken(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, token *db.APIToken) error {
+			savedToken = token
+			return nil
+		})
+
+		client := client{config: Config{Tokens: TokensConfig{MaxExpiresIn: Duration{24 * time.Hour}}}, dbClient: dbClient}
+
+		w := httptest.NewRecorder()
+		client.createTokenHandler(w, newRequest(`{"name": "ci"}`))
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+		require.Equal(t, 24*time.Hour, savedToken.ExpiresAt.Sub(savedToken.CreatedAt))
+	})
+
+	t.Run("should create token with basic authorization header", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		dbClient.EXPECT().CreateAPIToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		client := client{dbClient: dbClient}
+
+		req := newRequest(`{"name": "ci"}`)
+		req.Header.Set("Authorization", "Basic dGVzdDp0ZXN0")
+		w := httptest.NewRecorder()
+		client.createTokenHandler(w, req)
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+	})
+
+	t.Run("should fail when token could not be saved", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		dbClient.EXPECT().CreateAPIToken(gomock.Any(), gomock.Any()).Return(fmt.Errorf("unexpected error"))
+
+		client := client{dbClient: dbClient}
+
+		w := httptest.NewRecorder()
+		client.createTokenHandler(w, newRequest(`{"name": "ci"}`))
+
+		utils.AssertStatusEq(t, w, http.StatusInternalServerError)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to create api token"]}`)
+	})
+
+	t.Run("should create token", func(t *testing.T) {
+		var savedToken *db.APIToken
+
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		dbClient.EXPECT().CreateAPIToken(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, token *db.APIToken) error {
+			savedToken = token
+			return nil
+		})
+
+		client := client{dbClient: dbClient}
+
+		w := httptest.NewRecorder()
+		client.createTokenHandler(w, newRequest(`{"name": "ci", "expiresIn": "1h", "permissions": {"teams": ["team1"]}}`))
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+
+		var response tokenResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		require.Equal(t, savedToken.ID, response.ID)
+		require.Equal(t, "ci", response.Name)
+		require.Equal(t, []string{"team1"}, response.Permissions.Teams)
+		require.Equal(t, time.Hour, savedToken.ExpiresAt.Sub(savedToken.CreatedAt))
+		require.Equal(t, user.ID, savedToken.User.ID)
+		require.Equal(t, user.Permissions, savedToken.User.Permissions)
+		require.Equal(t, []string{"team1"}, savedToken.User.Scope.Teams)
+
+		dbClient.EXPECT().GetAPIToken(gomock.Any(), savedToken.ID).Return(savedToken, nil)
+		validatedToken, err := client.validateAPIToken(context.Background(), response.Token)
+		require.NoError(t, err)
+		require.Equal(t, savedToken, validatedToken)
+	})
+}
+
+func TestDeleteTokenHandler(t *testing.T) {
+	user := authContext.User{ID: "test@kobs.io"}
+
+	for _, tt := range []struct {
+		name               string
+		prepare            func(dbClient *db.MockClient)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name: "should fail when token is not found",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().DeleteAPIToken(gomock.Any(), "test@kobs.io", "token1").Return(db.ErrAPITokenNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `{"errors": ["Api token not found"]}`,
+		},
+		{
+			name: "should fail when token could not be deleted",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().DeleteAPIToken(gomock.Any(), "test@kobs.io", "token1").Return(fmt.Errorf("unexpected error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `{"errors": ["Failed to delete api token"]}`,
+		},
+		{
+			name: "should delete token",
+			prepare: func(dbClient *db.MockClient) {
+				dbClient.EXPECT().DeleteAPIToken(gomock.Any(), "test@kobs.io", "token1").Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+			expectedBody:       `null`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dbClient := db.NewMockClient(ctrl)
+			tt.prepare(dbClient)
+
+			client := client{dbClient: dbClient}
+
+			ctx := context.WithValue(context.Background(), authContext.UserKey, user)
+			req, _ := http.NewRequestWithContext(ctx, http.MethodDelete, "/tokens?id=token1", nil)
+			w := httptest.NewRecorder()
+			client.deleteTokenHandler(w, req)
+
+			utils.AssertStatusEq(t, w, tt.expectedStatusCode)
+			utils.AssertJSONEq(t, w, tt.expectedBody)
+		})
+	}
+}
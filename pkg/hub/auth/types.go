@@ -7,8 +7,6 @@ import (
 	dashboardv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/dashboard/v1"
 	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
 	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
-
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Duration is our custom type for the session duration in the auth configuration. This is required so that we can
@@ -41,16 +39,33 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 type Config struct {
 	OIDC    OIDCConfig    `json:"oidc" embed:"" prefix:"oidc." envprefix:"OIDC_"`
 	Session SessionConfig `json:"session" embed:"" prefix:"session." envprefix:"SESSION_"`
+	Static  StaticConfig  `json:"static" embed:"" prefix:"static." envprefix:"STATIC_"`
+	Signin  SigninConfig  `json:"signin" embed:"" prefix:"signin." envprefix:"SIGNIN_"`
+	Tokens  TokensConfig  `json:"tokens" embed:"" prefix:"tokens." envprefix:"TOKENS_"`
+	// TrustedProxies is a list of CIDRs or ip addresses of proxies in front of the hub. The "X-Forwarded-For" header is
+	// only used to get the ip address of a client, when the request was sent by one of these proxies.
+	TrustedProxies []string `json:"trustedProxies" env:"TRUSTED_PROXIES" help:"A list of CIDRs of trusted proxies. The X-Forwarded-For header is only used to get the client ip, when the request was sent by a trusted proxy."`
 }
 
 type OIDCConfig struct {
-	Enabled      bool     `json:"enabled" env:"ENABLED" help:"Enables the OIDC provider, so that uses can sign in via OIDC."`
-	Issuer       string   `json:"issuer" env:"ISSUER" help:"The issuer url for the OIDC provider."`
-	ClientID     string   `json:"clientID" env:"CLIENT_ID" help:"The client id for the OIDC provider."`
-	ClientSecret string   `json:"clientSecret" env:"CLIENT_SECRET" help:"The client secret for the OIDC provider."`
-	RedirectURL  string   `json:"redirectURL" env:"REDIRECT_URL" help:"The redirect url for the OIDC provider."`
-	State        string   `json:"state" env:"STATE" help:"The state parameter for the OIDC provider."`
-	Scopes       []string `json:"scopes" env:"SCOPES" default:"openid,profile,email,groups" help:"The scopes which should be returned by the OIDC provider."`
+	Enabled      bool          `json:"enabled" env:"ENABLED" help:"Enables the OIDC provider, so that uses can sign in via OIDC."`
+	Issuer       string        `json:"issuer" env:"ISSUER" help:"The issuer url for the OIDC provider."`
+	ClientID     string        `json:"clientID" env:"CLIENT_ID" help:"The client id for the OIDC provider."`
+	ClientSecret string        `json:"clientSecret" env:"CLIENT_SECRET" help:"The client secret for the OIDC provider."`
+	RedirectURL  string        `json:"redirectURL" env:"REDIRECT_URL" help:"The redirect url for the OIDC provider."`
+	State        string        `json:"state" env:"STATE" help:"The state parameter for the OIDC provider."`
+	Scopes       []string      `json:"scopes" env:"SCOPES" default:"openid,profile,email,groups" help:"The scopes which should be returned by the OIDC provider."`
+	Mappings     []OIDCMapping `json:"mappings" kong:"-"`
+}
+
+// OIDCMapping maps the value of a claim from the OIDC provider to kobs teams and permissions. The mapping is evaluated
+// when a user signs in via OIDC: If one of the values of the claim matches the regular expression in `match`, the
+// `teams` and `permissions` of the mapping are added to the user. If no claim is set, the "groups" claim is used.
+type OIDCMapping struct {
+	Claim       string             `json:"claim"`
+	Match       string             `json:"match"`
+	Teams       []string           `json:"teams"`
+	Permissions userv1.Permissions `json:"permissions"`
 }
 
 type SessionConfig struct {
@@ -58,8 +73,25 @@ type SessionConfig struct {
 	Duration Duration `json:"duration" env:"DURATION" default:"168h" help:"The duration for how long a user session is valid."`
 }
 
+// StaticConfig is the configuration for local users, which are defined in the hub configuration instead of a User CR.
+// These users can always sign in, even when no cluster is reachable, so that they can be used as break-glass accounts.
+type StaticConfig struct {
+	Users        []userv1.UserSpec `json:"users" kong:"-"`
+	HtpasswdFile string            `json:"htpasswdFile" env:"HTPASSWD_FILE" help:"The path to a htpasswd file with bcrypt hashed passwords for local users."`
+}
+
+type SigninConfig struct {
+	RateLimit         int      `json:"rateLimit" env:"RATE_LIMIT" default:"10" help:"The maximum number of sign in requests per minute from a single ip address. Set to 0 to disable the rate limit."`
+	MaxFailedAttempts int      `json:"maxFailedAttempts" env:"MAX_FAILED_ATTEMPTS" default:"5" help:"The number of failed sign in attempts from a single ip address after which a user is locked for this ip address. Set to 0 to disable the lockout."`
+	LockoutDuration   Duration `json:"lockoutDuration" env:"LOCKOUT_DURATION" default:"15m" help:"The duration for how long a user is locked for an ip address after too many failed sign in attempts."`
+}
+
+type TokensConfig struct {
+	MaxExpiresIn Duration `json:"maxExpiresIn" env:"MAX_EXPIRES_IN" default:"2160h" help:"The maximum duration for how long a personal API token is valid. Set to 0 to disable the limit."`
+}
+
 type Token struct {
-	SessionID primitive.ObjectID `json:"sessionID"`
+	SessionID string `json:"sessionID"`
 }
 
 type signinRequest struct {
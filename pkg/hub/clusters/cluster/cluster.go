@@ -3,11 +3,16 @@ package cluster
 //go:generate mockgen -source=cluster.go -destination=./cluster_mock.go -package=cluster Client
 
 import (
+	"bytes"
 	"context"
+	"crypto/subtle"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
 	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
@@ -18,7 +23,10 @@ import (
 	"github.com/kobsio/kobs/pkg/plugins/plugin"
 	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
 	"github.com/kobsio/kobs/pkg/utils/middleware/roundtripper"
+	"github.com/kobsio/kobs/pkg/utils/reload"
 
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -28,9 +36,11 @@ import (
 )
 
 type Config struct {
-	Name    string `json:"name"`
-	Address string `json:"address"`
-	Token   string `json:"token"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	Token     string    `json:"token"`
+	TokenFile string    `json:"tokenFile"`
+	TLS       TLSConfig `json:"tls"`
 }
 
 type Client interface {
@@ -42,15 +52,24 @@ type Client interface {
 	GetDashboards(ctx context.Context) ([]dashboardv1.DashboardSpec, error)
 	GetTeams(ctx context.Context) ([]teamv1.TeamSpec, error)
 	GetUsers(ctx context.Context) ([]userv1.UserSpec, error)
+	SaveApplication(ctx context.Context, application applicationv1.ApplicationSpec) (*applicationv1.ApplicationSpec, error)
+	SaveTeam(ctx context.Context, team teamv1.TeamSpec) (*teamv1.TeamSpec, error)
+	SaveUser(ctx context.Context, user userv1.UserSpec) (*userv1.UserSpec, error)
+	StreamEvents(ctx context.Context, events chan<- kubernetes.Event) error
 	Request(ctx context.Context, method, url string, body io.Reader) (map[string]any, error)
 	Proxy(w http.ResponseWriter, r *http.Request)
+	DialWebSocket(ctx context.Context, path string) (*websocket.Conn, error)
+	AuthenticateTunnel(token string) bool
 }
 
 type client struct {
-	config     Config
-	httpClient *http.Client
-	proxyURL   *url.URL
-	tracer     trace.Tracer
+	config         Config
+	httpClient     *http.Client
+	proxyURL       *url.URL
+	proxyTransport http.RoundTripper
+	token          *reload.Token
+	tunnel         bool
+	tracer         trace.Tracer
 }
 
 func (c *client) GetName() string {
@@ -62,7 +81,7 @@ func (c *client) GetPlugins(ctx context.Context) ([]plugin.Instance, error) {
 	span.SetAttributes(attribute.Key("client").String(c.config.Name))
 	defer span.End()
 
-	res, err := doRequest[[]plugin.Instance](ctx, c.httpClient, c.config.Token, http.MethodGet, c.config.Address+"/api/plugins", nil)
+	res, err := doRequest[[]plugin.Instance](ctx, c.httpClient, c.token.Current(), http.MethodGet, c.config.Address+"/api/plugins", nil)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -76,7 +95,7 @@ func (c *client) GetNamespaces(ctx context.Context) ([]string, error) {
 	span.SetAttributes(attribute.Key("client").String(c.config.Name))
 	defer span.End()
 
-	res, err := doRequest[[]string](ctx, c.httpClient, c.config.Token, http.MethodGet, c.config.Address+"/api/resources/namespaces", nil)
+	res, err := doRequest[[]string](ctx, c.httpClient, c.token.Current(), http.MethodGet, c.config.Address+"/api/resources/namespaces", nil)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -90,7 +109,7 @@ func (c *client) GetCRDs(ctx context.Context) ([]kubernetes.CRD, error) {
 	span.SetAttributes(attribute.Key("client").String(c.config.Name))
 	defer span.End()
 
-	res, err := doRequest[[]kubernetes.CRD](ctx, c.httpClient, c.config.Token, http.MethodGet, c.config.Address+"/api/resources/crds", nil)
+	res, err := doRequest[[]kubernetes.CRD](ctx, c.httpClient, c.token.Current(), http.MethodGet, c.config.Address+"/api/resources/crds", nil)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -104,7 +123,7 @@ func (c *client) GetApplications(ctx context.Context) ([]applicationv1.Applicati
 	span.SetAttributes(attribute.Key("client").String(c.config.Name))
 	defer span.End()
 
-	res, err := doRequest[[]applicationv1.ApplicationSpec](ctx, c.httpClient, c.config.Token, http.MethodGet, c.config.Address+"/api/applications?cluster="+c.GetName(), nil)
+	res, err := doRequest[[]applicationv1.ApplicationSpec](ctx, c.httpClient, c.token.Current(), http.MethodGet, c.config.Address+"/api/applications?cluster="+c.GetName(), nil)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -118,7 +137,7 @@ func (c *client) GetDashboards(ctx context.Context) ([]dashboardv1.DashboardSpec
 	span.SetAttributes(attribute.Key("client").String(c.config.Name))
 	defer span.End()
 
-	res, err := doRequest[[]dashboardv1.DashboardSpec](ctx, c.httpClient, c.config.Token, http.MethodGet, c.config.Address+"/api/dashboards?cluster="+c.GetName(), nil)
+	res, err := doRequest[[]dashboardv1.DashboardSpec](ctx, c.httpClient, c.token.Current(), http.MethodGet, c.config.Address+"/api/dashboards?cluster="+c.GetName(), nil)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -132,7 +151,7 @@ func (c *client) GetTeams(ctx context.Context) ([]teamv1.TeamSpec, error) {
 	span.SetAttributes(attribute.Key("client").String(c.config.Name))
 	defer span.End()
 
-	res, err := doRequest[[]teamv1.TeamSpec](ctx, c.httpClient, c.config.Token, http.MethodGet, c.config.Address+"/api/teams?cluster="+c.GetName(), nil)
+	res, err := doRequest[[]teamv1.TeamSpec](ctx, c.httpClient, c.token.Current(), http.MethodGet, c.config.Address+"/api/teams?cluster="+c.GetName(), nil)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -146,7 +165,7 @@ func (c *client) GetUsers(ctx context.Context) ([]userv1.UserSpec, error) {
 	span.SetAttributes(attribute.Key("client").String(c.config.Name))
 	defer span.End()
 
-	res, err := doRequest[[]userv1.UserSpec](ctx, c.httpClient, c.config.Token, http.MethodGet, c.config.Address+"/api/users?cluster="+c.GetName(), nil)
+	res, err := doRequest[[]userv1.UserSpec](ctx, c.httpClient, c.token.Current(), http.MethodGet, c.config.Address+"/api/users?cluster="+c.GetName(), nil)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -155,6 +174,100 @@ func (c *client) GetUsers(ctx context.Context) ([]userv1.UserSpec, error) {
 	return res, err
 }
 
+// SaveApplication writes the provided application back to the cluster as Application CR. The resource version of the
+// application is used to detect concurrent modifications of the CR. If the CR was modified in the meantime the returned
+// error wraps the [ErrConflict] error. The returned application contains the new resource version of the CR.
+func (c *client) SaveApplication(ctx context.Context, application applicationv1.ApplicationSpec) (*applicationv1.ApplicationSpec, error) {
+	ctx, span := c.tracer.Start(ctx, "client.SaveApplication")
+	span.SetAttributes(attribute.Key("client").String(c.config.Name))
+	span.SetAttributes(attribute.Key("namespace").String(application.Namespace))
+	span.SetAttributes(attribute.Key("name").String(application.Name))
+	defer span.End()
+
+	body, err := json.Marshal(application)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	res, err := doRequest[applicationv1.ApplicationSpec](ctx, c.httpClient, c.token.Current(), http.MethodPut, c.config.Address+"/api/applications", bytes.NewReader(body))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// SaveTeam writes the provided team back to the cluster as Team CR. It works the same as the [SaveApplication] method.
+func (c *client) SaveTeam(ctx context.Context, team teamv1.TeamSpec) (*teamv1.TeamSpec, error) {
+	ctx, span := c.tracer.Start(ctx, "client.SaveTeam")
+	span.SetAttributes(attribute.Key("client").String(c.config.Name))
+	span.SetAttributes(attribute.Key("namespace").String(team.Namespace))
+	span.SetAttributes(attribute.Key("name").String(team.Name))
+	defer span.End()
+
+	body, err := json.Marshal(team)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	res, err := doRequest[teamv1.TeamSpec](ctx, c.httpClient, c.token.Current(), http.MethodPut, c.config.Address+"/api/teams", bytes.NewReader(body))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// SaveUser writes the provided user back to the cluster as User CR. It works the same as the [SaveApplication] method.
+func (c *client) SaveUser(ctx context.Context, user userv1.UserSpec) (*userv1.UserSpec, error) {
+	ctx, span := c.tracer.Start(ctx, "client.SaveUser")
+	span.SetAttributes(attribute.Key("client").String(c.config.Name))
+	span.SetAttributes(attribute.Key("namespace").String(user.Namespace))
+	span.SetAttributes(attribute.Key("name").String(user.Name))
+	defer span.End()
+
+	body, err := json.Marshal(user)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	res, err := doRequest[userv1.UserSpec](ctx, c.httpClient, c.token.Current(), http.MethodPut, c.config.Address+"/api/users", bytes.NewReader(body))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// StreamEvents opens a long-lived connection to the events API of the cluster and sends all received events to the
+// provided `events` channel. The function blocks until the connection is closed by the cluster, the provided context is
+// canceled or an event could not be decoded. In all cases an error is returned, so that the caller can reconnect.
+func (c *client) StreamEvents(ctx context.Context, events chan<- kubernetes.Event) error {
+	ctx, span := c.tracer.Start(ctx, "client.StreamEvents")
+	span.SetAttributes(attribute.Key("client").String(c.config.Name))
+	defer span.End()
+
+	err := streamRequest(ctx, c.httpClient, c.token.Current(), c.config.Address+"/api/events?cluster="+c.GetName(), events)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
 func (c *client) Request(ctx context.Context, method, url string, body io.Reader) (map[string]any, error) {
 	ctx, span := c.tracer.Start(ctx, "client.Request")
 	span.SetAttributes(attribute.Key("client").String(c.config.Name))
@@ -162,7 +275,7 @@ func (c *client) Request(ctx context.Context, method, url string, body io.Reader
 	span.SetAttributes(attribute.Key("url").String(url))
 	defer span.End()
 
-	res, err := doRequest[map[string]any](ctx, c.httpClient, c.config.Token, method, c.config.Address+url, body)
+	res, err := doRequest[map[string]any](ctx, c.httpClient, c.token.Current(), method, c.config.Address+url, body)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -178,6 +291,9 @@ func (c *client) Proxy(w http.ResponseWriter, r *http.Request) {
 
 	proxy := httputil.NewSingleHostReverseProxy(c.proxyURL)
 	proxy.FlushInterval = -1
+	if c.proxyTransport != nil {
+		proxy.Transport = c.proxyTransport
+	}
 
 	originalDirector := proxy.Director
 	proxy.Director = func(req *http.Request) {
@@ -185,7 +301,8 @@ func (c *client) Proxy(w http.ResponseWriter, r *http.Request) {
 		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
 		req.Host = req.URL.Host
-		req.Header.Set("Authorization", "Bearer "+c.config.Token)
+		req.Header.Set("Authorization", "Bearer "+c.token.Current())
+		setImpersonationHeaders(ctx, req.Header)
 	}
 
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
@@ -196,18 +313,134 @@ func (c *client) Proxy(w http.ResponseWriter, r *http.Request) {
 	proxy.ServeHTTP(w, r)
 }
 
+// DialWebSocket opens a WebSocket connection to the provided path (including the query parameters) of the cluster. It
+// can be used instead of the Proxy method, when the hub has to read the messages which are sent via a WebSocket
+// connection. Like for the Proxy method, the token of the cluster and the impersonation headers for the user from the
+// context are added to the request.
+func (c *client) DialWebSocket(ctx context.Context, path string) (*websocket.Conn, error) {
+	ctx, span := c.tracer.Start(ctx, "client.DialWebSocket")
+	span.SetAttributes(attribute.Key("client").String(c.config.Name))
+	span.SetAttributes(attribute.Key("path").String(path))
+	defer span.End()
+
+	conn, err := func() (*websocket.Conn, error) {
+		ref, err := url.Parse(path)
+		if err != nil {
+			return nil, err
+		}
+
+		// The path is appended to the path of the cluster address, like it is done by the reverse proxy in the Proxy
+		// method.
+		u := *c.proxyURL
+		u.Path = strings.TrimSuffix(u.Path, "/") + ref.Path
+		u.RawPath = ""
+		u.RawQuery = ref.RawQuery
+		if u.Scheme == "https" {
+			u.Scheme = "wss"
+		} else {
+			u.Scheme = "ws"
+		}
+
+		dialer := &websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			HandshakeTimeout: 45 * time.Second,
+		}
+		if transport, ok := c.proxyTransport.(*http.Transport); ok {
+			dialer.NetDialContext = transport.DialContext
+			dialer.TLSClientConfig = transport.TLSClientConfig
+		}
+
+		header := make(http.Header)
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+		header.Set("Authorization", "Bearer "+c.token.Current())
+		setImpersonationHeaders(ctx, header)
+
+		conn, res, err := dialer.DialContext(ctx, u.String(), header)
+		if res != nil && res.Body != nil {
+			res.Body.Close()
+		}
+
+		return conn, err
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// AuthenticateTunnel returns true when the cluster is connected via a tunnel and the provided token matches the token
+// of the cluster.
+func (c *client) AuthenticateTunnel(token string) bool {
+	clusterToken := c.token.Current()
+	return c.tunnel && clusterToken != "" && subtle.ConstantTimeCompare([]byte(clusterToken), []byte(token)) == 1
+}
+
+// NewClient returns a new client for the cluster with the provided configuration. When the address of the cluster is
+// "tunnel", all requests are sent through the tunnel, which was opened by the cluster. When a TLS configuration is
+// provided, the configured CA and client certificate are used for the requests to the cluster.
+//
+// The token can also be read from a file, which is reloaded when it is changed, so that the token can be rotated
+// without a restart.
 func NewClient(config Config) (Client, error) {
+	token, err := reload.NewToken(config.Token, config.TokenFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Address == TunnelAddress {
+		proxyURL, err := url.Parse("http://" + TunnelAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		transport := newTunnelTransport(config.Name)
+
+		return &client{
+			config: Config{Name: config.Name, Address: proxyURL.String()},
+			httpClient: &http.Client{
+				Transport: otelhttp.NewTransport(transport),
+			},
+			proxyURL:       proxyURL,
+			proxyTransport: transport,
+			token:          token,
+			tunnel:         true,
+			tracer:         otel.Tracer("client"),
+		}, nil
+	}
+
 	proxyURL, err := url.Parse(config.Address)
 	if err != nil {
 		return nil, err
 	}
 
+	if config.TLS.CAFile != "" || config.TLS.CertFile != "" || config.TLS.KeyFile != "" {
+		transport, err := newTLSTransport(config.TLS)
+		if err != nil {
+			return nil, err
+		}
+
+		return &client{
+			config: config,
+			httpClient: &http.Client{
+				Transport: otelhttp.NewTransport(transport),
+			},
+			proxyURL:       proxyURL,
+			proxyTransport: transport,
+			token:          token,
+			tracer:         otel.Tracer("client"),
+		}, nil
+	}
+
 	return &client{
 		config: config,
 		httpClient: &http.Client{
 			Transport: roundtripper.DefaultRoundTripper,
 		},
 		proxyURL: proxyURL,
+		token:    token,
 		tracer:   otel.Tracer("client"),
 	}, nil
 }
@@ -11,6 +11,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	websocket "github.com/gorilla/websocket"
 	kubernetes "github.com/kobsio/kobs/pkg/cluster/kubernetes"
 	v1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
 	v10 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/dashboard/v1"
@@ -42,6 +43,35 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// AuthenticateTunnel mocks base method.
+func (m *MockClient) AuthenticateTunnel(token string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateTunnel", token)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// AuthenticateTunnel indicates an expected call of AuthenticateTunnel.
+func (mr *MockClientMockRecorder) AuthenticateTunnel(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateTunnel", reflect.TypeOf((*MockClient)(nil).AuthenticateTunnel), token)
+}
+
+// DialWebSocket mocks base method.
+func (m *MockClient) DialWebSocket(ctx context.Context, path string) (*websocket.Conn, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DialWebSocket", ctx, path)
+	ret0, _ := ret[0].(*websocket.Conn)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DialWebSocket indicates an expected call of DialWebSocket.
+func (mr *MockClientMockRecorder) DialWebSocket(ctx, path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DialWebSocket", reflect.TypeOf((*MockClient)(nil).DialWebSocket), ctx, path)
+}
+
 // GetApplications mocks base method.
 func (m *MockClient) GetApplications(ctx context.Context) ([]v1.ApplicationSpec, error) {
 	m.ctrl.T.Helper()
@@ -187,3 +217,62 @@ func (mr *MockClientMockRecorder) Request(ctx, method, url, body interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Request", reflect.TypeOf((*MockClient)(nil).Request), ctx, method, url, body)
 }
+
+// SaveApplication mocks base method.
+func (m *MockClient) SaveApplication(ctx context.Context, application v1.ApplicationSpec) (*v1.ApplicationSpec, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveApplication", ctx, application)
+	ret0, _ := ret[0].(*v1.ApplicationSpec)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveApplication indicates an expected call of SaveApplication.
+func (mr *MockClientMockRecorder) SaveApplication(ctx, application interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveApplication", reflect.TypeOf((*MockClient)(nil).SaveApplication), ctx, application)
+}
+
+// SaveTeam mocks base method.
+func (m *MockClient) SaveTeam(ctx context.Context, team v11.TeamSpec) (*v11.TeamSpec, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTeam", ctx, team)
+	ret0, _ := ret[0].(*v11.TeamSpec)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveTeam indicates an expected call of SaveTeam.
+func (mr *MockClientMockRecorder) SaveTeam(ctx, team interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTeam", reflect.TypeOf((*MockClient)(nil).SaveTeam), ctx, team)
+}
+
+// SaveUser mocks base method.
+func (m *MockClient) SaveUser(ctx context.Context, user v12.UserSpec) (*v12.UserSpec, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveUser", ctx, user)
+	ret0, _ := ret[0].(*v12.UserSpec)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveUser indicates an expected call of SaveUser.
+func (mr *MockClientMockRecorder) SaveUser(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUser", reflect.TypeOf((*MockClient)(nil).SaveUser), ctx, user)
+}
+
+// StreamEvents mocks base method.
+func (m *MockClient) StreamEvents(ctx context.Context, events chan<- kubernetes.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamEvents", ctx, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamEvents indicates an expected call of StreamEvents.
+func (mr *MockClientMockRecorder) StreamEvents(ctx, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamEvents", reflect.TypeOf((*MockClient)(nil).StreamEvents), ctx, events)
+}
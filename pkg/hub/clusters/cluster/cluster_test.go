@@ -2,11 +2,18 @@ package cluster
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
+	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/require"
 )
 
@@ -98,6 +105,99 @@ func TestGetUsers(t *testing.T) {
 	require.Empty(t, plugins)
 }
 
+func TestSaveApplication(t *testing.T) {
+	t.Run("should save application", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPut, r.Method)
+			require.Equal(t, "/api/applications", r.URL.Path)
+
+			var application applicationv1.ApplicationSpec
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&application))
+			application.ResourceVersion = "2"
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(application)
+		}))
+		defer ts.Close()
+
+		client, _ := NewClient(Config{Address: ts.URL})
+
+		application, err := client.SaveApplication(context.Background(), applicationv1.ApplicationSpec{Cluster: "cluster1", Namespace: "default", Name: "application1", ResourceVersion: "1"})
+		require.NoError(t, err)
+		require.Equal(t, &applicationv1.ApplicationSpec{Cluster: "cluster1", Namespace: "default", Name: "application1", ResourceVersion: "2"}, application)
+	})
+
+	t.Run("should return conflict error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"errors": ["The application was modified, reload the application and try again"]}`))
+		}))
+		defer ts.Close()
+
+		client, _ := NewClient(Config{Address: ts.URL})
+
+		application, err := client.SaveApplication(context.Background(), applicationv1.ApplicationSpec{Cluster: "cluster1", Namespace: "default", Name: "application1", ResourceVersion: "1"})
+		require.ErrorIs(t, err, ErrConflict)
+		require.Nil(t, application)
+	})
+}
+
+func TestSaveTeam(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	client, _ := NewClient(Config{Address: ts.URL})
+
+	team, err := client.SaveTeam(context.Background(), teamv1.TeamSpec{})
+	require.Error(t, err)
+	require.Nil(t, team)
+}
+
+func TestSaveUser(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	client, _ := NewClient(Config{Address: ts.URL})
+
+	user, err := client.SaveUser(context.Background(), userv1.UserSpec{})
+	require.Error(t, err)
+	require.Nil(t, user)
+}
+
+func TestStreamEvents(t *testing.T) {
+	t.Run("should return error for invalid status code", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":["The 'cluster' parameter can not be empty"]}`))
+		}))
+		defer ts.Close()
+
+		client, _ := NewClient(Config{Address: ts.URL})
+
+		err := client.StreamEvents(context.Background(), make(chan kubernetes.Event))
+		require.Error(t, err)
+		require.Equal(t, "[The 'cluster' parameter can not be empty]", err.Error())
+	})
+
+	t.Run("should send events until stream is closed", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/events", r.URL.Path)
+			require.Equal(t, "foobar", r.URL.Query().Get("cluster"))
+			w.Write([]byte(`{"type":"add","resource":"teams","team":{"id":"team1"}}` + "\n" + `{"type":"delete","resource":"teams","team":{"id":"team1"}}` + "\n"))
+		}))
+		defer ts.Close()
+
+		client, _ := NewClient(Config{Name: "foobar", Address: ts.URL})
+
+		events := make(chan kubernetes.Event, 2)
+		err := client.StreamEvents(context.Background(), events)
+		require.Error(t, err)
+		require.Equal(t, kubernetes.Event{Type: kubernetes.EventTypeAdd, Resource: "teams", Team: &teamv1.TeamSpec{ID: "team1"}}, <-events)
+		require.Equal(t, kubernetes.Event{Type: kubernetes.EventTypeDelete, Resource: "teams", Team: &teamv1.TeamSpec{ID: "team1"}}, <-events)
+	})
+}
+
 func TestRequest(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 	defer ts.Close()
@@ -189,3 +289,41 @@ func TestNewClient(t *testing.T) {
 		require.NotEmpty(t, client)
 	})
 }
+
+func TestDialWebSocket(t *testing.T) {
+	satelliteServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/base/api/resources/terminal" || r.URL.Query().Get("name") != "pod1" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		upgrader := websocket.Upgrader{}
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+
+		c.WriteMessage(websocket.TextMessage, []byte("hello"))
+	}))
+	defer satelliteServer.Close()
+
+	t.Run("should dial websocket", func(t *testing.T) {
+		client, _ := NewClient(Config{Address: satelliteServer.URL + "/base", Token: "token"})
+
+		conn, err := client.DialWebSocket(context.Background(), "/api/resources/terminal?name=pod1")
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, msg, err := conn.ReadMessage()
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(msg))
+	})
+
+	t.Run("should return error", func(t *testing.T) {
+		client, _ := NewClient(Config{Address: satelliteServer.URL, Token: "token"})
+
+		_, err := client.DialWebSocket(context.Background(), "/api/resources/terminal?name=pod1")
+		require.Error(t, err)
+	})
+}
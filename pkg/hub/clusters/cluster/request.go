@@ -3,10 +3,13 @@ package cluster
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
 	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
 
 	"github.com/go-chi/chi/v5/middleware"
@@ -14,18 +17,46 @@ import (
 	"go.opentelemetry.io/otel/propagation"
 )
 
+// setImpersonationHeaders forwards the id and teams of the authenticated user from the provided context to the cluster,
+// so that the cluster can impersonate the user for requests against the Kubernetes API. The headers from the original
+// request are always removed, so that a user can not set them by himself.
+func setImpersonationHeaders(ctx context.Context, header http.Header) {
+	header.Del(kubernetes.ImpersonateUserHeader)
+	header.Del(kubernetes.ImpersonateGroupHeader)
+
+	user, err := authContext.GetUser(ctx)
+	if err != nil {
+		return
+	}
+
+	header.Set(kubernetes.ImpersonateUserHeader, user.ID)
+	for _, team := range user.Teams {
+		header.Add(kubernetes.ImpersonateGroupHeader, team)
+	}
+}
+
+// ErrConflict is returned by a request, when the cluster responds with a conflict. This is the case when a CR should be
+// saved, but the CR was modified since it was loaded by the user.
+var ErrConflict = errors.New("conflict")
+
 // doRequest runs a http request against the given url with the given client. It decodes the returned result in the
-// specified type and returns it. if the response code is not 200 it returns an error.
+// specified type and returns it. if the response code is not 200 it returns an error. If the response code is 409 the
+// returned error wraps the [ErrConflict] error.
 func doRequest[T any](ctx context.Context, client *http.Client, token, method, url string, body io.Reader) (T, error) {
 	var result T
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return result, err
 	}
 
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
 	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	setImpersonationHeaders(ctx, req.Header)
 
 	if requestID := middleware.GetReqID(ctx); requestID != "" {
 		req.Header.Set("requestID", requestID)
@@ -51,5 +82,56 @@ func doRequest[T any](ctx context.Context, client *http.Client, token, method, u
 		return result, err
 	}
 
+	if resp.StatusCode == http.StatusConflict {
+		return result, fmt.Errorf("%w: %v", ErrConflict, res.Errors)
+	}
+
 	return result, fmt.Errorf("%v", res.Errors)
 }
+
+// streamRequest runs a http request against the given url with the given client. The response body must contain a
+// stream of JSON objects, which are decoded into the specified type and sent to the provided channel one after another.
+// If the response code is not 200 or the stream ends, it returns an error.
+func streamRequest[T any](ctx context.Context, client *http.Client, token, url string, results chan<- T) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		var res errresponse.ErrResponse
+		if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("%v", res.Errors)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+
+	for {
+		var result T
+		if err := decoder.Decode(&result); err != nil {
+			if err == io.EOF {
+				return fmt.Errorf("stream was closed")
+			}
+			return err
+		}
+
+		select {
+		case results <- result:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
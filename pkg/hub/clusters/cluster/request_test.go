@@ -2,10 +2,16 @@ package cluster
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/stretchr/testify/require"
 )
@@ -91,6 +97,32 @@ func TestDoRequest(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("request sends method and body", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(fmt.Sprintf(`["%s", "%s", "%s"]`, r.Method, r.Header.Get("Content-Type"), body)))
+		}))
+		defer ts.Close()
+
+		res, err := doRequest[[]string](context.Background(), ts.Client(), "", http.MethodPut, ts.URL, strings.NewReader("body"))
+		require.NoError(t, err)
+		require.Equal(t, []string{http.MethodPut, "application/json", "body"}, res)
+	})
+
+	t.Run("request fails with conflict", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"errors": ["The application was modified"]}`))
+		}))
+		defer ts.Close()
+
+		_, err := doRequest[[]string](context.Background(), ts.Client(), "", http.MethodPut, ts.URL, nil)
+		require.ErrorIs(t, err, ErrConflict)
+	})
+
 	t.Run("request fails with error", func(t *testing.T) {
 		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
@@ -105,3 +137,25 @@ func TestDoRequest(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestSetImpersonationHeaders(t *testing.T) {
+	t.Run("should remove headers when context does not contain a user", func(t *testing.T) {
+		header := http.Header{}
+		header.Set(kubernetes.ImpersonateUserHeader, "admin@kobs.io")
+		header.Add(kubernetes.ImpersonateGroupHeader, "admins@kobs.io")
+
+		setImpersonationHeaders(context.Background(), header)
+		require.Empty(t, header.Values(kubernetes.ImpersonateUserHeader))
+		require.Empty(t, header.Values(kubernetes.ImpersonateGroupHeader))
+	})
+
+	t.Run("should set headers for user", func(t *testing.T) {
+		header := http.Header{}
+		header.Set(kubernetes.ImpersonateUserHeader, "admin@kobs.io")
+
+		ctx := context.WithValue(context.Background(), authContext.UserKey, authContext.User{ID: "user1@kobs.io", Teams: []string{"team1@kobs.io", "team2@kobs.io"}})
+		setImpersonationHeaders(ctx, header)
+		require.Equal(t, []string{"user1@kobs.io"}, header.Values(kubernetes.ImpersonateUserHeader))
+		require.Equal(t, []string{"team1@kobs.io", "team2@kobs.io"}, header.Values(kubernetes.ImpersonateGroupHeader))
+	})
+}
@@ -0,0 +1,80 @@
+package cluster
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/kobsio/kobs/pkg/utils/reload"
+)
+
+// TLSConfig is the TLS configuration, which is used to connect to a cluster. The CA file is used to verify the
+// certificate of the cluster and the certificate and key file are used as client certificate, when the cluster requires
+// mutual TLS. All files are reloaded when they are changed, so that they can be rotated without a restart.
+type TLSConfig struct {
+	CAFile     string `json:"caFile"`
+	CertFile   string `json:"certFile"`
+	KeyFile    string `json:"keyFile"`
+	ServerName string `json:"serverName"`
+}
+
+// newTLSTransport returns a http transport, which uses the provided TLS configuration. Since the CA and the client
+// certificate can be changed at runtime, we create a new TLS configuration for each new connection, instead of using
+// the static TLSClientConfig of the transport.
+func newTLSTransport(config TLSConfig) (*http.Transport, error) {
+	var rootCAs *reload.CertPool
+	if config.CAFile != "" {
+		var err error
+		rootCAs, err = reload.NewCertPool(config.CAFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var certificate *reload.Certificate
+	if config.CertFile != "" || config.KeyFile != "" {
+		var err error
+		certificate, err = reload.NewCertificate(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	return &http.Transport{
+		DialContext: dialer.DialContext,
+		DialTLSContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			serverName := config.ServerName
+			if serverName == "" {
+				host, _, err := net.SplitHostPort(addr)
+				if err != nil {
+					return nil, err
+				}
+				serverName = host
+			}
+
+			tlsConfig := &tls.Config{
+				MinVersion: tls.VersionTLS12,
+				ServerName: serverName,
+			}
+
+			if rootCAs != nil {
+				tlsConfig.RootCAs = rootCAs.Get()
+			}
+
+			if certificate != nil {
+				tlsConfig.GetClientCertificate = certificate.GetClientCertificate
+			}
+
+			tlsDialer := &tls.Dialer{NetDialer: dialer, Config: tlsConfig}
+			return tlsDialer.DialContext(ctx, network, addr)
+		},
+		TLSHandshakeTimeout: 10 * time.Second,
+	}, nil
+}
@@ -0,0 +1,111 @@
+package cluster
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// generateCertificates generates a CA and a server and client certificate signed by the CA in the provided directory.
+func generateCertificates(t *testing.T, dir string) {
+	var writePEM = func(name, pemType string, data []byte) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: data}), 0600))
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kobs-ca"},
+		NotBefore:             time.Now().Add(-1 * time.Hour),
+		NotAfter:              time.Now().Add(1 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	writePEM("ca.pem", "CERTIFICATE", caDER)
+
+	for i, name := range []string{"server", "client"} {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(int64(i + 2)),
+			Subject:      pkix.Name{CommonName: name},
+			DNSNames:     []string{"localhost"},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+			NotBefore:    time.Now().Add(-1 * time.Hour),
+			NotAfter:     time.Now().Add(1 * time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+		writePEM(name+".pem", "CERTIFICATE", der)
+		writePEM(name+"-key.pem", "EC PRIVATE KEY", keyDER)
+	}
+}
+
+func TestNewTLSTransport(t *testing.T) {
+	dir := t.TempDir()
+	generateCertificates(t, dir)
+
+	t.Run("should fail for invalid ca file", func(t *testing.T) {
+		_, err := newTLSTransport(TLSConfig{CAFile: filepath.Join(dir, "not-existing.pem")})
+		require.Error(t, err)
+	})
+
+	t.Run("should fail for invalid certificate", func(t *testing.T) {
+		_, err := newTLSTransport(TLSConfig{CAFile: filepath.Join(dir, "ca.pem"), CertFile: filepath.Join(dir, "client.pem")})
+		require.Error(t, err)
+	})
+
+	t.Run("should use client certificate", func(t *testing.T) {
+		caPEM, err := os.ReadFile(filepath.Join(dir, "ca.pem"))
+		require.NoError(t, err)
+		clientCAs := x509.NewCertPool()
+		clientCAs.AppendCertsFromPEM(caPEM)
+
+		serverCertificate, err := tls.LoadX509KeyPair(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"))
+		require.NoError(t, err)
+
+		ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`["default"]`))
+		}))
+		ts.TLS = &tls.Config{Certificates: []tls.Certificate{serverCertificate}, ClientCAs: clientCAs, ClientAuth: tls.RequireAndVerifyClientCert}
+		ts.StartTLS()
+		defer ts.Close()
+
+		client, err := NewClient(Config{Name: "cluster1", Address: ts.URL, TLS: TLSConfig{CAFile: filepath.Join(dir, "ca.pem"), CertFile: filepath.Join(dir, "client.pem"), KeyFile: filepath.Join(dir, "client-key.pem")}})
+		require.NoError(t, err)
+
+		namespaces, err := client.GetNamespaces(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{"default"}, namespaces)
+
+		client, err = NewClient(Config{Name: "cluster1", Address: ts.URL, TLS: TLSConfig{CAFile: filepath.Join(dir, "ca.pem")}})
+		require.NoError(t, err)
+
+		_, err = client.GetNamespaces(context.Background())
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,77 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kobsio/kobs/pkg/utils/tunnel"
+)
+
+// TunnelAddress is the address of a cluster, which is not reachable by the hub and instead connects to the hub via a
+// tunnel. All requests to such a cluster are sent through the tunnel, which was registered for the name of the
+// cluster.
+const TunnelAddress = "tunnel"
+
+// tunnels contains all tunnels, which are currently connected to the hub. The key is the name of the cluster.
+//
+// The tunnels are only known by the hub replica, to which the cluster is connected. Requests to a cluster which is
+// connected to another replica are failing, because the requests are not routed between the replicas. Sticky routing
+// doesn't help, because the requests for a cluster are sent by all replicas and by the watcher. This means that the hub
+// must run with a single replica, when clusters are connected via a tunnel.
+var tunnels = struct {
+	sync.RWMutex
+	sessions map[string]*tunnel.Session
+}{sessions: make(map[string]*tunnel.Session)}
+
+// RegisterTunnel registers the provided tunnel session for the cluster with the provided name. If the cluster already
+// has a tunnel, the old tunnel is closed.
+func RegisterTunnel(name string, session *tunnel.Session) {
+	tunnels.Lock()
+	defer tunnels.Unlock()
+
+	if existingSession, ok := tunnels.sessions[name]; ok && existingSession != session {
+		existingSession.Close()
+	}
+
+	tunnels.sessions[name] = session
+}
+
+// UnregisterTunnel removes the provided tunnel session for the cluster with the provided name. The session is only
+// removed when it wasn't already replaced by a new session of the cluster.
+func UnregisterTunnel(name string, session *tunnel.Session) {
+	tunnels.Lock()
+	defer tunnels.Unlock()
+
+	if tunnels.sessions[name] == session {
+		delete(tunnels.sessions, name)
+	}
+}
+
+// dialTunnel opens a new stream in the tunnel of the cluster with the provided name.
+func dialTunnel(name string) (net.Conn, error) {
+	tunnels.RLock()
+	session, ok := tunnels.sessions[name]
+	tunnels.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("cluster %s is not connected", name)
+	}
+
+	return session.Open()
+}
+
+// newTunnelTransport returns a http transport, which sends all requests through the tunnel of the cluster with the
+// provided name.
+func newTunnelTransport(name string) *http.Transport {
+	return &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialTunnel(name)
+		},
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
@@ -3,25 +3,59 @@ package clusters
 //go:generate mockgen -source=clusters.go -destination=./clusters_mock.go -package=clusters Client
 
 import (
+	"context"
+	"sync"
+	"time"
+
 	cluster "github.com/kobsio/kobs/pkg/hub/clusters/cluster"
+	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/instrument/log"
+
+	"go.uber.org/zap"
 )
 
+// syncInterval is the interval in which the clusters, which were registered via the API, are loaded from the database.
+const syncInterval = 30 * time.Second
+
 type Config []cluster.Config
 
+// Client is the interface which must be implemented by a clusters client. Next to the clusters from the configuration
+// file, the client also contains the clusters which were registered at runtime and saved in the database. These
+// clusters are loaded via the `Sync` method, which is called in the configured interval via the `Watch` method.
 type Client interface {
 	GetClusters() []cluster.Client
 	GetCluster(name string) cluster.Client
+	IsStatic(name string) bool
+	Sync(ctx context.Context) error
+	Watch(ctx context.Context)
 }
 
 type client struct {
-	clusters []cluster.Client
+	static    []cluster.Client
+	dbClient  db.Client
+	clusters  []cluster.Client
+	dynamic   map[string]dynamicCluster
+	clusterMu sync.RWMutex
+}
+
+// dynamicCluster is a cluster which was registered via the API. We keep the configuration of the cluster, so that we
+// only have to create a new cluster client when the configuration was changed.
+type dynamicCluster struct {
+	config db.Cluster
+	client cluster.Client
 }
 
 func (c *client) GetClusters() []cluster.Client {
+	c.clusterMu.RLock()
+	defer c.clusterMu.RUnlock()
+
 	return c.clusters
 }
 
 func (c *client) GetCluster(name string) cluster.Client {
+	c.clusterMu.RLock()
+	defer c.clusterMu.RUnlock()
+
 	for _, cluster := range c.clusters {
 		if cluster.GetName() == name {
 			return cluster
@@ -31,7 +65,101 @@ func (c *client) GetCluster(name string) cluster.Client {
 	return nil
 }
 
-func NewClient(config Config) (Client, error) {
+// IsStatic returns true if the cluster with the provided name is defined in the configuration file. These clusters can
+// not be changed or removed via the API.
+func (c *client) IsStatic(name string) bool {
+	for _, cluster := range c.static {
+		if cluster.GetName() == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewClusterConfig returns the configuration for the client of a cluster, which was registered via the API.
+func NewClusterConfig(registeredCluster db.Cluster) cluster.Config {
+	return cluster.Config{
+		Name:      registeredCluster.Name,
+		Address:   registeredCluster.Address,
+		Token:     registeredCluster.Token,
+		TokenFile: registeredCluster.TokenFile,
+		TLS: cluster.TLSConfig{
+			CAFile:     registeredCluster.TLS.CAFile,
+			CertFile:   registeredCluster.TLS.CertFile,
+			KeyFile:    registeredCluster.TLS.KeyFile,
+			ServerName: registeredCluster.TLS.ServerName,
+		},
+	}
+}
+
+// Sync loads all registered clusters from the database and updates the list of clusters. Clusters from the
+// configuration file always take precedence over a registered cluster with the same name. If the database client is
+// nil only the clusters from the configuration file are used.
+func (c *client) Sync(ctx context.Context) error {
+	if c.dbClient == nil {
+		return nil
+	}
+
+	registeredClusters, err := c.dbClient.GetClusters(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.clusterMu.Lock()
+	defer c.clusterMu.Unlock()
+
+	clusters := append([]cluster.Client{}, c.static...)
+	dynamic := make(map[string]dynamicCluster)
+
+	for _, registeredCluster := range registeredClusters {
+		if c.IsStatic(registeredCluster.Name) {
+			continue
+		}
+
+		if existingCluster, ok := c.dynamic[registeredCluster.Name]; ok && NewClusterConfig(existingCluster.config) == NewClusterConfig(registeredCluster) {
+			clusters = append(clusters, existingCluster.client)
+			dynamic[registeredCluster.Name] = existingCluster
+			continue
+		}
+
+		clusterClient, err := cluster.NewClient(NewClusterConfig(registeredCluster))
+		if err != nil {
+			log.Warn(ctx, "Could not create cluster client", zap.Error(err), zap.String("cluster", registeredCluster.Name))
+			continue
+		}
+
+		clusters = append(clusters, clusterClient)
+		dynamic[registeredCluster.Name] = dynamicCluster{config: registeredCluster, client: clusterClient}
+	}
+
+	c.clusters = clusters
+	c.dynamic = dynamic
+
+	return nil
+}
+
+// Watch syncs the registered clusters from the database in a fixed interval, until the provided context is canceled.
+// This should be called in a new go routine.
+func (c *client) Watch(ctx context.Context) {
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Sync(ctx); err != nil {
+				log.Warn(ctx, "Could not sync clusters", zap.Error(err))
+			}
+		}
+	}
+}
+
+// NewClient returns a new clusters client for the clusters from the provided configuration. When a database client is
+// provided, the clusters which were registered via the API are also loaded from the database.
+func NewClient(config Config, dbClient db.Client) (Client, error) {
 	var clusters []cluster.Client
 
 	for _, satelliteConfig := range config {
@@ -43,7 +171,16 @@ func NewClient(config Config) (Client, error) {
 		clusters = append(clusters, clusterClient)
 	}
 
-	return &client{
+	c := &client{
+		static:   clusters,
+		dbClient: dbClient,
 		clusters: clusters,
-	}, nil
+		dynamic:  make(map[string]dynamicCluster),
+	}
+
+	if err := c.Sync(context.Background()); err != nil {
+		log.Warn(context.Background(), "Could not sync clusters", zap.Error(err))
+	}
+
+	return c, nil
 }
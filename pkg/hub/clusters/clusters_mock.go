@@ -5,6 +5,7 @@
 package clusters
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -61,3 +62,43 @@ func (mr *MockClientMockRecorder) GetClusters() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusters", reflect.TypeOf((*MockClient)(nil).GetClusters))
 }
+
+// IsStatic mocks base method.
+func (m *MockClient) IsStatic(name string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsStatic", name)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsStatic indicates an expected call of IsStatic.
+func (mr *MockClientMockRecorder) IsStatic(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsStatic", reflect.TypeOf((*MockClient)(nil).IsStatic), name)
+}
+
+// Sync mocks base method.
+func (m *MockClient) Sync(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockClientMockRecorder) Sync(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockClient)(nil).Sync), ctx)
+}
+
+// Watch mocks base method.
+func (m *MockClient) Watch(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Watch", ctx)
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockClientMockRecorder) Watch(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockClient)(nil).Watch), ctx)
+}
@@ -1,8 +1,14 @@
 package clusters
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
+	"github.com/kobsio/kobs/pkg/hub/clusters/cluster"
+	"github.com/kobsio/kobs/pkg/hub/db"
+
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -13,7 +19,7 @@ var testConfig = Config{{
 }}
 
 func TestGetClusters(t *testing.T) {
-	client, _ := NewClient(testConfig)
+	client, _ := NewClient(testConfig, nil)
 	clusters := client.GetClusters()
 
 	require.NotEmpty(t, clusters)
@@ -21,7 +27,7 @@ func TestGetClusters(t *testing.T) {
 }
 
 func TestGetAddress(t *testing.T) {
-	client, _ := NewClient(testConfig)
+	client, _ := NewClient(testConfig, nil)
 
 	t.Run("cluster found", func(t *testing.T) {
 		cluster := client.GetCluster("foobar")
@@ -36,13 +42,101 @@ func TestGetAddress(t *testing.T) {
 
 func TestNewClient(t *testing.T) {
 	t.Run("create new client fails", func(t *testing.T) {
-		_, err := NewClient(Config{{Address: " http://localhost:15221"}})
+		_, err := NewClient(Config{{Address: " http://localhost:15221"}}, nil)
 		require.Error(t, err)
 	})
 
 	t.Run("create new client succeeds", func(t *testing.T) {
-		client, err := NewClient(Config{{Address: "http://localhost:15221"}})
+		client, err := NewClient(Config{{Address: "http://localhost:15221"}}, nil)
 		require.NoError(t, err)
 		require.NotEmpty(t, client)
 	})
 }
+
+func TestNewClusterConfig(t *testing.T) {
+	require.Equal(t, cluster.Config{
+		Name:      "cluster1",
+		Address:   "https://cluster1:15221",
+		Token:     "token",
+		TokenFile: "/var/run/secrets/kobs/token",
+		TLS:       cluster.TLSConfig{CAFile: "ca.pem", CertFile: "cert.pem", KeyFile: "key.pem", ServerName: "cluster1.kobs.io"},
+	}, NewClusterConfig(db.Cluster{
+		Name:      "cluster1",
+		Address:   "https://cluster1:15221",
+		Token:     "token",
+		TokenFile: "/var/run/secrets/kobs/token",
+		TLS:       db.ClusterTLS{CAFile: "ca.pem", CertFile: "cert.pem", KeyFile: "key.pem", ServerName: "cluster1.kobs.io"},
+		UpdatedAt: 1,
+	}))
+}
+
+func TestSync(t *testing.T) {
+	t.Run("should add registered clusters", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		dbClient.EXPECT().GetClusters(gomock.Any()).Return([]db.Cluster{{Name: "foobar", Address: "http://foobar:15221"}, {Name: "cluster1", Address: "http://cluster1:15221"}}, nil)
+
+		client, err := NewClient(testConfig, dbClient)
+		require.NoError(t, err)
+
+		clusters := client.GetClusters()
+		require.Equal(t, 2, len(clusters))
+		require.Equal(t, "foobar", clusters[0].GetName())
+		require.Equal(t, "cluster1", clusters[1].GetName())
+		require.True(t, client.IsStatic("foobar"))
+		require.False(t, client.IsStatic("cluster1"))
+	})
+
+	t.Run("should reuse unchanged and remove unregistered clusters", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		gomock.InOrder(
+			dbClient.EXPECT().GetClusters(gomock.Any()).Return([]db.Cluster{{Name: "cluster1", Address: "http://cluster1:15221"}, {Name: "cluster2", Address: "http://cluster2:15221"}}, nil),
+			dbClient.EXPECT().GetClusters(gomock.Any()).Return([]db.Cluster{{Name: "cluster1", Address: "http://cluster1:15221"}}, nil),
+		)
+
+		client, err := NewClient(nil, dbClient)
+		require.NoError(t, err)
+		cluster1 := client.GetCluster("cluster1")
+		require.NotNil(t, client.GetCluster("cluster2"))
+
+		err = client.Sync(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 1, len(client.GetClusters()))
+		require.Same(t, cluster1, client.GetCluster("cluster1"))
+		require.Nil(t, client.GetCluster("cluster2"))
+	})
+
+	t.Run("should recreate clusters with changed configuration", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		gomock.InOrder(
+			dbClient.EXPECT().GetClusters(gomock.Any()).Return([]db.Cluster{{Name: "cluster1", Address: "https://cluster1:15221"}}, nil),
+			dbClient.EXPECT().GetClusters(gomock.Any()).Return([]db.Cluster{{Name: "cluster1", Address: "https://cluster1:15221", TLS: db.ClusterTLS{ServerName: "cluster1.kobs.io"}}}, nil),
+		)
+
+		client, err := NewClient(nil, dbClient)
+		require.NoError(t, err)
+		cluster1 := client.GetCluster("cluster1")
+
+		err = client.Sync(context.Background())
+		require.NoError(t, err)
+		require.NotSame(t, cluster1, client.GetCluster("cluster1"))
+	})
+
+	t.Run("should keep clusters when sync fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		gomock.InOrder(
+			dbClient.EXPECT().GetClusters(gomock.Any()).Return([]db.Cluster{{Name: "cluster1", Address: "http://cluster1:15221"}}, nil),
+			dbClient.EXPECT().GetClusters(gomock.Any()).Return(nil, fmt.Errorf("unexpected error")),
+		)
+
+		client, err := NewClient(nil, dbClient)
+		require.NoError(t, err)
+
+		err = client.Sync(context.Background())
+		require.Error(t, err)
+		require.NotNil(t, client.GetCluster("cluster1"))
+	})
+}
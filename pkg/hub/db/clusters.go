@@ -0,0 +1,36 @@
+package db
+
+// Cluster is a cluster, which was registered at runtime via the API of the hub. In contrast to the clusters from the
+// configuration file, these clusters are saved in the database, so that they can be loaded by all hub and watcher
+// instances without a restart.
+type Cluster struct {
+	Name      string     `json:"name" bson:"_id"`
+	Address   string     `json:"address" bson:"address"`
+	Token     string     `json:"token" bson:"token"`
+	TokenFile string     `json:"tokenFile,omitempty" bson:"tokenFile,omitempty"`
+	TLS       ClusterTLS `json:"tls,omitempty" bson:"tls,omitempty"`
+	UpdatedAt int64      `json:"updatedAt" bson:"updatedAt"`
+}
+
+// ClusterTLS is the TLS configuration of a registered cluster. The files must be available on all hub and watcher
+// instances.
+type ClusterTLS struct {
+	CAFile     string `json:"caFile,omitempty" bson:"caFile,omitempty"`
+	CertFile   string `json:"certFile,omitempty" bson:"certFile,omitempty"`
+	KeyFile    string `json:"keyFile,omitempty" bson:"keyFile,omitempty"`
+	ServerName string `json:"serverName,omitempty" bson:"serverName,omitempty"`
+}
+
+// clusterCollections is the list of collections / tables which contain data, which was synced from a cluster. When a
+// cluster is deleted, we remove all documents with the name of the cluster in the provided field from these
+// collections.
+var clusterCollections = map[string]string{
+	"plugins":      "cluster",
+	"namespaces":   "cluster",
+	"applications": "cluster",
+	"dashboards":   "cluster",
+	"teams":        "cluster",
+	"users":        "cluster",
+	"topology":     "sourceCluster",
+	"syncs":        "cluster",
+}
@@ -0,0 +1,1272 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
+	dashboardv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/dashboard/v1"
+	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/plugins/plugin"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testClient runs the conformance tests against the provided client. All database backends must pass these tests, so
+// that they can be used interchangeably in the hub and the watcher.
+func testClient(t *testing.T, c Client) {
+	t.Run("CreateIndexes", func(t *testing.T) {
+		err := c.CreateIndexes(ctx(t))
+		require.NoError(t, err)
+	})
+
+	t.Run("SaveAndGetPlugins", func(t *testing.T) {
+		plugins := []plugin.Instance{{
+			Name: "test-cluster",
+			Type: "prometheus",
+		}, {
+			Name: "test-cluster",
+			Type: "klogs",
+		}}
+
+		err := c.SavePlugins(ctx(t), "test-cluster", plugins)
+		require.NoError(t, err)
+
+		storedPlugins1, err := c.GetPlugins(ctx(t))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(storedPlugins1))
+
+		err = c.SavePlugins(ctx(t), "test-cluster", plugins[0:1])
+		require.NoError(t, err)
+
+		storedPlugins2, err := c.GetPlugins(ctx(t))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(storedPlugins2))
+	})
+
+	t.Run("SaveAndGetNamespaces", func(t *testing.T) {
+		namespaces1 := []string{"default", "kube-system"}
+		err := c.SaveNamespaces(ctx(t), "test-cluster", namespaces1)
+		require.NoError(t, err)
+
+		storedNamespaces1, err := c.GetNamespaces(ctx(t))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(storedNamespaces1))
+
+		namespaces2 := []string{"default"}
+		err = c.SaveNamespaces(ctx(t), "test-cluster", namespaces2)
+		require.NoError(t, err)
+
+		storedNamespaces2, err := c.GetNamespaces(ctx(t))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(storedNamespaces2))
+	})
+
+	t.Run("SaveAndGetCRDs", func(t *testing.T) {
+		crds1 := []kubernetes.CRD{
+			{ID: "resource1.path1/v1", Resource: "resource1", Path: "path1/v1"},
+			{ID: "resource2.path2/v2", Resource: "resource2", Path: "path2/v2"},
+		}
+
+		err := c.SaveCRDs(ctx(t), crds1)
+		require.NoError(t, err)
+
+		storedCRDs1, err := c.GetCRDs(ctx(t))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(storedCRDs1))
+
+		err = c.SaveCRDs(ctx(t), crds1[0:1])
+		require.NoError(t, err)
+
+		storedCRDs2, err := c.GetCRDs(ctx(t))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(storedCRDs2))
+	})
+
+	t.Run("SaveAndGetApplications", func(t *testing.T) {
+		applications := []applicationv1.ApplicationSpec{{
+			ID:        "/cluster/test-cluster/namespace/default/name/application1",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "application1",
+		}, {
+			ID:        "/cluster/test-cluster/namespace/default/name/application2",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "application2",
+		}}
+
+		err := c.SaveApplications(ctx(t), "test-cluster", applications)
+		require.NoError(t, err)
+
+		storedApplications1, err := c.GetApplications(ctx(t))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(storedApplications1))
+
+		err = c.SaveApplications(ctx(t), "test-cluster", applications[0:1])
+		require.NoError(t, err)
+
+		storedApplications2, err := c.GetApplications(ctx(t))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(storedApplications2))
+	})
+
+	t.Run("SaveAndGetApplication", func(t *testing.T) {
+		application := applicationv1.ApplicationSpec{
+			ID:        "/cluster/test-cluster/namespace/default/name/application1",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "application1",
+		}
+
+		err := c.SaveApplication(ctx(t), &application)
+		require.NoError(t, err)
+
+		storedApplication1, err := c.GetApplicationByID(ctx(t), application.ID)
+		require.NoError(t, err)
+		require.Equal(t, application.ID, storedApplication1.ID)
+		require.Equal(t, application.Cluster, storedApplication1.Cluster)
+		require.Equal(t, application.Namespace, storedApplication1.Namespace)
+		require.Equal(t, application.Name, storedApplication1.Name)
+
+		application.Name = "application2"
+		err = c.SaveApplication(ctx(t), &application)
+		require.NoError(t, err)
+
+		storedApplication2, err := c.GetApplicationByID(ctx(t), application.ID)
+		require.NoError(t, err)
+		require.Equal(t, application.ID, storedApplication2.ID)
+		require.Equal(t, application.Cluster, storedApplication2.Cluster)
+		require.Equal(t, application.Namespace, storedApplication2.Namespace)
+		require.Equal(t, application.Name, storedApplication2.Name)
+	})
+
+	t.Run("SaveAndGetDashboards", func(t *testing.T) {
+		dashboards := []dashboardv1.DashboardSpec{{
+			ID:        "/cluster/test-cluster/namespace/default/name/dashboard1",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "dashboard1",
+		}, {
+			ID:        "/cluster/test-cluster/namespace/default/name/dashboard2",
+			Cluster:   "test-cluster",
+			Namespace: "kube-system",
+			Name:      "dashboard2",
+		}}
+
+		err := c.SaveDashboards(ctx(t), "test-cluster", dashboards)
+		require.NoError(t, err)
+
+		storedDashboards1, err := c.GetDashboards(ctx(t), nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(storedDashboards1))
+
+		storedDashboards2, err := c.GetDashboards(ctx(t), []string{"test-cluster"}, []string{"default"})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(storedDashboards2))
+
+		err = c.SaveDashboards(ctx(t), "test-cluster", dashboards[0:1])
+		require.NoError(t, err)
+
+		storedDashboards3, err := c.GetDashboards(ctx(t), nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(storedDashboards3))
+	})
+
+	t.Run("SaveAndGetTeams", func(t *testing.T) {
+		teams := []teamv1.TeamSpec{{
+			ID:        "/cluster/test-cluster/namespace/default/name/team1",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "team1",
+		}, {
+			ID:        "/cluster/test-cluster/namespace/default/name/team2",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "team2",
+		}}
+
+		err := c.SaveTeams(ctx(t), "test-cluster", teams)
+		require.NoError(t, err)
+
+		storedTeams1, err := c.GetTeams(ctx(t), "")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(storedTeams1))
+
+		err = c.SaveTeams(ctx(t), "test-cluster", teams[0:1])
+		require.NoError(t, err)
+
+		storedTeams2, err := c.GetTeams(ctx(t), "")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(storedTeams2))
+	})
+
+	t.Run("SaveAndGetTeam", func(t *testing.T) {
+		team := teamv1.TeamSpec{
+			ID:        "/cluster/test-cluster/namespace/default/name/team1",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "team1",
+		}
+
+		err := c.SaveTeam(ctx(t), &team)
+		require.NoError(t, err)
+
+		storedTeam1, err := c.GetTeamByID(ctx(t), team.ID)
+		require.NoError(t, err)
+		require.Equal(t, team.ID, storedTeam1.ID)
+		require.Equal(t, team.Cluster, storedTeam1.Cluster)
+		require.Equal(t, team.Namespace, storedTeam1.Namespace)
+		require.Equal(t, team.Name, storedTeam1.Name)
+
+		team.Name = "team2"
+		err = c.SaveTeam(ctx(t), &team)
+		require.NoError(t, err)
+
+		storedTeam2, err := c.GetTeamByID(ctx(t), team.ID)
+		require.NoError(t, err)
+		require.Equal(t, team.ID, storedTeam2.ID)
+		require.Equal(t, team.Cluster, storedTeam2.Cluster)
+		require.Equal(t, team.Namespace, storedTeam2.Namespace)
+		require.Equal(t, team.Name, storedTeam2.Name)
+	})
+
+	t.Run("SaveAndDeleteApplication", func(t *testing.T) {
+		application := applicationv1.ApplicationSpec{
+			ID:        "/cluster/test-cluster/namespace/default/name/application1",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "application1",
+			Topology: applicationv1.Topology{
+				Dependencies: []applicationv1.Dependency{{Cluster: "test-cluster", Namespace: "default", Name: "application2"}},
+			},
+		}
+
+		err := c.SaveApplication(ctx(t), &application)
+		require.NoError(t, err)
+
+		err = c.SaveApplicationTopology(ctx(t), &application)
+		require.NoError(t, err)
+
+		topology, err := c.GetTopologyByIDs(ctx(t), "sourceID", []string{application.ID})
+		require.NoError(t, err)
+		require.Len(t, topology, 1)
+
+		err = c.DeleteApplication(ctx(t), application.ID)
+		require.NoError(t, err)
+
+		_, err = c.GetApplicationByID(ctx(t), application.ID)
+		require.Error(t, err)
+
+		topology, err = c.GetTopologyByIDs(ctx(t), "sourceID", []string{application.ID})
+		require.NoError(t, err)
+		require.Empty(t, topology)
+	})
+
+	t.Run("SaveAndDeleteDashboard", func(t *testing.T) {
+		dashboard := dashboardv1.DashboardSpec{
+			ID:        "/cluster/test-cluster/namespace/default/name/dashboard1",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "dashboard1",
+		}
+
+		err := c.SaveDashboard(ctx(t), &dashboard)
+		require.NoError(t, err)
+
+		storedDashboard, err := c.GetDashboardByID(ctx(t), dashboard.ID)
+		require.NoError(t, err)
+		require.Equal(t, dashboard.Name, storedDashboard.Name)
+
+		err = c.DeleteDashboard(ctx(t), dashboard.ID)
+		require.NoError(t, err)
+
+		_, err = c.GetDashboardByID(ctx(t), dashboard.ID)
+		require.Error(t, err)
+	})
+
+	t.Run("DeleteTeamAndUser", func(t *testing.T) {
+		team := teamv1.TeamSpec{ID: "team1@kobs.io", Cluster: "test-cluster", Namespace: "default", Name: "team1"}
+		user := userv1.UserSpec{ID: "user1@kobs.io", Cluster: "test-cluster", Namespace: "default", Name: "user1"}
+
+		require.NoError(t, c.SaveTeam(ctx(t), &team))
+		require.NoError(t, c.SaveUser(ctx(t), &user))
+
+		require.NoError(t, c.DeleteTeam(ctx(t), team.ID))
+		require.NoError(t, c.DeleteUser(ctx(t), user.ID))
+
+		_, err := c.GetTeamByID(ctx(t), team.ID)
+		require.Error(t, err)
+
+		storedUser, err := c.GetUserByID(ctx(t), user.ID)
+		require.NoError(t, err)
+		require.Nil(t, storedUser)
+	})
+
+	t.Run("SaveAndGetUsers", func(t *testing.T) {
+		users := []userv1.UserSpec{{
+			ID:        "/cluster/test-cluster/namespace/default/name/user1",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "user1",
+		}, {
+			ID:        "/cluster/test-cluster/namespace/default/name/user2",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "user2",
+		}}
+
+		err := c.SaveUsers(ctx(t), "test-cluster", users)
+		require.NoError(t, err)
+
+		storedUsers1, err := c.GetUsers(ctx(t))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(storedUsers1))
+
+		err = c.SaveUsers(ctx(t), "test-cluster", users[0:1])
+		require.NoError(t, err)
+
+		storedUsers2, err := c.GetUsers(ctx(t))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(storedUsers2))
+	})
+
+	t.Run("SaveAndGetUser", func(t *testing.T) {
+		user := userv1.UserSpec{
+			ID:        "/cluster/test-cluster/namespace/default/name/user1",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "user1",
+		}
+
+		err := c.SaveUser(ctx(t), &user)
+		require.NoError(t, err)
+
+		storedUser1, err := c.GetUserByID(ctx(t), user.ID)
+		require.NoError(t, err)
+		require.Equal(t, user.ID, storedUser1.ID)
+		require.Equal(t, user.Cluster, storedUser1.Cluster)
+		require.Equal(t, user.Namespace, storedUser1.Namespace)
+		require.Equal(t, user.Name, storedUser1.Name)
+
+		user.Name = "user2"
+		err = c.SaveUser(ctx(t), &user)
+		require.NoError(t, err)
+
+		storedUser2, err := c.GetUserByID(ctx(t), user.ID)
+		require.NoError(t, err)
+		require.Equal(t, user.ID, storedUser2.ID)
+		require.Equal(t, user.Cluster, storedUser2.Cluster)
+		require.Equal(t, user.Namespace, storedUser2.Namespace)
+		require.Equal(t, user.Name, storedUser2.Name)
+	})
+
+	t.Run("SaveAndGetTags", func(t *testing.T) {
+		applications := []applicationv1.ApplicationSpec{{
+			ID:        "/cluster/test-cluster/namespace/default/name/application1",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "application1",
+			Tags:      []string{"tag1", "tag2", "tag3"},
+		}, {
+			ID:        "/cluster/test-cluster/namespace/default/name/application2",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "application2",
+			Tags:      []string{"tag3", "tag4", "tag5"},
+		}}
+
+		err := c.SaveTags(ctx(t), applications)
+		require.NoError(t, err)
+
+		storedTags1, err := c.GetTags(ctx(t))
+		require.NoError(t, err)
+		require.Equal(t, 5, len(storedTags1))
+	})
+
+	t.Run("SaveAndGetTopology", func(t *testing.T) {
+		applications := []applicationv1.ApplicationSpec{{
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "application1",
+			Topology: applicationv1.Topology{
+				Dependencies: []applicationv1.Dependency{{
+					Cluster:   "test-cluster",
+					Namespace: "default",
+					Name:      "application2",
+				}},
+			},
+		}, {
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "application2",
+		}}
+
+		err := c.SaveTopology(ctx(t), "test-cluster", applications)
+		require.NoError(t, err)
+
+		storedTopology1, err := c.GetTopologyByIDs(ctx(t), "targetID", []string{"/cluster/test-cluster/namespace/default/name/application2"})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(storedTopology1))
+
+		storedTopology2, err := c.GetTopologyByIDs(ctx(t), "sourceID", []string{"/cluster/test-cluster/namespace/default/name/application1"})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(storedTopology2))
+	})
+
+	t.Run("GetNamespacesByClusters", func(t *testing.T) {
+		err := c.SaveNamespaces(ctx(t), "test-cluster1", []string{"default", "kube-system"})
+		require.NoError(t, err)
+		err = c.SaveNamespaces(ctx(t), "test-cluster2", []string{"default", "kube-system", "istio-system"})
+		require.NoError(t, err)
+
+		storedNamespaces1, err := c.GetNamespacesByClusters(ctx(t), []string{"test-cluster1"})
+		require.NoError(t, err)
+		require.Equal(t, 2, len(storedNamespaces1))
+
+		storedNamespaces2, err := c.GetNamespacesByClusters(ctx(t), []string{"test-cluster1", "test-cluster2"})
+		require.NoError(t, err)
+		require.Equal(t, 5, len(storedNamespaces2))
+
+		storedNamespaces3, err := c.GetNamespacesByClusters(ctx(t), []string{})
+		require.NoError(t, err)
+		require.Equal(t, 5, len(storedNamespaces3))
+
+		storedNamespaces4, err := c.GetNamespacesByClusters(ctx(t), nil)
+		require.NoError(t, err)
+		require.Equal(t, 5, len(storedNamespaces4))
+	})
+
+	t.Run("GetCRDByID", func(t *testing.T) {
+		crds := []kubernetes.CRD{
+			{ID: "resource1.path1/v1", Resource: "resource1", Path: "path1/v1"},
+			{ID: "resource2.path2/v2", Resource: "resource2", Path: "path2/v2"},
+		}
+
+		err := c.SaveCRDs(ctx(t), crds)
+		require.NoError(t, err)
+
+		crd1, err := c.GetCRDByID(ctx(t), "resource1.path1/v1")
+		require.NoError(t, err)
+		require.NotNil(t, crd1)
+
+		crd2, err := c.GetCRDByID(ctx(t), "resource3.path3/v3")
+		require.Error(t, err)
+		require.Nil(t, crd2)
+	})
+
+	t.Run("GetApplicationsByFilter", func(t *testing.T) {
+		applications1 := []applicationv1.ApplicationSpec{
+			{ID: "cluster/test-cluster1/namespace/default/application1", Cluster: "test-cluster1", Namespace: "default", Name: "application1", Teams: []string{"team1"}, Tags: []string{"monitoring", "observability"}, Topology: applicationv1.Topology{External: false}},
+			{ID: "cluster/test-cluster1/namespace/default/application2", Cluster: "test-cluster1", Namespace: "default", Name: "application2", Teams: []string{"team1", "team2"}, Tags: []string{"monitoring", "observability"}, Topology: applicationv1.Topology{External: false}},
+			{ID: "cluster/test-cluster1/namespace/default/application3", Cluster: "test-cluster1", Namespace: "default", Name: "application3", Teams: []string{"team3"}, Tags: []string{}, Topology: applicationv1.Topology{External: true}},
+		}
+		applications2 := []applicationv1.ApplicationSpec{
+			{ID: "cluster/test-cluster2/namespace/default/application4", Cluster: "test-cluster2", Namespace: "default", Name: "application4", Teams: []string{"team1", "team2", "team3"}, Tags: []string{"monitoring", "observability"}, Topology: applicationv1.Topology{External: false}},
+			{ID: "cluster/test-cluster2/namespace/default/application5", Cluster: "test-cluster2", Namespace: "default", Name: "application5", Teams: []string{}, Tags: []string{"monitoring", "observability"}, Topology: applicationv1.Topology{External: false}},
+			{ID: "cluster/test-cluster2/namespace/kube-system/application6", Cluster: "test-cluster2", Namespace: "kube-system", Name: "application6", Teams: []string{"team1"}, Tags: []string{"core", "provider"}, Topology: applicationv1.Topology{External: false}},
+			{ID: "cluster/test-cluster2/namespace/kube-system/application7", Cluster: "test-cluster2", Namespace: "kube-system", Name: "application7", Teams: []string{}, Tags: []string{"core", "provider"}, Topology: applicationv1.Topology{External: false}},
+			{ID: "cluster/test-cluster2/namespace/kube-system/application8", Cluster: "test-cluster2", Namespace: "kube-system", Name: "application8", Teams: []string{"team3"}, Tags: []string{"core", "provider"}, Topology: applicationv1.Topology{External: false}},
+			{ID: "cluster/test-cluster2/namespace/kube-system/application9", Cluster: "test-cluster2", Namespace: "kube-system", Name: "application9", Teams: []string{"team1"}, Tags: []string{"core", "provider"}, Topology: applicationv1.Topology{External: false}},
+			{ID: "cluster/test-cluster2/namespace/kube-system/application10", Cluster: "test-cluster2", Namespace: "kube-system", Name: "application10", Teams: []string{}, Tags: []string{"core", "provider", "logging"}, Topology: applicationv1.Topology{External: true}},
+		}
+
+		err := c.SaveApplications(ctx(t), "test-cluster1", applications1)
+		require.NoError(t, err)
+		err = c.SaveApplications(ctx(t), "test-cluster2", applications2)
+		require.NoError(t, err)
+		err = c.SaveApplicationsHealth(ctx(t), []ApplicationHealth{
+			{Application: "cluster/test-cluster1/namespace/default/application1", Status: HealthStatusHealthy, Timestamp: time.Now()},
+			{Application: "cluster/test-cluster1/namespace/default/application2", Status: HealthStatusUnhealthy, Timestamp: time.Now()},
+			{Application: "cluster/test-cluster2/namespace/default/application4", Status: HealthStatusDegraded, Timestamp: time.Now()},
+		})
+		require.NoError(t, err)
+
+		getApplicationsNames := func(storedApplications []applicationv1.ApplicationSpec) []string {
+			var names []string
+			for _, a := range storedApplications {
+				names = append(names, a.Name)
+			}
+			return names
+		}
+
+		ctx := ctx(t)
+		for _, tt := range []struct {
+			name                 string
+			teams                []string
+			clusters             []string
+			namespaces           []string
+			tags                 []string
+			health               []string
+			searchTerm           string
+			limit                int
+			offset               int
+			expectedError        bool
+			expectedApplications []string
+			expectedCount        int
+		}{
+			{name: "searchTerm can not be compiled to regexp", teams: nil, clusters: nil, namespaces: nil, tags: nil, searchTerm: "*", limit: 100, offset: 0, expectedError: true, expectedApplications: nil, expectedCount: 0},
+			{name: "no filters", teams: nil, clusters: nil, namespaces: nil, tags: nil, searchTerm: "", limit: 100, offset: 0, expectedError: false, expectedApplications: []string{"application1", "application10", "application2", "application3", "application4", "application5", "application6", "application7", "application8", "application9"}, expectedCount: 10},
+			{name: "no filters but limit", teams: nil, clusters: nil, namespaces: nil, tags: nil, searchTerm: "", limit: 5, offset: 0, expectedError: false, expectedApplications: []string{"application1", "application10", "application2", "application3", "application4"}, expectedCount: 10},
+			{name: "no filters but limit and offset", teams: nil, clusters: nil, namespaces: nil, tags: nil, searchTerm: "", limit: 5, offset: 5, expectedError: false, expectedApplications: []string{"application5", "application6", "application7", "application8", "application9"}, expectedCount: 10},
+			{name: "only searchTerm", teams: nil, clusters: nil, namespaces: nil, tags: nil, searchTerm: "application1", limit: 100, offset: 0, expectedError: false, expectedApplications: []string{"application1", "application10"}, expectedCount: 2},
+			{name: "filter by team", teams: []string{"team2"}, clusters: nil, namespaces: nil, tags: nil, searchTerm: "", limit: 100, offset: 0, expectedError: false, expectedApplications: []string{"application2", "application4"}, expectedCount: 2},
+			{name: "filter by teams", teams: []string{"team1", "team3"}, clusters: nil, namespaces: nil, tags: nil, searchTerm: "", limit: 100, offset: 0, expectedError: false, expectedApplications: []string{"application1", "application2", "application3", "application4", "application6", "application8", "application9"}, expectedCount: 7},
+			{name: "filter by cluster and namespace", teams: nil, clusters: []string{"test-cluster1", "test-cluster2"}, namespaces: []string{"default"}, tags: nil, searchTerm: "", limit: 100, offset: 0, expectedError: false, expectedApplications: []string{"application1", "application2", "application3", "application4", "application5"}, expectedCount: 5},
+			{name: "filter by tags", teams: nil, clusters: nil, namespaces: nil, tags: []string{"logging"}, searchTerm: "", limit: 100, offset: 0, expectedError: false, expectedApplications: []string{"application10"}, expectedCount: 1},
+			{name: "filter by health", teams: nil, clusters: nil, namespaces: nil, tags: nil, health: []string{"degraded", "unhealthy"}, searchTerm: "", limit: 100, offset: 0, expectedError: false, expectedApplications: []string{"application2", "application4"}, expectedCount: 2},
+			{name: "filter by team and health", teams: []string{"team1"}, clusters: nil, namespaces: nil, tags: nil, health: []string{"healthy"}, searchTerm: "", limit: 100, offset: 0, expectedError: false, expectedApplications: []string{"application1"}, expectedCount: 1},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				storedApplications, err := c.GetApplicationsByFilter(ctx, tt.teams, tt.clusters, tt.namespaces, tt.tags, tt.health, tt.searchTerm, tt.limit, tt.offset)
+				if tt.expectedError {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+				require.Equal(t, tt.expectedApplications, getApplicationsNames(storedApplications))
+
+				count, err := c.GetApplicationsByFilterCount(ctx, tt.teams, tt.clusters, tt.namespaces, tt.tags, tt.health, tt.searchTerm)
+				if tt.expectedError {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+				require.Equal(t, tt.expectedCount, count)
+			})
+		}
+	})
+
+	t.Run("GetApplicationsByGroup", func(t *testing.T) {
+		applications1 := []applicationv1.ApplicationSpec{
+			{ID: "1", Cluster: "test-cluster1", Namespace: "default", Name: "application1", Teams: []string{"team1"}},
+			{ID: "2", Cluster: "test-cluster1", Namespace: "default", Name: "application2", Teams: []string{"team1"}},
+			{ID: "3", Cluster: "test-cluster1", Namespace: "kube-system", Name: "application3", Teams: []string{"team1"}},
+			{ID: "4", Cluster: "test-cluster1", Namespace: "kube-system", Name: "application4", Teams: []string{"team1"}},
+		}
+		applications2 := []applicationv1.ApplicationSpec{
+			{ID: "5", Cluster: "test-cluster2", Namespace: "default", Name: "application1", Teams: []string{"team1"}},
+			{ID: "6", Cluster: "test-cluster2", Namespace: "default", Name: "application2", Teams: []string{"team1"}},
+			{ID: "7", Cluster: "test-cluster2", Namespace: "kube-system", Name: "application3", Teams: []string{"team1"}},
+			{ID: "8", Cluster: "test-cluster2", Namespace: "kube-system", Name: "application4", Teams: []string{"team1"}},
+			{ID: "9", Cluster: "test-cluster2", Namespace: "kube-system", Name: "application5", Teams: []string{"team1"}},
+			{ID: "10", Cluster: "test-cluster2", Namespace: "kube-system", Name: "application6", Teams: []string{"team1"}},
+		}
+
+		err := c.SaveApplications(ctx(t), "test-cluster1", applications1)
+		require.NoError(t, err)
+		err = c.SaveApplications(ctx(t), "test-cluster2", applications2)
+		require.NoError(t, err)
+
+		ctx := ctx(t)
+		for _, tt := range []struct {
+			name           string
+			teams          []string
+			groups         []string
+			expectedGroups []ApplicationGroup
+		}{
+			{name: "require groups and teams", teams: nil, groups: nil, expectedGroups: nil},
+			{name: "team must be in group", teams: []string{"team2"}, groups: []string{"name", "namespace"}, expectedGroups: nil},
+			{name: "return groups", teams: []string{"team1"}, groups: []string{"name", "namespace"}, expectedGroups: []ApplicationGroup{
+				{ID: ApplicationGroupID{Cluster: "", Namespace: "default", Name: "application1"}, Clusters: []string{"test-cluster1", "test-cluster2"}, Namespaces: []string{"default", "default"}, Names: []string{"application1", "application1"}, Description: "", Teams: []string{"team1"}},
+				{ID: ApplicationGroupID{Cluster: "", Namespace: "default", Name: "application2"}, Clusters: []string{"test-cluster1", "test-cluster2"}, Namespaces: []string{"default", "default"}, Names: []string{"application2", "application2"}, Description: "", Teams: []string{"team1"}},
+				{ID: ApplicationGroupID{Cluster: "", Namespace: "kube-system", Name: "application3"}, Clusters: []string{"test-cluster1", "test-cluster2"}, Namespaces: []string{"kube-system", "kube-system"}, Names: []string{"application3", "application3"}, Description: "", Teams: []string{"team1"}},
+				{ID: ApplicationGroupID{Cluster: "", Namespace: "kube-system", Name: "application4"}, Clusters: []string{"test-cluster1", "test-cluster2"}, Namespaces: []string{"kube-system", "kube-system"}, Names: []string{"application4", "application4"}, Description: "", Teams: []string{"team1"}},
+				{ID: ApplicationGroupID{Cluster: "", Namespace: "kube-system", Name: "application5"}, Clusters: []string{"test-cluster2"}, Namespaces: []string{"kube-system"}, Names: []string{"application5"}, Description: "", Teams: []string{"team1"}},
+				{ID: ApplicationGroupID{Cluster: "", Namespace: "kube-system", Name: "application6"}, Clusters: []string{"test-cluster2"}, Namespaces: []string{"kube-system"}, Names: []string{"application6"}, Description: "", Teams: []string{"team1"}},
+			}},
+			{name: "return groups for multiple teams", teams: []string{"team1", "team2"}, groups: []string{"name", "namespace"}, expectedGroups: []ApplicationGroup{
+				{ID: ApplicationGroupID{Cluster: "", Namespace: "default", Name: "application1"}, Clusters: []string{"test-cluster1", "test-cluster2"}, Namespaces: []string{"default", "default"}, Names: []string{"application1", "application1"}, Description: "", Teams: []string{"team1"}},
+				{ID: ApplicationGroupID{Cluster: "", Namespace: "default", Name: "application2"}, Clusters: []string{"test-cluster1", "test-cluster2"}, Namespaces: []string{"default", "default"}, Names: []string{"application2", "application2"}, Description: "", Teams: []string{"team1"}},
+				{ID: ApplicationGroupID{Cluster: "", Namespace: "kube-system", Name: "application3"}, Clusters: []string{"test-cluster1", "test-cluster2"}, Namespaces: []string{"kube-system", "kube-system"}, Names: []string{"application3", "application3"}, Description: "", Teams: []string{"team1"}},
+				{ID: ApplicationGroupID{Cluster: "", Namespace: "kube-system", Name: "application4"}, Clusters: []string{"test-cluster1", "test-cluster2"}, Namespaces: []string{"kube-system", "kube-system"}, Names: []string{"application4", "application4"}, Description: "", Teams: []string{"team1"}},
+				{ID: ApplicationGroupID{Cluster: "", Namespace: "kube-system", Name: "application5"}, Clusters: []string{"test-cluster2"}, Namespaces: []string{"kube-system"}, Names: []string{"application5"}, Description: "", Teams: []string{"team1"}},
+				{ID: ApplicationGroupID{Cluster: "", Namespace: "kube-system", Name: "application6"}, Clusters: []string{"test-cluster2"}, Namespaces: []string{"kube-system"}, Names: []string{"application6"}, Description: "", Teams: []string{"team1"}},
+			}},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				storedGroups, err := c.GetApplicationsByGroup(ctx, tt.teams, tt.groups)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedGroups, storedGroups)
+			})
+		}
+	})
+
+	t.Run("GetApplicationByID", func(t *testing.T) {
+		applications := []applicationv1.ApplicationSpec{{
+			ID:        "/cluster/test-cluster/namespace/default/name/application1",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "application1",
+		}, {
+			ID:        "/cluster/test-cluster/namespace/default/name/application2",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "application2",
+		}}
+
+		err := c.SaveApplications(ctx(t), "test-cluster", applications)
+		require.NoError(t, err)
+
+		storedApplication1, err := c.GetApplicationByID(ctx(t), "/cluster/test-cluster/namespace/default/name/application1")
+		require.NoError(t, err)
+		require.NotNil(t, storedApplication1)
+
+		storedApplication2, err := c.GetApplicationByID(ctx(t), "/cluster/test-cluster/namespace/default/name/application3")
+		require.Error(t, err)
+		require.Nil(t, storedApplication2)
+	})
+
+	t.Run("GetDashboardByID", func(t *testing.T) {
+		dashboards := []dashboardv1.DashboardSpec{{
+			ID:        "/cluster/test-cluster/namespace/default/name/dashboard1",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "dashboard1",
+		}, {
+			ID:        "/cluster/test-cluster/namespace/default/name/dashboard2",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "dashboard2",
+		}}
+
+		err := c.SaveDashboards(ctx(t), "test-cluster", dashboards)
+		require.NoError(t, err)
+
+		storedDashboard1, err := c.GetDashboardByID(ctx(t), "/cluster/test-cluster/namespace/default/name/dashboard1")
+		require.NoError(t, err)
+		require.NotNil(t, storedDashboard1)
+
+		storedDashboard2, err := c.GetDashboardByID(ctx(t), "/cluster/test-cluster/namespace/default/name/dashboard3")
+		require.Error(t, err)
+		require.Nil(t, storedDashboard2)
+	})
+
+	t.Run("GetTeamsByIDs", func(t *testing.T) {
+		teams := []teamv1.TeamSpec{{
+			ID:        "team1@kobs.io",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "team1",
+		}, {
+			ID:        "team2@kobs.io",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "team2",
+		}, {
+			ID:        "team3@kobs.io",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "team3",
+		}, {
+			ID:        "team3@kobs.io",
+			Cluster:   "stage-de1",
+			Namespace: "default",
+			Name:      "team3",
+		}}
+
+		err := c.SaveTeams(ctx(t), "test-cluster", teams)
+		require.NoError(t, err)
+
+		storedTeams1, err := c.GetTeamsByIDs(ctx(t), []string{"team1@kobs.io"}, "")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(storedTeams1))
+
+		storedTeams2, err := c.GetTeamsByIDs(ctx(t), []string{"team1@kobs.io", "team2@kobs.io"}, "")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(storedTeams2))
+
+		storedTeams3, err := c.GetTeamsByIDs(ctx(t), []string{}, "")
+		require.NoError(t, err)
+		require.Equal(t, 0, len(storedTeams3))
+
+		storedTeams4, err := c.GetTeamsByIDs(ctx(t), nil, "")
+		require.NoError(t, err)
+		require.Equal(t, 0, len(storedTeams4))
+
+		storedTeams5, err := c.GetTeamsByIDs(ctx(t), []string{"team3@kobs.io"}, "")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(storedTeams5))
+
+		storedTeams6, err := c.GetTeamsByIDs(ctx(t), []string{"team1@kobs.io", "team2@kobs.io"}, "team2")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(storedTeams6))
+	})
+
+	t.Run("GetTeamByID", func(t *testing.T) {
+		teams := []teamv1.TeamSpec{{
+			ID:        "team1@kobs.io",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "team1",
+			Dashboards: []dashboardv1.Reference{
+				{Cluster: "test-cluster", Namespace: "default", Name: "dashboard1", Title: "Dashboard 1"},
+			},
+		}, {
+			ID:        "team2@kobs.io",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "team2",
+			Dashboards: []dashboardv1.Reference{
+				{Cluster: "test-cluster", Namespace: "default", Name: "dashboard1", Title: "Dashboard 1"},
+			},
+		}, {
+			ID:        "team3@kobs.io",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "team3",
+			Dashboards: []dashboardv1.Reference{
+				{Cluster: "test-cluster", Namespace: "default", Name: "dashboard1", Title: "Dashboard 1"},
+			},
+		}}
+
+		err := c.SaveTeams(ctx(t), "test-cluster", teams)
+		require.NoError(t, err)
+
+		storedTeam1, err := c.GetTeamByID(ctx(t), "team1@kobs.io")
+		require.NoError(t, err)
+		require.NotNil(t, storedTeam1)
+
+		storedTeam2, err := c.GetTeamByID(ctx(t), "team4@kobs.io")
+		require.Error(t, err)
+		require.Nil(t, storedTeam2)
+	})
+
+	t.Run("GetGetUserByID", func(t *testing.T) {
+		users := []userv1.UserSpec{{
+			ID:        "user1@kobs.io",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "user1",
+		}, {
+			ID:        "user2@kobs.io",
+			Cluster:   "test-cluster",
+			Namespace: "default",
+			Name:      "user2",
+		}}
+
+		err := c.SaveUsers(ctx(t), "test-cluster", users)
+		require.NoError(t, err)
+
+		storedUser1, err := c.GetUserByID(ctx(t), "user1@kobs.io")
+		require.NoError(t, err)
+		require.NotNil(t, storedUser1)
+
+		storedUser2, err := c.GetUserByID(ctx(t), "user4@kobs.io")
+		require.NoError(t, err)
+		require.Nil(t, storedUser2)
+	})
+
+	t.Run("CreateSession", func(t *testing.T) {
+		session, err := c.CreateSession(ctx(t), authContext.User{ID: "userid"}, SessionMetadata{ExpiresAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		require.NotNil(t, session)
+	})
+
+	t.Run("GetSession", func(t *testing.T) {
+		session, err := c.CreateSession(ctx(t), authContext.User{ID: "userid"}, SessionMetadata{ExpiresAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		require.NotNil(t, session)
+
+		ctx := ctx(t)
+		t.Run("should return session", func(t *testing.T) {
+			actualSession, err := c.GetSession(ctx, session.ID)
+			require.NoError(t, err)
+			require.Equal(t, session.ID, actualSession.ID)
+			require.Equal(t, session.User.ID, actualSession.User.ID)
+		})
+
+		t.Run("should fail to return session, when session does not exists", func(t *testing.T) {
+			_, err := c.GetSession(ctx, "000000000000000000000000")
+			require.Error(t, err)
+			require.Equal(t, ErrSessionNotFound, err)
+		})
+	})
+
+	t.Run("GetAndUpdateSession", func(t *testing.T) {
+		session, err := c.CreateSession(ctx(t), authContext.User{ID: "userid"}, SessionMetadata{ExpiresAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		require.NotNil(t, session)
+
+		ctx := ctx(t)
+		t.Run("should return and update session", func(t *testing.T) {
+			actualSession, err := c.GetAndUpdateSession(ctx, session.ID)
+			require.NoError(t, err)
+			require.Equal(t, session.ID, actualSession.ID)
+			require.Equal(t, session.User.ID, actualSession.User.ID)
+		})
+
+		t.Run("should fail to update session, when session does not exists", func(t *testing.T) {
+			_, err := c.GetAndUpdateSession(ctx, "000000000000000000000000")
+			require.Error(t, err)
+			require.Equal(t, ErrSessionNotFound, err)
+		})
+	})
+
+	t.Run("DeleteSession", func(t *testing.T) {
+		session, err := c.CreateSession(ctx(t), authContext.User{ID: "userid"}, SessionMetadata{ExpiresAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		require.NotNil(t, session)
+
+		ctx := ctx(t)
+		t.Run("should delete session", func(t *testing.T) {
+			err := c.DeleteSession(ctx, session.ID)
+			require.NoError(t, err)
+		})
+
+		t.Run("should fail to delete session, when session does not exists", func(t *testing.T) {
+			err := c.DeleteSession(ctx, "000000000000000000000000")
+			require.Error(t, err)
+			require.Equal(t, ErrSessionNotFound, err)
+		})
+	})
+
+	t.Run("GetAndDeleteSessions", func(t *testing.T) {
+		ctx := ctx(t)
+
+		session1, err := c.CreateSession(ctx, authContext.User{ID: "user1"}, SessionMetadata{UserAgent: "curl/8.0.0", IP: "127.0.0.1", ExpiresAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		session2, err := c.CreateSession(ctx, authContext.User{ID: "user1"}, SessionMetadata{ExpiresAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		_, err = c.CreateSession(ctx, authContext.User{ID: "user1"}, SessionMetadata{ExpiresAt: time.Now().Add(-time.Hour)})
+		require.NoError(t, err)
+		session4, err := c.CreateSession(ctx, authContext.User{ID: "user2"}, SessionMetadata{ExpiresAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+
+		_, err = c.GetAndUpdateSession(ctx, session1.ID)
+		require.NoError(t, err)
+
+		t.Run("should return active sessions of user", func(t *testing.T) {
+			sessions, err := c.GetSessions(ctx, "user1")
+			require.NoError(t, err)
+			require.Len(t, sessions, 2)
+			require.Equal(t, session1.ID, sessions[0].ID)
+			require.Equal(t, "curl/8.0.0", sessions[0].UserAgent)
+			require.Equal(t, "127.0.0.1", sessions[0].IP)
+			require.Equal(t, session2.ID, sessions[1].ID)
+		})
+
+		t.Run("should delete all sessions of user", func(t *testing.T) {
+			err := c.DeleteSessions(ctx, "user1")
+			require.NoError(t, err)
+
+			sessions, err := c.GetSessions(ctx, "user1")
+			require.NoError(t, err)
+			require.Empty(t, sessions)
+
+			_, err = c.GetSession(ctx, session1.ID)
+			require.Equal(t, ErrSessionNotFound, err)
+
+			sessions, err = c.GetSessions(ctx, "user2")
+			require.NoError(t, err)
+			require.Len(t, sessions, 1)
+			require.Equal(t, session4.ID, sessions[0].ID)
+		})
+	})
+
+	t.Run("SaveAndGetDocuments", func(t *testing.T) {
+		type document struct {
+			ID    string `json:"id" bson:"_id"`
+			Group string `json:"group" bson:"group"`
+			Text  string `json:"text" bson:"text"`
+		}
+
+		documents := []Document{
+			{ID: "document1", Data: document{ID: "document1", Group: "group1", Text: "Hello World"}},
+			{ID: "document2", Data: document{ID: "document2", Group: "group1", Text: "Foo Bar"}},
+			{ID: "document3", Data: document{ID: "document3", Group: "group2", Text: "hello kobs"}},
+		}
+
+		err := c.SaveDocuments(ctx(t), "documents", documents)
+		require.NoError(t, err)
+
+		var storedDocuments1 []document
+		err = c.GetDocuments(ctx(t), "documents", DocumentQuery{}, &storedDocuments1)
+		require.NoError(t, err)
+		require.Equal(t, []document{{ID: "document1", Group: "group1", Text: "Hello World"}, {ID: "document2", Group: "group1", Text: "Foo Bar"}, {ID: "document3", Group: "group2", Text: "hello kobs"}}, storedDocuments1)
+
+		var storedDocuments2 []document
+		err = c.GetDocuments(ctx(t), "documents", DocumentQuery{ID: "document2"}, &storedDocuments2)
+		require.NoError(t, err)
+		require.Equal(t, []document{{ID: "document2", Group: "group1", Text: "Foo Bar"}}, storedDocuments2)
+
+		var storedDocuments3 []document
+		err = c.GetDocuments(ctx(t), "documents", DocumentQuery{Fields: map[string]string{"group": "group1"}}, &storedDocuments3)
+		require.NoError(t, err)
+		require.Equal(t, []document{{ID: "document1", Group: "group1", Text: "Hello World"}, {ID: "document2", Group: "group1", Text: "Foo Bar"}}, storedDocuments3)
+
+		var storedDocuments4 []document
+		err = c.GetDocuments(ctx(t), "documents", DocumentQuery{Search: "hello", SearchFields: []string{"text"}}, &storedDocuments4)
+		require.NoError(t, err)
+		require.Equal(t, []document{{ID: "document1", Group: "group1", Text: "Hello World"}, {ID: "document3", Group: "group2", Text: "hello kobs"}}, storedDocuments4)
+
+		err = c.SaveDocuments(ctx(t), "documents", documents[:1])
+		require.NoError(t, err)
+
+		var storedDocuments5 []document
+		err = c.GetDocuments(ctx(t), "documents", DocumentQuery{}, &storedDocuments5)
+		require.NoError(t, err)
+		require.Equal(t, []document{{ID: "document1", Group: "group1", Text: "Hello World"}}, storedDocuments5)
+
+		var storedDocuments6 []document
+		err = c.GetDocuments(ctx(t), "other", DocumentQuery{}, &storedDocuments6)
+		require.NoError(t, err)
+		require.Empty(t, storedDocuments6)
+	})
+
+	t.Run("SaveAndGetAuditEvents", func(t *testing.T) {
+		ctx := ctx(t)
+		events := []AuditEvent{
+			{ID: "event1", Timestamp: 1000, User: "user1", Cluster: "cluster1", Namespace: "namespace1", Resource: "pods", Name: "pod1", Verb: "delete", Method: "DELETE", StatusCode: 200, Result: "success"},
+			{ID: "event2", Timestamp: 2000, User: "user2", Cluster: "cluster1", Namespace: "namespace2", Resource: "deployments", Name: "deployment1", Verb: "patch", Method: "PUT", StatusCode: 500, Result: "failure"},
+			{ID: "event3", Timestamp: 3000, User: "user1", Cluster: "cluster2", Plugin: "flux", Verb: "sync", Method: "GET", StatusCode: 200, Result: "success"},
+		}
+
+		for _, event := range events {
+			err := c.SaveAuditEvent(ctx, &event)
+			require.NoError(t, err)
+		}
+
+		for _, tt := range []struct {
+			name      string
+			user      string
+			cluster   string
+			namespace string
+			verb      string
+			timeStart int64
+			timeEnd   int64
+			limit     int
+			offset    int
+			expected  []string
+		}{
+			{name: "should return all events", expected: []string{"event3", "event2", "event1"}},
+			{name: "should return events for user", user: "user1", expected: []string{"event3", "event1"}},
+			{name: "should return events for cluster and namespace", cluster: "cluster1", namespace: "namespace2", expected: []string{"event2"}},
+			{name: "should return events for verb", verb: "sync", expected: []string{"event3"}},
+			{name: "should return events for time range", timeStart: 1500, timeEnd: 3000, expected: []string{"event3", "event2"}},
+			{name: "should return events with limit and offset", limit: 1, offset: 1, expected: []string{"event2"}},
+			{name: "should return no events", user: "user3", expected: nil},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				storedEvents, err := c.GetAuditEvents(ctx, tt.user, tt.cluster, tt.namespace, tt.verb, tt.timeStart, tt.timeEnd, tt.limit, tt.offset)
+				require.NoError(t, err)
+
+				var ids []string
+				for _, event := range storedEvents {
+					ids = append(ids, event.ID)
+				}
+				require.Equal(t, tt.expected, ids)
+			})
+		}
+	})
+
+	t.Run("APITokens", func(t *testing.T) {
+		ctx := ctx(t)
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		tokens := []APIToken{
+			{ID: "token1", Name: "token1", Hash: "hash1", User: authContext.User{ID: "user1"}, CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(time.Hour)},
+			{ID: "token2", Name: "token2", Hash: "hash2", User: authContext.User{ID: "user1"}, CreatedAt: now.Add(-1 * time.Hour), ExpiresAt: now.Add(time.Hour)},
+			{ID: "token3", Name: "token3", Hash: "hash3", User: authContext.User{ID: "user1"}, CreatedAt: now.Add(-3 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+			{ID: "token4", Name: "token4", Hash: "hash4", User: authContext.User{ID: "user2"}, CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		}
+
+		for _, token := range tokens {
+			err := c.CreateAPIToken(ctx, &token)
+			require.NoError(t, err)
+		}
+
+		t.Run("should return token", func(t *testing.T) {
+			token, err := c.GetAPIToken(ctx, "token1")
+			require.NoError(t, err)
+			require.Equal(t, "hash1", token.Hash)
+			require.Equal(t, "user1", token.User.ID)
+			require.True(t, tokens[0].ExpiresAt.Equal(token.ExpiresAt))
+		})
+
+		t.Run("should fail to return expired token", func(t *testing.T) {
+			_, err := c.GetAPIToken(ctx, "token3")
+			require.Equal(t, ErrAPITokenNotFound, err)
+		})
+
+		t.Run("should return tokens of user", func(t *testing.T) {
+			storedTokens, err := c.GetAPITokens(ctx, "user1")
+			require.NoError(t, err)
+
+			var ids []string
+			for _, token := range storedTokens {
+				ids = append(ids, token.ID)
+			}
+			require.Equal(t, []string{"token1", "token2"}, ids)
+		})
+
+		t.Run("should fail to delete token of other user", func(t *testing.T) {
+			err := c.DeleteAPIToken(ctx, "user2", "token1")
+			require.Equal(t, ErrAPITokenNotFound, err)
+		})
+
+		t.Run("should delete token", func(t *testing.T) {
+			err := c.DeleteAPIToken(ctx, "user1", "token1")
+			require.NoError(t, err)
+
+			_, err = c.GetAPIToken(ctx, "token1")
+			require.Equal(t, ErrAPITokenNotFound, err)
+		})
+	})
+
+	t.Run("SaveAndGetSyncRuns", func(t *testing.T) {
+		ctx := ctx(t)
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		runs := []SyncRun{
+			{ID: "run1", Cluster: "cluster1", Resource: "applications", Timestamp: now.Add(-2 * time.Minute), Duration: 100, Count: 10, Failures: 0, Healthy: true},
+			{ID: "run2", Cluster: "cluster1", Resource: "applications", Timestamp: now.Add(-1 * time.Minute), Duration: 200, Error: "unexpected error", Failures: 1, Healthy: true},
+			{ID: "run3", Cluster: "cluster1", Resource: "teams", Timestamp: now.Add(-3 * time.Minute), Duration: 50, Count: 2, Failures: 0, Healthy: true},
+			{ID: "run4", Cluster: "cluster2", Resource: "applications", Timestamp: now, Duration: 300, Error: "unexpected error", Failures: 3, Healthy: false},
+		}
+
+		for _, run := range runs {
+			err := c.SaveSyncRun(ctx, &run)
+			require.NoError(t, err)
+		}
+
+		for _, tt := range []struct {
+			name     string
+			cluster  string
+			resource string
+			limit    int
+			expected []string
+		}{
+			{name: "should return all sync runs", expected: []string{"run4", "run2", "run1", "run3"}},
+			{name: "should return sync runs for cluster", cluster: "cluster1", expected: []string{"run2", "run1", "run3"}},
+			{name: "should return sync runs for cluster and resource", cluster: "cluster1", resource: "applications", expected: []string{"run2", "run1"}},
+			{name: "should return sync runs with limit", cluster: "cluster1", limit: 1, expected: []string{"run2"}},
+			{name: "should return no sync runs", cluster: "cluster3", expected: nil},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				storedRuns, err := c.GetSyncRuns(ctx, tt.cluster, tt.resource, tt.limit)
+				require.NoError(t, err)
+
+				var ids []string
+				for _, run := range storedRuns {
+					ids = append(ids, run.ID)
+				}
+				require.Equal(t, tt.expected, ids)
+			})
+		}
+
+		t.Run("should return last sync runs", func(t *testing.T) {
+			storedRuns, err := c.GetLastSyncRuns(ctx)
+			require.NoError(t, err)
+
+			var ids []string
+			for _, run := range storedRuns {
+				ids = append(ids, run.ID)
+			}
+			require.Equal(t, []string{"run2", "run3", "run4"}, ids)
+		})
+	})
+
+	t.Run("SaveAndGetApplicationsHealth", func(t *testing.T) {
+		ctx := ctx(t)
+		now := time.Now().UTC().Truncate(time.Millisecond)
+
+		err := c.SaveApplicationsHealth(ctx, []ApplicationHealth{
+			{Application: "application1", Status: HealthStatusHealthy, Timestamp: now.Add(-2 * time.Minute)},
+			{Application: "application2", Status: HealthStatusHealthy, Timestamp: now.Add(-2 * time.Minute)},
+		})
+		require.NoError(t, err)
+
+		err = c.SaveApplicationsHealth(ctx, []ApplicationHealth{
+			{Application: "application1", Status: HealthStatusHealthy, Timestamp: now.Add(-1 * time.Minute)},
+			{Application: "application2", Status: HealthStatusDegraded, Reasons: []ApplicationHealthReason{{Type: "workload", Name: "Deployment/application2", Status: HealthStatusDegraded, Message: "1 of 2 replicas are ready"}}, Timestamp: now.Add(-1 * time.Minute)},
+		})
+		require.NoError(t, err)
+
+		err = c.SaveApplicationsHealth(ctx, []ApplicationHealth{
+			{Application: "application2", Status: HealthStatusDegraded, Reasons: []ApplicationHealthReason{{Type: "workload", Name: "Deployment/application2", Status: HealthStatusDegraded, Message: "1 of 2 replicas are ready"}}, Timestamp: now},
+			{Application: "application3", Status: HealthStatusUnknown, Timestamp: now},
+		})
+		require.NoError(t, err)
+
+		t.Run("should return health of all applications", func(t *testing.T) {
+			health, err := c.GetApplicationsHealth(ctx, nil)
+			require.NoError(t, err)
+			require.Len(t, health, 2)
+			require.Equal(t, "application2", health[0].ID)
+			require.Equal(t, HealthStatusDegraded, health[0].Status)
+			require.Equal(t, []ApplicationHealthReason{{Type: "workload", Name: "Deployment/application2", Status: HealthStatusDegraded, Message: "1 of 2 replicas are ready"}}, health[0].Reasons)
+			require.Equal(t, "application3", health[1].ID)
+			require.Equal(t, HealthStatusUnknown, health[1].Status)
+		})
+
+		t.Run("should return health of application", func(t *testing.T) {
+			health, err := c.GetApplicationsHealth(ctx, []string{"application3"})
+			require.NoError(t, err)
+			require.Len(t, health, 1)
+			require.Equal(t, "application3", health[0].Application)
+			require.True(t, now.Equal(health[0].Timestamp))
+		})
+
+		for _, tt := range []struct {
+			name     string
+			id       string
+			limit    int
+			expected []string
+		}{
+			{name: "should return only changed health", id: "application1", expected: []string{HealthStatusHealthy}},
+			{name: "should return health history", id: "application2", expected: []string{HealthStatusDegraded, HealthStatusHealthy}},
+			{name: "should return health history with limit", id: "application2", limit: 1, expected: []string{HealthStatusDegraded}},
+			{name: "should return no health history", id: "application4", expected: nil},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				history, err := c.GetApplicationHealthHistory(ctx, tt.id, tt.limit)
+				require.NoError(t, err)
+
+				var statuses []string
+				for _, h := range history {
+					statuses = append(statuses, h.Status)
+				}
+				require.Equal(t, tt.expected, statuses)
+			})
+		}
+	})
+
+	t.Run("SaveAndGetTerminalRecordings", func(t *testing.T) {
+		ctx := ctx(t)
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		recordings := []TerminalRecording{
+			{ID: "recording1", User: "user1", Cluster: "cluster1", Namespace: "default", Pod: "pod1", Container: "container1", Shell: "bash", Timestamp: now.Add(-2 * time.Minute), Duration: 1000, Size: 3, Data: []byte("one")},
+			{ID: "recording2", User: "user2", Cluster: "cluster1", Namespace: "kube-system", Pod: "pod2", Container: "container1", Shell: "sh", Timestamp: now.Add(-1 * time.Minute), Duration: 2000, Size: 3, Data: []byte("two")},
+			{ID: "recording3", User: "user1", Cluster: "cluster2", Namespace: "default", Pod: "pod3", Container: "container1", Shell: "bash", Timestamp: now, Duration: 3000, Size: 5, Truncated: true, Data: []byte("three")},
+		}
+
+		for _, recording := range recordings {
+			err := c.SaveTerminalRecording(ctx, &recording)
+			require.NoError(t, err)
+		}
+
+		for _, tt := range []struct {
+			name      string
+			user      string
+			cluster   string
+			namespace string
+			limit     int
+			offset    int
+			expected  []string
+		}{
+			{name: "should return all recordings", expected: []string{"recording3", "recording2", "recording1"}},
+			{name: "should return recordings for user", user: "user1", expected: []string{"recording3", "recording1"}},
+			{name: "should return recordings for cluster and namespace", cluster: "cluster1", namespace: "default", expected: []string{"recording1"}},
+			{name: "should return recordings with limit and offset", limit: 1, offset: 1, expected: []string{"recording2"}},
+			{name: "should return no recordings", user: "user3", expected: nil},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				storedRecordings, err := c.GetTerminalRecordings(ctx, tt.user, tt.cluster, tt.namespace, tt.limit, tt.offset)
+				require.NoError(t, err)
+
+				var ids []string
+				for _, recording := range storedRecordings {
+					require.Nil(t, recording.Data)
+					ids = append(ids, recording.ID)
+				}
+				require.Equal(t, tt.expected, ids)
+			})
+		}
+
+		t.Run("should return recording with data", func(t *testing.T) {
+			recording, err := c.GetTerminalRecording(ctx, "recording3")
+			require.NoError(t, err)
+			require.Equal(t, "pod3", recording.Pod)
+			require.True(t, recording.Truncated)
+			require.True(t, now.Equal(recording.Timestamp))
+			require.Equal(t, []byte("three"), recording.Data)
+		})
+
+		t.Run("should return error for not existing recording", func(t *testing.T) {
+			_, err := c.GetTerminalRecording(ctx, "recording4")
+			require.Equal(t, ErrTerminalRecordingNotFound, err)
+		})
+	})
+
+	t.Run("SaveGetAndDeleteClusters", func(t *testing.T) {
+		ctx := ctx(t)
+
+		err := c.SaveCluster(ctx, &Cluster{Name: "cluster2", Address: "http://cluster2:15221", Token: "token2"})
+		require.NoError(t, err)
+		err = c.SaveCluster(ctx, &Cluster{Name: "cluster1", Address: "http://cluster1:15221", Token: "token1"})
+		require.NoError(t, err)
+
+		err = c.SaveNamespaces(ctx, "cluster1", []string{"default"})
+		require.NoError(t, err)
+		err = c.SaveNamespaces(ctx, "cluster2", []string{"default"})
+		require.NoError(t, err)
+
+		t.Run("should return clusters", func(t *testing.T) {
+			clusters, err := c.GetClusters(ctx)
+			require.NoError(t, err)
+			require.Equal(t, 2, len(clusters))
+			require.Equal(t, "cluster1", clusters[0].Name)
+			require.Equal(t, "http://cluster1:15221", clusters[0].Address)
+			require.Equal(t, "token1", clusters[0].Token)
+			require.Equal(t, "cluster2", clusters[1].Name)
+		})
+
+		t.Run("should delete cluster and data", func(t *testing.T) {
+			err := c.DeleteCluster(ctx, "cluster1")
+			require.NoError(t, err)
+
+			clusters, err := c.GetClusters(ctx)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(clusters))
+			require.Equal(t, "cluster2", clusters[0].Name)
+
+			namespaces, err := c.GetNamespacesByClusters(ctx, []string{"cluster1", "cluster2"})
+			require.NoError(t, err)
+			require.Equal(t, 1, len(namespaces))
+			require.Equal(t, "cluster2", namespaces[0].Cluster)
+		})
+	})
+
+	t.Run("Leases", func(t *testing.T) {
+		ctx := ctx(t)
+
+		t.Run("should fail to return not existing lease", func(t *testing.T) {
+			_, err := c.GetLease(ctx, "watcher")
+			require.Equal(t, ErrLeaseNotFound, err)
+		})
+
+		t.Run("should acquire lease", func(t *testing.T) {
+			lease, err := c.AcquireLease(ctx, "watcher", "holder1", time.Minute)
+			require.NoError(t, err)
+			require.Equal(t, "holder1", lease.Holder)
+		})
+
+		t.Run("should not acquire lease held by other holder", func(t *testing.T) {
+			lease, err := c.AcquireLease(ctx, "watcher", "holder2", time.Minute)
+			require.NoError(t, err)
+			require.Equal(t, "holder1", lease.Holder)
+		})
+
+		t.Run("should renew lease", func(t *testing.T) {
+			before, err := c.GetLease(ctx, "watcher")
+			require.NoError(t, err)
+
+			lease, err := c.AcquireLease(ctx, "watcher", "holder1", time.Minute)
+			require.NoError(t, err)
+			require.Equal(t, "holder1", lease.Holder)
+			require.True(t, before.AcquiredAt.Equal(lease.AcquiredAt))
+			require.False(t, lease.ExpiresAt.Before(before.ExpiresAt))
+		})
+
+		t.Run("should acquire released lease", func(t *testing.T) {
+			err := c.ReleaseLease(ctx, "watcher", "holder1")
+			require.NoError(t, err)
+
+			lease, err := c.AcquireLease(ctx, "watcher", "holder2", time.Minute)
+			require.NoError(t, err)
+			require.Equal(t, "holder2", lease.Holder)
+		})
+	})
+}
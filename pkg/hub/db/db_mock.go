@@ -7,6 +7,7 @@ package db
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	kubernetes "github.com/kobsio/kobs/pkg/cluster/kubernetes"
@@ -16,8 +17,6 @@ import (
 	v12 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
 	context0 "github.com/kobsio/kobs/pkg/hub/auth/context"
 	plugin "github.com/kobsio/kobs/pkg/plugins/plugin"
-	primitive "go.mongodb.org/mongo-driver/bson/primitive"
-	mongo "go.mongodb.org/mongo-driver/mongo"
 )
 
 // MockClient is a mock of Client interface.
@@ -43,6 +42,35 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// AcquireLease mocks base method.
+func (m *MockClient) AcquireLease(ctx context.Context, name, holder string, duration time.Duration) (*Lease, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireLease", ctx, name, holder, duration)
+	ret0, _ := ret[0].(*Lease)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireLease indicates an expected call of AcquireLease.
+func (mr *MockClientMockRecorder) AcquireLease(ctx, name, holder, duration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireLease", reflect.TypeOf((*MockClient)(nil).AcquireLease), ctx, name, holder, duration)
+}
+
+// CreateAPIToken mocks base method.
+func (m *MockClient) CreateAPIToken(ctx context.Context, token *APIToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAPIToken indicates an expected call of CreateAPIToken.
+func (mr *MockClientMockRecorder) CreateAPIToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIToken", reflect.TypeOf((*MockClient)(nil).CreateAPIToken), ctx, token)
+}
+
 // CreateIndexes mocks base method.
 func (m *MockClient) CreateIndexes(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -58,36 +86,78 @@ func (mr *MockClientMockRecorder) CreateIndexes(ctx interface{}) *gomock.Call {
 }
 
 // CreateSession mocks base method.
-func (m *MockClient) CreateSession(ctx context.Context, user context0.User) (*Session, error) {
+func (m *MockClient) CreateSession(ctx context.Context, user context0.User, metadata SessionMetadata) (*Session, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSession", ctx, user)
+	ret := m.ctrl.Call(m, "CreateSession", ctx, user, metadata)
 	ret0, _ := ret[0].(*Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateSession indicates an expected call of CreateSession.
-func (mr *MockClientMockRecorder) CreateSession(ctx, user interface{}) *gomock.Call {
+func (mr *MockClientMockRecorder) CreateSession(ctx, user, metadata interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockClient)(nil).CreateSession), ctx, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockClient)(nil).CreateSession), ctx, user, metadata)
 }
 
-// DB mocks base method.
-func (m *MockClient) DB() *mongo.Client {
+// DeleteAPIToken mocks base method.
+func (m *MockClient) DeleteAPIToken(ctx context.Context, userID, id string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DB")
-	ret0, _ := ret[0].(*mongo.Client)
+	ret := m.ctrl.Call(m, "DeleteAPIToken", ctx, userID, id)
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DB indicates an expected call of DB.
-func (mr *MockClientMockRecorder) DB() *gomock.Call {
+// DeleteAPIToken indicates an expected call of DeleteAPIToken.
+func (mr *MockClientMockRecorder) DeleteAPIToken(ctx, userID, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DB", reflect.TypeOf((*MockClient)(nil).DB))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIToken", reflect.TypeOf((*MockClient)(nil).DeleteAPIToken), ctx, userID, id)
+}
+
+// DeleteApplication mocks base method.
+func (m *MockClient) DeleteApplication(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteApplication", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteApplication indicates an expected call of DeleteApplication.
+func (mr *MockClientMockRecorder) DeleteApplication(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteApplication", reflect.TypeOf((*MockClient)(nil).DeleteApplication), ctx, id)
+}
+
+// DeleteCluster mocks base method.
+func (m *MockClient) DeleteCluster(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCluster", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCluster indicates an expected call of DeleteCluster.
+func (mr *MockClientMockRecorder) DeleteCluster(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCluster", reflect.TypeOf((*MockClient)(nil).DeleteCluster), ctx, name)
+}
+
+// DeleteDashboard mocks base method.
+func (m *MockClient) DeleteDashboard(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDashboard", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDashboard indicates an expected call of DeleteDashboard.
+func (mr *MockClientMockRecorder) DeleteDashboard(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDashboard", reflect.TypeOf((*MockClient)(nil).DeleteDashboard), ctx, id)
 }
 
 // DeleteSession mocks base method.
-func (m *MockClient) DeleteSession(ctx context.Context, sessionID primitive.ObjectID) error {
+func (m *MockClient) DeleteSession(ctx context.Context, sessionID string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteSession", ctx, sessionID)
 	ret0, _ := ret[0].(error)
@@ -100,8 +170,80 @@ func (mr *MockClientMockRecorder) DeleteSession(ctx, sessionID interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockClient)(nil).DeleteSession), ctx, sessionID)
 }
 
+// DeleteSessions mocks base method.
+func (m *MockClient) DeleteSessions(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSessions", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSessions indicates an expected call of DeleteSessions.
+func (mr *MockClientMockRecorder) DeleteSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSessions", reflect.TypeOf((*MockClient)(nil).DeleteSessions), ctx, userID)
+}
+
+// DeleteTeam mocks base method.
+func (m *MockClient) DeleteTeam(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTeam", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTeam indicates an expected call of DeleteTeam.
+func (mr *MockClientMockRecorder) DeleteTeam(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTeam", reflect.TypeOf((*MockClient)(nil).DeleteTeam), ctx, id)
+}
+
+// DeleteUser mocks base method.
+func (m *MockClient) DeleteUser(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockClientMockRecorder) DeleteUser(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockClient)(nil).DeleteUser), ctx, id)
+}
+
+// GetAPIToken mocks base method.
+func (m *MockClient) GetAPIToken(ctx context.Context, id string) (*APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIToken", ctx, id)
+	ret0, _ := ret[0].(*APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIToken indicates an expected call of GetAPIToken.
+func (mr *MockClientMockRecorder) GetAPIToken(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIToken", reflect.TypeOf((*MockClient)(nil).GetAPIToken), ctx, id)
+}
+
+// GetAPITokens mocks base method.
+func (m *MockClient) GetAPITokens(ctx context.Context, userID string) ([]APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPITokens", ctx, userID)
+	ret0, _ := ret[0].([]APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPITokens indicates an expected call of GetAPITokens.
+func (mr *MockClientMockRecorder) GetAPITokens(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPITokens", reflect.TypeOf((*MockClient)(nil).GetAPITokens), ctx, userID)
+}
+
 // GetAndUpdateSession mocks base method.
-func (m *MockClient) GetAndUpdateSession(ctx context.Context, sessionID primitive.ObjectID) (*Session, error) {
+func (m *MockClient) GetAndUpdateSession(ctx context.Context, sessionID string) (*Session, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAndUpdateSession", ctx, sessionID)
 	ret0, _ := ret[0].(*Session)
@@ -130,6 +272,21 @@ func (mr *MockClientMockRecorder) GetApplicationByID(ctx, id interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationByID", reflect.TypeOf((*MockClient)(nil).GetApplicationByID), ctx, id)
 }
 
+// GetApplicationHealthHistory mocks base method.
+func (m *MockClient) GetApplicationHealthHistory(ctx context.Context, id string, limit int) ([]ApplicationHealth, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApplicationHealthHistory", ctx, id, limit)
+	ret0, _ := ret[0].([]ApplicationHealth)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApplicationHealthHistory indicates an expected call of GetApplicationHealthHistory.
+func (mr *MockClientMockRecorder) GetApplicationHealthHistory(ctx, id, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationHealthHistory", reflect.TypeOf((*MockClient)(nil).GetApplicationHealthHistory), ctx, id, limit)
+}
+
 // GetApplications mocks base method.
 func (m *MockClient) GetApplications(ctx context.Context) ([]v1.ApplicationSpec, error) {
 	m.ctrl.T.Helper()
@@ -146,33 +303,33 @@ func (mr *MockClientMockRecorder) GetApplications(ctx interface{}) *gomock.Call
 }
 
 // GetApplicationsByFilter mocks base method.
-func (m *MockClient) GetApplicationsByFilter(ctx context.Context, teams, clusters, namespaces, tags []string, searchTerm string, limit, offset int) ([]v1.ApplicationSpec, error) {
+func (m *MockClient) GetApplicationsByFilter(ctx context.Context, teams, clusters, namespaces, tags, health []string, searchTerm string, limit, offset int) ([]v1.ApplicationSpec, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetApplicationsByFilter", ctx, teams, clusters, namespaces, tags, searchTerm, limit, offset)
+	ret := m.ctrl.Call(m, "GetApplicationsByFilter", ctx, teams, clusters, namespaces, tags, health, searchTerm, limit, offset)
 	ret0, _ := ret[0].([]v1.ApplicationSpec)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetApplicationsByFilter indicates an expected call of GetApplicationsByFilter.
-func (mr *MockClientMockRecorder) GetApplicationsByFilter(ctx, teams, clusters, namespaces, tags, searchTerm, limit, offset interface{}) *gomock.Call {
+func (mr *MockClientMockRecorder) GetApplicationsByFilter(ctx, teams, clusters, namespaces, tags, health, searchTerm, limit, offset interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationsByFilter", reflect.TypeOf((*MockClient)(nil).GetApplicationsByFilter), ctx, teams, clusters, namespaces, tags, searchTerm, limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationsByFilter", reflect.TypeOf((*MockClient)(nil).GetApplicationsByFilter), ctx, teams, clusters, namespaces, tags, health, searchTerm, limit, offset)
 }
 
 // GetApplicationsByFilterCount mocks base method.
-func (m *MockClient) GetApplicationsByFilterCount(ctx context.Context, teams, clusters, namespaces, tags []string, searchTerm string) (int, error) {
+func (m *MockClient) GetApplicationsByFilterCount(ctx context.Context, teams, clusters, namespaces, tags, health []string, searchTerm string) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetApplicationsByFilterCount", ctx, teams, clusters, namespaces, tags, searchTerm)
+	ret := m.ctrl.Call(m, "GetApplicationsByFilterCount", ctx, teams, clusters, namespaces, tags, health, searchTerm)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetApplicationsByFilterCount indicates an expected call of GetApplicationsByFilterCount.
-func (mr *MockClientMockRecorder) GetApplicationsByFilterCount(ctx, teams, clusters, namespaces, tags, searchTerm interface{}) *gomock.Call {
+func (mr *MockClientMockRecorder) GetApplicationsByFilterCount(ctx, teams, clusters, namespaces, tags, health, searchTerm interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationsByFilterCount", reflect.TypeOf((*MockClient)(nil).GetApplicationsByFilterCount), ctx, teams, clusters, namespaces, tags, searchTerm)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationsByFilterCount", reflect.TypeOf((*MockClient)(nil).GetApplicationsByFilterCount), ctx, teams, clusters, namespaces, tags, health, searchTerm)
 }
 
 // GetApplicationsByGroup mocks base method.
@@ -190,6 +347,36 @@ func (mr *MockClientMockRecorder) GetApplicationsByGroup(ctx, teams, groups inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationsByGroup", reflect.TypeOf((*MockClient)(nil).GetApplicationsByGroup), ctx, teams, groups)
 }
 
+// GetApplicationsHealth mocks base method.
+func (m *MockClient) GetApplicationsHealth(ctx context.Context, ids []string) ([]ApplicationHealth, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApplicationsHealth", ctx, ids)
+	ret0, _ := ret[0].([]ApplicationHealth)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApplicationsHealth indicates an expected call of GetApplicationsHealth.
+func (mr *MockClientMockRecorder) GetApplicationsHealth(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationsHealth", reflect.TypeOf((*MockClient)(nil).GetApplicationsHealth), ctx, ids)
+}
+
+// GetAuditEvents mocks base method.
+func (m *MockClient) GetAuditEvents(ctx context.Context, user, cluster, namespace, verb string, timeStart, timeEnd int64, limit, offset int) ([]AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEvents", ctx, user, cluster, namespace, verb, timeStart, timeEnd, limit, offset)
+	ret0, _ := ret[0].([]AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEvents indicates an expected call of GetAuditEvents.
+func (mr *MockClientMockRecorder) GetAuditEvents(ctx, user, cluster, namespace, verb, timeStart, timeEnd, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEvents", reflect.TypeOf((*MockClient)(nil).GetAuditEvents), ctx, user, cluster, namespace, verb, timeStart, timeEnd, limit, offset)
+}
+
 // GetCRDByID mocks base method.
 func (m *MockClient) GetCRDByID(ctx context.Context, id string) (*kubernetes.CRD, error) {
 	m.ctrl.T.Helper()
@@ -220,6 +407,21 @@ func (mr *MockClientMockRecorder) GetCRDs(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCRDs", reflect.TypeOf((*MockClient)(nil).GetCRDs), ctx)
 }
 
+// GetClusters mocks base method.
+func (m *MockClient) GetClusters(ctx context.Context) ([]Cluster, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClusters", ctx)
+	ret0, _ := ret[0].([]Cluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClusters indicates an expected call of GetClusters.
+func (mr *MockClientMockRecorder) GetClusters(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusters", reflect.TypeOf((*MockClient)(nil).GetClusters), ctx)
+}
+
 // GetDashboardByID mocks base method.
 func (m *MockClient) GetDashboardByID(ctx context.Context, id string) (*v10.DashboardSpec, error) {
 	m.ctrl.T.Helper()
@@ -250,6 +452,50 @@ func (mr *MockClientMockRecorder) GetDashboards(ctx, clusters, namespaces interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDashboards", reflect.TypeOf((*MockClient)(nil).GetDashboards), ctx, clusters, namespaces)
 }
 
+// GetDocuments mocks base method.
+func (m *MockClient) GetDocuments(ctx context.Context, collection string, query DocumentQuery, documents any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDocuments", ctx, collection, query, documents)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetDocuments indicates an expected call of GetDocuments.
+func (mr *MockClientMockRecorder) GetDocuments(ctx, collection, query, documents interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDocuments", reflect.TypeOf((*MockClient)(nil).GetDocuments), ctx, collection, query, documents)
+}
+
+// GetLastSyncRuns mocks base method.
+func (m *MockClient) GetLastSyncRuns(ctx context.Context) ([]SyncRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastSyncRuns", ctx)
+	ret0, _ := ret[0].([]SyncRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastSyncRuns indicates an expected call of GetLastSyncRuns.
+func (mr *MockClientMockRecorder) GetLastSyncRuns(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastSyncRuns", reflect.TypeOf((*MockClient)(nil).GetLastSyncRuns), ctx)
+}
+
+// GetLease mocks base method.
+func (m *MockClient) GetLease(ctx context.Context, name string) (*Lease, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLease", ctx, name)
+	ret0, _ := ret[0].(*Lease)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLease indicates an expected call of GetLease.
+func (mr *MockClientMockRecorder) GetLease(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLease", reflect.TypeOf((*MockClient)(nil).GetLease), ctx, name)
+}
+
 // GetNamespaces mocks base method.
 func (m *MockClient) GetNamespaces(ctx context.Context) ([]Namespace, error) {
 	m.ctrl.T.Helper()
@@ -296,7 +542,7 @@ func (mr *MockClientMockRecorder) GetPlugins(ctx interface{}) *gomock.Call {
 }
 
 // GetSession mocks base method.
-func (m *MockClient) GetSession(ctx context.Context, sessionID primitive.ObjectID) (*Session, error) {
+func (m *MockClient) GetSession(ctx context.Context, sessionID string) (*Session, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSession", ctx, sessionID)
 	ret0, _ := ret[0].(*Session)
@@ -310,6 +556,36 @@ func (mr *MockClientMockRecorder) GetSession(ctx, sessionID interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockClient)(nil).GetSession), ctx, sessionID)
 }
 
+// GetSessions mocks base method.
+func (m *MockClient) GetSessions(ctx context.Context, userID string) ([]Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessions", ctx, userID)
+	ret0, _ := ret[0].([]Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessions indicates an expected call of GetSessions.
+func (mr *MockClientMockRecorder) GetSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessions", reflect.TypeOf((*MockClient)(nil).GetSessions), ctx, userID)
+}
+
+// GetSyncRuns mocks base method.
+func (m *MockClient) GetSyncRuns(ctx context.Context, cluster, resource string, limit int) ([]SyncRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSyncRuns", ctx, cluster, resource, limit)
+	ret0, _ := ret[0].([]SyncRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSyncRuns indicates an expected call of GetSyncRuns.
+func (mr *MockClientMockRecorder) GetSyncRuns(ctx, cluster, resource, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSyncRuns", reflect.TypeOf((*MockClient)(nil).GetSyncRuns), ctx, cluster, resource, limit)
+}
+
 // GetTags mocks base method.
 func (m *MockClient) GetTags(ctx context.Context) ([]Tag, error) {
 	m.ctrl.T.Helper()
@@ -370,6 +646,36 @@ func (mr *MockClientMockRecorder) GetTeamsByIDs(ctx, ids, searchTerm interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamsByIDs", reflect.TypeOf((*MockClient)(nil).GetTeamsByIDs), ctx, ids, searchTerm)
 }
 
+// GetTerminalRecording mocks base method.
+func (m *MockClient) GetTerminalRecording(ctx context.Context, id string) (*TerminalRecording, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTerminalRecording", ctx, id)
+	ret0, _ := ret[0].(*TerminalRecording)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTerminalRecording indicates an expected call of GetTerminalRecording.
+func (mr *MockClientMockRecorder) GetTerminalRecording(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTerminalRecording", reflect.TypeOf((*MockClient)(nil).GetTerminalRecording), ctx, id)
+}
+
+// GetTerminalRecordings mocks base method.
+func (m *MockClient) GetTerminalRecordings(ctx context.Context, user, cluster, namespace string, limit, offset int) ([]TerminalRecording, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTerminalRecordings", ctx, user, cluster, namespace, limit, offset)
+	ret0, _ := ret[0].([]TerminalRecording)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTerminalRecordings indicates an expected call of GetTerminalRecordings.
+func (mr *MockClientMockRecorder) GetTerminalRecordings(ctx, user, cluster, namespace, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTerminalRecordings", reflect.TypeOf((*MockClient)(nil).GetTerminalRecordings), ctx, user, cluster, namespace, limit, offset)
+}
+
 // GetTopologyByIDs mocks base method.
 func (m *MockClient) GetTopologyByIDs(ctx context.Context, field string, ids []string) ([]Topology, error) {
 	m.ctrl.T.Helper()
@@ -415,6 +721,20 @@ func (mr *MockClientMockRecorder) GetUsers(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockClient)(nil).GetUsers), ctx)
 }
 
+// ReleaseLease mocks base method.
+func (m *MockClient) ReleaseLease(ctx context.Context, name, holder string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseLease", ctx, name, holder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseLease indicates an expected call of ReleaseLease.
+func (mr *MockClientMockRecorder) ReleaseLease(ctx, name, holder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseLease", reflect.TypeOf((*MockClient)(nil).ReleaseLease), ctx, name, holder)
+}
+
 // SaveApplication mocks base method.
 func (m *MockClient) SaveApplication(ctx context.Context, application *v1.ApplicationSpec) error {
 	m.ctrl.T.Helper()
@@ -429,6 +749,20 @@ func (mr *MockClientMockRecorder) SaveApplication(ctx, application interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveApplication", reflect.TypeOf((*MockClient)(nil).SaveApplication), ctx, application)
 }
 
+// SaveApplicationTopology mocks base method.
+func (m *MockClient) SaveApplicationTopology(ctx context.Context, application *v1.ApplicationSpec) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveApplicationTopology", ctx, application)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveApplicationTopology indicates an expected call of SaveApplicationTopology.
+func (mr *MockClientMockRecorder) SaveApplicationTopology(ctx, application interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveApplicationTopology", reflect.TypeOf((*MockClient)(nil).SaveApplicationTopology), ctx, application)
+}
+
 // SaveApplications mocks base method.
 func (m *MockClient) SaveApplications(ctx context.Context, cluster string, applications []v1.ApplicationSpec) error {
 	m.ctrl.T.Helper()
@@ -443,6 +777,34 @@ func (mr *MockClientMockRecorder) SaveApplications(ctx, cluster, applications in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveApplications", reflect.TypeOf((*MockClient)(nil).SaveApplications), ctx, cluster, applications)
 }
 
+// SaveApplicationsHealth mocks base method.
+func (m *MockClient) SaveApplicationsHealth(ctx context.Context, health []ApplicationHealth) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveApplicationsHealth", ctx, health)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveApplicationsHealth indicates an expected call of SaveApplicationsHealth.
+func (mr *MockClientMockRecorder) SaveApplicationsHealth(ctx, health interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveApplicationsHealth", reflect.TypeOf((*MockClient)(nil).SaveApplicationsHealth), ctx, health)
+}
+
+// SaveAuditEvent mocks base method.
+func (m *MockClient) SaveAuditEvent(ctx context.Context, event *AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAuditEvent", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAuditEvent indicates an expected call of SaveAuditEvent.
+func (mr *MockClientMockRecorder) SaveAuditEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAuditEvent", reflect.TypeOf((*MockClient)(nil).SaveAuditEvent), ctx, event)
+}
+
 // SaveCRDs mocks base method.
 func (m *MockClient) SaveCRDs(ctx context.Context, crds []kubernetes.CRD) error {
 	m.ctrl.T.Helper()
@@ -457,6 +819,34 @@ func (mr *MockClientMockRecorder) SaveCRDs(ctx, crds interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCRDs", reflect.TypeOf((*MockClient)(nil).SaveCRDs), ctx, crds)
 }
 
+// SaveCluster mocks base method.
+func (m *MockClient) SaveCluster(ctx context.Context, cluster *Cluster) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCluster", ctx, cluster)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCluster indicates an expected call of SaveCluster.
+func (mr *MockClientMockRecorder) SaveCluster(ctx, cluster interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCluster", reflect.TypeOf((*MockClient)(nil).SaveCluster), ctx, cluster)
+}
+
+// SaveDashboard mocks base method.
+func (m *MockClient) SaveDashboard(ctx context.Context, dashboard *v10.DashboardSpec) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDashboard", ctx, dashboard)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDashboard indicates an expected call of SaveDashboard.
+func (mr *MockClientMockRecorder) SaveDashboard(ctx, dashboard interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDashboard", reflect.TypeOf((*MockClient)(nil).SaveDashboard), ctx, dashboard)
+}
+
 // SaveDashboards mocks base method.
 func (m *MockClient) SaveDashboards(ctx context.Context, cluster string, dashboards []v10.DashboardSpec) error {
 	m.ctrl.T.Helper()
@@ -471,6 +861,20 @@ func (mr *MockClientMockRecorder) SaveDashboards(ctx, cluster, dashboards interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDashboards", reflect.TypeOf((*MockClient)(nil).SaveDashboards), ctx, cluster, dashboards)
 }
 
+// SaveDocuments mocks base method.
+func (m *MockClient) SaveDocuments(ctx context.Context, collection string, documents []Document) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDocuments", ctx, collection, documents)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDocuments indicates an expected call of SaveDocuments.
+func (mr *MockClientMockRecorder) SaveDocuments(ctx, collection, documents interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDocuments", reflect.TypeOf((*MockClient)(nil).SaveDocuments), ctx, collection, documents)
+}
+
 // SaveNamespaces mocks base method.
 func (m *MockClient) SaveNamespaces(ctx context.Context, cluster string, namespaces []string) error {
 	m.ctrl.T.Helper()
@@ -499,6 +903,20 @@ func (mr *MockClientMockRecorder) SavePlugins(ctx, cluster, plugins interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePlugins", reflect.TypeOf((*MockClient)(nil).SavePlugins), ctx, cluster, plugins)
 }
 
+// SaveSyncRun mocks base method.
+func (m *MockClient) SaveSyncRun(ctx context.Context, run *SyncRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSyncRun", ctx, run)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSyncRun indicates an expected call of SaveSyncRun.
+func (mr *MockClientMockRecorder) SaveSyncRun(ctx, run interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSyncRun", reflect.TypeOf((*MockClient)(nil).SaveSyncRun), ctx, run)
+}
+
 // SaveTags mocks base method.
 func (m *MockClient) SaveTags(ctx context.Context, applications []v1.ApplicationSpec) error {
 	m.ctrl.T.Helper()
@@ -541,6 +959,20 @@ func (mr *MockClientMockRecorder) SaveTeams(ctx, cluster, teams interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTeams", reflect.TypeOf((*MockClient)(nil).SaveTeams), ctx, cluster, teams)
 }
 
+// SaveTerminalRecording mocks base method.
+func (m *MockClient) SaveTerminalRecording(ctx context.Context, recording *TerminalRecording) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTerminalRecording", ctx, recording)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTerminalRecording indicates an expected call of SaveTerminalRecording.
+func (mr *MockClientMockRecorder) SaveTerminalRecording(ctx, recording interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTerminalRecording", reflect.TypeOf((*MockClient)(nil).SaveTerminalRecording), ctx, recording)
+}
+
 // SaveTopology mocks base method.
 func (m *MockClient) SaveTopology(ctx context.Context, cluster string, applications []v1.ApplicationSpec) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const (
+	// applicationHealthTTL is the time after which an entry in the health history of an application is deleted from
+	// the database.
+	applicationHealthTTL = 720 * time.Hour
+)
+
+// The following constants are the possible values for the status of an application and for the status of a single
+// reason.
+const (
+	HealthStatusHealthy   = "healthy"
+	HealthStatusDegraded  = "degraded"
+	HealthStatusUnhealthy = "unhealthy"
+	HealthStatusUnknown   = "unknown"
+)
+
+// ApplicationHealth is the health status of an application, which is computed by the hub. The `application` field
+// contains the id of the application and the `reasons` field contains the results of all checks which were used to
+// compute the `status`.
+//
+// The current health status of an application uses the id of the application as `id`. When the status or the reasons
+// of an application are changed, the new status is also added to the health history of the application, where the id
+// also contains the timestamp of the status.
+type ApplicationHealth struct {
+	ID          string                    `json:"id" bson:"_id"`
+	Application string                    `json:"application" bson:"application"`
+	Status      string                    `json:"status" bson:"status"`
+	Reasons     []ApplicationHealthReason `json:"reasons,omitempty" bson:"reasons"`
+	Timestamp   time.Time                 `json:"timestamp" bson:"timestamp"`
+}
+
+// ApplicationHealthReason is the result of a single check for the health of an application. The `type` is "insight"
+// or "workload" and the `name` is the title of the insight or the kind and name of the workload (e.g.
+// "Deployment/nginx").
+type ApplicationHealthReason struct {
+	Type    string `json:"type" bson:"type"`
+	Name    string `json:"name" bson:"name"`
+	Status  string `json:"status" bson:"status"`
+	Message string `json:"message,omitempty" bson:"message"`
+}
+
+// healthHistoryID returns the id of an entry in the health history of an application.
+func healthHistoryID(health ApplicationHealth) string {
+	return fmt.Sprintf("%s/%d", health.Application, health.Timestamp.UnixNano())
+}
+
+// healthChanged returns true when the provided health of an application differs from the current one, so that it must
+// be added to the health history. If there is no current health for the application, the health is always changed.
+func healthChanged(current *ApplicationHealth, health ApplicationHealth) bool {
+	if current == nil {
+		return true
+	}
+
+	if current.Status != health.Status || len(current.Reasons) != len(health.Reasons) {
+		return true
+	}
+
+	return len(health.Reasons) > 0 && !reflect.DeepEqual(current.Reasons, health.Reasons)
+}
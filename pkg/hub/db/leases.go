@@ -0,0 +1,23 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrLeaseNotFound is our custom error which is returned when we are not able to find a lease with the provided
+	// name.
+	ErrLeaseNotFound = fmt.Errorf("lease not found")
+)
+
+// Lease is a lease which is used for the leader election between multiple replicas of a component, e.g. the watcher.
+// The replica with the id from the `holder` field is the leader, until the lease expires at `expiresAt`. The leader
+// must renew the lease before it expires, otherwise another replica can acquire the lease.
+type Lease struct {
+	Name       string    `json:"name" bson:"_id"`
+	Holder     string    `json:"holder" bson:"holder"`
+	AcquiredAt time.Time `json:"acquiredAt" bson:"acquiredAt"`
+	RenewedAt  time.Time `json:"renewedAt" bson:"renewedAt"`
+	ExpiresAt  time.Time `json:"expiresAt" bson:"expiresAt"`
+}
@@ -0,0 +1,2065 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
+	dashboardv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/dashboard/v1"
+	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/plugins/plugin"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type mongodbClient struct {
+	db     *mongo.Client
+	tracer trace.Tracer
+}
+
+// newMongoDBClient creates a new MongoDB client which implements our database interface.
+//
+// To create a local MongoDB for testing the following commands can be used:
+//
+//	docker run --name mongodb -d -p 27017:27017 mongo
+//	docker stop mongodb
+//	docker rm mongodb
+func newMongoDBClient(config Config) (Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, err := mongo.Connect(ctx, options.Client().ApplyURI(config.URI))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(ctx, readpref.Primary()); err != nil {
+		return nil, err
+	}
+
+	return &mongodbClient{
+		db:     db,
+		tracer: otel.Tracer("db"),
+	}, nil
+}
+
+// Returns the collection with given name
+// uses specified "kobs.db.name" value from cxt if given  as db or "kobs" as default
+func (c *mongodbClient) coll(ctx context.Context, collection string) *mongo.Collection {
+	dbName := ctx.Value(kobsDbName)
+	if dbName == nil {
+		dbName = "kobs"
+	}
+	return c.db.Database(dbName.(string)).Collection(collection)
+}
+
+func (c *mongodbClient) save(ctx context.Context, collection string, models []mongo.WriteModel, cluster string, updatedAt int64) error {
+	_, err := c.coll(ctx, collection).BulkWrite(ctx, models)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{{Key: "updatedAt", Value: bson.D{{Key: "$lt", Value: updatedAt}}}}
+	if cluster != "" {
+		filter = bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "cluster", Value: bson.D{{Key: "$eq", Value: cluster}}}}, filter}}}
+	}
+
+	_, err = c.coll(ctx, collection).DeleteMany(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *mongodbClient) CreateIndexes(ctx context.Context) error {
+	ctx, span := c.tracer.Start(ctx, "db.CreateIndexes")
+	defer span.End()
+
+	// Create TTL index for sessions collection, which will delete all inactive sessions which are older than 7 days (168h).
+	_, err := c.coll(ctx, "sessions").Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "updatedAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(sessionTTL.Seconds())),
+		})
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	// Create TTL index for the expiration time of the sessions, which will delete a session as soon as the token for
+	// the session is expired.
+	_, err = c.coll(ctx, "sessions").Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	// Create TTL index for the personal API tokens, which will delete a token as soon as it is expired.
+	_, err = c.coll(ctx, "tokens").Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	// Create an index for the timestamp of the audit events, because the audit events are always sorted by their
+	// timestamp.
+	_, err = c.coll(ctx, "audit").Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "timestamp", Value: -1}}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	// Create TTL index for the sync runs of the watcher, which will delete all sync runs which are older than 7 days
+	// (168h). The index is also used to sort the sync runs by their timestamp.
+	_, err = c.coll(ctx, "syncs").Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "timestamp", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(syncRunTTL.Seconds())),
+		})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	// Create an index for the timestamp of the terminal recordings, because the recordings are always sorted by their
+	// timestamp.
+	_, err = c.coll(ctx, "recordings").Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "timestamp", Value: -1}}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	// Create TTL index for the health history of the applications, which will delete all entries which are older than
+	// 30 days (720h), and an index to get the history of a single application.
+	_, err = c.coll(ctx, "healthhistory").Indexes().CreateMany(
+		ctx,
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "timestamp", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(int32(applicationHealthTTL.Seconds())),
+			},
+			{
+				Keys: bson.D{{Key: "application", Value: 1}, {Key: "timestamp", Value: -1}},
+			},
+		})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *mongodbClient) SavePlugins(ctx context.Context, cluster string, plugins []plugin.Instance) error {
+	if len(plugins) == 0 {
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, "db.SavePlugins")
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	defer span.End()
+
+	var models []mongo.WriteModel
+	updatedAt := time.Now().UnixMilli()
+
+	for _, p := range plugins {
+		p.ID = fmt.Sprintf("/cluster/%s/type/%s/name/%s", cluster, p.Type, p.Name)
+		p.Cluster = cluster
+		p.UpdatedAt = updatedAt
+		models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.D{{Key: "_id", Value: p.ID}}).SetReplacement(p).SetUpsert(true))
+	}
+
+	err := c.save(ctx, "plugins", models, cluster, updatedAt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *mongodbClient) SaveNamespaces(ctx context.Context, cluster string, namespaces []string) error {
+	if len(namespaces) == 0 {
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, "db.SaveNamespaces")
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	defer span.End()
+
+	var models []mongo.WriteModel
+	updatedAt := time.Now().UnixMilli()
+
+	for _, n := range namespaces {
+		namespace := Namespace{
+			ID:        fmt.Sprintf("/cluster/%s/namespace/%s", cluster, n),
+			Namespace: n,
+			Cluster:   cluster,
+			UpdatedAt: updatedAt,
+		}
+
+		models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.D{{Key: "_id", Value: namespace.ID}}).SetReplacement(namespace).SetUpsert(true))
+	}
+
+	err := c.save(ctx, "namespaces", models, cluster, updatedAt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *mongodbClient) SaveCRDs(ctx context.Context, crds []kubernetes.CRD) error {
+	if len(crds) == 0 {
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, "db.SaveCRDs")
+	defer span.End()
+
+	var models []mongo.WriteModel
+	updatedAtTime := time.Now()
+	updatedAt := updatedAtTime.UnixMilli()
+
+	for _, crd := range crds {
+		crd.UpdatedAt = updatedAt
+		models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.D{{Key: "_id", Value: crd.ID}}).SetReplacement(crd).SetUpsert(true))
+	}
+
+	err := c.save(ctx, "crds", models, "", updatedAtTime.Add(time.Duration(-72*time.Hour)).UnixMilli())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *mongodbClient) SaveApplications(ctx context.Context, cluster string, applications []applicationv1.ApplicationSpec) error {
+	if len(applications) == 0 {
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, "db.SaveApplications")
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	defer span.End()
+
+	var models []mongo.WriteModel
+	updatedAt := time.Now().UnixMilli()
+
+	for _, a := range applications {
+		a.UpdatedAt = updatedAt
+		models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.D{{Key: "_id", Value: a.ID}}).SetReplacement(a).SetUpsert(true))
+	}
+
+	err := c.save(ctx, "applications", models, cluster, updatedAt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *mongodbClient) SaveApplication(ctx context.Context, application *applicationv1.ApplicationSpec) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveApplication")
+	defer span.End()
+
+	upsert := true
+	application.UpdatedAt = time.Now().UnixMilli()
+
+	_, err := c.coll(ctx, "applications").ReplaceOne(ctx, bson.D{{Key: "_id", Value: application.ID}}, application, &options.ReplaceOptions{Upsert: &upsert})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *mongodbClient) SaveDashboards(ctx context.Context, cluster string, dashboards []dashboardv1.DashboardSpec) error {
+	if len(dashboards) == 0 {
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, "db.SaveDashboards")
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	defer span.End()
+
+	var models []mongo.WriteModel
+	updatedAt := time.Now().UnixMilli()
+
+	for _, d := range dashboards {
+		d.UpdatedAt = updatedAt
+		models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.D{{Key: "_id", Value: d.ID}}).SetReplacement(d).SetUpsert(true))
+	}
+
+	err := c.save(ctx, "dashboards", models, cluster, updatedAt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *mongodbClient) SaveDashboard(ctx context.Context, dashboard *dashboardv1.DashboardSpec) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveDashboard")
+	defer span.End()
+
+	upsert := true
+	dashboard.UpdatedAt = time.Now().UnixMilli()
+
+	_, err := c.coll(ctx, "dashboards").ReplaceOne(ctx, bson.D{{Key: "_id", Value: dashboard.ID}}, dashboard, &options.ReplaceOptions{Upsert: &upsert})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *mongodbClient) SaveTeams(ctx context.Context, cluster string, teams []teamv1.TeamSpec) error {
+	if len(teams) == 0 {
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, "db.SaveTeams")
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	defer span.End()
+
+	var models []mongo.WriteModel
+	updatedAt := time.Now().UnixMilli()
+
+	for _, t := range teams {
+		t.UpdatedAt = updatedAt
+		models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.D{{Key: "_id", Value: t.ID}}).SetReplacement(t).SetUpsert(true))
+	}
+
+	err := c.save(ctx, "teams", models, cluster, updatedAt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *mongodbClient) SaveTeam(ctx context.Context, team *teamv1.TeamSpec) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveTeam")
+	defer span.End()
+
+	upsert := true
+	team.UpdatedAt = time.Now().UnixMilli()
+
+	_, err := c.coll(ctx, "teams").ReplaceOne(ctx, bson.D{{Key: "_id", Value: team.ID}}, team, &options.ReplaceOptions{Upsert: &upsert})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *mongodbClient) SaveUsers(ctx context.Context, cluster string, users []userv1.UserSpec) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, "db.SaveUsers")
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	defer span.End()
+
+	var models []mongo.WriteModel
+	updatedAt := time.Now().UnixMilli()
+
+	for _, u := range users {
+		u.UpdatedAt = updatedAt
+		models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.D{{Key: "_id", Value: u.ID}}).SetReplacement(u).SetUpsert(true))
+	}
+
+	err := c.save(ctx, "users", models, cluster, updatedAt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *mongodbClient) SaveUser(ctx context.Context, user *userv1.UserSpec) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveUser")
+	defer span.End()
+
+	upsert := true
+	user.UpdatedAt = time.Now().UnixMilli()
+
+	_, err := c.coll(ctx, "users").ReplaceOne(ctx, bson.D{{Key: "_id", Value: user.ID}}, user, &options.ReplaceOptions{Upsert: &upsert})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *mongodbClient) SaveCluster(ctx context.Context, cluster *Cluster) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveCluster")
+	span.SetAttributes(attribute.Key("name").String(cluster.Name))
+	defer span.End()
+
+	upsert := true
+	cluster.UpdatedAt = time.Now().UnixMilli()
+
+	_, err := c.coll(ctx, "clusters").ReplaceOne(ctx, bson.D{{Key: "_id", Value: cluster.Name}}, cluster, &options.ReplaceOptions{Upsert: &upsert})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *mongodbClient) SaveTags(ctx context.Context, applications []applicationv1.ApplicationSpec) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveTags")
+	defer span.End()
+
+	var models []mongo.WriteModel
+	updatedAtTime := time.Now()
+	updatedAt := updatedAtTime.UnixMilli()
+
+	for _, a := range applications {
+		for _, t := range a.Tags {
+			tag := Tag{
+				ID:        fmt.Sprintf("/tag/%s", t),
+				Tag:       t,
+				UpdatedAt: updatedAt,
+			}
+
+			models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.D{{Key: "_id", Value: tag.ID}}).SetReplacement(tag).SetUpsert(true))
+		}
+	}
+
+	if len(models) == 0 {
+		return nil
+	}
+
+	err := c.save(ctx, "tags", models, "", updatedAtTime.Add(time.Duration(-72*time.Hour)).UnixMilli())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *mongodbClient) SaveTopology(ctx context.Context, cluster string, applications []applicationv1.ApplicationSpec) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveTopology")
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	defer span.End()
+
+	var models []mongo.WriteModel
+	updatedAt := time.Now().UnixMilli()
+
+	for _, a := range applications {
+		for _, t := range topologyForApplication(a, updatedAt) {
+			models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.D{{Key: "_id", Value: t.ID}}).SetReplacement(t).SetUpsert(true))
+		}
+	}
+
+	if len(models) == 0 {
+		return nil
+	}
+
+	_, err := c.coll(ctx, "topology").BulkWrite(ctx, models)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	_, err = c.coll(ctx, "topology").DeleteMany(ctx, bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "sourceCluster", Value: bson.D{{Key: "$eq", Value: cluster}}}}, bson.D{{Key: "updatedAt", Value: bson.D{{Key: "$lt", Value: updatedAt}}}}}}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// SaveApplicationTopology replaces the topology edges of the provided application. In contrast to the SaveTopology
+// method it only deletes the outdated edges where the provided application is the source, so that it can be used to
+// apply the changes for a single application without touching the topology of the other applications in the cluster.
+func (c *mongodbClient) SaveApplicationTopology(ctx context.Context, application *applicationv1.ApplicationSpec) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveApplicationTopology")
+	span.SetAttributes(attribute.Key("id").String(application.ID))
+	defer span.End()
+
+	var models []mongo.WriteModel
+	updatedAt := time.Now().UnixMilli()
+	sourceID := fmt.Sprintf("/cluster/%s/namespace/%s/name/%s", application.Cluster, application.Namespace, application.Name)
+
+	for _, t := range topologyForApplication(*application, updatedAt) {
+		models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.D{{Key: "_id", Value: t.ID}}).SetReplacement(t).SetUpsert(true))
+	}
+
+	if len(models) > 0 {
+		_, err := c.coll(ctx, "topology").BulkWrite(ctx, models)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+	}
+
+	_, err := c.coll(ctx, "topology").DeleteMany(ctx, bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "sourceID", Value: bson.D{{Key: "$eq", Value: sourceID}}}}, bson.D{{Key: "updatedAt", Value: bson.D{{Key: "$lt", Value: updatedAt}}}}}}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// DeleteApplication deletes the application with the provided id and all topology edges where the application is the
+// source. It doesn't return an error when the application doesn't exist.
+func (c *mongodbClient) DeleteApplication(ctx context.Context, id string) error {
+	ctx, span := c.tracer.Start(ctx, "db.DeleteApplication")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	_, err := c.coll(ctx, "applications").DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	_, err = c.coll(ctx, "topology").DeleteMany(ctx, bson.D{{Key: "sourceID", Value: id}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// DeleteDashboard deletes the dashboard with the provided id. It doesn't return an error when the dashboard doesn't
+// exist.
+func (c *mongodbClient) DeleteDashboard(ctx context.Context, id string) error {
+	ctx, span := c.tracer.Start(ctx, "db.DeleteDashboard")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	_, err := c.coll(ctx, "dashboards").DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// DeleteTeam deletes the team with the provided id. It doesn't return an error when the team doesn't exist.
+func (c *mongodbClient) DeleteTeam(ctx context.Context, id string) error {
+	ctx, span := c.tracer.Start(ctx, "db.DeleteTeam")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	_, err := c.coll(ctx, "teams").DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// DeleteUser deletes the user with the provided id. It doesn't return an error when the user doesn't exist.
+func (c *mongodbClient) DeleteUser(ctx context.Context, id string) error {
+	ctx, span := c.tracer.Start(ctx, "db.DeleteUser")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	_, err := c.coll(ctx, "users").DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// DeleteCluster deletes the registered cluster with the provided name and all data, which was synced from the cluster.
+func (c *mongodbClient) DeleteCluster(ctx context.Context, name string) error {
+	ctx, span := c.tracer.Start(ctx, "db.DeleteCluster")
+	span.SetAttributes(attribute.Key("name").String(name))
+	defer span.End()
+
+	_, err := c.coll(ctx, "clusters").DeleteOne(ctx, bson.D{{Key: "_id", Value: name}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	for collection, field := range clusterCollections {
+		_, err := c.coll(ctx, collection).DeleteMany(ctx, bson.D{{Key: field, Value: bson.D{{Key: "$eq", Value: name}}}})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *mongodbClient) GetPlugins(ctx context.Context) ([]plugin.Instance, error) {
+	_, span := c.tracer.Start(ctx, "db.GetPlugins")
+	defer span.End()
+
+	var plugins []plugin.Instance
+
+	cursor, err := c.coll(ctx, "plugins").Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &plugins)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return plugins, nil
+}
+
+func (c *mongodbClient) GetNamespaces(ctx context.Context) ([]Namespace, error) {
+	_, span := c.tracer.Start(ctx, "db.GetNamespaces")
+	defer span.End()
+
+	var namespaces []Namespace
+
+	cursor, err := c.coll(ctx, "namespaces").Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &namespaces)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return namespaces, nil
+}
+
+func (c *mongodbClient) GetNamespacesByClusters(ctx context.Context, clusters []string) ([]Namespace, error) {
+	_, span := c.tracer.Start(ctx, "db.GetNamespacesByClusters")
+	span.SetAttributes(attribute.Key("clusters").StringSlice(clusters))
+	defer span.End()
+
+	filter := bson.D{{Key: "cluster", Value: bson.D{{Key: "$in", Value: clusters}}}}
+
+	if len(clusters) == 0 {
+		filter = bson.D{}
+	}
+
+	var namespaces []Namespace
+
+	cursor, err := c.coll(ctx, "namespaces").Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &namespaces)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return namespaces, nil
+}
+
+func (c *mongodbClient) GetCRDs(ctx context.Context) ([]kubernetes.CRD, error) {
+	_, span := c.tracer.Start(ctx, "db.GetCRDs")
+	defer span.End()
+
+	var crds []kubernetes.CRD
+
+	cursor, err := c.coll(ctx, "crds").Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &crds)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return crds, nil
+}
+
+func (c *mongodbClient) GetCRDByID(ctx context.Context, id string) (*kubernetes.CRD, error) {
+	_, span := c.tracer.Start(ctx, "db.GetCRDByID")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	var crd kubernetes.CRD
+
+	result := c.coll(ctx, "crds").FindOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err := result.Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err := result.Decode(&crd)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &crd, nil
+}
+
+func (c *mongodbClient) GetApplications(ctx context.Context) ([]applicationv1.ApplicationSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetApplications")
+	defer span.End()
+
+	var applications []applicationv1.ApplicationSpec
+
+	cursor, err := c.coll(ctx, "applications").Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &applications)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return applications, nil
+}
+
+func (c *mongodbClient) GetApplicationsByFilter(ctx context.Context, teams, clusters, namespaces, tags, health []string, searchTerm string, limit, offset int) ([]applicationv1.ApplicationSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetApplicationsByFilter")
+	span.SetAttributes(attribute.Key("teams").StringSlice(teams))
+	span.SetAttributes(attribute.Key("clusters").StringSlice(clusters))
+	span.SetAttributes(attribute.Key("namespaces").StringSlice(namespaces))
+	span.SetAttributes(attribute.Key("tags").StringSlice(tags))
+	span.SetAttributes(attribute.Key("health").StringSlice(health))
+	span.SetAttributes(attribute.Key("searchTerm").String(searchTerm))
+	span.SetAttributes(attribute.Key("limit").Int(limit))
+	span.SetAttributes(attribute.Key("offset").Int(offset))
+	defer span.End()
+
+	filter := make(bson.M)
+
+	if searchTerm != "" {
+		filter["name"] = bson.M{"$regex": primitive.Regex{Pattern: searchTerm, Options: "i"}}
+	}
+
+	if len(teams) > 0 {
+		filter["teams"] = bson.M{"$in": teams}
+	}
+
+	if len(clusters) > 0 {
+		filter["cluster"] = bson.M{"$in": clusters}
+	}
+
+	if len(namespaces) > 0 {
+		filter["namespace"] = bson.M{"$in": namespaces}
+	}
+
+	if len(tags) > 0 {
+		filter["tags"] = bson.M{"$in": tags}
+	}
+
+	if len(health) > 0 {
+		ids, err := c.coll(ctx, "health").Distinct(ctx, "_id", bson.M{"status": bson.M{"$in": health}})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+
+		filter["_id"] = bson.M{"$in": ids}
+	}
+
+	var applications []applicationv1.ApplicationSpec
+
+	cursor, err := c.coll(ctx, "applications").Find(ctx, filter, options.Find().SetSort(bson.M{"name": 1}).SetLimit(int64(limit)).SetSkip(int64(offset)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &applications)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return applications, nil
+}
+
+func (c *mongodbClient) GetApplicationsByFilterCount(ctx context.Context, teams, clusters, namespaces, tags, health []string, searchTerm string) (int, error) {
+	_, span := c.tracer.Start(ctx, "db.GetApplicationsByFilterCount")
+	span.SetAttributes(attribute.Key("teams").StringSlice(teams))
+	span.SetAttributes(attribute.Key("clusters").StringSlice(clusters))
+	span.SetAttributes(attribute.Key("namespaces").StringSlice(namespaces))
+	span.SetAttributes(attribute.Key("tags").StringSlice(tags))
+	span.SetAttributes(attribute.Key("health").StringSlice(health))
+	span.SetAttributes(attribute.Key("searchTerm").String(searchTerm))
+	defer span.End()
+
+	filter := make(bson.M)
+
+	if searchTerm != "" {
+		filter["name"] = bson.M{"$regex": primitive.Regex{Pattern: searchTerm, Options: "i"}}
+	}
+
+	if len(teams) > 0 {
+		filter["teams"] = bson.M{"$in": teams}
+	}
+
+	if len(clusters) > 0 {
+		filter["cluster"] = bson.M{"$in": clusters}
+	}
+
+	if len(namespaces) > 0 {
+		filter["namespace"] = bson.M{"$in": namespaces}
+	}
+
+	if len(tags) > 0 {
+		filter["tags"] = bson.M{"$in": tags}
+	}
+
+	if len(health) > 0 {
+		ids, err := c.coll(ctx, "health").Distinct(ctx, "_id", bson.M{"status": bson.M{"$in": health}})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return 0, err
+		}
+
+		filter["_id"] = bson.M{"$in": ids}
+	}
+
+	count, err := c.coll(ctx, "applications").CountDocuments(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+func (c *mongodbClient) GetApplicationsByGroup(ctx context.Context, teams, groups []string) ([]ApplicationGroup, error) {
+	if len(teams) == 0 || len(groups) == 0 {
+		return nil, nil
+	}
+
+	_, span := c.tracer.Start(ctx, "db.GetApplicationsByGroup")
+	span.SetAttributes(attribute.Key("teams").StringSlice(teams))
+	span.SetAttributes(attribute.Key("groups").StringSlice(groups))
+	defer span.End()
+
+	var mongoGroups bson.D
+	for _, group := range groups {
+		mongoGroups = append(mongoGroups, bson.E{Key: group, Value: fmt.Sprintf("$%s", group)})
+	}
+
+	var applications []ApplicationGroup
+
+	cursor, err := c.coll(ctx, "applications").Aggregate(ctx, mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "teams", Value: bson.D{{Key: "$in", Value: teams}}}}}},
+		bson.D{
+			{Key: "$group",
+				Value: bson.D{
+					{Key: "_id", Value: mongoGroups},
+					{Key: "clusters", Value: bson.D{{Key: "$push", Value: "$cluster"}}},
+					{Key: "namespaces", Value: bson.D{{Key: "$push", Value: "$namespace"}}},
+					{Key: "names", Value: bson.D{{Key: "$push", Value: "$name"}}},
+					{Key: "description", Value: bson.D{{Key: "$first", Value: "$description"}}},
+					{Key: "tags", Value: bson.D{{Key: "$first", Value: "$tags"}}},
+					{Key: "teams", Value: bson.D{{Key: "$first", Value: "$teams"}}},
+					{Key: "dependencies", Value: bson.D{{Key: "$first", Value: "$dependencies"}}},
+				},
+			},
+		},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &applications)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return applications, nil
+}
+
+func (c *mongodbClient) GetApplicationByID(ctx context.Context, id string) (*applicationv1.ApplicationSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetApplicationByID")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	var application applicationv1.ApplicationSpec
+
+	result := c.coll(ctx, "applications").FindOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err := result.Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err := result.Decode(&application)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &application, nil
+}
+
+func (c *mongodbClient) GetDashboards(ctx context.Context, clusters, namespaces []string) ([]dashboardv1.DashboardSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetDashboards")
+	defer span.End()
+
+	filter := make(bson.M)
+
+	if len(clusters) > 0 {
+		filter["cluster"] = bson.M{"$in": clusters}
+	}
+
+	if len(namespaces) > 0 {
+		filter["namespace"] = bson.M{"$in": namespaces}
+	}
+
+	var dashboards []dashboardv1.DashboardSpec
+
+	cursor, err := c.coll(ctx, "dashboards").Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &dashboards)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return dashboards, nil
+}
+
+func (c *mongodbClient) GetDashboardByID(ctx context.Context, id string) (*dashboardv1.DashboardSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetDashboardByID")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	var dashboard dashboardv1.DashboardSpec
+
+	result := c.coll(ctx, "dashboards").FindOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err := result.Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err := result.Decode(&dashboard)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &dashboard, nil
+}
+
+func (c *mongodbClient) GetTeams(ctx context.Context, searchTerm string) ([]teamv1.TeamSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetTeams")
+	defer span.End()
+
+	var teams []teamv1.TeamSpec
+
+	filter := bson.D{}
+	if searchTerm != "" {
+		filter = bson.D{{Key: "_id", Value: bson.M{"$regex": primitive.Regex{Pattern: searchTerm, Options: "i"}}}}
+	}
+
+	cursor, err := c.coll(ctx, "teams").Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &teams)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+func (c *mongodbClient) GetTeamsByIDs(ctx context.Context, ids []string, searchTerm string) ([]teamv1.TeamSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetTeamsByIDs")
+	span.SetAttributes(attribute.Key("ids").StringSlice(ids))
+	defer span.End()
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var teams []teamv1.TeamSpec
+
+	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}}
+	if searchTerm != "" {
+		filter = bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}}, bson.D{{Key: "_id", Value: bson.M{"$regex": primitive.Regex{Pattern: searchTerm, Options: "i"}}}}}}}
+	}
+
+	cursor, err := c.coll(ctx, "teams").Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &teams)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+func (c *mongodbClient) GetTeamByID(ctx context.Context, id string) (*teamv1.TeamSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetTeamByID")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	var team teamv1.TeamSpec
+
+	result := c.coll(ctx, "teams").FindOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err := result.Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err := result.Decode(&team)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &team, nil
+}
+
+func (c *mongodbClient) GetUsers(ctx context.Context) ([]userv1.UserSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetUsers")
+	defer span.End()
+
+	var users []userv1.UserSpec
+
+	cursor, err := c.coll(ctx, "users").Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &users)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (c *mongodbClient) GetClusters(ctx context.Context) ([]Cluster, error) {
+	_, span := c.tracer.Start(ctx, "db.GetClusters")
+	defer span.End()
+
+	var clusters []Cluster
+
+	cursor, err := c.coll(ctx, "clusters").Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &clusters)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return clusters, nil
+}
+
+func (c *mongodbClient) GetUserByID(ctx context.Context, id string) (*userv1.UserSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetUserByID")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	var user userv1.UserSpec
+
+	result := c.coll(ctx, "users").FindOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err := result.Err(); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err := result.Decode(&user)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (c *mongodbClient) GetTags(ctx context.Context) ([]Tag, error) {
+	_, span := c.tracer.Start(ctx, "db.GetTags")
+	defer span.End()
+
+	var tags []Tag
+
+	cursor, err := c.coll(ctx, "tags").Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "tag", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &tags)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (c *mongodbClient) GetTopologyByIDs(ctx context.Context, field string, ids []string) ([]Topology, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	_, span := c.tracer.Start(ctx, "db.GetTopologyByIDs")
+	span.SetAttributes(attribute.Key("field").String(field))
+	span.SetAttributes(attribute.Key("ids").StringSlice(ids))
+	defer span.End()
+
+	var topology []Topology
+
+	cursor, err := c.coll(ctx, "topology").Find(ctx, bson.D{{Key: field, Value: bson.D{{Key: "$in", Value: ids}}}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &topology)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return topology, nil
+}
+
+// SaveDocuments replaces all documents in the provided plugin collection with the provided documents. Documents which
+// are not part of the provided list are deleted.
+func (c *mongodbClient) SaveDocuments(ctx context.Context, collection string, documents []Document) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveDocuments")
+	span.SetAttributes(attribute.Key("collection").String(collection))
+	defer span.End()
+
+	ids := []string{}
+	var models []mongo.WriteModel
+
+	for _, d := range documents {
+		ids = append(ids, d.ID)
+		models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.D{{Key: "_id", Value: d.ID}}).SetReplacement(d.Data).SetUpsert(true))
+	}
+
+	if len(models) > 0 {
+		_, err := c.coll(ctx, collection).BulkWrite(ctx, models)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+	}
+
+	_, err := c.coll(ctx, collection).DeleteMany(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$nin", Value: ids}}}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetDocuments returns all documents from the provided plugin collection which are matching the provided query. The
+// documents are decoded into the provided `documents` value, which must be a pointer to a slice.
+func (c *mongodbClient) GetDocuments(ctx context.Context, collection string, query DocumentQuery, documents any) error {
+	_, span := c.tracer.Start(ctx, "db.GetDocuments")
+	span.SetAttributes(attribute.Key("collection").String(collection))
+	defer span.End()
+
+	filter := make(bson.M)
+
+	if query.ID != "" {
+		filter["_id"] = bson.M{"$eq": query.ID}
+	}
+
+	for field, value := range query.Fields {
+		filter[field] = bson.M{"$eq": value}
+	}
+
+	if query.Search != "" && len(query.SearchFields) > 0 {
+		var search bson.A
+		for _, field := range query.SearchFields {
+			search = append(search, bson.M{field: bson.M{"$regex": primitive.Regex{Pattern: regexp.QuoteMeta(query.Search), Options: "i"}}})
+		}
+		filter["$or"] = search
+	}
+
+	cursor, err := c.coll(ctx, collection).Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	err = cursor.All(ctx, documents)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// SaveAuditEvent saves a single audit event. Audit events are never updated or deleted by kobs.
+func (c *mongodbClient) SaveAuditEvent(ctx context.Context, event *AuditEvent) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveAuditEvent")
+	span.SetAttributes(attribute.Key("id").String(event.ID))
+	defer span.End()
+
+	_, err := c.coll(ctx, "audit").InsertOne(ctx, event)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetAuditEvents returns all audit events which are matching the provided filters, sorted by their timestamp, so that
+// the newest event is returned first. Empty filters are ignored, as well as a `timeStart` or `timeEnd` of 0.
+func (c *mongodbClient) GetAuditEvents(ctx context.Context, user, cluster, namespace, verb string, timeStart, timeEnd int64, limit, offset int) ([]AuditEvent, error) {
+	_, span := c.tracer.Start(ctx, "db.GetAuditEvents")
+	span.SetAttributes(attribute.Key("user").String(user))
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	span.SetAttributes(attribute.Key("namespace").String(namespace))
+	span.SetAttributes(attribute.Key("verb").String(verb))
+	span.SetAttributes(attribute.Key("timeStart").Int64(timeStart))
+	span.SetAttributes(attribute.Key("timeEnd").Int64(timeEnd))
+	span.SetAttributes(attribute.Key("limit").Int(limit))
+	span.SetAttributes(attribute.Key("offset").Int(offset))
+	defer span.End()
+
+	filter := make(bson.M)
+
+	for field, value := range map[string]string{"user": user, "cluster": cluster, "namespace": namespace, "verb": verb} {
+		if value != "" {
+			filter[field] = bson.M{"$eq": value}
+		}
+	}
+
+	timestamp := make(bson.M)
+	if timeStart > 0 {
+		timestamp["$gte"] = timeStart
+	}
+	if timeEnd > 0 {
+		timestamp["$lte"] = timeEnd
+	}
+	if len(timestamp) > 0 {
+		filter["timestamp"] = timestamp
+	}
+
+	var events []AuditEvent
+
+	cursor, err := c.coll(ctx, "audit").Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}).SetLimit(int64(limit)).SetSkip(int64(offset)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &events)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// SaveSyncRun saves a single sync run of the watcher. Sync runs are deleted via a TTL index after 7 days.
+func (c *mongodbClient) SaveSyncRun(ctx context.Context, run *SyncRun) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveSyncRun")
+	span.SetAttributes(attribute.Key("cluster").String(run.Cluster))
+	span.SetAttributes(attribute.Key("resource").String(run.Resource))
+	defer span.End()
+
+	_, err := c.coll(ctx, "syncs").InsertOne(ctx, run)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetSyncRuns returns the sync runs for the provided cluster and resource type, sorted by their timestamp, so that the
+// newest sync run is returned first. Empty filters are ignored.
+func (c *mongodbClient) GetSyncRuns(ctx context.Context, cluster, resource string, limit int) ([]SyncRun, error) {
+	_, span := c.tracer.Start(ctx, "db.GetSyncRuns")
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	span.SetAttributes(attribute.Key("resource").String(resource))
+	span.SetAttributes(attribute.Key("limit").Int(limit))
+	defer span.End()
+
+	filter := make(bson.M)
+
+	for field, value := range map[string]string{"cluster": cluster, "resource": resource} {
+		if value != "" {
+			filter[field] = bson.M{"$eq": value}
+		}
+	}
+
+	var runs []SyncRun
+
+	cursor, err := c.coll(ctx, "syncs").Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}}).SetLimit(int64(limit)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &runs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// GetLastSyncRuns returns the last sync run for each cluster and resource type, sorted by the cluster and resource.
+func (c *mongodbClient) GetLastSyncRuns(ctx context.Context) ([]SyncRun, error) {
+	_, span := c.tracer.Start(ctx, "db.GetLastSyncRuns")
+	defer span.End()
+
+	var runs []SyncRun
+
+	cursor, err := c.coll(ctx, "syncs").Aggregate(ctx, mongo.Pipeline{
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "timestamp", Value: -1}}}},
+		bson.D{
+			{Key: "$group",
+				Value: bson.D{
+					{Key: "_id", Value: bson.D{{Key: "cluster", Value: "$cluster"}, {Key: "resource", Value: "$resource"}}},
+					{Key: "run", Value: bson.D{{Key: "$first", Value: "$$ROOT"}}},
+				},
+			},
+		},
+		bson.D{{Key: "$replaceRoot", Value: bson.D{{Key: "newRoot", Value: "$run"}}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "cluster", Value: 1}, {Key: "resource", Value: 1}}}},
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &runs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// SaveApplicationsHealth saves the current health status of all applications. The health of applications which are
+// not part of the provided list is deleted. If the status or the reasons of an application are changed, the new status
+// is also added to the health history, which is deleted via a TTL index after 30 days.
+func (c *mongodbClient) SaveApplicationsHealth(ctx context.Context, health []ApplicationHealth) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveApplicationsHealth")
+	defer span.End()
+
+	err := func() error {
+		var currentHealth []ApplicationHealth
+
+		cursor, err := c.coll(ctx, "health").Find(ctx, bson.M{})
+		if err != nil {
+			return err
+		}
+
+		if err := cursor.All(ctx, &currentHealth); err != nil {
+			return err
+		}
+
+		current := make(map[string]*ApplicationHealth)
+		for i := range currentHealth {
+			current[currentHealth[i].ID] = &currentHealth[i]
+		}
+
+		ids := []string{}
+		var models []mongo.WriteModel
+		var history []any
+
+		for _, h := range health {
+			h.ID = h.Application
+			ids = append(ids, h.ID)
+			models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.D{{Key: "_id", Value: h.ID}}).SetReplacement(h).SetUpsert(true))
+
+			if healthChanged(current[h.ID], h) {
+				h.ID = healthHistoryID(h)
+				history = append(history, h)
+			}
+		}
+
+		if len(models) > 0 {
+			if _, err := c.coll(ctx, "health").BulkWrite(ctx, models); err != nil {
+				return err
+			}
+		}
+
+		if len(history) > 0 {
+			if _, err := c.coll(ctx, "healthhistory").InsertMany(ctx, history); err != nil {
+				return err
+			}
+		}
+
+		_, err = c.coll(ctx, "health").DeleteMany(ctx, bson.M{"_id": bson.M{"$nin": ids}})
+		return err
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetApplicationsHealth returns the current health status of the applications with the provided ids. If no ids are
+// provided the health status of all applications is returned.
+func (c *mongodbClient) GetApplicationsHealth(ctx context.Context, ids []string) ([]ApplicationHealth, error) {
+	_, span := c.tracer.Start(ctx, "db.GetApplicationsHealth")
+	span.SetAttributes(attribute.Key("ids").StringSlice(ids))
+	defer span.End()
+
+	filter := make(bson.M)
+
+	if len(ids) > 0 {
+		filter["_id"] = bson.M{"$in": ids}
+	}
+
+	var health []ApplicationHealth
+
+	cursor, err := c.coll(ctx, "health").Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &health)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return health, nil
+}
+
+// GetApplicationHealthHistory returns the health history of the application with the provided id, sorted by the
+// timestamp, so that the newest status is returned first.
+func (c *mongodbClient) GetApplicationHealthHistory(ctx context.Context, id string, limit int) ([]ApplicationHealth, error) {
+	_, span := c.tracer.Start(ctx, "db.GetApplicationHealthHistory")
+	span.SetAttributes(attribute.Key("id").String(id))
+	span.SetAttributes(attribute.Key("limit").Int(limit))
+	defer span.End()
+
+	var history []ApplicationHealth
+
+	cursor, err := c.coll(ctx, "healthhistory").Find(ctx, bson.D{{Key: "application", Value: bson.D{{Key: "$eq", Value: id}}}}, options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}}).SetLimit(int64(limit)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &history)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// SaveTerminalRecording saves a single terminal recording. Recordings are never updated or deleted by kobs.
+func (c *mongodbClient) SaveTerminalRecording(ctx context.Context, recording *TerminalRecording) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveTerminalRecording")
+	span.SetAttributes(attribute.Key("id").String(recording.ID))
+	defer span.End()
+
+	_, err := c.coll(ctx, "recordings").InsertOne(ctx, recording)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetTerminalRecordings returns all terminal recordings which are matching the provided filters, sorted by their
+// timestamp, so that the newest recording is returned first. Empty filters are ignored. The data of the recordings is
+// not returned.
+func (c *mongodbClient) GetTerminalRecordings(ctx context.Context, user, cluster, namespace string, limit, offset int) ([]TerminalRecording, error) {
+	_, span := c.tracer.Start(ctx, "db.GetTerminalRecordings")
+	span.SetAttributes(attribute.Key("user").String(user))
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	span.SetAttributes(attribute.Key("namespace").String(namespace))
+	span.SetAttributes(attribute.Key("limit").Int(limit))
+	span.SetAttributes(attribute.Key("offset").Int(offset))
+	defer span.End()
+
+	filter := make(bson.M)
+
+	for field, value := range map[string]string{"user": user, "cluster": cluster, "namespace": namespace} {
+		if value != "" {
+			filter[field] = bson.M{"$eq": value}
+		}
+	}
+
+	var recordings []TerminalRecording
+
+	cursor, err := c.coll(ctx, "recordings").Find(ctx, filter, options.Find().SetProjection(bson.M{"data": 0}).SetSort(bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}).SetLimit(int64(limit)).SetSkip(int64(offset)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &recordings)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return recordings, nil
+}
+
+// GetTerminalRecording returns the terminal recording with the provided id, including the data of the recording. If
+// the recording doesn't exist ErrTerminalRecordingNotFound is returned.
+func (c *mongodbClient) GetTerminalRecording(ctx context.Context, id string) (*TerminalRecording, error) {
+	_, span := c.tracer.Start(ctx, "db.GetTerminalRecording")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	res := c.coll(ctx, "recordings").FindOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if res.Err() != nil {
+		span.RecordError(res.Err())
+		span.SetStatus(codes.Error, res.Err().Error())
+		if res.Err() == mongo.ErrNoDocuments {
+			return nil, ErrTerminalRecordingNotFound
+		}
+		return nil, res.Err()
+	}
+
+	var recording TerminalRecording
+	if err := res.Decode(&recording); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &recording, nil
+}
+
+// mongodbSession is the structure of a session as it is saved in MongoDB. In contrast to the Session struct it uses an
+// ObjectID as id, so that sessions which were created before the id was changed to a string are still valid.
+type mongodbSession struct {
+	ID              primitive.ObjectID `bson:"_id"`
+	User            authContext.User   `bson:"user"`
+	SessionMetadata `bson:",inline"`
+	CreatedAt       time.Time `bson:"createdAt"`
+	UpdatedAt       time.Time `bson:"updatedAt"`
+}
+
+// CreateSession creates a new session for the provided `user`.
+func (c *mongodbClient) CreateSession(ctx context.Context, user authContext.User, metadata SessionMetadata) (*Session, error) {
+	now := time.Now()
+	session := mongodbSession{
+		ID:              primitive.NewObjectID(),
+		User:            user,
+		SessionMetadata: metadata,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	ctx, span := c.tracer.Start(ctx, "db.CreateSession")
+	span.SetAttributes(attribute.Key("sessionID").String(session.ID.Hex()))
+	span.SetAttributes(attribute.Key("userID").String(user.ID))
+	defer span.End()
+
+	_, err := c.coll(ctx, "sessions").InsertOne(ctx, session)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &Session{ID: session.ID.Hex(), User: session.User, SessionMetadata: session.SessionMetadata, CreatedAt: session.CreatedAt, UpdatedAt: session.UpdatedAt}, nil
+}
+
+// GetSession returns the session with the provided session id.
+func (c *mongodbClient) GetSession(ctx context.Context, sessionID string) (*Session, error) {
+	ctx, span := c.tracer.Start(ctx, "db.GetSession")
+	span.SetAttributes(attribute.Key("sessionID").String(sessionID))
+	defer span.End()
+
+	id, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		span.RecordError(ErrSessionNotFound)
+		span.SetStatus(codes.Error, ErrSessionNotFound.Error())
+		return nil, ErrSessionNotFound
+	}
+
+	res := c.coll(ctx, "sessions").FindOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if res.Err() != nil {
+		span.RecordError(res.Err())
+		span.SetStatus(codes.Error, res.Err().Error())
+		if res.Err() == mongo.ErrNoDocuments {
+			return nil, ErrSessionNotFound
+		}
+		return nil, res.Err()
+	}
+
+	var session Session
+	err = res.Decode(&session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// GetSessions returns all active sessions of the user with the provided id. The sessions are sorted by the time they
+// were used the last time, so that the most recently used session is returned first.
+func (c *mongodbClient) GetSessions(ctx context.Context, userID string) ([]Session, error) {
+	ctx, span := c.tracer.Start(ctx, "db.GetSessions")
+	span.SetAttributes(attribute.Key("userID").String(userID))
+	defer span.End()
+
+	var sessions []Session
+
+	cursor, err := c.coll(ctx, "sessions").Find(ctx, bson.D{{Key: "user.id", Value: userID}, {Key: "expiresAt", Value: bson.D{{Key: "$gt", Value: time.Now()}}}}, options.Find().SetSort(bson.D{{Key: "updatedAt", Value: -1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &sessions)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// GetAndUpdateSession returns the session for the provided `sessionID` and updates the `updatedAt` field of the session
+// to the current time, so that we know when the session was used the last time.
+func (c *mongodbClient) GetAndUpdateSession(ctx context.Context, sessionID string) (*Session, error) {
+	ctx, span := c.tracer.Start(ctx, "db.UpdateSession")
+	span.SetAttributes(attribute.Key("sessionID").String(sessionID))
+	defer span.End()
+
+	id, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		span.RecordError(ErrSessionNotFound)
+		span.SetStatus(codes.Error, ErrSessionNotFound.Error())
+		return nil, ErrSessionNotFound
+	}
+
+	res := c.coll(ctx, "sessions").FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: id}}, bson.D{{Key: "$set", Value: bson.D{{Key: "updatedAt", Value: time.Now()}}}})
+	if res.Err() != nil {
+		span.RecordError(res.Err())
+		span.SetStatus(codes.Error, res.Err().Error())
+		if res.Err() == mongo.ErrNoDocuments {
+			return nil, ErrSessionNotFound
+		}
+		return nil, res.Err()
+	}
+
+	var session Session
+	err = res.Decode(&session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// DeleteSession deletes an existing user session, so that the session can not be used anymore to make requests against
+// our API which requires an authenticated user.
+func (c *mongodbClient) DeleteSession(ctx context.Context, sessionID string) error {
+	ctx, span := c.tracer.Start(ctx, "db.DeleteSession")
+	span.SetAttributes(attribute.Key("sessionID").String(sessionID))
+	defer span.End()
+
+	id, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		span.RecordError(ErrSessionNotFound)
+		span.SetStatus(codes.Error, ErrSessionNotFound.Error())
+		return ErrSessionNotFound
+	}
+
+	res, err := c.coll(ctx, "sessions").DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	if res.DeletedCount != 1 {
+		span.RecordError(ErrSessionNotFound)
+		span.SetStatus(codes.Error, ErrSessionNotFound.Error())
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// DeleteSessions deletes all sessions of the user with the provided id, so that the user must sign in again on all
+// devices.
+func (c *mongodbClient) DeleteSessions(ctx context.Context, userID string) error {
+	ctx, span := c.tracer.Start(ctx, "db.DeleteSessions")
+	span.SetAttributes(attribute.Key("userID").String(userID))
+	defer span.End()
+
+	_, err := c.coll(ctx, "sessions").DeleteMany(ctx, bson.D{{Key: "user.id", Value: userID}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// CreateAPIToken saves the provided personal API token.
+func (c *mongodbClient) CreateAPIToken(ctx context.Context, token *APIToken) error {
+	ctx, span := c.tracer.Start(ctx, "db.CreateAPIToken")
+	span.SetAttributes(attribute.Key("id").String(token.ID))
+	span.SetAttributes(attribute.Key("userID").String(token.User.ID))
+	defer span.End()
+
+	_, err := c.coll(ctx, "tokens").InsertOne(ctx, token)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetAPIToken returns the personal API token with the provided id. If the token doesn't exist or if it is already
+// expired ErrAPITokenNotFound is returned.
+func (c *mongodbClient) GetAPIToken(ctx context.Context, id string) (*APIToken, error) {
+	ctx, span := c.tracer.Start(ctx, "db.GetAPIToken")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	res := c.coll(ctx, "tokens").FindOne(ctx, bson.D{{Key: "_id", Value: id}, {Key: "expiresAt", Value: bson.D{{Key: "$gt", Value: time.Now()}}}})
+	if res.Err() != nil {
+		span.RecordError(res.Err())
+		span.SetStatus(codes.Error, res.Err().Error())
+		if res.Err() == mongo.ErrNoDocuments {
+			return nil, ErrAPITokenNotFound
+		}
+		return nil, res.Err()
+	}
+
+	var token APIToken
+	err := res.Decode(&token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// GetAPITokens returns all personal API tokens of the user with the provided id, which are not expired.
+func (c *mongodbClient) GetAPITokens(ctx context.Context, userID string) ([]APIToken, error) {
+	ctx, span := c.tracer.Start(ctx, "db.GetAPITokens")
+	span.SetAttributes(attribute.Key("userID").String(userID))
+	defer span.End()
+
+	var tokens []APIToken
+
+	cursor, err := c.coll(ctx, "tokens").Find(ctx, bson.D{{Key: "user.id", Value: userID}, {Key: "expiresAt", Value: bson.D{{Key: "$gt", Value: time.Now()}}}}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &tokens)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// DeleteAPIToken deletes the personal API token with the provided id. A token can only be deleted by the user who
+// created it, so that ErrAPITokenNotFound is returned when the token doesn't belong to the provided user.
+func (c *mongodbClient) DeleteAPIToken(ctx context.Context, userID, id string) error {
+	ctx, span := c.tracer.Start(ctx, "db.DeleteAPIToken")
+	span.SetAttributes(attribute.Key("userID").String(userID))
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	res, err := c.coll(ctx, "tokens").DeleteOne(ctx, bson.D{{Key: "_id", Value: id}, {Key: "user.id", Value: userID}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	if res.DeletedCount != 1 {
+		span.RecordError(ErrAPITokenNotFound)
+		span.SetStatus(codes.Error, ErrAPITokenNotFound.Error())
+		return ErrAPITokenNotFound
+	}
+
+	return nil
+}
+
+// AcquireLease acquires or renews the lease with the provided name for the provided holder. The lease is only acquired
+// when it does not exist yet, when it is already held by the holder or when it is expired. The returned lease always
+// contains the current holder of the lease, so that the caller can check if it is the leader.
+func (c *mongodbClient) AcquireLease(ctx context.Context, name, holder string, duration time.Duration) (*Lease, error) {
+	ctx, span := c.tracer.Start(ctx, "db.AcquireLease")
+	span.SetAttributes(attribute.Key("name").String(name))
+	span.SetAttributes(attribute.Key("holder").String(holder))
+	defer span.End()
+
+	now := time.Now().UTC()
+	filter := bson.D{
+		{Key: "_id", Value: name},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "holder", Value: holder}},
+			bson.D{{Key: "expiresAt", Value: bson.D{{Key: "$lte", Value: now}}}},
+		}},
+	}
+
+	// We use an update pipeline, so that the `acquiredAt` field is only updated when the holder of the lease changes.
+	update := mongo.Pipeline{
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "acquiredAt", Value: bson.D{{Key: "$cond", Value: bson.A{bson.D{{Key: "$eq", Value: bson.A{"$holder", bson.D{{Key: "$literal", Value: holder}}}}}, "$acquiredAt", now}}}},
+			{Key: "holder", Value: bson.D{{Key: "$literal", Value: holder}}},
+			{Key: "renewedAt", Value: now},
+			{Key: "expiresAt", Value: now.Add(duration)},
+		}}},
+	}
+
+	var lease Lease
+
+	err := c.coll(ctx, "leases").FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&lease)
+	if err != nil {
+		// When the lease is held by another holder, the filter does not match and MongoDB tries to insert a new
+		// document with the same id, which fails with a duplicate key error. In this case we return the current lease.
+		if mongo.IsDuplicateKeyError(err) {
+			return c.GetLease(ctx, name)
+		}
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &lease, nil
+}
+
+// ReleaseLease releases the lease with the provided name, when it is held by the provided holder. The lease is not
+// deleted, instead it is marked as expired, so that another holder can acquire the lease immediately.
+func (c *mongodbClient) ReleaseLease(ctx context.Context, name, holder string) error {
+	ctx, span := c.tracer.Start(ctx, "db.ReleaseLease")
+	span.SetAttributes(attribute.Key("name").String(name))
+	span.SetAttributes(attribute.Key("holder").String(holder))
+	defer span.End()
+
+	_, err := c.coll(ctx, "leases").UpdateOne(ctx, bson.D{{Key: "_id", Value: name}, {Key: "holder", Value: holder}}, bson.D{{Key: "$set", Value: bson.D{{Key: "expiresAt", Value: time.Now().UTC()}}}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetLease returns the lease with the provided name. If the lease does not exist ErrLeaseNotFound is returned.
+func (c *mongodbClient) GetLease(ctx context.Context, name string) (*Lease, error) {
+	ctx, span := c.tracer.Start(ctx, "db.GetLease")
+	span.SetAttributes(attribute.Key("name").String(name))
+	defer span.End()
+
+	var lease Lease
+
+	err := c.coll(ctx, "leases").FindOne(ctx, bson.D{{Key: "_id", Value: name}}).Decode(&lease)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrLeaseNotFound
+		}
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &lease, nil
+}
@@ -0,0 +1,2382 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
+	dashboardv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/dashboard/v1"
+	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/plugins/plugin"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// postgresTables is the list of tables which are used to store the resources synced by the watcher. Each table mirrors
+// a collection from the MongoDB backend, where the document is saved in the `data` column. The `cluster` and
+// `updated_at` columns are used to delete outdated documents, like it is done for MongoDB.
+var postgresTables = []string{"plugins", "namespaces", "crds", "applications", "dashboards", "teams", "users", "tags", "topology", "audit", "clusters"}
+
+// postgresGroups maps the fields which can be used to group applications to the corresponding column.
+var postgresGroups = map[string]string{
+	"cluster":   "data->>'cluster'",
+	"namespace": "data->>'namespace'",
+	"name":      "data->>'name'",
+}
+
+// postgresTopologyFields maps the fields which can be used to get the topology to the corresponding column.
+var postgresTopologyFields = map[string]string{
+	"sourceID": "data->>'sourceID'",
+	"targetID": "data->>'targetID'",
+}
+
+type postgresClient struct {
+	db       *sql.DB
+	tracer   trace.Tracer
+	schemas  sync.Map
+	schemaMu sync.Mutex
+}
+
+// postgresRow is a single row in one of the postgresTables.
+type postgresRow struct {
+	ID        string
+	Cluster   string
+	UpdatedAt int64
+	Data      any
+}
+
+// postgresExecer is implemented by sql.DB and sql.Tx, so that rows can be written with or without a transaction.
+type postgresExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// newPostgresClient creates a new PostgreSQL client which implements our database interface. The tables are created
+// in a schema named "kobs" when they are used the first time.
+//
+// To create a local PostgreSQL for testing the following commands can be used:
+//
+//	docker run --name postgres -d -p 5432:5432 -e POSTGRES_PASSWORD=postgres postgres
+//	docker stop postgres
+//	docker rm postgres
+func newPostgresClient(config Config) (Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, err := sql.Open("postgres", config.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		return nil, err
+	}
+
+	return &postgresClient{
+		db:     db,
+		tracer: otel.Tracer("db"),
+	}, nil
+}
+
+// schema returns the quoted name of the schema for the current request. Like the database name for MongoDB, it can be
+// overwritten via the context, so that each test can use its own schema.
+func (c *postgresClient) schema(ctx context.Context) string {
+	dbName := ctx.Value(kobsDbName)
+	if dbName == nil {
+		dbName = "kobs"
+	}
+	return pq.QuoteIdentifier(dbName.(string))
+}
+
+// table returns the quoted name of the provided table. Before the name is returned we ensure that the schema and all
+// tables exist, so that they must not be created manually before kobs is started.
+func (c *postgresClient) table(ctx context.Context, table string) (string, error) {
+	schema := c.schema(ctx)
+	if err := c.migrate(ctx, schema); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s.%s", schema, pq.QuoteIdentifier(table)), nil
+}
+
+// migrate creates the provided schema with all tables and indexes. The statements are only executed once per schema
+// and client.
+func (c *postgresClient) migrate(ctx context.Context, schema string) error {
+	if _, ok := c.schemas.Load(schema); ok {
+		return nil
+	}
+
+	c.schemaMu.Lock()
+	defer c.schemaMu.Unlock()
+
+	if _, ok := c.schemas.Load(schema); ok {
+		return nil
+	}
+
+	statements := []string{fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", schema)}
+
+	for _, table := range postgresTables {
+		statements = append(statements,
+			fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s.%s (id TEXT PRIMARY KEY, cluster TEXT NOT NULL DEFAULT '', updated_at BIGINT NOT NULL DEFAULT 0, data JSONB NOT NULL)", schema, pq.QuoteIdentifier(table)),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s.%s (cluster, updated_at)", pq.QuoteIdentifier(table+"_cluster_updated_at"), schema, pq.QuoteIdentifier(table)),
+		)
+	}
+
+	statements = append(statements,
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s.documents (collection TEXT NOT NULL, id TEXT NOT NULL, data JSONB NOT NULL, PRIMARY KEY (collection, id))", schema),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s.sessions (id TEXT PRIMARY KEY, \"user\" JSONB NOT NULL, user_agent TEXT NOT NULL DEFAULT '', ip TEXT NOT NULL DEFAULT '', created_at TIMESTAMPTZ NOT NULL, updated_at TIMESTAMPTZ NOT NULL, expires_at TIMESTAMPTZ NOT NULL)", schema),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS sessions_updated_at ON %s.sessions (updated_at)", schema),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS sessions_user_id ON %s.sessions ((\"user\"->>'id'))", schema),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s.tokens (id TEXT PRIMARY KEY, user_id TEXT NOT NULL, created_at TIMESTAMPTZ NOT NULL, expires_at TIMESTAMPTZ NOT NULL, data JSONB NOT NULL)", schema),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS tokens_user_id ON %s.tokens (user_id)", schema),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s.syncs (id TEXT PRIMARY KEY, cluster TEXT NOT NULL, resource TEXT NOT NULL, timestamp TIMESTAMPTZ NOT NULL, data JSONB NOT NULL)", schema),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS syncs_cluster_resource_timestamp ON %s.syncs (cluster, resource, timestamp)", schema),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s.health (id TEXT PRIMARY KEY, status TEXT NOT NULL, data JSONB NOT NULL)", schema),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s.healthhistory (id TEXT PRIMARY KEY, application TEXT NOT NULL, timestamp TIMESTAMPTZ NOT NULL, data JSONB NOT NULL)", schema),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS healthhistory_application_timestamp ON %s.healthhistory (application, timestamp)", schema),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s.recordings (id TEXT PRIMARY KEY, timestamp TIMESTAMPTZ NOT NULL, data JSONB NOT NULL, content BYTEA NOT NULL)", schema),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS recordings_timestamp ON %s.recordings (timestamp)", schema),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s.leases (name TEXT PRIMARY KEY, holder TEXT NOT NULL, acquired_at TIMESTAMPTZ NOT NULL, renewed_at TIMESTAMPTZ NOT NULL, expires_at TIMESTAMPTZ NOT NULL)", schema),
+	)
+
+	for _, statement := range statements {
+		if _, err := c.db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	c.schemas.Store(schema, true)
+	return nil
+}
+
+// upsert inserts the provided row into the provided table or replaces the existing row with the same id.
+func (c *postgresClient) upsert(ctx context.Context, execer postgresExecer, table string, row postgresRow) error {
+	data, err := json.Marshal(row.Data)
+	if err != nil {
+		return err
+	}
+
+	_, err = execer.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (id, cluster, updated_at, data) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO UPDATE SET cluster = EXCLUDED.cluster, updated_at = EXCLUDED.updated_at, data = EXCLUDED.data", table), row.ID, row.Cluster, row.UpdatedAt, data)
+	return err
+}
+
+// save upserts all provided rows and deletes all rows which were updated before the provided `updatedAt` time. If a
+// cluster is provided only the outdated rows for this cluster are deleted. This is the same logic as it is used in the
+// save method of the MongoDB client.
+func (c *postgresClient) save(ctx context.Context, table string, rows []postgresRow, cluster string, updatedAt int64) error {
+	t, err := c.table(ctx, table)
+	if err != nil {
+		return err
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, row := range rows {
+		if err := c.upsert(ctx, tx, t, row); err != nil {
+			return err
+		}
+	}
+
+	if cluster != "" {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE cluster = $1 AND updated_at < $2", t), cluster, updatedAt)
+	} else {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE updated_at < $1", t), updatedAt)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// saveOne upserts a single row in the provided table.
+func (c *postgresClient) saveOne(ctx context.Context, table string, row postgresRow) error {
+	t, err := c.table(ctx, table)
+	if err != nil {
+		return err
+	}
+
+	return c.upsert(ctx, c.db, t, row)
+}
+
+// deleteByID deletes the row with the provided id from the provided table.
+func (c *postgresClient) deleteByID(ctx context.Context, table, id string) error {
+	t, err := c.table(ctx, table)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = $1", t), id)
+	return err
+}
+
+// postgresQuery runs the provided query, which must return a single JSON column, and decodes all returned rows into
+// the provided type.
+func postgresQuery[T any](ctx context.Context, db *sql.DB, query string, args ...any) ([]T, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []T
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		var result T
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// postgresQueryOne runs the provided query, which must return a single JSON column, and decodes the first returned row
+// into the provided type. If the query doesn't return a row, sql.ErrNoRows is returned.
+func postgresQueryOne[T any](ctx context.Context, db *sql.DB, query string, args ...any) (*T, error) {
+	var data []byte
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&data); err != nil {
+		return nil, err
+	}
+
+	var result T
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// postgresApplicationsFilter returns the where clause and the arguments to filter the applications by the provided
+// teams, clusters, namespaces, tags and search term.
+func postgresApplicationsFilter(teams, clusters, namespaces, tags []string, searchTerm string) (string, []any) {
+	var conditions []string
+	var args []any
+
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if searchTerm != "" {
+		add("data->>'name' ~* $%d", searchTerm)
+	}
+
+	if len(teams) > 0 {
+		add("data->'teams' ?| $%d", pq.Array(teams))
+	}
+
+	if len(clusters) > 0 {
+		add("cluster = ANY($%d)", pq.Array(clusters))
+	}
+
+	if len(namespaces) > 0 {
+		add("data->>'namespace' = ANY($%d)", pq.Array(namespaces))
+	}
+
+	if len(tags) > 0 {
+		add("data->'tags' ?| $%d", pq.Array(tags))
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// postgresHealthFilter adds a condition to the provided where clause and arguments, to filter the applications by the
+// provided health statuses, which are stored in the provided health table.
+func postgresHealthFilter(where string, args []any, health []string, healthTable string) (string, []any) {
+	if len(health) == 0 {
+		return where, args
+	}
+
+	args = append(args, pq.Array(health))
+	condition := fmt.Sprintf("id IN (SELECT id FROM %s WHERE status = ANY($%d))", healthTable, len(args))
+
+	if where == "" {
+		return " WHERE " + condition, args
+	}
+
+	return where + " AND " + condition, args
+}
+
+func (c *postgresClient) CreateIndexes(ctx context.Context) error {
+	ctx, span := c.tracer.Start(ctx, "db.CreateIndexes")
+	defer span.End()
+
+	// PostgreSQL doesn't support TTL indexes, so that we delete all inactive sessions which are older than 7 days
+	// (168h) and all expired sessions when the indexes are created. Expired sessions are also ignored when a session is
+	// returned.
+	t, err := c.table(ctx, "sessions")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	_, err = c.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE updated_at < $1 OR expires_at < $2", t), time.Now().Add(-sessionTTL), time.Now())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	// The same applies to personal API tokens, so that all expired tokens are deleted here and ignored when a token is
+	// returned.
+	t, err = c.table(ctx, "tokens")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	_, err = c.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE expires_at < $1", t), time.Now())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	// Delete all sync runs of the watcher which are older than 7 days (168h).
+	t, err = c.table(ctx, "syncs")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	_, err = c.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE timestamp < $1", t), time.Now().Add(-syncRunTTL))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	// Delete all entries in the health history of the applications which are older than 30 days (720h).
+	t, err = c.table(ctx, "healthhistory")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	_, err = c.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE timestamp < $1", t), time.Now().Add(-applicationHealthTTL))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *postgresClient) SavePlugins(ctx context.Context, cluster string, plugins []plugin.Instance) error {
+	if len(plugins) == 0 {
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, "db.SavePlugins")
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	defer span.End()
+
+	var rows []postgresRow
+	updatedAt := time.Now().UnixMilli()
+
+	for _, p := range plugins {
+		p.ID = fmt.Sprintf("/cluster/%s/type/%s/name/%s", cluster, p.Type, p.Name)
+		p.Cluster = cluster
+		p.UpdatedAt = updatedAt
+		rows = append(rows, postgresRow{ID: p.ID, Cluster: cluster, UpdatedAt: updatedAt, Data: p})
+	}
+
+	err := c.save(ctx, "plugins", rows, cluster, updatedAt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *postgresClient) SaveNamespaces(ctx context.Context, cluster string, namespaces []string) error {
+	if len(namespaces) == 0 {
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, "db.SaveNamespaces")
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	defer span.End()
+
+	var rows []postgresRow
+	updatedAt := time.Now().UnixMilli()
+
+	for _, n := range namespaces {
+		namespace := Namespace{
+			ID:        fmt.Sprintf("/cluster/%s/namespace/%s", cluster, n),
+			Namespace: n,
+			Cluster:   cluster,
+			UpdatedAt: updatedAt,
+		}
+
+		rows = append(rows, postgresRow{ID: namespace.ID, Cluster: cluster, UpdatedAt: updatedAt, Data: namespace})
+	}
+
+	err := c.save(ctx, "namespaces", rows, cluster, updatedAt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *postgresClient) SaveCRDs(ctx context.Context, crds []kubernetes.CRD) error {
+	if len(crds) == 0 {
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, "db.SaveCRDs")
+	defer span.End()
+
+	var rows []postgresRow
+	updatedAtTime := time.Now()
+	updatedAt := updatedAtTime.UnixMilli()
+
+	for _, crd := range crds {
+		crd.UpdatedAt = updatedAt
+		rows = append(rows, postgresRow{ID: crd.ID, UpdatedAt: updatedAt, Data: crd})
+	}
+
+	err := c.save(ctx, "crds", rows, "", updatedAtTime.Add(time.Duration(-72*time.Hour)).UnixMilli())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *postgresClient) SaveApplications(ctx context.Context, cluster string, applications []applicationv1.ApplicationSpec) error {
+	if len(applications) == 0 {
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, "db.SaveApplications")
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	defer span.End()
+
+	var rows []postgresRow
+	updatedAt := time.Now().UnixMilli()
+
+	for _, a := range applications {
+		a.UpdatedAt = updatedAt
+		rows = append(rows, postgresRow{ID: a.ID, Cluster: a.Cluster, UpdatedAt: updatedAt, Data: a})
+	}
+
+	err := c.save(ctx, "applications", rows, cluster, updatedAt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *postgresClient) SaveApplication(ctx context.Context, application *applicationv1.ApplicationSpec) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveApplication")
+	defer span.End()
+
+	application.UpdatedAt = time.Now().UnixMilli()
+
+	err := c.saveOne(ctx, "applications", postgresRow{ID: application.ID, Cluster: application.Cluster, UpdatedAt: application.UpdatedAt, Data: application})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *postgresClient) SaveDashboards(ctx context.Context, cluster string, dashboards []dashboardv1.DashboardSpec) error {
+	if len(dashboards) == 0 {
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, "db.SaveDashboards")
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	defer span.End()
+
+	var rows []postgresRow
+	updatedAt := time.Now().UnixMilli()
+
+	for _, d := range dashboards {
+		d.UpdatedAt = updatedAt
+		rows = append(rows, postgresRow{ID: d.ID, Cluster: d.Cluster, UpdatedAt: updatedAt, Data: d})
+	}
+
+	err := c.save(ctx, "dashboards", rows, cluster, updatedAt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *postgresClient) SaveDashboard(ctx context.Context, dashboard *dashboardv1.DashboardSpec) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveDashboard")
+	defer span.End()
+
+	dashboard.UpdatedAt = time.Now().UnixMilli()
+
+	err := c.saveOne(ctx, "dashboards", postgresRow{ID: dashboard.ID, Cluster: dashboard.Cluster, UpdatedAt: dashboard.UpdatedAt, Data: dashboard})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *postgresClient) SaveTeams(ctx context.Context, cluster string, teams []teamv1.TeamSpec) error {
+	if len(teams) == 0 {
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, "db.SaveTeams")
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	defer span.End()
+
+	var rows []postgresRow
+	updatedAt := time.Now().UnixMilli()
+
+	for _, t := range teams {
+		t.UpdatedAt = updatedAt
+		rows = append(rows, postgresRow{ID: t.ID, Cluster: t.Cluster, UpdatedAt: updatedAt, Data: t})
+	}
+
+	err := c.save(ctx, "teams", rows, cluster, updatedAt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *postgresClient) SaveTeam(ctx context.Context, team *teamv1.TeamSpec) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveTeam")
+	defer span.End()
+
+	team.UpdatedAt = time.Now().UnixMilli()
+
+	err := c.saveOne(ctx, "teams", postgresRow{ID: team.ID, Cluster: team.Cluster, UpdatedAt: team.UpdatedAt, Data: team})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *postgresClient) SaveUsers(ctx context.Context, cluster string, users []userv1.UserSpec) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, "db.SaveUsers")
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	defer span.End()
+
+	var rows []postgresRow
+	updatedAt := time.Now().UnixMilli()
+
+	for _, u := range users {
+		u.UpdatedAt = updatedAt
+		rows = append(rows, postgresRow{ID: u.ID, Cluster: u.Cluster, UpdatedAt: updatedAt, Data: u})
+	}
+
+	err := c.save(ctx, "users", rows, cluster, updatedAt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *postgresClient) SaveUser(ctx context.Context, user *userv1.UserSpec) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveUser")
+	defer span.End()
+
+	user.UpdatedAt = time.Now().UnixMilli()
+
+	err := c.saveOne(ctx, "users", postgresRow{ID: user.ID, Cluster: user.Cluster, UpdatedAt: user.UpdatedAt, Data: user})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *postgresClient) SaveCluster(ctx context.Context, cluster *Cluster) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveCluster")
+	span.SetAttributes(attribute.Key("name").String(cluster.Name))
+	defer span.End()
+
+	cluster.UpdatedAt = time.Now().UnixMilli()
+
+	err := c.saveOne(ctx, "clusters", postgresRow{ID: cluster.Name, UpdatedAt: cluster.UpdatedAt, Data: cluster})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *postgresClient) SaveTags(ctx context.Context, applications []applicationv1.ApplicationSpec) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveTags")
+	defer span.End()
+
+	var rows []postgresRow
+	updatedAtTime := time.Now()
+	updatedAt := updatedAtTime.UnixMilli()
+
+	for _, a := range applications {
+		for _, t := range a.Tags {
+			tag := Tag{
+				ID:        fmt.Sprintf("/tag/%s", t),
+				Tag:       t,
+				UpdatedAt: updatedAt,
+			}
+
+			rows = append(rows, postgresRow{ID: tag.ID, UpdatedAt: updatedAt, Data: tag})
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	err := c.save(ctx, "tags", rows, "", updatedAtTime.Add(time.Duration(-72*time.Hour)).UnixMilli())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *postgresClient) SaveTopology(ctx context.Context, cluster string, applications []applicationv1.ApplicationSpec) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveTopology")
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	defer span.End()
+
+	var rows []postgresRow
+	updatedAt := time.Now().UnixMilli()
+
+	for _, a := range applications {
+		for _, t := range topologyForApplication(a, updatedAt) {
+			rows = append(rows, postgresRow{ID: t.ID, Cluster: t.SourceCluster, UpdatedAt: updatedAt, Data: t})
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	err := c.save(ctx, "topology", rows, cluster, updatedAt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// SaveApplicationTopology replaces the topology edges of the provided application. In contrast to the SaveTopology
+// method it only deletes the outdated edges where the provided application is the source.
+func (c *postgresClient) SaveApplicationTopology(ctx context.Context, application *applicationv1.ApplicationSpec) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveApplicationTopology")
+	span.SetAttributes(attribute.Key("id").String(application.ID))
+	defer span.End()
+
+	updatedAt := time.Now().UnixMilli()
+	topology := topologyForApplication(*application, updatedAt)
+
+	err := func() error {
+		t, err := c.table(ctx, "topology")
+		if err != nil {
+			return err
+		}
+
+		tx, err := c.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		for _, edge := range topology {
+			if err := c.upsert(ctx, tx, t, postgresRow{ID: edge.ID, Cluster: edge.SourceCluster, UpdatedAt: updatedAt, Data: edge}); err != nil {
+				return err
+			}
+		}
+
+		sourceID := fmt.Sprintf("/cluster/%s/namespace/%s/name/%s", application.Cluster, application.Namespace, application.Name)
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE data->>'sourceID' = $1 AND updated_at < $2", t), sourceID, updatedAt); err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// SaveDocuments replaces all documents in the provided plugin collection with the provided documents. Documents which
+// are not part of the provided list are deleted.
+func (c *postgresClient) SaveDocuments(ctx context.Context, collection string, documents []Document) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveDocuments")
+	span.SetAttributes(attribute.Key("collection").String(collection))
+	defer span.End()
+
+	err := func() error {
+		t, err := c.table(ctx, "documents")
+		if err != nil {
+			return err
+		}
+
+		tx, err := c.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		ids := []string{}
+
+		for _, d := range documents {
+			data, err := json.Marshal(d.Data)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (collection, id, data) VALUES ($1, $2, $3) ON CONFLICT (collection, id) DO UPDATE SET data = EXCLUDED.data", t), collection, d.ID, data)
+			if err != nil {
+				return err
+			}
+
+			ids = append(ids, d.ID)
+		}
+
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE collection = $1 AND NOT (id = ANY($2))", t), collection, pq.Array(ids)); err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// DeleteApplication deletes the application with the provided id and all topology edges where the application is the
+// source. It doesn't return an error when the application doesn't exist.
+func (c *postgresClient) DeleteApplication(ctx context.Context, id string) error {
+	ctx, span := c.tracer.Start(ctx, "db.DeleteApplication")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	err := c.deleteByID(ctx, "applications", id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	t, err := c.table(ctx, "topology")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	_, err = c.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE data->>'sourceID' = $1", t), id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// DeleteDashboard deletes the dashboard with the provided id. It doesn't return an error when the dashboard doesn't
+// exist.
+func (c *postgresClient) DeleteDashboard(ctx context.Context, id string) error {
+	ctx, span := c.tracer.Start(ctx, "db.DeleteDashboard")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	err := c.deleteByID(ctx, "dashboards", id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// DeleteTeam deletes the team with the provided id. It doesn't return an error when the team doesn't exist.
+func (c *postgresClient) DeleteTeam(ctx context.Context, id string) error {
+	ctx, span := c.tracer.Start(ctx, "db.DeleteTeam")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	err := c.deleteByID(ctx, "teams", id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// DeleteUser deletes the user with the provided id. It doesn't return an error when the user doesn't exist.
+func (c *postgresClient) DeleteUser(ctx context.Context, id string) error {
+	ctx, span := c.tracer.Start(ctx, "db.DeleteUser")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	err := c.deleteByID(ctx, "users", id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// DeleteCluster deletes the registered cluster with the provided name and all data, which was synced from the cluster.
+func (c *postgresClient) DeleteCluster(ctx context.Context, name string) error {
+	ctx, span := c.tracer.Start(ctx, "db.DeleteCluster")
+	span.SetAttributes(attribute.Key("name").String(name))
+	defer span.End()
+
+	err := func() error {
+		tx, err := c.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		t, err := c.table(ctx, "clusters")
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = $1", t), name); err != nil {
+			return err
+		}
+
+		for table := range clusterCollections {
+			t, err := c.table(ctx, table)
+			if err != nil {
+				return err
+			}
+
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE cluster = $1", t), name); err != nil {
+				return err
+			}
+		}
+
+		return tx.Commit()
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *postgresClient) GetPlugins(ctx context.Context) ([]plugin.Instance, error) {
+	_, span := c.tracer.Start(ctx, "db.GetPlugins")
+	defer span.End()
+
+	plugins, err := getAll[plugin.Instance](ctx, c, "plugins")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return plugins, nil
+}
+
+func (c *postgresClient) GetNamespaces(ctx context.Context) ([]Namespace, error) {
+	_, span := c.tracer.Start(ctx, "db.GetNamespaces")
+	defer span.End()
+
+	namespaces, err := getAll[Namespace](ctx, c, "namespaces")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return namespaces, nil
+}
+
+func (c *postgresClient) GetNamespacesByClusters(ctx context.Context, clusters []string) ([]Namespace, error) {
+	_, span := c.tracer.Start(ctx, "db.GetNamespacesByClusters")
+	span.SetAttributes(attribute.Key("clusters").StringSlice(clusters))
+	defer span.End()
+
+	if len(clusters) == 0 {
+		return c.GetNamespaces(ctx)
+	}
+
+	namespaces, err := func() ([]Namespace, error) {
+		t, err := c.table(ctx, "namespaces")
+		if err != nil {
+			return nil, err
+		}
+
+		return postgresQuery[Namespace](ctx, c.db, fmt.Sprintf("SELECT data FROM %s WHERE cluster = ANY($1) ORDER BY id COLLATE \"C\"", t), pq.Array(clusters))
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return namespaces, nil
+}
+
+func (c *postgresClient) GetCRDs(ctx context.Context) ([]kubernetes.CRD, error) {
+	_, span := c.tracer.Start(ctx, "db.GetCRDs")
+	defer span.End()
+
+	crds, err := getAll[kubernetes.CRD](ctx, c, "crds")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return crds, nil
+}
+
+func (c *postgresClient) GetCRDByID(ctx context.Context, id string) (*kubernetes.CRD, error) {
+	_, span := c.tracer.Start(ctx, "db.GetCRDByID")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	crd, err := getByID[kubernetes.CRD](ctx, c, "crds", id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return crd, nil
+}
+
+func (c *postgresClient) GetApplications(ctx context.Context) ([]applicationv1.ApplicationSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetApplications")
+	defer span.End()
+
+	applications, err := getAll[applicationv1.ApplicationSpec](ctx, c, "applications")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return applications, nil
+}
+
+func (c *postgresClient) GetApplicationsByFilter(ctx context.Context, teams, clusters, namespaces, tags, health []string, searchTerm string, limit, offset int) ([]applicationv1.ApplicationSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetApplicationsByFilter")
+	span.SetAttributes(attribute.Key("teams").StringSlice(teams))
+	span.SetAttributes(attribute.Key("clusters").StringSlice(clusters))
+	span.SetAttributes(attribute.Key("namespaces").StringSlice(namespaces))
+	span.SetAttributes(attribute.Key("tags").StringSlice(tags))
+	span.SetAttributes(attribute.Key("health").StringSlice(health))
+	span.SetAttributes(attribute.Key("searchTerm").String(searchTerm))
+	span.SetAttributes(attribute.Key("limit").Int(limit))
+	span.SetAttributes(attribute.Key("offset").Int(offset))
+	defer span.End()
+
+	applications, err := func() ([]applicationv1.ApplicationSpec, error) {
+		t, err := c.table(ctx, "applications")
+		if err != nil {
+			return nil, err
+		}
+
+		h, err := c.table(ctx, "health")
+		if err != nil {
+			return nil, err
+		}
+
+		where, args := postgresApplicationsFilter(teams, clusters, namespaces, tags, searchTerm)
+		where, args = postgresHealthFilter(where, args, health, h)
+		args = append(args, limit, offset)
+
+		return postgresQuery[applicationv1.ApplicationSpec](ctx, c.db, fmt.Sprintf("SELECT data FROM %s%s ORDER BY data->>'name' COLLATE \"C\" LIMIT NULLIF($%d, 0) OFFSET $%d", t, where, len(args)-1, len(args)), args...)
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return applications, nil
+}
+
+func (c *postgresClient) GetApplicationsByFilterCount(ctx context.Context, teams, clusters, namespaces, tags, health []string, searchTerm string) (int, error) {
+	_, span := c.tracer.Start(ctx, "db.GetApplicationsByFilterCount")
+	span.SetAttributes(attribute.Key("teams").StringSlice(teams))
+	span.SetAttributes(attribute.Key("clusters").StringSlice(clusters))
+	span.SetAttributes(attribute.Key("namespaces").StringSlice(namespaces))
+	span.SetAttributes(attribute.Key("tags").StringSlice(tags))
+	span.SetAttributes(attribute.Key("health").StringSlice(health))
+	span.SetAttributes(attribute.Key("searchTerm").String(searchTerm))
+	defer span.End()
+
+	count, err := func() (int, error) {
+		t, err := c.table(ctx, "applications")
+		if err != nil {
+			return 0, err
+		}
+
+		h, err := c.table(ctx, "health")
+		if err != nil {
+			return 0, err
+		}
+
+		where, args := postgresApplicationsFilter(teams, clusters, namespaces, tags, searchTerm)
+		where, args = postgresHealthFilter(where, args, health, h)
+
+		var count int
+		if err := c.db.QueryRowContext(ctx, fmt.Sprintf("SELECT count(*) FROM %s%s", t, where), args...).Scan(&count); err != nil {
+			return 0, err
+		}
+
+		return count, nil
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (c *postgresClient) GetApplicationsByGroup(ctx context.Context, teams, groups []string) ([]ApplicationGroup, error) {
+	if len(teams) == 0 || len(groups) == 0 {
+		return nil, nil
+	}
+
+	_, span := c.tracer.Start(ctx, "db.GetApplicationsByGroup")
+	span.SetAttributes(attribute.Key("teams").StringSlice(teams))
+	span.SetAttributes(attribute.Key("groups").StringSlice(groups))
+	defer span.End()
+
+	applications, err := func() ([]ApplicationGroup, error) {
+		t, err := c.table(ctx, "applications")
+		if err != nil {
+			return nil, err
+		}
+
+		// The groups are provided by the user, so that we only allow the fields which are also part of the
+		// ApplicationGroupID. The selected id columns are empty for all fields which are not used to group the
+		// applications, like it is done by MongoDB.
+		ids := map[string]string{"cluster": "''", "namespace": "''", "name": "''"}
+		var groupBy, orderBy []string
+
+		for _, group := range groups {
+			column, ok := postgresGroups[group]
+			if !ok {
+				return nil, fmt.Errorf("invalid group %s", group)
+			}
+
+			ids[group] = column
+			groupBy = append(groupBy, column)
+			orderBy = append(orderBy, column+" COLLATE \"C\"")
+		}
+
+		query := fmt.Sprintf(`SELECT %s, %s, %s,
+			jsonb_agg(data->'cluster' ORDER BY id), jsonb_agg(data->'namespace' ORDER BY id), jsonb_agg(data->'name' ORDER BY id),
+			(array_agg(data->>'description' ORDER BY id))[1], (array_agg(data->'tags' ORDER BY id))[1], (array_agg(data->'teams' ORDER BY id))[1], (array_agg(data->'topology' ORDER BY id))[1]
+			FROM %s WHERE data->'teams' ?| $1 GROUP BY %s ORDER BY %s`, ids["cluster"], ids["namespace"], ids["name"], t, strings.Join(groupBy, ", "), strings.Join(orderBy, ", "))
+
+		rows, err := c.db.QueryContext(ctx, query, pq.Array(teams))
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		var applications []ApplicationGroup
+
+		for rows.Next() {
+			var application ApplicationGroup
+			var clusters, namespaces, names, tags, teams, topology []byte
+			var description sql.NullString
+
+			if err := rows.Scan(&application.ID.Cluster, &application.ID.Namespace, &application.ID.Name, &clusters, &namespaces, &names, &description, &tags, &teams, &topology); err != nil {
+				return nil, err
+			}
+
+			application.Description = description.String
+
+			for _, field := range []struct {
+				data  []byte
+				value any
+			}{
+				{clusters, &application.Clusters},
+				{namespaces, &application.Namespaces},
+				{names, &application.Names},
+				{tags, &application.Tags},
+				{teams, &application.Teams},
+				{topology, &application.Topology},
+			} {
+				if field.data == nil {
+					continue
+				}
+
+				if err := json.Unmarshal(field.data, field.value); err != nil {
+					return nil, err
+				}
+			}
+
+			applications = append(applications, application)
+		}
+
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		return applications, nil
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return applications, nil
+}
+
+func (c *postgresClient) GetApplicationByID(ctx context.Context, id string) (*applicationv1.ApplicationSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetApplicationByID")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	application, err := getByID[applicationv1.ApplicationSpec](ctx, c, "applications", id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return application, nil
+}
+
+func (c *postgresClient) GetDashboards(ctx context.Context, clusters, namespaces []string) ([]dashboardv1.DashboardSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetDashboards")
+	defer span.End()
+
+	dashboards, err := func() ([]dashboardv1.DashboardSpec, error) {
+		t, err := c.table(ctx, "dashboards")
+		if err != nil {
+			return nil, err
+		}
+
+		where, args := postgresApplicationsFilter(nil, clusters, namespaces, nil, "")
+		return postgresQuery[dashboardv1.DashboardSpec](ctx, c.db, fmt.Sprintf("SELECT data FROM %s%s ORDER BY id COLLATE \"C\"", t, where), args...)
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return dashboards, nil
+}
+
+func (c *postgresClient) GetDashboardByID(ctx context.Context, id string) (*dashboardv1.DashboardSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetDashboardByID")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	dashboard, err := getByID[dashboardv1.DashboardSpec](ctx, c, "dashboards", id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return dashboard, nil
+}
+
+func (c *postgresClient) GetTeams(ctx context.Context, searchTerm string) ([]teamv1.TeamSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetTeams")
+	defer span.End()
+
+	teams, err := func() ([]teamv1.TeamSpec, error) {
+		t, err := c.table(ctx, "teams")
+		if err != nil {
+			return nil, err
+		}
+
+		if searchTerm == "" {
+			return postgresQuery[teamv1.TeamSpec](ctx, c.db, fmt.Sprintf("SELECT data FROM %s ORDER BY id COLLATE \"C\"", t))
+		}
+
+		return postgresQuery[teamv1.TeamSpec](ctx, c.db, fmt.Sprintf("SELECT data FROM %s WHERE id ~* $1 ORDER BY id COLLATE \"C\"", t), searchTerm)
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+func (c *postgresClient) GetTeamsByIDs(ctx context.Context, ids []string, searchTerm string) ([]teamv1.TeamSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetTeamsByIDs")
+	span.SetAttributes(attribute.Key("ids").StringSlice(ids))
+	defer span.End()
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	teams, err := func() ([]teamv1.TeamSpec, error) {
+		t, err := c.table(ctx, "teams")
+		if err != nil {
+			return nil, err
+		}
+
+		if searchTerm == "" {
+			return postgresQuery[teamv1.TeamSpec](ctx, c.db, fmt.Sprintf("SELECT data FROM %s WHERE id = ANY($1) ORDER BY id COLLATE \"C\"", t), pq.Array(ids))
+		}
+
+		return postgresQuery[teamv1.TeamSpec](ctx, c.db, fmt.Sprintf("SELECT data FROM %s WHERE id = ANY($1) AND id ~* $2 ORDER BY id COLLATE \"C\"", t), pq.Array(ids), searchTerm)
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+func (c *postgresClient) GetTeamByID(ctx context.Context, id string) (*teamv1.TeamSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetTeamByID")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	team, err := getByID[teamv1.TeamSpec](ctx, c, "teams", id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return team, nil
+}
+
+func (c *postgresClient) GetUsers(ctx context.Context) ([]userv1.UserSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetUsers")
+	defer span.End()
+
+	users, err := getAll[userv1.UserSpec](ctx, c, "users")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (c *postgresClient) GetClusters(ctx context.Context) ([]Cluster, error) {
+	_, span := c.tracer.Start(ctx, "db.GetClusters")
+	defer span.End()
+
+	clusters, err := getAll[Cluster](ctx, c, "clusters")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return clusters, nil
+}
+
+func (c *postgresClient) GetUserByID(ctx context.Context, id string) (*userv1.UserSpec, error) {
+	_, span := c.tracer.Start(ctx, "db.GetUserByID")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	user, err := getByID[userv1.UserSpec](ctx, c, "users", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (c *postgresClient) GetTags(ctx context.Context) ([]Tag, error) {
+	_, span := c.tracer.Start(ctx, "db.GetTags")
+	defer span.End()
+
+	tags, err := func() ([]Tag, error) {
+		t, err := c.table(ctx, "tags")
+		if err != nil {
+			return nil, err
+		}
+
+		return postgresQuery[Tag](ctx, c.db, fmt.Sprintf("SELECT data FROM %s ORDER BY data->>'tag' COLLATE \"C\"", t))
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (c *postgresClient) GetTopologyByIDs(ctx context.Context, field string, ids []string) ([]Topology, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	_, span := c.tracer.Start(ctx, "db.GetTopologyByIDs")
+	span.SetAttributes(attribute.Key("field").String(field))
+	span.SetAttributes(attribute.Key("ids").StringSlice(ids))
+	defer span.End()
+
+	topology, err := func() ([]Topology, error) {
+		column, ok := postgresTopologyFields[field]
+		if !ok {
+			return nil, fmt.Errorf("invalid field %s", field)
+		}
+
+		t, err := c.table(ctx, "topology")
+		if err != nil {
+			return nil, err
+		}
+
+		return postgresQuery[Topology](ctx, c.db, fmt.Sprintf("SELECT data FROM %s WHERE %s = ANY($1) ORDER BY id COLLATE \"C\"", t, column), pq.Array(ids))
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return topology, nil
+}
+
+// GetDocuments returns all documents from the provided plugin collection which are matching the provided query. The
+// documents are decoded into the provided `documents` value, which must be a pointer to a slice.
+func (c *postgresClient) GetDocuments(ctx context.Context, collection string, query DocumentQuery, documents any) error {
+	_, span := c.tracer.Start(ctx, "db.GetDocuments")
+	span.SetAttributes(attribute.Key("collection").String(collection))
+	defer span.End()
+
+	err := func() error {
+		t, err := c.table(ctx, "documents")
+		if err != nil {
+			return err
+		}
+
+		conditions := []string{"collection = $1"}
+		args := []any{collection}
+
+		if query.ID != "" {
+			args = append(args, query.ID)
+			conditions = append(conditions, fmt.Sprintf("id = $%d", len(args)))
+		}
+
+		for field, value := range query.Fields {
+			args = append(args, field, value)
+			conditions = append(conditions, fmt.Sprintf("data->>$%d::text = $%d", len(args)-1, len(args)))
+		}
+
+		if query.Search != "" && len(query.SearchFields) > 0 {
+			args = append(args, "%"+strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(query.Search)+"%")
+			search := len(args)
+
+			var searchConditions []string
+			for _, field := range query.SearchFields {
+				args = append(args, field)
+				searchConditions = append(searchConditions, fmt.Sprintf("data->>$%d::text ILIKE $%d", len(args), search))
+			}
+			conditions = append(conditions, "("+strings.Join(searchConditions, " OR ")+")")
+		}
+
+		var data []byte
+		err = c.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COALESCE(jsonb_agg(data ORDER BY id COLLATE \"C\"), '[]') FROM %s WHERE %s", t, strings.Join(conditions, " AND ")), args...).Scan(&data)
+		if err != nil {
+			return err
+		}
+
+		return json.Unmarshal(data, documents)
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// SaveAuditEvent saves a single audit event. The timestamp of the event is saved in the `updated_at` column, so that it
+// can be used to sort the events.
+func (c *postgresClient) SaveAuditEvent(ctx context.Context, event *AuditEvent) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveAuditEvent")
+	span.SetAttributes(attribute.Key("id").String(event.ID))
+	defer span.End()
+
+	err := func() error {
+		t, err := c.table(ctx, "audit")
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.db.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (id, cluster, updated_at, data) VALUES ($1, $2, $3, $4)", t), event.ID, event.Cluster, event.Timestamp, data)
+		return err
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetAuditEvents returns all audit events which are matching the provided filters, sorted by their timestamp, so that
+// the newest event is returned first. Empty filters are ignored, as well as a `timeStart` or `timeEnd` of 0.
+func (c *postgresClient) GetAuditEvents(ctx context.Context, user, cluster, namespace, verb string, timeStart, timeEnd int64, limit, offset int) ([]AuditEvent, error) {
+	_, span := c.tracer.Start(ctx, "db.GetAuditEvents")
+	span.SetAttributes(attribute.Key("user").String(user))
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	span.SetAttributes(attribute.Key("namespace").String(namespace))
+	span.SetAttributes(attribute.Key("verb").String(verb))
+	span.SetAttributes(attribute.Key("timeStart").Int64(timeStart))
+	span.SetAttributes(attribute.Key("timeEnd").Int64(timeEnd))
+	span.SetAttributes(attribute.Key("limit").Int(limit))
+	span.SetAttributes(attribute.Key("offset").Int(offset))
+	defer span.End()
+
+	events, err := func() ([]AuditEvent, error) {
+		t, err := c.table(ctx, "audit")
+		if err != nil {
+			return nil, err
+		}
+
+		conditions := []string{"TRUE"}
+		var args []any
+
+		add := func(condition string, arg any) {
+			args = append(args, arg)
+			conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+		}
+
+		if user != "" {
+			add("data->>'user' = $%d", user)
+		}
+		if cluster != "" {
+			add("cluster = $%d", cluster)
+		}
+		if namespace != "" {
+			add("data->>'namespace' = $%d", namespace)
+		}
+		if verb != "" {
+			add("data->>'verb' = $%d", verb)
+		}
+		if timeStart > 0 {
+			add("updated_at >= $%d", timeStart)
+		}
+		if timeEnd > 0 {
+			add("updated_at <= $%d", timeEnd)
+		}
+
+		args = append(args, limit, offset)
+
+		return postgresQuery[AuditEvent](ctx, c.db, fmt.Sprintf("SELECT data FROM %s WHERE %s ORDER BY updated_at DESC, id DESC LIMIT NULLIF($%d, 0) OFFSET $%d", t, strings.Join(conditions, " AND "), len(args)-1, len(args)), args...)
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// SaveSyncRun saves a single sync run of the watcher. Sync runs which are older than 7 days are deleted when the
+// indexes are created.
+func (c *postgresClient) SaveSyncRun(ctx context.Context, run *SyncRun) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveSyncRun")
+	span.SetAttributes(attribute.Key("cluster").String(run.Cluster))
+	span.SetAttributes(attribute.Key("resource").String(run.Resource))
+	defer span.End()
+
+	err := func() error {
+		t, err := c.table(ctx, "syncs")
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(run)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.db.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (id, cluster, resource, timestamp, data) VALUES ($1, $2, $3, $4, $5)", t), run.ID, run.Cluster, run.Resource, run.Timestamp, data)
+		return err
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetSyncRuns returns the sync runs for the provided cluster and resource type, sorted by their timestamp, so that the
+// newest sync run is returned first. Empty filters are ignored.
+func (c *postgresClient) GetSyncRuns(ctx context.Context, cluster, resource string, limit int) ([]SyncRun, error) {
+	_, span := c.tracer.Start(ctx, "db.GetSyncRuns")
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	span.SetAttributes(attribute.Key("resource").String(resource))
+	span.SetAttributes(attribute.Key("limit").Int(limit))
+	defer span.End()
+
+	runs, err := func() ([]SyncRun, error) {
+		t, err := c.table(ctx, "syncs")
+		if err != nil {
+			return nil, err
+		}
+
+		conditions := []string{"TRUE"}
+		var args []any
+
+		if cluster != "" {
+			args = append(args, cluster)
+			conditions = append(conditions, fmt.Sprintf("cluster = $%d", len(args)))
+		}
+		if resource != "" {
+			args = append(args, resource)
+			conditions = append(conditions, fmt.Sprintf("resource = $%d", len(args)))
+		}
+
+		args = append(args, limit)
+
+		return postgresQuery[SyncRun](ctx, c.db, fmt.Sprintf("SELECT data FROM %s WHERE %s ORDER BY timestamp DESC LIMIT NULLIF($%d, 0)", t, strings.Join(conditions, " AND "), len(args)), args...)
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// GetLastSyncRuns returns the last sync run for each cluster and resource type, sorted by the cluster and resource.
+func (c *postgresClient) GetLastSyncRuns(ctx context.Context) ([]SyncRun, error) {
+	_, span := c.tracer.Start(ctx, "db.GetLastSyncRuns")
+	defer span.End()
+
+	t, err := c.table(ctx, "syncs")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	runs, err := postgresQuery[SyncRun](ctx, c.db, fmt.Sprintf("SELECT DISTINCT ON (cluster, resource) data FROM %s ORDER BY cluster, resource, timestamp DESC", t))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// SaveApplicationsHealth saves the current health status of all applications. The health of applications which are
+// not part of the provided list is deleted. If the status or the reasons of an application are changed, the new status
+// is also added to the health history. Entries in the health history which are older than 30 days are deleted when
+// the indexes are created.
+func (c *postgresClient) SaveApplicationsHealth(ctx context.Context, health []ApplicationHealth) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveApplicationsHealth")
+	defer span.End()
+
+	err := func() error {
+		t, err := c.table(ctx, "health")
+		if err != nil {
+			return err
+		}
+
+		th, err := c.table(ctx, "healthhistory")
+		if err != nil {
+			return err
+		}
+
+		currentHealth, err := postgresQuery[ApplicationHealth](ctx, c.db, fmt.Sprintf("SELECT data FROM %s", t))
+		if err != nil {
+			return err
+		}
+
+		current := make(map[string]*ApplicationHealth)
+		for i := range currentHealth {
+			current[currentHealth[i].ID] = &currentHealth[i]
+		}
+
+		tx, err := c.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		ids := []string{}
+
+		for _, h := range health {
+			h.ID = h.Application
+			ids = append(ids, h.ID)
+
+			data, err := json.Marshal(h)
+			if err != nil {
+				return err
+			}
+
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (id, status, data) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET status = EXCLUDED.status, data = EXCLUDED.data", t), h.ID, h.Status, data); err != nil {
+				return err
+			}
+
+			if healthChanged(current[h.ID], h) {
+				h.ID = healthHistoryID(h)
+
+				data, err := json.Marshal(h)
+				if err != nil {
+					return err
+				}
+
+				if _, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (id, application, timestamp, data) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO NOTHING", th), h.ID, h.Application, h.Timestamp, data); err != nil {
+					return err
+				}
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE NOT (id = ANY($1))", t), pq.Array(ids)); err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetApplicationsHealth returns the current health status of the applications with the provided ids. If no ids are
+// provided the health status of all applications is returned.
+func (c *postgresClient) GetApplicationsHealth(ctx context.Context, ids []string) ([]ApplicationHealth, error) {
+	_, span := c.tracer.Start(ctx, "db.GetApplicationsHealth")
+	span.SetAttributes(attribute.Key("ids").StringSlice(ids))
+	defer span.End()
+
+	health, err := func() ([]ApplicationHealth, error) {
+		t, err := c.table(ctx, "health")
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ids) > 0 {
+			return postgresQuery[ApplicationHealth](ctx, c.db, fmt.Sprintf("SELECT data FROM %s WHERE id = ANY($1) ORDER BY id COLLATE \"C\"", t), pq.Array(ids))
+		}
+
+		return postgresQuery[ApplicationHealth](ctx, c.db, fmt.Sprintf("SELECT data FROM %s ORDER BY id COLLATE \"C\"", t))
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return health, nil
+}
+
+// GetApplicationHealthHistory returns the health history of the application with the provided id, sorted by the
+// timestamp, so that the newest status is returned first.
+func (c *postgresClient) GetApplicationHealthHistory(ctx context.Context, id string, limit int) ([]ApplicationHealth, error) {
+	_, span := c.tracer.Start(ctx, "db.GetApplicationHealthHistory")
+	span.SetAttributes(attribute.Key("id").String(id))
+	span.SetAttributes(attribute.Key("limit").Int(limit))
+	defer span.End()
+
+	history, err := func() ([]ApplicationHealth, error) {
+		t, err := c.table(ctx, "healthhistory")
+		if err != nil {
+			return nil, err
+		}
+
+		return postgresQuery[ApplicationHealth](ctx, c.db, fmt.Sprintf("SELECT data FROM %s WHERE application = $1 ORDER BY timestamp DESC LIMIT NULLIF($2, 0)", t), id, limit)
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// SaveTerminalRecording saves a single terminal recording. The metadata of the recording is saved in the `data` column
+// and the recording itself in the `content` column, so that the list of recordings can be returned without reading
+// the recordings.
+func (c *postgresClient) SaveTerminalRecording(ctx context.Context, recording *TerminalRecording) error {
+	ctx, span := c.tracer.Start(ctx, "db.SaveTerminalRecording")
+	span.SetAttributes(attribute.Key("id").String(recording.ID))
+	defer span.End()
+
+	err := func() error {
+		t, err := c.table(ctx, "recordings")
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(recording)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.db.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (id, timestamp, data, content) VALUES ($1, $2, $3, $4)", t), recording.ID, recording.Timestamp, data, recording.Data)
+		return err
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetTerminalRecordings returns all terminal recordings which are matching the provided filters, sorted by their
+// timestamp, so that the newest recording is returned first. Empty filters are ignored. The data of the recordings is
+// not returned.
+func (c *postgresClient) GetTerminalRecordings(ctx context.Context, user, cluster, namespace string, limit, offset int) ([]TerminalRecording, error) {
+	_, span := c.tracer.Start(ctx, "db.GetTerminalRecordings")
+	span.SetAttributes(attribute.Key("user").String(user))
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	span.SetAttributes(attribute.Key("namespace").String(namespace))
+	span.SetAttributes(attribute.Key("limit").Int(limit))
+	span.SetAttributes(attribute.Key("offset").Int(offset))
+	defer span.End()
+
+	recordings, err := func() ([]TerminalRecording, error) {
+		t, err := c.table(ctx, "recordings")
+		if err != nil {
+			return nil, err
+		}
+
+		conditions := []string{"TRUE"}
+		var args []any
+
+		add := func(condition string, arg any) {
+			args = append(args, arg)
+			conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+		}
+
+		if user != "" {
+			add("data->>'user' = $%d", user)
+		}
+		if cluster != "" {
+			add("data->>'cluster' = $%d", cluster)
+		}
+		if namespace != "" {
+			add("data->>'namespace' = $%d", namespace)
+		}
+
+		args = append(args, limit, offset)
+
+		return postgresQuery[TerminalRecording](ctx, c.db, fmt.Sprintf("SELECT data FROM %s WHERE %s ORDER BY timestamp DESC, id DESC LIMIT NULLIF($%d, 0) OFFSET $%d", t, strings.Join(conditions, " AND "), len(args)-1, len(args)), args...)
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return recordings, nil
+}
+
+// GetTerminalRecording returns the terminal recording with the provided id, including the data of the recording. If
+// the recording doesn't exist ErrTerminalRecordingNotFound is returned.
+func (c *postgresClient) GetTerminalRecording(ctx context.Context, id string) (*TerminalRecording, error) {
+	_, span := c.tracer.Start(ctx, "db.GetTerminalRecording")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	recording, err := func() (*TerminalRecording, error) {
+		t, err := c.table(ctx, "recordings")
+		if err != nil {
+			return nil, err
+		}
+
+		var data, content []byte
+		if err := c.db.QueryRowContext(ctx, fmt.Sprintf("SELECT data, content FROM %s WHERE id = $1", t), id).Scan(&data, &content); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrTerminalRecordingNotFound
+			}
+			return nil, err
+		}
+
+		var recording TerminalRecording
+		if err := json.Unmarshal(data, &recording); err != nil {
+			return nil, err
+		}
+		recording.Data = content
+
+		return &recording, nil
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return recording, nil
+}
+
+// CreateSession creates a new session for the provided `user`.
+func (c *postgresClient) CreateSession(ctx context.Context, user authContext.User, metadata SessionMetadata) (*Session, error) {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	session := Session{
+		ID:              hex.EncodeToString(id),
+		User:            user,
+		SessionMetadata: metadata,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	ctx, span := c.tracer.Start(ctx, "db.CreateSession")
+	span.SetAttributes(attribute.Key("sessionID").String(session.ID))
+	span.SetAttributes(attribute.Key("userID").String(user.ID))
+	defer span.End()
+
+	err := func() error {
+		t, err := c.table(ctx, "sessions")
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(user)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.db.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (id, \"user\", user_agent, ip, created_at, updated_at, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7)", t), session.ID, data, session.UserAgent, session.IP, session.CreatedAt, session.UpdatedAt, session.ExpiresAt)
+		return err
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// postgresSessionColumns are the columns which must be selected to scan a session via scanSession.
+const postgresSessionColumns = "id, \"user\", user_agent, ip, created_at, updated_at, expires_at"
+
+// postgresScanner is implemented by sql.Row and sql.Rows, so that scanSession can be used for both.
+type postgresScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanSession returns the session from the provided row. If the row is empty ErrSessionNotFound is returned.
+func scanSession(row postgresScanner) (*Session, error) {
+	var session Session
+	var user []byte
+
+	if err := row.Scan(&session.ID, &user, &session.UserAgent, &session.IP, &session.CreatedAt, &session.UpdatedAt, &session.ExpiresAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(user, &session.User); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// GetSession returns the session with the provided session id. Sessions which were not used within the session TTL
+// are ignored, because PostgreSQL doesn't delete them automatically.
+func (c *postgresClient) GetSession(ctx context.Context, sessionID string) (*Session, error) {
+	ctx, span := c.tracer.Start(ctx, "db.GetSession")
+	span.SetAttributes(attribute.Key("sessionID").String(sessionID))
+	defer span.End()
+
+	t, err := c.table(ctx, "sessions")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	session, err := scanSession(c.db.QueryRowContext(ctx, fmt.Sprintf("SELECT %s FROM %s WHERE id = $1 AND updated_at >= $2 AND expires_at > $3", postgresSessionColumns, t), sessionID, time.Now().Add(-sessionTTL), time.Now()))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// GetSessions returns all active sessions of the user with the provided id. The sessions are sorted by the time they
+// were used the last time, so that the most recently used session is returned first.
+func (c *postgresClient) GetSessions(ctx context.Context, userID string) ([]Session, error) {
+	ctx, span := c.tracer.Start(ctx, "db.GetSessions")
+	span.SetAttributes(attribute.Key("userID").String(userID))
+	defer span.End()
+
+	sessions, err := func() ([]Session, error) {
+		t, err := c.table(ctx, "sessions")
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		rows, err := c.db.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s WHERE \"user\"->>'id' = $1 AND updated_at >= $2 AND expires_at > $3 ORDER BY updated_at DESC", postgresSessionColumns, t), userID, now.Add(-sessionTTL), now)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		var sessions []Session
+		for rows.Next() {
+			session, err := scanSession(rows)
+			if err != nil {
+				return nil, err
+			}
+			sessions = append(sessions, *session)
+		}
+
+		return sessions, rows.Err()
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// GetAndUpdateSession returns the session for the provided `sessionID` and updates the `updatedAt` field of the session
+// to the current time, so that we know when the session was used the last time.
+func (c *postgresClient) GetAndUpdateSession(ctx context.Context, sessionID string) (*Session, error) {
+	ctx, span := c.tracer.Start(ctx, "db.UpdateSession")
+	span.SetAttributes(attribute.Key("sessionID").String(sessionID))
+	defer span.End()
+
+	t, err := c.table(ctx, "sessions")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	now := time.Now()
+	session, err := scanSession(c.db.QueryRowContext(ctx, fmt.Sprintf("UPDATE %s SET updated_at = $1 WHERE id = $2 AND updated_at >= $3 AND expires_at > $4 RETURNING %s", t, postgresSessionColumns), now.UTC(), sessionID, now.Add(-sessionTTL), now))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// DeleteSession deletes an existing user session, so that the session can not be used anymore to make requests against
+// our API which requires an authenticated user.
+func (c *postgresClient) DeleteSession(ctx context.Context, sessionID string) error {
+	ctx, span := c.tracer.Start(ctx, "db.DeleteSession")
+	span.SetAttributes(attribute.Key("sessionID").String(sessionID))
+	defer span.End()
+
+	t, err := c.table(ctx, "sessions")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	res, err := c.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = $1", t), sessionID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	if deleted, err := res.RowsAffected(); err != nil || deleted != 1 {
+		span.RecordError(ErrSessionNotFound)
+		span.SetStatus(codes.Error, ErrSessionNotFound.Error())
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// DeleteSessions deletes all sessions of the user with the provided id, so that the user must sign in again on all
+// devices.
+func (c *postgresClient) DeleteSessions(ctx context.Context, userID string) error {
+	ctx, span := c.tracer.Start(ctx, "db.DeleteSessions")
+	span.SetAttributes(attribute.Key("userID").String(userID))
+	defer span.End()
+
+	t, err := c.table(ctx, "sessions")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	_, err = c.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE \"user\"->>'id' = $1", t), userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// CreateAPIToken saves the provided personal API token.
+func (c *postgresClient) CreateAPIToken(ctx context.Context, token *APIToken) error {
+	ctx, span := c.tracer.Start(ctx, "db.CreateAPIToken")
+	span.SetAttributes(attribute.Key("id").String(token.ID))
+	span.SetAttributes(attribute.Key("userID").String(token.User.ID))
+	defer span.End()
+
+	err := func() error {
+		t, err := c.table(ctx, "tokens")
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(token)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.db.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (id, user_id, created_at, expires_at, data) VALUES ($1, $2, $3, $4, $5)", t), token.ID, token.User.ID, token.CreatedAt, token.ExpiresAt, data)
+		return err
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetAPIToken returns the personal API token with the provided id. If the token doesn't exist or if it is already
+// expired ErrAPITokenNotFound is returned.
+func (c *postgresClient) GetAPIToken(ctx context.Context, id string) (*APIToken, error) {
+	ctx, span := c.tracer.Start(ctx, "db.GetAPIToken")
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	token, err := func() (*APIToken, error) {
+		t, err := c.table(ctx, "tokens")
+		if err != nil {
+			return nil, err
+		}
+
+		token, err := postgresQueryOne[APIToken](ctx, c.db, fmt.Sprintf("SELECT data FROM %s WHERE id = $1 AND expires_at > $2", t), id, time.Now())
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrAPITokenNotFound
+			}
+			return nil, err
+		}
+
+		return token, nil
+	}()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// GetAPITokens returns all personal API tokens of the user with the provided id, which are not expired.
+func (c *postgresClient) GetAPITokens(ctx context.Context, userID string) ([]APIToken, error) {
+	ctx, span := c.tracer.Start(ctx, "db.GetAPITokens")
+	span.SetAttributes(attribute.Key("userID").String(userID))
+	defer span.End()
+
+	t, err := c.table(ctx, "tokens")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	tokens, err := postgresQuery[APIToken](ctx, c.db, fmt.Sprintf("SELECT data FROM %s WHERE user_id = $1 AND expires_at > $2 ORDER BY created_at", t), userID, time.Now())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// DeleteAPIToken deletes the personal API token with the provided id. A token can only be deleted by the user who
+// created it, so that ErrAPITokenNotFound is returned when the token doesn't belong to the provided user.
+func (c *postgresClient) DeleteAPIToken(ctx context.Context, userID, id string) error {
+	ctx, span := c.tracer.Start(ctx, "db.DeleteAPIToken")
+	span.SetAttributes(attribute.Key("userID").String(userID))
+	span.SetAttributes(attribute.Key("id").String(id))
+	defer span.End()
+
+	t, err := c.table(ctx, "tokens")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	res, err := c.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND user_id = $2", t), id, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	if deleted, err := res.RowsAffected(); err != nil || deleted != 1 {
+		span.RecordError(ErrAPITokenNotFound)
+		span.SetStatus(codes.Error, ErrAPITokenNotFound.Error())
+		return ErrAPITokenNotFound
+	}
+
+	return nil
+}
+
+// getAll returns all rows of the provided table ordered by their id.
+func getAll[T any](ctx context.Context, c *postgresClient, table string) ([]T, error) {
+	t, err := c.table(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	return postgresQuery[T](ctx, c.db, fmt.Sprintf("SELECT data FROM %s ORDER BY id COLLATE \"C\"", t))
+}
+
+// getByID returns the row with the provided id from the provided table. If the row doesn't exist sql.ErrNoRows is
+// returned.
+func getByID[T any](ctx context.Context, c *postgresClient, table, id string) (*T, error) {
+	t, err := c.table(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	return postgresQueryOne[T](ctx, c.db, fmt.Sprintf("SELECT data FROM %s WHERE id = $1", t), id)
+}
+
+// AcquireLease acquires or renews the lease with the provided name for the provided holder. The lease is only acquired
+// when it does not exist yet, when it is already held by the holder or when it is expired. The returned lease always
+// contains the current holder of the lease, so that the caller can check if it is the leader.
+func (c *postgresClient) AcquireLease(ctx context.Context, name, holder string, duration time.Duration) (*Lease, error) {
+	ctx, span := c.tracer.Start(ctx, "db.AcquireLease")
+	span.SetAttributes(attribute.Key("name").String(name))
+	span.SetAttributes(attribute.Key("holder").String(holder))
+	defer span.End()
+
+	t, err := c.table(ctx, "leases")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	_, err = c.db.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %[1]s (name, holder, acquired_at, renewed_at, expires_at) VALUES ($1, $2, $3, $3, $4)
+		ON CONFLICT (name) DO UPDATE SET
+			holder = EXCLUDED.holder,
+			acquired_at = CASE WHEN %[1]s.holder = EXCLUDED.holder THEN %[1]s.acquired_at ELSE EXCLUDED.acquired_at END,
+			renewed_at = EXCLUDED.renewed_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE %[1]s.holder = EXCLUDED.holder OR %[1]s.expires_at <= EXCLUDED.renewed_at`, t), name, holder, now, now.Add(duration))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return c.GetLease(ctx, name)
+}
+
+// ReleaseLease releases the lease with the provided name, when it is held by the provided holder. The lease is not
+// deleted, instead it is marked as expired, so that another holder can acquire the lease immediately.
+func (c *postgresClient) ReleaseLease(ctx context.Context, name, holder string) error {
+	ctx, span := c.tracer.Start(ctx, "db.ReleaseLease")
+	span.SetAttributes(attribute.Key("name").String(name))
+	span.SetAttributes(attribute.Key("holder").String(holder))
+	defer span.End()
+
+	t, err := c.table(ctx, "leases")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	_, err = c.db.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET expires_at = $1 WHERE name = $2 AND holder = $3", t), time.Now().UTC(), name, holder)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetLease returns the lease with the provided name. If the lease does not exist ErrLeaseNotFound is returned.
+func (c *postgresClient) GetLease(ctx context.Context, name string) (*Lease, error) {
+	ctx, span := c.tracer.Start(ctx, "db.GetLease")
+	span.SetAttributes(attribute.Key("name").String(name))
+	defer span.End()
+
+	t, err := c.table(ctx, "leases")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	var lease Lease
+
+	err = c.db.QueryRowContext(ctx, fmt.Sprintf("SELECT name, holder, acquired_at, renewed_at, expires_at FROM %s WHERE name = $1", t), name).Scan(&lease.Name, &lease.Holder, &lease.AcquiredAt, &lease.RenewedAt, &lease.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLeaseNotFound
+		}
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	lease.AcquiredAt = lease.AcquiredAt.UTC()
+	lease.RenewedAt = lease.RenewedAt.UTC()
+	lease.ExpiresAt = lease.ExpiresAt.UTC()
+
+	return &lease, nil
+}
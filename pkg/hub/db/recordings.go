@@ -0,0 +1,34 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrTerminalRecordingNotFound is our custom error which is returned when we are not able to find a terminal
+	// recording with the provided id.
+	ErrTerminalRecordingNotFound = fmt.Errorf("terminal recording not found")
+)
+
+// TerminalRecording is the recording of a terminal session in a container, which was started by a user through the
+// hub. The `data` field contains the recording in the asciicast v2 format. It is only returned when a single recording
+// is requested, so that the list of recordings can be returned without loading all recordings.
+//
+// The `duration` is the duration of the session in milliseconds and the `size` is the size of the recording in bytes.
+// When the recording exceeds the configured maximum size, the remaining output of the session is not recorded and
+// `truncated` is true.
+type TerminalRecording struct {
+	ID        string    `json:"id" bson:"_id"`
+	User      string    `json:"user" bson:"user"`
+	Cluster   string    `json:"cluster" bson:"cluster"`
+	Namespace string    `json:"namespace" bson:"namespace"`
+	Pod       string    `json:"pod" bson:"pod"`
+	Container string    `json:"container" bson:"container"`
+	Shell     string    `json:"shell" bson:"shell"`
+	Timestamp time.Time `json:"timestamp" bson:"timestamp"`
+	Duration  int64     `json:"duration" bson:"duration"`
+	Size      int       `json:"size" bson:"size"`
+	Truncated bool      `json:"truncated" bson:"truncated"`
+	Data      []byte    `json:"-" bson:"data,omitempty"`
+}
@@ -0,0 +1,29 @@
+package db
+
+import (
+	"time"
+)
+
+const (
+	// syncRunTTL is the time after which a sync run is deleted from the database.
+	syncRunTTL = 168 * time.Hour
+)
+
+// SyncRun is a single sync of a resource type (e.g. "applications") from a cluster, which was run by the watcher. The
+// `duration` is the duration of the sync in milliseconds and the `count` is the number of synced items. If the sync
+// failed, the `error` field contains the error message.
+//
+// The `failures` field contains the number of consecutive failed syncs for the cluster and resource type, including the
+// current sync. When the number of consecutive failures reaches the configured threshold of the watcher, the sync run
+// is marked as unhealthy, so that the data for the resource type can be flagged as stale.
+type SyncRun struct {
+	ID        string    `json:"id" bson:"_id"`
+	Cluster   string    `json:"cluster" bson:"cluster"`
+	Resource  string    `json:"resource" bson:"resource"`
+	Timestamp time.Time `json:"timestamp" bson:"timestamp"`
+	Duration  int64     `json:"duration" bson:"duration"`
+	Count     int       `json:"count" bson:"count"`
+	Error     string    `json:"error,omitempty" bson:"error"`
+	Failures  int       `json:"failures" bson:"failures"`
+	Healthy   bool      `json:"healthy" bson:"healthy"`
+}
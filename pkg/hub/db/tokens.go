@@ -0,0 +1,26 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+)
+
+var (
+	// ErrAPITokenNotFound is our custom error which is returned when we are not able to find a personal API token with
+	// the provided id or when the token is already expired.
+	ErrAPITokenNotFound = fmt.Errorf("api token not found")
+)
+
+// APIToken is the structure of a personal API token as it is saved in the database. The token itself is never saved,
+// instead we save the SHA-256 hash of the token in the `hash` field. The `user` field contains the user which created
+// the token, including the permissions the token is scoped to.
+type APIToken struct {
+	ID        string           `json:"id" bson:"_id"`
+	Name      string           `json:"name" bson:"name"`
+	Hash      string           `json:"hash" bson:"hash"`
+	User      authContext.User `json:"user" bson:"user"`
+	CreatedAt time.Time        `json:"createdAt" bson:"createdAt"`
+	ExpiresAt time.Time        `json:"expiresAt" bson:"expiresAt"`
+}
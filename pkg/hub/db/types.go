@@ -1,6 +1,8 @@
 package db
 
 import (
+	"fmt"
+
 	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
 )
 
@@ -32,6 +34,33 @@ type Topology struct {
 	UpdatedAt           int64  `json:"updatedAt" bson:"updatedAt"`
 }
 
+// topologyForApplication returns the topology edges for all dependencies of the provided application.
+func topologyForApplication(application applicationv1.ApplicationSpec, updatedAt int64) []Topology {
+	var topology []Topology
+	sourceID := fmt.Sprintf("/cluster/%s/namespace/%s/name/%s", application.Cluster, application.Namespace, application.Name)
+
+	for _, dependency := range application.Topology.Dependencies {
+		targetID := fmt.Sprintf("/cluster/%s/namespace/%s/name/%s", dependency.Cluster, dependency.Namespace, dependency.Name)
+
+		topology = append(topology, Topology{
+			ID:                  fmt.Sprintf("%s---%s", sourceID, targetID),
+			SourceID:            sourceID,
+			SourceCluster:       application.Cluster,
+			SourceNamespace:     application.Namespace,
+			SourceName:          application.Name,
+			TargetID:            targetID,
+			TargetCluster:       dependency.Cluster,
+			TargetNamespace:     dependency.Namespace,
+			TargetName:          dependency.Name,
+			TopologyExternal:    application.Topology.External,
+			TopologyDescription: dependency.Description,
+			UpdatedAt:           updatedAt,
+		})
+	}
+
+	return topology
+}
+
 type ApplicationGroup struct {
 	ID          ApplicationGroupID     `json:"id" bson:"_id"`
 	Clusters    []string               `json:"clusters,omitempty" bson:"clusters"`
@@ -48,3 +77,41 @@ type ApplicationGroupID struct {
 	Namespace string `json:"namespace,omitempty" bson:"namespace"`
 	Name      string `json:"name,omitempty" bson:"name"`
 }
+
+// Document is a single document in a collection which is owned by a plugin, e.g. the runbooks of the runbooks plugin.
+// The data of the document is saved as it is, so that the same struct can be used to read the documents via the
+// GetDocuments method. The struct must use the same field names for the json and bson tags, so that the documents can
+// be filtered in all database backends.
+type Document struct {
+	ID   string
+	Data any
+}
+
+// DocumentQuery is used to filter the documents of a plugin collection. The ID and the Fields are matched exactly,
+// while the Search term is matched case insensitive against all SearchFields. Empty fields are ignored.
+type DocumentQuery struct {
+	ID           string
+	Fields       map[string]string
+	Search       string
+	SearchFields []string
+}
+
+// AuditEvent is a single mutating request, which was made by a user through the hub. The `timestamp` is the time in
+// milliseconds when the request was received. The `bodyDigest` is the SHA-256 hash of the request body, so that we can
+// verify which changes were made by the user, without storing confidential data from the request body.
+type AuditEvent struct {
+	ID         string `json:"id" bson:"_id"`
+	Timestamp  int64  `json:"timestamp" bson:"timestamp"`
+	User       string `json:"user" bson:"user"`
+	Cluster    string `json:"cluster" bson:"cluster"`
+	Namespace  string `json:"namespace" bson:"namespace"`
+	Resource   string `json:"resource" bson:"resource"`
+	Name       string `json:"name" bson:"name"`
+	Plugin     string `json:"plugin" bson:"plugin"`
+	Verb       string `json:"verb" bson:"verb"`
+	Method     string `json:"method" bson:"method"`
+	Path       string `json:"path" bson:"path"`
+	BodyDigest string `json:"bodyDigest" bson:"bodyDigest"`
+	StatusCode int    `json:"statusCode" bson:"statusCode"`
+	Result     string `json:"result" bson:"result"`
+}
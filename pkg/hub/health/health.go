@@ -0,0 +1,296 @@
+package health
+
+//go:generate mockgen -source=health.go -destination=./health_mock.go -package=health Client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/clusters"
+	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/hub/plugins"
+	"github.com/kobsio/kobs/pkg/instrument/log"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// LeaseName is the name of the lease, which is used when the leader election is enabled, so that only one replica of
+// the hub computes the health of the applications.
+const LeaseName = "health"
+
+var applicationsMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "kobs",
+	Name:      "hub_applications_health",
+	Help:      "Number of applications, partitioned by their health status.",
+}, []string{"status"})
+
+type Config struct {
+	Enabled        bool          `json:"enabled" env:"ENABLED" default:"false" help:"Compute the health status of all applications in the configured interval."`
+	Interval       time.Duration `json:"interval" env:"INTERVAL" default:"60s" help:"The interval in which the health status of all applications is computed."`
+	Workers        int           `json:"workers" env:"WORKERS" default:"10" help:"The number of applications for which the health status is computed in parallel."`
+	Timeout        time.Duration `json:"timeout" env:"TIMEOUT" default:"30s" help:"The maximum time to compute the health status of a single application."`
+	Window         time.Duration `json:"window" env:"WINDOW" default:"15m" help:"The time range which is used to get the values of the insights of an application."`
+	WorkloadLabel  string        `json:"workloadLabel" env:"WORKLOAD_LABEL" default:"app.kubernetes.io/name" help:"The label which is used to select the Deployments, StatefulSets and DaemonSets of an application. The value of the label must be the name of the application."`
+	LeaderElection bool          `json:"leaderElection" env:"LEADER_ELECTION" default:"false" help:"Enable the leader election, so that only one replica of the hub computes the health status of the applications."`
+}
+
+// Client is the interface which must be implemented by a health client.
+type Client interface {
+	Watch()
+	Stop() error
+}
+
+// client implements the Client interface. It computes the health status of all applications in the configured interval
+// and saves them in the database. The health of an application is computed from the insights of the application,
+// which define health thresholds, and from the readiness of the workloads of the application.
+//
+// The values of the insights are requested via the plugin routes of the hub, so that the health computation works the
+// same way as the insights which are shown in the frontend, regardless if the plugin is running in the hub or in a
+// cluster. The workloads are requested via the resources API of the clusters.
+type client struct {
+	config         Config
+	clustersClient clusters.Client
+	dbClient       db.Client
+	plugins        http.Handler
+	tracer         trace.Tracer
+	identity       string
+	ctx            context.Context
+	cancel         context.CancelFunc
+}
+
+// Watch computes the health of all applications in the configured interval, until the client is stopped. This should
+// be called in a new go routine.
+func (c *client) Watch() {
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if c.isLeader() {
+			c.evaluate()
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops the computation of the health and releases the lease, when the leader election is enabled, so that
+// another replica of the hub can take over immediately.
+func (c *client) Stop() error {
+	c.cancel()
+
+	if c.config.LeaderElection {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		return c.dbClient.ReleaseLease(ctx, LeaseName, c.identity)
+	}
+
+	return nil
+}
+
+// isLeader returns true when the leader election is disabled or when this replica acquired the lease. Since the health
+// is computed only once per interval, we do not run a separate leader election loop like the watcher. Instead the lease
+// is acquired for two intervals before each run, so that the leader keeps the lease as long as it is running.
+func (c *client) isLeader() bool {
+	if !c.config.LeaderElection {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
+	defer cancel()
+
+	lease, err := c.dbClient.AcquireLease(ctx, LeaseName, c.identity, 2*c.config.Interval)
+	if err != nil {
+		log.Warn(ctx, "Failed to acquire lease", zap.Error(err), zap.String("identity", c.identity))
+		return false
+	}
+
+	return lease.Holder == c.identity
+}
+
+// evaluate computes the health of all applications and saves them in the database. The health of the applications is
+// computed in parallel by the configured number of workers.
+func (c *client) evaluate() {
+	ctx, span := c.tracer.Start(c.ctx, "health.evaluate")
+	defer span.End()
+
+	startTime := time.Now()
+
+	applications, err := c.dbClient.GetApplications(ctx)
+	if err != nil {
+		log.Error(ctx, "Failed to get applications", zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+
+	health := make([]db.ApplicationHealth, len(applications))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < max(c.config.Workers, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				health[i] = c.evaluateApplication(ctx, applications[i])
+			}
+		}()
+	}
+
+	for i := range applications {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := c.dbClient.SaveApplicationsHealth(ctx, health); err != nil {
+		log.Error(ctx, "Failed to save health", zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+
+	counts := map[string]float64{db.HealthStatusHealthy: 0, db.HealthStatusDegraded: 0, db.HealthStatusUnhealthy: 0, db.HealthStatusUnknown: 0}
+	for _, h := range health {
+		counts[h.Status]++
+	}
+	for status, count := range counts {
+		applicationsMetric.WithLabelValues(status).Set(count)
+	}
+
+	log.Debug(ctx, "Health was computed", zap.Int("count", len(health)), zap.Duration("duration", time.Since(startTime)))
+}
+
+// evaluateApplication computes the health of a single application. The status of the application is the worst status
+// of all checks. If an application doesn't have any checks, because no insight defines health thresholds and no
+// workloads were found, the status is unknown.
+func (c *client) evaluateApplication(ctx context.Context, application applicationv1.ApplicationSpec) db.ApplicationHealth {
+	ctx, span := c.tracer.Start(ctx, "health.evaluateApplication")
+	span.SetAttributes(attribute.Key("application").String(application.ID))
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	// The user is also added to the context for the requests against the clusters, so that the workloads are
+	// requested with the id of the user and the teams of the application when the impersonation is enabled.
+	user := c.getApplicationUser(ctx, application)
+	ctx = context.WithValue(ctx, authContext.UserKey, user)
+
+	var reasons []db.ApplicationHealthReason
+	reasons = append(reasons, c.checkInsights(ctx, application, user)...)
+	reasons = append(reasons, c.checkWorkloads(ctx, application)...)
+
+	status := db.HealthStatusUnknown
+	if len(reasons) > 0 {
+		status = db.HealthStatusHealthy
+		for _, reason := range reasons {
+			if severity(reason.Status) > severity(status) {
+				status = reason.Status
+			}
+		}
+	}
+
+	span.SetAttributes(attribute.Key("status").String(status))
+
+	return db.ApplicationHealth{
+		Application: application.ID,
+		Status:      status,
+		Reasons:     reasons,
+		Timestamp:   time.Now(),
+	}
+}
+
+// getApplicationUser returns the user which is used to get the values of the insights of the provided application via
+// the plugin routes of the hub and to get the workloads of the application. The user has the plugin permissions of the
+// teams which own the application, so that an application author can not use an insight to read data from a plugin,
+// which the teams of the application can not access. If the teams can not be returned, the user doesn't have any
+// permissions.
+func (c *client) getApplicationUser(ctx context.Context, application applicationv1.ApplicationSpec) authContext.User {
+	user := authContext.User{ID: "kobs-health", Name: "kobs health", Teams: application.Teams}
+
+	if len(application.Teams) == 0 {
+		return user
+	}
+
+	teams, err := c.dbClient.GetTeamsByIDs(ctx, application.Teams, "")
+	if err != nil {
+		log.Warn(ctx, "Failed to get teams of application", zap.Error(err), zap.String("application", application.ID))
+		return user
+	}
+
+	for _, team := range teams {
+		user.Permissions.Plugins = append(user.Permissions.Plugins, team.Permissions.Plugins...)
+	}
+
+	return user
+}
+
+// severity returns the severity of the provided status, which is used to get the worst status of all checks.
+func severity(status string) int {
+	switch status {
+	case db.HealthStatusUnhealthy:
+		return 3
+	case db.HealthStatusDegraded:
+		return 2
+	case db.HealthStatusUnknown:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// newIdentity returns the identity of the hub replica for the leader election. The identity is the hostname (e.g. the
+// name of the Pod) with a random suffix, so that two hubs on the same host are getting a different identity.
+func newIdentity() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "hub"
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return hostname
+	}
+
+	return hostname + "-" + hex.EncodeToString(suffix)
+}
+
+// NewClient returns a new health client. The plugin routes of the hub are mounted at the same path as in the API
+// server, so that the insights can be requested with the same path as in the frontend.
+func NewClient(config Config, clustersClient clusters.Client, dbClient db.Client, pluginsClient plugins.Client) Client {
+	router := chi.NewRouter()
+	router.Mount("/api/plugins", pluginsClient.Mount())
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &client{
+		config:         config,
+		clustersClient: clustersClient,
+		dbClient:       dbClient,
+		plugins:        router,
+		tracer:         otel.Tracer("health"),
+		identity:       newIdentity(),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
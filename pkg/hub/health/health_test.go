@@ -0,0 +1,305 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
+	dashboardv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/dashboard/v1"
+	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/clusters"
+	"github.com/kobsio/kobs/pkg/hub/clusters/cluster"
+	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/hub/plugins"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func newTestClient(t *testing.T, insights http.HandlerFunc) (*client, *clusters.MockClient, *cluster.MockClient, *db.MockClient) {
+	ctrl := gomock.NewController(t)
+	clustersClient := clusters.NewMockClient(ctrl)
+	clusterClient := cluster.NewMockClient(ctrl)
+	dbClient := db.NewMockClient(ctrl)
+
+	router := chi.NewRouter()
+	if insights != nil {
+		router.Post("/api/plugins/prometheus/insight", insights)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	return &client{
+		config:         Config{Interval: time.Minute, Workers: 2, Timeout: 10 * time.Second, Window: 15 * time.Minute, WorkloadLabel: "app.kubernetes.io/name"},
+		clustersClient: clustersClient,
+		dbClient:       dbClient,
+		plugins:        router,
+		tracer:         otel.Tracer("health"),
+		identity:       "hub1",
+		ctx:            ctx,
+		cancel:         cancel,
+	}, clustersClient, clusterClient, dbClient
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Run("should not save health when applications can not be returned", func(t *testing.T) {
+		c, _, _, dbClient := newTestClient(t, nil)
+		dbClient.EXPECT().GetApplications(gomock.Any()).Return(nil, fmt.Errorf("unexpected error"))
+
+		c.evaluate()
+	})
+
+	t.Run("should save health of all applications", func(t *testing.T) {
+		c, clustersClient, clusterClient, dbClient := newTestClient(t, nil)
+		dbClient.EXPECT().GetApplications(gomock.Any()).Return([]applicationv1.ApplicationSpec{
+			{ID: "application1", Cluster: "cluster1", Namespace: "default", Name: "application1"},
+			{ID: "application2", Cluster: "cluster2", Namespace: "default", Name: "application2"},
+		}, nil)
+		clustersClient.EXPECT().GetCluster("cluster1").Return(clusterClient)
+		clustersClient.EXPECT().GetCluster("cluster2").Return(nil)
+		clusterClient.EXPECT().Request(gomock.Any(), http.MethodGet, "/api/resources?namespace=default&resource=deployments&path=/apis/apps/v1&paramName=labelSelector&param=app.kubernetes.io%2Fname%3Dapplication1", nil).Return(map[string]any{"items": []any{map[string]any{"metadata": map[string]any{"name": "application1"}, "spec": map[string]any{"replicas": 2}, "status": map[string]any{"readyReplicas": 2}}}}, nil)
+		clusterClient.EXPECT().Request(gomock.Any(), http.MethodGet, gomock.Any(), nil).Return(map[string]any{"items": []any{}}, nil).Times(2)
+		dbClient.EXPECT().SaveApplicationsHealth(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, health []db.ApplicationHealth) error {
+			require.Len(t, health, 2)
+			require.Equal(t, "application1", health[0].Application)
+			require.Equal(t, db.HealthStatusHealthy, health[0].Status)
+			require.Equal(t, []db.ApplicationHealthReason{{Type: "workload", Name: "Deployment/application1", Status: db.HealthStatusHealthy, Message: "2 of 2 replicas are ready"}}, health[0].Reasons)
+			require.Equal(t, "application2", health[1].Application)
+			require.Equal(t, db.HealthStatusUnknown, health[1].Status)
+			return nil
+		})
+
+		c.evaluate()
+	})
+}
+
+func TestEvaluateApplication(t *testing.T) {
+	application := applicationv1.ApplicationSpec{
+		ID:        "application1",
+		Cluster:   "cluster1",
+		Namespace: "default",
+		Name:      "application1",
+		Teams:     []string{"team1"},
+		Insights: []applicationv1.Insight{
+			{Title: "Requests", Plugin: dashboardv1.Plugin{Cluster: "hub", Type: "prometheus", Name: "prometheus"}},
+			{Title: "Success Rate", Unit: "%", Plugin: dashboardv1.Plugin{Cluster: "hub", Type: "prometheus", Name: "prometheus"}, Health: &applicationv1.InsightHealth{Operator: "<", Degraded: "99", Unhealthy: "95"}},
+		},
+	}
+
+	for _, tt := range []struct {
+		name            string
+		insights        http.HandlerFunc
+		workloads       map[string]any
+		expectedStatus  string
+		expectedReasons []db.ApplicationHealthReason
+	}{
+		{
+			name: "should return healthy application",
+			insights: func(w http.ResponseWriter, r *http.Request) {
+				render.JSON(w, r, []map[string]any{{"x": 1, "y": 97}, {"x": 2, "y": 99.5}, {"x": 3, "y": nil}})
+			},
+			workloads:      map[string]any{"items": []any{}},
+			expectedStatus: db.HealthStatusHealthy,
+			expectedReasons: []db.ApplicationHealthReason{
+				{Type: "insight", Name: "Success Rate", Status: db.HealthStatusHealthy, Message: "The last value is within the thresholds"},
+			},
+		},
+		{
+			name: "should return worst status of insights and workloads",
+			insights: func(w http.ResponseWriter, r *http.Request) {
+				render.JSON(w, r, []map[string]any{{"x": 1, "y": 97}})
+			},
+			workloads:      map[string]any{"items": []any{map[string]any{"metadata": map[string]any{"name": "application1"}, "spec": map[string]any{"replicas": 3}, "status": map[string]any{"readyReplicas": 0}}}},
+			expectedStatus: db.HealthStatusUnhealthy,
+			expectedReasons: []db.ApplicationHealthReason{
+				{Type: "insight", Name: "Success Rate", Status: db.HealthStatusDegraded, Message: "The last value exceeds the degraded threshold"},
+				{Type: "workload", Name: "Deployment/application1", Status: db.HealthStatusUnhealthy, Message: "0 of 3 replicas are ready"},
+			},
+		},
+		{
+			name: "should return unknown status for insight, when plugin returns an error",
+			insights: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				render.JSON(w, r, map[string]any{"errors": []string{"Invalid instance name"}})
+			},
+			workloads:      map[string]any{"items": []any{}},
+			expectedStatus: db.HealthStatusUnknown,
+			expectedReasons: []db.ApplicationHealthReason{
+				{Type: "insight", Name: "Success Rate", Status: db.HealthStatusUnknown, Message: "Failed to get insight data"},
+			},
+		},
+		{
+			name: "should get insight with plugin permissions of the teams",
+			insights: func(w http.ResponseWriter, r *http.Request) {
+				user := authContext.MustGetUser(r.Context())
+				if !user.HasPluginAccess(r.Header.Get("x-kobs-cluster"), "prometheus", r.Header.Get("x-kobs-plugin")) || user.HasPluginAccess("hub", "prometheus", "other") {
+					w.WriteHeader(http.StatusForbidden)
+					render.JSON(w, r, map[string]any{"errors": []string{"You are not allowed to access the plugin"}})
+					return
+				}
+				render.JSON(w, r, []map[string]any{{"x": 1, "y": 100}})
+			},
+			workloads:      map[string]any{"items": []any{}},
+			expectedStatus: db.HealthStatusHealthy,
+			expectedReasons: []db.ApplicationHealthReason{
+				{Type: "insight", Name: "Success Rate", Status: db.HealthStatusHealthy, Message: "The last value is within the thresholds"},
+			},
+		},
+		{
+			name: "should return unknown status for insight without data",
+			insights: func(w http.ResponseWriter, r *http.Request) {
+				render.JSON(w, r, []map[string]any{{"x": 1, "y": nil}})
+			},
+			workloads:      map[string]any{"items": []any{map[string]any{"metadata": map[string]any{"name": "application1"}, "status": map[string]any{"readyReplicas": 1}}}},
+			expectedStatus: db.HealthStatusUnknown,
+			expectedReasons: []db.ApplicationHealthReason{
+				{Type: "insight", Name: "Success Rate", Status: db.HealthStatusUnknown, Message: "No data was found"},
+				{Type: "workload", Name: "Deployment/application1", Status: db.HealthStatusHealthy, Message: "1 of 1 replicas are ready"},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c, clustersClient, clusterClient, dbClient := newTestClient(t, tt.insights)
+			dbClient.EXPECT().GetTeamsByIDs(gomock.Any(), []string{"team1"}, "").Return([]teamv1.TeamSpec{{ID: "team1", Permissions: userv1.Permissions{Plugins: []userv1.Plugin{{Cluster: "hub", Type: "prometheus", Name: "prometheus"}}}}}, nil)
+			clustersClient.EXPECT().GetCluster("cluster1").Return(clusterClient)
+			clusterClient.EXPECT().Request(gomock.Any(), http.MethodGet, gomock.Any(), nil).DoAndReturn(func(ctx context.Context, method, url string, body io.Reader) (map[string]any, error) {
+				if url == "/api/resources?namespace=default&resource=deployments&path=/apis/apps/v1&paramName=labelSelector&param=app.kubernetes.io%2Fname%3Dapplication1" {
+					return tt.workloads, nil
+				}
+				return map[string]any{"items": []any{}}, nil
+			}).Times(3)
+
+			health := c.evaluateApplication(context.Background(), application)
+			require.Equal(t, "application1", health.Application)
+			require.Equal(t, tt.expectedStatus, health.Status)
+			require.Equal(t, tt.expectedReasons, health.Reasons)
+		})
+	}
+
+	t.Run("should return unknown status for workloads, when request fails", func(t *testing.T) {
+		c, clustersClient, clusterClient, _ := newTestClient(t, nil)
+		clustersClient.EXPECT().GetCluster("cluster1").Return(clusterClient)
+		clusterClient.EXPECT().Request(gomock.Any(), http.MethodGet, gomock.Any(), nil).DoAndReturn(func(ctx context.Context, method, url string, body io.Reader) (map[string]any, error) {
+			require.Equal(t, "kobs-health", authContext.MustGetUser(ctx).ID)
+			return nil, fmt.Errorf("unexpected error")
+		}).Times(3)
+
+		health := c.evaluateApplication(context.Background(), applicationv1.ApplicationSpec{ID: "application1", Cluster: "cluster1", Namespace: "default", Name: "application1"})
+		require.Equal(t, db.HealthStatusUnknown, health.Status)
+		require.Equal(t, []db.ApplicationHealthReason{
+			{Type: "workload", Name: "Deployment", Status: db.HealthStatusUnknown, Message: "Failed to get deployments: unexpected error"},
+			{Type: "workload", Name: "StatefulSet", Status: db.HealthStatusUnknown, Message: "Failed to get statefulsets: unexpected error"},
+			{Type: "workload", Name: "DaemonSet", Status: db.HealthStatusUnknown, Message: "Failed to get daemonsets: unexpected error"},
+		}, health.Reasons)
+	})
+}
+
+func TestGetApplicationUser(t *testing.T) {
+	t.Run("should return user without permissions for application without teams", func(t *testing.T) {
+		c, _, _, _ := newTestClient(t, nil)
+
+		user := c.getApplicationUser(context.Background(), applicationv1.ApplicationSpec{ID: "application1"})
+		require.False(t, user.HasPluginAccess("hub", "prometheus", "prometheus"))
+	})
+
+	t.Run("should return user without permissions when teams can not be returned", func(t *testing.T) {
+		c, _, _, dbClient := newTestClient(t, nil)
+		dbClient.EXPECT().GetTeamsByIDs(gomock.Any(), []string{"team1"}, "").Return(nil, fmt.Errorf("unexpected error"))
+
+		user := c.getApplicationUser(context.Background(), applicationv1.ApplicationSpec{ID: "application1", Teams: []string{"team1"}})
+		require.False(t, user.HasPluginAccess("hub", "prometheus", "prometheus"))
+	})
+
+	t.Run("should return user with plugin permissions of teams", func(t *testing.T) {
+		c, _, _, dbClient := newTestClient(t, nil)
+		dbClient.EXPECT().GetTeamsByIDs(gomock.Any(), []string{"team1", "team2"}, "").Return([]teamv1.TeamSpec{
+			{ID: "team1", Permissions: userv1.Permissions{Plugins: []userv1.Plugin{{Cluster: "hub", Type: "prometheus", Name: "prometheus"}}}},
+			{ID: "team2", Permissions: userv1.Permissions{Plugins: []userv1.Plugin{{Cluster: "hub", Type: "klogs", Name: "*"}}}},
+		}, nil)
+
+		user := c.getApplicationUser(context.Background(), applicationv1.ApplicationSpec{ID: "application1", Teams: []string{"team1", "team2"}})
+		require.True(t, user.HasPluginAccess("hub", "prometheus", "prometheus"))
+		require.True(t, user.HasPluginAccess("hub", "klogs", "klogs"))
+		require.False(t, user.HasPluginAccess("hub", "prometheus", "prometheus-secret"))
+	})
+}
+
+func TestIsLeader(t *testing.T) {
+	t.Run("should be leader when leader election is disabled", func(t *testing.T) {
+		c, _, _, _ := newTestClient(t, nil)
+		require.True(t, c.isLeader())
+	})
+
+	t.Run("should be leader when lease is acquired", func(t *testing.T) {
+		c, _, _, dbClient := newTestClient(t, nil)
+		c.config.LeaderElection = true
+		dbClient.EXPECT().AcquireLease(gomock.Any(), LeaseName, "hub1", 2*time.Minute).Return(&db.Lease{Name: LeaseName, Holder: "hub1"}, nil)
+
+		require.True(t, c.isLeader())
+	})
+
+	t.Run("should not be leader when lease is held by other replica", func(t *testing.T) {
+		c, _, _, dbClient := newTestClient(t, nil)
+		c.config.LeaderElection = true
+		dbClient.EXPECT().AcquireLease(gomock.Any(), LeaseName, "hub1", 2*time.Minute).Return(&db.Lease{Name: LeaseName, Holder: "hub2"}, nil)
+
+		require.False(t, c.isLeader())
+	})
+
+	t.Run("should not be leader when lease can not be acquired", func(t *testing.T) {
+		c, _, _, dbClient := newTestClient(t, nil)
+		c.config.LeaderElection = true
+		dbClient.EXPECT().AcquireLease(gomock.Any(), LeaseName, "hub1", 2*time.Minute).Return(nil, fmt.Errorf("unexpected error"))
+
+		require.False(t, c.isLeader())
+	})
+}
+
+func TestInsightStatus(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		health   applicationv1.InsightHealth
+		value    float64
+		expected string
+	}{
+		{name: "should be healthy without thresholds", health: applicationv1.InsightHealth{}, value: 100, expected: db.HealthStatusHealthy},
+		{name: "should be degraded with default operator", health: applicationv1.InsightHealth{Degraded: "10", Unhealthy: "20"}, value: 15, expected: db.HealthStatusDegraded},
+		{name: "should be unhealthy with default operator", health: applicationv1.InsightHealth{Degraded: "10", Unhealthy: "20"}, value: 20.5, expected: db.HealthStatusUnhealthy},
+		{name: "should be healthy with >= operator", health: applicationv1.InsightHealth{Operator: ">=", Degraded: "10"}, value: 9.9, expected: db.HealthStatusHealthy},
+		{name: "should be degraded with >= operator", health: applicationv1.InsightHealth{Operator: ">=", Degraded: "10"}, value: 10, expected: db.HealthStatusDegraded},
+		{name: "should be unhealthy with < operator", health: applicationv1.InsightHealth{Operator: "<", Degraded: "0.99", Unhealthy: "0.95"}, value: 0.9, expected: db.HealthStatusUnhealthy},
+		{name: "should be healthy with <= operator", health: applicationv1.InsightHealth{Operator: "<=", Unhealthy: "1"}, value: 1.1, expected: db.HealthStatusHealthy},
+		{name: "should ignore invalid thresholds", health: applicationv1.InsightHealth{Degraded: "ten"}, value: 100, expected: db.HealthStatusHealthy},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, insightStatus(tt.health, tt.value))
+		})
+	}
+}
+
+func TestWorkloadStatus(t *testing.T) {
+	require.Equal(t, db.HealthStatusHealthy, workloadStatus(0, 0))
+	require.Equal(t, db.HealthStatusHealthy, workloadStatus(2, 2))
+	require.Equal(t, db.HealthStatusDegraded, workloadStatus(2, 1))
+	require.Equal(t, db.HealthStatusUnhealthy, workloadStatus(2, 0))
+}
+
+func TestNewClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	pluginsClient := plugins.NewMockClient(ctrl)
+	pluginsClient.EXPECT().Mount().Return(chi.NewRouter())
+
+	c := NewClient(Config{}, nil, nil, pluginsClient)
+	require.NotNil(t, c)
+	require.NoError(t, c.Stop())
+}
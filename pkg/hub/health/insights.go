@@ -0,0 +1,153 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/instrument/log"
+	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
+
+	"go.uber.org/zap"
+)
+
+// datum is a single value of an insight, as it is returned by the insight route of a plugin.
+type datum struct {
+	X int64    `json:"x"`
+	Y *float64 `json:"y"`
+}
+
+// errNoData is returned when an insight doesn't return any value for the configured time window.
+var errNoData = errors.New("no data was found")
+
+// checkInsights returns a reason for each insight of the application, which defines health thresholds. Insights without
+// health thresholds are only shown in the frontend and ignored for the health of the application. The insights are
+// evaluated with the provided user, which has the plugin permissions of the teams of the application.
+func (c *client) checkInsights(ctx context.Context, application applicationv1.ApplicationSpec, user authContext.User) []db.ApplicationHealthReason {
+	var reasons []db.ApplicationHealthReason
+
+	for _, insight := range application.Insights {
+		if insight.Health == nil {
+			continue
+		}
+
+		reasons = append(reasons, c.checkInsight(ctx, application, insight, user))
+	}
+
+	return reasons
+}
+
+// checkInsight gets the values of the provided insight for the configured time window and compares the last value with
+// the health thresholds of the insight.
+//
+// The reasons can be viewed by all users with access to the application, so that they must not contain the value of
+// the insight or the error returned by the plugin. Errors are only logged.
+func (c *client) checkInsight(ctx context.Context, application applicationv1.ApplicationSpec, insight applicationv1.Insight, user authContext.User) db.ApplicationHealthReason {
+	reason := db.ApplicationHealthReason{Type: "insight", Name: insight.Title, Status: db.HealthStatusUnknown}
+
+	value, err := c.getInsightValue(ctx, insight, user)
+	if err != nil {
+		if errors.Is(err, errNoData) {
+			reason.Message = "No data was found"
+			return reason
+		}
+
+		log.Warn(ctx, "Failed to get insight value", zap.Error(err), zap.String("application", application.ID), zap.String("insight", insight.Title))
+		reason.Message = "Failed to get insight data"
+		return reason
+	}
+
+	reason.Status = insightStatus(*insight.Health, value)
+	switch reason.Status {
+	case db.HealthStatusUnhealthy:
+		reason.Message = "The last value exceeds the unhealthy threshold"
+	case db.HealthStatusDegraded:
+		reason.Message = "The last value exceeds the degraded threshold"
+	default:
+		reason.Message = "The last value is within the thresholds"
+	}
+
+	return reason
+}
+
+// getInsightValue requests the values of the insight from the plugin routes of the hub and returns the last value,
+// which is not null.
+func (c *client) getInsightValue(ctx context.Context, insight applicationv1.Insight, user authContext.User) (float64, error) {
+	body := []byte("{}")
+	if insight.Plugin.Options != nil {
+		body = insight.Plugin.Options.Raw
+	}
+
+	timeEnd := time.Now()
+	timeStart := timeEnd.Add(-c.config.Window)
+
+	req, err := http.NewRequestWithContext(context.WithValue(ctx, authContext.UserKey, user), http.MethodPost, fmt.Sprintf("/api/plugins/%s/insight?timeStart=%d&timeEnd=%d", insight.Plugin.Type, timeStart.Unix(), timeEnd.Unix()), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-kobs-cluster", insight.Plugin.Cluster)
+	req.Header.Set("x-kobs-plugin", insight.Plugin.Name)
+
+	w := httptest.NewRecorder()
+	c.plugins.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		var res errresponse.ErrResponse
+		if err := json.NewDecoder(w.Body).Decode(&res); err == nil && len(res.Errors) > 0 {
+			return 0, fmt.Errorf("failed to get insight data: %s", res.Errors[0])
+		}
+
+		return 0, fmt.Errorf("failed to get insight data: status code %d", w.Code)
+	}
+
+	var data []datum
+	if err := json.NewDecoder(w.Body).Decode(&data); err != nil {
+		return 0, fmt.Errorf("failed to decode insight data: %w", err)
+	}
+
+	for i := len(data) - 1; i >= 0; i-- {
+		if data[i].Y != nil {
+			return *data[i].Y, nil
+		}
+	}
+
+	return 0, errNoData
+}
+
+// insightStatus compares the provided value with the health thresholds of an insight. Thresholds which can not be
+// parsed are ignored, because they are already rejected by the validation of the Application CR.
+func insightStatus(health applicationv1.InsightHealth, value float64) string {
+	if threshold, err := strconv.ParseFloat(health.Unhealthy, 64); err == nil && exceeds(health.Operator, value, threshold) {
+		return db.HealthStatusUnhealthy
+	}
+
+	if threshold, err := strconv.ParseFloat(health.Degraded, 64); err == nil && exceeds(health.Operator, value, threshold) {
+		return db.HealthStatusDegraded
+	}
+
+	return db.HealthStatusHealthy
+}
+
+// exceeds returns true when the value exceeds the threshold for the provided operator. The default operator is ">".
+func exceeds(operator string, value, threshold float64) bool {
+	switch operator {
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	default:
+		return value > threshold
+	}
+}
@@ -0,0 +1,117 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
+	"github.com/kobsio/kobs/pkg/hub/db"
+)
+
+// workloads is the list of workload resources, which are checked for the health of an application.
+var workloads = []struct {
+	kind     string
+	resource string
+}{
+	{kind: "Deployment", resource: "deployments"},
+	{kind: "StatefulSet", resource: "statefulsets"},
+	{kind: "DaemonSet", resource: "daemonsets"},
+}
+
+// workloadList contains the fields of a list of Deployments, StatefulSets or DaemonSets, which are required to check
+// the readiness of the workloads.
+type workloadList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			Replicas *int64 `json:"replicas"`
+		} `json:"spec"`
+		Status struct {
+			ReadyReplicas          int64 `json:"readyReplicas"`
+			DesiredNumberScheduled int64 `json:"desiredNumberScheduled"`
+			NumberReady            int64 `json:"numberReady"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// checkWorkloads returns a reason for each Deployment, StatefulSet and DaemonSet of the application. The workloads are
+// selected by the configured workload label, which must have the name of the application as value.
+func (c *client) checkWorkloads(ctx context.Context, application applicationv1.ApplicationSpec) []db.ApplicationHealthReason {
+	clusterClient := c.clustersClient.GetCluster(application.Cluster)
+	if clusterClient == nil {
+		return []db.ApplicationHealthReason{{Type: "workload", Name: application.Cluster, Status: db.HealthStatusUnknown, Message: "Cluster was not found"}}
+	}
+
+	var reasons []db.ApplicationHealthReason
+	selector := url.QueryEscape(fmt.Sprintf("%s=%s", c.config.WorkloadLabel, application.Name))
+
+	for _, workload := range workloads {
+		res, err := clusterClient.Request(ctx, http.MethodGet, fmt.Sprintf("/api/resources?namespace=%s&resource=%s&path=%s&paramName=%s&param=%s", application.Namespace, workload.resource, "/apis/apps/v1", "labelSelector", selector), nil)
+		if err != nil {
+			reasons = append(reasons, db.ApplicationHealthReason{Type: "workload", Name: workload.kind, Status: db.HealthStatusUnknown, Message: fmt.Sprintf("Failed to get %s: %s", workload.resource, err.Error())})
+			continue
+		}
+
+		list, err := toWorkloadList(res)
+		if err != nil {
+			reasons = append(reasons, db.ApplicationHealthReason{Type: "workload", Name: workload.kind, Status: db.HealthStatusUnknown, Message: fmt.Sprintf("Failed to decode %s: %s", workload.resource, err.Error())})
+			continue
+		}
+
+		for _, item := range list.Items {
+			desired := int64(1)
+			if item.Spec.Replicas != nil {
+				desired = *item.Spec.Replicas
+			}
+			ready := item.Status.ReadyReplicas
+
+			if workload.kind == "DaemonSet" {
+				desired = item.Status.DesiredNumberScheduled
+				ready = item.Status.NumberReady
+			}
+
+			reasons = append(reasons, db.ApplicationHealthReason{
+				Type:    "workload",
+				Name:    fmt.Sprintf("%s/%s", workload.kind, item.Metadata.Name),
+				Status:  workloadStatus(desired, ready),
+				Message: fmt.Sprintf("%d of %d replicas are ready", ready, desired),
+			})
+		}
+	}
+
+	return reasons
+}
+
+// toWorkloadList converts the response of the resources API of a cluster to a workload list.
+func toWorkloadList(res map[string]any) (*workloadList, error) {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+
+	var list workloadList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+// workloadStatus returns the status of a workload. A workload is healthy when all replicas are ready, degraded when
+// only some replicas are ready and unhealthy when no replica is ready.
+func workloadStatus(desired, ready int64) string {
+	if ready >= desired {
+		return db.HealthStatusHealthy
+	}
+
+	if ready > 0 {
+		return db.HealthStatusDegraded
+	}
+
+	return db.HealthStatusUnhealthy
+}
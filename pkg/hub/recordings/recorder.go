@@ -0,0 +1,128 @@
+package recordings
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultWidth and defaultHeight are used for the header of a recording, when the terminal size was never sent by
+	// the user.
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+// header is the header of a recording in the asciicast v2 format. See
+// https://docs.asciinema.org/manual/asciicast/v2/ for the specification of the format.
+type header struct {
+	Version   int               `json:"version"`
+	Width     uint16            `json:"width"`
+	Height    uint16            `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// recorder records the output and the size changes of a terminal session as events in the asciicast v2 format. All
+// methods can be called concurrently. The events are kept in memory until the session is closed, so that the `maxSize`
+// limits the memory used by a recording. When the size of the recorded events would exceed the `maxSize`, the event
+// and all following events are dropped and the recording is marked as truncated. The caller must then close the
+// session, so that no output is shown to the user which is not recorded.
+type recorder struct {
+	mu        sync.Mutex
+	start     time.Time
+	now       func() time.Time
+	width     uint16
+	height    uint16
+	events    bytes.Buffer
+	maxSize   int
+	truncated bool
+}
+
+// resize records a size change of the terminal. The first size change before any output is used as the size in the
+// header of the recording.
+func (r *recorder) resize(cols, rows uint16) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.events.Len() == 0 {
+		r.width = cols
+		r.height = rows
+		return
+	}
+
+	r.write("r", fmt.Sprintf("%dx%d", cols, rows))
+}
+
+// output records the output of the terminal. It returns false when the output could not be recorded, because the
+// recording reached the maximum size.
+func (r *recorder) output(data string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.write("o", data)
+}
+
+// write adds a new event with the provided code and data to the recording. The time of an event is the number of
+// seconds since the start of the recording, rounded to microseconds. The caller must hold the lock of the recorder.
+// It returns false when the event was dropped.
+func (r *recorder) write(code, data string) bool {
+	if r.truncated {
+		return false
+	}
+
+	elapsed := math.Round(r.now().Sub(r.start).Seconds()*1e6) / 1e6
+
+	event, err := json.Marshal([]any{elapsed, code, data})
+	if err != nil {
+		return false
+	}
+
+	if r.maxSize > 0 && r.events.Len()+len(event)+1 > r.maxSize {
+		r.truncated = true
+		return false
+	}
+
+	r.events.Write(event)
+	r.events.WriteByte('\n')
+	return true
+}
+
+// asciicast returns the recording in the asciicast v2 format, which is the header followed by all recorded events. The
+// returned boolean is true, when events were dropped, because the recording exceeded the maximum size.
+func (r *recorder) asciicast(title, shell string) ([]byte, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h := header{
+		Version:   2,
+		Width:     r.width,
+		Height:    r.height,
+		Timestamp: r.start.Unix(),
+		Title:     title,
+		Env:       map[string]string{"SHELL": shell, "TERM": "xterm"},
+	}
+	if h.Width == 0 || h.Height == 0 {
+		h.Width = defaultWidth
+		h.Height = defaultHeight
+	}
+
+	data, _ := json.Marshal(h)
+	data = append(data, '\n')
+	data = append(data, r.events.Bytes()...)
+
+	return data, r.truncated
+}
+
+// newRecorder returns a new recorder, which starts the recording at the current time.
+func newRecorder(maxSize int) *recorder {
+	return &recorder{
+		start:   time.Now(),
+		now:     time.Now,
+		maxSize: maxSize,
+	}
+}
@@ -0,0 +1,58 @@
+package recordings
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	var newTestRecorder = func(maxSize int) (*recorder, *time.Time) {
+		start := time.Unix(1700000000, 0)
+		now := start
+
+		r := newRecorder(maxSize)
+		r.start = start
+		r.now = func() time.Time { return now }
+
+		return r, &now
+	}
+
+	t.Run("should use default size when terminal was never resized", func(t *testing.T) {
+		r, _ := newTestRecorder(0)
+
+		data, truncated := r.asciicast("title", "bash")
+		require.False(t, truncated)
+		require.Equal(t, `{"version":2,"width":80,"height":24,"timestamp":1700000000,"title":"title","env":{"SHELL":"bash","TERM":"xterm"}}`+"\n", string(data))
+	})
+
+	t.Run("should record output and resize events", func(t *testing.T) {
+		r, now := newTestRecorder(0)
+
+		r.resize(120, 40)
+		*now = now.Add(1500 * time.Millisecond)
+		r.output("$ ls\r\n")
+		*now = now.Add(250 * time.Microsecond)
+		r.resize(100, 30)
+
+		data, truncated := r.asciicast("title", "sh")
+		require.False(t, truncated)
+		require.Equal(t, `{"version":2,"width":120,"height":40,"timestamp":1700000000,"title":"title","env":{"SHELL":"sh","TERM":"xterm"}}`+"\n"+
+			`[1.5,"o","$ ls\r\n"]`+"\n"+
+			`[1.50025,"r","100x30"]`+"\n", string(data))
+	})
+
+	t.Run("should drop events when maximum size is exceeded", func(t *testing.T) {
+		r, _ := newTestRecorder(30)
+
+		require.True(t, r.output("first"))
+		require.False(t, r.output("second"))
+		require.False(t, r.output("x"))
+
+		data, truncated := r.asciicast("", "sh")
+		require.True(t, truncated)
+		require.Equal(t, `{"version":2,"width":80,"height":24,"timestamp":1700000000,"env":{"SHELL":"sh","TERM":"xterm"}}`+"\n"+
+			`[0,"o","first"]`+"\n", string(data))
+	})
+}
@@ -0,0 +1,401 @@
+package recordings
+
+//go:generate mockgen -source=recordings.go -destination=./recordings_mock.go -package=recordings Client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/terminal"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/clusters/cluster"
+	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/instrument/log"
+	"github.com/kobsio/kobs/pkg/utils/middleware/errresponse"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+var (
+	pingPeriod = 30 * time.Second
+)
+
+// recordingsBatchSize is the number of recordings which are requested at once from the database, when the recordings
+// must be filtered by the permissions of the user.
+const recordingsBatchSize = 500
+
+// maxSizeMessage is sent to the user, when the terminal session is closed, because the recording reached the maximum
+// size.
+const maxSizeMessage = "\r\nThe terminal session was closed, because the recording reached the maximum size.\r\n"
+
+type Config struct {
+	Clusters []string `json:"clusters" env:"CLUSTERS" help:"The names of the clusters for which all terminal sessions are recorded. Use \"*\" to record the terminal sessions for all clusters."`
+	MaxSize  int      `json:"maxSize" env:"MAX_SIZE" default:"10485760" help:"The maximum size of a single recording in bytes. A recording is kept in memory until the terminal session is closed and the session is closed when the recording reaches the maximum size. Set to 0 to disable the limit."`
+}
+
+// Client is the interface of the recordings client. The client can be used to record the terminal sessions for the
+// configured clusters and provides a router to list and download the recordings.
+type Client interface {
+	IsEnabled(cluster string) bool
+	Terminal(w http.ResponseWriter, r *http.Request, clusterClient cluster.Client)
+	Mount() chi.Router
+}
+
+type client struct {
+	config   Config
+	router   *chi.Mux
+	dbClient db.Client
+	tracer   trace.Tracer
+}
+
+// IsEnabled returns true when the terminal sessions for the cluster with the provided name should be recorded.
+func (c *client) IsEnabled(cluster string) bool {
+	for _, name := range c.config.Clusters {
+		if name == "*" || name == cluster {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Terminal records a terminal session in a container. Instead of proxying the request to the cluster, we open a
+// WebSocket connection to the cluster and relay all messages between the user and the cluster, so that we can record
+// the output of the terminal and all changes of the terminal size. The input of the user is not recorded, because it
+// can contain confidential data like passwords, which are not shown in the terminal.
+//
+// When the session is closed the recording is saved in the asciicast v2 format together with the user, Pod, container
+// and the duration of the session. The recording is kept in memory until then. When the output of the terminal would
+// exceed the configured maximum size, the output is not forwarded to the user, the session is closed and the recording
+// is saved and marked as truncated, so that a user can not run commands whose output isn't recorded.
+func (c *client) Terminal(w http.ResponseWriter, r *http.Request, clusterClient cluster.Client) {
+	ctx, span := c.tracer.Start(r.Context(), "recordings.Terminal")
+	defer span.End()
+
+	user := authContext.MustGetUser(ctx)
+	recording := db.TerminalRecording{
+		ID:        newID(),
+		User:      user.ID,
+		Cluster:   clusterClient.GetName(),
+		Namespace: r.URL.Query().Get("namespace"),
+		Pod:       r.URL.Query().Get("name"),
+		Container: r.URL.Query().Get("container"),
+		Shell:     r.URL.Query().Get("shell"),
+	}
+
+	span.SetAttributes(attribute.Key("id").String(recording.ID))
+	span.SetAttributes(attribute.Key("cluster").String(recording.Cluster))
+	span.SetAttributes(attribute.Key("namespace").String(recording.Namespace))
+	span.SetAttributes(attribute.Key("pod").String(recording.Pod))
+	span.SetAttributes(attribute.Key("container").String(recording.Container))
+
+	clusterConn, err := clusterClient.DialWebSocket(ctx, r.URL.RequestURI())
+	if err != nil {
+		log.Error(ctx, "Failed to connect to cluster", zap.Error(err), zap.String("cluster", recording.Cluster))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		errresponse.Render(w, r, http.StatusBadGateway, "Failed to connect to cluster")
+		return
+	}
+	defer clusterConn.Close()
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+
+	userConn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Error(ctx, "Failed to upgrade connection", zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	defer userConn.Close()
+
+	rec := newRecorder(c.config.MaxSize)
+	relay(ctx, userConn, clusterConn, rec)
+
+	recording.Timestamp = rec.start
+	recording.Duration = time.Since(rec.start).Milliseconds()
+	recording.Data, recording.Truncated = rec.asciicast(fmt.Sprintf("%s/%s/%s/%s", recording.Cluster, recording.Namespace, recording.Pod, recording.Container), recording.Shell)
+	recording.Size = len(recording.Data)
+
+	if err := c.dbClient.SaveTerminalRecording(context.WithoutCancel(ctx), &recording); err != nil {
+		log.Error(ctx, "Failed to save terminal recording", zap.Error(err), zap.String("id", recording.ID))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+
+	log.Info(ctx, "Terminal session was recorded", zap.String("id", recording.ID), zap.String("user", recording.User), zap.String("cluster", recording.Cluster), zap.String("namespace", recording.Namespace), zap.String("pod", recording.Pod), zap.String("container", recording.Container), zap.Int64("duration", recording.Duration), zap.Int("size", recording.Size))
+}
+
+// relay relays all messages between the connection of the user and the connection to the cluster, until one of the
+// connections is closed. The resize messages of the user and the output of the terminal are passed to the recorder. If
+// the output can not be recorded, the session is closed.
+func relay(ctx context.Context, userConn, clusterConn *websocket.Conn, rec *recorder) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	// The user connection is not proxied anymore, so that we have to send the ping messages to the user ourselves. The
+	// ping messages from the cluster are answered by the default ping handler of the cluster connection.
+	go func() {
+		ticker := time.NewTicker(pingPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := userConn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pingPeriod)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	copyMessages := func(src, dst *websocket.Conn, record func(msg terminal.Message) bool) {
+		defer wg.Done()
+
+		// When one side of the session is closed, we close both connections, so that the other goroutine also
+		// returns.
+		defer cancel()
+		defer src.Close()
+		defer dst.Close()
+
+		for {
+			messageType, data, err := src.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			if messageType == websocket.TextMessage {
+				var msg terminal.Message
+				if err := json.Unmarshal(data, &msg); err == nil && !record(msg) {
+					return
+				}
+			}
+
+			if err := dst.WriteMessage(messageType, data); err != nil {
+				return
+			}
+		}
+	}
+
+	go copyMessages(userConn, clusterConn, func(msg terminal.Message) bool {
+		if msg.Op == "resize" {
+			rec.resize(msg.Cols, msg.Rows)
+		}
+		return true
+	})
+
+	go copyMessages(clusterConn, userConn, func(msg terminal.Message) bool {
+		if msg.Op == "stdout" && !rec.output(msg.Data) {
+			userConn.WriteJSON(terminal.Message{Op: "stdout", Data: maxSizeMessage})
+			return false
+		}
+		return true
+	})
+
+	wg.Wait()
+}
+
+// getRecordings returns the recorded terminal sessions. A user can see their own recordings and all recordings for
+// which they are allowed to access the `recordings` resource in the cluster and namespace of the recording, like in the
+// getRecording handler.
+func (c *client) getRecordings(w http.ResponseWriter, r *http.Request) {
+	ctx, span := c.tracer.Start(r.Context(), "getRecordings")
+	defer span.End()
+
+	user := authContext.MustGetUser(ctx)
+	userFilter := r.URL.Query().Get("user")
+	cluster := r.URL.Query().Get("cluster")
+	namespace := r.URL.Query().Get("namespace")
+	limit := r.URL.Query().Get("limit")
+	offset := r.URL.Query().Get("offset")
+
+	span.SetAttributes(attribute.Key("user").String(userFilter))
+	span.SetAttributes(attribute.Key("cluster").String(cluster))
+	span.SetAttributes(attribute.Key("namespace").String(namespace))
+	span.SetAttributes(attribute.Key("limit").String(limit))
+	span.SetAttributes(attribute.Key("offset").String(offset))
+
+	parsedLimit, err := parseInt(limit, 100)
+	if err != nil {
+		log.Error(ctx, "Failed to parse 'limit' parameter", zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		errresponse.Render(w, r, http.StatusBadRequest, "Failed to parse 'limit' parameter")
+		return
+	}
+
+	parsedOffset, err := parseInt(offset, 0)
+	if err != nil {
+		log.Error(ctx, "Failed to parse 'offset' parameter", zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		errresponse.Render(w, r, http.StatusBadRequest, "Failed to parse 'offset' parameter")
+		return
+	}
+
+	recordings, err := c.getPermittedRecordings(ctx, user, userFilter, cluster, namespace, parsedLimit, parsedOffset)
+	if err != nil {
+		log.Error(ctx, "Failed to get recordings", zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get recordings")
+		return
+	}
+
+	render.JSON(w, r, recordings)
+}
+
+// getPermittedRecordings returns the recordings matching the provided filters, which can be viewed by the user. When
+// the user can view all recordings for the filters, the limit and offset are passed to the database. Otherwise the
+// recordings are requested in batches and filtered by the permissions of the user, until enough recordings are found.
+func (c *client) getPermittedRecordings(ctx context.Context, user *authContext.User, userFilter, cluster, namespace string, limit, offset int) ([]db.TerminalRecording, error) {
+	if userFilter == user.ID || user.HasResourceAccess(orAll(cluster), orAll(namespace), "recordings", "get") {
+		return c.dbClient.GetTerminalRecordings(ctx, userFilter, cluster, namespace, limit, offset)
+	}
+
+	var recordings []db.TerminalRecording
+	skipped := 0
+
+	for batchOffset := 0; ; batchOffset += recordingsBatchSize {
+		batch, err := c.dbClient.GetTerminalRecordings(ctx, userFilter, cluster, namespace, recordingsBatchSize, batchOffset)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, recording := range batch {
+			if !canViewRecording(user, recording) {
+				continue
+			}
+
+			if skipped < offset {
+				skipped++
+				continue
+			}
+
+			recordings = append(recordings, recording)
+			if len(recordings) == limit {
+				return recordings, nil
+			}
+		}
+
+		if len(batch) < recordingsBatchSize {
+			return recordings, nil
+		}
+	}
+}
+
+// getRecording returns a single recording in the asciicast v2 format, so that it can be replayed with asciinema. A
+// user can download their own recordings and all recordings for which they are allowed to access the `recordings`
+// resource in the cluster and namespace of the recording.
+func (c *client) getRecording(w http.ResponseWriter, r *http.Request) {
+	ctx, span := c.tracer.Start(r.Context(), "getRecording")
+	defer span.End()
+
+	user := authContext.MustGetUser(ctx)
+	id := chi.URLParam(r, "id")
+
+	span.SetAttributes(attribute.Key("id").String(id))
+
+	recording, err := c.dbClient.GetTerminalRecording(ctx, id)
+	if err != nil {
+		log.Error(ctx, "Failed to get recording", zap.Error(err), zap.String("id", id))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, db.ErrTerminalRecordingNotFound) {
+			errresponse.Render(w, r, http.StatusNotFound, "Recording was not found")
+			return
+		}
+		errresponse.Render(w, r, http.StatusInternalServerError, "Failed to get recording")
+		return
+	}
+
+	if !canViewRecording(user, *recording) {
+		log.Warn(ctx, "The user is not authorized to view the recording", zap.String("id", id))
+		span.RecordError(fmt.Errorf("user is not authorized to view the recording"))
+		span.SetStatus(codes.Error, "user is not authorized to view the recording")
+		errresponse.Render(w, r, http.StatusForbidden, "You are not allowed to view the recording")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-asciicast")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.cast\"", recording.ID))
+	w.WriteHeader(http.StatusOK)
+	w.Write(recording.Data)
+}
+
+// Mount returns the router of the recordings client, so it can be mounted into an existing chi router.
+func (c *client) Mount() chi.Router {
+	return c.router
+}
+
+// NewClient returns a new recordings client. The terminal sessions are only recorded for the clusters from the
+// provided configuration.
+func NewClient(config Config, dbClient db.Client) Client {
+	c := &client{
+		config:   config,
+		router:   chi.NewRouter(),
+		dbClient: dbClient,
+		tracer:   otel.Tracer("recordings"),
+	}
+
+	c.router.Get("/", c.getRecordings)
+	c.router.Get("/{id}", c.getRecording)
+
+	return c
+}
+
+// newID returns a new random id for a recording.
+func newID() string {
+	id := make([]byte, 12)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// canViewRecording returns true when the provided user can view the recording. A user can view their own recordings
+// and all recordings for which they are allowed to access the `recordings` resource in the cluster and namespace of the
+// recording.
+func canViewRecording(user *authContext.User, recording db.TerminalRecording) bool {
+	return recording.User == user.ID || user.HasResourceAccess(recording.Cluster, recording.Namespace, "recordings", "get")
+}
+
+// orAll returns "*" for an empty filter, so that the filter can be used to check the permissions of a user.
+func orAll(value string) string {
+	if value == "" {
+		return "*"
+	}
+
+	return value
+}
+
+// parseInt parses the provided value as integer. If the value is empty the provided default value is returned.
+func parseInt(value string, defaultValue int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,401 @@
+package recordings
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/terminal"
+	authContext "github.com/kobsio/kobs/pkg/hub/auth/context"
+	"github.com/kobsio/kobs/pkg/hub/clusters/cluster"
+	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestIsEnabled(t *testing.T) {
+	require.False(t, NewClient(Config{}, nil).IsEnabled("cluster1"))
+	require.False(t, NewClient(Config{Clusters: []string{"cluster2"}}, nil).IsEnabled("cluster1"))
+	require.True(t, NewClient(Config{Clusters: []string{"cluster2", "cluster1"}}, nil).IsEnabled("cluster1"))
+	require.True(t, NewClient(Config{Clusters: []string{"*"}}, nil).IsEnabled("cluster1"))
+}
+
+func TestTerminal(t *testing.T) {
+	var newClient = func(t *testing.T) (*db.MockClient, *cluster.MockClient, *client) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		clusterClient := cluster.NewMockClient(ctrl)
+		clusterClient.EXPECT().GetName().Return("cluster1").AnyTimes()
+
+		return dbClient, clusterClient, &client{config: Config{Clusters: []string{"*"}, MaxSize: 100}, router: chi.NewRouter(), dbClient: dbClient, tracer: otel.Tracer("recordings")}
+	}
+
+	var newServer = func(c *client, clusterClient cluster.Client) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), authContext.UserKey, authContext.User{ID: "user@kobs.io"})
+			c.Terminal(w, r.WithContext(ctx), clusterClient)
+		}))
+	}
+
+	t.Run("should fail when connection to cluster fails", func(t *testing.T) {
+		_, clusterClient, c := newClient(t)
+		clusterClient.EXPECT().DialWebSocket(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("unexpected error"))
+
+		ctx := context.WithValue(context.Background(), authContext.UserKey, authContext.User{ID: "user@kobs.io"})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/api/resources/terminal?namespace=default&name=pod1", nil)
+		w := httptest.NewRecorder()
+		c.Terminal(w, req, clusterClient)
+
+		utils.AssertStatusEq(t, w, http.StatusBadGateway)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to connect to cluster"]}`)
+	})
+
+	t.Run("should record terminal session", func(t *testing.T) {
+		// The cluster server answers each stdin message with a stdout message containing the same data and closes the
+		// connection after the first message, so that the session is finished.
+		clusterServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upgrader := websocket.Upgrader{}
+			conn, err := upgrader.Upgrade(w, r, nil)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			for {
+				var msg terminal.Message
+				if err := conn.ReadJSON(&msg); err != nil {
+					return
+				}
+
+				if msg.Op == "stdin" {
+					conn.WriteJSON(terminal.Message{Op: "stdout", Data: "echo " + msg.Data})
+					conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+					return
+				}
+			}
+		}))
+		defer clusterServer.Close()
+
+		dbClient, clusterClient, c := newClient(t)
+		clusterClient.EXPECT().DialWebSocket(gomock.Any(), "/api/resources/terminal?namespace=default&name=pod1&container=container1&shell=bash").DoAndReturn(func(ctx context.Context, path string) (*websocket.Conn, error) {
+			conn, _, err := websocket.DefaultDialer.DialContext(ctx, "ws"+strings.TrimPrefix(clusterServer.URL, "http")+path, nil)
+			return conn, err
+		})
+
+		saved := make(chan *db.TerminalRecording, 1)
+		dbClient.EXPECT().SaveTerminalRecording(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, recording *db.TerminalRecording) error {
+			saved <- recording
+			return nil
+		})
+
+		server := newServer(c, clusterClient)
+		defer server.Close()
+
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/resources/terminal?namespace=default&name=pod1&container=container1&shell=bash", nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.NoError(t, conn.WriteJSON(terminal.Message{Op: "resize", Cols: 100, Rows: 50}))
+		require.NoError(t, conn.WriteJSON(terminal.Message{Op: "stdin", Data: "secret"}))
+
+		var msg terminal.Message
+		require.NoError(t, conn.ReadJSON(&msg))
+		require.Equal(t, terminal.Message{Op: "stdout", Data: "echo secret"}, msg)
+
+		select {
+		case recording := <-saved:
+			require.NotEmpty(t, recording.ID)
+			require.Equal(t, "user@kobs.io", recording.User)
+			require.Equal(t, "cluster1", recording.Cluster)
+			require.Equal(t, "default", recording.Namespace)
+			require.Equal(t, "pod1", recording.Pod)
+			require.Equal(t, "container1", recording.Container)
+			require.Equal(t, "bash", recording.Shell)
+			require.False(t, recording.Truncated)
+			require.Equal(t, len(recording.Data), recording.Size)
+			require.Contains(t, string(recording.Data), `"width":100,"height":50`)
+			require.Contains(t, string(recording.Data), `"o","echo secret"]`)
+			require.NotContains(t, string(recording.Data), `"secret"`)
+		case <-time.After(5 * time.Second):
+			t.Fatal("recording was not saved")
+		}
+	})
+
+	t.Run("should close terminal session when recording reaches maximum size", func(t *testing.T) {
+		// The cluster server answers the first stdin message with a small and a large stdout message, which exceeds the
+		// maximum size of the recording. The connection is kept open, so that it must be closed by the hub.
+		clusterServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upgrader := websocket.Upgrader{}
+			conn, err := upgrader.Upgrade(w, r, nil)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			for {
+				var msg terminal.Message
+				if err := conn.ReadJSON(&msg); err != nil {
+					return
+				}
+
+				if msg.Op == "stdin" {
+					conn.WriteJSON(terminal.Message{Op: "stdout", Data: "small"})
+					conn.WriteJSON(terminal.Message{Op: "stdout", Data: strings.Repeat("x", 200)})
+				}
+			}
+		}))
+		defer clusterServer.Close()
+
+		dbClient, clusterClient, c := newClient(t)
+		clusterClient.EXPECT().DialWebSocket(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, path string) (*websocket.Conn, error) {
+			conn, _, err := websocket.DefaultDialer.DialContext(ctx, "ws"+strings.TrimPrefix(clusterServer.URL, "http")+path, nil)
+			return conn, err
+		})
+
+		saved := make(chan *db.TerminalRecording, 1)
+		dbClient.EXPECT().SaveTerminalRecording(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, recording *db.TerminalRecording) error {
+			saved <- recording
+			return nil
+		})
+
+		server := newServer(c, clusterClient)
+		defer server.Close()
+
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/resources/terminal?namespace=default&name=pod1", nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.NoError(t, conn.WriteJSON(terminal.Message{Op: "stdin", Data: "cat file"}))
+
+		var msg terminal.Message
+		require.NoError(t, conn.ReadJSON(&msg))
+		require.Equal(t, terminal.Message{Op: "stdout", Data: "small"}, msg)
+		require.NoError(t, conn.ReadJSON(&msg))
+		require.Equal(t, terminal.Message{Op: "stdout", Data: maxSizeMessage}, msg)
+		require.Error(t, conn.ReadJSON(&msg))
+
+		select {
+		case recording := <-saved:
+			require.True(t, recording.Truncated)
+			require.Contains(t, string(recording.Data), `"o","small"]`)
+			require.NotContains(t, string(recording.Data), "xxx")
+		case <-time.After(5 * time.Second):
+			t.Fatal("recording was not saved")
+		}
+	})
+}
+
+func TestGetRecordings(t *testing.T) {
+	var newClient = func(t *testing.T) (*db.MockClient, *client) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+
+		return dbClient, &client{router: chi.NewRouter(), dbClient: dbClient, tracer: otel.Tracer("recordings")}
+	}
+
+	adminUser := authContext.User{ID: "admin@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"*"}, Namespaces: []string{"*"}, Resources: []string{"recordings"}, Verbs: []string{"get"}}}}}
+	namespaceUser := authContext.User{ID: "user@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"cluster1"}, Namespaces: []string{"default"}, Resources: []string{"recordings"}, Verbs: []string{"get"}}}}}
+
+	for _, tt := range []struct {
+		name               string
+		user               authContext.User
+		url                string
+		prepare            func(t *testing.T, dbClient *db.MockClient)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "should fail for invalid limit",
+			user:               adminUser,
+			url:                "/?limit=abc",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors": ["Failed to parse 'limit' parameter"]}`,
+		},
+		{
+			name:               "should fail for invalid offset",
+			user:               adminUser,
+			url:                "/?offset=abc",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"errors": ["Failed to parse 'offset' parameter"]}`,
+		},
+		{
+			name: "should fail when recordings could not be returned",
+			user: adminUser,
+			url:  "/",
+			prepare: func(t *testing.T, dbClient *db.MockClient) {
+				dbClient.EXPECT().GetTerminalRecordings(gomock.Any(), "", "", "", 100, 0).Return(nil, fmt.Errorf("unexpected error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `{"errors": ["Failed to get recordings"]}`,
+		},
+		{
+			name: "should return own recordings",
+			user: authContext.User{ID: "user@kobs.io"},
+			url:  "/?user=user@kobs.io&cluster=cluster1",
+			prepare: func(t *testing.T, dbClient *db.MockClient) {
+				dbClient.EXPECT().GetTerminalRecordings(gomock.Any(), "user@kobs.io", "cluster1", "", 100, 0).Return([]db.TerminalRecording{{ID: "recording1", Timestamp: time.Unix(0, 0).UTC(), Data: []byte("data")}}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `[{"id": "recording1", "user": "", "cluster": "", "namespace": "", "pod": "", "container": "", "shell": "", "timestamp": "1970-01-01T00:00:00Z", "duration": 0, "size": 0, "truncated": false}]`,
+		},
+		{
+			name: "should filter recordings by permissions of user",
+			user: namespaceUser,
+			url:  "/?user=admin@kobs.io&limit=1&offset=1",
+			prepare: func(t *testing.T, dbClient *db.MockClient) {
+				dbClient.EXPECT().GetTerminalRecordings(gomock.Any(), "admin@kobs.io", "", "", recordingsBatchSize, 0).Return([]db.TerminalRecording{
+					{ID: "recording1", User: "admin@kobs.io", Cluster: "cluster1", Namespace: "default", Timestamp: time.Unix(0, 0).UTC()},
+					{ID: "recording2", User: "admin@kobs.io", Cluster: "cluster2", Namespace: "default", Timestamp: time.Unix(0, 0).UTC()},
+					{ID: "recording3", User: "admin@kobs.io", Cluster: "cluster1", Namespace: "kube-system", Timestamp: time.Unix(0, 0).UTC()},
+					{ID: "recording4", User: "admin@kobs.io", Cluster: "cluster1", Namespace: "default", Timestamp: time.Unix(0, 0).UTC()},
+					{ID: "recording5", User: "admin@kobs.io", Cluster: "cluster1", Namespace: "default", Timestamp: time.Unix(0, 0).UTC()},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `[{"id": "recording4", "user": "admin@kobs.io", "cluster": "cluster1", "namespace": "default", "pod": "", "container": "", "shell": "", "timestamp": "1970-01-01T00:00:00Z", "duration": 0, "size": 0, "truncated": false}]`,
+		},
+		{
+			name: "should request recordings in batches when filtering by permissions of user",
+			user: authContext.User{ID: "user@kobs.io"},
+			url:  "/",
+			prepare: func(t *testing.T, dbClient *db.MockClient) {
+				batch := make([]db.TerminalRecording, recordingsBatchSize)
+				for i := range batch {
+					batch[i] = db.TerminalRecording{ID: fmt.Sprintf("recording%d", i), User: "admin@kobs.io", Cluster: "cluster1", Namespace: "default", Timestamp: time.Unix(0, 0).UTC()}
+				}
+
+				dbClient.EXPECT().GetTerminalRecordings(gomock.Any(), "", "", "", recordingsBatchSize, 0).Return(batch, nil)
+				dbClient.EXPECT().GetTerminalRecordings(gomock.Any(), "", "", "", recordingsBatchSize, recordingsBatchSize).Return([]db.TerminalRecording{
+					{ID: "recording1", User: "user@kobs.io", Cluster: "cluster1", Namespace: "default", Timestamp: time.Unix(0, 0).UTC()},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `[{"id": "recording1", "user": "user@kobs.io", "cluster": "cluster1", "namespace": "default", "pod": "", "container": "", "shell": "", "timestamp": "1970-01-01T00:00:00Z", "duration": 0, "size": 0, "truncated": false}]`,
+		},
+		{
+			name: "should fail when recordings could not be returned while filtering by permissions of user",
+			user: authContext.User{ID: "user@kobs.io"},
+			url:  "/",
+			prepare: func(t *testing.T, dbClient *db.MockClient) {
+				dbClient.EXPECT().GetTerminalRecordings(gomock.Any(), "", "", "", recordingsBatchSize, 0).Return(nil, fmt.Errorf("unexpected error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `{"errors": ["Failed to get recordings"]}`,
+		},
+		{
+			name: "should return recordings of all users",
+			user: adminUser,
+			url:  "/?user=user@kobs.io&cluster=cluster1&namespace=default&limit=10&offset=20",
+			prepare: func(t *testing.T, dbClient *db.MockClient) {
+				dbClient.EXPECT().GetTerminalRecordings(gomock.Any(), "user@kobs.io", "cluster1", "default", 10, 20).Return(nil, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `null`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dbClient, c := newClient(t)
+			if tt.prepare != nil {
+				tt.prepare(t, dbClient)
+			}
+
+			ctx := context.WithValue(context.Background(), authContext.UserKey, tt.user)
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, tt.url, nil)
+			w := httptest.NewRecorder()
+			c.getRecordings(w, req)
+
+			utils.AssertStatusEq(t, w, tt.expectedStatusCode)
+			utils.AssertJSONEq(t, w, tt.expectedBody)
+		})
+	}
+}
+
+func TestGetRecording(t *testing.T) {
+	var newClient = func(t *testing.T) (*db.MockClient, *client) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+
+		return dbClient, &client{router: chi.NewRouter(), dbClient: dbClient, tracer: otel.Tracer("recordings")}
+	}
+
+	var newRequest = func(user authContext.User) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "recording1")
+
+		ctx := context.WithValue(context.Background(), authContext.UserKey, user)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/recording1", nil)
+		return req
+	}
+
+	recording := &db.TerminalRecording{ID: "recording1", User: "user@kobs.io", Cluster: "cluster1", Namespace: "default", Data: []byte(`{"version":2}`)}
+
+	t.Run("should fail when recording is not found", func(t *testing.T) {
+		dbClient, c := newClient(t)
+		dbClient.EXPECT().GetTerminalRecording(gomock.Any(), "recording1").Return(nil, db.ErrTerminalRecordingNotFound)
+
+		w := httptest.NewRecorder()
+		c.getRecording(w, newRequest(authContext.User{ID: "user@kobs.io"}))
+
+		utils.AssertStatusEq(t, w, http.StatusNotFound)
+		utils.AssertJSONEq(t, w, `{"errors": ["Recording was not found"]}`)
+	})
+
+	t.Run("should fail when recording could not be returned", func(t *testing.T) {
+		dbClient, c := newClient(t)
+		dbClient.EXPECT().GetTerminalRecording(gomock.Any(), "recording1").Return(nil, fmt.Errorf("unexpected error"))
+
+		w := httptest.NewRecorder()
+		c.getRecording(w, newRequest(authContext.User{ID: "user@kobs.io"}))
+
+		utils.AssertStatusEq(t, w, http.StatusInternalServerError)
+		utils.AssertJSONEq(t, w, `{"errors": ["Failed to get recording"]}`)
+	})
+
+	t.Run("should fail when user is not allowed to view the recording", func(t *testing.T) {
+		dbClient, c := newClient(t)
+		dbClient.EXPECT().GetTerminalRecording(gomock.Any(), "recording1").Return(recording, nil)
+
+		w := httptest.NewRecorder()
+		c.getRecording(w, newRequest(authContext.User{ID: "other@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"cluster1"}, Namespaces: []string{"kube-system"}, Resources: []string{"recordings"}, Verbs: []string{"get"}}}}}))
+
+		utils.AssertStatusEq(t, w, http.StatusForbidden)
+		utils.AssertJSONEq(t, w, `{"errors": ["You are not allowed to view the recording"]}`)
+	})
+
+	t.Run("should return own recording", func(t *testing.T) {
+		dbClient, c := newClient(t)
+		dbClient.EXPECT().GetTerminalRecording(gomock.Any(), "recording1").Return(recording, nil)
+
+		w := httptest.NewRecorder()
+		c.getRecording(w, newRequest(authContext.User{ID: "user@kobs.io"}))
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+		require.Equal(t, "application/x-asciicast", w.Header().Get("Content-Type"))
+		require.Equal(t, `attachment; filename="recording1.cast"`, w.Header().Get("Content-Disposition"))
+		require.Equal(t, `{"version":2}`, w.Body.String())
+	})
+
+	t.Run("should return recording of other user", func(t *testing.T) {
+		dbClient, c := newClient(t)
+		dbClient.EXPECT().GetTerminalRecording(gomock.Any(), "recording1").Return(recording, nil)
+
+		w := httptest.NewRecorder()
+		c.getRecording(w, newRequest(authContext.User{ID: "admin@kobs.io", Permissions: userv1.Permissions{Resources: []userv1.Resources{{Clusters: []string{"cluster1"}, Namespaces: []string{"default"}, Resources: []string{"recordings"}, Verbs: []string{"get"}}}}}))
+
+		utils.AssertStatusEq(t, w, http.StatusOK)
+		require.Equal(t, `{"version":2}`, w.Body.String())
+	})
+}
+
+func TestNewClient(t *testing.T) {
+	c := NewClient(Config{}, nil)
+	require.NotNil(t, c)
+	require.NotNil(t, c.Mount())
+}
@@ -0,0 +1,148 @@
+package catalog
+
+//go:generate mockgen -source=catalog.go -destination=./catalog_mock.go -package=catalog Client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
+	dashboardv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/dashboard/v1"
+	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/defaults"
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes/cluster/lint"
+	"github.com/kobsio/kobs/pkg/instrument/log"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Repository string `json:"repository"`
+	Branch     string `json:"branch"`
+	Directory  string `json:"directory"`
+	Namespace  string `json:"namespace"`
+}
+
+// Resources are the applications, dashboards, teams and users which were loaded from a catalog.
+type Resources struct {
+	Applications []applicationv1.ApplicationSpec
+	Dashboards   []dashboardv1.DashboardSpec
+	Teams        []teamv1.TeamSpec
+	Users        []userv1.UserSpec
+}
+
+// Client is the interface which must be implemented by a catalog client. A catalog is a local directory or a Git
+// repository with YAML files, which contain Application, Dashboard, Team and User CRs. The resources of a catalog are
+// tagged with the name of the catalog as cluster, so that they can be saved like the resources of a real cluster.
+type Client interface {
+	GetName() string
+	Load(ctx context.Context) (*Resources, error)
+}
+
+// client implements the Client interface. The mutex is used to ensure that the repository of the catalog is not
+// updated by multiple syncs at the same time.
+type client struct {
+	config Config
+	tracer trace.Tracer
+	mu     sync.Mutex
+}
+
+// GetName returns the name of the catalog, which is used as cluster name for all resources of the catalog.
+func (c *client) GetName() string {
+	return c.config.Name
+}
+
+// Load loads all resources from the catalog. When a repository is configured, the repository is cloned or updated
+// before the resources are loaded. Like for the resources from a cluster, the defaults for all resources are set, so
+// that the cluster, namespace and name of each resource is set.
+//
+// If a file of the catalog can not be parsed an error is returned, instead of returning the remaining resources. This
+// ensures that the resources from the invalid file are not deleted from the database until the file is fixed.
+func (c *client) Load(ctx context.Context) (*Resources, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ctx, span := c.tracer.Start(ctx, "catalog.Load")
+	span.SetAttributes(attribute.Key("catalog").String(c.config.Name))
+	defer span.End()
+
+	path := c.config.Path
+	if c.config.Repository != "" {
+		dir, err := c.checkout(ctx)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+
+		path = joinPath(dir, c.config.Path)
+	}
+
+	manifests, problems, err := lint.Load([]string{path}, c.config.Namespace)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			log.Warn(ctx, "Invalid manifest in catalog", zap.String("catalog", c.config.Name), zap.String("problem", problem.String()))
+		}
+
+		err := fmt.Errorf("found %d invalid manifests, first problem: %s", len(problems), problems[0].String())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	resources := &Resources{}
+	for _, manifest := range manifests {
+		if manifest.Name == "" {
+			log.Warn(ctx, "Skip manifest without name", zap.String("catalog", c.config.Name), zap.String("file", manifest.File), zap.String("kind", manifest.Kind))
+			continue
+		}
+
+		switch {
+		case manifest.Application != nil:
+			resources.Applications = append(resources.Applications, defaults.SetApplicationDefaults(*manifest.Application, c.config.Name, manifest.Namespace, manifest.Name))
+		case manifest.Dashboard != nil:
+			resources.Dashboards = append(resources.Dashboards, defaults.SetDashboardDefaults(*manifest.Dashboard, c.config.Name, manifest.Namespace, manifest.Name))
+		case manifest.Team != nil:
+			resources.Teams = append(resources.Teams, defaults.SetTeamDefaults(*manifest.Team, c.config.Name, manifest.Namespace, manifest.Name))
+		case manifest.User != nil:
+			resources.Users = append(resources.Users, defaults.SetUserDefaults(*manifest.User, c.config.Name, manifest.Namespace, manifest.Name))
+		}
+	}
+
+	return resources, nil
+}
+
+// NewClient returns a new catalog client. The name of the catalog and a path or repository are required. If no
+// namespace is configured, the resources without a namespace are placed in the "default" namespace.
+func NewClient(config Config) (Client, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	if config.Path == "" && config.Repository == "" {
+		return nil, fmt.Errorf("path or repository is required for catalog %s", config.Name)
+	}
+
+	if config.Namespace == "" {
+		config.Namespace = "default"
+	}
+
+	return &client{
+		config: config,
+		tracer: otel.Tracer("catalog"),
+	}, nil
+}
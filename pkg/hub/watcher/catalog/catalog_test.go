@@ -0,0 +1,152 @@
+package catalog
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testManifests = `---
+apiVersion: kobs.io/v1
+kind: Dashboard
+metadata:
+  name: resources
+spec:
+  rows:
+    - panels:
+        - title: Markdown
+          plugin:
+            type: core
+            name: markdown
+---
+apiVersion: kobs.io/v1
+kind: Team
+metadata:
+  name: team1
+  namespace: kobs
+spec:
+  id: team1@kobs.io
+  dashboards:
+    - title: Resources
+      namespace: default
+      name: resources
+`
+
+func TestLoad(t *testing.T) {
+	t.Run("should load resources from directory", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "manifests.yaml"), []byte(testManifests), 0644))
+
+		client, err := NewClient(Config{Name: "platform", Path: dir})
+		require.NoError(t, err)
+
+		resources, err := client.Load(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, resources.Applications)
+		require.Empty(t, resources.Users)
+
+		require.Equal(t, 1, len(resources.Dashboards))
+		require.Equal(t, "/cluster/platform/namespace/default/name/resources", resources.Dashboards[0].ID)
+		require.Equal(t, "platform", resources.Dashboards[0].Rows[0].Panels[0].Plugin.Cluster)
+
+		require.Equal(t, 1, len(resources.Teams))
+		require.Equal(t, "team1@kobs.io", resources.Teams[0].ID)
+		require.Equal(t, "platform", resources.Teams[0].Cluster)
+		require.Equal(t, "kobs", resources.Teams[0].Namespace)
+		require.Equal(t, "platform", resources.Teams[0].Dashboards[0].Cluster)
+	})
+
+	t.Run("should return error for invalid manifest", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "manifests.yaml"), []byte(testManifests), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.yaml"), []byte("apiVersion: kobs.io/v1\nkind: User\nmetadata:\n  name: user1\nspec:\n  teams: team1\n"), 0644))
+
+		client, err := NewClient(Config{Name: "platform", Path: dir})
+		require.NoError(t, err)
+
+		_, err = client.Load(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("should return error for missing directory", func(t *testing.T) {
+		client, err := NewClient(Config{Name: "platform", Path: filepath.Join(t.TempDir(), "missing")})
+		require.NoError(t, err)
+
+		_, err = client.Load(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("should load resources from repository", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git is not installed")
+		}
+
+		repository := t.TempDir()
+		git := func(args ...string) {
+			cmd := exec.Command("git", append([]string{"-c", "user.name=kobs", "-c", "user.email=kobs@kobs.io"}, args...)...)
+			cmd.Dir = repository
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(out))
+		}
+
+		require.NoError(t, os.MkdirAll(filepath.Join(repository, "catalog"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repository, "catalog", "manifests.yaml"), []byte(testManifests), 0644))
+		git("init", "--initial-branch", "main")
+		git("add", "-A")
+		git("commit", "-m", "Add manifests")
+
+		client, err := NewClient(Config{Name: "platform", Repository: "file://" + repository, Branch: "main", Path: "catalog", Directory: filepath.Join(t.TempDir(), "checkout")})
+		require.NoError(t, err)
+
+		resources, err := client.Load(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 1, len(resources.Dashboards))
+		require.Equal(t, 1, len(resources.Teams))
+
+		require.NoError(t, os.Remove(filepath.Join(repository, "catalog", "manifests.yaml")))
+		require.NoError(t, os.WriteFile(filepath.Join(repository, "catalog", "team.yaml"), []byte("apiVersion: kobs.io/v1\nkind: Team\nmetadata:\n  name: team2\nspec:\n  id: team2@kobs.io\n"), 0644))
+		git("add", "-A")
+		git("commit", "-m", "Update manifests")
+
+		resources, err = client.Load(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, resources.Dashboards)
+		require.Equal(t, 1, len(resources.Teams))
+		require.Equal(t, "team2@kobs.io", resources.Teams[0].ID)
+	})
+
+	t.Run("should return error for invalid repository", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git is not installed")
+		}
+
+		client, err := NewClient(Config{Name: "platform", Repository: "file://" + filepath.Join(t.TempDir(), "missing"), Directory: filepath.Join(t.TempDir(), "checkout")})
+		require.NoError(t, err)
+
+		_, err = client.Load(context.Background())
+		require.Error(t, err)
+	})
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := NewClient(Config{Path: "/catalog"})
+	require.Error(t, err)
+
+	_, err = NewClient(Config{Name: "platform"})
+	require.Error(t, err)
+
+	catalogClient, err := NewClient(Config{Name: "platform", Path: "/catalog"})
+	require.NoError(t, err)
+	require.Equal(t, "platform", catalogClient.GetName())
+	require.Equal(t, "default", catalogClient.(*client).config.Namespace)
+}
+
+func TestJoinPath(t *testing.T) {
+	require.Equal(t, "/tmp/repository", joinPath("/tmp/repository", ""))
+	require.Equal(t, "/tmp/repository/catalog", joinPath("/tmp/repository", "catalog"))
+	require.Equal(t, "/tmp/repository/etc", joinPath("/tmp/repository", "../../etc"))
+}
@@ -0,0 +1,84 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// checkout clones the repository of the catalog into the configured directory or updates the repository, when it was
+// already cloned. We only fetch the latest commit of the configured branch, because the history of the repository is
+// not needed. Local changes in the directory are discarded, so that the directory always contains the state of the
+// repository.
+func (c *client) checkout(ctx context.Context) (string, error) {
+	dir := c.config.Directory
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "kobs-catalogs", c.config.Name)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		if err := os.RemoveAll(dir); err != nil {
+			return "", err
+		}
+		if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+			return "", err
+		}
+
+		args := []string{"clone", "--depth", "1"}
+		if c.config.Branch != "" {
+			args = append(args, "--branch", c.config.Branch)
+		}
+		args = append(args, c.config.Repository, dir)
+
+		if err := runGit(ctx, "", args...); err != nil {
+			return "", err
+		}
+
+		return dir, nil
+	}
+
+	ref := "HEAD"
+	if c.config.Branch != "" {
+		ref = c.config.Branch
+	}
+
+	if err := runGit(ctx, dir, "fetch", "--depth", "1", "origin", ref); err != nil {
+		return "", err
+	}
+	if err := runGit(ctx, dir, "reset", "--hard", "FETCH_HEAD"); err != nil {
+		return "", err
+	}
+	if err := runGit(ctx, dir, "clean", "-fdx"); err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// runGit runs the git binary with the given arguments in the given directory. Interactive prompts for credentials are
+// disabled, so that a missing credential results in an error instead of blocking the sync. Credentials must be provided
+// via the repository url or the configuration of git.
+func runGit(ctx context.Context, dir string, args ...string) error {
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// joinPath joins the directory of the repository with the configured path. The path is cleaned before it is joined,
+// so that it can not point to a directory outside of the repository.
+func joinPath(dir, path string) string {
+	return filepath.Join(dir, filepath.Clean("/"+path))
+}
@@ -26,6 +26,12 @@ var (
 		Help:       "Latency of sync requests processed by the watcher, partitioned by status and resource.",
 		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.95: 0.005, 0.99: 0.001},
 	}, []string{"cluster", "status", "resource"})
+
+	leaderMetric = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "kobs",
+		Name:      "watcher_leader",
+		Help:      "Is 1 when the watcher is the leader and syncs the resources, otherwise it is 0.",
+	})
 )
 
 // instrument is a small helper function to generate metrics and logs for the watcher function. The helper is
@@ -0,0 +1,167 @@
+package watcher
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/kobsio/kobs/pkg/instrument/log"
+
+	"go.uber.org/zap"
+)
+
+// LeaseName is the name of the lease, which is used for the leader election between multiple replicas of the watcher.
+const LeaseName = "watcher"
+
+// errNotLeader is returned when a sync should save data in the database, but the watcher is not the leader anymore.
+var errNotLeader = errors.New("watcher is not the leader")
+
+// newIdentity returns the identity of the watcher replica for the leader election. The identity is the hostname (e.g.
+// the name of the Pod) with a random suffix, so that two watchers on the same host are getting a different identity.
+func newIdentity() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "watcher"
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return hostname
+	}
+
+	return hostname + "-" + hex.EncodeToString(suffix)
+}
+
+// isLeader returns true when the leader election is disabled or when this replica holds the lease.
+func (c *client) isLeader() bool {
+	return !c.config.LeaderElection || c.leader.Load()
+}
+
+// leaderContext returns the context for the current term of the leader. The context is canceled as soon as the watcher
+// loses the leadership, so that all running syncs are stopped. When the leader election is disabled, the context is
+// only canceled when the watcher is stopped.
+func (c *client) leaderContext() context.Context {
+	c.termMu.Lock()
+	defer c.termMu.Unlock()
+
+	if c.termCtx != nil {
+		return c.termCtx
+	}
+
+	return c.ctx
+}
+
+// ensureLeader returns an error when the provided context was canceled or when the watcher is not the leader anymore.
+// It must be called before data is saved in the database, so that a replica which lost the leadership doesn't
+// overwrite the data saved by the new leader.
+func (c *client) ensureLeader(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if !c.isLeader() {
+		return errNotLeader
+	}
+
+	return nil
+}
+
+// setTerm starts a new term when the watcher became the leader and cancels the context of the current term when the
+// watcher lost the leadership.
+func (c *client) setTerm(isLeader bool) {
+	c.termMu.Lock()
+	defer c.termMu.Unlock()
+
+	if c.termCancel != nil {
+		c.termCancel()
+	}
+
+	c.termCtx, c.termCancel = context.WithCancel(c.ctx)
+	if !isLeader {
+		c.termCancel()
+	}
+}
+
+// runLeaderElection tries to acquire or renew the lease every third of the configured lease duration, until the
+// watcher is stopped. This should be called in a new go routine.
+func (c *client) runLeaderElection() {
+	ticker := time.NewTicker(c.config.LeaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		c.electLeader()
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// electLeader tries to acquire or renew the lease. When the lease can not be renewed, because the database is not
+// available, we stay the leader until the lease expires, because no other replica can acquire the lease before.
+//
+// When the leadership changes, we notify the watch loop via the `leaderChanges` channel, so that the sync is started
+// immediately when we become the leader and stopped when we lose the leadership.
+func (c *client) electLeader() {
+	startTime := time.Now()
+
+	ctx, cancel := context.WithTimeout(c.ctx, c.config.LeaseDuration/3)
+	defer cancel()
+
+	var isLeader bool
+
+	lease, err := c.dbClient.AcquireLease(ctx, LeaseName, c.identity, c.config.LeaseDuration)
+	if err != nil {
+		log.Warn(ctx, "Failed to acquire lease", zap.Error(err), zap.String("identity", c.identity))
+		isLeader = c.leader.Load() && time.Now().Before(c.leaderUntil)
+	} else {
+		isLeader = lease.Holder == c.identity
+		if isLeader {
+			c.leaderUntil = startTime.Add(c.config.LeaseDuration)
+		}
+	}
+
+	if isLeader == c.leader.Load() {
+		return
+	}
+
+	c.leader.Store(isLeader)
+	c.setTerm(isLeader)
+
+	if isLeader {
+		leaderMetric.Set(1)
+		log.Info(ctx, "Watcher became the leader", zap.String("identity", c.identity))
+	} else {
+		leaderMetric.Set(0)
+		log.Info(ctx, "Watcher lost the leadership", zap.String("identity", c.identity))
+	}
+
+	select {
+	case c.leaderChanges <- struct{}{}:
+	default:
+	}
+}
+
+// releaseLease releases the lease when this replica is the leader, so that another replica can take over immediately
+// instead of waiting until the lease expires.
+func (c *client) releaseLease() {
+	if !c.config.LeaderElection || !c.leader.Load() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := c.dbClient.ReleaseLease(ctx, LeaseName, c.identity); err != nil {
+		log.Warn(ctx, "Failed to release lease", zap.Error(err), zap.String("identity", c.identity))
+	}
+
+	c.leader.Store(false)
+	c.setTerm(false)
+	leaderMetric.Set(0)
+}
@@ -4,11 +4,17 @@ package watcher
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
 	"github.com/kobsio/kobs/pkg/hub/clusters"
 	"github.com/kobsio/kobs/pkg/hub/clusters/cluster"
 	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/hub/watcher/catalog"
 	"github.com/kobsio/kobs/pkg/hub/watcher/worker"
 	"github.com/kobsio/kobs/pkg/instrument/log"
 
@@ -26,8 +32,14 @@ func (r task) Do() {
 }
 
 type Config struct {
-	Interval time.Duration `json:"interval" env:"INTERVAL" default:"300s" help:"Set the interval to sync all resources from the clusters to the hub."`
-	Workers  int64         `json:"workers" env:"WORKERS" default:"10" help:"The number of workers (goroutines) to spawn for the sync process."`
+	Interval       time.Duration    `json:"interval" env:"INTERVAL" default:"300s" help:"Set the interval to sync all resources from the clusters to the hub."`
+	Workers        int64            `json:"workers" env:"WORKERS" default:"10" help:"The number of workers (goroutines) to spawn for the sync process."`
+	Events         bool             `json:"events" env:"EVENTS" default:"true" help:"Watch the clusters for changes of applications, dashboards, teams and users, so that they are synced immediately instead of only in the configured interval."`
+	EventsInterval time.Duration    `json:"eventsInterval" env:"EVENTS_INTERVAL" default:"10s" help:"The time to wait before the connection to a cluster is reopened, when the events stream was closed."`
+	MaxFailures    int              `json:"maxFailures" env:"MAX_FAILURES" default:"3" help:"The number of consecutive failed syncs of a resource, after which the cluster is marked as unhealthy."`
+	LeaderElection bool             `json:"leaderElection" env:"LEADER_ELECTION" default:"false" help:"Enable the leader election, so that only one replica of the watcher syncs the resources at a time."`
+	LeaseDuration  time.Duration    `json:"leaseDuration" env:"LEASE_DURATION" default:"15s" help:"The duration of the lease for the leader election. The leader renews the lease every third of the duration."`
+	Catalogs       []catalog.Config `json:"catalogs" kong:"-"`
 }
 
 // Client is the interface which must be implemented by a watcher client.
@@ -39,38 +51,286 @@ type Client interface {
 // client implements the Client interface. It contains a http client which can be used to make the requests to the
 // clusters, an interval which defines the time between each sync with the clusters, a worker pool and a db client to
 // save the requested resources.
+//
+// When events are enabled the client also holds a context, which is canceled when the watcher is stopped, to close the
+// events streams to all clusters.
+//
+// When the leader election is enabled, the client only syncs the resources while it holds the lease, so that multiple
+// replicas of the watcher do not sync the same resources. All syncs are running with the context of the current term,
+// which is canceled when the lease is lost, and the leadership is checked again before data is saved.
+//
+// The client also counts the consecutive failed syncs for each cluster and resource, which are saved together with each
+// sync run in the database. The counters of clusters which were removed are pruned in each sync.
+//
+// Next to the clusters, the client also syncs the resources from the configured catalogs, which are saved with the name
+// of the catalog as cluster.
 type client struct {
-	interval       time.Duration
+	config         Config
 	workerPool     worker.Pool
 	clustersClient clusters.Client
 	dbClient       db.Client
 	tracer         trace.Tracer
+	ctx            context.Context
+	cancel         context.CancelFunc
+	failures       map[failureKey]int
+	failuresMu     sync.Mutex
+	clusters       map[string]watchedCluster
+	identity       string
+	leader         atomic.Bool
+	leaderUntil    time.Time
+	leaderChanges  chan struct{}
+	termCtx        context.Context
+	termCancel     context.CancelFunc
+	termMu         sync.Mutex
+	catalogs       []catalog.Client
+}
+
+// failureKey is the key for the counter of consecutive failed syncs of a resource in a cluster or catalog.
+type failureKey struct {
+	cluster  string
+	resource string
+}
+
+// watchedCluster is a cluster which is known by the watcher. It contains the client for the cluster and the cancel
+// function for the events stream of the cluster, so that the stream can be closed when the cluster is removed.
+type watchedCluster struct {
+	client cluster.Client
+	cancel context.CancelFunc
 }
 
 // Watch triggers the internal watch function in the specified interval. This should be called in a new go routine.
+//
+// If events are enabled, we also open an events stream to each cluster, so that changes of applications, dashboards,
+// teams and users are applied immediately. The periodic sync is still running, to reconcile all resources for which we
+// missed an event, e.g. because the stream was interrupted.
+//
+// When the leader election is enabled, the resources are only synced while the watcher is the leader. When the watcher
+// becomes the leader, it starts a sync immediately.
+//
+// Since clusters can be registered and removed at runtime, we also check the list of clusters in the configured events
+// interval, to open the events streams for new clusters and to clean up removed clusters.
 func (c *client) Watch() {
-	ticker := time.NewTicker(c.interval)
+	if c.config.LeaderElection {
+		go c.runLeaderElection()
+	}
+
+	c.reconcileClusters()
+
+	ticker := time.NewTicker(c.config.Interval)
 	defer ticker.Stop()
 
+	clustersTicker := time.NewTicker(c.config.EventsInterval)
+	defer clustersTicker.Stop()
+
 	for {
-		<-ticker.C
-		c.watch()
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			if c.isLeader() {
+				c.watch()
+			}
+		case <-clustersTicker.C:
+			c.reconcileClusters()
+		case <-c.leaderChanges:
+			c.reconcileClusters()
+			if c.isLeader() {
+				c.watch()
+			}
+		}
 	}
 }
 
-// Stop stops the worker pool of the watcher. If stopping the worker pool fails it returns an error.
+// reconcileClusters compares the clusters from the clusters client with the clusters known by the watcher. If the
+// watcher is not the leader, all events streams are closed. For new
+// clusters (or clusters with a new client, because the configuration was changed) we open the events stream. For
+// removed clusters we close the events stream and delete all data of the cluster from the database, so that we do not
+// serve stale data for a cluster which does not exist anymore.
+func (c *client) reconcileClusters() {
+	if !c.isLeader() {
+		for name, watched := range c.clusters {
+			watched.cancel()
+			delete(c.clusters, name)
+		}
+		return
+	}
+
+	current := make(map[string]cluster.Client)
+	for _, cl := range c.clustersClient.GetClusters() {
+		current[cl.GetName()] = cl
+	}
+
+	for name, watched := range c.clusters {
+		if cl, ok := current[name]; ok && cl == watched.client {
+			continue
+		}
+
+		watched.cancel()
+		delete(c.clusters, name)
+
+		if _, ok := current[name]; !ok {
+			log.Info(c.ctx, "Cluster was removed", zap.String("cluster", name))
+
+			ctx, cancel := context.WithTimeout(c.leaderContext(), 30*time.Second)
+			err := c.ensureLeader(ctx)
+			if err == nil {
+				err = c.dbClient.DeleteCluster(ctx, name)
+			}
+			if err != nil {
+				log.Warn(c.ctx, "Failed to delete data of removed cluster", zap.Error(err), zap.String("cluster", name))
+			}
+			cancel()
+		}
+	}
+
+	for name, cl := range current {
+		if _, ok := c.clusters[name]; ok {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(c.leaderContext())
+		c.clusters[name] = watchedCluster{client: cl, cancel: cancel}
+
+		if c.config.Events {
+			go c.watchEvents(ctx, cl)
+		}
+	}
+}
+
+// Stop stops the worker pool of the watcher, closes all events streams and releases the lease when the watcher is the
+// leader. If stopping the worker pool fails it returns an error.
 func (c *client) Stop() error {
+	c.cancel()
+	c.releaseLease()
 	return c.workerPool.Stop()
 }
 
+// watchEvents opens the events stream for the provided cluster and applies all received events. When the stream is
+// closed, we wait for the configured events interval and reopen it, until the provided context is canceled, because
+// the watcher was stopped or the cluster was removed.
+func (c *client) watchEvents(ctx context.Context, cl cluster.Client) {
+	for {
+		events := make(chan kubernetes.Event)
+		errs := make(chan error, 1)
+
+		go func() {
+			errs <- cl.StreamEvents(ctx, events)
+		}()
+
+	stream:
+		for {
+			select {
+			case event := <-events:
+				c.handleEvent(cl.GetName(), event)
+			case err := <-errs:
+				if ctx.Err() == nil {
+					log.Warn(ctx, "Events stream was closed", zap.Error(err), zap.String("cluster", cl.GetName()))
+				}
+				break stream
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.config.EventsInterval):
+		}
+	}
+}
+
+// handleEvent applies a single event from the provided cluster to the database. For add and update events the resource
+// is saved and for delete events the resource is removed from the database. For applications we also have to save the
+// tags and the topology, like it is done in the periodic sync.
+func (c *client) handleEvent(clusterName string, event kubernetes.Event) {
+	startTime := time.Now()
+	ctx, span := c.tracer.Start(c.leaderContext(), "watcher.events")
+	span.SetAttributes(attribute.Key("cluster").String(clusterName))
+	span.SetAttributes(attribute.Key("resource").String(event.Resource))
+	span.SetAttributes(attribute.Key("type").String(string(event.Type)))
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(log.ContextWithValue(ctx, zap.Time("startTime", startTime), zap.String("type", string(event.Type))), 30*time.Second)
+	defer cancel()
+
+	if err := c.ensureLeader(ctx); err != nil {
+		log.Debug(ctx, "Event was not applied", zap.Error(err), zap.String("cluster", clusterName))
+		return
+	}
+
+	err := c.applyEvent(ctx, event)
+	instrument(ctx, span, clusterName, event.Resource, err, 1, startTime)
+}
+
+// applyEvent saves or deletes the resource from the provided event in the database.
+func (c *client) applyEvent(ctx context.Context, event kubernetes.Event) error {
+	switch event.Resource {
+	case "applications":
+		if event.Application == nil {
+			return fmt.Errorf("application is missing")
+		}
+
+		if event.Type == kubernetes.EventTypeDelete {
+			return c.dbClient.DeleteApplication(ctx, event.Application.ID)
+		}
+
+		if err := c.dbClient.SaveApplication(ctx, event.Application); err != nil {
+			return err
+		}
+
+		if err := c.dbClient.SaveTags(ctx, []applicationv1.ApplicationSpec{*event.Application}); err != nil {
+			return err
+		}
+
+		return c.dbClient.SaveApplicationTopology(ctx, event.Application)
+	case "dashboards":
+		if event.Dashboard == nil {
+			return fmt.Errorf("dashboard is missing")
+		}
+
+		if event.Type == kubernetes.EventTypeDelete {
+			return c.dbClient.DeleteDashboard(ctx, event.Dashboard.ID)
+		}
+
+		return c.dbClient.SaveDashboard(ctx, event.Dashboard)
+	case "teams":
+		if event.Team == nil {
+			return fmt.Errorf("team is missing")
+		}
+
+		if event.Type == kubernetes.EventTypeDelete {
+			return c.dbClient.DeleteTeam(ctx, event.Team.ID)
+		}
+
+		return c.dbClient.SaveTeam(ctx, event.Team)
+	case "users":
+		if event.User == nil {
+			return fmt.Errorf("user is missing")
+		}
+
+		if event.Type == kubernetes.EventTypeDelete {
+			return c.dbClient.DeleteUser(ctx, event.User.ID)
+		}
+
+		return c.dbClient.SaveUser(ctx, event.User)
+	default:
+		return fmt.Errorf("invalid resource %s", event.Resource)
+	}
+}
+
 // watch is the internal watch method of the watcher. It loops through all configured clusters and adds a task for
 // each resource (plugins, applications, dashboards, teams and users) to the worker pool.
+//
+// The tasks are using the context of the current term, so that queued and running tasks are stopped when the watcher
+// loses the leadership. Before a task saves data in the database, it checks that the watcher is still the leader.
 func (c *client) watch() {
 	startTime := time.Now()
-	ctx, span := c.tracer.Start(context.Background(), "watcher")
+	ctx, span := c.tracer.Start(c.leaderContext(), "watcher")
 	defer span.End()
 
-	for _, cl := range c.clustersClient.GetClusters() {
+	clusters := c.clustersClient.GetClusters()
+	c.pruneFailures(clusters)
+
+	for _, cl := range clusters {
 		go func(cl cluster.Client) {
 			c.workerPool.RunTask(task(func() {
 				ctx, span := c.tracer.Start(ctx, "watcher.plugins")
@@ -82,17 +342,22 @@ func (c *client) watch() {
 
 				plugins, err := cl.GetPlugins(ctx)
 				if err != nil {
-					instrument(ctx, span, cl.GetName(), "plugins", err, len(plugins), startTime)
+					c.recordSync(ctx, span, cl.GetName(), "plugins", err, len(plugins), startTime)
+					return
+				}
+
+				if err := c.ensureLeader(ctx); err != nil {
+					c.recordSync(ctx, span, cl.GetName(), "plugins", err, 0, startTime)
 					return
 				}
 
 				err = c.dbClient.SavePlugins(ctx, cl.GetName(), plugins)
 				if err != nil {
-					instrument(ctx, span, cl.GetName(), "plugins", err, len(plugins), startTime)
+					c.recordSync(ctx, span, cl.GetName(), "plugins", err, len(plugins), startTime)
 					return
 				}
 
-				instrument(ctx, span, cl.GetName(), "plugins", nil, len(plugins), startTime)
+				c.recordSync(ctx, span, cl.GetName(), "plugins", nil, len(plugins), startTime)
 			}))
 		}(cl)
 
@@ -107,17 +372,22 @@ func (c *client) watch() {
 
 				namespaces, err := cl.GetNamespaces(ctx)
 				if err != nil {
-					instrument(ctx, span, cl.GetName(), "namespaces", err, len(namespaces), startTime)
+					c.recordSync(ctx, span, cl.GetName(), "namespaces", err, len(namespaces), startTime)
+					return
+				}
+
+				if err := c.ensureLeader(ctx); err != nil {
+					c.recordSync(ctx, span, cl.GetName(), "namespaces", err, 0, startTime)
 					return
 				}
 
 				err = c.dbClient.SaveNamespaces(ctx, cl.GetName(), namespaces)
 				if err != nil {
-					instrument(ctx, span, cl.GetName(), "namespaces", err, len(namespaces), startTime)
+					c.recordSync(ctx, span, cl.GetName(), "namespaces", err, len(namespaces), startTime)
 					return
 				}
 
-				instrument(ctx, span, cl.GetName(), "namespaces", nil, len(namespaces), startTime)
+				c.recordSync(ctx, span, cl.GetName(), "namespaces", nil, len(namespaces), startTime)
 			}))
 		}(cl)
 
@@ -132,17 +402,22 @@ func (c *client) watch() {
 
 				crds, err := cl.GetCRDs(ctx)
 				if err != nil {
-					instrument(ctx, span, cl.GetName(), "crds", err, len(crds), startTime)
+					c.recordSync(ctx, span, cl.GetName(), "crds", err, len(crds), startTime)
+					return
+				}
+
+				if err := c.ensureLeader(ctx); err != nil {
+					c.recordSync(ctx, span, cl.GetName(), "crds", err, 0, startTime)
 					return
 				}
 
 				err = c.dbClient.SaveCRDs(ctx, crds)
 				if err != nil {
-					instrument(ctx, span, cl.GetName(), "crds", err, len(crds), startTime)
+					c.recordSync(ctx, span, cl.GetName(), "crds", err, len(crds), startTime)
 					return
 				}
 
-				instrument(ctx, span, cl.GetName(), "crds", nil, len(crds), startTime)
+				c.recordSync(ctx, span, cl.GetName(), "crds", nil, len(crds), startTime)
 			}))
 		}(cl)
 
@@ -157,29 +432,34 @@ func (c *client) watch() {
 
 				applications, err := cl.GetApplications(ctx)
 				if err != nil {
-					instrument(ctx, span, cl.GetName(), "applications", err, len(applications), startTime)
+					c.recordSync(ctx, span, cl.GetName(), "applications", err, len(applications), startTime)
+					return
+				}
+
+				if err := c.ensureLeader(ctx); err != nil {
+					c.recordSync(ctx, span, cl.GetName(), "applications", err, 0, startTime)
 					return
 				}
 
 				err = c.dbClient.SaveApplications(ctx, cl.GetName(), applications)
 				if err != nil {
-					instrument(ctx, span, cl.GetName(), "applications", err, len(applications), startTime)
+					c.recordSync(ctx, span, cl.GetName(), "applications", err, len(applications), startTime)
 					return
 				}
 
 				err = c.dbClient.SaveTags(ctx, applications)
 				if err != nil {
-					instrument(ctx, span, cl.GetName(), "tags", err, len(applications), startTime)
+					c.recordSync(ctx, span, cl.GetName(), "applications", err, len(applications), startTime)
 					return
 				}
 
 				err = c.dbClient.SaveTopology(ctx, cl.GetName(), applications)
 				if err != nil {
-					instrument(ctx, span, cl.GetName(), "tags", err, len(applications), startTime)
+					c.recordSync(ctx, span, cl.GetName(), "applications", err, len(applications), startTime)
 					return
 				}
 
-				instrument(ctx, span, cl.GetName(), "applications", nil, len(applications), startTime)
+				c.recordSync(ctx, span, cl.GetName(), "applications", nil, len(applications), startTime)
 			}))
 		}(cl)
 
@@ -194,17 +474,22 @@ func (c *client) watch() {
 
 				dashboards, err := cl.GetDashboards(ctx)
 				if err != nil {
-					instrument(ctx, span, cl.GetName(), "dashboards", err, len(dashboards), startTime)
+					c.recordSync(ctx, span, cl.GetName(), "dashboards", err, len(dashboards), startTime)
+					return
+				}
+
+				if err := c.ensureLeader(ctx); err != nil {
+					c.recordSync(ctx, span, cl.GetName(), "dashboards", err, 0, startTime)
 					return
 				}
 
 				err = c.dbClient.SaveDashboards(ctx, cl.GetName(), dashboards)
 				if err != nil {
-					instrument(ctx, span, cl.GetName(), "dashboards", err, len(dashboards), startTime)
+					c.recordSync(ctx, span, cl.GetName(), "dashboards", err, len(dashboards), startTime)
 					return
 				}
 
-				instrument(ctx, span, cl.GetName(), "dashboards", nil, len(dashboards), startTime)
+				c.recordSync(ctx, span, cl.GetName(), "dashboards", nil, len(dashboards), startTime)
 			}))
 		}(cl)
 
@@ -219,17 +504,22 @@ func (c *client) watch() {
 
 				teams, err := cl.GetTeams(ctx)
 				if err != nil {
-					instrument(ctx, span, cl.GetName(), "teams", err, len(teams), startTime)
+					c.recordSync(ctx, span, cl.GetName(), "teams", err, len(teams), startTime)
+					return
+				}
+
+				if err := c.ensureLeader(ctx); err != nil {
+					c.recordSync(ctx, span, cl.GetName(), "teams", err, 0, startTime)
 					return
 				}
 
 				err = c.dbClient.SaveTeams(ctx, cl.GetName(), teams)
 				if err != nil {
-					instrument(ctx, span, cl.GetName(), "teams", err, len(teams), startTime)
+					c.recordSync(ctx, span, cl.GetName(), "teams", err, len(teams), startTime)
 					return
 				}
 
-				instrument(ctx, span, cl.GetName(), "teams", nil, len(teams), startTime)
+				c.recordSync(ctx, span, cl.GetName(), "teams", nil, len(teams), startTime)
 			}))
 		}(cl)
 
@@ -244,39 +534,210 @@ func (c *client) watch() {
 
 				users, err := cl.GetUsers(ctx)
 				if err != nil {
-					instrument(ctx, span, cl.GetName(), "users", err, len(users), startTime)
+					c.recordSync(ctx, span, cl.GetName(), "users", err, len(users), startTime)
+					return
+				}
+
+				if err := c.ensureLeader(ctx); err != nil {
+					c.recordSync(ctx, span, cl.GetName(), "users", err, 0, startTime)
 					return
 				}
 
 				err = c.dbClient.SaveUsers(ctx, cl.GetName(), users)
 				if err != nil {
-					instrument(ctx, span, cl.GetName(), "users", err, len(users), startTime)
+					c.recordSync(ctx, span, cl.GetName(), "users", err, len(users), startTime)
 					return
 				}
 
-				instrument(ctx, span, cl.GetName(), "users", nil, len(users), startTime)
+				c.recordSync(ctx, span, cl.GetName(), "users", nil, len(users), startTime)
 			}))
 		}(cl)
 	}
+
+	for _, cat := range c.catalogs {
+		go func(cat catalog.Client) {
+			c.workerPool.RunTask(task(func() {
+				c.syncCatalog(ctx, cat, startTime)
+			}))
+		}(cat)
+	}
+}
+
+// syncCatalog loads all resources from the provided catalog and saves them in the database. The resources are saved
+// with the name of the catalog as cluster, so that the same save and delete semantics as for the resources of a
+// cluster are applied. If the resources can not be loaded, the sync for all resources is marked as failed.
+//
+// A catalog is not synced, when a cluster with the same name exists, because the resources of the cluster would be
+// overwritten by the resources of the catalog and vice versa.
+func (c *client) syncCatalog(ctx context.Context, cat catalog.Client, startTime time.Time) {
+	ctx, span := c.tracer.Start(ctx, "watcher.catalog")
+	span.SetAttributes(attribute.Key("catalog").String(cat.GetName()))
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(log.ContextWithValue(ctx, zap.Time("startTime", startTime)), 5*time.Minute)
+	defer cancel()
+
+	var err error
+	var resources *catalog.Resources
+
+	if c.clustersClient.GetCluster(cat.GetName()) != nil {
+		err = fmt.Errorf("catalog name %s is already used by a cluster", cat.GetName())
+	} else {
+		resources, err = cat.Load(ctx)
+	}
+
+	if err == nil {
+		err = c.ensureLeader(ctx)
+	}
+
+	if err != nil {
+		for _, resource := range []string{"applications", "dashboards", "teams", "users"} {
+			c.recordSync(ctx, span, cat.GetName(), resource, err, 0, startTime)
+		}
+		return
+	}
+
+	err = c.dbClient.SaveApplications(ctx, cat.GetName(), resources.Applications)
+	if err == nil {
+		err = c.dbClient.SaveTags(ctx, resources.Applications)
+	}
+	if err == nil {
+		err = c.dbClient.SaveTopology(ctx, cat.GetName(), resources.Applications)
+	}
+	c.recordSync(ctx, span, cat.GetName(), "applications", err, len(resources.Applications), startTime)
+
+	err = c.dbClient.SaveDashboards(ctx, cat.GetName(), resources.Dashboards)
+	c.recordSync(ctx, span, cat.GetName(), "dashboards", err, len(resources.Dashboards), startTime)
+
+	err = c.dbClient.SaveTeams(ctx, cat.GetName(), resources.Teams)
+	c.recordSync(ctx, span, cat.GetName(), "teams", err, len(resources.Teams), startTime)
+
+	err = c.dbClient.SaveUsers(ctx, cat.GetName(), resources.Users)
+	c.recordSync(ctx, span, cat.GetName(), "users", err, len(resources.Users), startTime)
+}
+
+// pruneFailures removes the counters of consecutive failed syncs for all clusters which are not in the provided list of
+// clusters and are not a catalog anymore, so that the counters of removed clusters are not kept forever.
+func (c *client) pruneFailures(clusters []cluster.Client) {
+	names := make(map[string]bool)
+	for _, cl := range clusters {
+		names[cl.GetName()] = true
+	}
+	for _, cat := range c.catalogs {
+		names[cat.GetName()] = true
+	}
+
+	c.failuresMu.Lock()
+	defer c.failuresMu.Unlock()
+
+	for key := range c.failures {
+		if !names[key.cluster] {
+			delete(c.failures, key)
+		}
+	}
+}
+
+// recordSync generates the metrics and logs for a sync via the instrument helper function and saves the sync run in
+// the database, so that we have a history of all syncs for each cluster and resource.
+//
+// We count the consecutive failures for each cluster and resource. When the number of consecutive failures reaches the
+// configured maximum, the sync run is marked as unhealthy, so that the data for the resource can be flagged as stale.
+//
+// When the watcher is not the leader anymore, the sync run is not saved and the failures are not counted, because the
+// sync was stopped and the new leader is responsible for the sync.
+func (c *client) recordSync(ctx context.Context, span trace.Span, cluster, resource string, err error, length int, startTime time.Time) {
+	if !c.isLeader() {
+		log.Debug(ctx, "Sync was stopped, because the watcher is not the leader anymore", zap.String("cluster", cluster), zap.String("resource", resource))
+		return
+	}
+
+	instrument(ctx, span, cluster, resource, err, length, startTime)
+
+	c.failuresMu.Lock()
+	key := failureKey{cluster: cluster, resource: resource}
+	if err != nil {
+		c.failures[key] = c.failures[key] + 1
+	} else {
+		c.failures[key] = 0
+	}
+	failures := c.failures[key]
+	c.failuresMu.Unlock()
+
+	run := &db.SyncRun{
+		ID:        fmt.Sprintf("/cluster/%s/resource/%s/timestamp/%d", cluster, resource, startTime.UnixNano()),
+		Cluster:   cluster,
+		Resource:  resource,
+		Timestamp: startTime.UTC(),
+		Duration:  time.Since(startTime).Milliseconds(),
+		Count:     length,
+		Failures:  failures,
+		Healthy:   c.config.MaxFailures <= 0 || failures < c.config.MaxFailures,
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	if c.config.MaxFailures > 0 && failures == c.config.MaxFailures {
+		log.Error(ctx, "Cluster is unhealthy", zap.String("cluster", cluster), zap.String("resource", resource), zap.Int("failures", failures))
+	}
+
+	// The sync run is also saved when the context of the sync was canceled, e.g. because the sync took longer than
+	// the configured timeout. Therefore we have to use a new context without the cancelation of the sync context.
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	if err := c.dbClient.SaveSyncRun(saveCtx, run); err != nil {
+		log.Warn(ctx, "Failed to save sync run", zap.Error(err), zap.String("cluster", cluster), zap.String("resource", resource))
+	}
 }
 
 // NewClient returns a new watcher. To create the watcher a interval, the number of workers in the worker pool, the
-// clusters and a database client is needed.
+// clusters and a database client is needed. The names of the configured catalogs must be unique.
 func NewClient(config Config, clustersClient clusters.Client, dbClient db.Client) (Client, error) {
+	var catalogs []catalog.Client
+	catalogNames := make(map[string]bool)
+	for _, catalogConfig := range config.Catalogs {
+		if catalogNames[catalogConfig.Name] {
+			return nil, fmt.Errorf("duplicate catalog name %s", catalogConfig.Name)
+		}
+		catalogNames[catalogConfig.Name] = true
+
+		cat, err := catalog.NewClient(catalogConfig)
+		if err != nil {
+			return nil, err
+		}
+		catalogs = append(catalogs, cat)
+	}
+
 	workerPool, err := worker.NewPool(config.Workers)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+
 	client := &client{
-		interval:       config.Interval,
+		config:         config,
 		workerPool:     workerPool,
 		clustersClient: clustersClient,
 		dbClient:       dbClient,
 		tracer:         otel.Tracer("watcher"),
+		ctx:            ctx,
+		cancel:         cancel,
+		failures:       make(map[failureKey]int),
+		clusters:       make(map[string]watchedCluster),
+		identity:       newIdentity(),
+		leaderChanges:  make(chan struct{}, 1),
+		catalogs:       catalogs,
 	}
 
-	go client.watch()
+	// When the leader election is enabled, the first sync is started as soon as the watcher becomes the leader. Until
+	// then the context for the syncs is canceled.
+	if config.LeaderElection {
+		client.setTerm(false)
+	} else {
+		go client.watch()
+	}
 
 	return client, nil
 }
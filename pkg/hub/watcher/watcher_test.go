@@ -0,0 +1,374 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kobsio/kobs/pkg/cluster/kubernetes"
+	applicationv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/application/v1"
+	dashboardv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/dashboard/v1"
+	teamv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/team/v1"
+	userv1 "github.com/kobsio/kobs/pkg/cluster/kubernetes/apis/user/v1"
+	"github.com/kobsio/kobs/pkg/hub/clusters"
+	"github.com/kobsio/kobs/pkg/hub/clusters/cluster"
+	"github.com/kobsio/kobs/pkg/hub/db"
+	"github.com/kobsio/kobs/pkg/hub/watcher/catalog"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestApplyEvent(t *testing.T) {
+	var newClient = func(t *testing.T) (*client, *db.MockClient) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		return &client{dbClient: dbClient}, dbClient
+	}
+
+	t.Run("should save application, tags and topology", func(t *testing.T) {
+		application := &applicationv1.ApplicationSpec{ID: "application1"}
+
+		client, dbClient := newClient(t)
+		dbClient.EXPECT().SaveApplication(gomock.Any(), application).Return(nil)
+		dbClient.EXPECT().SaveTags(gomock.Any(), []applicationv1.ApplicationSpec{*application}).Return(nil)
+		dbClient.EXPECT().SaveApplicationTopology(gomock.Any(), application).Return(nil)
+
+		err := client.applyEvent(context.Background(), kubernetes.Event{Type: kubernetes.EventTypeAdd, Resource: "applications", Application: application})
+		require.NoError(t, err)
+	})
+
+	t.Run("should return error when saving application fails", func(t *testing.T) {
+		application := &applicationv1.ApplicationSpec{ID: "application1"}
+
+		client, dbClient := newClient(t)
+		dbClient.EXPECT().SaveApplication(gomock.Any(), application).Return(fmt.Errorf("unexpected error"))
+
+		err := client.applyEvent(context.Background(), kubernetes.Event{Type: kubernetes.EventTypeUpdate, Resource: "applications", Application: application})
+		require.Error(t, err)
+	})
+
+	t.Run("should delete application", func(t *testing.T) {
+		client, dbClient := newClient(t)
+		dbClient.EXPECT().DeleteApplication(gomock.Any(), "application1").Return(nil)
+
+		err := client.applyEvent(context.Background(), kubernetes.Event{Type: kubernetes.EventTypeDelete, Resource: "applications", Application: &applicationv1.ApplicationSpec{ID: "application1"}})
+		require.NoError(t, err)
+	})
+
+	t.Run("should save and delete dashboard", func(t *testing.T) {
+		dashboard := &dashboardv1.DashboardSpec{ID: "dashboard1"}
+
+		client, dbClient := newClient(t)
+		dbClient.EXPECT().SaveDashboard(gomock.Any(), dashboard).Return(nil)
+		dbClient.EXPECT().DeleteDashboard(gomock.Any(), "dashboard1").Return(nil)
+
+		require.NoError(t, client.applyEvent(context.Background(), kubernetes.Event{Type: kubernetes.EventTypeAdd, Resource: "dashboards", Dashboard: dashboard}))
+		require.NoError(t, client.applyEvent(context.Background(), kubernetes.Event{Type: kubernetes.EventTypeDelete, Resource: "dashboards", Dashboard: dashboard}))
+	})
+
+	t.Run("should save and delete team", func(t *testing.T) {
+		team := &teamv1.TeamSpec{ID: "team1"}
+
+		client, dbClient := newClient(t)
+		dbClient.EXPECT().SaveTeam(gomock.Any(), team).Return(nil)
+		dbClient.EXPECT().DeleteTeam(gomock.Any(), "team1").Return(nil)
+
+		require.NoError(t, client.applyEvent(context.Background(), kubernetes.Event{Type: kubernetes.EventTypeAdd, Resource: "teams", Team: team}))
+		require.NoError(t, client.applyEvent(context.Background(), kubernetes.Event{Type: kubernetes.EventTypeDelete, Resource: "teams", Team: team}))
+	})
+
+	t.Run("should save and delete user", func(t *testing.T) {
+		user := &userv1.UserSpec{ID: "user1"}
+
+		client, dbClient := newClient(t)
+		dbClient.EXPECT().SaveUser(gomock.Any(), user).Return(nil)
+		dbClient.EXPECT().DeleteUser(gomock.Any(), "user1").Return(nil)
+
+		require.NoError(t, client.applyEvent(context.Background(), kubernetes.Event{Type: kubernetes.EventTypeAdd, Resource: "users", User: user}))
+		require.NoError(t, client.applyEvent(context.Background(), kubernetes.Event{Type: kubernetes.EventTypeDelete, Resource: "users", User: user}))
+	})
+
+	t.Run("should return error for missing spec", func(t *testing.T) {
+		client, _ := newClient(t)
+
+		for _, resource := range []string{"applications", "dashboards", "teams", "users"} {
+			require.Error(t, client.applyEvent(context.Background(), kubernetes.Event{Type: kubernetes.EventTypeAdd, Resource: resource}))
+		}
+	})
+
+	t.Run("should return error for invalid resource", func(t *testing.T) {
+		client, _ := newClient(t)
+		require.Error(t, client.applyEvent(context.Background(), kubernetes.Event{Type: kubernetes.EventTypeAdd, Resource: "foo"}))
+	})
+}
+
+func TestRecordSync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dbClient := db.NewMockClient(ctrl)
+	client := &client{config: Config{MaxFailures: 2}, dbClient: dbClient, failures: make(map[failureKey]int)}
+
+	var runs []*db.SyncRun
+	dbClient.EXPECT().SaveSyncRun(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, run *db.SyncRun) error {
+		runs = append(runs, run)
+		return nil
+	}).Times(4)
+
+	for _, err := range []error{fmt.Errorf("unexpected error"), fmt.Errorf("unexpected error"), nil} {
+		ctx, span := otel.Tracer("watcher").Start(context.Background(), "watcher")
+		client.recordSync(ctx, span, "cluster1", "applications", err, 1, time.Now())
+	}
+
+	ctx, span := otel.Tracer("watcher").Start(context.Background(), "watcher")
+	client.recordSync(ctx, span, "cluster1", "teams", nil, 5, time.Now())
+
+	require.Equal(t, 4, len(runs))
+
+	require.Equal(t, "cluster1", runs[0].Cluster)
+	require.Equal(t, "applications", runs[0].Resource)
+	require.Equal(t, "unexpected error", runs[0].Error)
+	require.Equal(t, 1, runs[0].Failures)
+	require.True(t, runs[0].Healthy)
+
+	require.Equal(t, 2, runs[1].Failures)
+	require.False(t, runs[1].Healthy)
+
+	require.Equal(t, "", runs[2].Error)
+	require.Equal(t, 0, runs[2].Failures)
+	require.True(t, runs[2].Healthy)
+
+	require.Equal(t, "teams", runs[3].Resource)
+	require.Equal(t, 5, runs[3].Count)
+	require.True(t, runs[3].Healthy)
+}
+
+func TestRecordSyncWithoutLeadership(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dbClient := db.NewMockClient(ctrl)
+	client := &client{config: Config{LeaderElection: true}, dbClient: dbClient, failures: make(map[failureKey]int)}
+
+	ctx, span := otel.Tracer("watcher").Start(context.Background(), "watcher")
+	client.recordSync(ctx, span, "cluster1", "applications", errNotLeader, 0, time.Now())
+	require.Empty(t, client.failures)
+}
+
+func TestPruneFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cluster1 := cluster.NewMockClient(ctrl)
+	cluster1.EXPECT().GetName().Return("cluster1").AnyTimes()
+	catalogClient := catalog.NewMockClient(ctrl)
+	catalogClient.EXPECT().GetName().Return("platform").AnyTimes()
+
+	client := &client{catalogs: []catalog.Client{catalogClient}, failures: map[failureKey]int{
+		{cluster: "cluster1", resource: "applications"}: 1,
+		{cluster: "cluster2", resource: "applications"}: 2,
+		{cluster: "platform", resource: "teams"}:        3,
+	}}
+
+	client.pruneFailures([]cluster.Client{cluster1})
+	require.Equal(t, map[failureKey]int{
+		{cluster: "cluster1", resource: "applications"}: 1,
+		{cluster: "platform", resource: "teams"}:        3,
+	}, client.failures)
+}
+
+func TestReconcileClusters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dbClient := db.NewMockClient(ctrl)
+	clustersClient := clusters.NewMockClient(ctrl)
+	cluster1 := cluster.NewMockClient(ctrl)
+	cluster1.EXPECT().GetName().Return("cluster1").AnyTimes()
+	cluster2 := cluster.NewMockClient(ctrl)
+	cluster2.EXPECT().GetName().Return("cluster2").AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &client{dbClient: dbClient, clustersClient: clustersClient, ctx: ctx, clusters: make(map[string]watchedCluster)}
+
+	clustersClient.EXPECT().GetClusters().Return([]cluster.Client{cluster1, cluster2})
+	client.reconcileClusters()
+	require.Equal(t, 2, len(client.clusters))
+
+	clustersClient.EXPECT().GetClusters().Return([]cluster.Client{cluster1})
+	dbClient.EXPECT().DeleteCluster(gomock.Any(), "cluster2").Return(nil)
+	client.reconcileClusters()
+	require.Equal(t, 1, len(client.clusters))
+	require.Equal(t, cluster1, client.clusters["cluster1"].client)
+}
+
+func TestElectLeader(t *testing.T) {
+	var newClient = func(t *testing.T) (*client, *db.MockClient) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+
+		return &client{config: Config{LeaderElection: true, LeaseDuration: 15 * time.Second}, dbClient: dbClient, ctx: context.Background(), identity: "watcher-1", leaderChanges: make(chan struct{}, 1)}, dbClient
+	}
+
+	t.Run("should become leader", func(t *testing.T) {
+		client, dbClient := newClient(t)
+		dbClient.EXPECT().AcquireLease(gomock.Any(), LeaseName, "watcher-1", 15*time.Second).Return(&db.Lease{Name: LeaseName, Holder: "watcher-1"}, nil)
+
+		client.electLeader()
+		require.True(t, client.isLeader())
+		require.Equal(t, 1, len(client.leaderChanges))
+	})
+
+	t.Run("should not become leader when lease is held by another replica", func(t *testing.T) {
+		client, dbClient := newClient(t)
+		dbClient.EXPECT().AcquireLease(gomock.Any(), LeaseName, "watcher-1", 15*time.Second).Return(&db.Lease{Name: LeaseName, Holder: "watcher-2"}, nil)
+
+		client.electLeader()
+		require.False(t, client.isLeader())
+		require.Equal(t, 0, len(client.leaderChanges))
+	})
+
+	t.Run("should stay leader until lease expires when lease can not be renewed", func(t *testing.T) {
+		client, dbClient := newClient(t)
+		dbClient.EXPECT().AcquireLease(gomock.Any(), LeaseName, "watcher-1", 15*time.Second).Return(&db.Lease{Name: LeaseName, Holder: "watcher-1"}, nil)
+		dbClient.EXPECT().AcquireLease(gomock.Any(), LeaseName, "watcher-1", 15*time.Second).Return(nil, fmt.Errorf("unexpected error"))
+
+		client.electLeader()
+		client.electLeader()
+		require.True(t, client.isLeader())
+
+		client.leaderUntil = time.Now().Add(-1 * time.Second)
+		dbClient.EXPECT().AcquireLease(gomock.Any(), LeaseName, "watcher-1", 15*time.Second).Return(nil, fmt.Errorf("unexpected error"))
+
+		client.electLeader()
+		require.False(t, client.isLeader())
+	})
+
+	t.Run("should release lease", func(t *testing.T) {
+		client, dbClient := newClient(t)
+		dbClient.EXPECT().AcquireLease(gomock.Any(), LeaseName, "watcher-1", 15*time.Second).Return(&db.Lease{Name: LeaseName, Holder: "watcher-1"}, nil)
+		dbClient.EXPECT().ReleaseLease(gomock.Any(), LeaseName, "watcher-1").Return(nil)
+
+		client.electLeader()
+		client.releaseLease()
+		require.False(t, client.isLeader())
+		require.Error(t, client.leaderContext().Err())
+	})
+
+	t.Run("should cancel context of the term when leadership is lost", func(t *testing.T) {
+		client, dbClient := newClient(t)
+		dbClient.EXPECT().AcquireLease(gomock.Any(), LeaseName, "watcher-1", 15*time.Second).Return(&db.Lease{Name: LeaseName, Holder: "watcher-1"}, nil)
+		dbClient.EXPECT().AcquireLease(gomock.Any(), LeaseName, "watcher-1", 15*time.Second).Return(&db.Lease{Name: LeaseName, Holder: "watcher-2"}, nil)
+
+		client.electLeader()
+		ctx := client.leaderContext()
+		require.NoError(t, client.ensureLeader(ctx))
+
+		client.electLeader()
+		require.False(t, client.isLeader())
+		require.ErrorIs(t, ctx.Err(), context.Canceled)
+		require.Error(t, client.ensureLeader(ctx))
+		require.ErrorIs(t, client.ensureLeader(context.Background()), errNotLeader)
+	})
+
+	t.Run("should always be leader when leader election is disabled", func(t *testing.T) {
+		client, _ := newClient(t)
+		client.config.LeaderElection = false
+		require.True(t, client.isLeader())
+	})
+}
+
+func TestSyncCatalog(t *testing.T) {
+	var newClient = func(t *testing.T) (*client, *db.MockClient, *clusters.MockClient, *catalog.MockClient) {
+		ctrl := gomock.NewController(t)
+		dbClient := db.NewMockClient(ctrl)
+		clustersClient := clusters.NewMockClient(ctrl)
+		catalogClient := catalog.NewMockClient(ctrl)
+		catalogClient.EXPECT().GetName().Return("platform").AnyTimes()
+
+		return &client{config: Config{MaxFailures: 3}, dbClient: dbClient, clustersClient: clustersClient, tracer: otel.Tracer("watcher"), failures: make(map[failureKey]int)}, dbClient, clustersClient, catalogClient
+	}
+
+	t.Run("should save resources from catalog", func(t *testing.T) {
+		resources := &catalog.Resources{
+			Applications: []applicationv1.ApplicationSpec{{ID: "application1"}},
+			Dashboards:   []dashboardv1.DashboardSpec{{ID: "dashboard1"}},
+			Teams:        []teamv1.TeamSpec{{ID: "team1"}},
+			Users:        []userv1.UserSpec{{ID: "user1"}},
+		}
+
+		client, dbClient, clustersClient, catalogClient := newClient(t)
+		clustersClient.EXPECT().GetCluster("platform").Return(nil)
+		catalogClient.EXPECT().Load(gomock.Any()).Return(resources, nil)
+		dbClient.EXPECT().SaveApplications(gomock.Any(), "platform", resources.Applications).Return(nil)
+		dbClient.EXPECT().SaveTags(gomock.Any(), resources.Applications).Return(nil)
+		dbClient.EXPECT().SaveTopology(gomock.Any(), "platform", resources.Applications).Return(nil)
+		dbClient.EXPECT().SaveDashboards(gomock.Any(), "platform", resources.Dashboards).Return(nil)
+		dbClient.EXPECT().SaveTeams(gomock.Any(), "platform", resources.Teams).Return(nil)
+		dbClient.EXPECT().SaveUsers(gomock.Any(), "platform", resources.Users).Return(fmt.Errorf("unexpected error"))
+
+		var runs []*db.SyncRun
+		dbClient.EXPECT().SaveSyncRun(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, run *db.SyncRun) error {
+			runs = append(runs, run)
+			return nil
+		}).Times(4)
+
+		client.syncCatalog(context.Background(), catalogClient, time.Now())
+		require.Equal(t, 4, len(runs))
+		require.Equal(t, "platform", runs[0].Cluster)
+		require.Equal(t, "applications", runs[0].Resource)
+		require.Equal(t, 1, runs[0].Count)
+		require.Equal(t, "", runs[0].Error)
+		require.Equal(t, "users", runs[3].Resource)
+		require.Equal(t, "unexpected error", runs[3].Error)
+	})
+
+	t.Run("should record failed sync when catalog can not be loaded", func(t *testing.T) {
+		client, dbClient, clustersClient, catalogClient := newClient(t)
+		clustersClient.EXPECT().GetCluster("platform").Return(nil)
+		catalogClient.EXPECT().Load(gomock.Any()).Return(nil, fmt.Errorf("unexpected error"))
+
+		var runs []*db.SyncRun
+		dbClient.EXPECT().SaveSyncRun(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, run *db.SyncRun) error {
+			runs = append(runs, run)
+			return nil
+		}).Times(4)
+
+		client.syncCatalog(context.Background(), catalogClient, time.Now())
+		for _, run := range runs {
+			require.Equal(t, "unexpected error", run.Error)
+		}
+	})
+
+	t.Run("should not save resources when watcher is not the leader", func(t *testing.T) {
+		client, _, clustersClient, catalogClient := newClient(t)
+		client.config.LeaderElection = true
+		clustersClient.EXPECT().GetCluster("platform").Return(nil)
+		catalogClient.EXPECT().Load(gomock.Any()).Return(&catalog.Resources{}, nil)
+
+		client.syncCatalog(context.Background(), catalogClient, time.Now())
+	})
+
+	t.Run("should not sync catalog when a cluster with the same name exists", func(t *testing.T) {
+		client, dbClient, clustersClient, catalogClient := newClient(t)
+		clustersClient.EXPECT().GetCluster("platform").Return(cluster.NewMockClient(gomock.NewController(t)))
+
+		var runs []*db.SyncRun
+		dbClient.EXPECT().SaveSyncRun(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, run *db.SyncRun) error {
+			runs = append(runs, run)
+			return nil
+		}).Times(4)
+
+		client.syncCatalog(context.Background(), catalogClient, time.Now())
+		require.Equal(t, "catalog name platform is already used by a cluster", runs[0].Error)
+	})
+}
+
+func TestNewClient(t *testing.T) {
+	t.Run("should return error for duplicate catalog names", func(t *testing.T) {
+		_, err := NewClient(Config{Workers: 1, Catalogs: []catalog.Config{{Name: "platform", Path: "/catalog1"}, {Name: "platform", Path: "/catalog2"}}}, nil, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("should return error for invalid catalog", func(t *testing.T) {
+		_, err := NewClient(Config{Workers: 1, Catalogs: []catalog.Config{{Name: "platform"}}}, nil, nil)
+		require.Error(t, err)
+	})
+}
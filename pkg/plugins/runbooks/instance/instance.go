@@ -15,8 +15,6 @@ import (
 	"github.com/kobsio/kobs/pkg/instrument/log"
 
 	"github.com/mitchellh/mapstructure"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
 )
 
@@ -47,21 +45,17 @@ func (i *instance) GetName() string {
 func (i *instance) GetRunbooks(ctx context.Context, query, alert, group string) ([]Runbook, error) {
 	var runbooks []Runbook
 
-	filter := make(bson.M)
+	var documentQuery db.DocumentQuery
 	if alert != "" && group != "" {
-		filter["_id"] = bson.M{"$eq": fmt.Sprintf("/group/%s/alert/%s", group, alert)}
+		documentQuery.ID = fmt.Sprintf("/group/%s/alert/%s", group, alert)
 	} else if group != "" {
-		filter["group"] = bson.M{"$eq": group}
+		documentQuery.Fields = map[string]string{"group": group}
 	} else if query != "" {
-		filter["$text"] = bson.M{"$search": query}
+		documentQuery.Search = query
+		documentQuery.SearchFields = []string{"alert", "message", "runbook"}
 	}
 
-	cursor, err := i.dbClient.DB().Database("kobs").Collection("runbooks").Find(ctx, filter)
-	if err != nil {
-		return nil, err
-	}
-
-	err = cursor.All(ctx, &runbooks)
+	err := i.dbClient.GetDocuments(ctx, "runbooks", documentQuery, &runbooks)
 	if err != nil {
 		return nil, err
 	}
@@ -117,27 +111,18 @@ func (i *instance) SyncRunbooks(ctx context.Context) error {
 		}
 	}
 
-	var models []mongo.WriteModel
-	for _, runbook := range runbooks {
-		models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.D{{Key: "_id", Value: runbook.ID}}).SetReplacement(runbook).SetUpsert(true))
+	// If we were not able to get any runbook, we do not save the runbooks, so that we do not delete all existing
+	// runbooks when all clusters are temporarily not available.
+	if len(runbooks) == 0 {
+		return nil
 	}
 
-	_, err := i.dbClient.DB().Database("kobs").Collection("runbooks").BulkWrite(ctx, models)
-	if err != nil {
-		return err
-	}
-
-	_, err = i.dbClient.DB().Database("kobs").Collection("runbooks").DeleteMany(ctx, bson.D{{Key: "updatedAt", Value: bson.D{{Key: "$lt", Value: updatedAt}}}})
-	if err != nil {
-		return err
-	}
-
-	_, err = i.dbClient.DB().Database("kobs").Collection("runbooks").Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "alert", Value: "text"}, {Key: "message", Value: "text"}, {Key: "runbook", Value: "text"}}})
-	if err != nil {
-		return err
+	var documents []db.Document
+	for _, runbook := range runbooks {
+		documents = append(documents, db.Document{ID: runbook.ID, Data: runbook})
 	}
 
-	return nil
+	return i.dbClient.SaveDocuments(ctx, "runbooks", documents)
 }
 
 func New(name string, options map[string]any, clustersClient clusters.Client, dbClient db.Client) (Instance, error) {
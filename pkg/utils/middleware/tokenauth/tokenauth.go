@@ -1,6 +1,7 @@
 package tokenauth
 
 import (
+	"crypto/subtle"
 	"net/http"
 	"strings"
 
@@ -11,11 +12,27 @@ import (
 // header with the `Bearer` prefix. If the token from the header doesn't matches the `token` parameter the middleware
 // returns a unauthorized error.
 func Handler(token string) func(next http.Handler) http.Handler {
+	return HandlerFunc(func() []string {
+		if token == "" {
+			return nil
+		}
+		return []string{token}
+	}, false)
+}
+
+// HandlerFunc is the same as Handler, but the valid tokens are returned by the provided function for each request. This
+// allows us to rotate the token without restarting kobs. The token from the header must match one of the returned
+// tokens.
+//
+// When no token is returned, all requests are allowed, unless `required` is true. In this case all requests are
+// rejected, so that a misconfigured token (e.g. an empty token file) never disables the authentication.
+func HandlerFunc(getTokens func() []string, required bool) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
-			if token != "" {
-				authHeader := r.Header.Get("Authorization")
-				if !strings.HasPrefix(authHeader, "Bearer ") || strings.TrimPrefix(authHeader, "Bearer ") != token {
+			tokens := getTokens()
+			if len(tokens) > 0 || required {
+				bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+				if !ok || !isValidToken(bearer, tokens) {
 					errresponse.Render(w, r, http.StatusUnauthorized)
 					return
 				}
@@ -27,3 +44,18 @@ func Handler(token string) func(next http.Handler) http.Handler {
 		return http.HandlerFunc(fn)
 	}
 }
+
+// isValidToken returns true when the provided token is not empty and matches one of the valid tokens.
+func isValidToken(token string, validTokens []string) bool {
+	if token == "" {
+		return false
+	}
+
+	for _, validToken := range validTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(validToken)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -59,3 +59,56 @@ func TestHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlerFunc(t *testing.T) {
+	for _, tt := range []struct {
+		name               string
+		tokens             []string
+		required           bool
+		authorization      string
+		expectedStatusCode int
+	}{
+		{
+			name:               "should allow all requests when no token is configured",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "should reject requests when token is required but empty",
+			required:           true,
+			authorization:      "Bearer ",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "should accept all valid tokens",
+			tokens:             []string{"token1", "token2"},
+			required:           true,
+			authorization:      "Bearer token2",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "should reject invalid token",
+			tokens:             []string{"token1", "token2"},
+			required:           true,
+			authorization:      "Bearer token3",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			router := chi.NewRouter()
+			router.Use(HandlerFunc(func() []string { return tt.tokens }, tt.required))
+			router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				render.JSON(w, r, nil)
+			})
+
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
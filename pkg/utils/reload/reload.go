@@ -0,0 +1,317 @@
+package reload
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kobsio/kobs/pkg/instrument/log"
+
+	"go.uber.org/zap"
+)
+
+// checkInterval is the minimum time between two checks if a file was changed.
+var checkInterval = 10 * time.Second
+
+// File caches the content of a file and reloads the content when the file was changed. To detect changes we compare
+// the modification time and the size of the file. The file is checked at most once per checkInterval, so that the
+// content can be retrieved for each request, without reading the file every time. Since we are using os.Stat, this also
+// works for files mounted from a Kubernetes Secret, where the file is replaced by changing a symlink.
+type File struct {
+	path      string
+	mu        sync.Mutex
+	content   []byte
+	modTime   time.Time
+	size      int64
+	version   uint64
+	lastCheck time.Time
+}
+
+// Get returns the current content of the file and a version, which is increased each time the file is changed. If the
+// file can not be reloaded the last known content is returned.
+func (f *File) Get() ([]byte, uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if time.Since(f.lastCheck) >= checkInterval {
+		if err := f.load(); err != nil {
+			log.Warn(context.Background(), "Could not reload file", zap.Error(err), zap.String("path", f.path))
+		}
+	}
+
+	return f.content, f.version
+}
+
+// load reads the file, when the modification time or size of the file changed since the last load.
+func (f *File) load() error {
+	f.lastCheck = time.Now()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+
+	if f.version > 0 && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return nil
+	}
+
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+
+	f.content = content
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	f.version = f.version + 1
+
+	return nil
+}
+
+// NewFile returns a new File for the provided path. It returns an error when the file can not be read.
+func NewFile(path string) (*File, error) {
+	f := &File{path: path}
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Token is a token, which can be read from a file and is reloaded when the file was changed. The file can contain
+// multiple tokens, one per line. The first token is used for requests and all tokens are accepted, so that a new token
+// can be rolled out to all components before it is used. When the tokens are changed, the previous tokens are still
+// accepted for the tokenGracePeriod.
+//
+// When the file is empty, e.g. because it is written at the moment it is reloaded, the last tokens are kept, so that
+// the token can never become empty after it was loaded once.
+type Token struct {
+	file          *File
+	mu            sync.Mutex
+	tokens        []string
+	previous      []string
+	previousUntil time.Time
+	version       uint64
+}
+
+// tokenGracePeriod is the time, for which the previous tokens are still accepted after the token file was changed.
+var tokenGracePeriod = 5 * time.Minute
+
+// Current returns the token, which should be used for requests. If no token is configured an empty string is returned.
+func (t *Token) Current() string {
+	tokens := t.get()
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	return tokens[0]
+}
+
+// Valid returns all tokens which should be accepted, including the previous tokens during the grace period.
+func (t *Token) Valid() []string {
+	tokens := t.get()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.previous) > 0 && time.Now().Before(t.previousUntil) {
+		return append(append([]string{}, tokens...), t.previous...)
+	}
+
+	return tokens
+}
+
+// Required returns true when the token is read from a file, so that an empty token must never be treated as "no
+// authentication".
+func (t *Token) Required() bool {
+	return t.file != nil
+}
+
+// get returns the current tokens and reloads them from the file if necessary.
+func (t *Token) get() []string {
+	if t.file == nil {
+		return t.tokens
+	}
+
+	content, version := t.file.Get()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if version == t.version {
+		return t.tokens
+	}
+	t.version = version
+
+	tokens := parseTokens(content)
+	if len(tokens) == 0 {
+		log.Warn(context.Background(), "Token file is empty, keep using the last token", zap.String("path", t.file.path))
+		return t.tokens
+	}
+
+	if len(t.tokens) > 0 {
+		t.previous = t.tokens
+		t.previousUntil = time.Now().Add(tokenGracePeriod)
+	}
+	t.tokens = tokens
+
+	return t.tokens
+}
+
+// parseTokens returns all non empty lines of the provided content. Leading and trailing whitespace is removed from each
+// line, so that the file can end with a newline.
+func parseTokens(content []byte) []string {
+	var tokens []string
+
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		if token := string(bytes.TrimSpace(line)); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+
+	return tokens
+}
+
+// NewToken returns a new Token. If the path is empty the provided token is used. Otherwise the tokens are read from the
+// file and an error is returned when the file can not be read or doesn't contain a token.
+func NewToken(token, path string) (*Token, error) {
+	if path == "" {
+		if token == "" {
+			return &Token{}, nil
+		}
+		return &Token{tokens: []string{token}}, nil
+	}
+
+	file, err := NewFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Token{file: file}
+	if len(t.get()) == 0 {
+		return nil, fmt.Errorf("no token found in %s", path)
+	}
+
+	return t, nil
+}
+
+// Certificate is a certificate and key pair, which is reloaded when the certificate or key file was changed.
+type Certificate struct {
+	certFile    *File
+	keyFile     *File
+	mu          sync.Mutex
+	certificate *tls.Certificate
+	certVersion uint64
+	keyVersion  uint64
+}
+
+// Get returns the current certificate. If the changed files can not be parsed, e.g. because only one of the files
+// was already updated, the last valid certificate is returned.
+func (c *Certificate) Get() (*tls.Certificate, error) {
+	certPEM, certVersion := c.certFile.Get()
+	keyPEM, keyVersion := c.keyFile.Get()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.certificate != nil && certVersion == c.certVersion && keyVersion == c.keyVersion {
+		return c.certificate, nil
+	}
+
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		if c.certificate != nil {
+			return c.certificate, nil
+		}
+		return nil, err
+	}
+
+	c.certificate = &certificate
+	c.certVersion = certVersion
+	c.keyVersion = keyVersion
+
+	return c.certificate, nil
+}
+
+// GetCertificate can be used as GetCertificate function in a tls.Config for a server.
+func (c *Certificate) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return c.Get()
+}
+
+// GetClientCertificate can be used as GetClientCertificate function in a tls.Config for a client.
+func (c *Certificate) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return c.Get()
+}
+
+// NewCertificate returns a new Certificate for the provided certificate and key file. It returns an error when the
+// files can not be read or do not contain a valid certificate and key pair.
+func NewCertificate(certFile, keyFile string) (*Certificate, error) {
+	cert, err := NewFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := NewFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Certificate{certFile: cert, keyFile: key}
+	if _, err := c.Get(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// CertPool is a pool of CA certificates, which is reloaded when the CA file was changed.
+type CertPool struct {
+	file    *File
+	mu      sync.Mutex
+	pool    *x509.CertPool
+	version uint64
+}
+
+// Get returns the current pool of CA certificates. If the changed file doesn't contain any valid certificate, the last
+// valid pool is returned.
+func (p *CertPool) Get() *x509.CertPool {
+	caPEM, version := p.file.Get()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pool != nil && version == p.version {
+		return p.pool
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return p.pool
+	}
+
+	p.pool = pool
+	p.version = version
+
+	return p.pool
+}
+
+// NewCertPool returns a new CertPool for the provided CA file. It returns an error when the file can not be read or
+// doesn't contain any valid certificate.
+func NewCertPool(caFile string) (*CertPool, error) {
+	file, err := NewFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &CertPool{file: file}
+	if p.Get() == nil {
+		return nil, fmt.Errorf("no valid certificates found in %s", caFile)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,241 @@
+package reload
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// generateCertificates generates a CA and a server and client certificate signed by the CA in the provided directory.
+func generateCertificates(t *testing.T, dir string) {
+	var writePEM = func(name, pemType string, data []byte) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: data}), 0600))
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kobs-ca"},
+		NotBefore:             time.Now().Add(-1 * time.Hour),
+		NotAfter:              time.Now().Add(1 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	writePEM("ca.pem", "CERTIFICATE", caDER)
+
+	for i, name := range []string{"server", "client"} {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(int64(i + 2)),
+			Subject:      pkix.Name{CommonName: name},
+			DNSNames:     []string{"localhost"},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+			NotBefore:    time.Now().Add(-1 * time.Hour),
+			NotAfter:     time.Now().Add(1 * time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+		writePEM(name+".pem", "CERTIFICATE", der)
+		writePEM(name+"-key.pem", "EC PRIVATE KEY", keyDER)
+	}
+}
+
+func TestFile(t *testing.T) {
+	checkInterval = 0
+	defer func() { checkInterval = 10 * time.Second }()
+
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("token1\n"), 0600))
+
+	t.Run("should fail for not existing file", func(t *testing.T) {
+		_, err := NewFile(filepath.Join(t.TempDir(), "not-existing"))
+		require.Error(t, err)
+	})
+
+	t.Run("should reload file", func(t *testing.T) {
+		file, err := NewFile(path)
+		require.NoError(t, err)
+
+		content, version := file.Get()
+		require.Equal(t, "token1\n", string(content))
+		require.Equal(t, uint64(1), version)
+
+		require.NoError(t, os.WriteFile(path, []byte("token22\n"), 0600))
+		content, version = file.Get()
+		require.Equal(t, "token22\n", string(content))
+		require.Equal(t, uint64(2), version)
+
+		require.NoError(t, os.Remove(path))
+		content, version = file.Get()
+		require.Equal(t, "token22\n", string(content))
+		require.Equal(t, uint64(2), version)
+	})
+}
+
+func TestToken(t *testing.T) {
+	checkInterval = 0
+	defer func() { checkInterval = 10 * time.Second }()
+
+	t.Run("should return static token", func(t *testing.T) {
+		token, err := NewToken("token1", "")
+		require.NoError(t, err)
+		require.Equal(t, "token1", token.Current())
+		require.Equal(t, []string{"token1"}, token.Valid())
+		require.False(t, token.Required())
+	})
+
+	t.Run("should return no token", func(t *testing.T) {
+		token, err := NewToken("", "")
+		require.NoError(t, err)
+		require.Equal(t, "", token.Current())
+		require.Empty(t, token.Valid())
+		require.False(t, token.Required())
+	})
+
+	t.Run("should fail for not existing file", func(t *testing.T) {
+		_, err := NewToken("token1", filepath.Join(t.TempDir(), "not-existing"))
+		require.Error(t, err)
+	})
+
+	t.Run("should fail for empty file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(path, []byte("\n"), 0600))
+
+		_, err := NewToken("token1", path)
+		require.Error(t, err)
+	})
+
+	t.Run("should return token from file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(path, []byte("token2\n"), 0600))
+
+		token, err := NewToken("token1", path)
+		require.NoError(t, err)
+		require.Equal(t, "token2", token.Current())
+		require.True(t, token.Required())
+	})
+
+	t.Run("should return multiple tokens from file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(path, []byte("token2\n\n token3 \n"), 0600))
+
+		token, err := NewToken("", path)
+		require.NoError(t, err)
+		require.Equal(t, "token2", token.Current())
+		require.Equal(t, []string{"token2", "token3"}, token.Valid())
+	})
+
+	t.Run("should keep last token when file is empty", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(path, []byte("token2\n"), 0600))
+
+		token, err := NewToken("", path)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(path, []byte(""), 0600))
+		require.Equal(t, "token2", token.Current())
+		require.Equal(t, []string{"token2"}, token.Valid())
+	})
+
+	t.Run("should accept previous token during grace period", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(path, []byte("token2\n"), 0600))
+
+		token, err := NewToken("", path)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(path, []byte("token3\n"), 0600))
+		require.Equal(t, "token3", token.Current())
+		require.Equal(t, []string{"token3", "token2"}, token.Valid())
+
+		tokenGracePeriod = 0
+		defer func() { tokenGracePeriod = 5 * time.Minute }()
+
+		require.NoError(t, os.WriteFile(path, []byte("token4\n"), 0600))
+		require.Equal(t, []string{"token4"}, token.Valid())
+	})
+}
+
+func TestCertificate(t *testing.T) {
+	checkInterval = 0
+	defer func() { checkInterval = 10 * time.Second }()
+
+	dir := t.TempDir()
+	generateCertificates(t, dir)
+
+	t.Run("should fail for invalid key pair", func(t *testing.T) {
+		_, err := NewCertificate(filepath.Join(dir, "server.pem"), filepath.Join(dir, "client-key.pem"))
+		require.Error(t, err)
+	})
+
+	t.Run("should reload certificate", func(t *testing.T) {
+		certFile := filepath.Join(t.TempDir(), "cert.pem")
+		keyFile := filepath.Join(t.TempDir(), "key.pem")
+		copyFile(t, filepath.Join(dir, "server.pem"), certFile)
+		copyFile(t, filepath.Join(dir, "server-key.pem"), keyFile)
+
+		certificate, err := NewCertificate(certFile, keyFile)
+		require.NoError(t, err)
+		requireCommonName(t, certificate, "server")
+
+		// When only the certificate was changed, the old certificate should be returned until the key is also changed.
+		copyFile(t, filepath.Join(dir, "client.pem"), certFile)
+		requireCommonName(t, certificate, "server")
+
+		copyFile(t, filepath.Join(dir, "client-key.pem"), keyFile)
+		requireCommonName(t, certificate, "client")
+	})
+}
+
+func TestCertPool(t *testing.T) {
+	dir := t.TempDir()
+	generateCertificates(t, dir)
+
+	t.Run("should fail for invalid ca file", func(t *testing.T) {
+		_, err := NewCertPool(filepath.Join(dir, "server-key.pem"))
+		require.Error(t, err)
+	})
+
+	t.Run("should return cert pool", func(t *testing.T) {
+		pool, err := NewCertPool(filepath.Join(dir, "ca.pem"))
+		require.NoError(t, err)
+		require.NotNil(t, pool.Get())
+	})
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	data, err := os.ReadFile(src)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(dst, data, 0600))
+	// Make sure that the modification time changes, even when the file system has a low resolution for the
+	// modification time.
+	require.NoError(t, os.Chtimes(dst, time.Now(), time.Now().Add(time.Duration(len(data))*time.Second)))
+}
+
+func requireCommonName(t *testing.T, certificate *Certificate, commonName string) {
+	cert, err := certificate.Get()
+	require.NoError(t, err)
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	require.Equal(t, commonName, parsed.Subject.CommonName)
+}
@@ -0,0 +1,264 @@
+package tunnel
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// The following frame types are used to multiplex multiple streams over a single WebSocket connection. Each frame is
+// sent as binary WebSocket message, where the first byte is the frame type, the next four bytes are the id of the
+// stream and the rest of the message is the payload of the frame.
+const (
+	frameOpen byte = iota + 1
+	frameData
+	frameClose
+	frameWindow
+)
+
+const (
+	headerSize        = 5
+	maxPayloadSize    = 32 * 1024
+	initialWindowSize = 256 * 1024
+	acceptBacklog     = 64
+	pingPeriod        = 30 * time.Second
+	pongWait          = 90 * time.Second
+	writeWait         = 10 * time.Second
+)
+
+// ClusterHeader is the header which is used by a cluster to provide its name, when it opens a tunnel to the hub.
+const ClusterHeader = "X-Kobs-Cluster"
+
+// ErrSessionClosed is returned when a stream should be opened or accepted on a closed session.
+var ErrSessionClosed = errors.New("tunnel session closed")
+
+// Session multiplexes multiple streams over a single WebSocket connection. Each stream implements the net.Conn
+// interface, so that it can be used to serve or send plain HTTP requests (including WebSocket upgrades) through the
+// tunnel.
+//
+// The side which initiates the streams (the hub) uses the `Open` method to open a new stream. The other side (the
+// cluster) uses the session as net.Listener, so that all opened streams can be served by an http.Server.
+//
+// Each stream has its own flow control window, so that a slow reader of one stream doesn't block all other streams of
+// the session.
+type Session struct {
+	conn      *websocket.Conn
+	writeMu   sync.Mutex
+	streamsMu sync.Mutex
+	streams   map[uint32]*stream
+	nextID    atomic.Uint32
+	accept    chan *stream
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewSession returns a new session for the provided WebSocket connection. The `client` parameter must be true on the
+// side which opens the streams and false on the side which accepts the streams, so that the ids of the streams can not
+// conflict.
+func NewSession(conn *websocket.Conn, client bool) *Session {
+	s := &Session{
+		conn:    conn,
+		streams: make(map[uint32]*stream),
+		accept:  make(chan *stream, acceptBacklog),
+		done:    make(chan struct{}),
+	}
+
+	if client {
+		s.nextID.Store(1)
+	} else {
+		s.nextID.Store(2)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	go s.readLoop()
+	go s.pingLoop()
+
+	return s
+}
+
+// Open opens a new stream in the session.
+func (s *Session) Open() (net.Conn, error) {
+	select {
+	case <-s.done:
+		return nil, ErrSessionClosed
+	default:
+	}
+
+	id := s.nextID.Add(2) - 2
+	st := newStream(s, id)
+
+	s.streamsMu.Lock()
+	s.streams[id] = st
+	s.streamsMu.Unlock()
+
+	if err := s.writeFrame(frameOpen, id, nil); err != nil {
+		s.removeStream(id)
+		return nil, err
+	}
+
+	return st, nil
+}
+
+// Accept waits for and returns the next stream, which was opened by the other side of the session. Together with the
+// `Close` and `Addr` methods the session implements the net.Listener interface.
+func (s *Session) Accept() (net.Conn, error) {
+	select {
+	case st := <-s.accept:
+		return st, nil
+	case <-s.done:
+		return nil, ErrSessionClosed
+	}
+}
+
+// Addr returns the address of the session.
+func (s *Session) Addr() net.Addr {
+	return addr{}
+}
+
+// Close closes the session, the underlying WebSocket connection and all streams of the session.
+func (s *Session) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.conn.Close()
+
+		s.streamsMu.Lock()
+		defer s.streamsMu.Unlock()
+
+		for id, st := range s.streams {
+			st.remoteClose()
+			delete(s.streams, id)
+		}
+	})
+
+	return nil
+}
+
+// Done returns a channel, which is closed when the session is closed.
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// readLoop reads all frames from the WebSocket connection and passes them to the corresponding stream. When the
+// connection returns an error the session is closed.
+func (s *Session) readLoop() {
+	defer s.Close()
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		s.conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		if len(data) < headerSize {
+			continue
+		}
+
+		frameType := data[0]
+		id := binary.BigEndian.Uint32(data[1:headerSize])
+		payload := data[headerSize:]
+
+		switch frameType {
+		case frameOpen:
+			st := newStream(s, id)
+
+			s.streamsMu.Lock()
+			s.streams[id] = st
+			s.streamsMu.Unlock()
+
+			select {
+			case s.accept <- st:
+			default:
+				s.removeStream(id)
+				s.writeFrame(frameClose, id, nil)
+			}
+		case frameData:
+			if st := s.getStream(id); st != nil && !st.receive(payload) {
+				st.Close()
+			}
+		case frameClose:
+			if st := s.getStream(id); st != nil {
+				st.remoteClose()
+				s.removeStream(id)
+			}
+		case frameWindow:
+			if st := s.getStream(id); st != nil && len(payload) == 4 {
+				st.addWindow(int(binary.BigEndian.Uint32(payload)))
+			}
+		}
+	}
+}
+
+// pingLoop sends a ping message in the configured interval, so that a broken connection is detected by both sides of
+// the session.
+func (s *Session) pingLoop() {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				s.Close()
+				return
+			}
+		}
+	}
+}
+
+// writeFrame writes a single frame to the WebSocket connection. If the frame can not be written the session is
+// closed.
+func (s *Session) writeFrame(frameType byte, id uint32, payload []byte) error {
+	data := make([]byte, headerSize+len(payload))
+	data[0] = frameType
+	binary.BigEndian.PutUint32(data[1:headerSize], id)
+	copy(data[headerSize:], payload)
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	if err := s.conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		go s.Close()
+		return err
+	}
+
+	return nil
+}
+
+func (s *Session) getStream(id uint32) *stream {
+	s.streamsMu.Lock()
+	defer s.streamsMu.Unlock()
+
+	return s.streams[id]
+}
+
+func (s *Session) removeStream(id uint32) {
+	s.streamsMu.Lock()
+	defer s.streamsMu.Unlock()
+
+	delete(s.streams, id)
+}
+
+// addr implements the net.Addr interface for the session and all streams of the session.
+type addr struct{}
+
+func (addr) Network() string {
+	return "tunnel"
+}
+
+func (addr) String() string {
+	return "tunnel"
+}
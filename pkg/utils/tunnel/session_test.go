@@ -0,0 +1,226 @@
+package tunnel
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func newSessions(t *testing.T) (*Session, *Session) {
+	serverSessions := make(chan *Session, 1)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		serverSessions <- NewSession(conn, false)
+	}))
+	t.Cleanup(ts.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	require.NoError(t, err)
+
+	clientSession := NewSession(conn, true)
+	serverSession := <-serverSessions
+
+	t.Cleanup(func() {
+		clientSession.Close()
+		serverSession.Close()
+	})
+
+	return clientSession, serverSession
+}
+
+func TestSession(t *testing.T) {
+	t.Run("should send data in both directions", func(t *testing.T) {
+		clientSession, serverSession := newSessions(t)
+
+		clientConn, err := clientSession.Open()
+		require.NoError(t, err)
+
+		serverConn, err := serverSession.Accept()
+		require.NoError(t, err)
+
+		_, err = clientConn.Write([]byte("ping"))
+		require.NoError(t, err)
+
+		buf := make([]byte, 4)
+		_, err = io.ReadFull(serverConn, buf)
+		require.NoError(t, err)
+		require.Equal(t, "ping", string(buf))
+
+		_, err = serverConn.Write([]byte("pong"))
+		require.NoError(t, err)
+
+		_, err = io.ReadFull(clientConn, buf)
+		require.NoError(t, err)
+		require.Equal(t, "pong", string(buf))
+	})
+
+	t.Run("should send more data than the window size", func(t *testing.T) {
+		clientSession, serverSession := newSessions(t)
+
+		clientConn, err := clientSession.Open()
+		require.NoError(t, err)
+
+		serverConn, err := serverSession.Accept()
+		require.NoError(t, err)
+
+		data := make([]byte, 4*initialWindowSize+123)
+		rand.Read(data)
+
+		go func() {
+			clientConn.Write(data)
+			clientConn.Close()
+		}()
+
+		received, err := io.ReadAll(serverConn)
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(data, received))
+	})
+
+	t.Run("should return eof when stream is closed by the other side", func(t *testing.T) {
+		clientSession, serverSession := newSessions(t)
+
+		clientConn, err := clientSession.Open()
+		require.NoError(t, err)
+
+		serverConn, err := serverSession.Accept()
+		require.NoError(t, err)
+
+		require.NoError(t, serverConn.Close())
+
+		_, err = clientConn.Read(make([]byte, 1))
+		require.Equal(t, io.EOF, err)
+
+		_, err = serverConn.Read(make([]byte, 1))
+		require.ErrorIs(t, err, net.ErrClosed)
+	})
+
+	t.Run("should close stream when the other side exceeds the window", func(t *testing.T) {
+		clientSession, serverSession := newSessions(t)
+
+		clientConn, err := clientSession.Open()
+		require.NoError(t, err)
+
+		serverConn, err := serverSession.Accept()
+		require.NoError(t, err)
+
+		// We write the frames directly to the session, so that the window of the stream is ignored like by a
+		// misbehaving peer.
+		id := clientConn.(*stream).id
+		for i := 0; i <= initialWindowSize/maxPayloadSize; i++ {
+			require.NoError(t, clientSession.writeFrame(frameData, id, make([]byte, maxPayloadSize)))
+		}
+
+		require.Eventually(t, func() bool {
+			return serverSession.getStream(id) == nil
+		}, 5*time.Second, 10*time.Millisecond)
+
+		_, err = serverConn.Read(make([]byte, 1))
+		require.ErrorIs(t, err, net.ErrClosed)
+
+		_, err = clientConn.Read(make([]byte, 1))
+		require.Equal(t, io.EOF, err)
+	})
+
+	t.Run("should return error when read deadline is exceeded", func(t *testing.T) {
+		clientSession, _ := newSessions(t)
+
+		clientConn, err := clientSession.Open()
+		require.NoError(t, err)
+
+		require.NoError(t, clientConn.SetReadDeadline(time.Now().Add(10*time.Millisecond)))
+		_, err = clientConn.Read(make([]byte, 1))
+		require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	})
+
+	t.Run("should close all streams when session is closed", func(t *testing.T) {
+		clientSession, serverSession := newSessions(t)
+
+		clientConn, err := clientSession.Open()
+		require.NoError(t, err)
+
+		_, err = serverSession.Accept()
+		require.NoError(t, err)
+
+		require.NoError(t, serverSession.Close())
+		<-clientSession.Done()
+
+		_, err = clientConn.Read(make([]byte, 1))
+		require.Equal(t, io.EOF, err)
+
+		_, err = clientSession.Open()
+		require.Equal(t, ErrSessionClosed, err)
+
+		_, err = serverSession.Accept()
+		require.Equal(t, ErrSessionClosed, err)
+	})
+
+	t.Run("should serve http requests and websocket connections", func(t *testing.T) {
+		clientSession, serverSession := newSessions(t)
+
+		server := &http.Server{
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/ws" {
+					upgrader := websocket.Upgrader{}
+					conn, err := upgrader.Upgrade(w, r, nil)
+					if err != nil {
+						return
+					}
+					defer conn.Close()
+
+					_, msg, err := conn.ReadMessage()
+					if err != nil {
+						return
+					}
+					conn.WriteMessage(websocket.TextMessage, append([]byte("echo "), msg...))
+					return
+				}
+
+				w.Write([]byte("hello " + r.URL.Path))
+			}),
+			ReadHeaderTimeout: 3 * time.Second,
+		}
+		go server.Serve(serverSession)
+		defer server.Close()
+
+		dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return clientSession.Open()
+		}
+
+		httpClient := &http.Client{Transport: &http.Transport{DialContext: dial}}
+		for i := 0; i < 3; i++ {
+			resp, err := httpClient.Get("http://tunnel/world")
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			require.NoError(t, err)
+			require.Equal(t, "hello /world", string(body))
+		}
+
+		dialer := websocket.Dialer{NetDialContext: dial}
+		conn, _, err := dialer.Dial("ws://tunnel/ws", nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("test")))
+		_, msg, err := conn.ReadMessage()
+		require.NoError(t, err)
+		require.Equal(t, "echo test", string(msg))
+	})
+}
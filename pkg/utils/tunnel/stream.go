@@ -0,0 +1,263 @@
+package tunnel
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// stream is a single stream of a session. It implements the net.Conn interface.
+//
+// The received data is buffered until it is read. To limit the size of the buffer each side of a stream can only send
+// as much data as the other side allows via its window. When the data is read, the window is increased via a window
+// frame.
+type stream struct {
+	id            uint32
+	session       *Session
+	mu            sync.Mutex
+	readBuffer    []byte
+	unacked       int
+	sendWindow    int
+	localClosed   bool
+	remoteClosed  bool
+	readDeadline  time.Time
+	writeDeadline time.Time
+	readNotify    chan struct{}
+	writeNotify   chan struct{}
+}
+
+func newStream(session *Session, id uint32) *stream {
+	return &stream{
+		id:          id,
+		session:     session,
+		sendWindow:  initialWindowSize,
+		readNotify:  make(chan struct{}, 1),
+		writeNotify: make(chan struct{}, 1),
+	}
+}
+
+func (st *stream) Read(b []byte) (int, error) {
+	for {
+		st.mu.Lock()
+
+		if st.localClosed {
+			st.mu.Unlock()
+			return 0, net.ErrClosed
+		}
+
+		if len(st.readBuffer) > 0 {
+			n := copy(b, st.readBuffer)
+			st.readBuffer = st.readBuffer[n:]
+			if len(st.readBuffer) == 0 {
+				st.readBuffer = nil
+			}
+
+			var windowUpdate int
+			st.unacked += n
+			if st.unacked >= initialWindowSize/2 && !st.remoteClosed {
+				windowUpdate = st.unacked
+				st.unacked = 0
+			}
+			st.mu.Unlock()
+
+			if windowUpdate > 0 {
+				payload := make([]byte, 4)
+				binary.BigEndian.PutUint32(payload, uint32(windowUpdate))
+				st.session.writeFrame(frameWindow, st.id, payload)
+			}
+
+			return n, nil
+		}
+
+		if st.remoteClosed {
+			st.mu.Unlock()
+			return 0, io.EOF
+		}
+
+		deadline := st.readDeadline
+		st.mu.Unlock()
+
+		if err := st.wait(st.readNotify, deadline); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (st *stream) Write(b []byte) (int, error) {
+	var written int
+
+	for written < len(b) {
+		st.mu.Lock()
+
+		if st.localClosed {
+			st.mu.Unlock()
+			return written, net.ErrClosed
+		}
+
+		if st.remoteClosed {
+			st.mu.Unlock()
+			return written, io.ErrClosedPipe
+		}
+
+		if st.sendWindow == 0 {
+			deadline := st.writeDeadline
+			st.mu.Unlock()
+
+			if err := st.wait(st.writeNotify, deadline); err != nil {
+				return written, err
+			}
+			continue
+		}
+
+		n := min(len(b)-written, st.sendWindow, maxPayloadSize)
+		st.sendWindow -= n
+		st.mu.Unlock()
+
+		if err := st.session.writeFrame(frameData, st.id, b[written:written+n]); err != nil {
+			return written, err
+		}
+
+		written += n
+	}
+
+	return written, nil
+}
+
+// Close closes the stream and notifies the other side of the stream, if it wasn't already closed by the other side.
+func (st *stream) Close() error {
+	st.mu.Lock()
+	if st.localClosed {
+		st.mu.Unlock()
+		return nil
+	}
+	st.localClosed = true
+	remoteClosed := st.remoteClosed
+	st.mu.Unlock()
+
+	notify(st.readNotify)
+	notify(st.writeNotify)
+	st.session.removeStream(st.id)
+
+	if !remoteClosed {
+		st.session.writeFrame(frameClose, st.id, nil)
+	}
+
+	return nil
+}
+
+func (st *stream) LocalAddr() net.Addr {
+	return st.session.Addr()
+}
+
+func (st *stream) RemoteAddr() net.Addr {
+	return st.session.Addr()
+}
+
+func (st *stream) SetDeadline(t time.Time) error {
+	st.mu.Lock()
+	st.readDeadline = t
+	st.writeDeadline = t
+	st.mu.Unlock()
+
+	notify(st.readNotify)
+	notify(st.writeNotify)
+	return nil
+}
+
+func (st *stream) SetReadDeadline(t time.Time) error {
+	st.mu.Lock()
+	st.readDeadline = t
+	st.mu.Unlock()
+
+	notify(st.readNotify)
+	return nil
+}
+
+func (st *stream) SetWriteDeadline(t time.Time) error {
+	st.mu.Lock()
+	st.writeDeadline = t
+	st.mu.Unlock()
+
+	notify(st.writeNotify)
+	return nil
+}
+
+// receive adds the received data to the read buffer of the stream. The other side of the stream is only allowed to
+// send as much data as we granted via the window, so that all data which wasn't acknowledged yet via a window frame
+// must fit into the initial window size. If the other side exceeds the window, the data is dropped and false is
+// returned, so that the stream can be closed by the caller. This ensures that a peer can not grow the read buffer
+// without limit.
+func (st *stream) receive(data []byte) bool {
+	st.mu.Lock()
+	if st.localClosed {
+		st.mu.Unlock()
+		return true
+	}
+
+	if len(st.readBuffer)+st.unacked+len(data) > initialWindowSize {
+		st.mu.Unlock()
+		return false
+	}
+
+	st.readBuffer = append(st.readBuffer, data...)
+	st.mu.Unlock()
+
+	notify(st.readNotify)
+	return true
+}
+
+// addWindow increases the send window of the stream, after the other side of the stream read the data.
+func (st *stream) addWindow(n int) {
+	st.mu.Lock()
+	st.sendWindow += n
+	st.mu.Unlock()
+
+	notify(st.writeNotify)
+}
+
+// remoteClose marks the stream as closed by the other side. All buffered data can still be read, afterwards the read
+// methods returns io.EOF.
+func (st *stream) remoteClose() {
+	st.mu.Lock()
+	st.remoteClosed = true
+	st.mu.Unlock()
+
+	notify(st.readNotify)
+	notify(st.writeNotify)
+}
+
+// wait blocks until the provided channel is notified, the deadline is exceeded or the session is closed.
+func (st *stream) wait(ch chan struct{}, deadline time.Time) error {
+	var timeout <-chan time.Time
+
+	if !deadline.IsZero() {
+		d := time.Until(deadline)
+		if d <= 0 {
+			return os.ErrDeadlineExceeded
+		}
+
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-ch:
+		return nil
+	case <-timeout:
+		return os.ErrDeadlineExceeded
+	case <-st.session.done:
+		st.remoteClose()
+		return nil
+	}
+}
+
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}